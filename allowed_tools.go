@@ -0,0 +1,81 @@
+package llmproviders
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+// allowedToolsPlan limits the tools the model may call this turn (WithAllowedTools). Gemini
+// gets every tool with allowedFunctionNames; other providers are only sent the allowed tools.
+// finalize rejects responses calling any other tool.
+type allowedToolsPlan struct {
+	allowed []string
+}
+
+// planAllowedTools returns a plan when opts sets allowed tools, checking that they and a
+// forced tool choice name configured tools
+func planAllowedTools(opts *llmtypes.CallOptions) (*allowedToolsPlan, error) {
+	if opts.AllowedTools == nil {
+		return nil, nil
+	}
+	configured := make(map[string]bool, len(opts.Tools))
+	for _, tool := range opts.Tools {
+		if tool.Function != nil {
+			configured[tool.Function.Name] = true
+		}
+	}
+	for _, name := range opts.AllowedTools {
+		if !configured[name] {
+			return nil, fmt.Errorf("allowed tool %q is not one of the call's tools", name)
+		}
+	}
+	if name := forcedToolName(opts.ToolChoice); name != "" && !slices.Contains(opts.AllowedTools, name) {
+		return nil, fmt.Errorf("tool choice %q is not one of the allowed tools (%s)", name, strings.Join(opts.AllowedTools, ", "))
+	}
+	return &allowedToolsPlan{allowed: opts.AllowedTools}, nil
+}
+
+// nativeAllowedTools reports whether the model restricts tools itself (Gemini's
+// allowedFunctionNames) rather than being sent only the allowed tools
+func nativeAllowedTools(provider Provider, modelID string) bool {
+	return provider == ProviderVertex && !strings.HasPrefix(modelID, "claude-")
+}
+
+// apply sends only the allowed tools to models without native support. With no tool
+// allowed the call is made as with WithDisableTools.
+func (a *allowedToolsPlan) apply(provider Provider, modelID string, messages []llmtypes.MessageContent, options []llmtypes.CallOption) ([]llmtypes.MessageContent, []llmtypes.CallOption) {
+	if len(a.allowed) == 0 {
+		return disableTools(provider, messages, options)
+	}
+	if nativeAllowedTools(provider, modelID) {
+		return messages, options
+	}
+	options = append(append([]llmtypes.CallOption{}, options...), func(o *llmtypes.CallOptions) {
+		tools := make([]llmtypes.Tool, 0, len(a.allowed))
+		for _, tool := range o.Tools {
+			if tool.Function != nil && slices.Contains(a.allowed, tool.Function.Name) {
+				tools = append(tools, tool)
+			}
+		}
+		o.Tools = tools
+	})
+	return messages, options
+}
+
+// finalize returns an error if the response calls a tool that is not allowed
+func (a *allowedToolsPlan) finalize(resp *llmtypes.ContentResponse) error {
+	for _, choice := range resp.Choices {
+		if choice == nil {
+			continue
+		}
+		for _, toolCall := range choice.ToolCalls {
+			if toolCall.FunctionCall != nil && !slices.Contains(a.allowed, toolCall.FunctionCall.Name) {
+				return fmt.Errorf("model called tool %q, which is not allowed this turn (allowed: %s)", toolCall.FunctionCall.Name, strings.Join(a.allowed, ", "))
+			}
+		}
+	}
+	return nil
+}
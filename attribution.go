@@ -0,0 +1,59 @@
+package llmproviders
+
+import (
+	"cmp"
+	"os"
+
+	"github.com/openai/openai-go/v3/option"
+)
+
+// AppAttribution identifies the calling application to providers. Set it per Config so
+// multi-tenant hosts can attribute each tenant's requests; empty fields fall back to the
+// environment variables noted below.
+type AppAttribution struct {
+	// Referer is the app URL, sent to OpenRouter as HTTP-Referer (OPENROUTER_HTTP_REFERER)
+	Referer string
+	// Title is the app name, sent to OpenRouter as X-Title (OPENROUTER_X_TITLE)
+	Title string
+	// Organization is the OpenAI organization ID, sent as OpenAI-Organization (OPENAI_ORG_ID)
+	Organization string
+	// Project is the OpenAI project ID, sent as OpenAI-Project (OPENAI_PROJECT_ID)
+	Project string
+}
+
+// attribution returns Config.AppAttribution, or an empty one
+func (config Config) attribution() AppAttribution {
+	if config.AppAttribution == nil {
+		return AppAttribution{}
+	}
+	return *config.AppAttribution
+}
+
+// openRouterAttributionOptions returns the OpenRouter app attribution headers
+func (config Config) openRouterAttributionOptions() []option.RequestOption {
+	attribution := config.attribution()
+	referer := cmp.Or(attribution.Referer, os.Getenv("OPENROUTER_HTTP_REFERER"))
+	title := cmp.Or(attribution.Title, os.Getenv("OPENROUTER_X_TITLE"))
+	var options []option.RequestOption
+	if referer != "" {
+		options = append(options, option.WithHeader("HTTP-Referer", referer))
+	}
+	if title != "" {
+		options = append(options, option.WithHeader("X-Title", title))
+	}
+	return options
+}
+
+// openAIAttributionOptions returns the OpenAI organization and project options. The SDK
+// reads OPENAI_ORG_ID and OPENAI_PROJECT_ID itself, so only configured values are added.
+func (config Config) openAIAttributionOptions() []option.RequestOption {
+	attribution := config.attribution()
+	var options []option.RequestOption
+	if attribution.Organization != "" {
+		options = append(options, option.WithOrganization(attribution.Organization))
+	}
+	if attribution.Project != "" {
+		options = append(options, option.WithProject(attribution.Project))
+	}
+	return options
+}
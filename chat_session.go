@@ -0,0 +1,205 @@
+package llmproviders
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+	"github.com/manishiitg/multi-llm-provider-go/pkg/history"
+)
+
+// ChatSessionOption configures a ChatSession
+type ChatSessionOption func(*ChatSession)
+
+// WithSessionSystemPrompt starts the history with a system message
+func WithSessionSystemPrompt(prompt string) ChatSessionOption {
+	return func(s *ChatSession) {
+		s.messages = append([]llmtypes.MessageContent{llmtypes.TextParts(llmtypes.ChatMessageTypeSystem, prompt)}, s.messages...)
+	}
+}
+
+// WithSessionHistory resumes a conversation, e.g. one restored with UnmarshalTranscript
+func WithSessionHistory(messages []llmtypes.MessageContent) ChatSessionOption {
+	return func(s *ChatSession) {
+		s.messages = append(s.messages, messages...)
+	}
+}
+
+// WithSessionCallOptions sets the call options used for every turn (tools, model,
+// temperature, ...). Options passed to Send are applied after them.
+func WithSessionCallOptions(options ...llmtypes.CallOption) ChatSessionOption {
+	return func(s *ChatSession) {
+		s.callOptions = append(s.callOptions, options...)
+	}
+}
+
+// WithSessionMaxHistoryTokens compacts the history before every turn by dropping the
+// oldest messages (see history.Truncate) until it fits in maxTokens. System messages
+// and the current turn are always kept.
+func WithSessionMaxHistoryTokens(maxTokens int) ChatSessionOption {
+	return func(s *ChatSession) {
+		s.maxHistoryTokens = maxTokens
+	}
+}
+
+// ChatSession holds the history of a conversation with a model so callers only send the
+// new turn. Each response is recorded as an assistant message, including its tool calls;
+// the caller executes them and records the results with AddToolResults before calling
+// Continue. A ChatSession is safe for concurrent use; turns run one at a time.
+//
+//	session := llmproviders.NewChatSession(llm, llmproviders.WithSessionSystemPrompt("Be brief."))
+//	resp, err := session.Send(ctx, "What is the capital of France?")
+type ChatSession struct {
+	model            llmtypes.Model
+	callOptions      []llmtypes.CallOption
+	maxHistoryTokens int
+
+	mu          sync.Mutex
+	messages    []llmtypes.MessageContent
+	checkpoints map[CheckpointID][]llmtypes.MessageContent
+	nextID      CheckpointID
+}
+
+// CheckpointID identifies a point in a ChatSession's history saved with Checkpoint
+type CheckpointID int
+
+// NewChatSession creates a ChatSession for model, usually one from InitializeLLM
+func NewChatSession(model llmtypes.Model, opts ...ChatSessionOption) *ChatSession {
+	s := &ChatSession{model: model}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Send adds a user message with text and generates the model's answer
+func (s *ChatSession) Send(ctx context.Context, text string, options ...llmtypes.CallOption) (*llmtypes.ContentResponse, error) {
+	return s.SendMessage(ctx, llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, text), options...)
+}
+
+// SendMessage adds msg (e.g. a user message with images) and generates the model's answer.
+// If generation fails, msg is removed again so the turn can be retried.
+func (s *ChatSession) SendMessage(ctx context.Context, msg llmtypes.MessageContent, options ...llmtypes.CallOption) (*llmtypes.ContentResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous := s.messages
+	s.messages = append(s.snapshot(), msg)
+	resp, err := s.generate(ctx, options)
+	if err != nil {
+		s.messages = previous
+		return nil, err
+	}
+	return resp, nil
+}
+
+// Continue generates the next answer without adding a message, e.g. after AddToolResults
+func (s *ChatSession) Continue(ctx context.Context, options ...llmtypes.CallOption) (*llmtypes.ContentResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.generate(ctx, options)
+}
+
+// AddToolResults records the results of the tool calls of the last response
+func (s *ChatSession) AddToolResults(results ...llmtypes.ToolCallResponse) {
+	if len(results) == 0 {
+		return
+	}
+	parts := make([]llmtypes.ContentPart, 0, len(results))
+	for _, result := range results {
+		parts = append(parts, result)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.messages = append(s.messages, llmtypes.MessageContent{Role: llmtypes.ChatMessageTypeTool, Parts: parts})
+}
+
+// History returns a copy of the conversation so far
+func (s *ChatSession) History() []llmtypes.MessageContent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]llmtypes.MessageContent(nil), s.messages...)
+}
+
+// Checkpoint saves the current history so it can be restored later, e.g. to regenerate an
+// answer or edit a message and resend it. Saving is cheap: the history is shared, not copied.
+func (s *ChatSession) Checkpoint() CheckpointID {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.checkpoints == nil {
+		s.checkpoints = make(map[CheckpointID][]llmtypes.MessageContent)
+	}
+	s.nextID++
+	s.checkpoints[s.nextID] = s.snapshot()
+	return s.nextID
+}
+
+// Restore rewinds the history to the checkpoint id. Checkpoints stay valid after a restore,
+// so the same point can be restored again. Since a checkpoint holds a whole history, tool
+// calls are restored together with their results.
+func (s *ChatSession) Restore(id CheckpointID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	messages, ok := s.checkpoints[id]
+	if !ok {
+		return fmt.Errorf("chat session: unknown checkpoint %d", id)
+	}
+	s.messages = messages
+	return nil
+}
+
+// Fork returns a new session that continues from the current history with the same model
+// and options. The sessions share their common history but diverge independently;
+// checkpoints are not carried over.
+func (s *ChatSession) Fork() *ChatSession {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return &ChatSession{
+		model:            s.model,
+		callOptions:      s.callOptions[:len(s.callOptions):len(s.callOptions)],
+		maxHistoryTokens: s.maxHistoryTokens,
+		messages:         s.snapshot(),
+	}
+}
+
+// snapshot returns the history with its capacity capped, so appending to it copies the
+// messages instead of overwriting a history that shares them. Messages are never modified
+// in place. s.mu must be held.
+func (s *ChatSession) snapshot() []llmtypes.MessageContent {
+	return s.messages[:len(s.messages):len(s.messages)]
+}
+
+// generate normalizes and compacts the history, calls the model and records the answer.
+// s.mu must be held.
+func (s *ChatSession) generate(ctx context.Context, options []llmtypes.CallOption) (*llmtypes.ContentResponse, error) {
+	s.messages = history.NormalizeHistory(s.messages)
+	if s.maxHistoryTokens > 0 {
+		// A history that still doesn't fit is sent as far truncated as possible
+		result, _ := history.Truncate(s.messages, s.maxHistoryTokens, true)
+		s.messages = result.Messages
+	}
+
+	callOptions := append(append([]llmtypes.CallOption{}, s.callOptions...), options...)
+	resp, err := s.model.GenerateContent(ctx, s.messages, callOptions...)
+	if err != nil {
+		return nil, err
+	}
+	if resp == nil || len(resp.Choices) == 0 || resp.Choices[0] == nil {
+		return nil, fmt.Errorf("chat session: no choices in response")
+	}
+
+	choice := resp.Choices[0]
+	assistant := llmtypes.MessageContent{Role: llmtypes.ChatMessageTypeAI}
+	if choice.Content != "" {
+		assistant.Parts = append(assistant.Parts, llmtypes.TextContent{Text: choice.Content})
+	}
+	for _, tc := range choice.ToolCalls {
+		assistant.Parts = append(assistant.Parts, tc)
+	}
+	if len(assistant.Parts) > 0 {
+		s.messages = append(s.messages, assistant)
+	}
+	return resp, nil
+}
@@ -0,0 +1,104 @@
+package llmproviders
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+// DefaultChoiceRetries is how many times GenerateChoice retries an answer outside the
+// allowed choices when the caller doesn't set WithSchemaRetry
+const DefaultChoiceRetries = 2
+
+// choiceProperty is the property holding the answer in GenerateChoice's schema
+const choiceProperty = "choice"
+
+// GenerateChoice asks llm to answer with exactly one of choices, e.g. to classify text into
+// a fixed set of labels. The answer is constrained with a one-property enum schema through
+// WithStructuredOutput, so the best strategy for the model is used (JSON schema, forced tool
+// call or JSON mode), and answers outside the set are retried with feedback.
+//
+// llm should come from InitializeLLM; other models are asked for the same JSON and their
+// answer is matched against choices, ignoring case and surrounding whitespace.
+func GenerateChoice(ctx context.Context, llm llmtypes.Model, messages []llmtypes.MessageContent, choices []string, options ...llmtypes.CallOption) (string, error) {
+	if len(choices) == 0 {
+		return "", fmt.Errorf("GenerateChoice requires at least one choice")
+	}
+
+	schema := map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			choiceProperty: map[string]interface{}{
+				"type":        "string",
+				"enum":        choices,
+				"description": "The selected choice",
+			},
+		},
+		"required":             []string{choiceProperty},
+		"additionalProperties": false,
+	}
+	options = append([]llmtypes.CallOption{
+		llmtypes.WithStructuredOutput(schema, choiceProperty, true),
+		llmtypes.WithSchemaRetry(DefaultChoiceRetries),
+	}, options...)
+	opts := &llmtypes.CallOptions{}
+	for _, opt := range options {
+		opt(opts)
+	}
+
+	for attempt := 0; ; attempt++ {
+		resp, err := llm.GenerateContent(ctx, messages, options...)
+		if err != nil {
+			return "", err
+		}
+		if resp == nil || len(resp.Choices) == 0 || resp.Choices[0] == nil {
+			return "", fmt.Errorf("GenerateChoice: no choices in response")
+		}
+
+		content := resp.Choices[0].Content
+		if choice, ok := matchChoice(content, choices); ok {
+			return choice, nil
+		}
+		if attempt >= opts.SchemaRetries {
+			return "", fmt.Errorf("GenerateChoice: answer %q is not one of %s", truncateForError(content), strings.Join(choices, ", "))
+		}
+
+		// Models that ignore structured output get the allowed choices spelled out
+		messages = append(append([]llmtypes.MessageContent{}, messages...),
+			llmtypes.TextParts(llmtypes.ChatMessageTypeAI, content),
+			llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, fmt.Sprintf(
+				"Your answer must be exactly one of: %s. Respond only with a JSON object of the form {%q: \"<choice>\"}.",
+				strings.Join(choices, ", "), choiceProperty)),
+		)
+	}
+}
+
+// matchChoice extracts the answer from content ({"choice": ...} or plain text) and returns
+// the matching entry of choices
+func matchChoice(content string, choices []string) (string, bool) {
+	answer := strings.TrimSpace(content)
+	var parsed map[string]interface{}
+	if err := json.Unmarshal([]byte(extractJSONDocument(answer)), &parsed); err == nil {
+		value, ok := parsed[choiceProperty].(string)
+		if !ok {
+			return "", false
+		}
+		answer = strings.TrimSpace(value)
+	}
+	answer = strings.Trim(answer, "\"'`.")
+
+	for _, choice := range choices {
+		if answer == choice {
+			return choice, true
+		}
+	}
+	for _, choice := range choices {
+		if strings.EqualFold(answer, strings.TrimSpace(choice)) {
+			return choice, true
+		}
+	}
+	return "", false
+}
@@ -11,6 +11,7 @@ import (
 
 	llmproviders "github.com/manishiitg/multi-llm-provider-go"
 	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+	"github.com/manishiitg/multi-llm-provider-go/pkg/utils"
 
 	"github.com/manishiitg/multi-llm-provider-go/interfaces"
 
@@ -707,7 +708,7 @@ func handleCommand(cmd string, conv *Conversation, tools []llmtypes.Tool) error
 				if text, ok := msg.Parts[0].(llmtypes.TextContent); ok {
 					preview = text.Text
 					if len(preview) > 50 {
-						preview = preview[:50] + "..."
+						preview = utils.TruncateUTF8(preview, 50) + "..."
 					}
 				}
 			}
@@ -81,6 +81,82 @@ func main() {
 	rootCmd.AddCommand(vertexcmd.VertexEmbeddingTestCmd)
 	rootCmd.AddCommand(sharedcmd.TokenUsageTestCmd)
 	rootCmd.AddCommand(sharedcmd.TestSuiteCmd)
+	rootCmd.AddCommand(sharedcmd.TranscriptTestCmd)
+	rootCmd.AddCommand(sharedcmd.SingleFlightTestCmd)
+	rootCmd.AddCommand(sharedcmd.FineTunedTestCmd)
+	rootCmd.AddCommand(sharedcmd.BenchCmd)
+	rootCmd.AddCommand(sharedcmd.ToolCallIDsTestCmd)
+	rootCmd.AddCommand(sharedcmd.ToolNamesTestCmd)
+	rootCmd.AddCommand(sharedcmd.SchemaRefsTestCmd)
+	rootCmd.AddCommand(sharedcmd.MaxToolCallsTestCmd)
+	rootCmd.AddCommand(sharedcmd.DisableToolsTestCmd)
+	rootCmd.AddCommand(sharedcmd.StreamWriterTestCmd)
+	rootCmd.AddCommand(sharedcmd.ImageMediaTypeTestCmd)
+	rootCmd.AddCommand(sharedcmd.ImageAutoConvertTestCmd)
+	rootCmd.AddCommand(sharedcmd.ResponseUsageTestCmd)
+	rootCmd.AddCommand(sharedcmd.QuotaErrorsTestCmd)
+	rootCmd.AddCommand(sharedcmd.RegionsTestCmd)
+	rootCmd.AddCommand(sharedcmd.StreamFallbackTestCmd)
+	rootCmd.AddCommand(sharedcmd.AppAttributionTestCmd)
+	rootCmd.AddCommand(sharedcmd.RetriesTestCmd)
+	rootCmd.AddCommand(sharedcmd.AllowedToolsTestCmd)
+	rootCmd.AddCommand(sharedcmd.StreamCancelTestCmd)
+	rootCmd.AddCommand(sharedcmd.StreamStopTestCmd)
+	rootCmd.AddCommand(sharedcmd.ModelAliasesTestCmd)
+	rootCmd.AddCommand(sharedcmd.RouterTestCmd)
+	rootCmd.AddCommand(sharedcmd.ContentBlocksTestCmd)
+	rootCmd.AddCommand(sharedcmd.ToolResultSummaryTestCmd)
+	rootCmd.AddCommand(sharedcmd.ProviderErrorsTestCmd)
+	rootCmd.AddCommand(sharedcmd.VectorIndexTestCmd)
+	rootCmd.AddCommand(sharedcmd.EmptyContentRetryTestCmd)
+	rootCmd.AddCommand(sharedcmd.ContextCacheTestCmd)
+	rootCmd.AddCommand(sharedcmd.TraceIDTestCmd)
+	rootCmd.AddCommand(sharedcmd.ModelNotFoundTestCmd)
+	rootCmd.AddCommand(sharedcmd.BedrockEmbeddingsTestCmd)
+	rootCmd.AddCommand(sharedcmd.EmbeddingTaskTypeTestCmd)
+	rootCmd.AddCommand(sharedcmd.FixturesCmd)
+	rootCmd.AddCommand(sharedcmd.FixturesReplayTestCmd)
+	rootCmd.AddCommand(sharedcmd.LLMTestAssertionsTestCmd)
+	rootCmd.AddCommand(sharedcmd.ConformanceCmd)
+	rootCmd.AddCommand(sharedcmd.LLMTestConformanceTestCmd)
+	rootCmd.AddCommand(sharedcmd.StreamEventHookTestCmd)
+	rootCmd.AddCommand(sharedcmd.RequiredToolArgsTestCmd)
+	rootCmd.AddCommand(sharedcmd.PromptDebugTestCmd)
+	rootCmd.AddCommand(sharedcmd.ReasoningTagsTestCmd)
+	rootCmd.AddCommand(sharedcmd.JSONExtractionTestCmd)
+	rootCmd.AddCommand(sharedcmd.TemperatureTestCmd)
+	rootCmd.AddCommand(sharedcmd.MaxTokensTestCmd)
+	rootCmd.AddCommand(sharedcmd.ProviderParamsTestCmd)
+	rootCmd.AddCommand(sharedcmd.OptionValidationTestCmd)
+	rootCmd.AddCommand(sharedcmd.ValidateOptionsTestCmd)
+	rootCmd.AddCommand(sharedcmd.ImagePayloadTestCmd)
+	rootCmd.AddCommand(sharedcmd.ParsedJSONTestCmd)
+	rootCmd.AddCommand(sharedcmd.OpenAIToolResultsTestCmd)
+	rootCmd.AddCommand(sharedcmd.GeminiToolResultsTestCmd)
+	rootCmd.AddCommand(sharedcmd.ResponseValidationTestCmd)
+	rootCmd.AddCommand(sharedcmd.RetryDiversityTestCmd)
+	rootCmd.AddCommand(sharedcmd.StreamStallTestCmd)
+	rootCmd.AddCommand(sharedcmd.DefaultSystemPromptTestCmd)
+	rootCmd.AddCommand(sharedcmd.MessageTransformTestCmd)
+	rootCmd.AddCommand(sharedcmd.StreamUTF8TestCmd)
+	rootCmd.AddCommand(sharedcmd.InterceptorsTestCmd)
+	rootCmd.AddCommand(sharedcmd.TranslateRoundTripTestCmd)
+	rootCmd.AddCommand(sharedcmd.StreamAggregatorTestCmd)
+	rootCmd.AddCommand(sharedcmd.RedactTestCmd)
+	rootCmd.AddCommand(sharedcmd.ExtraBodyTestCmd)
+	rootCmd.AddCommand(sharedcmd.ServiceTierTestCmd)
+	rootCmd.AddCommand(sharedcmd.HTTPServerTestCmd)
+	rootCmd.AddCommand(sharedcmd.HistoryTruncateTestCmd)
+	rootCmd.AddCommand(sharedcmd.MaxInputTokensTestCmd)
+	rootCmd.AddCommand(sharedcmd.AgentLoopTestCmd)
+	rootCmd.AddCommand(sharedcmd.AgentConcurrentToolsTestCmd)
+	rootCmd.AddCommand(sharedcmd.AgentObserverTestCmd)
+	rootCmd.AddCommand(sharedcmd.MultipleChoicesTestCmd)
+	rootCmd.AddCommand(sharedcmd.AbortOnToolCallTestCmd)
+	rootCmd.AddCommand(sharedcmd.AssistantPrefillTestCmd)
+	rootCmd.AddCommand(sharedcmd.AutoContinueTestCmd)
+	rootCmd.AddCommand(sharedcmd.ToolEmulationTestCmd)
+	rootCmd.AddCommand(sharedcmd.ChatSessionTestCmd)
 
 	if err := rootCmd.Execute(); err != nil {
 		os.Exit(1)
@@ -0,0 +1,29 @@
+package llmproviders
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+// Complete implements llmtypes.CompletionModel by sending a raw prompt to the underlying
+// model's completions endpoint: OpenAI-compatible /completions for OpenAI and OpenRouter,
+// InvokeModel for Bedrock text models. Other providers only accept chat messages and
+// return an error.
+func (p *ProviderAwareLLM) Complete(ctx context.Context, prompt string, options ...llmtypes.CallOption) (*llmtypes.ContentResponse, error) {
+	model, ok := p.Model.(llmtypes.CompletionModel)
+	if !ok {
+		return nil, fmt.Errorf("raw prompt completions are not supported for provider %s", p.provider)
+	}
+
+	start := time.Now()
+	resp, err := model.Complete(ctx, prompt, options...)
+	if err != nil {
+		p.logger.Infof("❌ Completion failed - provider: %s, model: %s, error: %v", string(p.provider), p.modelID, err)
+		return nil, err
+	}
+	p.logger.Infof("⏱️  Completion received - provider: %s, model: %s, duration: %v", string(p.provider), p.modelID, time.Since(start))
+	return resp, nil
+}
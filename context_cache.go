@@ -0,0 +1,36 @@
+package llmproviders
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+// CreateCachedContent implements llmtypes.ContextCacheModel by caching messages on the
+// provider for ttl, so later calls reference them with WithCachedContent instead of
+// resending them. Config.DefaultSystemPrompt is applied to messages as for calls. Only Gemini supports explicit context caching; other providers return an
+// error (Anthropic caches inline with cache_control instead).
+func (p *ProviderAwareLLM) CreateCachedContent(ctx context.Context, messages []llmtypes.MessageContent, ttl time.Duration, options ...llmtypes.CallOption) (llmtypes.CacheName, error) {
+	model, ok := p.Model.(llmtypes.ContextCacheModel)
+	if !ok {
+		return "", fmt.Errorf("explicit context caching is not supported for provider %s", p.provider)
+	}
+	name, err := model.CreateCachedContent(ctx, p.withDefaultSystemPrompt(messages), ttl, options...)
+	if err != nil {
+		p.logger.Infof("❌ Creating cached content failed - provider: %s, model: %s, error: %v", string(p.provider), p.modelID, err)
+		return "", newProviderError(p.provider, p.modelID, err)
+	}
+	return name, nil
+}
+
+// DeleteCachedContent implements llmtypes.ContextCacheModel by deleting a cache created with
+// CreateCachedContent before it expires
+func (p *ProviderAwareLLM) DeleteCachedContent(ctx context.Context, name llmtypes.CacheName) error {
+	model, ok := p.Model.(llmtypes.ContextCacheModel)
+	if !ok {
+		return fmt.Errorf("explicit context caching is not supported for provider %s", p.provider)
+	}
+	return model.DeleteCachedContent(ctx, name)
+}
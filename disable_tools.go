@@ -0,0 +1,42 @@
+package llmproviders
+
+import (
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+// disableTools rewrites a call so the model answers in text (WithDisableTools). Tools are
+// dropped unless the history holds tool calls or results: Anthropic and Bedrock reject such
+// a history without tool definitions, so the tools are kept with tool choice "none".
+// Bedrock has no "none" choice, so there the tool history is sent as text instead.
+func disableTools(provider Provider, messages []llmtypes.MessageContent, options []llmtypes.CallOption) ([]llmtypes.MessageContent, []llmtypes.CallOption) {
+	keepTools := false
+	if hasToolHistory(messages) {
+		if provider == ProviderBedrock {
+			messages = emulateToolHistory(messages)
+		} else {
+			keepTools = true
+		}
+	}
+	options = append(append([]llmtypes.CallOption{}, options...), func(o *llmtypes.CallOptions) {
+		if keepTools {
+			o.ToolChoice = &llmtypes.ToolChoice{Type: "none", None: true}
+			return
+		}
+		o.Tools = nil
+		o.ToolChoice = nil
+	})
+	return messages, options
+}
+
+// hasToolHistory reports whether messages contain tool calls or tool results
+func hasToolHistory(messages []llmtypes.MessageContent) bool {
+	for _, msg := range messages {
+		for _, part := range msg.Parts {
+			switch part.(type) {
+			case llmtypes.ToolCall, llmtypes.ToolCallResponse:
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -24,6 +24,10 @@ const (
 	CapabilityTextGeneration = "text_generation"
 	CapabilityToolCalling    = "tool_calling"
 	CapabilityStreaming      = "streaming"
+	CapabilityVision         = "vision"
+	CapabilityJSONSchema     = "json_schema"
+	CapabilityReasoning      = "reasoning"
+	CapabilityEmbeddings     = "embeddings"
 )
 
 // TokenUsage represents token consumption information
@@ -0,0 +1,188 @@
+package llmproviders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+	"github.com/manishiitg/multi-llm-provider-go/pkg/utils"
+	openaisdk "github.com/openai/openai-go/v3"
+	"google.golang.org/genai"
+)
+
+// retryableErrorMarkers identify transient failures in error messages that carry no status code
+var retryableErrorMarkers = []string{
+	"429", "rate limit", "too many requests", "throttl", "overloaded",
+	"resource_exhausted", "resource exhausted", "service unavailable", "bad gateway",
+	"gateway timeout", "internal server error", "status 500", "status 502", "status 503", "status 504",
+}
+
+// IsRetryableError reports whether err is a transient provider failure worth retrying on
+// another model: rate limiting (429), overload (529) and server errors (5xx). Cancellation,
+// deadlines, request errors (4xx) and exhausted quota or credits (IsQuotaError, also a 429
+// for some providers) are not retryable.
+func IsRetryableError(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) || IsQuotaError(err) {
+		return false
+	}
+	if status := errorStatusCode(err); status != 0 {
+		return status == http.StatusTooManyRequests || status >= 500
+	}
+	message := strings.ToLower(err.Error())
+	for _, marker := range retryableErrorMarkers {
+		if strings.Contains(message, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// errorStatusCode returns the HTTP status code of a provider SDK error, or 0 if unknown
+func errorStatusCode(err error) int {
+	var openaiErr *openaisdk.Error
+	if errors.As(err, &openaiErr) {
+		return openaiErr.StatusCode
+	}
+	var anthropicErr *anthropic.Error
+	if errors.As(err, &anthropicErr) {
+		return anthropicErr.StatusCode
+	}
+	var genaiErr genai.APIError
+	if errors.As(err, &genaiErr) {
+		return genaiErr.Code
+	}
+	var httpErr interface{ HTTPStatusCode() int }
+	if errors.As(err, &httpErr) {
+		return httpErr.HTTPStatusCode()
+	}
+	return 0
+}
+
+// callModel calls the underlying model, through generateWithFallback when the call has
+// fallback models or is retried by the wrapper (see retrySettings and WithRetryOnEmptyContent).
+// Each attempt runs the interceptors (generateAttempt).
+func (p *ProviderAwareLLM) callModel(ctx context.Context, messages []llmtypes.MessageContent, options []llmtypes.CallOption, opts *llmtypes.CallOptions) (*llmtypes.ContentResponse, error) {
+	retries, policy, retried := p.retrySettings(opts)
+	if retried {
+		// Tell adapters the wrapper owns retries so the SDK doesn't retry as well
+		options = append(append([]llmtypes.CallOption{}, options...), llmtypes.WithMaxRetries(retries))
+	}
+	if len(opts.FallbackModels) > 0 || retries > 0 || opts.RetryOnEmptyContent > 0 {
+		return p.generateWithFallback(ctx, messages, options, opts.FallbackModels, opts.StreamChan, retries, policy, opts.RetryOnEmptyContent)
+	}
+	return p.generateAttempt(ctx, messages, options)
+}
+
+// isEmptyResponse reports whether resp, from a successful call, has no content and no tool
+// calls for a reason other than a content filter (utils.IsContentFilterStopReason)
+func isEmptyResponse(resp *llmtypes.ContentResponse) bool {
+	if resp == nil || len(resp.Choices) == 0 || resp.Choices[0] == nil {
+		return true
+	}
+	choice := resp.Choices[0]
+	return choice.Content == "" && len(choice.ToolCalls) == 0 && choice.FuncCall == nil && !utils.IsContentFilterStopReason(choice.StopReason)
+}
+
+// generateWithFallback calls the model, retrying a failed attempt up to retries times as
+// policy allows, and, while the call still fails with a retryable error, repeats it with
+// each of fallbackModels in order (WithModel on the same provider, so quota errors, which
+// affect the whole account, end the loop). Tools and other options are kept. When
+// streaming, each attempt streams through its own channel and chunks are forwarded to
+// streamChan, which is closed on return; once an attempt has forwarded output (content or a
+// tool call), its failure is returned rather than retried or falling back, so the caller
+// never receives a mix of two responses. Other chunks, such as reasoning, usage and the
+// finish chunk, are held until the attempt forwards output or is the one returned. A
+// successful attempt with an empty response (isEmptyResponse) is repeated up to
+// emptyRetries times over the whole call, unless it forwarded output.
+func (p *ProviderAwareLLM) generateWithFallback(ctx context.Context, messages []llmtypes.MessageContent, options []llmtypes.CallOption, fallbackModels []string, streamChan chan<- llmtypes.StreamChunk, retries int, policy llmtypes.RetryPolicy, emptyRetries int) (*llmtypes.ContentResponse, error) {
+	if streamChan != nil {
+		defer close(streamChan)
+	}
+
+	models := append([]string{""}, fallbackModels...)
+	var lastErr error
+	emptyAttempts := 0
+	for i, model := range models {
+		callOptions := options
+		if model != "" {
+			callOptions = append(append([]llmtypes.CallOption{}, options...), llmtypes.WithModel(model))
+		}
+
+		var err error
+		for attempt := 0; ; attempt++ {
+			var resp *llmtypes.ContentResponse
+			forwarded := false
+			var held []llmtypes.StreamChunk
+			send := func(chunk llmtypes.StreamChunk) {
+				select {
+				case streamChan <- chunk:
+				case <-ctx.Done():
+				}
+			}
+			release := func() {
+				for _, chunk := range held {
+					send(chunk)
+				}
+				held = nil
+			}
+			if streamChan == nil {
+				resp, err = p.generateAttempt(ctx, messages, callOptions)
+			} else {
+				resp, err = utils.GenerateStreaming(ctx, attemptModel{p}, messages, callOptions, func(chunk llmtypes.StreamChunk) {
+					output := (chunk.Type == llmtypes.StreamChunkTypeContent && chunk.Content != "") || chunk.Type == llmtypes.StreamChunkTypeToolCall
+					if !forwarded && !output {
+						held = append(held, chunk)
+						return
+					}
+					forwarded = true
+					release()
+					send(chunk)
+				})
+			}
+			if err == nil && emptyAttempts < emptyRetries && !forwarded && isEmptyResponse(resp) {
+				emptyAttempts++
+				p.logger.Infof("🔁 Empty response with no content or tool calls, retrying (%d/%d)", emptyAttempts, emptyRetries)
+				attempt--
+				continue
+			}
+			if err == nil {
+				release()
+				if i > 0 {
+					p.logger.Infof("✅ Fallback model %s succeeded after %d failed attempts", model, i)
+				}
+				return resp, nil
+			}
+			if forwarded || ctx.Err() != nil {
+				release()
+				return nil, err
+			}
+			if attempt >= retries || !policy.Retryable(err) {
+				break
+			}
+			delay := retryDelay(policy, attempt, err)
+			p.logger.Infof("🔁 Retrying in %v (%d/%d) - error: %v", delay, attempt+1, retries, err)
+			if sleepErr := sleepContext(ctx, delay); sleepErr != nil {
+				return nil, err
+			}
+		}
+		if model != "" {
+			err = modelNotFoundError(p.provider, model, err)
+		}
+		if !IsRetryableError(err) && !IsModelNotFoundError(err) {
+			return nil, err
+		}
+
+		lastErr = err
+		if i+1 < len(models) {
+			p.logger.Infof("🔄 Retryable error, falling back to model %s (%d/%d) - error: %v", models[i+1], i+1, len(fallbackModels), err)
+		}
+	}
+	if len(fallbackModels) == 0 {
+		return nil, lastErr
+	}
+	return nil, fmt.Errorf("all %d fallback models failed: %w", len(fallbackModels), lastErr)
+}
@@ -0,0 +1,101 @@
+package llmproviders
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+// generateAttempt sends one attempt of a call to the provider: the first call, a retry, a
+// fallback model or a follow-up such as a schema retry. The request interceptors run on the
+// messages and options of the attempt, and the response interceptors on its response.
+func (p *ProviderAwareLLM) generateAttempt(ctx context.Context, messages []llmtypes.MessageContent, options []llmtypes.CallOption) (*llmtypes.ContentResponse, error) {
+	opts := reparse(options)
+	modelID := p.modelID
+	if opts.Model != "" {
+		modelID = opts.Model
+	}
+
+	// Run request interceptors in order; they may rewrite messages, append options or set headers
+	if len(opts.RequestInterceptors) > 0 {
+		req := &llmtypes.ProviderRequest{
+			Provider: string(p.provider),
+			ModelID:  modelID,
+			Messages: messages,
+			Options:  append([]llmtypes.CallOption{}, options...),
+			Headers:  make(map[string]string),
+		}
+		for i, interceptor := range opts.RequestInterceptors {
+			if err := interceptor(req); err != nil {
+				p.logger.Infof("❌ Request interceptor %d rejected request - provider: %s, model: %s, error: %v", i+1, string(p.provider), modelID, err)
+				return nil, fmt.Errorf("request interceptor %d: %w", i+1, err)
+			}
+		}
+		messages = req.Messages
+		options = req.Options
+		if len(req.Headers) > 0 {
+			options = append(options, llmtypes.WithExtraHeaders(req.Headers))
+		}
+	}
+
+	if sent, ok := ctx.Value(sentMessagesKey{}).(*sentMessages); ok {
+		sent.record(messages)
+	}
+	p.logRequest(messages, reparse(options))
+
+	resp, err := p.Model.GenerateContent(ctx, messages, options...)
+	if err != nil || resp == nil {
+		return resp, err
+	}
+
+	// Run response interceptors in order
+	for i, interceptor := range opts.ResponseInterceptors {
+		if err := interceptor(resp); err != nil {
+			p.logger.Infof("❌ Response interceptor %d rejected response - provider: %s, model: %s, error: %v", i+1, string(p.provider), modelID, err)
+			return nil, fmt.Errorf("response interceptor %d: %w", i+1, err)
+		}
+	}
+	return resp, nil
+}
+
+// attemptModel is the model of a ProviderAwareLLM as seen by one attempt (generateAttempt),
+// for helpers that take an llmtypes.Model
+type attemptModel struct {
+	p *ProviderAwareLLM
+}
+
+func (m attemptModel) GenerateContent(ctx context.Context, messages []llmtypes.MessageContent, options ...llmtypes.CallOption) (*llmtypes.ContentResponse, error) {
+	return m.p.generateAttempt(ctx, messages, options)
+}
+
+func (m attemptModel) GetModelID() string {
+	return m.p.GetModelID()
+}
+
+type sentMessagesKey struct{}
+
+// sentMessages records the messages of a call's latest attempt after the request interceptors,
+// so the call's logs and events show what the provider received rather than what the caller
+// passed in (a redacting interceptor would otherwise be bypassed)
+type sentMessages struct {
+	mu       sync.Mutex
+	messages []llmtypes.MessageContent
+}
+
+func withSentMessages(ctx context.Context, sent *sentMessages) context.Context {
+	return context.WithValue(ctx, sentMessagesKey{}, sent)
+}
+
+func (s *sentMessages) record(messages []llmtypes.MessageContent) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.messages = messages
+}
+
+func (s *sentMessages) get() []llmtypes.MessageContent {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.messages
+}
@@ -69,3 +69,15 @@ type EventEmitter interface {
 	// Tool call events
 	EmitToolCallDetected(provider string, modelID string, toolCallID string, toolName string, arguments string, traceID TraceID, metadata LLMMetadata)
 }
+
+// InputTrimEmitter is an optional EventEmitter extension notified when WithMaxInputTokens
+// trims the history before a request
+type InputTrimEmitter interface {
+	EmitInputTrimmed(provider string, modelID string, droppedMessages int, reclaimedTokens int, inputTokens int, traceID TraceID, metadata LLMMetadata)
+}
+
+// ToolCallTruncateEmitter is an optional EventEmitter extension notified when
+// WithMaxToolCallsPerResponse drops tool calls from a response
+type ToolCallTruncateEmitter interface {
+	EmitToolCallsTruncated(provider string, modelID string, receivedToolCalls int, keptToolCalls int, traceID TraceID, metadata LLMMetadata)
+}
@@ -194,6 +194,16 @@ func LoadResponseByRequest(config RecordingConfig, request RequestInfo) (*Record
 	return nil, fmt.Errorf("no recorded response found matching request hash: %s", currentHash)
 }
 
+// RecordingFiles returns the recorded response files of config's test, provider and model
+func RecordingFiles(config RecordingConfig) ([]string, error) {
+	baseDir := config.BaseDir
+	if baseDir == "" {
+		baseDir = "testdata"
+	}
+	pattern := fmt.Sprintf("%s_%s_*.json", config.TestName, sanitizeForFilename(config.ModelID))
+	return filepath.Glob(filepath.Join(baseDir, config.Provider, pattern))
+}
+
 // generateFilename creates a filename from test name and model ID
 func generateFilename(testName, modelID string) string {
 	// Sanitize model ID for filename (replace special chars)
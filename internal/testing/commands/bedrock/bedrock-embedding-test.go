@@ -26,7 +26,7 @@ type bedrockEmbeddingTestFlags struct {
 var bedrockEmbeddingFlags bedrockEmbeddingTestFlags
 
 func init() {
-	BedrockEmbeddingTestCmd.Flags().StringVar(&bedrockEmbeddingFlags.model, "model", "", "Bedrock embedding model to test (default: amazon.titan-embed-text-v1)")
+	BedrockEmbeddingTestCmd.Flags().StringVar(&bedrockEmbeddingFlags.model, "model", "", "Bedrock embedding model to test, Titan or Cohere (default: amazon.titan-embed-text-v1)")
 }
 
 func runBedrockEmbeddingTest(cmd *cobra.Command, args []string) {
@@ -0,0 +1,181 @@
+package shared
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	llmproviders "github.com/manishiitg/multi-llm-provider-go"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+	"github.com/manishiitg/multi-llm-provider-go/pkg/utils"
+
+	"github.com/spf13/cobra"
+)
+
+// AbortOnToolCallTestCmd checks that WithAbortOnToolCall stops streaming at the first tool call
+var AbortOnToolCallTestCmd = &cobra.Command{
+	Use:   "abort-on-tool-call",
+	Short: "Test that WithAbortOnToolCall cancels a streaming request at the first tool call",
+	Long: `This test streams from fake models and a fake Anthropic endpoint that keep generating
+after a tool call, and checks that with WithAbortOnToolCall:
+- the request is cancelled once the first complete tool call is streamed
+- the response holds the content streamed so far and that tool call, with StopReason
+  "tool_calls" and no usage
+- the stream receives the content, the tool call and a finish chunk, and is closed
+- a response without tool calls is returned unchanged
+- the caller cancelling the call returns the context error
+
+No API calls are made.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !RunAbortOnToolCallTest() {
+			os.Exit(1)
+		}
+	},
+}
+
+// anthropicToolCallStream is an Anthropic event stream with some text and a complete tool
+// call, which the fake endpoint follows by hanging as if more calls were being generated
+const anthropicToolCallStream = "event: message_start\n" +
+	`data: {"type":"message_start","message":{"id":"msg_1","type":"message","role":"assistant","model":"claude-sonnet-4-20250514","content":[],"stop_reason":null,"usage":{"input_tokens":1,"output_tokens":1}}}` + "\n\n" +
+	"event: content_block_start\n" + `data: {"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}` + "\n\n" +
+	"event: content_block_delta\n" + `data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Let me check."}}` + "\n\n" +
+	"event: content_block_stop\n" + `data: {"type":"content_block_stop","index":0}` + "\n\n" +
+	"event: content_block_start\n" + `data: {"type":"content_block_start","index":1,"content_block":{"type":"tool_use","id":"toolu_1","name":"get_weather","input":{}}}` + "\n\n" +
+	"event: content_block_delta\n" + `data: {"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":"{\"city\":\"Paris\"}"}}` + "\n\n" +
+	"event: content_block_stop\n" + `data: {"type":"content_block_stop","index":1}` + "\n\n"
+
+// describeStream renders the chunks of a stream, for comparisons
+func describeStream(streamChan <-chan llmtypes.StreamChunk) string {
+	var parts []string
+	for chunk := range streamChan {
+		switch chunk.Type {
+		case llmtypes.StreamChunkTypeContent:
+			parts = append(parts, fmt.Sprintf("content(%s)", chunk.Content))
+		case llmtypes.StreamChunkTypeToolCall:
+			parts = append(parts, fmt.Sprintf("call(%s)", chunk.ToolCall.FunctionCall.Name))
+		case llmtypes.StreamChunkTypeFinish:
+			parts = append(parts, fmt.Sprintf("finish(%s)", chunk.StopReason))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// RunAbortOnToolCallTest verifies that streaming stops at the first tool call
+func RunAbortOnToolCallTest() bool {
+	log.Printf("\n🛑 Test: Abort On Tool Call")
+
+	messages := []llmtypes.MessageContent{llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "Weather in Paris and Rome?")}
+	passed := true
+
+	// A model that streams a tool call, then keeps generating until cancelled
+	cancelled := make(chan struct{}, 1)
+	model := fakeModelFunc(func(ctx context.Context, messages []llmtypes.MessageContent, opts *llmtypes.CallOptions) (*llmtypes.ContentResponse, error) {
+		defer close(opts.StreamChan)
+		call := toolCall("call_1", "get_weather", `{"city":"Paris"}`)
+		opts.StreamChan <- llmtypes.StreamChunk{Type: llmtypes.StreamChunkTypeContent, Content: "Let me check."}
+		opts.StreamChan <- llmtypes.StreamChunk{Type: llmtypes.StreamChunkTypeToolCall, ToolCall: &call}
+		select {
+		case <-ctx.Done():
+			cancelled <- struct{}{}
+			return nil, ctx.Err()
+		case <-time.After(5 * time.Second):
+			return nil, fmt.Errorf("the request was not cancelled")
+		}
+	})
+	streamChan := make(chan llmtypes.StreamChunk, 10)
+	start := time.Now()
+	resp, err := utils.GenerateUntilToolCall(context.Background(), model, messages, []llmtypes.CallOption{llmtypes.WithStreamingChan(streamChan), llmtypes.WithAbortOnToolCall()})
+	elapsed := time.Since(start)
+	stream := describeStream(streamChan)
+	if err != nil || len(cancelled) != 1 || elapsed > time.Second {
+		log.Printf("❌ Expected the request cancelled at the tool call, got error %v after %v", err, elapsed)
+		passed = false
+	} else if choice := resp.Choices[0]; choice.Content != "Let me check." || len(choice.ToolCalls) != 1 || choice.StopReason != "tool_calls" || resp.Usage != nil {
+		log.Printf("❌ Expected the streamed content and tool call with stop reason tool_calls and no usage, got %q, %d calls, %q", choice.Content, len(choice.ToolCalls), choice.StopReason)
+		passed = false
+	} else if stream != "content(Let me check.) call(get_weather) finish(tool_calls)" {
+		log.Printf("❌ Expected the stream to end with the tool call and a finish chunk, got %s", stream)
+		passed = false
+	} else {
+		log.Printf("✅ The request is cancelled at the first tool call (%v) and the stream ends: %s", elapsed.Round(time.Millisecond), stream)
+	}
+
+	// A response without tool calls is returned unchanged
+	textModel := fakeModelFunc(func(ctx context.Context, messages []llmtypes.MessageContent, opts *llmtypes.CallOptions) (*llmtypes.ContentResponse, error) {
+		resp := streamChoice(opts, &llmtypes.ContentChoice{Content: "Sunny in both.", StopReason: "stop"}, "Sunny ", "in both.")
+		resp.Usage = &llmtypes.Usage{InputTokens: 5, OutputTokens: 4, TotalTokens: 9}
+		return resp, nil
+	})
+	streamChan = make(chan llmtypes.StreamChunk, 10)
+	resp, err = utils.GenerateUntilToolCall(context.Background(), textModel, messages, []llmtypes.CallOption{llmtypes.WithStreamingChan(streamChan), llmtypes.WithAbortOnToolCall()})
+	stream = describeStream(streamChan)
+	if err != nil || resp.Choices[0].StopReason != "stop" || resp.Usage == nil || stream != "content(Sunny ) content(in both.) finish(stop)" {
+		log.Printf("❌ Expected the text response unchanged with its usage, got %s (error %v)", stream, err)
+		passed = false
+	} else {
+		log.Printf("✅ A response without tool calls is returned unchanged")
+	}
+
+	// The caller cancelling returns the context error
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	slowModel := fakeModelFunc(func(ctx context.Context, messages []llmtypes.MessageContent, opts *llmtypes.CallOptions) (*llmtypes.ContentResponse, error) {
+		defer close(opts.StreamChan)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	streamChan = make(chan llmtypes.StreamChunk, 10)
+	_, err = utils.GenerateUntilToolCall(ctx, slowModel, messages, []llmtypes.CallOption{llmtypes.WithStreamingChan(streamChan), llmtypes.WithAbortOnToolCall()})
+	describeStream(streamChan)
+	if err == nil || ctx.Err() == nil {
+		log.Printf("❌ Expected the context error, got %v", err)
+		passed = false
+	} else {
+		log.Printf("✅ Cancelling the call returns the context error: %v", err)
+	}
+
+	// End to end through the Anthropic adapter
+	disconnected := make(chan struct{}, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, anthropicToolCallStream)
+		w.(http.Flusher).Flush()
+		select {
+		case <-r.Context().Done():
+			disconnected <- struct{}{}
+		case <-time.After(5 * time.Second):
+		}
+	}))
+	defer server.Close()
+	target, _ := url.Parse(server.URL)
+	apiKey := "test"
+	llm, err := llmproviders.InitializeLLM(llmproviders.Config{Provider: llmproviders.ProviderAnthropic, ModelID: "claude-sonnet-4-20250514",
+		APIKeys: &llmproviders.ProviderAPIKeys{Anthropic: &apiKey}, HTTPClient: &http.Client{Transport: redirectTransport{target: target}}})
+	streamChan = make(chan llmtypes.StreamChunk, 10)
+	start = time.Now()
+	if err == nil {
+		resp, err = llm.GenerateContent(context.Background(), messages, llmtypes.WithStreamingChan(streamChan), llmproviders.WithAbortOnToolCall())
+	}
+	elapsed = time.Since(start)
+	stream = describeStream(streamChan)
+	select {
+	case <-disconnected:
+	case <-time.After(time.Second):
+	}
+	if err != nil || elapsed > 2*time.Second || len(resp.Choices[0].ToolCalls) != 1 || resp.Choices[0].ToolCalls[0].FunctionCall.Arguments != `{"city":"Paris"}` {
+		log.Printf("❌ Anthropic: expected the Paris tool call without waiting for the stream to end, got error %v after %v", err, elapsed)
+		passed = false
+	} else if !strings.HasSuffix(stream, "call(get_weather) finish(tool_calls)") {
+		log.Printf("❌ Anthropic: expected the stream to end with the tool call and a finish chunk, got %s", stream)
+		passed = false
+	} else {
+		log.Printf("✅ Anthropic: the request is cancelled at the tool call (%v): %s", elapsed.Round(time.Millisecond), stream)
+	}
+	return passed
+}
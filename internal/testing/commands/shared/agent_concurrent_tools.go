@@ -0,0 +1,150 @@
+package shared
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+	"github.com/manishiitg/multi-llm-provider-go/pkg/agent"
+
+	"github.com/spf13/cobra"
+)
+
+// AgentConcurrentToolsTestCmd checks that agent.Loop runs the tool calls of a turn concurrently
+var AgentConcurrentToolsTestCmd = &cobra.Command{
+	Use:   "agent-concurrent-tools",
+	Short: "Test that agent.Loop runs parallel tool calls concurrently, up to WithMaxConcurrentTools",
+	Long: `This test drives agent.Loop with a fake model requesting several tool calls in one turn
+and checks that:
+- the calls run concurrently, never more at once than WithMaxConcurrentTools, and at most
+  DefaultMaxConcurrentTools without the option
+- WithMaxConcurrentTools(1) runs them one at a time
+- the results are sent back in the order of the calls, whichever finishes first
+- cancelling the context ends the loop with the context error, and calls still waiting to
+  run get errored results
+
+No API calls are made.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !RunAgentConcurrentToolsTest() {
+			os.Exit(1)
+		}
+	},
+}
+
+// concurrencyGauge records the most tool handlers running at once
+type concurrencyGauge struct {
+	mu      sync.Mutex
+	running int
+	peak    int
+}
+
+// tool returns a handler that sleeps longer for lower n, so later calls finish first, and
+// answers n
+func (g *concurrencyGauge) tool() agent.ToolFunc {
+	return func(ctx context.Context, args json.RawMessage) (string, error) {
+		var in struct{ N int }
+		if err := json.Unmarshal(args, &in); err != nil {
+			return "", err
+		}
+		g.mu.Lock()
+		g.running++
+		g.peak = max(g.peak, g.running)
+		g.mu.Unlock()
+		defer func() {
+			g.mu.Lock()
+			g.running--
+			g.mu.Unlock()
+		}()
+		select {
+		case <-time.After(time.Duration(10-in.N) * 10 * time.Millisecond):
+			return fmt.Sprintf("%d", in.N), nil
+		case <-ctx.Done():
+			return "", ctx.Err()
+		}
+	}
+}
+
+// parallelCallsModel requests count tool calls in its first turn, then answers
+func parallelCallsModel(count int, fedBack *[]llmtypes.ToolCallResponse) llmtypes.Model {
+	return fakeModelFunc(func(ctx context.Context, messages []llmtypes.MessageContent, opts *llmtypes.CallOptions) (*llmtypes.ContentResponse, error) {
+		if len(messages) == 1 {
+			calls := make([]llmtypes.ToolCall, count)
+			for i := range calls {
+				calls[i] = toolCall(fmt.Sprintf("call_%d", i), "work", fmt.Sprintf(`{"n":%d}`, i))
+			}
+			return toolCallResponse(calls...), nil
+		}
+		*fedBack = toolResultsOf(messages[len(messages)-1])
+		return textResponse("done"), nil
+	})
+}
+
+// RunAgentConcurrentToolsTest verifies how agent.Loop runs the tool calls of one turn
+func RunAgentConcurrentToolsTest() bool {
+	log.Printf("\n🧵 Test: Agent Concurrent Tools")
+
+	messages := []llmtypes.MessageContent{llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "Do the work")}
+	passed := true
+	for _, c := range []struct {
+		name    string
+		calls   int
+		options []agent.Option
+		peak    int
+	}{
+		{"WithMaxConcurrentTools(2)", 5, []agent.Option{agent.WithMaxConcurrentTools(2)}, 2},
+		{"WithMaxConcurrentTools(1)", 3, []agent.Option{agent.WithMaxConcurrentTools(1)}, 1},
+		{"default", 6, nil, agent.DefaultMaxConcurrentTools},
+	} {
+		gauge := &concurrencyGauge{}
+		var fedBack []llmtypes.ToolCallResponse
+		loop := agent.NewLoop(parallelCallsModel(c.calls, &fedBack), map[string]agent.ToolFunc{"work": gauge.tool()}, c.options...)
+		_, err := loop.Run(context.Background(), messages)
+		var order []string
+		for _, result := range fedBack {
+			order = append(order, result.ToolCallID+"="+result.Content)
+		}
+		want := make([]string, c.calls)
+		for i := range want {
+			want[i] = fmt.Sprintf("call_%d=%d", i, i)
+		}
+		if err != nil || gauge.peak != c.peak || strings.Join(order, " ") != strings.Join(want, " ") {
+			log.Printf("❌ %s: expected %d tools at once and results %v, got %d at once and %v (error %v)", c.name, c.peak, want, gauge.peak, order, err)
+			passed = false
+			continue
+		}
+		log.Printf("✅ %s: %d calls ran at most %d at a time, results in call order", c.name, c.calls, gauge.peak)
+	}
+
+	// Cancel while the first two of four calls are running
+	gauge := &concurrencyGauge{}
+	var fedBack []llmtypes.ToolCallResponse
+	ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+	defer cancel()
+	loop := agent.NewLoop(parallelCallsModel(4, &fedBack), map[string]agent.ToolFunc{"work": gauge.tool()}, agent.WithMaxConcurrentTools(2))
+	result, err := loop.Run(ctx, messages)
+	if err == nil || ctx.Err() == nil || !strings.Contains(err.Error(), ctx.Err().Error()) {
+		log.Printf("❌ Cancelled: expected the context error, got %v", err)
+		passed = false
+	} else {
+		results := toolResultsOf(result.Messages[len(result.Messages)-1])
+		errored := 0
+		for _, r := range results {
+			if r.IsError {
+				errored++
+			}
+		}
+		if len(results) != 4 || errored != 4 {
+			log.Printf("❌ Cancelled: expected 4 errored tool results in the transcript, got %d of %d", errored, len(results))
+			passed = false
+		} else {
+			log.Printf("✅ Cancelling ends the loop (%v) with errored results for every call", err)
+		}
+	}
+	return passed
+}
@@ -0,0 +1,150 @@
+package shared
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+	"github.com/manishiitg/multi-llm-provider-go/pkg/agent"
+
+	"github.com/spf13/cobra"
+)
+
+// AgentLoopTestCmd checks that agent.Loop runs tools until the model answers, within its limit
+var AgentLoopTestCmd = &cobra.Command{
+	Use:   "agent-loop",
+	Short: "Test that agent.Loop runs tool calls until the model answers, up to WithMaxToolIterations",
+	Long: `This test drives agent.Loop with fake models and checks that:
+- tool calls are executed and their results fed back until the model answers in text
+- the result holds the final content, the number of tool-calling turns and the full transcript
+- a model that keeps calling tools is stopped after WithMaxToolIterations turns with an
+  error, returning the transcript so far, and DefaultMaxToolIterations applies otherwise
+- unknown tools and tool errors are sent back to the model as errored tool results
+- a model error ends the loop with an error naming the turn
+
+No API calls are made.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !RunAgentLoopTest() {
+			os.Exit(1)
+		}
+	},
+}
+
+// addTool adds the integers a and b
+func addTool(ctx context.Context, args json.RawMessage) (string, error) {
+	var in struct{ A, B int }
+	if err := json.Unmarshal(args, &in); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%d", in.A+in.B), nil
+}
+
+// toolResultsOf returns the tool results in msg
+func toolResultsOf(msg llmtypes.MessageContent) []llmtypes.ToolCallResponse {
+	var results []llmtypes.ToolCallResponse
+	for _, part := range msg.Parts {
+		if result, ok := part.(llmtypes.ToolCallResponse); ok {
+			results = append(results, result)
+		}
+	}
+	return results
+}
+
+// RunAgentLoopTest verifies how agent.Loop runs and stops
+func RunAgentLoopTest() bool {
+	log.Printf("\n🔁 Test: Agent Loop")
+
+	ctx := context.Background()
+	messages := []llmtypes.MessageContent{llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "What is 1+2+3?")}
+	tools := map[string]agent.ToolFunc{"add": addTool}
+	passed := true
+
+	// Two tool-calling turns, then the answer
+	calls := 0
+	model := fakeModelFunc(func(ctx context.Context, messages []llmtypes.MessageContent, opts *llmtypes.CallOptions) (*llmtypes.ContentResponse, error) {
+		calls++
+		switch calls {
+		case 1:
+			return toolCallResponse(toolCall("call_1", "add", `{"a":1,"b":2}`)), nil
+		case 2:
+			return toolCallResponse(toolCall("call_2", "add", `{"a":3,"b":3}`)), nil
+		}
+		return textResponse("6"), nil
+	})
+	result, err := agent.NewLoop(model, tools).Run(ctx, messages)
+	if err != nil || result.Content != "6" || result.Iterations != 2 || calls != 3 || len(result.Messages) != 6 {
+		log.Printf("❌ Expected the answer 6 after 2 tool turns and a 6-message transcript, got %q after %d turns, %d messages (error %v)", result.Content, result.Iterations, len(result.Messages), err)
+		passed = false
+	} else if results := toolResultsOf(result.Messages[4]); len(results) != 1 || results[0].Content != "6" || results[0].ToolCallID != "call_2" {
+		log.Printf("❌ Expected the second tool result 6 for call_2 in the transcript, got %+v", results)
+		passed = false
+	} else {
+		log.Printf("✅ Tools run until the model answers: %q after %d tool turns", result.Content, result.Iterations)
+	}
+
+	// A model that never stops calling tools
+	endless := func(calls *int) llmtypes.Model {
+		return fakeModelFunc(func(ctx context.Context, messages []llmtypes.MessageContent, opts *llmtypes.CallOptions) (*llmtypes.ContentResponse, error) {
+			*calls++
+			return toolCallResponse(toolCall(fmt.Sprintf("call_%d", *calls), "add", `{"a":1,"b":1}`)), nil
+		})
+	}
+	calls = 0
+	result, err = agent.NewLoop(endless(&calls), tools, agent.WithMaxToolIterations(3)).Run(ctx, messages)
+	if err == nil || !strings.Contains(err.Error(), "after 3 tool iterations") || result.Iterations != 3 || calls != 4 {
+		log.Printf("❌ Expected the loop stopped after 3 tool iterations (4 model calls), got %d iterations, %d calls (error %v)", result.Iterations, calls, err)
+		passed = false
+	} else if last := result.Messages[len(result.Messages)-1]; len(toolCallsOf(last)) != 1 {
+		log.Printf("❌ Expected the transcript to end with the pending tool call, got role %s", last.Role)
+		passed = false
+	} else {
+		log.Printf("✅ WithMaxToolIterations(3) stops an endless loop: %v", err)
+	}
+
+	calls = 0
+	_, err = agent.NewLoop(endless(&calls), tools).Run(ctx, messages)
+	if err == nil || calls != agent.DefaultMaxToolIterations+1 {
+		log.Printf("❌ Expected the default limit of %d iterations, got %d model calls (error %v)", agent.DefaultMaxToolIterations, calls, err)
+		passed = false
+	} else {
+		log.Printf("✅ DefaultMaxToolIterations (%d) applies without the option", agent.DefaultMaxToolIterations)
+	}
+
+	// Unknown tools and tool errors are sent back to the model
+	var fedBack []llmtypes.ToolCallResponse
+	calls = 0
+	model = fakeModelFunc(func(ctx context.Context, messages []llmtypes.MessageContent, opts *llmtypes.CallOptions) (*llmtypes.ContentResponse, error) {
+		calls++
+		if calls == 1 {
+			return toolCallResponse(toolCall("call_1", "subtract", `{}`), toolCall("call_2", "add", `not json`)), nil
+		}
+		fedBack = toolResultsOf(messages[len(messages)-1])
+		return textResponse("Sorry, I cannot compute that."), nil
+	})
+	_, err = agent.NewLoop(model, tools).Run(ctx, messages)
+	if err != nil || len(fedBack) != 2 || !fedBack[0].IsError || !strings.Contains(fedBack[0].Content, `unknown tool "subtract"`) || !fedBack[1].IsError {
+		log.Printf("❌ Expected errored results for the unknown tool and the failing tool, got %+v (error %v)", fedBack, err)
+		passed = false
+	} else {
+		log.Printf("✅ Unknown tools and tool errors are sent back as errored results: %q, %q", fedBack[0].Content, fedBack[1].Content)
+	}
+
+	// A model error ends the loop
+	failure := errors.New("provider unavailable")
+	model = fakeModelFunc(func(ctx context.Context, messages []llmtypes.MessageContent, opts *llmtypes.CallOptions) (*llmtypes.ContentResponse, error) {
+		return nil, failure
+	})
+	_, err = agent.NewLoop(model, tools).Run(ctx, messages)
+	if !errors.Is(err, failure) || !strings.Contains(err.Error(), "agent loop turn 1") {
+		log.Printf("❌ Expected the model error wrapped with its turn, got %v", err)
+		passed = false
+	} else {
+		log.Printf("✅ A model error ends the loop: %v", err)
+	}
+	return passed
+}
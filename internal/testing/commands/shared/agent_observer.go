@@ -0,0 +1,151 @@
+package shared
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+	"github.com/manishiitg/multi-llm-provider-go/pkg/agent"
+
+	"github.com/spf13/cobra"
+)
+
+// AgentObserverTestCmd checks the events agent.WithLoopObserver reports
+var AgentObserverTestCmd = &cobra.Command{
+	Use:   "agent-observer",
+	Short: "Test that agent.WithLoopObserver streams each turn and reports loop progress",
+	Long: `This test drives agent.Loop with a fake streaming model and checks that with
+WithLoopObserver:
+- each turn starts with a turn_start event and its content is streamed as content_delta events
+- tool_call, tool_start and tool_end events report every tool call with its result
+- a complete event carries the result, or the error when the loop fails
+- events are delivered one at a time, even while tools run concurrently
+- without an observer the model is not asked to stream
+
+No API calls are made.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !RunAgentObserverTest() {
+			os.Exit(1)
+		}
+	},
+}
+
+// RunAgentObserverTest verifies the events of an observed agent.Loop
+func RunAgentObserverTest() bool {
+	log.Printf("\n👀 Test: Agent Observer")
+
+	messages := []llmtypes.MessageContent{llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "What is 1+2+3?")}
+	streamed := false
+	model := fakeModelFunc(func(ctx context.Context, messages []llmtypes.MessageContent, opts *llmtypes.CallOptions) (*llmtypes.ContentResponse, error) {
+		streamed = opts.StreamChan != nil
+		if len(messages) == 1 {
+			choice := &llmtypes.ContentChoice{Content: "Let me add.", ToolCalls: []llmtypes.ToolCall{
+				toolCall("call_1", "add", `{"a":1,"b":2}`),
+				toolCall("call_2", "add", `{"a":3,"b":0}`),
+			}, StopReason: "tool_calls"}
+			return streamChoice(opts, choice, "Let me ", "add."), nil
+		}
+		return streamChoice(opts, &llmtypes.ContentChoice{Content: "It is 6.", StopReason: "stop"}, "It is ", "6."), nil
+	})
+
+	// Tool events of one turn may come in any order, so they are sorted per turn below
+	var events []string
+	var inObserver, overlapped atomic.Bool
+	var final *agent.Result
+	observer := func(event agent.LoopEvent) {
+		if inObserver.Swap(true) {
+			overlapped.Store(true)
+		}
+		defer inObserver.Store(false)
+		time.Sleep(time.Millisecond)
+		line := fmt.Sprintf("%d:%s", event.Turn, event.Type)
+		switch event.Type {
+		case agent.LoopEventContentDelta:
+			line += fmt.Sprintf("(%s)", event.Chunk.Content)
+		case agent.LoopEventToolCall, agent.LoopEventToolStart:
+			line += fmt.Sprintf("(%s)", event.ToolCall.ID)
+		case agent.LoopEventToolEnd:
+			line += fmt.Sprintf("(%s=%s)", event.ToolCall.ID, event.ToolResult.Content)
+		case agent.LoopEventComplete:
+			final = event.Result
+			line += fmt.Sprintf("(err=%v)", event.Err)
+		}
+		events = append(events, line)
+	}
+
+	passed := true
+	loop := agent.NewLoop(model, map[string]agent.ToolFunc{"add": addTool}, agent.WithLoopObserver(observer))
+	result, err := loop.Run(context.Background(), messages)
+	want := []string{
+		"1:turn_start", "1:content_delta(Let me )", "1:content_delta(add.)",
+		"1:tool_call(call_1)", "1:tool_call(call_2)",
+		"1:tool_end(call_1=3)", "1:tool_end(call_2=3)", "1:tool_start(call_1)", "1:tool_start(call_2)",
+		"2:turn_start", "2:content_delta(It is )", "2:content_delta(6.)",
+		"2:complete(err=<nil>)",
+	}
+	got := append([]string(nil), events...)
+	sortToolEvents(got)
+	if err != nil || strings.Join(got, " ") != strings.Join(want, " ") {
+		log.Printf("❌ Expected the events\n%s\ngot\n%s\n(error %v)", strings.Join(want, "\n"), strings.Join(got, "\n"), err)
+		passed = false
+	} else if final != result || !streamed {
+		log.Printf("❌ Expected the model streamed and the complete event to carry the result")
+		passed = false
+	} else if slices.Index(events, "1:tool_start(call_1)") > slices.Index(events, "1:tool_end(call_1=3)") ||
+		slices.Index(events, "1:tool_start(call_2)") > slices.Index(events, "1:tool_end(call_2=3)") {
+		log.Printf("❌ Expected each tool_start before its tool_end, got %v", events)
+		passed = false
+	} else {
+		log.Printf("✅ Turns, streamed content and tool calls are reported in order (%d events)", len(events))
+	}
+	if overlapped.Load() {
+		log.Printf("❌ The observer was called concurrently")
+		passed = false
+	} else {
+		log.Printf("✅ Events are delivered one at a time")
+	}
+
+	// The complete event carries the error of a failed loop
+	failure := errors.New("provider unavailable")
+	events = nil
+	failing := fakeModelFunc(func(ctx context.Context, messages []llmtypes.MessageContent, opts *llmtypes.CallOptions) (*llmtypes.ContentResponse, error) {
+		return nil, failure
+	})
+	_, err = agent.NewLoop(failing, nil, agent.WithLoopObserver(observer)).Run(context.Background(), messages)
+	if len(events) != 2 || events[1] != fmt.Sprintf("1:complete(err=%v)", err) || !errors.Is(err, failure) {
+		log.Printf("❌ Expected turn_start and a complete event with the error, got %v", events)
+		passed = false
+	} else {
+		log.Printf("✅ The complete event carries the error: %s", events[1])
+	}
+
+	// Without an observer the model is not streamed
+	if _, err := agent.NewLoop(model, map[string]agent.ToolFunc{"add": addTool}).Run(context.Background(), messages); err != nil || streamed {
+		log.Printf("❌ Expected no streaming without an observer (streamed %t, error %v)", streamed, err)
+		passed = false
+	} else {
+		log.Printf("✅ Without an observer the model is not asked to stream")
+	}
+	return passed
+}
+
+// sortToolEvents sorts each run of tool_start and tool_end events, whose order depends on
+// how the concurrent tool handlers are scheduled
+func sortToolEvents(events []string) {
+	for start := 0; start < len(events); {
+		end := start
+		for end < len(events) && (strings.Contains(events[end], ":tool_start") || strings.Contains(events[end], ":tool_end")) {
+			end++
+		}
+		sort.Strings(events[start:end])
+		start = max(end, start+1)
+	}
+}
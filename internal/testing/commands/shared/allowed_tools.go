@@ -0,0 +1,120 @@
+package shared
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"os"
+	"strings"
+
+	llmproviders "github.com/manishiitg/multi-llm-provider-go"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+
+	"github.com/spf13/cobra"
+)
+
+// AllowedToolsTestCmd checks that WithAllowedTools limits the tools of one call
+var AllowedToolsTestCmd = &cobra.Command{
+	Use:   "allowed-tools",
+	Short: "Test that WithAllowedTools limits the tools the model may call",
+	Long: `This test checks that WithAllowedTools:
+- sends only the allowed tools to OpenAI (dry run)
+- sends every tool to Gemini with allowedFunctionNames (dry run)
+- rejects a response calling a tool that is not allowed
+- rejects allowed names and forced tool choices that are not among the call's tools
+
+No API calls are made.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !RunAllowedToolsTest() {
+			os.Exit(1)
+		}
+	},
+}
+
+// toolCallingModel is a fake model that always calls the named tool
+type toolCallingModel struct {
+	fakeModelID
+	name string
+}
+
+func (m *toolCallingModel) GenerateContent(ctx context.Context, messages []llmtypes.MessageContent, options ...llmtypes.CallOption) (*llmtypes.ContentResponse, error) {
+	return &llmtypes.ContentResponse{Choices: []*llmtypes.ContentChoice{{
+		StopReason: "tool_calls",
+		ToolCalls:  []llmtypes.ToolCall{{ID: "call_1", Type: "function", FunctionCall: &llmtypes.FunctionCall{Name: m.name, Arguments: "{}"}}},
+	}}}, nil
+}
+
+// RunAllowedToolsTest verifies the requests and validation of WithAllowedTools
+func RunAllowedToolsTest() bool {
+	log.Printf("\n🧰 Test: Allowed Tools")
+
+	var tools []llmtypes.Tool
+	for _, name := range []string{"get_weather", "search", "send_email"} {
+		tools = append(tools, llmtypes.Tool{Type: "function", Function: &llmtypes.FunctionDefinition{
+			Name:        name,
+			Description: "Tool " + name,
+			Parameters:  llmtypes.NewParameters(map[string]interface{}{"type": "object", "properties": map[string]interface{}{}}),
+		}})
+	}
+	messages := []llmtypes.MessageContent{llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "Find the weather in Paris.")}
+	allowed := llmproviders.WithAllowedTools([]string{"get_weather", "search"})
+
+	dryRun := func(config llmproviders.Config) string {
+		llm, err := llmproviders.InitializeLLM(config)
+		if err != nil {
+			log.Printf("❌ %s initialization failed: %v", config.Provider, err)
+			return ""
+		}
+		resp, err := llm.GenerateContent(context.Background(), messages, llmtypes.WithTools(tools), allowed, llmtypes.WithDryRun())
+		if err != nil || resp == nil || !resp.DryRun {
+			log.Printf("❌ %s dry run failed: %v", config.Provider, err)
+			return ""
+		}
+		request, _ := json.Marshal(resp.Raw)
+		return string(request)
+	}
+
+	passed := true
+	apiKey := "dry-run"
+	request := dryRun(llmproviders.Config{Provider: llmproviders.ProviderOpenAI, ModelID: "gpt-4.1", APIKeys: &llmproviders.ProviderAPIKeys{OpenAI: &apiKey}})
+	if request == "" || !strings.Contains(request, `"search"`) || strings.Contains(request, `"send_email"`) {
+		log.Printf("❌ OpenAI request should have only the allowed tools: %s", request)
+		passed = false
+	} else {
+		log.Printf("✅ OpenAI is sent only the allowed tools")
+	}
+	request = dryRun(llmproviders.Config{Provider: llmproviders.ProviderVertex, ModelID: "gemini-2.5-flash", APIKeys: &llmproviders.ProviderAPIKeys{Vertex: &apiKey}})
+	if request == "" || !strings.Contains(request, `"send_email"`) || !strings.Contains(request, `"allowedFunctionNames":["get_weather","search"]`) || !strings.Contains(request, `"VALIDATED"`) {
+		log.Printf("❌ Gemini request should have every tool with allowedFunctionNames: %s", request)
+		passed = false
+	} else {
+		log.Printf("✅ Gemini is sent every tool with allowedFunctionNames in VALIDATED mode")
+	}
+
+	llm := llmproviders.NewProviderAwareLLM(&toolCallingModel{name: "send_email"}, llmproviders.ProviderOpenAI, "fake-model", nil, "allowed-tools-test", nil)
+	if _, err := llm.GenerateContent(context.Background(), messages, llmtypes.WithTools(tools), allowed); err == nil || !strings.Contains(err.Error(), "send_email") {
+		log.Printf("❌ A call to a disallowed tool should fail, got %v", err)
+		passed = false
+	} else {
+		log.Printf("✅ A call to a disallowed tool fails: %v", err)
+	}
+	if _, err := llm.GenerateContent(context.Background(), messages, llmtypes.WithTools(tools), llmproviders.WithAllowedTools([]string{"send_email"})); err != nil {
+		log.Printf("❌ A call to an allowed tool should succeed, got %v", err)
+		passed = false
+	} else {
+		log.Printf("✅ A call to an allowed tool succeeds")
+	}
+
+	for name, options := range map[string][]llmtypes.CallOption{
+		"an allowed tool that is not configured": {llmtypes.WithTools(tools), llmproviders.WithAllowedTools([]string{"delete_files"})},
+		"a forced tool that is not allowed":      {llmtypes.WithTools(tools), allowed, llmtypes.WithToolChoice(&llmtypes.ToolChoice{Type: "function", Function: &llmtypes.FunctionName{Name: "send_email"}})},
+	} {
+		if _, err := llm.GenerateContent(context.Background(), messages, options...); err == nil {
+			log.Printf("❌ A call with %s should fail", name)
+			passed = false
+		} else {
+			log.Printf("✅ A call with %s fails: %v", name, err)
+		}
+	}
+	return passed
+}
@@ -0,0 +1,98 @@
+package shared
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+
+	llmproviders "github.com/manishiitg/multi-llm-provider-go"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+
+	"github.com/spf13/cobra"
+)
+
+// AppAttributionTestCmd checks that Config.AppAttribution reaches the provider as headers
+var AppAttributionTestCmd = &cobra.Command{
+	Use:   "app-attribution",
+	Short: "Test app attribution headers from Config.AppAttribution",
+	Long: `This test checks, against a local fake provider server, that:
+- OpenRouter requests carry HTTP-Referer and X-Title from Config.AppAttribution, falling back
+  to OPENROUTER_HTTP_REFERER and OPENROUTER_X_TITLE
+- OpenAI requests carry OpenAI-Organization and OpenAI-Project from Config.AppAttribution
+
+No API calls are made.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !RunAppAttributionTest() {
+			os.Exit(1)
+		}
+	},
+}
+
+// RunAppAttributionTest verifies the attribution headers sent for each provider
+func RunAppAttributionTest() bool {
+	log.Printf("\n🏷️  Test: App Attribution")
+
+	var headers http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		headers = r.Header.Clone()
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"id":"chatcmpl-1","object":"chat.completion","created":1,"model":"gpt-4.1","choices":[{"index":0,"message":{"role":"assistant","content":"Hello"},"finish_reason":"stop"}]}`)
+	}))
+	defer server.Close()
+	target, _ := url.Parse(server.URL)
+	httpClient := &http.Client{Transport: redirectTransport{target: target}}
+
+	apiKey := "test"
+	messages := []llmtypes.MessageContent{llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "Hi")}
+	call := func(config llmproviders.Config) error {
+		headers = nil
+		config.HTTPClient = httpClient
+		llm, err := llmproviders.InitializeLLM(config)
+		if err != nil {
+			return err
+		}
+		_, err = llm.GenerateContent(context.Background(), messages)
+		return err
+	}
+
+	passed := true
+	check := func(name string, err error, want map[string]string) {
+		for key, value := range want {
+			if err != nil || headers.Get(key) != value {
+				log.Printf("❌ %s: expected %s %q, got %q (err %v)", name, key, value, headers.Get(key), err)
+				passed = false
+				return
+			}
+		}
+		log.Printf("✅ %s sends %v", name, want)
+	}
+
+	openRouter := llmproviders.Config{Provider: llmproviders.ProviderOpenRouter, ModelID: "openai/gpt-4.1", APIKeys: &llmproviders.ProviderAPIKeys{OpenRouter: &apiKey}}
+	tenant := openRouter
+	tenant.AppAttribution = &llmproviders.AppAttribution{Referer: "https://tenant.example.com", Title: "Tenant App"}
+	check("OpenRouter with AppAttribution", call(tenant), map[string]string{"HTTP-Referer": "https://tenant.example.com", "X-Title": "Tenant App"})
+
+	for key, value := range map[string]string{"OPENROUTER_HTTP_REFERER": "https://env.example.com", "OPENROUTER_X_TITLE": "Env App"} {
+		if previous, ok := os.LookupEnv(key); ok {
+			defer os.Setenv(key, previous)
+		} else {
+			defer os.Unsetenv(key)
+		}
+		os.Setenv(key, value)
+	}
+	check("OpenRouter without AppAttribution", call(openRouter), map[string]string{"HTTP-Referer": "https://env.example.com", "X-Title": "Env App"})
+	tenant.AppAttribution = &llmproviders.AppAttribution{Title: "Tenant App"}
+	check("OpenRouter with a partial AppAttribution", call(tenant), map[string]string{"HTTP-Referer": "https://env.example.com", "X-Title": "Tenant App"})
+
+	check("OpenAI with AppAttribution", call(llmproviders.Config{
+		Provider:       llmproviders.ProviderOpenAI,
+		ModelID:        "gpt-4.1",
+		APIKeys:        &llmproviders.ProviderAPIKeys{OpenAI: &apiKey},
+		AppAttribution: &llmproviders.AppAttribution{Organization: "org-tenant", Project: "proj_tenant"},
+	}), map[string]string{"OpenAI-Organization": "org-tenant", "OpenAI-Project": "proj_tenant"})
+	return passed
+}
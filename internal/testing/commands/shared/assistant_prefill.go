@@ -0,0 +1,135 @@
+package shared
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"os"
+	"strings"
+
+	llmproviders "github.com/manishiitg/multi-llm-provider-go"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+	"github.com/manishiitg/multi-llm-provider-go/pkg/utils"
+
+	"github.com/spf13/cobra"
+)
+
+// AssistantPrefillTestCmd checks that WithAssistantPrefill makes responses start with the prefill
+var AssistantPrefillTestCmd = &cobra.Command{
+	Use:   "assistant-prefill",
+	Short: "Test that WithAssistantPrefill makes the response start with the prefill",
+	Long: `This test generates with WithAssistantPrefill against fake models and a fake Anthropic
+endpoint, and checks that:
+- with native support the prefill is sent as a trailing assistant message, without
+  trailing whitespace, and prepended to the continuation the model returns
+- a model repeating the prefill does not get it prepended twice
+- when streaming, the prefill is the first content chunk
+- without native support the model is instructed to begin with the prefill, and a
+  response that does not is rejected
+- the Anthropic adapter sends the prefill as the last message of the request
+
+No API calls are made.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !RunAssistantPrefillTest() {
+			os.Exit(1)
+		}
+	},
+}
+
+// RunAssistantPrefillTest verifies the responses generated with an assistant prefill
+func RunAssistantPrefillTest() bool {
+	log.Printf("\n✍️  Test: Assistant Prefill")
+
+	ctx := context.Background()
+	messages := []llmtypes.MessageContent{llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "Give me a JSON object")}
+	passed := true
+
+	// A model answering with answer and recording the last message it was sent
+	var last llmtypes.MessageContent
+	answering := func(answer string) llmtypes.Model {
+		return fakeModelFunc(func(ctx context.Context, messages []llmtypes.MessageContent, opts *llmtypes.CallOptions) (*llmtypes.ContentResponse, error) {
+			last = messages[len(messages)-1]
+			return streamChoice(opts, &llmtypes.ContentChoice{Content: answer, StopReason: "stop"}), nil
+		})
+	}
+	lastText := func() string {
+		text, _ := last.Parts[0].(llmtypes.TextContent)
+		return text.Text
+	}
+
+	// Native prefill: the model continues from the trailing assistant message
+	for _, c := range []struct {
+		name   string
+		answer string
+	}{
+		{"continuation", `"a": 1}`},
+		{"repeated prefill", `{"a": 1}`},
+	} {
+		resp, err := utils.GenerateWithPrefill(ctx, answering(c.answer), messages, []llmtypes.CallOption{llmtypes.WithAssistantPrefill("{ \n")}, true)
+		if err != nil || last.Role != llmtypes.ChatMessageTypeAI || lastText() != "{" || resp.Choices[0].Content != `{"a": 1}` {
+			log.Printf("❌ Native %s: expected a trailing assistant message %q and content %q, got %s %q, content %v (error %v)", c.name, "{", `{"a": 1}`, last.Role, lastText(), resp, err)
+			passed = false
+			continue
+		}
+		log.Printf("✅ Native %s: the prefill is sent as the last assistant message and the content is %s", c.name, resp.Choices[0].Content)
+	}
+
+	// The streamed text matches the returned content
+	streamChan := make(chan llmtypes.StreamChunk, 10)
+	resp, err := utils.GenerateWithPrefill(ctx, answering(`"a": 1}`), messages, []llmtypes.CallOption{llmtypes.WithAssistantPrefill("{"), llmtypes.WithStreamingChan(streamChan)}, true)
+	stream := describeStream(streamChan)
+	if err != nil || stream != `content({) content("a": 1}) finish(stop)` || resp.Choices[0].Content != `{"a": 1}` {
+		log.Printf("❌ Streaming: expected the prefill as the first chunk, got %s (error %v)", stream, err)
+		passed = false
+	} else {
+		log.Printf("✅ Streaming: the prefill is the first chunk: %s", stream)
+	}
+
+	// Emulated prefill: the model is instructed and its response checked
+	for _, c := range []struct {
+		name   string
+		answer string
+		fails  bool
+	}{
+		{"following the instruction", ` {"a": 1}`, false},
+		{"ignoring the instruction", `Sure! {"a": 1}`, true},
+	} {
+		resp, err := utils.GenerateWithPrefill(ctx, answering(c.answer), messages, []llmtypes.CallOption{llmtypes.WithAssistantPrefill("{")}, false)
+		instructed := last.Role == llmtypes.ChatMessageTypeSystem && strings.Contains(lastText(), "Begin your response with exactly")
+		if !instructed || (err != nil) != c.fails || (!c.fails && resp.Choices[0].Content != c.answer) {
+			log.Printf("❌ Emulated, %s: expected an instruction and error %t, got %s %q (error %v)", c.name, c.fails, last.Role, lastText(), err)
+			passed = false
+			continue
+		}
+		log.Printf("✅ Emulated, %s: the model is instructed (error %v)", c.name, err)
+	}
+
+	// The Anthropic adapter prefills natively
+	apiKey := "test"
+	server := newRecordingServer(anthropicHelloResponse)
+	llm, err := llmproviders.InitializeLLM(llmproviders.Config{Provider: llmproviders.ProviderAnthropic, ModelID: "claude-sonnet-4-20250514",
+		APIKeys: &llmproviders.ProviderAPIKeys{Anthropic: &apiKey}, HTTPClient: server.client()})
+	if err == nil {
+		resp, err = llm.GenerateContent(ctx, messages, llmproviders.WithAssistantPrefill("Say: "))
+	}
+	server.Close()
+	var body struct {
+		Messages []struct {
+			Role    string `json:"role"`
+			Content []struct {
+				Text string `json:"text"`
+			} `json:"content"`
+		} `json:"messages"`
+	}
+	_ = json.Unmarshal([]byte(server.body), &body)
+	if err != nil || len(body.Messages) == 0 || resp.Choices[0].Content != "Say:Hello" {
+		log.Printf("❌ Anthropic: expected the prefill as the last message and content %q, got %s (error %v)", "Say:Hello", server.body, err)
+		passed = false
+	} else if sent := body.Messages[len(body.Messages)-1]; sent.Role != "assistant" || len(sent.Content) != 1 || sent.Content[0].Text != "Say:" {
+		log.Printf("❌ Anthropic: expected the last message to be the assistant prefill %q, got %s", "Say:", server.body)
+		passed = false
+	} else {
+		log.Printf("✅ Anthropic sends the prefill as the last assistant message and returns %q", resp.Choices[0].Content)
+	}
+	return passed
+}
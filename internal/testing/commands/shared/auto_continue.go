@@ -0,0 +1,110 @@
+package shared
+
+import (
+	"context"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+	"github.com/manishiitg/multi-llm-provider-go/pkg/utils"
+
+	"github.com/spf13/cobra"
+)
+
+// AutoContinueTestCmd checks that WithAutoContinue stitches output cut off at the token limit
+var AutoContinueTestCmd = &cobra.Command{
+	Use:   "auto-continue",
+	Short: "Test that WithAutoContinue continues output cut off at the token limit",
+	Long: `This test generates with WithAutoContinue against fake models that stop at the token
+limit, and checks that:
+- the continuations are stitched into the first choice and the usage is summed
+- with native support the partial output is sent as a trailing assistant message, and
+  otherwise followed by a user message asking to continue
+- no more than the given number of continuations are requested
+- a truncated tool call is not continued
+- when streaming, the content of every request is forwarded, followed by one finish
+  chunk for the stitched response
+
+No API calls are made.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !RunAutoContinueTest() {
+			os.Exit(1)
+		}
+	},
+}
+
+// RunAutoContinueTest verifies the responses generated with auto-continue
+func RunAutoContinueTest() bool {
+	log.Printf("\n⏩ Test: Auto Continue")
+
+	ctx := context.Background()
+	messages := []llmtypes.MessageContent{llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "Write a sentence")}
+	passed := true
+
+	// A model answering the choices in turn, recording the messages of every request
+	var requests [][]llmtypes.MessageContent
+	answering := func(choices ...*llmtypes.ContentChoice) llmtypes.Model {
+		requests = nil
+		return fakeModelFunc(func(ctx context.Context, messages []llmtypes.MessageContent, opts *llmtypes.CallOptions) (*llmtypes.ContentResponse, error) {
+			choice := choices[min(len(requests), len(choices)-1)]
+			requests = append(requests, messages)
+			resp := streamChoice(opts, &llmtypes.ContentChoice{Content: choice.Content, StopReason: choice.StopReason, ToolCalls: choice.ToolCalls})
+			resp.Usage = &llmtypes.Usage{InputTokens: 10, OutputTokens: 5, TotalTokens: 15}
+			return resp, nil
+		})
+	}
+	truncated := &llmtypes.ContentChoice{Content: "The quick brown ", StopReason: "length"}
+	finished := &llmtypes.ContentChoice{Content: " fox jumps.", StopReason: "stop"}
+
+	for _, c := range []struct {
+		name   string
+		native bool
+		want   string
+	}{
+		{"native", true, "ai: text(The quick brown)"},
+		{"emulated", false, "ai: text(The quick brown )\nhuman: text(Your previous response was cut off"},
+	} {
+		resp, err := utils.GenerateWithAutoContinue(ctx, answering(truncated, finished), messages, []llmtypes.CallOption{llmtypes.WithAutoContinue(3)}, c.native)
+		if err != nil || len(requests) != 2 || resp.Choices[0].Content != "The quick brown fox jumps." || resp.Choices[0].StopReason != "stop" || resp.Usage.TotalTokens != 30 {
+			log.Printf("❌ %s: expected 2 requests stitched into %q with 30 tokens, got %d requests, %v (error %v)", c.name, "The quick brown fox jumps.", len(requests), resp, err)
+			passed = false
+		} else if got := describeMessages(requests[1][len(messages):]); !strings.HasPrefix(got, c.want) {
+			log.Printf("❌ %s: expected the continuation request to end with %q, got %q", c.name, c.want, got)
+			passed = false
+		} else {
+			log.Printf("✅ %s: %q stitched from 2 requests (%d tokens), the continuation request ends with %q", c.name, resp.Choices[0].Content, resp.Usage.TotalTokens, got)
+		}
+	}
+
+	// The number of continuations is capped
+	resp, err := utils.GenerateWithAutoContinue(ctx, answering(truncated), messages, []llmtypes.CallOption{llmtypes.WithAutoContinue(2)}, true)
+	if err != nil || len(requests) != 3 || resp.Choices[0].StopReason != "length" {
+		log.Printf("❌ Expected 3 requests for 2 continuations, still cut off, got %d (error %v)", len(requests), err)
+		passed = false
+	} else {
+		log.Printf("✅ 2 continuations make 3 requests and the response is still cut off: %q", resp.Choices[0].Content)
+	}
+
+	// A truncated tool call is returned as is
+	call := toolCall("call_1", "search", `{"q":`)
+	resp, err = utils.GenerateWithAutoContinue(ctx, answering(&llmtypes.ContentChoice{ToolCalls: []llmtypes.ToolCall{call}, StopReason: "length"}, finished), messages, []llmtypes.CallOption{llmtypes.WithAutoContinue(3)}, true)
+	if err != nil || len(requests) != 1 || len(resp.Choices[0].ToolCalls) != 1 {
+		log.Printf("❌ Expected a truncated tool call not to be continued, got %d requests (error %v)", len(requests), err)
+		passed = false
+	} else {
+		log.Printf("✅ A truncated tool call is not continued")
+	}
+
+	// Streaming forwards every request's content and one finish chunk
+	streamChan := make(chan llmtypes.StreamChunk, 10)
+	resp, err = utils.GenerateWithAutoContinue(ctx, answering(truncated, finished), messages, []llmtypes.CallOption{llmtypes.WithAutoContinue(3), llmtypes.WithStreamingChan(streamChan)}, true)
+	stream := describeStream(streamChan)
+	if err != nil || stream != "content(The quick brown ) content( fox jumps.) finish(stop)" || resp.Choices[0].Content != "The quick brown fox jumps." {
+		log.Printf("❌ Streaming: expected the content of both requests and one finish chunk, got %s (error %v)", stream, err)
+		passed = false
+	} else {
+		log.Printf("✅ Streaming: %s", stream)
+	}
+	return passed
+}
@@ -0,0 +1,178 @@
+package shared
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/manishiitg/multi-llm-provider-go/internal/testing"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+	bedrockadapter "github.com/manishiitg/multi-llm-provider-go/pkg/adapters/bedrock"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
+	"github.com/spf13/cobra"
+)
+
+// BedrockEmbeddingsTestCmd checks the request formats of the Bedrock embedding model families
+var BedrockEmbeddingsTestCmd = &cobra.Command{
+	Use:   "bedrock-embeddings",
+	Short: "Test Bedrock embeddings for Titan and Cohere models",
+	Long: `This test checks, against a local fake Bedrock server, that:
+- Titan v1/v2 and Titan multimodal models get one inputText per request, with their dimensions
+- Cohere embed v3 and v4 models get batches of up to 96 texts with input_type, and v4 the
+  output dimension
+- embeddings keep the order of the input across batches
+- unsupported dimensions, over-long texts and unknown models are rejected before any request
+
+No API calls are made.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !RunBedrockEmbeddingsTest() {
+			os.Exit(1)
+		}
+	},
+}
+
+// RunBedrockEmbeddingsTest verifies the Bedrock embedding request bodies and limits per model family
+func RunBedrockEmbeddingsTest() bool {
+	log.Printf("\n🧮 Test: Bedrock embeddings")
+
+	var mu sync.Mutex
+	var bodies []map[string]interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		var body map[string]interface{}
+		_ = json.Unmarshal(data, &body)
+		mu.Lock()
+		bodies = append(bodies, body)
+		mu.Unlock()
+
+		// Each vector is [index of the text in its request, 1]
+		w.Header().Set("Content-Type", "application/json")
+		texts, _ := body["texts"].([]interface{})
+		switch {
+		case texts == nil:
+			fmt.Fprint(w, `{"embedding":[0,1],"inputTextTokenCount":3}`)
+		case strings.Contains(r.URL.Path, "embed-v4"):
+			vectors := make([][]float64, len(texts))
+			for i := range texts {
+				vectors[i] = []float64{float64(i), 1}
+			}
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"embeddings": map[string]interface{}{"float": vectors}})
+		default:
+			vectors := make([][]float64, len(texts))
+			for i := range texts {
+				vectors[i] = []float64{float64(i), 1}
+			}
+			_ = json.NewEncoder(w).Encode(map[string]interface{}{"embeddings": vectors})
+		}
+	}))
+	defer server.Close()
+
+	client := bedrockruntime.New(bedrockruntime.Options{
+		Region:       "us-east-1",
+		Credentials:  aws.AnonymousCredentials{},
+		BaseEndpoint: aws.String(server.URL),
+	})
+	embed := func(modelID string, input interface{}, options ...llmtypes.EmbeddingOption) (*llmtypes.EmbeddingResponse, []map[string]interface{}, error) {
+		mu.Lock()
+		bodies = nil
+		mu.Unlock()
+		adapter := bedrockadapter.NewBedrockAdapter(client, modelID, testing.GetTestLogger())
+		resp, err := adapter.GenerateEmbeddings(context.Background(), input, options...)
+		mu.Lock()
+		defer mu.Unlock()
+		return resp, bodies, err
+	}
+
+	passed := true
+	checkBody := func(name, modelID string, input interface{}, want map[string]interface{}, options ...llmtypes.EmbeddingOption) {
+		resp, got, err := embed(modelID, input, options...)
+		if err != nil || len(got) != 1 {
+			log.Printf("❌ %s: expected 1 request, got %d (err %v)", name, len(got), err)
+			passed = false
+			return
+		}
+		for key, value := range want {
+			if fmt.Sprint(got[0][key]) != fmt.Sprint(value) {
+				log.Printf("❌ %s: expected %s=%v, got %v", name, key, value, got[0][key])
+				passed = false
+				return
+			}
+		}
+		if len(resp.Embeddings) == 0 || resp.Model != modelID {
+			log.Printf("❌ %s: unexpected response %+v", name, resp)
+			passed = false
+			return
+		}
+		log.Printf("✅ %s sends %v", name, want)
+	}
+
+	dims := func(n int) llmtypes.EmbeddingOption { return llmtypes.WithDimensions(n) }
+	checkBody("Titan v1", "amazon.titan-embed-text-v1", "Hello", map[string]interface{}{"inputText": "Hello", "dimensions": nil})
+	checkBody("Titan v2", "amazon.titan-embed-text-v2:0", "Hello", map[string]interface{}{"inputText": "Hello", "dimensions": 1024})
+	checkBody("Titan v2 with 256 dimensions", "amazon.titan-embed-text-v2:0", "Hello", map[string]interface{}{"dimensions": 256}, dims(256))
+	checkBody("Titan multimodal", "amazon.titan-embed-image-v1", "Hello", map[string]interface{}{"embeddingConfig": map[string]interface{}{"outputEmbeddingLength": 384}}, dims(384))
+	checkBody("Cohere v3", "cohere.embed-english-v3", []string{"a", "b"}, map[string]interface{}{"texts": []interface{}{"a", "b"}, "input_type": "search_document"})
+	checkBody("Cohere v4 (cross-region ID)", "us.cohere.embed-v4:0", []string{"a"}, map[string]interface{}{"output_dimension": 512, "embedding_types": []interface{}{"float"}}, dims(512))
+
+	if resp, _, err := embed("amazon.titan-embed-text-v1", "Hello"); err != nil || resp.Usage == nil || resp.Usage.PromptTokens != 3 {
+		log.Printf("❌ Titan usage: expected 3 prompt tokens, got %+v (err %v)", resp, err)
+		passed = false
+	} else {
+		log.Printf("✅ Titan reports input tokens in Usage")
+	}
+
+	// 100 texts are sent to Cohere as batches of 96 and 4, and to Titan one by one
+	texts := make([]string, 100)
+	for i := range texts {
+		texts[i] = fmt.Sprintf("text %d", i)
+	}
+	for _, modelID := range []string{"cohere.embed-multilingual-v3", "amazon.titan-embed-text-v2:0"} {
+		resp, got, err := embed(modelID, texts)
+		wantRequests := 2
+		if strings.Contains(modelID, "titan") {
+			wantRequests = 100
+		}
+		ordered := err == nil && len(resp.Embeddings) == len(texts)
+		for i := 0; ordered && i < len(texts); i++ {
+			ordered = resp.Embeddings[i].Index == i
+		}
+		if !ordered || len(got) != wantRequests || (wantRequests == 2 && resp.Embeddings[97].Embedding[0] != 1) {
+			log.Printf("❌ %s batching: expected %d requests and %d ordered embeddings, got %d requests (err %v)", modelID, wantRequests, len(texts), len(got), err)
+			passed = false
+		} else {
+			log.Printf("✅ %s embeds %d texts in %d requests", modelID, len(texts), wantRequests)
+		}
+	}
+
+	for _, tc := range []struct {
+		name    string
+		modelID string
+		input   interface{}
+		options []llmtypes.EmbeddingOption
+		want    string
+	}{
+		{"Titan v1 with custom dimensions", "amazon.titan-embed-text-v1", "Hello", []llmtypes.EmbeddingOption{dims(512)}, "does not support 512 dimensions"},
+		{"Titan v2 with 768 dimensions", "amazon.titan-embed-text-v2:0", "Hello", []llmtypes.EmbeddingOption{dims(768)}, "supported: [1024 512 256]"},
+		{"Cohere v3 with custom dimensions", "cohere.embed-english-v3", "Hello", []llmtypes.EmbeddingOption{dims(512)}, "does not support 512 dimensions"},
+		{"Cohere text over 2048 characters", "cohere.embed-english-v3", []string{"ok", strings.Repeat("x", 3000)}, nil, "input at index 1 is 3000 characters"},
+		{"Unknown embedding model", "meta.llama3-8b-instruct-v1:0", "Hello", nil, "unsupported Bedrock embedding model"},
+	} {
+		_, got, err := embed(tc.modelID, tc.input, tc.options...)
+		if err == nil || !strings.Contains(err.Error(), tc.want) || len(got) != 0 {
+			log.Printf("❌ %s: expected error containing %q and no request, got %v after %d requests", tc.name, tc.want, err, len(got))
+			passed = false
+			continue
+		}
+		log.Printf("✅ %s is rejected: %v", tc.name, err)
+	}
+	return passed
+}
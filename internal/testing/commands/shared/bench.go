@@ -0,0 +1,177 @@
+package shared
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	llmproviders "github.com/manishiitg/multi-llm-provider-go"
+	"github.com/manishiitg/multi-llm-provider-go/internal/testing"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+	"github.com/manishiitg/multi-llm-provider-go/pkg/bench"
+
+	"github.com/joho/godotenv"
+	"github.com/spf13/cobra"
+)
+
+// BenchCmd compares providers on the same prompt suite
+var BenchCmd = &cobra.Command{
+	Use:   "bench",
+	Short: "Benchmark latency, tokens and cost of several models on the same prompts",
+	Long: `Run the same prompt suite against several provider/model targets concurrently and
+report time to first token, total latency, token usage and cost per target.
+
+Targets are given as provider:model, optionally followed by @input/output prices
+in USD per million tokens (cost is 0 without prices).
+
+Examples:
+  llm-test bench --target openai:gpt-4.1@2/8 --target anthropic:claude-sonnet-4-20250514@3/15
+  llm-test bench --target openai:gpt-4.1-mini --target vertex:gemini-2.5-flash --prompt "Hi" --runs 5
+  llm-test bench --target openai:gpt-4.1 --prompts-file prompts.txt --csv results.csv`,
+	Run: runBench,
+}
+
+var (
+	benchTargets     []string
+	benchPrompts     []string
+	benchPromptsFile string
+	benchRuns        int
+	benchMaxTokens   int
+	benchTimeout     time.Duration
+	benchCSV         string
+)
+
+func init() {
+	BenchCmd.Flags().StringArrayVar(&benchTargets, "target", nil, "Target as provider:model[@inputPrice/outputPrice] (repeatable)")
+	BenchCmd.Flags().StringArrayVar(&benchPrompts, "prompt", nil, "Prompt to send (repeatable)")
+	BenchCmd.Flags().StringVar(&benchPromptsFile, "prompts-file", "", "File with one prompt per line")
+	BenchCmd.Flags().IntVar(&benchRuns, "runs", 3, "Times each prompt is sent to each target")
+	BenchCmd.Flags().IntVar(&benchMaxTokens, "max-tokens", 512, "Max output tokens per call")
+	BenchCmd.Flags().DurationVar(&benchTimeout, "timeout", 2*time.Minute, "Timeout per call")
+	BenchCmd.Flags().StringVar(&benchCSV, "csv", "", "Write every sample to this CSV file")
+}
+
+func runBench(cmd *cobra.Command, args []string) {
+	// Load .env file for API keys
+	_ = godotenv.Load(".env")
+	logger := testing.GetTestLogger()
+
+	prompts := append([]string(nil), benchPrompts...)
+	if benchPromptsFile != "" {
+		filePrompts, err := readPromptsFile(benchPromptsFile)
+		if err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		prompts = append(prompts, filePrompts...)
+	}
+	if len(prompts) == 0 {
+		prompts = []string{"Explain in three sentences why the sky is blue."}
+	}
+	if len(benchTargets) == 0 {
+		log.Fatalf("❌ At least one --target is required, e.g. --target openai:gpt-4.1")
+	}
+
+	targets := make([]bench.Target, 0, len(benchTargets))
+	for _, spec := range benchTargets {
+		target, config, err := parseBenchTarget(spec)
+		if err != nil {
+			log.Fatalf("❌ %v", err)
+		}
+		config.Logger = logger
+		config.Context = context.Background()
+		llm, err := llmproviders.InitializeLLM(config)
+		if err != nil {
+			log.Fatalf("❌ Failed to initialize %s: %v", spec, err)
+		}
+		target.Model = llm
+		targets = append(targets, target)
+	}
+
+	log.Printf("🏁 Benchmarking %d targets on %d prompts x %d runs", len(targets), len(prompts), benchRuns)
+	results := bench.Run(context.Background(), targets, prompts, bench.Options{
+		Runs:        benchRuns,
+		Timeout:     benchTimeout,
+		CallOptions: []llmtypes.CallOption{llmtypes.WithMaxTokens(benchMaxTokens)},
+	})
+
+	fmt.Println()
+	if err := bench.WriteTable(os.Stdout, results); err != nil {
+		log.Fatalf("❌ Failed to write results: %v", err)
+	}
+	for _, result := range results {
+		for _, sample := range result.Samples {
+			if sample.Err != nil {
+				log.Printf("⚠️  %s prompt %d run %d failed: %v", sample.Target, sample.Prompt, sample.Run, sample.Err)
+			}
+		}
+	}
+
+	if benchCSV != "" {
+		file, err := os.Create(benchCSV)
+		if err != nil {
+			log.Fatalf("❌ Failed to create %s: %v", benchCSV, err)
+		}
+		defer file.Close()
+		if err := bench.WriteCSV(file, results); err != nil {
+			log.Fatalf("❌ Failed to write %s: %v", benchCSV, err)
+		}
+		log.Printf("📄 Samples written to %s", benchCSV)
+	}
+}
+
+// parseBenchTarget parses provider:model[@inputPrice/outputPrice]
+func parseBenchTarget(spec string) (bench.Target, llmproviders.Config, error) {
+	target := bench.Target{Name: spec}
+	modelSpec := spec
+	if at := strings.LastIndex(spec, "@"); at >= 0 {
+		modelSpec = spec[:at]
+		prices := strings.SplitN(spec[at+1:], "/", 2)
+		if len(prices) != 2 {
+			return target, llmproviders.Config{}, fmt.Errorf("invalid prices in target %q, expected @input/output", spec)
+		}
+		var err error
+		if target.InputPricePerMillion, err = strconv.ParseFloat(prices[0], 64); err != nil {
+			return target, llmproviders.Config{}, fmt.Errorf("invalid input price in target %q: %w", spec, err)
+		}
+		if target.OutputPricePerMillion, err = strconv.ParseFloat(prices[1], 64); err != nil {
+			return target, llmproviders.Config{}, fmt.Errorf("invalid output price in target %q: %w", spec, err)
+		}
+	}
+
+	providerName, modelID, ok := strings.Cut(modelSpec, ":")
+	if !ok || modelID == "" {
+		return target, llmproviders.Config{}, fmt.Errorf("invalid target %q, expected provider:model", spec)
+	}
+	provider, err := llmproviders.ValidateProvider(providerName)
+	if err != nil {
+		return target, llmproviders.Config{}, err
+	}
+	target.Name = modelSpec
+	return target, llmproviders.Config{Provider: provider, ModelID: modelID}, nil
+}
+
+// readPromptsFile reads one prompt per non-empty line
+func readPromptsFile(path string) ([]string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open prompts file: %w", err)
+	}
+	defer file.Close()
+
+	var prompts []string
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		if line := strings.TrimSpace(scanner.Text()); line != "" {
+			prompts = append(prompts, line)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read prompts file: %w", err)
+	}
+	return prompts, nil
+}
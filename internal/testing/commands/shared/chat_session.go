@@ -0,0 +1,152 @@
+package shared
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	llmproviders "github.com/manishiitg/multi-llm-provider-go"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+
+	"github.com/spf13/cobra"
+)
+
+// ChatSessionTestCmd checks the history a ChatSession keeps and sends
+var ChatSessionTestCmd = &cobra.Command{
+	Use:   "chat-session",
+	Short: "Test that ChatSession records the conversation and sends it with every turn",
+	Long: `This test holds conversations with a fake model through ChatSession and checks that:
+- every turn sends the system prompt and the history, and records the answer
+- the session's call options apply to every turn and Send's options after them
+- tool calls are recorded, and AddToolResults and Continue answer them
+- a failed turn leaves the history as it was
+- Restore rewinds to a checkpoint, unknown checkpoints are rejected, and a forked
+  session diverges without changing the original
+- WithSessionMaxHistoryTokens drops the oldest messages
+
+No API calls are made.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !RunChatSessionTest() {
+			os.Exit(1)
+		}
+	},
+}
+
+// RunChatSessionTest verifies the conversations held through ChatSession
+func RunChatSessionTest() bool {
+	log.Printf("\n💬 Test: Chat Session")
+
+	ctx := context.Background()
+	passed := true
+	check := func(name, got, want string) {
+		if got != want {
+			log.Printf("❌ %s: expected %q, got %q", name, want, got)
+			passed = false
+			return
+		}
+		log.Printf("✅ %s: %q", name, got)
+	}
+
+	// A model answering "rN" to its Nth request, recording the last request
+	var requests int
+	var sent []llmtypes.MessageContent
+	sentOpts := &llmtypes.CallOptions{}
+	var answer func() (*llmtypes.ContentResponse, error)
+	model := fakeModelFunc(func(ctx context.Context, messages []llmtypes.MessageContent, opts *llmtypes.CallOptions) (*llmtypes.ContentResponse, error) {
+		requests++
+		sent, sentOpts = messages, opts
+		if answer != nil {
+			return answer()
+		}
+		return textResponse(fmt.Sprintf("r%d", requests)), nil
+	})
+	llm := llmproviders.NewProviderAwareLLM(model, llmproviders.ProviderOpenAI, "fake-model", nil, "chat-session-test", nil)
+
+	// Every turn sends the whole conversation
+	session := llmproviders.NewChatSession(llm, llmproviders.WithSessionSystemPrompt("system prompt"),
+		llmproviders.WithSessionCallOptions(llmproviders.WithTemperature(0.2), llmproviders.WithMaxTokens(100)))
+	_, err := session.Send(ctx, "hello")
+	if err == nil {
+		_, err = session.Send(ctx, "again", llmproviders.WithMaxTokens(50))
+	}
+	if err != nil {
+		log.Printf("❌ Expected two turns, got error %v", err)
+		return false
+	}
+	check("The second turn sends", messageLabels(sent), "system hello r1 again")
+	check("The history records both answers", messageLabels(session.History()), "system hello r1 again r2")
+	check("Options", fmt.Sprintf("temperature %.1f, max tokens %d", sentOpts.Temperature, sentOpts.MaxTokens), "temperature 0.2, max tokens 50")
+
+	// Tool calls are recorded and answered with AddToolResults and Continue
+	answer = func() (*llmtypes.ContentResponse, error) {
+		return toolCallResponse(toolCall("call_1", "search", `{"q":"go"}`)), nil
+	}
+	_, err = session.Send(ctx, "search")
+	answer = nil
+	if err == nil {
+		session.AddToolResults(llmtypes.ToolCallResponse{ToolCallID: "call_1", Name: "search", Content: "found"})
+		_, err = session.Continue(ctx)
+	}
+	if err != nil {
+		log.Printf("❌ Expected the tool call answered, got error %v", err)
+		passed = false
+	} else {
+		check("Continue sends the tool exchange", messageLabels(sent), "system hello r1 again r2 search call:call_1 result:call_1")
+	}
+
+	// A failed turn is not recorded
+	before := messageLabels(session.History())
+	answer = func() (*llmtypes.ContentResponse, error) { return nil, fmt.Errorf("provider unavailable") }
+	_, err = session.Send(ctx, "lost")
+	answer = nil
+	if err == nil {
+		log.Printf("❌ Expected the failed turn to return its error")
+		passed = false
+	} else {
+		check("A failed turn leaves the history", messageLabels(session.History()), before)
+	}
+
+	// Checkpoints and forks
+	checkpoint := session.Checkpoint()
+	fork := session.Fork()
+	_, err = session.Send(ctx, "first")
+	if err == nil {
+		_, err = fork.Send(ctx, "forked")
+	}
+	diverged := messageLabels(session.History())
+	if err == nil {
+		err = session.Restore(checkpoint)
+	}
+	if err != nil {
+		log.Printf("❌ Expected the checkpoint restored, got error %v", err)
+		passed = false
+	} else {
+		check("The fork leaves the original", strings.TrimPrefix(diverged, before), " first r6")
+		check("The fork diverges", strings.TrimPrefix(messageLabels(fork.History()), before), " forked r7")
+		check("Restore rewinds to the checkpoint", messageLabels(session.History()), before)
+	}
+	if err := session.Restore(checkpoint + 10); err == nil {
+		log.Printf("❌ Expected an unknown checkpoint to be rejected")
+		passed = false
+	} else {
+		log.Printf("✅ An unknown checkpoint is rejected: %v", err)
+	}
+
+	// The history is compacted to the token budget
+	long := strings.Repeat(" lorem ipsum", 200)
+	compacted := llmproviders.NewChatSession(llm, llmproviders.WithSessionSystemPrompt("system prompt"),
+		llmproviders.WithSessionHistory([]llmtypes.MessageContent{
+			llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "old"+long),
+			llmtypes.TextParts(llmtypes.ChatMessageTypeAI, "older"+long),
+		}),
+		llmproviders.WithSessionMaxHistoryTokens(100))
+	if _, err := compacted.Send(ctx, "new"); err != nil {
+		log.Printf("❌ Expected the compacted turn to succeed, got error %v", err)
+		passed = false
+	} else {
+		check("The oldest messages are dropped", messageLabels(sent), "system new")
+	}
+	return passed
+}
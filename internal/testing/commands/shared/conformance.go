@@ -0,0 +1,100 @@
+package shared
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+
+	llmproviders "github.com/manishiitg/multi-llm-provider-go"
+	"github.com/manishiitg/multi-llm-provider-go/internal/testing"
+	"github.com/manishiitg/multi-llm-provider-go/pkg/llmtest"
+
+	"github.com/joho/godotenv"
+	"github.com/spf13/cobra"
+)
+
+// ConformanceCmd runs the llmtest conformance battery against a live model
+var ConformanceCmd = &cobra.Command{
+	Use:   "conformance",
+	Short: "Check a model against the conformance battery for its capabilities",
+	Long: `Run the llmtest conformance battery (plain text, tool calls, parallel tool calls,
+streaming, structured output and image input) against a model and print a pass/fail matrix.
+
+Tests are selected by the model's capabilities (LookupCapabilities), so vision is not
+tested on text-only models. Use --no-parallel for models that don't call tools in parallel.
+
+Examples:
+  llm-test conformance --provider openai --model gpt-4.1
+  llm-test conformance --provider vertex --model gemini-2.5-flash --no-parallel`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !RunConformance() {
+			os.Exit(1)
+		}
+	},
+}
+
+var (
+	conformanceProvider   string
+	conformanceModel      string
+	conformanceNoParallel bool
+)
+
+func init() {
+	ConformanceCmd.Flags().StringVar(&conformanceProvider, "provider", "", "Provider of the model (required)")
+	ConformanceCmd.Flags().StringVar(&conformanceModel, "model", "", "Model ID (required)")
+	ConformanceCmd.Flags().BoolVar(&conformanceNoParallel, "no-parallel", false, "Skip the parallel tool calls test")
+	_ = ConformanceCmd.MarkFlagRequired("provider")
+	_ = ConformanceCmd.MarkFlagRequired("model")
+}
+
+// logTB is an llmtest.TB that logs reported failures
+type logTB struct{}
+
+func (logTB) Helper() {}
+
+func (logTB) Errorf(format string, args ...interface{}) {
+	log.Printf("❌ "+format, args...)
+}
+
+// RunConformance runs the conformance battery against --provider and --model
+func RunConformance() bool {
+	// Load .env file for API keys
+	_ = godotenv.Load(".env")
+
+	provider, err := llmproviders.ValidateProvider(conformanceProvider)
+	if err != nil {
+		log.Printf("❌ %v", err)
+		return false
+	}
+	llm, err := llmproviders.InitializeLLM(llmproviders.Config{
+		Provider: provider,
+		ModelID:  conformanceModel,
+		Logger:   testing.GetTestLogger(),
+		Context:  context.Background(),
+	})
+	if err != nil {
+		log.Printf("❌ Failed to initialize %s: %v", conformanceModel, err)
+		return false
+	}
+
+	modelCaps := llmproviders.LookupCapabilities(conformanceModel)
+	caps := llmtest.Capabilities{
+		Tools:             modelCaps.Tools,
+		ParallelToolCalls: modelCaps.Tools && !conformanceNoParallel,
+		Streaming:         modelCaps.Streaming,
+		JSONSchema:        modelCaps.JSONSchema,
+		Vision:            modelCaps.Vision,
+	}
+	log.Printf("\n🧪 Conformance: %s/%s (%s)", provider, conformanceModel, modelCaps)
+
+	results := llmtest.RunConformance(logTB{}, llm, conformanceModel, caps)
+	fmt.Println()
+	llmtest.WriteMatrix(os.Stdout, string(provider)+"/"+conformanceModel, results)
+	for _, result := range results {
+		if result.Status == llmtest.StatusFailed {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,152 @@
+package shared
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+
+	llmproviders "github.com/manishiitg/multi-llm-provider-go"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+
+	"github.com/spf13/cobra"
+)
+
+// ContentBlocksTestCmd checks that responses keep the order of interleaved text and tool calls
+var ContentBlocksTestCmd = &cobra.Command{
+	Use:   "content-blocks",
+	Short: "Test that Choice.Blocks preserves interleaved text and tool call order",
+	Long: `This test checks, against a local fake Anthropic server, that:
+- a response with text, a tool call and more text keeps that order in Blocks
+- Content and ToolCalls are still filled in
+- OrderedBlocks leaves out tool calls dropped by WithMaxToolCallsPerResponse
+- the StreamAggregator rebuilds the same order from streamed chunks
+
+No API calls are made.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !RunContentBlocksTest() {
+			os.Exit(1)
+		}
+	},
+}
+
+// blockOrder describes blocks as "text:...", "reasoning:..." or "tool_call:name"
+func blockOrder(blocks []llmtypes.ContentBlock) string {
+	var order []string
+	for _, block := range blocks {
+		if block.Type == llmtypes.ContentBlockTypeToolCall {
+			order = append(order, fmt.Sprintf("%s:%s", block.Type, block.ToolCall.FunctionCall.Name))
+			continue
+		}
+		order = append(order, fmt.Sprintf("%s:%s", block.Type, block.Text))
+	}
+	return strings.Join(order, " | ")
+}
+
+// RunContentBlocksTest verifies the ordered content blocks of responses
+func RunContentBlocksTest() bool {
+	log.Printf("\n🧱 Test: Content Blocks")
+
+	// The fake server streams text, a tool call, more text and another tool call
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		events := []string{
+			`{"type":"message_start","message":{"id":"msg_1","type":"message","role":"assistant","model":"claude-sonnet-4-20250514","content":[],"stop_reason":null,"usage":{"input_tokens":10,"output_tokens":1}}}`,
+			`{"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}`,
+			`{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Let me read both files."}}`,
+			`{"type":"content_block_stop","index":0}`,
+			`{"type":"content_block_start","index":1,"content_block":{"type":"tool_use","id":"toolu_1","name":"read_file","input":{}}}`,
+			`{"type":"content_block_delta","index":1,"delta":{"type":"input_json_delta","partial_json":"{\"path\":\"a.txt\"}"}}`,
+			`{"type":"content_block_stop","index":1}`,
+			`{"type":"content_block_start","index":2,"content_block":{"type":"text","text":""}}`,
+			`{"type":"content_block_delta","index":2,"delta":{"type":"text_delta","text":"And the second one."}}`,
+			`{"type":"content_block_stop","index":2}`,
+			`{"type":"content_block_start","index":3,"content_block":{"type":"tool_use","id":"toolu_2","name":"list_dir","input":{}}}`,
+			`{"type":"content_block_delta","index":3,"delta":{"type":"input_json_delta","partial_json":"{\"path\":\".\"}"}}`,
+			`{"type":"content_block_stop","index":3}`,
+			`{"type":"message_delta","delta":{"stop_reason":"tool_use"},"usage":{"output_tokens":20}}`,
+			`{"type":"message_stop"}`,
+		}
+		for _, event := range events {
+			var header struct {
+				Type string `json:"type"`
+			}
+			_ = json.Unmarshal([]byte(event), &header)
+			fmt.Fprintf(w, "event: %s\ndata: %s\n\n", header.Type, event)
+		}
+	}))
+	defer server.Close()
+	target, _ := url.Parse(server.URL)
+
+	apiKey := "test"
+	llm, err := llmproviders.InitializeLLM(llmproviders.Config{
+		Provider:   llmproviders.ProviderAnthropic,
+		ModelID:    "claude-sonnet-4-20250514",
+		APIKeys:    &llmproviders.ProviderAPIKeys{Anthropic: &apiKey},
+		HTTPClient: &http.Client{Transport: redirectTransport{target: target}},
+	})
+	if err != nil {
+		log.Printf("❌ Failed to initialize Anthropic: %v", err)
+		return false
+	}
+	messages := []llmtypes.MessageContent{llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "Read a.txt and list the directory.")}
+
+	passed := true
+	want := "text:Let me read both files. | tool_call:read_file | text:And the second one. | tool_call:list_dir"
+	resp, err := llm.GenerateContent(context.Background(), messages)
+	if err != nil || len(resp.Choices) == 0 {
+		log.Printf("❌ Call failed: %v", err)
+		return false
+	}
+	choice := resp.Choices[0]
+	if got := blockOrder(choice.OrderedBlocks()); got != want {
+		log.Printf("❌ Expected blocks %q, got %q", want, got)
+		passed = false
+	} else if !strings.Contains(choice.Content, "Let me read both files.") || len(choice.ToolCalls) != 2 {
+		log.Printf("❌ Content and ToolCalls should still be set, got %q and %d tool calls", choice.Content, len(choice.ToolCalls))
+		passed = false
+	} else {
+		log.Printf("✅ Blocks keep the interleaved order: %s", want)
+	}
+
+	resp, err = llm.GenerateContent(context.Background(), messages, llmtypes.WithMaxToolCallsPerResponse(1))
+	want = "text:Let me read both files. | tool_call:read_file | text:And the second one."
+	if err != nil || blockOrder(resp.Choices[0].OrderedBlocks()) != want {
+		log.Printf("❌ With WithMaxToolCallsPerResponse(1) expected blocks %q, got %v (%v)", want, resp, err)
+		passed = false
+	} else {
+		log.Printf("✅ OrderedBlocks leaves out dropped tool calls")
+	}
+
+	aggregator := llmtypes.NewStreamAggregator()
+	for _, chunk := range []llmtypes.StreamChunk{
+		{Type: llmtypes.StreamChunkTypeReasoning, Content: "Need the file."},
+		{Type: llmtypes.StreamChunkTypeContent, Content: "Reading "},
+		{Type: llmtypes.StreamChunkTypeContent, Content: "it."},
+		{Type: llmtypes.StreamChunkTypeToolCall, ToolCall: &llmtypes.ToolCall{ID: "call_1", Type: "function", FunctionCall: &llmtypes.FunctionCall{Name: "read_file", Arguments: "{}"}}},
+		{Type: llmtypes.StreamChunkTypeContent, Content: "Done."},
+	} {
+		aggregator.Add(chunk)
+	}
+	want = "reasoning:Need the file. | text:Reading it. | tool_call:read_file | text:Done."
+	if got := blockOrder(aggregator.Response().Choices[0].OrderedBlocks()); got != want {
+		log.Printf("❌ Aggregated blocks should be %q, got %q", want, got)
+		passed = false
+	} else {
+		log.Printf("✅ StreamAggregator rebuilds the block order from chunks")
+	}
+
+	plain := &llmtypes.ContentChoice{Content: "Hi", ToolCalls: []llmtypes.ToolCall{{ID: "call_1", FunctionCall: &llmtypes.FunctionCall{Name: "search"}}}}
+	if got := blockOrder(plain.OrderedBlocks()); got != "text:Hi | tool_call:search" {
+		log.Printf("❌ Without Blocks, OrderedBlocks should be content then tool calls, got %q", got)
+		passed = false
+	} else {
+		log.Printf("✅ Without Blocks, OrderedBlocks returns content then tool calls")
+	}
+	return passed
+}
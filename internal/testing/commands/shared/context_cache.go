@@ -0,0 +1,145 @@
+package shared
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	llmproviders "github.com/manishiitg/multi-llm-provider-go"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+
+	"github.com/spf13/cobra"
+)
+
+// ContextCacheTestCmd checks Gemini explicit context caching
+var ContextCacheTestCmd = &cobra.Command{
+	Use:   "context-cache",
+	Short: "Test Gemini explicit context caching (CreateCachedContent and WithCachedContent)",
+	Long: `This test checks, against a local fake Gemini server, that:
+- CreateCachedContent sends the cached messages, with system messages as the system
+  instruction, the tools and the TTL, and returns the cache name
+- WithCachedContent references the cache and leaves out the call's tools
+- cached tokens are reported in the usage
+- providers without explicit caching return an error
+
+No API calls are made.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !RunContextCacheTest() {
+			os.Exit(1)
+		}
+	},
+}
+
+// RunContextCacheTest verifies creating, using and deleting a Gemini context cache
+func RunContextCacheTest() bool {
+	log.Printf("\n🗄️ Test: Context Cache")
+
+	var mu sync.Mutex
+	requests := map[string]map[string]any{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		var body map[string]any
+		_ = json.Unmarshal(data, &body)
+		mu.Lock()
+		requests[r.Method+" "+r.URL.Path] = body
+		mu.Unlock()
+
+		switch {
+		case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/cachedContents"):
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"name":"cachedContents/manual-1","model":"models/gemini-2.5-flash","expireTime":"2030-01-01T00:00:00Z","usageMetadata":{"totalTokenCount":100000}}`)
+		case r.Method == http.MethodDelete:
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{}`)
+		default:
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprint(w, "data: {\"candidates\":[{\"content\":{\"role\":\"model\",\"parts\":[{\"text\":\"Section 4.2\"}]},\"finishReason\":\"STOP\"}],\"usageMetadata\":{\"promptTokenCount\":100020,\"cachedContentTokenCount\":100000,\"candidatesTokenCount\":3,\"totalTokenCount\":100023}}\n\n")
+		}
+	}))
+	defer server.Close()
+	target, _ := url.Parse(server.URL)
+
+	apiKey := "test"
+	model, err := llmproviders.InitializeLLM(llmproviders.Config{
+		Provider:   llmproviders.ProviderVertex,
+		ModelID:    "gemini-2.5-flash",
+		APIKeys:    &llmproviders.ProviderAPIKeys{Vertex: &apiKey},
+		HTTPClient: &http.Client{Transport: redirectTransport{target: target}},
+	})
+	if err != nil {
+		log.Printf("❌ InitializeLLM: %v", err)
+		return false
+	}
+	llm := model.(*llmproviders.ProviderAwareLLM)
+
+	tools := []llmtypes.Tool{{Type: "function", Function: &llmtypes.FunctionDefinition{
+		Name: "lookup_section", Description: "Look up a manual section", Parameters: llmtypes.NewParameters(map[string]interface{}{"type": "object", "properties": map[string]interface{}{}}),
+	}}}
+	manual := []llmtypes.MessageContent{
+		llmtypes.TextParts(llmtypes.ChatMessageTypeSystem, "Answer from the manual."),
+		llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, strings.Repeat("Manual text. ", 50)),
+	}
+
+	passed := true
+	ctx := context.Background()
+	name, err := llm.CreateCachedContent(ctx, manual, time.Hour, llmtypes.WithTools(tools))
+	mu.Lock()
+	created := requests["POST /v1beta/cachedContents"]
+	mu.Unlock()
+	encoded, _ := json.Marshal(created)
+	if err != nil || name != "cachedContents/manual-1" || created == nil || created["ttl"] != "3600s" ||
+		!strings.Contains(string(encoded), `"systemInstruction":{"parts":[{"text":"Answer from the manual."}]`) ||
+		!strings.Contains(string(encoded), "Manual text.") || !strings.Contains(string(encoded), "lookup_section") {
+		log.Printf("❌ CreateCachedContent: expected the cache name and a request with the TTL, system instruction, contents and tools, got %q, err %v, request %s", name, err, encoded)
+		passed = false
+	} else {
+		log.Printf("✅ CreateCachedContent returns %s", name)
+	}
+
+	messages := []llmtypes.MessageContent{llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "Where is the reset procedure?")}
+	resp, err := llm.GenerateContent(ctx, messages, llmproviders.WithCachedContent(name), llmtypes.WithTools(tools))
+	mu.Lock()
+	generated := requests["POST /v1beta/models/gemini-2.5-flash:streamGenerateContent"]
+	mu.Unlock()
+	if err != nil || generated == nil || generated["cachedContent"] != "cachedContents/manual-1" || generated["tools"] != nil {
+		log.Printf("❌ WithCachedContent: expected a request referencing the cache without tools, got err %v, request %v", err, generated)
+		passed = false
+	} else {
+		log.Printf("✅ WithCachedContent references the cache and leaves out the tools")
+	}
+	if err == nil {
+		info := resp.Choices[0].GenerationInfo
+		if info == nil || info.CachedContentTokens == nil || *info.CachedContentTokens != 100000 || resp.Usage == nil || resp.Usage.CacheTokens == nil || *resp.Usage.CacheTokens != 100000 {
+			log.Printf("❌ Usage: expected 100000 cached tokens, got %+v / %+v", info, resp.Usage)
+			passed = false
+		} else {
+			log.Printf("✅ Usage reports %d cached tokens", *resp.Usage.CacheTokens)
+		}
+	}
+
+	if err := llm.DeleteCachedContent(ctx, name); err != nil {
+		log.Printf("❌ DeleteCachedContent: %v", err)
+		passed = false
+	}
+
+	openaiKey := "test"
+	other, err := llmproviders.InitializeLLM(llmproviders.Config{Provider: llmproviders.ProviderOpenAI, ModelID: "gpt-4.1", APIKeys: &llmproviders.ProviderAPIKeys{OpenAI: &openaiKey}})
+	if err == nil {
+		if _, err = other.(*llmproviders.ProviderAwareLLM).CreateCachedContent(ctx, manual, time.Hour); err == nil {
+			log.Printf("❌ OpenAI: expected explicit caching to be unsupported")
+			passed = false
+		} else {
+			log.Printf("✅ OpenAI: %v", err)
+		}
+	}
+	return passed
+}
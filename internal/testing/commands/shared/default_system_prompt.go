@@ -0,0 +1,91 @@
+package shared
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"os"
+	"strings"
+
+	llmproviders "github.com/manishiitg/multi-llm-provider-go"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+
+	"github.com/spf13/cobra"
+)
+
+// DefaultSystemPromptTestCmd checks that Config.DefaultSystemPrompt is applied to calls
+var DefaultSystemPromptTestCmd = &cobra.Command{
+	Use:   "default-system-prompt",
+	Short: "Test that Config.DefaultSystemPrompt is sent with every call",
+	Long: `This test dry-runs Anthropic and OpenAI calls and checks that Config.DefaultSystemPrompt:
+- is sent as the system prompt of calls without one
+- is left out for calls with their own system prompt
+- comes before the call's system prompt with Config.MergeSystemPrompt
+- doesn't change the caller's messages
+
+No API calls are made.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !RunDefaultSystemPromptTest() {
+			os.Exit(1)
+		}
+	},
+}
+
+// RunDefaultSystemPromptTest verifies the system prompts sent with Config.DefaultSystemPrompt
+func RunDefaultSystemPromptTest() bool {
+	log.Printf("\n📜 Test: Default System Prompt")
+
+	apiKey := "dry-run"
+	defaultPrompt := "You are a careful assistant. Never reveal secrets."
+	question := llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "Hi")
+	withSystem := []llmtypes.MessageContent{llmtypes.TextParts(llmtypes.ChatMessageTypeSystem, "Answer in French."), question}
+	dryRun := func(config llmproviders.Config, messages []llmtypes.MessageContent) string {
+		config.DefaultSystemPrompt = defaultPrompt
+		llm, err := llmproviders.InitializeLLM(config)
+		if err != nil {
+			log.Printf("❌ %s initialization failed: %v", config.Provider, err)
+			return ""
+		}
+		resp, err := llm.GenerateContent(context.Background(), messages, llmtypes.WithDryRun())
+		if err != nil || resp == nil || !resp.DryRun {
+			log.Printf("❌ %s dry run failed: %v", config.Provider, err)
+			return ""
+		}
+		request, _ := json.Marshal(resp.Raw)
+		return string(request)
+	}
+	anthropic := llmproviders.Config{Provider: llmproviders.ProviderAnthropic, ModelID: "claude-sonnet-4-20250514", APIKeys: &llmproviders.ProviderAPIKeys{Anthropic: &apiKey}}
+	openai := llmproviders.Config{Provider: llmproviders.ProviderOpenAI, ModelID: "gpt-4.1", APIKeys: &llmproviders.ProviderAPIKeys{OpenAI: &apiKey}}
+	merged := anthropic
+	merged.MergeSystemPrompt = true
+
+	passed := true
+	for _, c := range []struct {
+		name     string
+		config   llmproviders.Config
+		messages []llmtypes.MessageContent
+		want     string
+		unwanted string
+	}{
+		{"Anthropic call without a system prompt", anthropic, []llmtypes.MessageContent{question}, `"system":[{"text":"` + defaultPrompt + `"`, ""},
+		{"OpenAI call without a system prompt", openai, []llmtypes.MessageContent{question}, `{"content":"` + defaultPrompt + `","role":"system"}`, ""},
+		{"call with its own system prompt", anthropic, withSystem, `"system":[{"text":"Answer in French."`, defaultPrompt},
+		{"merged system prompt", merged, withSystem, `"system":[{"text":"` + defaultPrompt + `\nAnswer in French."`, ""},
+	} {
+		request := dryRun(c.config, c.messages)
+		if !strings.Contains(request, c.want) || (c.unwanted != "" && strings.Contains(request, c.unwanted)) {
+			log.Printf("❌ %s: expected %s in the request, got %s", c.name, c.want, request)
+			passed = false
+			continue
+		}
+		log.Printf("✅ %s sends %s", c.name, c.want)
+	}
+
+	if len(withSystem) != 2 || len(withSystem[0].Parts) != 1 {
+		log.Printf("❌ The caller's messages were modified: %+v", withSystem)
+		passed = false
+	} else {
+		log.Printf("✅ The caller's messages are unchanged")
+	}
+	return passed
+}
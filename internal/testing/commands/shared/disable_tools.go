@@ -0,0 +1,150 @@
+package shared
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"os"
+	"strings"
+
+	llmproviders "github.com/manishiitg/multi-llm-provider-go"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+
+	"github.com/spf13/cobra"
+)
+
+// DisableToolsTestCmd checks that WithDisableTools suppresses tools for one call
+var DisableToolsTestCmd = &cobra.Command{
+	Use:   "disable-tools",
+	Short: "Test that WithDisableTools makes the model answer without tools",
+	Long: `This test checks that WithDisableTools, wherever it appears in the options:
+- sends no tools when the history has no tool calls (OpenAI dry run)
+- keeps the tools with tool_choice "none" when the history has tool calls (OpenAI and
+  Anthropic dry runs), since Anthropic rejects tool history without tool definitions
+- sends the tool history as text without tools on Bedrock, which has no "none" choice
+- composes with a ChatSession whose options set tools
+
+No API calls are made.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !RunDisableToolsTest() {
+			os.Exit(1)
+		}
+	},
+}
+
+// recordingModel is a fake model that records the messages and options of the last call
+type recordingModel struct {
+	fakeModelID
+	messages []llmtypes.MessageContent
+	opts     *llmtypes.CallOptions
+}
+
+func (m *recordingModel) GenerateContent(ctx context.Context, messages []llmtypes.MessageContent, options ...llmtypes.CallOption) (*llmtypes.ContentResponse, error) {
+	m.messages = messages
+	m.opts = &llmtypes.CallOptions{}
+	for _, opt := range options {
+		opt(m.opts)
+	}
+	return textResponse("Done."), nil
+}
+
+func (m *recordingModel) Call(ctx context.Context, prompt string, options ...llmtypes.CallOption) (string, error) {
+	return "Done.", nil
+}
+
+// RunDisableToolsTest verifies the requests built with WithDisableTools
+func RunDisableToolsTest() bool {
+	log.Printf("\n🚫 Test: Disable Tools")
+
+	tools := []llmtypes.Tool{{Type: "function", Function: &llmtypes.FunctionDefinition{
+		Name:        "get_weather",
+		Description: "Get the weather for a city",
+		Parameters:  llmtypes.NewParameters(map[string]interface{}{"type": "object", "properties": map[string]interface{}{"city": map[string]interface{}{"type": "string"}}}),
+	}}}
+	question := []llmtypes.MessageContent{llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "What's the weather in Paris?")}
+	withToolHistory := append(append([]llmtypes.MessageContent{}, question...),
+		llmtypes.MessageContent{Role: llmtypes.ChatMessageTypeAI, Parts: []llmtypes.ContentPart{
+			llmtypes.ToolCall{ID: "call_1", Type: "function", FunctionCall: &llmtypes.FunctionCall{Name: "get_weather", Arguments: `{"city":"Paris"}`}},
+		}},
+		llmtypes.MessageContent{Role: llmtypes.ChatMessageTypeTool, Parts: []llmtypes.ContentPart{
+			llmtypes.ToolCallResponse{ToolCallID: "call_1", Name: "get_weather", Content: "Sunny, 22°C"},
+		}},
+		llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "Summarize."),
+	)
+
+	dryRun := func(provider llmproviders.Provider, modelID string, keys *llmproviders.ProviderAPIKeys, messages []llmtypes.MessageContent) string {
+		llm, err := llmproviders.InitializeLLM(llmproviders.Config{Provider: provider, ModelID: modelID, APIKeys: keys})
+		if err != nil {
+			log.Printf("❌ %s initialization failed: %v", provider, err)
+			return ""
+		}
+		// WithDisableTools comes first to check that a later WithTools doesn't win
+		resp, err := llm.GenerateContent(context.Background(), messages, llmtypes.WithDisableTools(), llmtypes.WithTools(tools), llmtypes.WithDryRun())
+		if err != nil || resp == nil || !resp.DryRun {
+			log.Printf("❌ %s dry run failed: %v", provider, err)
+			return ""
+		}
+		request, _ := json.Marshal(resp.Raw)
+		return string(request)
+	}
+
+	passed := true
+	apiKey := "dry-run"
+	openaiKeys := &llmproviders.ProviderAPIKeys{OpenAI: &apiKey}
+	if request := dryRun(llmproviders.ProviderOpenAI, "gpt-4.1", openaiKeys, question); request == "" || strings.Contains(request, `"tools"`) {
+		log.Printf("❌ OpenAI request still has tools: %s", request)
+		passed = false
+	} else {
+		log.Printf("✅ OpenAI request without tool history has no tools")
+	}
+	if request := dryRun(llmproviders.ProviderOpenAI, "gpt-4.1", openaiKeys, withToolHistory); request == "" || !strings.Contains(request, `"tool_choice":"none"`) {
+		log.Printf("❌ OpenAI request with tool history lacks tool_choice none: %s", request)
+		passed = false
+	} else {
+		log.Printf("✅ OpenAI request with tool history has tool_choice none")
+	}
+	anthropicKeys := &llmproviders.ProviderAPIKeys{Anthropic: &apiKey}
+	if request := dryRun(llmproviders.ProviderAnthropic, "claude-sonnet-4-20250514", anthropicKeys, withToolHistory); request == "" || !strings.Contains(request, `"tool_choice":{"type":"none"}`) || !strings.Contains(request, `"get_weather"`) {
+		log.Printf("❌ Anthropic request with tool history lacks tools with tool_choice none: %s", request)
+		passed = false
+	} else {
+		log.Printf("✅ Anthropic request with tool history keeps tools with tool_choice none")
+	}
+
+	model := &recordingModel{}
+	bedrock := llmproviders.NewProviderAwareLLM(model, llmproviders.ProviderBedrock, "fake-model", nil, "disable-tools-test", nil)
+	if _, err := bedrock.GenerateContent(context.Background(), withToolHistory, llmtypes.WithTools(tools), llmtypes.WithDisableTools()); err != nil {
+		log.Printf("❌ Bedrock call failed: %v", err)
+		return false
+	}
+	toolParts := 0
+	for _, msg := range model.messages {
+		for _, part := range msg.Parts {
+			switch part.(type) {
+			case llmtypes.ToolCall, llmtypes.ToolCallResponse:
+				toolParts++
+			}
+		}
+	}
+	if len(model.opts.Tools) > 0 || toolParts > 0 {
+		log.Printf("❌ Bedrock got %d tools and %d tool parts, expected none", len(model.opts.Tools), toolParts)
+		passed = false
+	} else {
+		log.Printf("✅ Bedrock gets the tool history as text and no tools")
+	}
+
+	session := llmproviders.NewChatSession(
+		llmproviders.NewProviderAwareLLM(model, llmproviders.ProviderOpenAI, "fake-model", nil, "disable-tools-test", nil),
+		llmproviders.WithSessionCallOptions(llmtypes.WithTools(tools)),
+	)
+	if _, err := session.Send(context.Background(), "Hi", llmtypes.WithDisableTools()); err != nil || len(model.opts.Tools) > 0 {
+		log.Printf("❌ ChatSession turn with WithDisableTools sent %d tools (err: %v)", len(model.opts.Tools), err)
+		passed = false
+	} else if _, err := session.Send(context.Background(), "Again"); err != nil || len(model.opts.Tools) != 1 {
+		log.Printf("❌ Next ChatSession turn lost its tools (err: %v)", err)
+		passed = false
+	} else {
+		log.Printf("✅ ChatSession keeps its tools and drops them for one turn")
+	}
+	return passed
+}
@@ -0,0 +1,147 @@
+package shared
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/manishiitg/multi-llm-provider-go/internal/testing"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+	bedrockadapter "github.com/manishiitg/multi-llm-provider-go/pkg/adapters/bedrock"
+	vertexadapter "github.com/manishiitg/multi-llm-provider-go/pkg/adapters/vertex"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
+	"github.com/spf13/cobra"
+	"google.golang.org/genai"
+)
+
+// EmbeddingTaskTypeTestCmd checks that embedding task types reach each provider
+var EmbeddingTaskTypeTestCmd = &cobra.Command{
+	Use:   "embedding-task-type",
+	Short: "Test embedding task types for query and document embeddings (WithEmbeddingTaskType)",
+	Long: `This test checks, against local fake Bedrock and Gemini servers, that:
+- WithEmbeddingTaskType sets the Cohere input_type on Bedrock (search_document by default)
+  and the task type on Gemini, so one model embeds both queries and documents
+- WithInputType accepts provider names (search_query, RETRIEVAL_DOCUMENT)
+- task types a model doesn't support are rejected before any request
+- models without task types (Amazon Titan) ignore them
+
+No API calls are made.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !RunEmbeddingTaskTypeTest() {
+			os.Exit(1)
+		}
+	},
+}
+
+// RunEmbeddingTaskTypeTest verifies the task type sent with embedding requests
+func RunEmbeddingTaskTypeTest() bool {
+	log.Printf("\n🧭 Test: Embedding task types")
+
+	var mu sync.Mutex
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		mu.Lock()
+		requests = append(requests, string(data))
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		switch {
+		case strings.Contains(r.URL.Path, "batchEmbedContents"):
+			fmt.Fprint(w, `{"embeddings":[{"values":[0.1,0.2]}]}`)
+		case strings.Contains(r.URL.Path, "titan"):
+			fmt.Fprint(w, `{"embedding":[0.1,0.2],"inputTextTokenCount":1}`)
+		default:
+			fmt.Fprint(w, `{"embeddings":[[0.1,0.2]]}`)
+		}
+	}))
+	defer server.Close()
+	target, _ := url.Parse(server.URL)
+
+	bedrockClient := bedrockruntime.New(bedrockruntime.Options{
+		Region:       "us-east-1",
+		Credentials:  aws.AnonymousCredentials{},
+		BaseEndpoint: aws.String(server.URL),
+	})
+	genaiClient, err := genai.NewClient(context.Background(), &genai.ClientConfig{
+		APIKey:     "test",
+		Backend:    genai.BackendGeminiAPI,
+		HTTPClient: &http.Client{Transport: redirectTransport{target: target}},
+	})
+	if err != nil {
+		log.Printf("❌ Failed to create GenAI client: %v", err)
+		return false
+	}
+	cohere := bedrockadapter.NewBedrockAdapter(bedrockClient, "cohere.embed-english-v3", testing.GetTestLogger())
+	titan := bedrockadapter.NewBedrockAdapter(bedrockClient, "amazon.titan-embed-text-v2:0", testing.GetTestLogger())
+	gemini := vertexadapter.NewGoogleGenAIAdapter(genaiClient, "gemini-embedding-001", testing.GetTestLogger())
+
+	embed := func(model llmtypes.EmbeddingModel, options ...llmtypes.EmbeddingOption) (string, error) {
+		mu.Lock()
+		requests = nil
+		mu.Unlock()
+		_, err := model.GenerateEmbeddings(context.Background(), "Hello", options...)
+		mu.Lock()
+		defer mu.Unlock()
+		return strings.Join(requests, "\n"), err
+	}
+
+	passed := true
+	check := func(name string, model llmtypes.EmbeddingModel, field, want string, options ...llmtypes.EmbeddingOption) {
+		body, err := embed(model, options...)
+		var fields map[string]interface{}
+		_ = json.Unmarshal([]byte(body), &fields)
+		got := fmt.Sprint(fields[field])
+		if field == "taskType" {
+			// Gemini batches requests: {"requests":[{"taskType":...}]}
+			got = "<nil>"
+			if i := strings.Index(body, `"taskType":"`); i >= 0 {
+				got = strings.SplitN(body[i+len(`"taskType":"`):], `"`, 2)[0]
+			}
+		}
+		if err != nil || got != want {
+			log.Printf("❌ %s: expected %s %s, got %s (err %v)", name, field, want, got, err)
+			passed = false
+			return
+		}
+		log.Printf("✅ %s sends %s %s", name, field, want)
+	}
+
+	check("Cohere default", cohere, "input_type", "search_document")
+	check("Cohere query", cohere, "input_type", "search_query", llmtypes.WithEmbeddingTaskType(llmtypes.EmbeddingTaskRetrievalQuery))
+	check("Cohere document", cohere, "input_type", "search_document", llmtypes.WithEmbeddingTaskType(llmtypes.EmbeddingTaskRetrievalDocument))
+	check("Cohere WithInputType(search_query)", cohere, "input_type", "search_query", llmtypes.WithInputType("search_query"))
+	check("Cohere clustering", cohere, "input_type", "clustering", llmtypes.WithEmbeddingTaskType(llmtypes.EmbeddingTaskClustering))
+	check("Gemini default", gemini, "taskType", "<nil>")
+	check("Gemini query", gemini, "taskType", "RETRIEVAL_QUERY", llmtypes.WithEmbeddingTaskType(llmtypes.EmbeddingTaskRetrievalQuery))
+	check("Gemini WithInputType(search_document)", gemini, "taskType", "RETRIEVAL_DOCUMENT", llmtypes.WithInputType("search_document"))
+	check("Gemini WithInputType(CODE_RETRIEVAL_QUERY)", gemini, "taskType", "CODE_RETRIEVAL_QUERY", llmtypes.WithInputType("CODE_RETRIEVAL_QUERY"))
+	check("Titan ignores the task type", titan, "input_type", "<nil>", llmtypes.WithEmbeddingTaskType(llmtypes.EmbeddingTaskRetrievalQuery))
+
+	for _, tc := range []struct {
+		name     string
+		model    llmtypes.EmbeddingModel
+		taskType llmtypes.EmbeddingTaskType
+	}{
+		{"Cohere semantic_similarity", cohere, llmtypes.EmbeddingTaskSemanticSimilarity},
+		{"Gemini unknown task type", gemini, "search_everything"},
+	} {
+		body, err := embed(tc.model, llmtypes.WithEmbeddingTaskType(tc.taskType))
+		if err == nil || !strings.Contains(err.Error(), "supported") || body != "" {
+			log.Printf("❌ %s: expected an error and no request, got %v", tc.name, err)
+			passed = false
+			continue
+		}
+		log.Printf("✅ %s is rejected: %v", tc.name, err)
+	}
+	return passed
+}
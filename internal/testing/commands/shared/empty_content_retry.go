@@ -0,0 +1,116 @@
+package shared
+
+import (
+	"context"
+	"log"
+	"os"
+	"strings"
+
+	llmproviders "github.com/manishiitg/multi-llm-provider-go"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+
+	"github.com/spf13/cobra"
+)
+
+// EmptyContentRetryTestCmd checks WithRetryOnEmptyContent
+var EmptyContentRetryTestCmd = &cobra.Command{
+	Use:   "empty-content-retry",
+	Short: "Test retrying calls that return no content and no tool calls",
+	Long: `This test checks, with a fake model, that WithRetryOnEmptyContent:
+- repeats a call answered with an empty response until it gets content
+- gives up after n retries with the usual empty content error
+- doesn't retry responses stopped by a content filter, which fail with a content filter error
+- retries streaming calls, sending only the final response's chunks
+
+No API calls are made.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !RunEmptyContentRetryTest() {
+			os.Exit(1)
+		}
+	},
+}
+
+// emptyContentModel is a fake model whose first empties calls return an empty response
+// with stopReason
+type emptyContentModel struct {
+	fakeModelID
+	empties    int
+	stopReason string
+	calls      int
+}
+
+func (m *emptyContentModel) GenerateContent(ctx context.Context, messages []llmtypes.MessageContent, options ...llmtypes.CallOption) (*llmtypes.ContentResponse, error) {
+	opts := callOptions(options)
+	m.calls++
+	content, stopReason := "Hello there", "stop"
+	if m.calls <= m.empties {
+		content, stopReason = "", m.stopReason
+	}
+	return streamChoice(opts, &llmtypes.ContentChoice{Content: content, StopReason: stopReason}), nil
+}
+
+// RunEmptyContentRetryTest verifies which empty responses are retried and how often
+func RunEmptyContentRetryTest() bool {
+	log.Printf("\n🫙 Test: Empty Content Retry")
+
+	messages := []llmtypes.MessageContent{llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "Hi")}
+	call := func(model *emptyContentModel, options ...llmtypes.CallOption) (*llmtypes.ContentResponse, error) {
+		llm := llmproviders.NewProviderAwareLLM(model, llmproviders.ProviderOpenAI, "fake-model", nil, "empty-content-retry-test", nil)
+		return llm.GenerateContent(context.Background(), messages, options...)
+	}
+
+	passed := true
+	model := &emptyContentModel{empties: 2}
+	if resp, err := call(model, llmproviders.WithRetryOnEmptyContent(2)); err != nil || resp.Choices[0].Content != "Hello there" || model.calls != 3 {
+		log.Printf("❌ Retry: expected content after 3 calls, got %d calls, err %v", model.calls, err)
+		passed = false
+	} else {
+		log.Printf("✅ Two empty responses are retried (%d calls)", model.calls)
+	}
+
+	model = &emptyContentModel{empties: 3}
+	if _, err := call(model, llmproviders.WithRetryOnEmptyContent(2)); err == nil || !strings.Contains(err.Error(), "empty") || model.calls != 3 {
+		log.Printf("❌ Exhausted: expected the empty content error after 3 calls, got %d calls, err %v", model.calls, err)
+		passed = false
+	} else {
+		log.Printf("✅ Gives up after 2 retries: %v", err)
+	}
+
+	model = &emptyContentModel{empties: 1}
+	if _, err := call(model); err == nil || model.calls != 1 {
+		log.Printf("❌ Default: expected no retry without the option, got %d calls, err %v", model.calls, err)
+		passed = false
+	} else {
+		log.Printf("✅ Empty responses are not retried by default")
+	}
+
+	for _, stopReason := range []string{"content_filter", "SAFETY", "refusal", "guardrail_intervened"} {
+		model = &emptyContentModel{empties: 1, stopReason: stopReason}
+		if _, err := call(model, llmproviders.WithRetryOnEmptyContent(2)); err == nil || !strings.Contains(err.Error(), "content filter") || model.calls != 1 {
+			log.Printf("❌ Content filter %q: expected no retry and a content filter error, got %d calls, err %v", stopReason, model.calls, err)
+			passed = false
+		}
+	}
+	if passed {
+		log.Printf("✅ Content-filtered empty responses are not retried")
+	}
+
+	model = &emptyContentModel{empties: 1}
+	streamChan := make(chan llmtypes.StreamChunk, 10)
+	resp, err := call(model, llmproviders.WithRetryOnEmptyContent(1), llmtypes.WithStreamingChan(streamChan))
+	var streamed strings.Builder
+	finishes := 0
+	for chunk := range streamChan {
+		streamed.WriteString(chunk.Content)
+		if chunk.Type == llmtypes.StreamChunkTypeFinish {
+			finishes++
+		}
+	}
+	if err != nil || resp.Choices[0].Content != "Hello there" || streamed.String() != "Hello there" || finishes != 1 || model.calls != 2 {
+		log.Printf("❌ Streaming: expected one retry streaming %q and one finish chunk, got %d calls, streamed %q with %d finish chunks, err %v", "Hello there", model.calls, streamed.String(), finishes, err)
+		passed = false
+	} else {
+		log.Printf("✅ Streaming call is retried and streams only the final response")
+	}
+	return passed
+}
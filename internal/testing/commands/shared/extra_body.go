@@ -0,0 +1,118 @@
+package shared
+
+import (
+	"context"
+	"log"
+	"os"
+	"strings"
+
+	llmproviders "github.com/manishiitg/multi-llm-provider-go"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
+
+	"github.com/spf13/cobra"
+)
+
+// ExtraBodyTestCmd checks that WithExtraBody and WithExtraHeaders reach the provider request
+var ExtraBodyTestCmd = &cobra.Command{
+	Use:   "extra-body",
+	Short: "Test that WithExtraBody and WithExtraHeaders are merged into the provider request",
+	Long: `This test sends OpenAI and Anthropic calls to a local server and checks that:
+- WithExtraBody fields are added to the request body
+- fields set by typed options win over extra fields of the same name
+- extra objects are merged into the typed ones rather than replacing them
+- WithExtraHeaders headers are sent, and an extra anthropic-beta is combined with the adapter's
+- Bedrock sends the extra fields as additionalModelRequestFields (dry run, skipped without AWS config)
+
+No API calls are made.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !RunExtraBodyTest() {
+			os.Exit(1)
+		}
+	},
+}
+
+// RunExtraBodyTest verifies the requests built with extra body fields and headers
+func RunExtraBodyTest() bool {
+	log.Printf("\n🧳 Test: Extra Body")
+
+	apiKey := "test"
+	keys := &llmproviders.ProviderAPIKeys{OpenAI: &apiKey, Anthropic: &apiKey}
+	messages := []llmtypes.MessageContent{llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "Hello")}
+
+	passed := true
+	for _, c := range []struct {
+		provider   llmproviders.Provider
+		modelID    string
+		response   string
+		options    []llmtypes.CallOption
+		want       []string
+		unwanted   []string
+		header     string
+		wantHeader string
+	}{
+		{llmproviders.ProviderOpenAI, "gpt-4.1", openAIHelloResponse, []llmtypes.CallOption{
+			llmtypes.WithTemperature(0.5),
+			llmproviders.WithExtraBody(map[string]interface{}{"temperature": 0.9, "prompt_cache_key": "team-a"}),
+			llmproviders.WithExtraHeaders(map[string]string{"X-Team": "search"}),
+		}, []string{`"temperature":0.5`, `"prompt_cache_key":"team-a"`}, []string{`"temperature":0.9`}, "X-Team", "search"},
+		{llmproviders.ProviderAnthropic, "claude-sonnet-4-20250514", anthropicHelloResponse, []llmtypes.CallOption{
+			llmproviders.WithAnthropicParams(llmproviders.AnthropicParams{UserID: "user-1"}),
+			llmproviders.WithExtraBody(map[string]interface{}{"metadata": map[string]interface{}{"user_id": "user-2", "team": "search"}}),
+			llmproviders.WithExtraHeaders(map[string]string{"anthropic-beta": "files-api-2025-04-14"}),
+		}, []string{`"user_id":"user-1"`, `"team":"search"`}, []string{`"user-2"`}, "anthropic-beta", "prompt-caching-2024-07-31,files-api-2025-04-14"},
+	} {
+		server := newRecordingServer(c.response)
+		llm, err := llmproviders.InitializeLLM(llmproviders.Config{Provider: c.provider, ModelID: c.modelID, APIKeys: keys, HTTPClient: server.client()})
+		if err == nil {
+			_, err = llm.GenerateContent(context.Background(), messages, c.options...)
+		}
+		server.Close()
+		if err != nil {
+			log.Printf("❌ %s call failed: %v", c.provider, err)
+			passed = false
+			continue
+		}
+		ok := true
+		for _, field := range c.want {
+			if !strings.Contains(server.body, field) {
+				log.Printf("❌ %s request is missing %s: %s", c.provider, field, server.body)
+				ok = false
+			}
+		}
+		for _, field := range c.unwanted {
+			if strings.Contains(server.body, field) {
+				log.Printf("❌ %s request should not contain %s: %s", c.provider, field, server.body)
+				ok = false
+			}
+		}
+		if got := server.headers.Get(c.header); got != c.wantHeader {
+			log.Printf("❌ %s request should have header %s: %s, got %q", c.provider, c.header, c.wantHeader, got)
+			ok = false
+		}
+		if ok {
+			log.Printf("✅ %s merges the extra body with typed fields winning (%s) and sends %s: %s",
+				c.provider, strings.Join(c.want, " "), c.header, c.wantHeader)
+		}
+		passed = passed && ok
+	}
+
+	bedrock, err := llmproviders.InitializeLLM(llmproviders.Config{Provider: llmproviders.ProviderBedrock, ModelID: "us.anthropic.claude-sonnet-4-20250514-v1:0"})
+	if err != nil {
+		log.Printf("⚠️  Bedrock: skipped, %v", err)
+		return passed
+	}
+	resp, err := bedrock.GenerateContent(context.Background(), messages,
+		llmproviders.WithExtraBody(map[string]interface{}{"top_k": 40}), llmtypes.WithDryRun())
+	var fields []byte
+	if input, ok := resp.Raw.(*bedrockruntime.ConverseInput); err == nil && ok && input.AdditionalModelRequestFields != nil {
+		fields, _ = input.AdditionalModelRequestFields.MarshalSmithyDocument()
+	}
+	if string(fields) != `{"top_k":40}` {
+		log.Printf("❌ Bedrock: expected additionalModelRequestFields {\"top_k\":40}, got %s (error %v)", fields, err)
+		return false
+	}
+	log.Printf("✅ Bedrock sends the extra body as additionalModelRequestFields")
+	return passed
+}
@@ -0,0 +1,131 @@
+package shared
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+
+	llmproviders "github.com/manishiitg/multi-llm-provider-go"
+	"github.com/manishiitg/multi-llm-provider-go/internal/testing"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+// Scaffolding for the checks that run against a fake model instead of a provider
+
+// fakeModelID gives the fake model embedding it the model ID "fake-model"
+type fakeModelID struct{}
+
+func (fakeModelID) GetModelID() string {
+	return "fake-model"
+}
+
+// fakeModelFunc is a fake model answering every call with the function, given the call's
+// messages and parsed options
+type fakeModelFunc func(ctx context.Context, messages []llmtypes.MessageContent, opts *llmtypes.CallOptions) (*llmtypes.ContentResponse, error)
+
+func (f fakeModelFunc) GenerateContent(ctx context.Context, messages []llmtypes.MessageContent, options ...llmtypes.CallOption) (*llmtypes.ContentResponse, error) {
+	return f(ctx, messages, callOptions(options))
+}
+
+func (fakeModelFunc) GetModelID() string {
+	return "fake-model"
+}
+
+// newFakeLLM wraps a fake model for provider, logging to the test logger
+func newFakeLLM(model llmtypes.Model, provider llmproviders.Provider) *llmproviders.ProviderAwareLLM {
+	return llmproviders.NewProviderAwareLLM(model, provider, model.GetModelID(), nil, "trace", testing.GetTestLogger())
+}
+
+// callOptions returns the CallOptions set by the options of a call
+func callOptions(options []llmtypes.CallOption) *llmtypes.CallOptions {
+	opts := &llmtypes.CallOptions{}
+	for _, option := range options {
+		option(opts)
+	}
+	return opts
+}
+
+// textResponse returns a response with a single choice answering text
+func textResponse(text string) *llmtypes.ContentResponse {
+	return &llmtypes.ContentResponse{Choices: []*llmtypes.ContentChoice{{Content: text, StopReason: "stop"}}}
+}
+
+// toolCall returns a function tool call
+func toolCall(id, name, arguments string) llmtypes.ToolCall {
+	return llmtypes.ToolCall{ID: id, Type: "function", FunctionCall: &llmtypes.FunctionCall{Name: name, Arguments: arguments}}
+}
+
+// toolCallResponse returns a response with a single choice calling tools
+func toolCallResponse(calls ...llmtypes.ToolCall) *llmtypes.ContentResponse {
+	return &llmtypes.ContentResponse{Choices: []*llmtypes.ContentChoice{{ToolCalls: calls, StopReason: "tool_calls"}}}
+}
+
+// streamChoice answers a call with choice. If the call streams, the content is sent in
+// pieces (whole when none are given), then the tool calls and a finish chunk, and the
+// stream is closed as adapters do.
+func streamChoice(opts *llmtypes.CallOptions, choice *llmtypes.ContentChoice, pieces ...string) *llmtypes.ContentResponse {
+	resp := &llmtypes.ContentResponse{Choices: []*llmtypes.ContentChoice{choice}}
+	if opts.StreamChan != nil {
+		if len(pieces) == 0 {
+			pieces = []string{choice.Content}
+		}
+		for _, piece := range pieces {
+			if piece != "" {
+				opts.StreamChan <- llmtypes.StreamChunk{Type: llmtypes.StreamChunkTypeContent, Content: piece}
+			}
+		}
+		for i := range choice.ToolCalls {
+			opts.StreamChan <- llmtypes.StreamChunk{Type: llmtypes.StreamChunkTypeToolCall, ToolCall: &choice.ToolCalls[i]}
+		}
+		opts.StreamChan <- llmtypes.NewFinishChunk(resp)
+		close(opts.StreamChan)
+	}
+	return resp
+}
+
+// Responses of fake provider endpoints answering "Hello"
+const (
+	openAIHelloResponse = `{"id":"chatcmpl-1","object":"chat.completion","created":1,"model":"gpt-4.1","choices":[{"index":0,"message":{"role":"assistant","content":"Hello"},"finish_reason":"stop"}]}`
+	// The Anthropic adapter always streams
+	anthropicHelloResponse = "event: message_start\n" +
+		`data: {"type":"message_start","message":{"id":"msg_1","type":"message","role":"assistant","model":"claude-sonnet-4-20250514","content":[],"stop_reason":null,"usage":{"input_tokens":1,"output_tokens":1}}}` + "\n\n" +
+		"event: content_block_start\n" + `data: {"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}` + "\n\n" +
+		"event: content_block_delta\n" + `data: {"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Hello"}}` + "\n\n" +
+		"event: content_block_stop\n" + `data: {"type":"content_block_stop","index":0}` + "\n\n" +
+		"event: message_delta\n" + `data: {"type":"message_delta","delta":{"stop_reason":"end_turn"},"usage":{"output_tokens":1}}` + "\n\n" +
+		"event: message_stop\n" + `data: {"type":"message_stop"}` + "\n\n"
+)
+
+// recordingServer is a fake provider endpoint answering every request with a fixed JSON
+// body or event stream and recording the body and headers of the last request
+type recordingServer struct {
+	*httptest.Server
+	body    string
+	headers http.Header
+}
+
+// newRecordingServer starts a recordingServer answering with response; close it when done
+func newRecordingServer(response string) *recordingServer {
+	s := &recordingServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		s.body, s.headers = string(data), r.Header.Clone()
+		if strings.HasPrefix(response, "event:") {
+			w.Header().Set("Content-Type", "text/event-stream")
+		} else {
+			w.Header().Set("Content-Type", "application/json")
+		}
+		fmt.Fprint(w, response)
+	}))
+	return s
+}
+
+// client returns an HTTP client sending every request to the server, for Config.HTTPClient
+func (s *recordingServer) client() *http.Client {
+	target, _ := url.Parse(s.URL)
+	return &http.Client{Transport: redirectTransport{target: target}}
+}
@@ -0,0 +1,443 @@
+package shared
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	llmproviders "github.com/manishiitg/multi-llm-provider-go"
+	"github.com/manishiitg/multi-llm-provider-go/internal/recorder"
+	"github.com/manishiitg/multi-llm-provider-go/internal/testing"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+// fixtureVersion is the format version of fixture files; fixtures of another version must
+// be recorded again
+const fixtureVersion = 1
+
+// fixtureProviders are the providers whose adapters support record/replay
+var fixtureProviders = []llmproviders.Provider{llmproviders.ProviderOpenAI, llmproviders.ProviderOpenRouter, llmproviders.ProviderVertex, llmproviders.ProviderBedrock}
+
+// FixturesCmd records the fixture battery against a live provider, or replays it and checks
+// the results against the recorded fixtures
+var FixturesCmd = &cobra.Command{
+	Use:   "fixtures",
+	Short: "Record or verify deterministic fixtures of the standard test battery",
+	Long: `Runs the standard test battery (plain text, tool call, tool result, JSON mode and
+streaming) for one model.
+
+With --record, the battery runs against the live provider. Responses are saved through the
+recorder and a fixture file summarizes each response:
+- stop reason
+- whether there is content
+- tool call names and argument keys
+- token usage
+
+Without --record, every fixture in --fixture-dir (or only --provider/--model) is replayed
+from the recorded responses, and the summaries must match the fixture. Response text may
+differ; the structure may not.
+
+Replay makes no API calls and needs no credentials, so it can run in CI.
+
+Examples:
+  llm-test fixtures --record --provider openai --model gpt-4.1
+  llm-test fixtures`,
+	Run: func(cmd *cobra.Command, args []string) {
+		logFile := viper.GetString("log-file")
+		logLevel := viper.GetString("log-level")
+		testing.InitTestLogger(logFile, logLevel)
+
+		if fixturesFlags.record {
+			if fixturesFlags.provider == "" || fixturesFlags.model == "" {
+				log.Fatal("--provider and --model are required with --record")
+			}
+			path, err := RecordFixtures(llmproviders.Provider(fixturesFlags.provider), fixturesFlags.model, fixturesFlags.fixtureDir)
+			if err != nil {
+				log.Fatalf("❌ Recording fixtures failed: %v", err)
+			}
+			log.Printf("✅ Recorded fixtures to %s", path)
+			return
+		}
+		if !VerifyFixtures(fixturesFlags.fixtureDir, llmproviders.Provider(fixturesFlags.provider), fixturesFlags.model) {
+			os.Exit(1)
+		}
+	},
+}
+
+type fixturesTestFlags struct {
+	record     bool
+	provider   string
+	model      string
+	fixtureDir string
+}
+
+var fixturesFlags fixturesTestFlags
+
+func init() {
+	FixturesCmd.Flags().BoolVar(&fixturesFlags.record, "record", false, "Run the battery against the live provider and record fixtures")
+	FixturesCmd.Flags().StringVar(&fixturesFlags.provider, "provider", "", "Provider (openai, openrouter, vertex, bedrock); required with --record")
+	FixturesCmd.Flags().StringVar(&fixturesFlags.model, "model", "", "Model ID; required with --record")
+	FixturesCmd.Flags().StringVar(&fixturesFlags.fixtureDir, "fixture-dir", "testdata/fixtures", "Directory for fixtures and their recorded responses")
+}
+
+// fixtureFile is the recorded summary of the battery for one provider and model
+type fixtureFile struct {
+	Version    int                        `json:"version"`
+	Provider   string                     `json:"provider"`
+	ModelID    string                     `json:"model_id"`
+	RecordedAt time.Time                  `json:"recorded_at"`
+	Scenarios  map[string]fixtureResponse `json:"scenarios"`
+}
+
+// fixtureResponse is the deterministic structure of a response: what replay checks
+type fixtureResponse struct {
+	StopReason string              `json:"stop_reason"`
+	HasContent bool                `json:"has_content"`
+	ToolCalls  []fixtureToolCall   `json:"tool_calls,omitempty"`
+	Usage      *fixtureTokenCounts `json:"usage,omitempty"`
+}
+
+// fixtureToolCall is a tool call name with the sorted keys of its arguments
+type fixtureToolCall struct {
+	Name    string   `json:"name"`
+	ArgKeys []string `json:"arg_keys"`
+}
+
+type fixtureTokenCounts struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+// fixtureScenario is one call of the battery
+type fixtureScenario struct {
+	name     string
+	messages []llmtypes.MessageContent
+	options  []llmtypes.CallOption
+	stream   bool
+}
+
+// fixtureScenarios returns the battery. Requests must stay byte-for-byte stable: replay finds
+// the recorded response by request hash, so changing one means recording fixtures again.
+func fixtureScenarios() []fixtureScenario {
+	weatherTool := llmtypes.Tool{
+		Type: "function",
+		Function: &llmtypes.FunctionDefinition{
+			Name:        "get_weather",
+			Description: "Get the current weather for a city",
+			Parameters: llmtypes.NewParameters(map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"city": map[string]interface{}{"type": "string", "description": "City name"},
+					"unit": map[string]interface{}{"type": "string", "enum": []string{"celsius", "fahrenheit"}},
+				},
+				"required": []string{"city", "unit"},
+			}),
+		},
+	}
+	tools := llmtypes.WithTools([]llmtypes.Tool{weatherTool})
+
+	return []fixtureScenario{
+		{
+			name:     "plain_text",
+			messages: []llmtypes.MessageContent{llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "Say hello in one short sentence.")},
+		},
+		{
+			name:     "tool_call",
+			messages: []llmtypes.MessageContent{llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "What is the weather in Paris in celsius? Use the get_weather tool.")},
+			options:  []llmtypes.CallOption{tools},
+		},
+		{
+			name: "tool_result",
+			messages: []llmtypes.MessageContent{
+				llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "What is the weather in Paris in celsius?"),
+				{Role: llmtypes.ChatMessageTypeAI, Parts: []llmtypes.ContentPart{llmtypes.ToolCall{
+					ID:           "call_weather_1",
+					Type:         "function",
+					FunctionCall: &llmtypes.FunctionCall{Name: "get_weather", Arguments: `{"city":"Paris","unit":"celsius"}`},
+				}}},
+				{Role: llmtypes.ChatMessageTypeTool, Parts: []llmtypes.ContentPart{llmtypes.ToolCallResponse{
+					ToolCallID: "call_weather_1",
+					Name:       "get_weather",
+					Content:    "Sunny, 22°C",
+				}}},
+			},
+			options: []llmtypes.CallOption{tools},
+		},
+		{
+			name:     "json_mode",
+			messages: []llmtypes.MessageContent{llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, `Reply with a JSON object with the keys "city" and "country" for the capital of France.`)},
+			options:  []llmtypes.CallOption{llmtypes.WithJSONMode()},
+		},
+		{
+			name:     "streaming",
+			messages: []llmtypes.MessageContent{llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "Count from 1 to 5, separated by commas.")},
+			stream:   true,
+		},
+	}
+}
+
+// fixtureRecordingConfig returns the recorder configuration of scenario
+func fixtureRecordingConfig(provider llmproviders.Provider, modelID, scenario, dir string) recorder.RecordingConfig {
+	return recorder.RecordingConfig{
+		TestName: "fixture_" + scenario,
+		Provider: string(provider),
+		ModelID:  modelID,
+		BaseDir:  dir,
+	}
+}
+
+// fixtureRecorder returns ctx with a recorder for scenario, recording or replaying
+func fixtureRecorder(ctx context.Context, provider llmproviders.Provider, modelID, scenario, dir string, record bool) context.Context {
+	rec := recorder.NewRecorder(fixtureRecordingConfig(provider, modelID, scenario, dir))
+	rec.SetReplayMode(!record)
+	return recorder.WithRecorder(ctx, rec)
+}
+
+// runFixtureScenario makes the scenario's call and summarizes the response
+func runFixtureScenario(ctx context.Context, llm llmtypes.Model, modelID string, scenario fixtureScenario) (fixtureResponse, error) {
+	options := append([]llmtypes.CallOption{llmtypes.WithModel(modelID)}, scenario.options...)
+	if scenario.stream {
+		streamChan := make(chan llmtypes.StreamChunk, 100)
+		done := make(chan struct{})
+		go func() {
+			for range streamChan {
+			}
+			close(done)
+		}()
+		options = append(options, llmtypes.WithStreamingChan(streamChan))
+		defer func() { <-done }()
+	}
+	resp, err := llm.GenerateContent(ctx, scenario.messages, options...)
+	if err != nil {
+		return fixtureResponse{}, err
+	}
+	return summarizeFixtureResponse(resp)
+}
+
+// summarizeFixtureResponse returns the deterministic structure of resp
+func summarizeFixtureResponse(resp *llmtypes.ContentResponse) (fixtureResponse, error) {
+	if resp == nil || len(resp.Choices) == 0 {
+		return fixtureResponse{}, fmt.Errorf("response has no choices")
+	}
+	choice := resp.Choices[0]
+	summary := fixtureResponse{
+		StopReason: choice.StopReason,
+		HasContent: strings.TrimSpace(choice.Content) != "",
+	}
+	for _, toolCall := range choice.ToolCalls {
+		if toolCall.FunctionCall == nil {
+			continue
+		}
+		var args map[string]interface{}
+		if err := json.Unmarshal([]byte(toolCall.FunctionCall.Arguments), &args); err != nil {
+			return fixtureResponse{}, fmt.Errorf("tool call %s has invalid arguments %q: %w", toolCall.FunctionCall.Name, toolCall.FunctionCall.Arguments, err)
+		}
+		keys := make([]string, 0, len(args))
+		for key := range args {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		summary.ToolCalls = append(summary.ToolCalls, fixtureToolCall{Name: toolCall.FunctionCall.Name, ArgKeys: keys})
+	}
+	if resp.Usage != nil {
+		summary.Usage = &fixtureTokenCounts{InputTokens: resp.Usage.InputTokens, OutputTokens: resp.Usage.OutputTokens}
+	}
+	return summary, nil
+}
+
+// compareFixtureResponse returns how got differs from the recorded want
+func compareFixtureResponse(want, got fixtureResponse) []string {
+	var diffs []string
+	if got.StopReason != want.StopReason {
+		diffs = append(diffs, fmt.Sprintf("stop reason %q, recorded %q", got.StopReason, want.StopReason))
+	}
+	if got.HasContent != want.HasContent {
+		diffs = append(diffs, fmt.Sprintf("has content %v, recorded %v", got.HasContent, want.HasContent))
+	}
+	if len(got.ToolCalls) != len(want.ToolCalls) {
+		diffs = append(diffs, fmt.Sprintf("%d tool calls, recorded %d", len(got.ToolCalls), len(want.ToolCalls)))
+	} else {
+		for i := range want.ToolCalls {
+			if !reflect.DeepEqual(got.ToolCalls[i], want.ToolCalls[i]) {
+				diffs = append(diffs, fmt.Sprintf("tool call %d is %s%v, recorded %s%v", i, got.ToolCalls[i].Name, got.ToolCalls[i].ArgKeys, want.ToolCalls[i].Name, want.ToolCalls[i].ArgKeys))
+			}
+		}
+	}
+	if !reflect.DeepEqual(got.Usage, want.Usage) {
+		diffs = append(diffs, fmt.Sprintf("usage %s, recorded %s", formatFixtureUsage(got.Usage), formatFixtureUsage(want.Usage)))
+	}
+	return diffs
+}
+
+func formatFixtureUsage(usage *fixtureTokenCounts) string {
+	if usage == nil {
+		return "none"
+	}
+	return fmt.Sprintf("%d in/%d out", usage.InputTokens, usage.OutputTokens)
+}
+
+// fixturePath returns the fixture file of provider and modelID in dir
+func fixturePath(dir string, provider llmproviders.Provider, modelID string) string {
+	name := strings.NewReplacer("/", "_", ":", "_", ".", "_").Replace(modelID)
+	return filepath.Join(dir, string(provider), "fixtures_"+name+".fixture")
+}
+
+// initializeFixtureLLM initializes the model for recording (credentials from the environment)
+// or replay (placeholder credentials, no calls are made)
+func initializeFixtureLLM(config llmproviders.Config, record bool) (llmtypes.Model, error) {
+	found := false
+	for _, provider := range fixtureProviders {
+		found = found || provider == config.Provider
+	}
+	if !found {
+		return nil, fmt.Errorf("provider %q does not support record/replay, supported: %v", config.Provider, fixtureProviders)
+	}
+	if config.Logger == nil {
+		config.Logger = testing.GetTestLogger()
+	}
+	if !record && config.APIKeys == nil {
+		placeholder := "replay"
+		config.APIKeys = &llmproviders.ProviderAPIKeys{OpenAI: &placeholder, OpenRouter: &placeholder, Vertex: &placeholder}
+		if config.Provider == llmproviders.ProviderBedrock && config.Region == "" && os.Getenv("AWS_REGION") == "" {
+			config.Region = "us-east-1"
+		}
+	}
+	return llmproviders.InitializeLLM(config)
+}
+
+// RecordFixtures runs the battery against the live provider, saving the responses and the
+// fixture file in dir, and returns the fixture file path
+func RecordFixtures(provider llmproviders.Provider, modelID, dir string) (string, error) {
+	return recordFixtures(llmproviders.Config{Provider: provider, ModelID: modelID}, dir)
+}
+
+func recordFixtures(config llmproviders.Config, dir string) (string, error) {
+	llm, err := initializeFixtureLLM(config, true)
+	if err != nil {
+		return "", err
+	}
+
+	fixture := fixtureFile{
+		Version:    fixtureVersion,
+		Provider:   string(config.Provider),
+		ModelID:    config.ModelID,
+		RecordedAt: time.Now().UTC(),
+		Scenarios:  make(map[string]fixtureResponse),
+	}
+	for _, scenario := range fixtureScenarios() {
+		// Remove the recordings of an earlier run, which replay could pick instead
+		old, _ := recorder.RecordingFiles(fixtureRecordingConfig(config.Provider, config.ModelID, scenario.name, dir))
+		for _, path := range old {
+			if err := os.Remove(path); err != nil {
+				return "", err
+			}
+		}
+		ctx := fixtureRecorder(context.Background(), config.Provider, config.ModelID, scenario.name, dir, true)
+		summary, err := runFixtureScenario(ctx, llm, config.ModelID, scenario)
+		if err != nil {
+			return "", fmt.Errorf("scenario %s: %w", scenario.name, err)
+		}
+		fixture.Scenarios[scenario.name] = summary
+		log.Printf("📹 %s: stop reason %q, %d tool calls, usage %s", scenario.name, summary.StopReason, len(summary.ToolCalls), formatFixtureUsage(summary.Usage))
+	}
+
+	data, err := json.MarshalIndent(fixture, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	path := fixturePath(dir, config.Provider, config.ModelID)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return "", err
+	}
+	return path, os.WriteFile(path, data, 0644)
+}
+
+// VerifyFixtures replays the fixtures in dir, only those of provider and modelID when set,
+// and reports whether every scenario matches its fixture
+func VerifyFixtures(dir string, provider llmproviders.Provider, modelID string) bool {
+	log.Printf("\n🎞️  Test: Fixtures (%s)", dir)
+
+	paths, _ := filepath.Glob(filepath.Join(dir, "*", "*.fixture"))
+	if len(paths) == 0 {
+		log.Printf("❌ No fixtures found in %s, record them with --record", dir)
+		return false
+	}
+
+	passed := true
+	verified := 0
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			log.Printf("❌ %s: %v", path, err)
+			passed = false
+			continue
+		}
+		var fixture fixtureFile
+		if err := json.Unmarshal(data, &fixture); err != nil {
+			log.Printf("❌ %s: invalid fixture: %v", path, err)
+			passed = false
+			continue
+		}
+		if (provider != "" && fixture.Provider != string(provider)) || (modelID != "" && fixture.ModelID != modelID) {
+			continue
+		}
+		verified++
+		if !verifyFixture(llmproviders.Config{Provider: llmproviders.Provider(fixture.Provider), ModelID: fixture.ModelID}, dir, fixture) {
+			passed = false
+		}
+	}
+	if verified == 0 {
+		log.Printf("❌ No fixtures found for provider %q model %q in %s", provider, modelID, dir)
+		return false
+	}
+	return passed
+}
+
+// verifyFixture replays the battery for the fixture's provider and model and compares each
+// scenario with the fixture
+func verifyFixture(config llmproviders.Config, dir string, fixture fixtureFile) bool {
+	name := fmt.Sprintf("%s/%s", fixture.Provider, fixture.ModelID)
+	if fixture.Version != fixtureVersion {
+		log.Printf("❌ %s: fixture version %d, expected %d; record it again", name, fixture.Version, fixtureVersion)
+		return false
+	}
+	llm, err := initializeFixtureLLM(config, false)
+	if err != nil {
+		log.Printf("❌ %s: %v", name, err)
+		return false
+	}
+
+	passed := true
+	for _, scenario := range fixtureScenarios() {
+		want, ok := fixture.Scenarios[scenario.name]
+		if !ok {
+			log.Printf("❌ %s %s: not in the fixture; record it again", name, scenario.name)
+			passed = false
+			continue
+		}
+		ctx := fixtureRecorder(context.Background(), config.Provider, config.ModelID, scenario.name, dir, false)
+		got, err := runFixtureScenario(ctx, llm, config.ModelID, scenario)
+		if err != nil {
+			log.Printf("❌ %s %s: %v", name, scenario.name, err)
+			passed = false
+			continue
+		}
+		if diffs := compareFixtureResponse(want, got); len(diffs) > 0 {
+			log.Printf("❌ %s %s: %s", name, scenario.name, strings.Join(diffs, "; "))
+			passed = false
+			continue
+		}
+		log.Printf("✅ %s %s matches the fixture", name, scenario.name)
+	}
+	return passed
+}
@@ -0,0 +1,136 @@
+package shared
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+
+	llmproviders "github.com/manishiitg/multi-llm-provider-go"
+
+	"github.com/spf13/cobra"
+)
+
+// FixturesReplayTestCmd checks recording and verifying fixtures against a fake provider
+var FixturesReplayTestCmd = &cobra.Command{
+	Use:   "fixtures-replay",
+	Short: "Test recording and replaying fixtures of the test battery",
+	Long: `This test records the fixture battery against a local fake OpenAI server, then checks that:
+- replay without the server matches the recorded fixtures
+- a fixture whose tool call arguments, stop reason or usage differ fails verification
+- recording a provider without record/replay support is rejected
+
+No API calls are made.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !RunFixturesReplayTest() {
+			os.Exit(1)
+		}
+	},
+}
+
+// RunFixturesReplayTest verifies fixture recording and replay
+func RunFixturesReplayTest() bool {
+	log.Printf("\n🎞️  Test: Fixture record and replay")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var request struct {
+			Stream   bool              `json:"stream"`
+			Tools    []json.RawMessage `json:"tools"`
+			Messages []struct {
+				Role string `json:"role"`
+			} `json:"messages"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&request)
+		usage := `{"prompt_tokens":12,"completion_tokens":4,"total_tokens":16}`
+		if request.Stream {
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprint(w, `data: {"id":"c1","object":"chat.completion.chunk","created":1,"model":"gpt-4.1","choices":[{"index":0,"delta":{"role":"assistant","content":"1, 2, 3, 4, 5"},"finish_reason":null}]}`+"\n\n")
+			fmt.Fprint(w, `data: {"id":"c1","object":"chat.completion.chunk","created":1,"model":"gpt-4.1","choices":[{"index":0,"delta":{},"finish_reason":"stop"}]}`+"\n\n")
+			fmt.Fprintf(w, `data: {"id":"c1","object":"chat.completion.chunk","created":1,"model":"gpt-4.1","choices":[],"usage":%s}`+"\n\n", usage)
+			fmt.Fprint(w, "data: [DONE]\n\n")
+			return
+		}
+		message := `{"role":"assistant","content":"Hello there!"}`
+		finishReason := "stop"
+		if len(request.Tools) > 0 && request.Messages[len(request.Messages)-1].Role == "user" {
+			message = `{"role":"assistant","content":null,"tool_calls":[{"id":"call_1","type":"function","function":{"name":"get_weather","arguments":"{\"city\":\"Paris\",\"unit\":\"celsius\"}"}}]}`
+			finishReason = "tool_calls"
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"id":"c1","object":"chat.completion","created":1,"model":"gpt-4.1","choices":[{"index":0,"message":%s,"finish_reason":%q}],"usage":%s}`, message, finishReason, usage)
+	}))
+	target, _ := url.Parse(server.URL)
+
+	dir, err := os.MkdirTemp("", "fixtures")
+	if err != nil {
+		log.Printf("❌ Failed to create fixture directory: %v", err)
+		return false
+	}
+	defer os.RemoveAll(dir)
+
+	apiKey := "test"
+	config := llmproviders.Config{
+		Provider:   llmproviders.ProviderOpenAI,
+		ModelID:    "gpt-4.1",
+		APIKeys:    &llmproviders.ProviderAPIKeys{OpenAI: &apiKey},
+		HTTPClient: &http.Client{Transport: redirectTransport{target: target}},
+	}
+	path, err := recordFixtures(config, dir)
+	server.Close()
+	if err != nil {
+		log.Printf("❌ Recording fixtures failed: %v", err)
+		return false
+	}
+	log.Printf("✅ Recorded fixtures to %s", path)
+
+	passed := true
+	if !VerifyFixtures(dir, "", "") {
+		log.Printf("❌ Replay: expected the recorded fixtures to match")
+		passed = false
+	} else {
+		log.Printf("✅ Replay without the server matches the fixtures")
+	}
+
+	original, err := os.ReadFile(path)
+	if err != nil {
+		log.Printf("❌ Failed to read %s: %v", path, err)
+		return false
+	}
+	for _, tc := range []struct {
+		name string
+		old  string
+		new  string
+	}{
+		{"Tool call arguments", `"unit"`, `"units"`},
+		{"Stop reason", `"stop_reason": "tool_calls"`, `"stop_reason": "stop"`},
+		{"Usage", `"input_tokens": 12`, `"input_tokens": 13`},
+	} {
+		if !strings.Contains(string(original), tc.old) {
+			log.Printf("❌ %s: %s not in the fixture", tc.name, tc.old)
+			passed = false
+			continue
+		}
+		if err := os.WriteFile(path, []byte(strings.Replace(string(original), tc.old, tc.new, 1)), 0644); err != nil {
+			log.Printf("❌ Failed to write %s: %v", path, err)
+			return false
+		}
+		if VerifyFixtures(dir, llmproviders.ProviderOpenAI, "gpt-4.1") {
+			log.Printf("❌ %s: expected a changed fixture to fail verification", tc.name)
+			passed = false
+		} else {
+			log.Printf("✅ %s changes fail verification", tc.name)
+		}
+	}
+
+	if _, err := RecordFixtures(llmproviders.ProviderAnthropic, "claude-sonnet-4-20250514", dir); err == nil || !strings.Contains(err.Error(), "does not support record/replay") {
+		log.Printf("❌ Anthropic: expected an unsupported provider error, got %v", err)
+		passed = false
+	} else {
+		log.Printf("✅ Providers without record/replay are rejected: %v", err)
+	}
+	return passed
+}
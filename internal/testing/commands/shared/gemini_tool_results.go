@@ -0,0 +1,115 @@
+package shared
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+	"github.com/manishiitg/multi-llm-provider-go/pkg/adapters/vertex"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/genai"
+)
+
+// GeminiToolResultsTestCmd checks that parallel tool results become paired Gemini functionResponse parts
+var GeminiToolResultsTestCmd = &cobra.Command{
+	Use:   "gemini-tool-results",
+	Short: "Test that parallel tool results become ordered Gemini functionResponse parts",
+	Long: `This test converts parallel-tool histories to Gemini contents and checks that:
+- all results of a turn are sent as functionResponse parts of one user content
+- each functionResponse carries the name and ID of its functionCall, in the order of the calls
+- thought signatures (base64, as Gemini returns them) stay on every functionCall
+- results grouped in a user message (the Bedrock shape) or spread over several messages are
+  gathered, and results whose IDs don't match take the slot of the remaining calls in order
+
+No API calls are made.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !RunGeminiToolResultsTest() {
+			os.Exit(1)
+		}
+	},
+}
+
+// RunGeminiToolResultsTest verifies the Gemini conversion of parallel tool results
+func RunGeminiToolResultsTest() bool {
+	log.Printf("\n🧰 Test: Gemini Tool Results")
+
+	assistant := llmtypes.MessageContent{Role: llmtypes.ChatMessageTypeAI, Parts: []llmtypes.ContentPart{
+		llmtypes.TextContent{Text: "Let me check both."},
+		llmtypes.ToolCall{ID: "call_1", Type: "function", ThoughtSignature: "c2lnbmF0dXJl", FunctionCall: &llmtypes.FunctionCall{Name: "get_weather", Arguments: `{"city":"Paris"}`}},
+		llmtypes.ToolCall{ID: "call_2", Type: "function", FunctionCall: &llmtypes.FunctionCall{Name: "get_time", Arguments: `{"city":"Paris"}`}},
+	}}
+	weather := llmtypes.ToolCallResponse{ToolCallID: "call_1", Name: "get_weather", Content: "Sunny"}
+	clock := llmtypes.ToolCallResponse{ToolCallID: "call_2", Name: "get_time", Content: "10:00"}
+	history := func(messages ...llmtypes.MessageContent) []llmtypes.MessageContent {
+		return append([]llmtypes.MessageContent{llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "Weather and time in Paris?"), assistant}, messages...)
+	}
+
+	// shape describes the contents after the question: role and, per part, what was sent
+	shape := func(messages []llmtypes.MessageContent) string {
+		var contents []string
+		for _, content := range vertex.ToGeminiContents(messages, "gemini-3-pro-preview")[1:] {
+			var parts []string
+			for _, part := range content.Parts {
+				parts = append(parts, describeGeminiPart(part))
+			}
+			contents = append(contents, content.Role+"["+strings.Join(parts, ",")+"]")
+		}
+		return strings.Join(contents, " ")
+	}
+
+	calls := "model[text] model[call:get_weather#call_1+sig,call:get_time#call_2+sig]"
+	passed := true
+	for _, c := range []struct {
+		name     string
+		messages []llmtypes.MessageContent
+		want     string
+	}{
+		{"grouped tool message", history(
+			llmtypes.MessageContent{Role: llmtypes.ChatMessageTypeTool, Parts: []llmtypes.ContentPart{weather, clock}},
+		), calls + " user[response:get_weather#call_1,response:get_time#call_2]"},
+		{"out of order results", history(
+			llmtypes.MessageContent{Role: llmtypes.ChatMessageTypeTool, Parts: []llmtypes.ContentPart{clock, weather}},
+		), calls + " user[response:get_weather#call_1,response:get_time#call_2]"},
+		{"results across messages", history(
+			llmtypes.MessageContent{Role: llmtypes.ChatMessageTypeTool, Parts: []llmtypes.ContentPart{clock}},
+			llmtypes.MessageContent{Role: llmtypes.ChatMessageTypeTool, Parts: []llmtypes.ContentPart{weather}},
+		), calls + " user[response:get_weather#call_1,response:get_time#call_2]"},
+		{"results in a user message", history(
+			llmtypes.MessageContent{Role: llmtypes.ChatMessageTypeHuman, Parts: []llmtypes.ContentPart{weather, clock, llmtypes.TextContent{Text: "Keep it short."}}},
+		), calls + " user[response:get_weather#call_1,response:get_time#call_2] user[text]"},
+		{"unmatched IDs", history(
+			llmtypes.MessageContent{Role: llmtypes.ChatMessageTypeTool, Parts: []llmtypes.ContentPart{
+				llmtypes.ToolCallResponse{ToolCallID: "other_a", Content: "Sunny"},
+				clock,
+			}},
+		), calls + " user[response:get_weather#call_1,response:get_time#call_2]"},
+	} {
+		if got := shape(c.messages); got != c.want {
+			log.Printf("❌ %s:\n   expected %s\n   got      %s", c.name, c.want, got)
+			passed = false
+			continue
+		}
+		log.Printf("✅ %s", c.name)
+	}
+	return passed
+}
+
+// describeGeminiPart summarizes a Gemini part as kind:name#id, with +sig for thought signatures
+func describeGeminiPart(part *genai.Part) string {
+	switch {
+	case part.FunctionCall != nil:
+		description := fmt.Sprintf("call:%s#%s", part.FunctionCall.Name, part.FunctionCall.ID)
+		if len(part.ThoughtSignature) > 0 {
+			description += "+sig"
+		}
+		return description
+	case part.FunctionResponse != nil:
+		return fmt.Sprintf("response:%s#%s", part.FunctionResponse.Name, part.FunctionResponse.ID)
+	case part.Text != "":
+		return "text"
+	}
+	return "other"
+}
@@ -0,0 +1,109 @@
+package shared
+
+import (
+	"log"
+	"os"
+	"strings"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+	"github.com/manishiitg/multi-llm-provider-go/pkg/history"
+
+	"github.com/spf13/cobra"
+)
+
+// HistoryTruncateTestCmd checks which messages history.Truncate drops
+var HistoryTruncateTestCmd = &cobra.Command{
+	Use:   "history-truncate",
+	Short: "Test that history.Truncate drops the oldest messages and keeps the current turn",
+	Long: `This test truncates conversations with history.Truncate and checks that:
+- the oldest messages are dropped first and system messages are kept
+- the current turn, from the last user message on, is kept whole, including every
+  tool-call exchange of an agent loop answering it
+- earlier tool calls are dropped together with their results
+- a current turn larger than the budget is returned with an error
+
+No API calls are made.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !RunHistoryTruncateTest() {
+			os.Exit(1)
+		}
+	},
+}
+
+// messageLabels renders messages as the first word of their text, the IDs of their tool
+// calls and results, for comparisons
+func messageLabels(messages []llmtypes.MessageContent) string {
+	var labels []string
+	for _, msg := range messages {
+		for _, part := range msg.Parts {
+			switch p := part.(type) {
+			case llmtypes.TextContent:
+				labels = append(labels, strings.Fields(p.Text)[0])
+			case llmtypes.ToolCall:
+				labels = append(labels, "call:"+p.ID)
+			case llmtypes.ToolCallResponse:
+				labels = append(labels, "result:"+p.ToolCallID)
+			}
+		}
+	}
+	return strings.Join(labels, " ")
+}
+
+// toolExchange returns an assistant message calling a tool and the tool's result
+func toolExchange(id, result string) []llmtypes.MessageContent {
+	return []llmtypes.MessageContent{
+		{Role: llmtypes.ChatMessageTypeAI, Parts: []llmtypes.ContentPart{
+			llmtypes.ToolCall{ID: id, Type: "function", FunctionCall: &llmtypes.FunctionCall{Name: "search", Arguments: `{"q":"go"}`}},
+		}},
+		{Role: llmtypes.ChatMessageTypeTool, Parts: []llmtypes.ContentPart{
+			llmtypes.ToolCallResponse{ToolCallID: id, Name: "search", Content: result},
+		}},
+	}
+}
+
+// RunHistoryTruncateTest verifies what history.Truncate keeps
+func RunHistoryTruncateTest() bool {
+	log.Printf("\n✂️  Test: History Truncate")
+
+	long := strings.Repeat(" lorem ipsum", 200)
+	system := llmtypes.TextParts(llmtypes.ChatMessageTypeSystem, "system prompt")
+	earlier := []llmtypes.MessageContent{
+		llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "u1"+long),
+		llmtypes.TextParts(llmtypes.ChatMessageTypeAI, "a1"+long),
+	}
+	earlier = append(earlier, toolExchange("call_0", "r0"+long)...)
+	question := llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "u2 what changed?")
+
+	// The agent loop answering u2 has made two tool calls; the last group is a tool-call exchange
+	loop := append(toolExchange("call_1", "r1"+long), toolExchange("call_2", "r2"+long)...)
+	turn := append([]llmtypes.MessageContent{question}, loop...)
+
+	passed := true
+	for _, c := range []struct {
+		name     string
+		messages []llmtypes.MessageContent
+		budget   int
+		want     string
+		fails    bool
+	}{
+		{"chat", append(append([]llmtypes.MessageContent{system}, earlier[:2]...), question),
+			history.CountTokens([]llmtypes.MessageContent{system, question}) + 10,
+			"system u2", false},
+		{"agent loop", append(append([]llmtypes.MessageContent{system}, earlier...), turn...),
+			history.CountTokens(append([]llmtypes.MessageContent{system}, turn...)) + 10,
+			"system u2 call:call_1 result:call_1 call:call_2 result:call_2", false},
+		{"turn over budget", append(append([]llmtypes.MessageContent{system}, earlier...), turn...),
+			history.CountTokens(append([]llmtypes.MessageContent{system}, loop[2:]...)),
+			"system u2 call:call_1 result:call_1 call:call_2 result:call_2", true},
+	} {
+		result, err := history.Truncate(c.messages, c.budget, true)
+		got := messageLabels(result.Messages)
+		if got != c.want || (err != nil) != c.fails {
+			log.Printf("❌ %s: expected %q (error %t), got %q (error %v)", c.name, c.want, c.fails, got, err)
+			passed = false
+			continue
+		}
+		log.Printf("✅ %s: kept %q, dropped %d messages", c.name, got, result.DroppedMessages)
+	}
+	return passed
+}
@@ -0,0 +1,252 @@
+package shared
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+
+	llmproviders "github.com/manishiitg/multi-llm-provider-go"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+	"github.com/manishiitg/multi-llm-provider-go/pkg/httpserver"
+
+	"github.com/spf13/cobra"
+)
+
+// HTTPServerTestCmd checks the OpenAI and Anthropic compatible handlers of pkg/httpserver
+var HTTPServerTestCmd = &cobra.Command{
+	Use:   "httpserver",
+	Short: "Test the OpenAI and Anthropic compatible HTTP handlers",
+	Long: `This test serves a fake model with httpserver.NewChatCompletionsHandler and
+httpserver.NewMessagesHandler and checks that:
+- streamed responses are relayed as OpenAI chunks: the role, the content deltas, a tool_calls
+  delta with its index, the finish_reason and [DONE]
+- non-streaming responses return the tool calls in choices[0].message.tool_calls
+- the Anthropic handler streams the message_start, content_block_*, message_delta and
+  message_stop events
+- provider errors are answered with their status, e.g. 429 rate_limit_error, and other
+  errors with 502 api_error, streaming or not
+
+No API calls are made.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !RunHTTPServerTest() {
+			os.Exit(1)
+		}
+	},
+}
+
+// openAIFrame is the part of an OpenAI chat completion (chunk) the check looks at
+type openAIFrame struct {
+	Choices []struct {
+		Delta struct {
+			Role      string `json:"role"`
+			Content   string `json:"content"`
+			ToolCalls []struct {
+				Index    *int   `json:"index"`
+				ID       string `json:"id"`
+				Type     string `json:"type"`
+				Function struct {
+					Name      string `json:"name"`
+					Arguments string `json:"arguments"`
+				} `json:"function"`
+			} `json:"tool_calls"`
+		} `json:"delta"`
+		Message struct {
+			Content   string `json:"content"`
+			ToolCalls []struct {
+				ID       string `json:"id"`
+				Type     string `json:"type"`
+				Function struct {
+					Name      string `json:"name"`
+					Arguments string `json:"arguments"`
+				} `json:"function"`
+			} `json:"tool_calls"`
+		} `json:"message"`
+		FinishReason *string `json:"finish_reason"`
+	} `json:"choices"`
+	Error struct {
+		Type string `json:"type"`
+	} `json:"error"`
+}
+
+// describeOpenAIStream renders the SSE frames of an OpenAI stream one per line
+func describeOpenAIStream(body string) string {
+	var lines []string
+	scanner := bufio.NewScanner(strings.NewReader(body))
+	for scanner.Scan() {
+		data, ok := strings.CutPrefix(scanner.Text(), "data: ")
+		if !ok {
+			continue
+		}
+		var frame openAIFrame
+		if data == "[DONE]" || json.Unmarshal([]byte(data), &frame) != nil || len(frame.Choices) == 0 {
+			if frame.Error.Type != "" {
+				data = "error " + frame.Error.Type
+			}
+			lines = append(lines, data)
+			continue
+		}
+		choice := frame.Choices[0]
+		line := ""
+		if choice.Delta.Role != "" {
+			line += " role=" + choice.Delta.Role
+		}
+		if choice.Delta.Content != "" {
+			line += fmt.Sprintf(" content=%q", choice.Delta.Content)
+		}
+		for _, call := range choice.Delta.ToolCalls {
+			index := -1
+			if call.Index != nil {
+				index = *call.Index
+			}
+			line += fmt.Sprintf(" tool_call[%d]=%s %s %s %s", index, call.ID, call.Type, call.Function.Name, call.Function.Arguments)
+		}
+		if choice.FinishReason != nil {
+			line += " finish=" + *choice.FinishReason
+		}
+		lines = append(lines, strings.TrimSpace(line))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// describeAnthropicStream renders the event names of an Anthropic stream one per line
+func describeAnthropicStream(body string) string {
+	var events []string
+	for _, line := range strings.Split(body, "\n") {
+		if event, ok := strings.CutPrefix(line, "event: "); ok {
+			events = append(events, event)
+		}
+	}
+	return strings.Join(events, "\n")
+}
+
+// post sends body to url and returns the response status and body
+func post(url, body string) (int, string, error) {
+	resp, err := http.Post(url, "application/json", strings.NewReader(body))
+	if err != nil {
+		return 0, "", err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	return resp.StatusCode, string(data), err
+}
+
+// RunHTTPServerTest verifies what the HTTP handlers send for a fake model's answers and errors
+func RunHTTPServerTest() bool {
+	log.Printf("\n🌐 Test: HTTP Server")
+
+	var fail error
+	model := fakeModelFunc(func(ctx context.Context, messages []llmtypes.MessageContent, opts *llmtypes.CallOptions) (*llmtypes.ContentResponse, error) {
+		if fail != nil {
+			return nil, fail
+		}
+		choice := &llmtypes.ContentChoice{Content: "Let me check.", StopReason: "tool_calls", ToolCalls: []llmtypes.ToolCall{
+			{ID: "call_1", Type: "function", FunctionCall: &llmtypes.FunctionCall{Name: "get_weather", Arguments: `{"city":"Paris"}`}},
+		}}
+		return streamChoice(opts, choice, "Let me ", "check."), nil
+	})
+	mux := http.NewServeMux()
+	mux.Handle("/v1/chat/completions", httpserver.NewChatCompletionsHandler(model))
+	mux.Handle("/v1/messages", httpserver.NewMessagesHandler(model))
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	openAIRequest := func(stream bool) string {
+		return fmt.Sprintf(`{"model":"fake-model","stream":%t,"messages":[{"role":"user","content":"Weather in Paris?"}]}`, stream)
+	}
+	anthropicRequest := func(stream bool) string {
+		return fmt.Sprintf(`{"model":"fake-model","max_tokens":100,"stream":%t,"messages":[{"role":"user","content":"Weather in Paris?"}]}`, stream)
+	}
+
+	passed := true
+	status, body, err := post(server.URL+"/v1/chat/completions", openAIRequest(true))
+	want := strings.Join([]string{
+		"role=assistant",
+		`content="Let me "`,
+		`content="check."`,
+		`tool_call[0]=call_1 function get_weather {"city":"Paris"}`,
+		"finish=tool_calls",
+		"[DONE]",
+	}, "\n")
+	if got := describeOpenAIStream(body); err != nil || status != http.StatusOK || got != want {
+		log.Printf("❌ OpenAI stream: expected 200 with frames\n%s\ngot %d (error %v)\n%s", want, status, err, got)
+		passed = false
+	} else {
+		log.Printf("✅ OpenAI stream relays the content deltas, the tool call delta, finish_reason and [DONE]")
+	}
+
+	status, body, err = post(server.URL+"/v1/chat/completions", openAIRequest(false))
+	var completion openAIFrame
+	_ = json.Unmarshal([]byte(body), &completion)
+	got := ""
+	if len(completion.Choices) > 0 {
+		choice := completion.Choices[0]
+		got = fmt.Sprintf("%q", choice.Message.Content)
+		for _, call := range choice.Message.ToolCalls {
+			got += fmt.Sprintf(" %s %s %s %s", call.ID, call.Type, call.Function.Name, call.Function.Arguments)
+		}
+		if choice.FinishReason != nil {
+			got += " finish=" + *choice.FinishReason
+		}
+	}
+	if want := `"Let me check." call_1 function get_weather {"city":"Paris"} finish=tool_calls`; err != nil || status != http.StatusOK || got != want {
+		log.Printf("❌ OpenAI completion: expected 200 with %s, got %d (error %v) %s", want, status, err, body)
+		passed = false
+	} else {
+		log.Printf("✅ OpenAI completion returns the tool calls: %s", got)
+	}
+
+	status, body, err = post(server.URL+"/v1/messages", anthropicRequest(true))
+	want = strings.Join([]string{
+		"message_start",
+		"content_block_start", "content_block_delta", "content_block_delta", "content_block_stop",
+		"content_block_start", "content_block_delta", "content_block_stop",
+		"message_delta", "message_stop",
+	}, "\n")
+	if got := describeAnthropicStream(body); err != nil || status != http.StatusOK || got != want ||
+		!strings.Contains(body, `"partial_json":"{\"city\":\"Paris\"}"`) || !strings.Contains(body, `"stop_reason":"tool_use"`) {
+		log.Printf("❌ Anthropic stream: expected 200 with events\n%s\ngot %d (error %v)\n%s", want, status, err, body)
+		passed = false
+	} else {
+		log.Printf("✅ Anthropic stream sends the text and tool_use blocks and stop_reason tool_use")
+	}
+
+	for _, c := range []struct {
+		name       string
+		err        error
+		wantStatus int
+		wantType   string
+	}{
+		{"rate limit", &llmproviders.ProviderError{Provider: llmproviders.ProviderOpenAI, StatusCode: 429, Err: errors.New("429 Too Many Requests")},
+			http.StatusTooManyRequests, "rate_limit_error"},
+		{"wrapped invalid request", fmt.Errorf("attempt 1: %w", &llmproviders.ProviderError{Provider: llmproviders.ProviderAnthropic, StatusCode: 400, Err: errors.New("400 Bad Request")}),
+			http.StatusBadRequest, "invalid_request_error"},
+		{"error without a status", errors.New("connection reset"), http.StatusBadGateway, "api_error"},
+	} {
+		fail = c.err
+		for _, request := range []struct {
+			name, path, body string
+		}{
+			{"OpenAI", "/v1/chat/completions", openAIRequest(false)},
+			{"OpenAI stream", "/v1/chat/completions", openAIRequest(true)},
+			{"Anthropic", "/v1/messages", anthropicRequest(false)},
+			{"Anthropic stream", "/v1/messages", anthropicRequest(true)},
+		} {
+			status, body, err := post(server.URL+request.path, request.body)
+			if err != nil || status != c.wantStatus || !strings.Contains(body, `"type":"`+c.wantType+`"`) {
+				log.Printf("❌ %s, %s: expected %d %s, got %d (error %v) %s", c.name, request.name, c.wantStatus, c.wantType, status, err, body)
+				passed = false
+				continue
+			}
+			log.Printf("✅ %s, %s: %d %s", c.name, request.name, status, c.wantType)
+		}
+	}
+	return passed
+}
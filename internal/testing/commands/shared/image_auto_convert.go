@@ -0,0 +1,127 @@
+package shared
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/gif"
+	"log"
+	"os"
+	"strings"
+
+	llmproviders "github.com/manishiitg/multi-llm-provider-go"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+	"github.com/manishiitg/multi-llm-provider-go/pkg/utils"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/image/bmp"
+)
+
+// ImageAutoConvertTestCmd checks that WithImageAutoConvert re-encodes unsupported image formats
+var ImageAutoConvertTestCmd = &cobra.Command{
+	Use:   "image-auto-convert",
+	Short: "Test converting image formats a provider does not accept",
+	Long: `This test checks that:
+- a GIF sent to Gemini, which does not accept GIF, fails with a hint to use
+  WithImageAutoConvert, and is sent as PNG with it (Vertex dry runs)
+- a BMP with an explicit MediaType is converted to PNG for Anthropic, keeping its size
+- images in supported formats are not re-encoded
+
+No API calls are made.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !RunImageAutoConvertTest() {
+			os.Exit(1)
+		}
+	},
+}
+
+// RunImageAutoConvertTest verifies the requests built with WithImageAutoConvert
+func RunImageAutoConvertTest() bool {
+	log.Printf("\n🔄 Test: Image Auto Convert")
+
+	src := image.NewRGBA(image.Rect(0, 0, 40, 30))
+	for x := 0; x < 40; x++ {
+		src.Set(x, x%30, color.RGBA{R: 255, A: 255})
+	}
+	var gifData, bmpData bytes.Buffer
+	if err := gif.Encode(&gifData, src, nil); err != nil {
+		log.Printf("❌ Failed to encode GIF: %v", err)
+		return false
+	}
+	if err := bmp.Encode(&bmpData, src); err != nil {
+		log.Printf("❌ Failed to encode BMP: %v", err)
+		return false
+	}
+	gifBase64 := base64.StdEncoding.EncodeToString(gifData.Bytes())
+	bmpBase64 := base64.StdEncoding.EncodeToString(bmpData.Bytes())
+
+	messagesWith := func(img llmtypes.ImageContent) []llmtypes.MessageContent {
+		return []llmtypes.MessageContent{
+			{Role: llmtypes.ChatMessageTypeHuman, Parts: []llmtypes.ContentPart{llmtypes.TextContent{Text: "Describe this image"}, img}},
+		}
+	}
+	apiKey := "dry-run"
+	dryRun := func(provider llmproviders.Provider, modelID string, keys *llmproviders.ProviderAPIKeys, messages []llmtypes.MessageContent, options ...llmtypes.CallOption) (string, error) {
+		llm, err := llmproviders.InitializeLLM(llmproviders.Config{Provider: provider, ModelID: modelID, APIKeys: keys})
+		if err != nil {
+			return "", err
+		}
+		resp, err := llm.GenerateContent(context.Background(), messages, append(options, llmtypes.WithDryRun())...)
+		if err != nil {
+			return "", err
+		}
+		request, _ := json.Marshal(resp.Raw)
+		return string(request), nil
+	}
+
+	passed := true
+	vertexKeys := &llmproviders.ProviderAPIKeys{Vertex: &apiKey}
+	gifImage := llmtypes.ImageContent{SourceType: "base64", Data: gifBase64}
+	if _, err := dryRun(llmproviders.ProviderVertex, "gemini-2.5-flash", vertexKeys, messagesWith(gifImage)); err == nil || !strings.Contains(err.Error(), "WithImageAutoConvert") {
+		log.Printf("❌ Expected GIF for Gemini to fail with a WithImageAutoConvert hint, got %v", err)
+		passed = false
+	} else {
+		log.Printf("✅ GIF for Gemini rejected without the option: %v", err)
+	}
+	if request, err := dryRun(llmproviders.ProviderVertex, "gemini-2.5-flash", vertexKeys, messagesWith(gifImage), llmtypes.WithImageAutoConvert()); err != nil || !strings.Contains(request, `"mimeType":"image/png"`) || strings.Contains(request, "image/gif") {
+		log.Printf("❌ Expected GIF sent to Gemini as PNG (err: %v): %s", err, truncateForLog(request))
+		passed = false
+	} else {
+		log.Printf("✅ GIF sent to Gemini as PNG with WithImageAutoConvert")
+	}
+
+	bmpImage := llmtypes.ImageContent{SourceType: "base64", MediaType: "image/bmp", Data: bmpBase64}
+	converted, err := utils.ResolveImageMediaTypes(messagesWith(bmpImage), utils.AnthropicImageLimits, true)
+	if err != nil {
+		log.Printf("❌ BMP conversion failed: %v", err)
+		return false
+	}
+	img := converted[0].Parts[1].(llmtypes.ImageContent)
+	data, _ := base64.StdEncoding.DecodeString(img.Data)
+	if config, format, err := image.DecodeConfig(bytes.NewReader(data)); err != nil || img.MediaType != "image/png" || format != "png" || config.Width != 40 || config.Height != 30 {
+		log.Printf("❌ Expected a 40x30 PNG, got %s %s %dx%d (err: %v)", img.MediaType, format, config.Width, config.Height, err)
+		passed = false
+	} else {
+		log.Printf("✅ BMP converted to a 40x30 PNG for Anthropic")
+	}
+
+	gifForAnthropic := messagesWith(gifImage)
+	if resolved, err := utils.ResolveImageMediaTypes(gifForAnthropic, utils.AnthropicImageLimits, true); err != nil || resolved[0].Parts[1].(llmtypes.ImageContent).Data != gifBase64 || resolved[0].Parts[1].(llmtypes.ImageContent).MediaType != "image/gif" {
+		log.Printf("❌ Expected GIF for Anthropic, which accepts GIF, to be typed but not re-encoded (err: %v)", err)
+		passed = false
+	} else {
+		log.Printf("✅ GIF for Anthropic is kept as GIF")
+	}
+	return passed
+}
+
+// truncateForLog shortens s for log messages
+func truncateForLog(s string) string {
+	if len(s) > 300 {
+		return s[:300] + "..."
+	}
+	return s
+}
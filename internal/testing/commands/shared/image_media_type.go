@@ -0,0 +1,143 @@
+package shared
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"image"
+	"image/png"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+
+	llmproviders "github.com/manishiitg/multi-llm-provider-go"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+	"github.com/manishiitg/multi-llm-provider-go/pkg/utils"
+
+	"github.com/spf13/cobra"
+)
+
+// ImageMediaTypeTestCmd checks that image media types are detected when not set
+var ImageMediaTypeTestCmd = &cobra.Command{
+	Use:   "image-media-type",
+	Short: "Test MIME type detection for images sent without a MediaType",
+	Long: `This test checks that:
+- base64 images without a MediaType are sent with the type sniffed from their data,
+  in messages and in tool results (Anthropic and OpenAI dry runs)
+- formats the provider does not accept and data that is not an image fail with a clear error
+- URL images are typed from the Content-Type header, or sniffed when it is not an image type
+
+No API calls are made.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !RunImageMediaTypeTest() {
+			os.Exit(1)
+		}
+	},
+}
+
+// RunImageMediaTypeTest verifies media type detection for base64 and URL images
+func RunImageMediaTypeTest() bool {
+	log.Printf("\n🖼️  Test: Image Media Type Detection")
+
+	var pngData bytes.Buffer
+	if err := png.Encode(&pngData, image.NewRGBA(image.Rect(0, 0, 2, 2))); err != nil {
+		log.Printf("❌ Failed to encode test image: %v", err)
+		return false
+	}
+	pngBase64 := base64.StdEncoding.EncodeToString(pngData.Bytes())
+	bmpBase64 := base64.StdEncoding.EncodeToString(append([]byte("BM"), make([]byte, 64)...))
+	textBase64 := base64.StdEncoding.EncodeToString([]byte("not an image"))
+
+	messagesWith := func(data string) []llmtypes.MessageContent {
+		return []llmtypes.MessageContent{
+			{Role: llmtypes.ChatMessageTypeHuman, Parts: []llmtypes.ContentPart{
+				llmtypes.TextContent{Text: "What is in this image?"},
+				llmtypes.ImageContent{SourceType: "base64", Data: data},
+			}},
+		}
+	}
+	dryRun := func(provider llmproviders.Provider, modelID string, keys *llmproviders.ProviderAPIKeys, messages []llmtypes.MessageContent) (string, error) {
+		llm, err := llmproviders.InitializeLLM(llmproviders.Config{Provider: provider, ModelID: modelID, APIKeys: keys})
+		if err != nil {
+			return "", err
+		}
+		resp, err := llm.GenerateContent(context.Background(), messages, llmtypes.WithDryRun())
+		if err != nil {
+			return "", err
+		}
+		request, _ := json.Marshal(resp.Raw)
+		return string(request), nil
+	}
+
+	passed := true
+	apiKey := "dry-run"
+	anthropicKeys := &llmproviders.ProviderAPIKeys{Anthropic: &apiKey}
+	openaiKeys := &llmproviders.ProviderAPIKeys{OpenAI: &apiKey}
+	if request, err := dryRun(llmproviders.ProviderAnthropic, "claude-sonnet-4-20250514", anthropicKeys, messagesWith(pngBase64)); err != nil || !strings.Contains(request, `"media_type":"image/png"`) {
+		log.Printf("❌ Anthropic request lacks the detected media type (err: %v): %s", err, request)
+		passed = false
+	} else {
+		log.Printf("✅ Anthropic request has media_type image/png")
+	}
+	if request, err := dryRun(llmproviders.ProviderOpenAI, "gpt-4.1", openaiKeys, messagesWith(pngBase64)); err != nil || !strings.Contains(request, "data:image/png;base64,") {
+		log.Printf("❌ OpenAI request lacks the detected media type (err: %v): %s", err, request)
+		passed = false
+	} else {
+		log.Printf("✅ OpenAI request has a data:image/png URL")
+	}
+
+	toolResult := []llmtypes.MessageContent{{Role: llmtypes.ChatMessageTypeTool, Parts: []llmtypes.ContentPart{
+		llmtypes.ToolCallResponse{ToolCallID: "call_1", Name: "screenshot", Parts: []llmtypes.ContentPart{llmtypes.ImageContent{SourceType: "base64", Data: pngBase64}}},
+	}}}
+	imageOf := func(messages []llmtypes.MessageContent) llmtypes.ImageContent {
+		return messages[0].Parts[0].(llmtypes.ToolCallResponse).Parts[0].(llmtypes.ImageContent)
+	}
+	if resolved, err := utils.ResolveImageMediaTypes(toolResult, utils.AnthropicImageLimits, false); err != nil || imageOf(resolved).MediaType != "image/png" || imageOf(toolResult).MediaType != "" {
+		log.Printf("❌ Expected the tool result image typed image/png in a copy (err: %v)", err)
+		passed = false
+	} else {
+		log.Printf("✅ Tool result image typed image/png without modifying the caller's messages")
+	}
+
+	for _, tc := range []struct {
+		name, data, want string
+	}{
+		{"BMP image", bmpBase64, `"image/bmp" is not supported by anthropic`},
+		{"text data", textBase64, "unrecognized image format"},
+	} {
+		if _, err := dryRun(llmproviders.ProviderAnthropic, "claude-sonnet-4-20250514", anthropicKeys, messagesWith(tc.data)); err == nil || !strings.Contains(err.Error(), tc.want) {
+			log.Printf("❌ %s: expected an error containing %q, got %v", tc.name, tc.want, err)
+			passed = false
+		} else {
+			log.Printf("✅ %s rejected: %v", tc.name, err)
+		}
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/typed":
+			w.Header().Set("Content-Type", "image/webp; charset=binary")
+		case "/untyped":
+			w.Header().Set("Content-Type", "application/octet-stream")
+		}
+		_, _ = w.Write(pngData.Bytes())
+	}))
+	defer server.Close()
+	for _, tc := range []struct {
+		path, want string
+	}{
+		{"/typed", "image/webp"},
+		{"/untyped", "image/png"},
+	} {
+		if _, mediaType, err := utils.FetchImage(context.Background(), server.URL+tc.path); err != nil || mediaType != tc.want {
+			log.Printf("❌ FetchImage %s: expected %s, got %q (err: %v)", tc.path, tc.want, mediaType, err)
+			passed = false
+		} else {
+			log.Printf("✅ FetchImage %s typed %s", tc.path, mediaType)
+		}
+	}
+	return passed
+}
@@ -0,0 +1,137 @@
+package shared
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/png"
+	"log"
+	"math/rand"
+	"os"
+	"strings"
+
+	llmproviders "github.com/manishiitg/multi-llm-provider-go"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+
+	"github.com/spf13/cobra"
+)
+
+// ImagePayloadTestCmd checks the per-request image count and payload limits
+var ImagePayloadTestCmd = &cobra.Command{
+	Use:   "image-payload",
+	Short: "Test the image count and total image size limits of a request",
+	Long: `This test builds requests with WithDryRun and checks that:
+- more images than the provider accepts in one request (100 for Anthropic) fail clearly
+- WithMaxImageCount caps the images of a request, counting tool result images
+- more image data than the provider accepts in one request (20 MB for Gemini) fails clearly
+- WithImageDownsampling downscales the images to fit instead
+
+No API calls are made.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !RunImagePayloadTest() {
+			os.Exit(1)
+		}
+	},
+}
+
+// noisePNG returns a base64 PNG of random pixels, which does not compress
+func noisePNG(size int, seed int64) string {
+	random := rand.New(rand.NewSource(seed))
+	img := image.NewNRGBA(image.Rect(0, 0, size, size))
+	random.Read(img.Pix)
+	for i := 3; i < len(img.Pix); i += 4 {
+		img.Pix[i] = 255
+	}
+	var buf bytes.Buffer
+	_ = png.Encode(&buf, img)
+	return base64.StdEncoding.EncodeToString(buf.Bytes())
+}
+
+// RunImagePayloadTest verifies the image count and payload limits
+func RunImagePayloadTest() bool {
+	log.Printf("\n🖼️  Test: Image Count and Payload Limits")
+
+	apiKey := "dry-run"
+	keys := &llmproviders.ProviderAPIKeys{Anthropic: &apiKey, Vertex: &apiKey}
+	dryRun := func(provider llmproviders.Provider, modelID string, messages []llmtypes.MessageContent, options ...llmtypes.CallOption) (string, error) {
+		llm, err := llmproviders.InitializeLLM(llmproviders.Config{Provider: provider, ModelID: modelID, APIKeys: keys})
+		if err != nil {
+			return "", err
+		}
+		resp, err := llm.GenerateContent(context.Background(), messages, append(options, llmtypes.WithDryRun())...)
+		if err != nil {
+			return "", err
+		}
+		request, _ := json.Marshal(resp.Raw)
+		return string(request), nil
+	}
+	urlImages := func(n int) []llmtypes.ContentPart {
+		parts := []llmtypes.ContentPart{llmtypes.TextContent{Text: "Summarize these pages."}}
+		for i := 0; i < n; i++ {
+			parts = append(parts, llmtypes.ImageContent{SourceType: "url", MediaType: "image/png", Data: fmt.Sprintf("https://example.com/page-%d.png", i)})
+		}
+		return parts
+	}
+	human := func(parts []llmtypes.ContentPart) []llmtypes.MessageContent {
+		return []llmtypes.MessageContent{{Role: llmtypes.ChatMessageTypeHuman, Parts: parts}}
+	}
+
+	passed := true
+	expectError := func(name string, err error, want string) {
+		if err == nil || !strings.Contains(err.Error(), want) {
+			log.Printf("❌ %s: expected an error containing %q, got %v", name, want, err)
+			passed = false
+			return
+		}
+		log.Printf("✅ %s: %v", name, err)
+	}
+
+	_, err := dryRun(llmproviders.ProviderAnthropic, "claude-sonnet-4-20250514", human(urlImages(101)))
+	expectError("101 images on Anthropic", err, "anthropic limit of 100")
+
+	_, err = dryRun(llmproviders.ProviderAnthropic, "claude-sonnet-4-20250514", human(urlImages(11)), llmproviders.WithMaxImageCount(10))
+	expectError("11 images with WithMaxImageCount(10)", err, "WithMaxImageCount")
+
+	// Tool result images count too
+	withToolImages := []llmtypes.MessageContent{
+		{Role: llmtypes.ChatMessageTypeHuman, Parts: urlImages(9)},
+		{Role: llmtypes.ChatMessageTypeAI, Parts: []llmtypes.ContentPart{llmtypes.ToolCall{ID: "call_1", Type: "function", FunctionCall: &llmtypes.FunctionCall{Name: "screenshot", Arguments: "{}"}}}},
+		{Role: llmtypes.ChatMessageTypeTool, Parts: []llmtypes.ContentPart{llmtypes.ToolCallResponse{ToolCallID: "call_1", Name: "screenshot", Parts: urlImages(2)[1:]}}},
+	}
+	_, err = dryRun(llmproviders.ProviderAnthropic, "claude-sonnet-4-20250514", withToolImages, llmproviders.WithMaxImageCount(10))
+	expectError("9 images plus 2 tool result images with WithMaxImageCount(10)", err, "11 images")
+
+	if _, err = dryRun(llmproviders.ProviderAnthropic, "claude-sonnet-4-20250514", human(urlImages(11)), llmproviders.WithMaxImageCount(20)); err != nil {
+		log.Printf("❌ 11 images with WithMaxImageCount(20) should be sent, got %v", err)
+		passed = false
+	} else {
+		log.Printf("✅ 11 images with WithMaxImageCount(20) sent")
+	}
+
+	// Four incompressible 1200x1200 PNGs hold about 23 MB of base64, above Gemini's 20 MB
+	pages := []llmtypes.ContentPart{llmtypes.TextContent{Text: "Summarize these pages."}}
+	originalSize := 0
+	for i := 0; i < 4; i++ {
+		data := noisePNG(1200, int64(i))
+		originalSize += len(data)
+		pages = append(pages, llmtypes.ImageContent{SourceType: "base64", MediaType: "image/png", Data: data})
+	}
+	_, err = dryRun(llmproviders.ProviderVertex, "gemini-2.5-flash", human(pages))
+	expectError(fmt.Sprintf("%d bytes of images on Gemini", originalSize), err, "WithImageDownsampling")
+
+	request, err := dryRun(llmproviders.ProviderVertex, "gemini-2.5-flash", human(pages), llmproviders.WithImageDownsampling())
+	switch {
+	case err != nil:
+		log.Printf("❌ WithImageDownsampling should fit the images, got %v", err)
+		passed = false
+	case len(request) > 20*1024*1024 || strings.Count(request, `"image/jpeg"`) != 4:
+		log.Printf("❌ Expected 4 JPEG images within 20 MB, got a %d byte request with %d JPEG images", len(request), strings.Count(request, `"image/jpeg"`))
+		passed = false
+	default:
+		log.Printf("✅ WithImageDownsampling fit %d bytes of images into a %d byte request", originalSize, len(request))
+	}
+	return passed
+}
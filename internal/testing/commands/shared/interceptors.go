@@ -0,0 +1,128 @@
+package shared
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"strings"
+
+	llmproviders "github.com/manishiitg/multi-llm-provider-go"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+
+	"github.com/spf13/cobra"
+)
+
+// InterceptorsTestCmd checks that request and response interceptors run on every attempt
+var InterceptorsTestCmd = &cobra.Command{
+	Use:   "interceptors",
+	Short: "Test that request and response interceptors run on every attempt",
+	Long: `This test uses a fake model that records the model and headers of every call and checks that:
+- request interceptors run before every attempt and see the attempt's model, e.g. a fallback model
+- headers set by a request interceptor are sent with the attempt
+- response interceptors run on the response of every attempt, empty-content retries included
+- a rejecting request interceptor aborts the call before the provider is called
+- a response interceptor can rewrite the response, and a rejecting one fails the call
+
+No API calls are made.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !RunInterceptorsTest() {
+			os.Exit(1)
+		}
+	},
+}
+
+// attemptRecordingModel answers each call with the next of its answers, repeating the last
+// one, or fails calls to the models in fail, and records the model and X-Attempt header of
+// every call
+type attemptRecordingModel struct {
+	fakeModelID
+	answers []string
+	fail    map[string]error
+	calls   []string
+}
+
+func (m *attemptRecordingModel) GenerateContent(ctx context.Context, messages []llmtypes.MessageContent, options ...llmtypes.CallOption) (*llmtypes.ContentResponse, error) {
+	opts := callOptions(options)
+	model := m.GetModelID()
+	if opts.Model != "" {
+		model = opts.Model
+	}
+	m.calls = append(m.calls, model+"/"+opts.ExtraHeaders["X-Attempt"])
+	if err := m.fail[model]; err != nil {
+		return nil, err
+	}
+	answer := m.answers[min(len(m.calls), len(m.answers))-1]
+	return textResponse(answer), nil
+}
+
+// RunInterceptorsTest verifies when request and response interceptors run and what they see
+func RunInterceptorsTest() bool {
+	log.Printf("\n🪝 Test: Interceptors")
+
+	messages := []llmtypes.MessageContent{llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "ping")}
+	passed := true
+	for _, c := range []struct {
+		name      string
+		model     *attemptRecordingModel
+		options   []llmtypes.CallOption
+		rewrite   bool
+		reject    string
+		wantCalls string
+		wantSeen  string
+		wantErr   string
+		wantReply string
+	}{
+		{"fallback model", &attemptRecordingModel{answers: []string{"pong"}, fail: map[string]error{"fake-model": errors.New("503 service unavailable")}},
+			[]llmtypes.CallOption{llmproviders.WithFallbackModels([]string{"backup-model"})}, false, "",
+			"fake-model/fake-model backup-model/backup-model", "request:fake-model request:backup-model response", "", "pong"},
+		{"empty-content retry", &attemptRecordingModel{answers: []string{"", "pong"}},
+			[]llmtypes.CallOption{llmproviders.WithRetryOnEmptyContent(1)}, false, "",
+			"fake-model/fake-model fake-model/fake-model", "request:fake-model response request:fake-model response", "", "pong"},
+		{"rewritten response", &attemptRecordingModel{answers: []string{"pong"}}, nil, true, "",
+			"fake-model/fake-model", "request:fake-model response", "", "PONG"},
+		{"rejected request", &attemptRecordingModel{answers: []string{"pong"}}, nil, false, "request",
+			"", "request:fake-model", "request interceptor 1", ""},
+		{"rejected response", &attemptRecordingModel{answers: []string{"pong"}}, nil, false, "response",
+			"fake-model/fake-model", "request:fake-model response", "response interceptor 1", ""},
+	} {
+		var seen []string
+		options := append([]llmtypes.CallOption{
+			llmproviders.WithRequestInterceptor(func(req *llmtypes.ProviderRequest) error {
+				seen = append(seen, "request:"+req.ModelID)
+				req.Headers["X-Attempt"] = req.ModelID
+				if c.reject == "request" {
+					return errors.New("blocked")
+				}
+				return nil
+			}),
+			llmproviders.WithResponseInterceptor(func(resp *llmtypes.ContentResponse) error {
+				seen = append(seen, "response")
+				if c.rewrite {
+					resp.Choices[0].Content = strings.ToUpper(resp.Choices[0].Content)
+				}
+				if c.reject == "response" {
+					return errors.New("blocked")
+				}
+				return nil
+			}),
+		}, c.options...)
+
+		llm := newFakeLLM(c.model, llmproviders.ProviderOpenAI)
+		resp, err := llm.GenerateContent(context.Background(), messages, options...)
+		reply := ""
+		if err == nil && resp != nil && len(resp.Choices) > 0 {
+			reply = resp.Choices[0].Content
+		}
+		calls, interceptors := strings.Join(c.model.calls, " "), strings.Join(seen, " ")
+		if calls != c.wantCalls || interceptors != c.wantSeen || reply != c.wantReply ||
+			(c.wantErr == "") != (err == nil) || (err != nil && !strings.Contains(err.Error(), c.wantErr)) {
+			log.Printf("❌ %s: expected calls %q, interceptors %q, reply %q and error %q, got %q, %q, %q and %v",
+				c.name, c.wantCalls, c.wantSeen, c.wantReply, c.wantErr, calls, interceptors, reply, err)
+			passed = false
+			continue
+		}
+		log.Printf("✅ %s: calls %q, interceptors %q", c.name, calls, interceptors)
+	}
+	return passed
+}
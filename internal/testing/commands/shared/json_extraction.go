@@ -0,0 +1,88 @@
+package shared
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+
+	llmproviders "github.com/manishiitg/multi-llm-provider-go"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+
+	"github.com/spf13/cobra"
+)
+
+// JSONExtractionTestCmd checks ParseJSONFromContent and its use for structured output
+var JSONExtractionTestCmd = &cobra.Command{
+	Use:   "json-extraction",
+	Short: "Test extracting JSON from answers with code fences, preamble and trailing text",
+	Long: `This test checks that ParseJSONFromContent extracts the JSON document from:
+- clean JSON objects and arrays
+- markdown code fences, with or without a language tag
+- preamble ("Here's your JSON:") and text after the JSON
+- JSON whose strings contain braces, brackets and fences
+and returns ErrNoJSON for answers without valid JSON. It also checks that structured
+output returns clean JSON when the model wraps it in prose and fences.
+
+No API calls are made.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !RunJSONExtractionTest() {
+			os.Exit(1)
+		}
+	},
+}
+
+// RunJSONExtractionTest verifies ParseJSONFromContent
+func RunJSONExtractionTest() bool {
+	log.Printf("\n🧾 Test: JSON Extraction")
+
+	passed := true
+	for _, c := range []struct {
+		name, content, want string
+	}{
+		{"clean object", `{"city":"Paris"}`, `{"city":"Paris"}`},
+		{"clean array", " [1, 2, 3]\n", `[1, 2, 3]`},
+		{"fenced with language", "```json\n{\"city\":\"Paris\"}\n```", `{"city":"Paris"}`},
+		{"fenced without language", "```\n[{\"a\":1}]\n```", `[{"a":1}]`},
+		{"preamble and fence", "Here's your JSON:\n\n```json\n{\"city\":\"Paris\"}\n```\nLet me know if you need more.", `{"city":"Paris"}`},
+		{"preamble", `Sure! The result is {"city":"Paris","tags":["a","b"]}`, `{"city":"Paris","tags":["a","b"]}`},
+		{"trailing text", `{"ok":true} I hope this helps {with braces}.`, `{"ok":true}`},
+		{"brackets in prose", `Step [one] done: {"step":1}`, `{"step":1}`},
+		{"braces in strings", "Result: {\"code\":\"if (x) { y[0] = '}' }\",\"fence\":\"```\"} end", "{\"code\":\"if (x) { y[0] = '}' }\",\"fence\":\"```\"}"},
+		{"escaped quotes", `Output: {"quote":"she said \"{hi}\""} done`, `{"quote":"she said \"{hi}\""}`},
+		{"unclosed fence", "```json\n{\"city\":\"Paris\"}", `{"city":"Paris"}`},
+	} {
+		document, err := llmproviders.ParseJSONFromContent(c.content)
+		if err != nil || string(document) != c.want {
+			log.Printf("❌ %s: expected %s, got %s (error %v)", c.name, c.want, document, err)
+			passed = false
+		}
+	}
+	for _, content := range []string{"", "No JSON here.", `{"unterminated": "value"`, "{not json}"} {
+		if document, err := llmproviders.ParseJSONFromContent(content); !errors.Is(err, llmproviders.ErrNoJSON) {
+			log.Printf("❌ Expected ErrNoJSON for %q, got %s (error %v)", content, document, err)
+			passed = false
+		}
+	}
+	if passed {
+		log.Printf("✅ JSON extracted from clean, fenced, prefixed and suffixed answers")
+	}
+
+	// Structured output returns the JSON without the prose and fences around it
+	model := &chunkedContentModel{pieces: []string{"Here's the city you asked for:\n```json\n{\"city\":\"Paris\"}\n```\nAnything else?"}}
+	llm := newFakeLLM(model, llmproviders.ProviderOpenAI)
+	schema := map[string]interface{}{
+		"type":       "object",
+		"properties": map[string]interface{}{"city": map[string]interface{}{"type": "string"}},
+		"required":   []string{"city"},
+	}
+	resp, err := llm.GenerateContent(context.Background(), []llmtypes.MessageContent{
+		llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "Which city is the capital of France?"),
+	}, llmproviders.WithStructuredOutput(schema, "city", false))
+	if err != nil || resp.Choices[0].Content != `{"city":"Paris"}` {
+		log.Printf("❌ Expected clean structured output, got %v (error %v)", resp, err)
+		return false
+	}
+	log.Printf("✅ Structured output extracted from a fenced answer: %s", resp.Choices[0].Content)
+	return passed
+}
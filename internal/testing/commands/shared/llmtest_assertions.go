@@ -0,0 +1,166 @@
+package shared
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+	"github.com/manishiitg/multi-llm-provider-go/pkg/llmtest"
+
+	"github.com/spf13/cobra"
+)
+
+// LLMTestAssertionsTestCmd checks the llmtest assertion helpers
+var LLMTestAssertionsTestCmd = &cobra.Command{
+	Use:   "llmtest-assertions",
+	Short: "Test the llmtest assertion helpers on passing and failing responses",
+	Long: `This test checks that the llmtest assertions pass on well-formed responses and report:
+- a missing tool call, or a call to another tool
+- tool arguments that are invalid JSON, miss required parameters or break the schema
+- too few parallel tool calls, or duplicate tool call IDs
+- streamed content, tool calls or stop reasons that differ from the final response
+
+No API calls are made.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !RunLLMTestAssertionsTest() {
+			os.Exit(1)
+		}
+	},
+}
+
+// recordingTB is an llmtest.TB that records reported failures
+type recordingTB struct {
+	errors []string
+}
+
+func (t *recordingTB) Helper() {}
+
+func (t *recordingTB) Errorf(format string, args ...interface{}) {
+	t.errors = append(t.errors, fmt.Sprintf(format, args...))
+}
+
+// RunLLMTestAssertionsTest verifies the llmtest assertions
+func RunLLMTestAssertionsTest() bool {
+	log.Printf("\n🧪 Test: llmtest assertions")
+
+	weatherTool := llmtypes.Tool{
+		Type: "function",
+		Function: &llmtypes.FunctionDefinition{
+			Name: "get_weather",
+			Parameters: llmtypes.NewParameters(map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"city": map[string]interface{}{"type": "string"},
+					"unit": map[string]interface{}{"type": "string", "enum": []string{"celsius", "fahrenheit"}},
+				},
+				"required":             []string{"city"},
+				"additionalProperties": false,
+			}),
+		},
+	}
+	call := func(id, name, args string) llmtypes.ToolCall {
+		return llmtypes.ToolCall{ID: id, Type: "function", FunctionCall: &llmtypes.FunctionCall{Name: name, Arguments: args}}
+	}
+	response := func(content string, toolCalls ...llmtypes.ToolCall) *llmtypes.ContentResponse {
+		stopReason := "stop"
+		if len(toolCalls) > 0 {
+			stopReason = "tool_calls"
+		}
+		return &llmtypes.ContentResponse{Choices: []*llmtypes.ContentChoice{{Content: content, StopReason: stopReason, ToolCalls: toolCalls}}}
+	}
+	parallel := response("", call("call_1", "get_weather", `{"city":"Paris"}`), call("call_2", "get_weather", `{"city":"Rome","unit":"celsius"}`))
+
+	passed := true
+	// check runs assert and expects it to pass when want is empty, or to fail with want
+	check := func(name, want string, assert func(t llmtest.TB) bool) {
+		t := &recordingTB{}
+		ok := assert(t)
+		switch {
+		case want == "" && (!ok || len(t.errors) > 0):
+			log.Printf("❌ %s: expected to pass, got %v", name, t.errors)
+			passed = false
+		case want != "" && (ok || len(t.errors) != 1 || !strings.Contains(t.errors[0], want)):
+			log.Printf("❌ %s: expected one failure containing %q, got %v", name, want, t.errors)
+			passed = false
+		case want == "":
+			log.Printf("✅ %s passes", name)
+		default:
+			log.Printf("✅ %s fails: %s", name, t.errors[0])
+		}
+	}
+
+	check("AssertToolCall", "", func(t llmtest.TB) bool {
+		return llmtest.AssertToolCall(t, parallel, "get_weather").ID == "call_1"
+	})
+	check("AssertToolCall without tool calls", "got no tool calls", func(t llmtest.TB) bool {
+		return llmtest.AssertToolCall(t, response("It is sunny."), "get_weather").FunctionCall != nil
+	})
+	check("AssertToolCall to another tool", "got calls to [read_file]", func(t llmtest.TB) bool {
+		return llmtest.AssertToolCall(t, response("", call("call_1", "read_file", `{}`)), "get_weather").FunctionCall != nil
+	})
+
+	for _, tc := range []struct {
+		name string
+		args string
+		want string
+	}{
+		{"AssertValidToolArgs", `{"city":"Paris","unit":"celsius"}`, ""},
+		{"AssertValidToolArgs with invalid JSON", `{"city":`, "not valid JSON"},
+		{"AssertValidToolArgs without a required parameter", `{"unit":"celsius"}`, "city"},
+		{"AssertValidToolArgs with a value outside the enum", `{"city":"Paris","unit":"kelvin"}`, "$.unit"},
+		{"AssertValidToolArgs with an unknown parameter", `{"city":"Paris","country":"FR"}`, "country"},
+		{"AssertValidToolArgs with an array", `["Paris"]`, "not a JSON object"},
+	} {
+		check(tc.name, tc.want, func(t llmtest.TB) bool {
+			return llmtest.AssertValidToolArgs(t, weatherTool, call("call_1", "get_weather", tc.args))
+		})
+	}
+
+	check("AssertParallelToolCalls", "", func(t llmtest.TB) bool { return llmtest.AssertParallelToolCalls(t, parallel, 2) })
+	check("AssertParallelToolCalls with too few calls", "expected at least 3", func(t llmtest.TB) bool { return llmtest.AssertParallelToolCalls(t, parallel, 3) })
+	check("AssertParallelToolCalls with duplicate IDs", "used by more than one", func(t llmtest.TB) bool {
+		return llmtest.AssertParallelToolCalls(t, response("", call("call_1", "get_weather", `{"city":"Paris"}`), call("call_1", "get_weather", `{"city":"Rome"}`)), 2)
+	})
+
+	// The stream sends tool calls in another order and with other argument formatting
+	streamed := []llmtypes.StreamChunk{
+		{Type: llmtypes.StreamChunkTypeContent, Content: "Checking "},
+		{Type: llmtypes.StreamChunkTypeContent, Content: "both."},
+		{Type: llmtypes.StreamChunkTypeToolCall, ToolCall: &llmtypes.ToolCall{ID: "call_2", FunctionCall: &llmtypes.FunctionCall{Name: "get_weather", Arguments: `{"unit": "celsius", "city": "Rome"}`}}},
+		{Type: llmtypes.StreamChunkTypeToolCall, ToolCall: &llmtypes.ToolCall{ID: "call_1", FunctionCall: &llmtypes.FunctionCall{Name: "get_weather", Arguments: `{"city":"Paris"}`}}},
+		{Type: llmtypes.StreamChunkTypeFinish, StopReason: "tool_calls"},
+	}
+	final := response("Checking both.", parallel.Choices[0].ToolCalls...)
+	check("AssertStreamedEqualsFinal", "", func(t llmtest.TB) bool { return llmtest.AssertStreamedEqualsFinal(t, streamed, final) })
+	check("AssertStreamedEqualsFinal with missing content", `streamed content "Checking "`, func(t llmtest.TB) bool {
+		return llmtest.AssertStreamedEqualsFinal(t, append(append([]llmtypes.StreamChunk{}, streamed[0]), streamed[2:]...), final)
+	})
+	check("AssertStreamedEqualsFinal with a missing tool call", "streamed 1 tool calls", func(t llmtest.TB) bool {
+		return llmtest.AssertStreamedEqualsFinal(t, append(append([]llmtypes.StreamChunk{}, streamed[:3]...), streamed[4]), final)
+	})
+	check("AssertStreamedEqualsFinal with other arguments", "has arguments", func(t llmtest.TB) bool {
+		changed := append([]llmtypes.StreamChunk{}, streamed...)
+		changed[3] = llmtypes.StreamChunk{Type: llmtypes.StreamChunkTypeToolCall, ToolCall: &llmtypes.ToolCall{ID: "call_1", FunctionCall: &llmtypes.FunctionCall{Name: "get_weather", Arguments: `{"city":"Lyon"}`}}}
+		return llmtest.AssertStreamedEqualsFinal(t, changed, final)
+	})
+	check("AssertStreamedEqualsFinal with another stop reason", "streamed stop reason", func(t llmtest.TB) bool {
+		changed := append([]llmtypes.StreamChunk{}, streamed...)
+		changed[4].StopReason = "stop"
+		return llmtest.AssertStreamedEqualsFinal(t, changed, final)
+	})
+
+	streamChan, chunks := llmtest.Collect()
+	for _, chunk := range streamed {
+		streamChan <- chunk
+	}
+	close(streamChan)
+	if got := chunks(); len(got) != len(streamed) || len(chunks()) != len(streamed) {
+		log.Printf("❌ Collect: expected %d chunks, got %d", len(streamed), len(got))
+		passed = false
+	} else {
+		log.Printf("✅ Collect returns every streamed chunk")
+	}
+	return passed
+}
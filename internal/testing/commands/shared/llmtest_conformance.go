@@ -0,0 +1,157 @@
+package shared
+
+import (
+	"context"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+	"github.com/manishiitg/multi-llm-provider-go/pkg/llmtest"
+
+	"github.com/spf13/cobra"
+)
+
+// LLMTestConformanceTestCmd checks llmtest.RunConformance on fake models
+var LLMTestConformanceTestCmd = &cobra.Command{
+	Use:   "llmtest-conformance",
+	Short: "Test the llmtest conformance runner on scripted models",
+	Long: `This test runs llmtest.RunConformance on a scripted model that answers every test of the
+battery, and checks:
+- every test passes when all capabilities are declared
+- tests needing undeclared capabilities (vision, tools, ...) are skipped, not run
+- a model that returns no tool calls fails the tool tests and reports them
+
+No API calls are made.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !RunLLMTestConformanceTest() {
+			os.Exit(1)
+		}
+	},
+}
+
+// conformingModel is a fake model that answers each test of the conformance battery,
+// except tool calls when noTools is set. It records whether it was sent an image.
+type conformingModel struct {
+	fakeModelID
+	noTools  bool
+	sawImage bool
+}
+
+func (m *conformingModel) GenerateContent(ctx context.Context, messages []llmtypes.MessageContent, options ...llmtypes.CallOption) (*llmtypes.ContentResponse, error) {
+	opts := callOptions(options)
+	var prompt string
+	last := messages[len(messages)-1]
+	for _, part := range last.Parts {
+		switch part := part.(type) {
+		case llmtypes.TextContent:
+			prompt = part.Text
+		case llmtypes.ImageContent:
+			m.sawImage = true
+		}
+	}
+
+	choice := &llmtypes.ContentChoice{StopReason: "stop"}
+	call := func(id, city string) llmtypes.ToolCall {
+		return llmtypes.ToolCall{ID: id, Type: "function", FunctionCall: &llmtypes.FunctionCall{Name: "get_weather", Arguments: `{"city":"` + city + `"}`}}
+	}
+	switch {
+	case last.Role == llmtypes.ChatMessageTypeTool:
+		choice.Content = "It is sunny and 22°C in Paris."
+	case len(opts.Tools) > 0 && !m.noTools && strings.Contains(prompt, "Tokyo") && strings.Contains(prompt, "Paris"):
+		choice.StopReason = "tool_calls"
+		choice.ToolCalls = []llmtypes.ToolCall{call("call_1", "Paris"), call("call_2", "Tokyo")}
+	case len(opts.Tools) > 0 && !m.noTools:
+		choice.StopReason = "tool_calls"
+		choice.ToolCalls = []llmtypes.ToolCall{call("call_1", "Paris")}
+	case opts.JSONSchema != nil:
+		choice.Content = `{"city":"Paris","country":"France","population":2100000}`
+	case m.sawImage:
+		choice.Content = "Red."
+	default:
+		choice.Content = "Hello! 1, 2, 3"
+	}
+
+	return streamChoice(opts, choice, strings.SplitAfter(choice.Content, " ")...), nil
+}
+
+// RunLLMTestConformanceTest verifies llmtest.RunConformance
+func RunLLMTestConformanceTest() bool {
+	log.Printf("\n🧪 Test: llmtest conformance")
+
+	passed := true
+	statuses := func(results []llmtest.Result) map[string]llmtest.Status {
+		byName := make(map[string]llmtest.Status, len(results))
+		for _, result := range results {
+			byName[result.Name] = result.Status
+		}
+		return byName
+	}
+	expect := func(label string, got map[string]llmtest.Status, want map[string]llmtest.Status) {
+		for name, status := range want {
+			if got[name] != status {
+				log.Printf("❌ %s: %s is %s, expected %s", label, name, got[name], status)
+				passed = false
+			}
+		}
+	}
+
+	// Every capability declared: the whole battery runs and passes
+	all := llmtest.Capabilities{Tools: true, ParallelToolCalls: true, Streaming: true, JSONSchema: true, Vision: true}
+	t := &recordingTB{}
+	results := llmtest.RunConformance(t, &conformingModel{}, "fake-model", all)
+	if len(results) != 8 || len(t.errors) > 0 {
+		log.Printf("❌ Full battery: %d results, failures %v", len(results), t.errors)
+		passed = false
+	}
+	for _, result := range results {
+		if result.Status != llmtest.StatusPassed {
+			log.Printf("❌ Full battery: %s is %s: %v", result.Name, result.Status, result.Err)
+			passed = false
+		}
+	}
+	var matrix strings.Builder
+	llmtest.WriteMatrix(&matrix, "fake-model", results)
+	if !strings.Contains(matrix.String(), "structured_output") || !strings.Contains(matrix.String(), "passed") {
+		log.Printf("❌ Matrix is missing tests or statuses:\n%s", matrix.String())
+		passed = false
+	}
+
+	// Text-only model: no tool, vision or structured output tests, and no image is sent
+	model := &conformingModel{}
+	t = &recordingTB{}
+	results = llmtest.RunConformance(t, model, "fake-model", llmtest.Capabilities{Streaming: true})
+	expect("Text-only", statuses(results), map[string]llmtest.Status{
+		"plain_text":          llmtest.StatusPassed,
+		"streaming":           llmtest.StatusPassed,
+		"tool_call":           llmtest.StatusSkipped,
+		"parallel_tool_calls": llmtest.StatusSkipped,
+		"streaming_tool_call": llmtest.StatusSkipped,
+		"structured_output":   llmtest.StatusSkipped,
+		"image":               llmtest.StatusSkipped,
+	})
+	if model.sawImage || len(t.errors) > 0 {
+		log.Printf("❌ Text-only: image sent %v, failures %v", model.sawImage, t.errors)
+		passed = false
+	}
+
+	// Declared tools the model never calls: the tool tests fail and are reported
+	t = &recordingTB{}
+	results = llmtest.RunConformance(t, &conformingModel{noTools: true}, "fake-model", all)
+	expect("No tool calls", statuses(results), map[string]llmtest.Status{
+		"plain_text":          llmtest.StatusPassed,
+		"tool_call":           llmtest.StatusFailed,
+		"parallel_tool_calls": llmtest.StatusFailed,
+		"streaming_tool_call": llmtest.StatusFailed,
+		"image":               llmtest.StatusPassed,
+	})
+	if len(t.errors) != 3 || !strings.Contains(t.errors[0], "tool_call: expected a call to get_weather") {
+		log.Printf("❌ No tool calls: expected 3 reported failures, got %v", t.errors)
+		passed = false
+	}
+
+	if passed {
+		log.Printf("✅ llmtest conformance test passed")
+	}
+	return passed
+}
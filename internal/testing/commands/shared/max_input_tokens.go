@@ -0,0 +1,85 @@
+package shared
+
+import (
+	"context"
+	"log"
+	"os"
+	"strings"
+
+	llmproviders "github.com/manishiitg/multi-llm-provider-go"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+	"github.com/manishiitg/multi-llm-provider-go/pkg/history"
+
+	"github.com/spf13/cobra"
+)
+
+// MaxInputTokensTestCmd checks that WithMaxInputTokens trims the history before sending
+var MaxInputTokensTestCmd = &cobra.Command{
+	Use:   "max-input-tokens",
+	Short: "Test that WithMaxInputTokens trims the oldest messages before sending",
+	Long: `This test uses a fake model that records the messages it is sent and checks that
+with WithMaxInputTokens:
+- the oldest messages are dropped and an input trimmed event reports them
+- the system prompt and the current turn of an agent loop (the last user message and all
+  its tool-call exchanges) are sent whole, even when the turn alone exceeds the budget
+- a history within the budget is sent unchanged, without an event
+
+No API calls are made.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !RunMaxInputTokensTest() {
+			os.Exit(1)
+		}
+	},
+}
+
+// RunMaxInputTokensTest verifies the messages sent with WithMaxInputTokens
+func RunMaxInputTokensTest() bool {
+	log.Printf("\n📏 Test: Max Input Tokens")
+
+	long := strings.Repeat(" lorem ipsum", 200)
+	system := llmtypes.TextParts(llmtypes.ChatMessageTypeSystem, "system prompt")
+	messages := []llmtypes.MessageContent{
+		system,
+		llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "u1"+long),
+		llmtypes.TextParts(llmtypes.ChatMessageTypeAI, "a1"+long),
+		llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "u2 what changed?"),
+	}
+	messages = append(messages, toolExchange("call_1", "r1"+long)...)
+	messages = append(messages, toolExchange("call_2", "r2"+long)...)
+
+	var sent string
+	model := fakeModelFunc(func(ctx context.Context, messages []llmtypes.MessageContent, opts *llmtypes.CallOptions) (*llmtypes.ContentResponse, error) {
+		sent = messageLabels(messages)
+		return textResponse("Nothing changed."), nil
+	})
+
+	passed := true
+	for _, c := range []struct {
+		name    string
+		budget  int
+		want    string
+		dropped int
+	}{
+		{"over budget", history.CountTokens(append([]llmtypes.MessageContent{system}, messages[3:]...)) + 10,
+			"system u2 call:call_1 result:call_1 call:call_2 result:call_2", 2},
+		{"turn over budget", history.CountTokens(messages[len(messages)-2:]),
+			"system u2 call:call_1 result:call_1 call:call_2 result:call_2", 2},
+		{"within budget", history.CountTokens(messages) + 10,
+			"system u1 a1 u2 call:call_1 result:call_1 call:call_2 result:call_2", 0},
+	} {
+		emitter := NewTestEventEmitter()
+		llm := llmproviders.NewProviderAwareLLM(model, llmproviders.ProviderOpenAI, model.GetModelID(), emitter, "max-input-tokens-test", nil)
+		_, err := llm.GenerateContent(context.Background(), messages, llmproviders.WithMaxInputTokens(c.budget))
+		dropped := 0
+		for _, event := range emitter.InputTrimmedEvents {
+			dropped += event["dropped_messages"].(int)
+		}
+		if err != nil || sent != c.want || dropped != c.dropped {
+			log.Printf("❌ %s: expected %q with %d messages dropped, got %q with %d dropped (error %v)", c.name, c.want, c.dropped, sent, dropped, err)
+			passed = false
+			continue
+		}
+		log.Printf("✅ %s: sent %q, %d messages dropped", c.name, sent, dropped)
+	}
+	return passed
+}
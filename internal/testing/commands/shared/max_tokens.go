@@ -0,0 +1,106 @@
+package shared
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	llmproviders "github.com/manishiitg/multi-llm-provider-go"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+
+	"github.com/spf13/cobra"
+)
+
+// MaxTokensTestCmd checks which field carries the output token cap of OpenAI requests
+var MaxTokensTestCmd = &cobra.Command{
+	Use:   "max-tokens",
+	Short: "Test that OpenAI reasoning models are sent max_completion_tokens instead of max_tokens",
+	Long: `This test builds requests with WithDryRun and checks that WithMaxTokens is sent as:
+- max_completion_tokens for OpenAI o-series and gpt-5 models
+- max_tokens for other OpenAI models and for models routed through OpenRouter
+- nothing when no cap is set
+
+No API calls are made.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !RunMaxTokensTest() {
+			os.Exit(1)
+		}
+	},
+}
+
+// RunMaxTokensTest verifies the token cap field of OpenAI requests
+func RunMaxTokensTest() bool {
+	log.Printf("\n📏 Test: Max Tokens Field")
+
+	apiKey := "dry-run"
+	keys := &llmproviders.ProviderAPIKeys{OpenAI: &apiKey, OpenRouter: &apiKey}
+	messages := []llmtypes.MessageContent{llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "Hello")}
+
+	passed := true
+	for _, c := range []struct {
+		provider  llmproviders.Provider
+		modelID   string
+		maxTokens int
+		want      string // the cap field expected in the request, "" for none
+	}{
+		{llmproviders.ProviderOpenAI, "o3", 256, "max_completion_tokens"},
+		{llmproviders.ProviderOpenAI, "o3-mini", 256, "max_completion_tokens"},
+		{llmproviders.ProviderOpenAI, "o4-mini", 256, "max_completion_tokens"},
+		{llmproviders.ProviderOpenAI, "o1", 256, "max_completion_tokens"},
+		{llmproviders.ProviderOpenAI, "gpt-5-mini", 256, "max_completion_tokens"},
+		{llmproviders.ProviderOpenAI, "gpt-4o", 256, "max_tokens"},
+		{llmproviders.ProviderOpenAI, "gpt-4.1", 256, "max_tokens"},
+		{llmproviders.ProviderOpenRouter, "openai/o3", 256, "max_tokens"},
+		{llmproviders.ProviderOpenAI, "o3", 0, ""},
+	} {
+		llm, err := llmproviders.InitializeLLM(llmproviders.Config{Provider: c.provider, ModelID: c.modelID, APIKeys: keys})
+		if err != nil {
+			log.Printf("❌ %s initialization failed: %v", c.provider, err)
+			passed = false
+			continue
+		}
+		options := []llmtypes.CallOption{llmtypes.WithDryRun()}
+		if c.maxTokens > 0 {
+			options = append(options, llmtypes.WithMaxTokens(c.maxTokens))
+		}
+		resp, err := llm.GenerateContent(context.Background(), messages, options...)
+		if err != nil || resp == nil || !resp.DryRun {
+			log.Printf("❌ %s %s dry run failed: %v", c.provider, c.modelID, err)
+			passed = false
+			continue
+		}
+		raw, _ := json.Marshal(resp.Raw)
+		request := string(raw)
+		hasMaxTokens := strings.Contains(request, `"max_tokens"`)
+		hasMaxCompletionTokens := strings.Contains(request, `"max_completion_tokens"`)
+		switch c.want {
+		case "":
+			if hasMaxTokens || hasMaxCompletionTokens {
+				log.Printf("❌ %s %s should be sent no token cap: %s", c.provider, c.modelID, request)
+				passed = false
+				continue
+			}
+		case "max_completion_tokens":
+			if hasMaxTokens || !strings.Contains(request, fmt.Sprintf(`"max_completion_tokens":%d`, c.maxTokens)) {
+				log.Printf("❌ %s %s should be sent max_completion_tokens only: %s", c.provider, c.modelID, request)
+				passed = false
+				continue
+			}
+		default:
+			if hasMaxCompletionTokens || !strings.Contains(request, fmt.Sprintf(`"max_tokens":%d`, c.maxTokens)) {
+				log.Printf("❌ %s %s should be sent max_tokens only: %s", c.provider, c.modelID, request)
+				passed = false
+				continue
+			}
+		}
+		if c.want == "" {
+			log.Printf("✅ %s %s: no token cap sent without WithMaxTokens", c.provider, c.modelID)
+		} else {
+			log.Printf("✅ %s %s: WithMaxTokens(%d) sent as %s", c.provider, c.modelID, c.maxTokens, c.want)
+		}
+	}
+	return passed
+}
@@ -0,0 +1,133 @@
+package shared
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	llmproviders "github.com/manishiitg/multi-llm-provider-go"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+
+	"github.com/spf13/cobra"
+)
+
+// MaxToolCallsTestCmd checks that WithMaxToolCallsPerResponse caps returned and streamed tool calls
+var MaxToolCallsTestCmd = &cobra.Command{
+	Use:   "max-tool-calls",
+	Short: "Test that WithMaxToolCallsPerResponse keeps only the first n tool calls",
+	Long: `This test makes a fake model answer with many parallel tool calls, natively and
+through tool emulation, and checks that with WithMaxToolCallsPerResponse(2):
+- the response keeps the first 2 tool calls in order
+- only those 2 are streamed
+- a tool calls truncated event is emitted
+
+No API calls are made.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !RunMaxToolCallsTest() {
+			os.Exit(1)
+		}
+	},
+}
+
+// manyToolCallsModel is a fake model that answers with count tool calls, natively or as
+// emulated tool call blocks in the text
+type manyToolCallsModel struct {
+	fakeModelID
+	count    int
+	emulated bool
+}
+
+func (m *manyToolCallsModel) GenerateContent(ctx context.Context, messages []llmtypes.MessageContent, options ...llmtypes.CallOption) (*llmtypes.ContentResponse, error) {
+	opts := callOptions(options)
+	choice := &llmtypes.ContentChoice{StopReason: "tool_calls"}
+	var content strings.Builder
+	for i := 0; i < m.count; i++ {
+		if m.emulated {
+			content.WriteString(fmt.Sprintf(`<tool_call>{"name": "lookup", "arguments": {"n": %d}}</tool_call>`, i))
+			continue
+		}
+		choice.ToolCalls = append(choice.ToolCalls, llmtypes.ToolCall{
+			ID:           fmt.Sprintf("call_%d", i),
+			Type:         "function",
+			FunctionCall: &llmtypes.FunctionCall{Name: "lookup", Arguments: fmt.Sprintf(`{"n": %d}`, i)},
+		})
+	}
+	choice.Content = content.String()
+	if opts.StreamChan != nil {
+		for i := range choice.ToolCalls {
+			opts.StreamChan <- llmtypes.StreamChunk{Type: llmtypes.StreamChunkTypeToolCall, ToolCall: &choice.ToolCalls[i]}
+		}
+		close(opts.StreamChan)
+	}
+	return &llmtypes.ContentResponse{Choices: []*llmtypes.ContentChoice{choice}}, nil
+}
+
+func (m *manyToolCallsModel) Call(ctx context.Context, prompt string, options ...llmtypes.CallOption) (string, error) {
+	return "", nil
+}
+
+// RunMaxToolCallsTest verifies tool calls beyond the cap are dropped from the response and stream
+func RunMaxToolCallsTest() bool {
+	log.Printf("\n✂️  Test: Max Tool Calls Per Response")
+
+	tools := []llmtypes.Tool{{Type: "function", Function: &llmtypes.FunctionDefinition{
+		Name:        "lookup",
+		Description: "Look up a number",
+		Parameters:  llmtypes.NewParameters(map[string]interface{}{"type": "object", "properties": map[string]interface{}{"n": map[string]interface{}{"type": "integer"}}}),
+	}}}
+	messages := []llmtypes.MessageContent{llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "Look up 0 to 9")}
+
+	passed := true
+	for _, tc := range []struct {
+		name    string
+		modelID string
+		model   *manyToolCallsModel
+		options []llmtypes.CallOption
+	}{
+		{"native", "fake-model", &manyToolCallsModel{count: 10}, nil},
+		// gemma has no native tool calling, so the calls are parsed from the text
+		{"emulated", "gemma-3-27b-it", &manyToolCallsModel{count: 10, emulated: true}, []llmtypes.CallOption{llmtypes.WithToolEmulation()}},
+	} {
+		emitter := NewTestEventEmitter()
+		llm := llmproviders.NewProviderAwareLLM(tc.model, llmproviders.ProviderOpenRouter, tc.modelID, emitter, "max-tool-calls-test", nil)
+		streamChan := make(chan llmtypes.StreamChunk, 20)
+		options := append([]llmtypes.CallOption{llmtypes.WithTools(tools), llmtypes.WithStreamingChan(streamChan), llmtypes.WithMaxToolCallsPerResponse(2)}, tc.options...)
+		resp, err := llm.GenerateContent(context.Background(), messages, options...)
+		if err != nil || resp == nil || len(resp.Choices) != 1 {
+			log.Printf("❌ [%s] Call failed: %v", tc.name, err)
+			passed = false
+			continue
+		}
+
+		calls := resp.Choices[0].ToolCalls
+		if len(calls) != 2 || calls[0].FunctionCall.Arguments != `{"n": 0}` || calls[1].FunctionCall.Arguments != `{"n": 1}` {
+			log.Printf("❌ [%s] Expected the first 2 tool calls, got %d", tc.name, len(calls))
+			passed = false
+		} else {
+			log.Printf("✅ [%s] Response keeps the first 2 of 10 tool calls", tc.name)
+		}
+
+		streamed := 0
+		for chunk := range streamChan {
+			if chunk.Type == llmtypes.StreamChunkTypeToolCall {
+				streamed++
+			}
+		}
+		if streamed != 2 {
+			log.Printf("❌ [%s] Streamed %d tool calls, expected 2", tc.name, streamed)
+			passed = false
+		} else {
+			log.Printf("✅ [%s] Streamed 2 tool calls", tc.name)
+		}
+
+		if len(emitter.ToolCallsTruncatedEvents) != 1 || emitter.ToolCallsTruncatedEvents[0]["received_tool_calls"] != 10 {
+			log.Printf("❌ [%s] Expected one truncation event for 10 tool calls, got %v", tc.name, emitter.ToolCallsTruncatedEvents)
+			passed = false
+		} else {
+			log.Printf("✅ [%s] Truncation event emitted", tc.name)
+		}
+	}
+	return passed
+}
@@ -0,0 +1,110 @@
+package shared
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"os"
+	"strings"
+
+	llmproviders "github.com/manishiitg/multi-llm-provider-go"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+
+	"github.com/spf13/cobra"
+)
+
+// MessageTransformTestCmd checks WithMessageTransform and Config.MessageTransforms
+var MessageTransformTestCmd = &cobra.Command{
+	Use:   "message-transform",
+	Short: "Test that message transforms rewrite the messages sent to the provider",
+	Long: `This test dry-runs OpenAI calls and checks that:
+- WithMessageTransform rewrites the messages sent, e.g. to redact them
+- Config.MessageTransforms run first, then the call's transforms in the order they were added
+- transforms see the messages after the default system prompt is applied
+- the caller's messages are unchanged
+
+No API calls are made.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !RunMessageTransformTest() {
+			os.Exit(1)
+		}
+	},
+}
+
+// RunMessageTransformTest verifies the messages sent with message transforms
+func RunMessageTransformTest() bool {
+	log.Printf("\n🔀 Test: Message Transform")
+
+	apiKey := "dry-run"
+	messages := []llmtypes.MessageContent{llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "My password is hunter2")}
+	// suffix returns a transform appending text to the last message, without modifying it
+	suffix := func(text string) llmtypes.MessageTransform {
+		return func(messages []llmtypes.MessageContent) []llmtypes.MessageContent {
+			last := messages[len(messages)-1]
+			messages[len(messages)-1] = llmtypes.MessageContent{Role: last.Role, Parts: append(append([]llmtypes.ContentPart{}, last.Parts...), llmtypes.TextContent{Text: text})}
+			return messages
+		}
+	}
+	redact := func(messages []llmtypes.MessageContent) []llmtypes.MessageContent {
+		for i, msg := range messages {
+			parts := make([]llmtypes.ContentPart, len(msg.Parts))
+			for j, part := range msg.Parts {
+				if text, ok := part.(llmtypes.TextContent); ok {
+					part = llmtypes.TextContent{Text: strings.ReplaceAll(text.Text, "hunter2", "[redacted]")}
+				}
+				parts[j] = part
+			}
+			messages[i] = llmtypes.MessageContent{Role: msg.Role, Parts: parts}
+		}
+		return messages
+	}
+	dryRun := func(config llmproviders.Config, options ...llmtypes.CallOption) string {
+		config.Provider = llmproviders.ProviderOpenAI
+		config.ModelID = "gpt-4.1"
+		config.APIKeys = &llmproviders.ProviderAPIKeys{OpenAI: &apiKey}
+		llm, err := llmproviders.InitializeLLM(config)
+		if err != nil {
+			log.Printf("❌ Initialization failed: %v", err)
+			return ""
+		}
+		resp, err := llm.GenerateContent(context.Background(), messages, append(options, llmtypes.WithDryRun())...)
+		if err != nil || resp == nil || !resp.DryRun {
+			log.Printf("❌ Dry run failed: %v", err)
+			return ""
+		}
+		request, _ := json.Marshal(resp.Raw)
+		return string(request)
+	}
+
+	passed := true
+	for _, c := range []struct {
+		name     string
+		config   llmproviders.Config
+		options  []llmtypes.CallOption
+		want     string
+		unwanted string
+	}{
+		{"redacting transform", llmproviders.Config{}, []llmtypes.CallOption{llmproviders.WithMessageTransform(redact)}, "My password is [redacted]", "hunter2"},
+		{"ordered transforms", llmproviders.Config{MessageTransforms: []llmproviders.MessageTransform{suffix(" (config)")}},
+			[]llmtypes.CallOption{llmproviders.WithMessageTransform(suffix(" (first)")), llmproviders.WithMessageTransform(suffix(" (second)"))},
+			`hunter2\n (config)\n (first)\n (second)`, ""},
+		{"default system prompt", llmproviders.Config{DefaultSystemPrompt: "The admin password is hunter2."}, []llmtypes.CallOption{llmproviders.WithMessageTransform(redact)},
+			"The admin password is [redacted].", "hunter2"},
+	} {
+		request := dryRun(c.config, c.options...)
+		if !strings.Contains(request, c.want) || (c.unwanted != "" && strings.Contains(request, c.unwanted)) {
+			log.Printf("❌ %s: expected %s in the request, got %s", c.name, c.want, request)
+			passed = false
+			continue
+		}
+		log.Printf("✅ %s sends %s", c.name, c.want)
+	}
+
+	if len(messages) != 1 || len(messages[0].Parts) != 1 || messages[0].Parts[0].(llmtypes.TextContent).Text != "My password is hunter2" {
+		log.Printf("❌ The caller's messages were modified: %+v", messages)
+		passed = false
+	} else {
+		log.Printf("✅ The caller's messages are unchanged")
+	}
+	return passed
+}
@@ -0,0 +1,103 @@
+package shared
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"os"
+	"strings"
+
+	llmproviders "github.com/manishiitg/multi-llm-provider-go"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+
+	"github.com/spf13/cobra"
+)
+
+// ModelAliasesTestCmd checks the resolution of Config.ModelAliases
+var ModelAliasesTestCmd = &cobra.Command{
+	Use:   "model-aliases",
+	Short: "Test that logical model aliases resolve to provider and model",
+	Long: `This test checks that Config.ModelAliases:
+- resolves an alias in Config.ModelID to its provider and model (dry run)
+- resolves aliases passed to WithModel (dry run)
+- rejects unknown aliases, invalid aliases and aliases of another provider
+
+No API calls are made.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !RunModelAliasesTest() {
+			os.Exit(1)
+		}
+	},
+}
+
+// RunModelAliasesTest verifies alias resolution at initialization and per call
+func RunModelAliasesTest() bool {
+	log.Printf("\n🏷️ Test: Model Aliases")
+
+	apiKey := "dry-run"
+	aliases := map[string]llmproviders.ModelAlias{
+		"fast":   {Provider: llmproviders.ProviderOpenAI, ModelID: "gpt-4.1-mini"},
+		"smart":  {Provider: llmproviders.ProviderOpenAI, ModelID: "gpt-4.1"},
+		"vision": {Provider: llmproviders.ProviderVertex, ModelID: "gemini-2.5-flash"},
+	}
+	keys := &llmproviders.ProviderAPIKeys{OpenAI: &apiKey, Vertex: &apiKey}
+	messages := []llmtypes.MessageContent{llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "Hi")}
+
+	// requestModel returns the model sent in a dry run of llm
+	requestModel := func(llm llmtypes.Model, options ...llmtypes.CallOption) string {
+		resp, err := llm.GenerateContent(context.Background(), messages, append(options, llmtypes.WithDryRun())...)
+		if err != nil || resp == nil || !resp.DryRun {
+			log.Printf("❌ Dry run failed: %v", err)
+			return ""
+		}
+		request, _ := json.Marshal(resp.Raw)
+		var body struct {
+			Model string `json:"model"`
+		}
+		_ = json.Unmarshal(request, &body)
+		return body.Model
+	}
+
+	passed := true
+	llm, err := llmproviders.InitializeLLM(llmproviders.Config{ModelID: "smart", APIKeys: keys, ModelAliases: aliases})
+	if err != nil {
+		log.Printf("❌ Initializing with the alias \"smart\" failed: %v", err)
+		return false
+	}
+	if provider, ok := llm.(*llmproviders.ProviderAwareLLM); !ok || provider.GetProvider() != llmproviders.ProviderOpenAI || provider.GetModelID() != "gpt-4.1" {
+		log.Printf("❌ The alias \"smart\" should resolve to openai gpt-4.1, got %T %s", llm, llm.GetModelID())
+		passed = false
+	} else if model := requestModel(llm); model != "gpt-4.1" {
+		log.Printf("❌ The alias \"smart\" should request gpt-4.1, got %q", model)
+		passed = false
+	} else {
+		log.Printf("✅ Config.ModelID \"smart\" resolves to openai gpt-4.1")
+	}
+	if model := requestModel(llm, llmtypes.WithModel("fast")); model != "gpt-4.1-mini" {
+		log.Printf("❌ WithModel(\"fast\") should request gpt-4.1-mini, got %q", model)
+		passed = false
+	} else {
+		log.Printf("✅ WithModel(\"fast\") requests gpt-4.1-mini")
+	}
+	if _, err := llm.GenerateContent(context.Background(), messages, llmtypes.WithModel("vision"), llmtypes.WithDryRun()); err == nil || !strings.Contains(err.Error(), "vision") {
+		log.Printf("❌ WithModel with an alias of another provider should fail, got %v", err)
+		passed = false
+	} else {
+		log.Printf("✅ WithModel with an alias of another provider fails: %v", err)
+	}
+
+	for name, config := range map[string]llmproviders.Config{
+		"an unknown alias":                     {ModelID: "cheap", APIKeys: keys, ModelAliases: aliases},
+		"an alias of another provider":         {Provider: llmproviders.ProviderVertex, ModelID: "smart", APIKeys: keys, ModelAliases: aliases},
+		"an alias with an invalid provider":    {ModelID: "fast", APIKeys: keys, ModelAliases: map[string]llmproviders.ModelAlias{"fast": {Provider: "acme", ModelID: "m"}}},
+		"a fallback alias of another provider": {ModelID: "smart", FallbackModels: []string{"vision"}, APIKeys: keys, ModelAliases: aliases},
+	} {
+		if _, err := llmproviders.InitializeLLM(config); err == nil {
+			log.Printf("❌ Initializing with %s should fail", name)
+			passed = false
+		} else {
+			log.Printf("✅ Initializing with %s fails: %v", name, err)
+		}
+	}
+	return passed
+}
@@ -0,0 +1,127 @@
+package shared
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"sync/atomic"
+
+	llmproviders "github.com/manishiitg/multi-llm-provider-go"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+
+	"github.com/aws/smithy-go"
+	"github.com/spf13/cobra"
+)
+
+// ModelNotFoundTestCmd checks that unknown models fail with ErrModelNotFound
+var ModelNotFoundTestCmd = &cobra.Command{
+	Use:   "model-not-found",
+	Short: "Test the typed ErrModelNotFound error for unknown models",
+	Long: `This test checks, against a local fake provider server, that:
+- the model-not-found errors of OpenAI, OpenRouter, Anthropic, Gemini and Bedrock are
+  returned as ErrModelNotFound naming the provider and model
+- a model that is not found is not retried, but the call moves on to its fallback models
+
+No API calls are made.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !RunModelNotFoundTest() {
+			os.Exit(1)
+		}
+	},
+}
+
+// RunModelNotFoundTest verifies how model-not-found errors are typed, retried and fall back
+func RunModelNotFoundTest() bool {
+	log.Printf("\n🔎 Test: Model Not Found")
+
+	var requests atomic.Int32
+	var status int
+	var body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		data, _ := io.ReadAll(r.Body)
+		var request struct {
+			Model string `json:"model"`
+		}
+		_ = json.Unmarshal(data, &request)
+		w.Header().Set("Content-Type", "application/json")
+		if request.Model == "gpt-4.1" {
+			fmt.Fprint(w, `{"id":"chatcmpl-1","object":"chat.completion","created":1,"model":"gpt-4.1","choices":[{"index":0,"message":{"role":"assistant","content":"Hello"},"finish_reason":"stop"}]}`)
+			return
+		}
+		w.WriteHeader(status)
+		fmt.Fprint(w, body)
+	}))
+	defer server.Close()
+	target, _ := url.Parse(server.URL)
+
+	apiKey := "test"
+	messages := []llmtypes.MessageContent{llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "Hi")}
+	call := func(config llmproviders.Config, options ...llmtypes.CallOption) error {
+		requests.Store(0)
+		config.HTTPClient = &http.Client{Transport: redirectTransport{target: target}}
+		llm, err := llmproviders.InitializeLLM(config)
+		if err != nil {
+			return err
+		}
+		_, err = llm.GenerateContent(context.Background(), messages, options...)
+		return err
+	}
+
+	passed := true
+	check := func(name string, err error, provider llmproviders.Provider, modelID string) {
+		var notFound *llmproviders.ErrModelNotFound
+		var providerErr *llmproviders.ProviderError
+		if !errors.As(err, &notFound) || notFound.Provider != provider || notFound.ModelID != modelID || !errors.As(err, &providerErr) || providerErr.Retryable {
+			log.Printf("❌ %s: expected a non-retryable ErrModelNotFound for %s %s, got %v", name, provider, modelID, err)
+			passed = false
+			return
+		}
+		log.Printf("✅ %s: %v", name, err)
+	}
+
+	openAI := llmproviders.Config{Provider: llmproviders.ProviderOpenAI, ModelID: "gpt-4.1-typo", APIKeys: &llmproviders.ProviderAPIKeys{OpenAI: &apiKey}}
+	status, body = http.StatusNotFound, `{"error":{"message":"The model 'gpt-4.1-typo' does not exist or you do not have access to it.","type":"invalid_request_error","param":null,"code":"model_not_found"}}`
+	check("OpenAI", call(openAI, llmtypes.WithMaxRetries(2)), llmproviders.ProviderOpenAI, "gpt-4.1-typo")
+	if requests.Load() != 1 {
+		log.Printf("❌ OpenAI: expected no retry of a model that is not found, got %d requests", requests.Load())
+		passed = false
+	}
+
+	if err := call(openAI, llmtypes.WithFallbackModels([]string{"gpt-4.1"})); err != nil || requests.Load() != 2 {
+		log.Printf("❌ Fallback: expected the fallback model to answer after 2 requests, got %d requests, err %v", requests.Load(), err)
+		passed = false
+	} else {
+		log.Printf("✅ A model that is not found falls back to the next model")
+	}
+
+	check("WithModel", call(openAI, llmtypes.WithModel("gpt-5-typo")), llmproviders.ProviderOpenAI, "gpt-5-typo")
+
+	status, body = http.StatusBadRequest, `{"error":{"message":"openai/gpt-4.1-typo is not a valid model ID","code":400}}`
+	check("OpenRouter", call(llmproviders.Config{Provider: llmproviders.ProviderOpenRouter, ModelID: "openai/gpt-4.1-typo", APIKeys: &llmproviders.ProviderAPIKeys{OpenRouter: &apiKey}}), llmproviders.ProviderOpenRouter, "openai/gpt-4.1-typo")
+
+	status, body = http.StatusNotFound, `{"type":"error","error":{"type":"not_found_error","message":"model: claude-sonnet-9"}}`
+	check("Anthropic", call(llmproviders.Config{Provider: llmproviders.ProviderAnthropic, ModelID: "claude-sonnet-9", APIKeys: &llmproviders.ProviderAPIKeys{Anthropic: &apiKey}}), llmproviders.ProviderAnthropic, "claude-sonnet-9")
+
+	status, body = http.StatusNotFound, `{"error":{"code":404,"message":"models/gemini-9-flash is not found for API version v1beta","status":"NOT_FOUND"}}`
+	check("Gemini", call(llmproviders.Config{Provider: llmproviders.ProviderVertex, ModelID: "gemini-9-flash", APIKeys: &llmproviders.ProviderAPIKeys{Vertex: &apiKey}}), llmproviders.ProviderVertex, "gemini-9-flash")
+
+	bedrockErr := fmt.Errorf("bedrock converse stream: %w", &smithy.GenericAPIError{Code: "ValidationException", Message: "The provided model identifier is invalid."})
+	llm := llmproviders.NewProviderAwareLLM(&errorModel{err: bedrockErr}, llmproviders.ProviderBedrock, "anthropic.claude-9", nil, "model-not-found-test", nil)
+	_, err := llm.GenerateContent(context.Background(), messages)
+	check("Bedrock", err, llmproviders.ProviderBedrock, "anthropic.claude-9")
+
+	status, body = http.StatusBadRequest, `{"error":{"message":"Invalid value for 'temperature'.","type":"invalid_request_error","param":"temperature","code":"invalid_value"}}`
+	if err := call(openAI); llmproviders.IsModelNotFoundError(err) {
+		log.Printf("❌ Other request errors: expected no ErrModelNotFound, got %v", err)
+		passed = false
+	}
+	return passed
+}
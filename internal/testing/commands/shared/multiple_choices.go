@@ -0,0 +1,176 @@
+package shared
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"sync/atomic"
+
+	llmproviders "github.com/manishiitg/multi-llm-provider-go"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+	"github.com/manishiitg/multi-llm-provider-go/pkg/utils"
+
+	"github.com/spf13/cobra"
+)
+
+// MultipleChoicesTestCmd checks that WithN returns n choices, natively or emulated
+var MultipleChoicesTestCmd = &cobra.Command{
+	Use:   "multiple-choices",
+	Short: "Test that WithN returns n choices, natively or with concurrent requests",
+	Long: `This test uses fake models and fake provider endpoints and checks that with WithN(3):
+- OpenAI sends n in one request and returns its 3 choices
+- Anthropic, without native support, makes 3 single-choice requests whose choices are merged
+- emulated requests each ask for one choice, their choices are merged with the usage summed,
+  and streamed chunks carry their ChoiceIndex before the stream is closed
+- a failing request fails the call and cancels the others
+- WithN with WithAbortOnToolCall while streaming, or with WithAutoContinue, is rejected
+
+No API calls are made.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !RunMultipleChoicesTest() {
+			os.Exit(1)
+		}
+	},
+}
+
+// openAIThreeChoicesResponse is an OpenAI chat completion with three choices
+const openAIThreeChoicesResponse = `{"id":"chatcmpl-1","object":"chat.completion","created":1,"model":"gpt-4.1","choices":[` +
+	`{"index":0,"message":{"role":"assistant","content":"Red"},"finish_reason":"stop"},` +
+	`{"index":1,"message":{"role":"assistant","content":"Green"},"finish_reason":"stop"},` +
+	`{"index":2,"message":{"role":"assistant","content":"Blue"},"finish_reason":"stop"}]}`
+
+// choiceContents returns the content of every choice of resp
+func choiceContents(resp *llmtypes.ContentResponse) []string {
+	var contents []string
+	for _, choice := range resp.Choices {
+		contents = append(contents, choice.Content)
+	}
+	return contents
+}
+
+// RunMultipleChoicesTest verifies the choices returned with WithN
+func RunMultipleChoicesTest() bool {
+	log.Printf("\n🎰 Test: Multiple Choices")
+
+	ctx := context.Background()
+	messages := []llmtypes.MessageContent{llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "Name a color")}
+	apiKey := "test"
+	keys := &llmproviders.ProviderAPIKeys{OpenAI: &apiKey, Anthropic: &apiKey}
+	passed := true
+
+	// OpenAI generates the choices natively
+	server := newRecordingServer(openAIThreeChoicesResponse)
+	llm, err := llmproviders.InitializeLLM(llmproviders.Config{Provider: llmproviders.ProviderOpenAI, ModelID: "gpt-4.1", APIKeys: keys, HTTPClient: server.client()})
+	var resp *llmtypes.ContentResponse
+	if err == nil {
+		resp, err = llm.GenerateContent(ctx, messages, llmproviders.WithN(3))
+	}
+	server.Close()
+	if err != nil || !strings.Contains(server.body, `"n":3`) || strings.Join(choiceContents(resp), " ") != "Red Green Blue" {
+		log.Printf("❌ OpenAI: expected n=3 in the request and 3 choices, got %s (error %v)", server.body, err)
+		passed = false
+	} else {
+		log.Printf("✅ OpenAI sends n=3 and returns %v", choiceContents(resp))
+	}
+
+	// Anthropic has no n, so the adapter makes one request per choice
+	var requests, withN atomic.Int32
+	anthropic := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if body, _ := io.ReadAll(r.Body); strings.Contains(string(body), `"n":`) {
+			withN.Add(1)
+		}
+		w.Header().Set("Content-Type", "text/event-stream")
+		fmt.Fprint(w, anthropicHelloResponse)
+	}))
+	target, _ := url.Parse(anthropic.URL)
+	llm, err = llmproviders.InitializeLLM(llmproviders.Config{Provider: llmproviders.ProviderAnthropic, ModelID: "claude-sonnet-4-20250514", APIKeys: keys,
+		HTTPClient: &http.Client{Transport: redirectTransport{target: target}}})
+	if err == nil {
+		resp, err = llm.GenerateContent(ctx, messages, llmproviders.WithN(3))
+	}
+	anthropic.Close()
+	if err != nil || requests.Load() != 3 || withN.Load() != 0 || strings.Join(choiceContents(resp), " ") != "Hello Hello Hello" {
+		log.Printf("❌ Anthropic: expected 3 requests without n and 3 choices, got %d requests (%d with n) (error %v)", requests.Load(), withN.Load(), err)
+		passed = false
+	} else {
+		log.Printf("✅ Anthropic makes %d single-choice requests and returns %v", requests.Load(), choiceContents(resp))
+	}
+
+	// Emulated requests each ask for one choice
+	var calls atomic.Int32
+	model := fakeModelFunc(func(ctx context.Context, messages []llmtypes.MessageContent, opts *llmtypes.CallOptions) (*llmtypes.ContentResponse, error) {
+		if opts.N != 1 {
+			return nil, fmt.Errorf("expected single-choice requests, got n=%d", opts.N)
+		}
+		color := []string{"Red", "Green", "Blue"}[calls.Add(1)-1]
+		resp := streamChoice(opts, &llmtypes.ContentChoice{Content: color + " it is", StopReason: "stop"}, color, " it is")
+		resp.Usage = &llmtypes.Usage{InputTokens: 5, OutputTokens: 3, TotalTokens: 8}
+		return resp, nil
+	})
+	resp, err = utils.GenerateChoicesConcurrently(ctx, model, 3, messages, nil)
+	contents := choiceContents(resp)
+	if err != nil || len(contents) != 3 || resp.Usage == nil || resp.Usage.TotalTokens != 24 {
+		log.Printf("❌ Emulated: expected 3 choices with usage summed to 24 tokens, got %v (error %v)", contents, err)
+		passed = false
+	} else {
+		log.Printf("✅ Emulated: 3 choices %v with usage summed to %d tokens", contents, resp.Usage.TotalTokens)
+	}
+
+	calls.Store(0)
+	streamChan := make(chan llmtypes.StreamChunk, 20)
+	resp, err = utils.GenerateChoicesConcurrently(ctx, model, 3, messages, []llmtypes.CallOption{llmtypes.WithStreamingChan(streamChan)})
+	aggregator := llmtypes.NewStreamAggregator()
+	aggregator.Consume(streamChan)
+	if err != nil || strings.Join(choiceContents(aggregator.Response()), ",") != strings.Join(choiceContents(resp), ",") || !aggregator.Finished() {
+		log.Printf("❌ Emulated streaming: expected the streamed choices to match %v, got %v (error %v)", choiceContents(resp), choiceContents(aggregator.Response()), err)
+		passed = false
+	} else {
+		log.Printf("✅ Emulated streaming: chunks carry their ChoiceIndex and rebuild %v", choiceContents(resp))
+	}
+
+	// One failing request fails the call and cancels the others
+	failure := errors.New("overloaded")
+	var cancelled atomic.Int32
+	calls.Store(0)
+	failing := fakeModelFunc(func(ctx context.Context, messages []llmtypes.MessageContent, opts *llmtypes.CallOptions) (*llmtypes.ContentResponse, error) {
+		if calls.Add(1) == 1 {
+			return nil, failure
+		}
+		<-ctx.Done()
+		cancelled.Add(1)
+		return nil, ctx.Err()
+	})
+	_, err = utils.GenerateChoicesConcurrently(ctx, failing, 3, messages, nil)
+	if !errors.Is(err, failure) || cancelled.Load() != 2 {
+		log.Printf("❌ Failure: expected the first error and 2 cancelled requests, got %v, %d cancelled", err, cancelled.Load())
+		passed = false
+	} else {
+		log.Printf("✅ A failing request fails the call and cancels the other %d", cancelled.Load())
+	}
+
+	// Combinations that cannot be honored for every choice
+	fake := newFakeLLM(model, llmproviders.ProviderAnthropic)
+	for _, c := range []struct {
+		name    string
+		options []llmtypes.CallOption
+	}{
+		{"WithAbortOnToolCall while streaming", []llmtypes.CallOption{llmproviders.WithN(2), llmproviders.WithAbortOnToolCall(), llmtypes.WithStreamingChan(make(chan llmtypes.StreamChunk, 10))}},
+		{"WithAutoContinue", []llmtypes.CallOption{llmproviders.WithN(2), llmproviders.WithAutoContinue(2)}},
+	} {
+		if _, err := fake.GenerateContent(ctx, messages, c.options...); err == nil || !strings.Contains(err.Error(), "WithN(2)") {
+			log.Printf("❌ WithN with %s: expected the call rejected, got %v", c.name, err)
+			passed = false
+		} else {
+			log.Printf("✅ WithN with %s is rejected: %v", c.name, err)
+		}
+	}
+	return passed
+}
@@ -0,0 +1,101 @@
+package shared
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+	openaiadapter "github.com/manishiitg/multi-llm-provider-go/pkg/adapters/openai"
+
+	"github.com/spf13/cobra"
+)
+
+// OpenAIToolResultsTestCmd checks that grouped tool results are split into OpenAI tool messages
+var OpenAIToolResultsTestCmd = &cobra.Command{
+	Use:   "openai-tool-results",
+	Short: "Test that grouped tool results become ordered OpenAI tool messages",
+	Long: `This test converts multi-tool histories to OpenAI messages and checks that:
+- a tool message with several results becomes one tool message per result
+- results are ordered like the assistant's tool calls
+- results grouped in a user message (the Bedrock shape) become tool messages, with the
+  rest of that message following them as a user message
+- results split across several messages end up together, with tool images after them
+
+No API calls are made.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !RunOpenAIToolResultsTest() {
+			os.Exit(1)
+		}
+	},
+}
+
+// RunOpenAIToolResultsTest verifies the OpenAI conversion of grouped tool results
+func RunOpenAIToolResultsTest() bool {
+	log.Printf("\n🧰 Test: OpenAI Tool Results")
+
+	assistant := llmtypes.MessageContent{Role: llmtypes.ChatMessageTypeAI, Parts: []llmtypes.ContentPart{
+		llmtypes.ToolCall{ID: "call_1", Type: "function", FunctionCall: &llmtypes.FunctionCall{Name: "get_weather", Arguments: `{"city":"Paris"}`}},
+		llmtypes.ToolCall{ID: "call_2", Type: "function", FunctionCall: &llmtypes.FunctionCall{Name: "get_time", Arguments: `{"city":"Paris"}`}},
+		llmtypes.ToolCall{ID: "call_3", Type: "function", FunctionCall: &llmtypes.FunctionCall{Name: "screenshot", Arguments: `{}`}},
+	}}
+	weather := llmtypes.ToolCallResponse{ToolCallID: "call_1", Name: "get_weather", Content: "Sunny"}
+	clock := llmtypes.ToolCallResponse{ToolCallID: "call_2", Name: "get_time", Content: "10:00"}
+	screenshot := llmtypes.ToolCallResponse{ToolCallID: "call_3", Name: "screenshot", Parts: []llmtypes.ContentPart{
+		llmtypes.ImageContent{SourceType: "url", Data: "https://example.com/screen.png"},
+	}}
+	history := func(messages ...llmtypes.MessageContent) []llmtypes.MessageContent {
+		return append([]llmtypes.MessageContent{llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "Weather, time and a screenshot of Paris?"), assistant}, messages...)
+	}
+
+	// shape describes each converted message after the assistant one: its role and, for tool
+	// messages, the tool call it answers
+	shape := func(messages []llmtypes.MessageContent) string {
+		var roles []string
+		for _, msg := range openaiadapter.ToOpenAIMessages(messages)[2:] {
+			data, _ := json.Marshal(msg)
+			var fields struct {
+				Role       string `json:"role"`
+				ToolCallID string `json:"tool_call_id"`
+			}
+			_ = json.Unmarshal(data, &fields)
+			if fields.ToolCallID != "" {
+				fields.Role += ":" + fields.ToolCallID
+			}
+			roles = append(roles, fields.Role)
+		}
+		return strings.Join(roles, " ")
+	}
+
+	passed := true
+	for _, c := range []struct {
+		name     string
+		messages []llmtypes.MessageContent
+		want     string
+	}{
+		{"grouped tool message", history(
+			llmtypes.MessageContent{Role: llmtypes.ChatMessageTypeTool, Parts: []llmtypes.ContentPart{weather, clock, screenshot}},
+		), "tool:call_1 tool:call_2 tool:call_3 user"},
+		{"out of order results", history(
+			llmtypes.MessageContent{Role: llmtypes.ChatMessageTypeTool, Parts: []llmtypes.ContentPart{clock, screenshot, weather}},
+		), "tool:call_1 tool:call_2 tool:call_3 user"},
+		{"results in a user message", history(
+			llmtypes.MessageContent{Role: llmtypes.ChatMessageTypeHuman, Parts: []llmtypes.ContentPart{weather, clock, llmtypes.TextContent{Text: "Keep it short."}}},
+		), "tool:call_1 tool:call_2 user"},
+		{"results across messages", history(
+			llmtypes.MessageContent{Role: llmtypes.ChatMessageTypeTool, Parts: []llmtypes.ContentPart{screenshot}},
+			llmtypes.MessageContent{Role: llmtypes.ChatMessageTypeTool, Parts: []llmtypes.ContentPart{weather}},
+			llmtypes.MessageContent{Role: llmtypes.ChatMessageTypeTool, Parts: []llmtypes.ContentPart{clock}},
+			llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "Thanks!"),
+		), "tool:call_1 tool:call_2 tool:call_3 user user"},
+	} {
+		if got := shape(c.messages); got != c.want {
+			log.Printf("❌ %s: expected %s, got %s", c.name, c.want, got)
+			passed = false
+			continue
+		}
+		log.Printf("✅ %s: %s", c.name, c.want)
+	}
+	return passed
+}
@@ -0,0 +1,92 @@
+package shared
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+
+	llmproviders "github.com/manishiitg/multi-llm-provider-go"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+
+	"github.com/spf13/cobra"
+)
+
+// OptionValidationTestCmd checks that conflicting options fail before any request
+var OptionValidationTestCmd = &cobra.Command{
+	Use:   "option-validation",
+	Short: "Test that unsupported option combinations fail with ErrUnsupportedOptionCombination",
+	Long: `This test uses a fake model and checks that:
+- WithN(n>1) with WithAbortOnToolCall while streaming, WithN(n>1) with WithAutoContinue and
+  WithCachedContent on a model without context caching fail with ErrUnsupportedOptionCombination
+- the error lists every conflict and the model is not called
+- streaming several choices on its own is still allowed
+
+No API calls are made.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !RunOptionValidationTest() {
+			os.Exit(1)
+		}
+	},
+}
+
+// streamCountingModel is a chunkedContentModel that counts its calls
+type streamCountingModel struct {
+	chunkedContentModel
+	calls int
+}
+
+func (m *streamCountingModel) GenerateContent(ctx context.Context, messages []llmtypes.MessageContent, options ...llmtypes.CallOption) (*llmtypes.ContentResponse, error) {
+	m.calls++
+	return m.chunkedContentModel.GenerateContent(ctx, messages, options...)
+}
+
+// RunOptionValidationTest verifies that conflicting options are refused
+func RunOptionValidationTest() bool {
+	log.Printf("\n🚦 Test: Option Validation")
+
+	messages := []llmtypes.MessageContent{llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "Hello")}
+	generate := func(options ...llmtypes.CallOption) (int, error) {
+		model := &streamCountingModel{chunkedContentModel: chunkedContentModel{pieces: []string{"Hi there"}}}
+		llm := newFakeLLM(model, llmproviders.ProviderAnthropic)
+		streamChan := make(chan llmtypes.StreamChunk, 100)
+		_, err := llm.GenerateContent(context.Background(), messages, append(options, llmtypes.WithStreamingChan(streamChan))...)
+		return model.calls, err
+	}
+
+	passed := true
+	for _, c := range []struct {
+		name      string
+		options   []llmtypes.CallOption
+		conflicts int
+	}{
+		{"n=2 with abort on tool call", []llmtypes.CallOption{llmproviders.WithN(2), llmproviders.WithAbortOnToolCall()}, 1},
+		{"n=2 with auto-continue", []llmtypes.CallOption{llmproviders.WithN(2), llmproviders.WithAutoContinue(2)}, 1},
+		{"cached content without context caching", []llmtypes.CallOption{llmproviders.WithCachedContent("cachedContents/abc")}, 1},
+		{"several conflicts", []llmtypes.CallOption{llmproviders.WithN(3), llmproviders.WithAbortOnToolCall(), llmproviders.WithAutoContinue(1)}, 2},
+	} {
+		calls, err := generate(c.options...)
+		var combinationErr *llmproviders.OptionCombinationError
+		switch {
+		case !errors.Is(err, llmproviders.ErrUnsupportedOptionCombination) || !errors.As(err, &combinationErr):
+			log.Printf("❌ %s: expected ErrUnsupportedOptionCombination, got %v", c.name, err)
+			passed = false
+		case len(combinationErr.Conflicts) != c.conflicts:
+			log.Printf("❌ %s: expected %d conflicts, got %q", c.name, c.conflicts, combinationErr.Conflicts)
+			passed = false
+		case calls != 0:
+			log.Printf("❌ %s: the model was called %d times", c.name, calls)
+			passed = false
+		default:
+			log.Printf("✅ %s refused: %v", c.name, err)
+		}
+	}
+
+	if calls, err := generate(llmproviders.WithN(2)); err != nil || calls == 0 {
+		log.Printf("❌ Streaming two choices should be allowed, got %v after %d calls", err, calls)
+		passed = false
+	} else {
+		log.Printf("✅ Streaming two choices is allowed")
+	}
+	return passed
+}
@@ -0,0 +1,106 @@
+package shared
+
+import (
+	"context"
+	"log"
+	"os"
+
+	llmproviders "github.com/manishiitg/multi-llm-provider-go"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+
+	"github.com/spf13/cobra"
+)
+
+// ParsedJSONTestCmd checks that JSON answers are parsed into ContentResponse.ParsedJSON
+var ParsedJSONTestCmd = &cobra.Command{
+	Use:   "parsed-json",
+	Short: "Test that JSON answers and forced tool arguments are parsed into ParsedJSON",
+	Long: `This test uses fake models to check that:
+- with JSON mode, a fenced JSON answer is parsed into ParsedJSON with JSONValid set
+- an answer without valid JSON leaves ParsedJSON empty and JSONValid unset, without an error
+- the arguments of a forced tool call are parsed into ParsedJSON
+- responses to calls asking for neither are left without ParsedJSON
+
+No API calls are made.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !RunParsedJSONTest() {
+			os.Exit(1)
+		}
+	},
+}
+
+// forcedToolModel answers every call with a single call to the lookup tool
+type forcedToolModel struct {
+	fakeModelID
+	arguments string
+}
+
+func (m *forcedToolModel) GenerateContent(ctx context.Context, messages []llmtypes.MessageContent, options ...llmtypes.CallOption) (*llmtypes.ContentResponse, error) {
+	return &llmtypes.ContentResponse{Choices: []*llmtypes.ContentChoice{{
+		StopReason: "tool_calls",
+		ToolCalls: []llmtypes.ToolCall{{
+			ID:           "call_1",
+			Type:         "function",
+			FunctionCall: &llmtypes.FunctionCall{Name: "lookup", Arguments: m.arguments},
+		}},
+	}}}, nil
+}
+
+// RunParsedJSONTest verifies ContentResponse.ParsedJSON and JSONValid
+func RunParsedJSONTest() bool {
+	log.Printf("\n🧾 Test: Parsed JSON")
+
+	messages := []llmtypes.MessageContent{llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "Which city is the capital of France?")}
+	generate := func(model llmtypes.Model, options ...llmtypes.CallOption) *llmtypes.ContentResponse {
+		llm := newFakeLLM(model, llmproviders.ProviderOpenAI)
+		resp, err := llm.GenerateContent(context.Background(), messages, options...)
+		if err != nil {
+			log.Printf("❌ Call failed: %v", err)
+			return nil
+		}
+		return resp
+	}
+
+	passed := true
+	fenced := &chunkedContentModel{pieces: []string{"Here you go:\n```json\n{\"city\":\"Paris\"}\n```"}}
+	if resp := generate(fenced, llmtypes.WithJSONMode()); resp == nil || !resp.JSONValid || string(resp.ParsedJSON) != `{"city":"Paris"}` {
+		log.Printf("❌ Expected the fenced JSON answer to be parsed, got %+v", resp)
+		passed = false
+	} else {
+		log.Printf("✅ JSON mode answer parsed: %s", resp.ParsedJSON)
+	}
+
+	invalid := &chunkedContentModel{pieces: []string{"The capital of France is Paris."}}
+	if resp := generate(invalid, llmtypes.WithJSONMode()); resp == nil || resp.JSONValid || resp.ParsedJSON != nil {
+		log.Printf("❌ Expected no ParsedJSON for an answer without JSON, got %+v", resp)
+		passed = false
+	} else {
+		log.Printf("✅ Answer without JSON left ParsedJSON empty and JSONValid unset")
+	}
+
+	tools := []llmtypes.Tool{{Type: "function", Function: &llmtypes.FunctionDefinition{Name: "lookup", Description: "Looks up a city"}}}
+	forced := &llmtypes.ToolChoice{Type: "function", Function: &llmtypes.FunctionName{Name: "lookup"}}
+	resp := generate(&forcedToolModel{arguments: `{"city":"Paris"}`}, llmtypes.WithTools(tools), llmtypes.WithToolChoice(forced))
+	if resp == nil || !resp.JSONValid || string(resp.ParsedJSON) != `{"city":"Paris"}` {
+		log.Printf("❌ Expected the forced tool arguments to be parsed, got %+v", resp)
+		passed = false
+	} else {
+		log.Printf("✅ Forced tool arguments parsed: %s", resp.ParsedJSON)
+	}
+	resp = generate(&forcedToolModel{arguments: `{"city":`}, llmtypes.WithTools(tools), llmtypes.WithToolChoice(forced))
+	if resp == nil || resp.JSONValid || resp.ParsedJSON != nil {
+		log.Printf("❌ Expected no ParsedJSON for truncated tool arguments, got %+v", resp)
+		passed = false
+	} else {
+		log.Printf("✅ Truncated tool arguments left JSONValid unset")
+	}
+
+	plain := &chunkedContentModel{pieces: []string{`{"city":"Paris"}`}}
+	if resp := generate(plain); resp == nil || resp.JSONValid || resp.ParsedJSON != nil {
+		log.Printf("❌ Expected no ParsedJSON without JSON options, got %+v", resp)
+		passed = false
+	} else {
+		log.Printf("✅ Answer left unparsed when no JSON was requested")
+	}
+	return passed
+}
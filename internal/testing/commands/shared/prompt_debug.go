@@ -0,0 +1,113 @@
+package shared
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	llmproviders "github.com/manishiitg/multi-llm-provider-go"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+
+	"github.com/spf13/cobra"
+)
+
+// PromptDebugTestCmd checks the debug rendering of the final prompt
+var PromptDebugTestCmd = &cobra.Command{
+	Use:   "prompt-debug",
+	Short: "Test the debug-level rendering of the prompt sent to the provider",
+	Long: `This test sends a conversation with text, an image, a tool call and a tool result
+through ProviderAwareLLM and checks the FINAL PROMPT debug log:
+- every message is rendered with its role, name and parts in order
+- tool calls show their arguments and tool results their content and error flag
+- images are placeholders with their size instead of base64, long text is truncated
+
+No API calls are made.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !RunPromptDebugTest() {
+			os.Exit(1)
+		}
+	},
+}
+
+// captureLogger is a logger that keeps debug messages, and info and error messages in all
+type captureLogger struct {
+	debug []string
+	all   []string
+}
+
+func (l *captureLogger) Infof(format string, v ...any) {
+	l.all = append(l.all, fmt.Sprintf(format, v...))
+}
+func (l *captureLogger) Errorf(format string, v ...any) {
+	l.all = append(l.all, fmt.Sprintf(format, v...))
+}
+func (l *captureLogger) Debugf(format string, args ...interface{}) {
+	l.debug = append(l.debug, fmt.Sprintf(format, args...))
+	l.all = append(l.all, fmt.Sprintf(format, args...))
+}
+
+// RunPromptDebugTest verifies the FINAL PROMPT debug log
+func RunPromptDebugTest() bool {
+	log.Printf("\n📝 Test: Prompt Debug Rendering")
+
+	image := base64.StdEncoding.EncodeToString(make([]byte, 3000))
+	messages := []llmtypes.MessageContent{
+		llmtypes.TextParts(llmtypes.ChatMessageTypeSystem, "You are a weather assistant."),
+		{Role: llmtypes.ChatMessageTypeHuman, Name: "alice", Parts: []llmtypes.ContentPart{
+			llmtypes.TextContent{Text: "What is the weather where this photo was taken? " + strings.Repeat("x", 600)},
+			llmtypes.ImageContent{SourceType: "base64", MediaType: "image/png", Data: image},
+		}},
+		{Role: llmtypes.ChatMessageTypeAI, Parts: []llmtypes.ContentPart{llmtypes.ToolCall{
+			ID: "call_1", Type: "function", FunctionCall: &llmtypes.FunctionCall{Name: "get_weather", Arguments: `{"city":"Paris"}`},
+		}}},
+		{Role: llmtypes.ChatMessageTypeTool, Parts: []llmtypes.ContentPart{llmtypes.ToolCallResponse{
+			ToolCallID: "call_1", Name: "get_weather", Content: "service unavailable", IsError: true,
+		}}},
+	}
+
+	logger := &captureLogger{}
+	llm := llmproviders.NewProviderAwareLLM(&conformingModel{}, llmproviders.ProviderOpenAI, "fake-model", nil, "trace", logger)
+	if _, err := llm.GenerateContent(context.Background(), messages); err != nil {
+		log.Printf("❌ Call failed: %v", err)
+		return false
+	}
+	var prompt string
+	for _, message := range logger.debug {
+		if strings.HasPrefix(message, "📝 FINAL PROMPT") {
+			prompt = message
+		}
+	}
+	if prompt == "" {
+		log.Printf("❌ No FINAL PROMPT debug log")
+		return false
+	}
+
+	passed := true
+	for _, want := range []string{
+		"FINAL PROMPT (4 messages)",
+		`[1] system: "You are a weather assistant."`,
+		`[2] human (alice): "What is the weather where this photo was taken? xxx`,
+		"... [148 more chars]",
+		"| [image image/png, 3000 bytes]",
+		`[3] ai: [tool call call_1] get_weather({"city":"Paris"})`,
+		`[4] tool: [tool error call_1] get_weather: "service unavailable"`,
+	} {
+		if !strings.Contains(prompt, want) {
+			log.Printf("❌ Expected %q in the rendered prompt", want)
+			passed = false
+		}
+	}
+	if strings.Contains(prompt, image[:100]) {
+		log.Printf("❌ The rendered prompt contains base64 image data")
+		passed = false
+	}
+	if !passed {
+		log.Printf("Rendered prompt:\n%s", prompt)
+		return false
+	}
+	log.Printf("✅ Final prompt rendered at debug level:\n%s", prompt)
+	return true
+}
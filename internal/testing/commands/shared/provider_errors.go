@@ -0,0 +1,137 @@
+package shared
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+
+	llmproviders "github.com/manishiitg/multi-llm-provider-go"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+	"github.com/manishiitg/multi-llm-provider-go/pkg/utils"
+
+	"github.com/aws/smithy-go"
+	"github.com/spf13/cobra"
+)
+
+// ProviderErrorsTestCmd checks that provider error bodies are parsed into ProviderError fields
+var ProviderErrorsTestCmd = &cobra.Command{
+	Use:   "provider-errors",
+	Short: "Test parsing provider error bodies into ProviderError Code, Param, Type and Message",
+	Long: `This test checks, against a local fake provider server, that the error bodies of OpenAI,
+OpenRouter (numeric code), Anthropic and Gemini, a Bedrock API error and a Vertex Anthropic
+HTTP error fill ProviderError.Code, .Param, .Type and .Message.
+
+No API calls are made.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !RunProviderErrorsTest() {
+			os.Exit(1)
+		}
+	},
+}
+
+// errorModel is a fake model that fails every call with err
+type errorModel struct {
+	fakeModelID
+	err error
+}
+
+func (m *errorModel) GenerateContent(ctx context.Context, messages []llmtypes.MessageContent, options ...llmtypes.CallOption) (*llmtypes.ContentResponse, error) {
+	return nil, m.err
+}
+
+// RunProviderErrorsTest verifies the error fields parsed for each provider's error shape
+func RunProviderErrorsTest() bool {
+	log.Printf("\n🧾 Test: Provider Errors")
+
+	var status int
+	var body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		fmt.Fprint(w, body)
+	}))
+	defer server.Close()
+	target, _ := url.Parse(server.URL)
+	httpClient := &http.Client{Transport: redirectTransport{target: target}}
+
+	apiKey := "test"
+	messages := []llmtypes.MessageContent{llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "Hi")}
+	call := func(config llmproviders.Config) error {
+		config.HTTPClient = httpClient
+		llm, err := llmproviders.InitializeLLM(config)
+		if err != nil {
+			return err
+		}
+		_, err = llm.GenerateContent(context.Background(), messages)
+		return err
+	}
+	callFake := func(err error) error {
+		llm := llmproviders.NewProviderAwareLLM(&errorModel{err: err}, llmproviders.ProviderBedrock, "fake-model", nil, "provider-errors-test", nil)
+		_, err = llm.GenerateContent(context.Background(), messages)
+		return err
+	}
+
+	passed := true
+	check := func(name string, err error, want llmproviders.ProviderError) {
+		var providerErr *llmproviders.ProviderError
+		if !errors.As(err, &providerErr) {
+			log.Printf("❌ %s: expected a ProviderError, got %v", name, err)
+			passed = false
+			return
+		}
+		if providerErr.StatusCode != want.StatusCode || providerErr.Code != want.Code || providerErr.Param != want.Param || providerErr.Type != want.Type || providerErr.Message != want.Message {
+			log.Printf("❌ %s: expected status %d, code %q, param %q, type %q, message %q, got status %d, code %q, param %q, type %q, message %q", name,
+				want.StatusCode, want.Code, want.Param, want.Type, want.Message,
+				providerErr.StatusCode, providerErr.Code, providerErr.Param, providerErr.Type, providerErr.Message)
+			passed = false
+			return
+		}
+		log.Printf("✅ %s: code %q, param %q, type %q", name, providerErr.Code, providerErr.Param, providerErr.Type)
+	}
+
+	status, body = http.StatusBadRequest, `{"error":{"message":"This model's maximum context length is 128000 tokens.","type":"invalid_request_error","param":"messages","code":"context_length_exceeded"}}`
+	check("OpenAI", call(llmproviders.Config{Provider: llmproviders.ProviderOpenAI, ModelID: "gpt-4.1", APIKeys: &llmproviders.ProviderAPIKeys{OpenAI: &apiKey}}), llmproviders.ProviderError{
+		StatusCode: http.StatusBadRequest, Code: "context_length_exceeded", Param: "messages", Type: "invalid_request_error", Message: "This model's maximum context length is 128000 tokens.",
+	})
+
+	status, body = http.StatusUnauthorized, `{"error":{"message":"No auth credentials found","code":401}}`
+	check("OpenRouter", call(llmproviders.Config{Provider: llmproviders.ProviderOpenRouter, ModelID: "openai/gpt-4.1", APIKeys: &llmproviders.ProviderAPIKeys{OpenRouter: &apiKey}}), llmproviders.ProviderError{
+		StatusCode: http.StatusUnauthorized, Code: "401", Message: "No auth credentials found",
+	})
+
+	status, body = http.StatusBadRequest, `{"type":"error","error":{"type":"invalid_request_error","message":"max_tokens: 100000 > 64000, which is the maximum allowed number of output tokens"}}`
+	check("Anthropic", call(llmproviders.Config{Provider: llmproviders.ProviderAnthropic, ModelID: "claude-sonnet-4-20250514", APIKeys: &llmproviders.ProviderAPIKeys{Anthropic: &apiKey}}), llmproviders.ProviderError{
+		StatusCode: http.StatusBadRequest, Type: "invalid_request_error", Message: "max_tokens: 100000 > 64000, which is the maximum allowed number of output tokens",
+	})
+
+	status, body = http.StatusBadRequest, `{"error":{"code":400,"message":"API key not valid. Please pass a valid API key.","status":"INVALID_ARGUMENT","details":[{"@type":"type.googleapis.com/google.rpc.ErrorInfo","reason":"API_KEY_INVALID","domain":"googleapis.com"}]}}`
+	check("Gemini", call(llmproviders.Config{Provider: llmproviders.ProviderVertex, ModelID: "gemini-2.5-flash", APIKeys: &llmproviders.ProviderAPIKeys{Vertex: &apiKey}}), llmproviders.ProviderError{
+		StatusCode: http.StatusBadRequest, Code: "API_KEY_INVALID", Type: "INVALID_ARGUMENT", Message: "API key not valid. Please pass a valid API key.",
+	})
+
+	status, body = http.StatusBadRequest, `{"error":{"code":400,"message":"Invalid value at 'generation_config.temperature'","status":"INVALID_ARGUMENT","details":[{"@type":"type.googleapis.com/google.rpc.BadRequest","fieldViolations":[{"field":"generation_config.temperature","description":"must be at most 2"}]}]}}`
+	check("Gemini field violation", call(llmproviders.Config{Provider: llmproviders.ProviderVertex, ModelID: "gemini-2.5-flash", APIKeys: &llmproviders.ProviderAPIKeys{Vertex: &apiKey}}), llmproviders.ProviderError{
+		StatusCode: http.StatusBadRequest, Code: "INVALID_ARGUMENT", Param: "generation_config.temperature", Type: "INVALID_ARGUMENT", Message: "Invalid value at 'generation_config.temperature'",
+	})
+
+	bedrockErr := &smithy.GenericAPIError{Code: "ValidationException", Message: "The provided model identifier is invalid."}
+	check("Bedrock", callFake(fmt.Errorf("bedrock converse stream: %w", bedrockErr)), llmproviders.ProviderError{
+		Code: "ValidationException", Message: "The provided model identifier is invalid.",
+	})
+
+	vertexErr := &utils.HTTPError{StatusCode: http.StatusTooManyRequests, Body: `[{"error":{"code":429,"message":"Quota exceeded","status":"RESOURCE_EXHAUSTED"}}]`}
+	check("Vertex Anthropic, Google error list", callFake(vertexErr), llmproviders.ProviderError{
+		StatusCode: http.StatusTooManyRequests, Code: "RESOURCE_EXHAUSTED", Type: "RESOURCE_EXHAUSTED", Message: "Quota exceeded",
+	})
+
+	vertexErr = &utils.HTTPError{StatusCode: http.StatusTooManyRequests, Body: `{"type":"error","error":{"type":"rate_limit_error","message":"Number of request tokens has exceeded your per-minute rate limit"}}`}
+	check("Vertex Anthropic", callFake(vertexErr), llmproviders.ProviderError{
+		StatusCode: http.StatusTooManyRequests, Type: "rate_limit_error", Message: "Number of request tokens has exceeded your per-minute rate limit",
+	})
+	return passed
+}
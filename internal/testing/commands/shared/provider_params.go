@@ -0,0 +1,126 @@
+package shared
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"os"
+	"strings"
+
+	llmproviders "github.com/manishiitg/multi-llm-provider-go"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+
+	"github.com/spf13/cobra"
+)
+
+// ProviderParamsTestCmd checks that typed provider parameters reach the request
+var ProviderParamsTestCmd = &cobra.Command{
+	Use:   "provider-params",
+	Short: "Test that WithOpenAIParams, WithAnthropicParams and WithGeminiParams are sent natively",
+	Long: `This test builds requests with WithDryRun and checks that:
+- WithOpenAIParams, WithAnthropicParams and WithGeminiParams set the provider's native fields
+- they win over WithExtraBody fields of the same name
+- each provider ignores the parameters of the others
+
+No API calls are made.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !RunProviderParamsTest() {
+			os.Exit(1)
+		}
+	},
+}
+
+// RunProviderParamsTest verifies the typed provider parameters of requests built for each provider
+func RunProviderParamsTest() bool {
+	log.Printf("\n🎛️  Test: Provider Parameters")
+
+	apiKey := "dry-run"
+	keys := &llmproviders.ProviderAPIKeys{OpenAI: &apiKey, OpenRouter: &apiKey, Anthropic: &apiKey, Vertex: &apiKey}
+	messages := []llmtypes.MessageContent{llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "Hello")}
+
+	seed, topP, topK, parallel := int64(7), 0.9, int64(40), false
+	geminiTopK, geminiSeed := float32(20), int32(11)
+	options := []llmtypes.CallOption{
+		llmproviders.WithExtraBody(map[string]interface{}{"seed": 1, "top_k": 1}),
+		llmproviders.WithOpenAIParams(llmproviders.OpenAIParams{
+			Seed: &seed, TopP: &topP, ParallelToolCalls: &parallel, Stop: []string{"END"}, User: "user-1",
+		}),
+		llmproviders.WithAnthropicParams(llmproviders.AnthropicParams{
+			TopK: &topK, StopSequences: []string{"END"}, UserID: "user-1",
+		}),
+		llmproviders.WithGeminiParams(llmproviders.GeminiParams{
+			TopK: &geminiTopK, Seed: &geminiSeed, MediaResolution: "MEDIA_RESOLUTION_LOW",
+		}),
+		llmtypes.WithDryRun(),
+	}
+
+	passed := true
+	for _, c := range []struct {
+		provider llmproviders.Provider
+		modelID  string
+		want     []string
+		unwanted []string
+	}{
+		{llmproviders.ProviderOpenAI, "gpt-4.1",
+			[]string{`"seed":7`, `"top_p":0.9`, `"parallel_tool_calls":false`, `"stop":["END"]`, `"user":"user-1"`},
+			[]string{`"top_k"`, `"metadata"`}},
+		{llmproviders.ProviderOpenRouter, "openai/gpt-4.1", []string{`"seed":7`, `"top_p":0.9`}, []string{`"top_k"`}},
+		{llmproviders.ProviderAnthropic, "claude-sonnet-4-20250514",
+			[]string{`"top_k":40`, `"stop_sequences":["END"]`, `"metadata":{"user_id":"user-1"}`},
+			[]string{`"seed"`, `"top_p"`}},
+		{llmproviders.ProviderVertex, "gemini-2.5-flash",
+			[]string{`"topK":20`, `"seed":11`, `"mediaResolution":"MEDIA_RESOLUTION_LOW"`},
+			[]string{`"topP"`, `"stopSequences"`}},
+	} {
+		llm, err := llmproviders.InitializeLLM(llmproviders.Config{Provider: c.provider, ModelID: c.modelID, APIKeys: keys})
+		if err != nil {
+			log.Printf("❌ %s initialization failed: %v", c.provider, err)
+			passed = false
+			continue
+		}
+		resp, err := llm.GenerateContent(context.Background(), messages, options...)
+		if err != nil || resp == nil || !resp.DryRun {
+			log.Printf("❌ %s dry run failed: %v", c.provider, err)
+			passed = false
+			continue
+		}
+		raw, _ := json.Marshal(resp.Raw)
+		request := string(raw)
+		ok := true
+		for _, field := range c.want {
+			if !strings.Contains(request, field) {
+				log.Printf("❌ %s %s request is missing %s: %s", c.provider, c.modelID, field, request)
+				ok = false
+			}
+		}
+		for _, field := range c.unwanted {
+			if strings.Contains(request, field) {
+				log.Printf("❌ %s %s request should not contain %s: %s", c.provider, c.modelID, field, request)
+				ok = false
+			}
+		}
+		if ok {
+			log.Printf("✅ %s %s: native parameters sent: %s", c.provider, c.modelID, strings.Join(c.want, " "))
+		}
+		passed = passed && ok
+	}
+
+	// On the wire, the typed seed wins over the WithExtraBody one and other extra fields are kept
+	server := newRecordingServer(openAIHelloResponse)
+	defer server.Close()
+	llm, err := llmproviders.InitializeLLM(llmproviders.Config{
+		Provider:   llmproviders.ProviderOpenAI,
+		ModelID:    "gpt-4.1",
+		APIKeys:    keys,
+		HTTPClient: server.client(),
+	})
+	if err == nil {
+		_, err = llm.GenerateContent(context.Background(), messages, options[:len(options)-1]...)
+	}
+	if body := server.body; err != nil || !strings.Contains(body, `"seed":7`) || strings.Contains(body, `"seed":1`) || !strings.Contains(body, `"top_k":1`) {
+		log.Printf("❌ Expected the typed seed to win over WithExtraBody, got %s (error %v)", server.body, err)
+		return false
+	}
+	log.Printf("✅ Typed parameters win over WithExtraBody fields of the same name")
+	return passed
+}
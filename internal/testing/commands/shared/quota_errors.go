@@ -0,0 +1,121 @@
+package shared
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"sync/atomic"
+
+	llmproviders "github.com/manishiitg/multi-llm-provider-go"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+
+	openaisdk "github.com/openai/openai-go/v3"
+	"github.com/spf13/cobra"
+)
+
+// QuotaErrorsTestCmd checks that quota and billing errors are told apart from rate limits
+var QuotaErrorsTestCmd = &cobra.Command{
+	Use:   "quota-errors",
+	Short: "Test that quota/billing errors are not retried like rate limits",
+	Long: `This test checks, against a local fake provider server, that:
+- an OpenAI 429 insufficient_quota error is returned at once as ErrQuotaExceeded, without SDK
+  retries or fallback models, and still unwraps to the OpenAI SDK error
+- an OpenAI 429 rate limit is retried and falls back as before
+- Anthropic low credit balance and OpenRouter 402 errors match ErrQuotaExceeded
+
+No API calls are made.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !RunQuotaErrorsTest() {
+			os.Exit(1)
+		}
+	},
+}
+
+// redirectTransport sends every request to target, e.g. a local fake provider server
+type redirectTransport struct {
+	target *url.URL
+}
+
+func (t redirectTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	req = req.Clone(req.Context())
+	req.URL.Scheme = t.target.Scheme
+	req.URL.Host = t.target.Host
+	return http.DefaultTransport.RoundTrip(req)
+}
+
+// RunQuotaErrorsTest verifies how quota errors and rate limits are classified and retried
+func RunQuotaErrorsTest() bool {
+	log.Printf("\n💳 Test: Quota Errors")
+
+	var requests atomic.Int32
+	var status int
+	var body string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("retry-after-ms", "10")
+		w.WriteHeader(status)
+		fmt.Fprint(w, body)
+	}))
+	defer server.Close()
+	target, _ := url.Parse(server.URL)
+	httpClient := &http.Client{Transport: redirectTransport{target: target}}
+
+	messages := []llmtypes.MessageContent{llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "Hi")}
+	call := func(provider llmproviders.Provider, modelID string, keys *llmproviders.ProviderAPIKeys, options ...llmtypes.CallOption) error {
+		requests.Store(0)
+		llm, err := llmproviders.InitializeLLM(llmproviders.Config{Provider: provider, ModelID: modelID, APIKeys: keys, HTTPClient: httpClient})
+		if err != nil {
+			return err
+		}
+		_, err = llm.GenerateContent(context.Background(), messages, options...)
+		return err
+	}
+
+	passed := true
+	apiKey := "test"
+	openaiKeys := &llmproviders.ProviderAPIKeys{OpenAI: &apiKey}
+	fallback := llmtypes.WithFallbackModels([]string{"gpt-4.1-mini"})
+
+	status, body = http.StatusTooManyRequests, `{"error":{"message":"You exceeded your current quota, please check your plan and billing details.","type":"insufficient_quota","code":"insufficient_quota"}}`
+	err := call(llmproviders.ProviderOpenAI, "gpt-4.1", openaiKeys, fallback)
+	var providerErr *llmproviders.ProviderError
+	var sdkErr *openaisdk.Error
+	if !errors.Is(err, llmproviders.ErrQuotaExceeded) || !errors.As(err, &providerErr) || providerErr.Retryable || !errors.As(err, &sdkErr) || requests.Load() != 1 {
+		log.Printf("❌ OpenAI insufficient_quota: expected one request and a non-retryable ErrQuotaExceeded wrapping the SDK error, got %d requests, err %v", requests.Load(), err)
+		passed = false
+	} else {
+		log.Printf("✅ OpenAI insufficient_quota fails after 1 request as ErrQuotaExceeded (status %d)", providerErr.StatusCode)
+	}
+
+	status, body = http.StatusTooManyRequests, `{"error":{"message":"Rate limit reached for gpt-4.1 on tokens per min.","type":"requests","code":"rate_limit_exceeded"}}`
+	err = call(llmproviders.ProviderOpenAI, "gpt-4.1", openaiKeys, fallback)
+	if errors.Is(err, llmproviders.ErrQuotaExceeded) || !errors.As(err, &providerErr) || !providerErr.Retryable || requests.Load() != 6 {
+		log.Printf("❌ OpenAI rate limit: expected 3 attempts on each of 2 models and a retryable error, got %d requests, err %v", requests.Load(), err)
+		passed = false
+	} else {
+		log.Printf("✅ OpenAI rate limit is retried and falls back (%d requests)", requests.Load())
+	}
+
+	status, body = http.StatusBadRequest, `{"type":"error","error":{"type":"invalid_request_error","message":"Your credit balance is too low to access the Anthropic API. Please go to Plans & Billing to upgrade or purchase credits."}}`
+	if err = call(llmproviders.ProviderAnthropic, "claude-sonnet-4-20250514", &llmproviders.ProviderAPIKeys{Anthropic: &apiKey}); !errors.Is(err, llmproviders.ErrQuotaExceeded) || llmproviders.IsRetryableError(err) {
+		log.Printf("❌ Anthropic low credit balance: expected ErrQuotaExceeded, got %v", err)
+		passed = false
+	} else {
+		log.Printf("✅ Anthropic low credit balance is ErrQuotaExceeded")
+	}
+
+	status, body = http.StatusPaymentRequired, `{"error":{"message":"This request requires more credits.","code":402}}`
+	if err = call(llmproviders.ProviderOpenRouter, "openai/gpt-4.1", &llmproviders.ProviderAPIKeys{OpenRouter: &apiKey}); !errors.Is(err, llmproviders.ErrQuotaExceeded) || requests.Load() != 1 {
+		log.Printf("❌ OpenRouter 402: expected one request and ErrQuotaExceeded, got %d requests, err %v", requests.Load(), err)
+		passed = false
+	} else {
+		log.Printf("✅ OpenRouter 402 is ErrQuotaExceeded")
+	}
+	return passed
+}
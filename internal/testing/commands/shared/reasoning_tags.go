@@ -0,0 +1,118 @@
+package shared
+
+import (
+	"context"
+	"log"
+	"os"
+	"strings"
+
+	llmproviders "github.com/manishiitg/multi-llm-provider-go"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+
+	"github.com/spf13/cobra"
+)
+
+// ReasoningTagsTestCmd checks that WithStripReasoningTags removes leaked reasoning
+var ReasoningTagsTestCmd = &cobra.Command{
+	Use:   "reasoning-tags",
+	Short: "Test that WithStripReasoningTags removes <thinking> spans from content and streams",
+	Long: `This test uses a fake model that writes <thinking> spans into its answer, split across
+stream chunks, and checks that WithStripReasoningTags:
+- removes them from Content and from the streamed content, which stay equal
+- keeps them as reasoning blocks, and streams them as reasoning chunks only when reasoning is visible
+- only removes spans before the JSON in JSON mode, leaving tags inside JSON values intact
+- changes nothing without the option
+
+No API calls are made.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !RunReasoningTagsTest() {
+			os.Exit(1)
+		}
+	},
+}
+
+// chunkedContentModel is a fake model that answers with pieces, streamed one chunk each
+type chunkedContentModel struct {
+	fakeModelID
+	pieces []string
+}
+
+func (m *chunkedContentModel) GenerateContent(ctx context.Context, messages []llmtypes.MessageContent, options ...llmtypes.CallOption) (*llmtypes.ContentResponse, error) {
+	choice := &llmtypes.ContentChoice{Content: strings.Join(m.pieces, ""), StopReason: "stop"}
+	return streamChoice(callOptions(options), choice, m.pieces...), nil
+}
+
+// RunReasoningTagsTest verifies WithStripReasoningTags
+func RunReasoningTagsTest() bool {
+	log.Printf("\n💭 Test: Strip Reasoning Tags")
+
+	messages := []llmtypes.MessageContent{llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "What is six times seven?")}
+	answer := []string{"<thi", "nking>Six times seven", " is 42.</thin", "king>\n\n", "The answer", " is 42."}
+	generate := func(pieces []string, options ...llmtypes.CallOption) (*llmtypes.ContentResponse, string, string, error) {
+		llm := newFakeLLM(&chunkedContentModel{pieces: pieces}, llmproviders.ProviderOpenAI)
+		streamChan := make(chan llmtypes.StreamChunk, 100)
+		done := make(chan [2]string)
+		go func() {
+			var content, reasoning strings.Builder
+			for chunk := range streamChan {
+				switch chunk.Type {
+				case llmtypes.StreamChunkTypeContent:
+					content.WriteString(chunk.Content)
+				case llmtypes.StreamChunkTypeReasoning:
+					reasoning.WriteString(chunk.Content)
+				}
+			}
+			done <- [2]string{content.String(), reasoning.String()}
+		}()
+		resp, err := llm.GenerateContent(context.Background(), messages, append(options, llmtypes.WithStreamingChan(streamChan))...)
+		streamed := <-done
+		return resp, streamed[0], streamed[1], err
+	}
+
+	passed := true
+
+	// Hidden reasoning: removed from content and stream, kept as a reasoning block
+	resp, content, reasoning, err := generate(answer, llmproviders.WithStripReasoningTags(nil))
+	switch {
+	case err != nil:
+		log.Printf("❌ Call failed: %v", err)
+		passed = false
+	case resp.Choices[0].Content != "The answer is 42." || content != resp.Choices[0].Content || reasoning != "":
+		log.Printf("❌ Expected the answer without reasoning, got content %q, streamed %q, reasoning chunks %q", resp.Choices[0].Content, content, reasoning)
+		passed = false
+	case len(resp.Choices[0].Blocks) != 2 || resp.Choices[0].Blocks[0].Type != llmtypes.ContentBlockTypeReasoning || resp.Choices[0].Blocks[0].Text != "Six times seven is 42.":
+		log.Printf("❌ Expected a reasoning block followed by the answer, got %+v", resp.Choices[0].Blocks)
+		passed = false
+	default:
+		log.Printf("✅ Reasoning removed from content and stream, kept as a reasoning block")
+	}
+
+	// Visible reasoning is streamed as reasoning chunks
+	_, content, reasoning, err = generate(answer, llmproviders.WithStripReasoningTags([]string{"thinking"}), llmproviders.WithReasoningVisibility("visible"))
+	if err != nil || content != "The answer is 42." || reasoning != "Six times seven is 42." {
+		log.Printf("❌ Expected reasoning chunks with visible reasoning, got content %q, reasoning %q, error %v", content, reasoning, err)
+		passed = false
+	} else {
+		log.Printf("✅ Visible reasoning streamed as reasoning chunks")
+	}
+
+	// JSON mode: leading reasoning is removed, tags inside the JSON are kept
+	jsonAnswer := []string{"<think>Plan the JSON</think>\n", `{"note":"<think>literal</think>"}`}
+	resp, content, _, err = generate(jsonAnswer, llmproviders.WithStripReasoningTags(nil), llmproviders.WithJSONMode())
+	if err != nil || resp.Choices[0].Content != `{"note":"<think>literal</think>"}` || content != resp.Choices[0].Content {
+		log.Printf("❌ Expected the JSON intact in JSON mode, got %v, streamed %q, error %v", resp, content, err)
+		passed = false
+	} else {
+		log.Printf("✅ JSON mode: leading reasoning removed, JSON values intact: %s", resp.Choices[0].Content)
+	}
+
+	// Without the option the content is unchanged
+	resp, content, _, err = generate(answer)
+	if err != nil || resp.Choices[0].Content != strings.Join(answer, "") || content != resp.Choices[0].Content {
+		log.Printf("❌ Expected unchanged content without the option, got %q, error %v", content, err)
+		passed = false
+	} else {
+		log.Printf("✅ Content unchanged without WithStripReasoningTags")
+	}
+	return passed
+}
@@ -0,0 +1,152 @@
+package shared
+
+import (
+	"context"
+	"log"
+	"os"
+	"strings"
+
+	llmproviders "github.com/manishiitg/multi-llm-provider-go"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+	"github.com/manishiitg/multi-llm-provider-go/pkg/redact"
+
+	"github.com/spf13/cobra"
+)
+
+// RedactTestCmd checks that a Redactor masks PII sent to and returned by the provider
+var RedactTestCmd = &cobra.Command{
+	Use:   "redact",
+	Short: "Test that redact.Redactor masks PII sent to and returned by the provider",
+	Long: `This test uses a fake model that records the messages it is sent and checks that with a
+Redactor's Options:
+- emails, SSNs and Luhn-valid card numbers in text, tool call arguments and tool results reach
+  the provider as placeholders
+- numbers failing the Luhn check and images are sent unchanged
+- the call's logs and events only show the redacted messages
+- PII in the response is redacted too, and Unredact restores every original value
+- the same value always maps to the same placeholder
+- the caller's messages are unchanged
+- RedactStream redacts streamed content, including values split across chunks
+
+No API calls are made.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !RunRedactTest() {
+			os.Exit(1)
+		}
+	},
+}
+
+// RunRedactTest verifies what a provider sees and returns through a Redactor
+func RunRedactTest() bool {
+	log.Printf("\n🙈 Test: Redact")
+
+	messages := []llmtypes.MessageContent{
+		{Role: llmtypes.ChatMessageTypeHuman, Parts: []llmtypes.ContentPart{
+			llmtypes.TextContent{Text: "I'm alice@example.com, SSN 123-45-6789, card 4111 1111 1111 1111, order 1234 5678 9012 3456."},
+			llmtypes.ImageContent{SourceType: "base64", MediaType: "image/png", Data: "iVBORw0KGgo="},
+		}},
+		{Role: llmtypes.ChatMessageTypeAI, Parts: []llmtypes.ContentPart{
+			llmtypes.ToolCall{ID: "call_1", Type: "function", FunctionCall: &llmtypes.FunctionCall{Name: "lookup", Arguments: `{"email":"alice@example.com"}`}},
+		}},
+		{Role: llmtypes.ChatMessageTypeTool, Parts: []llmtypes.ContentPart{
+			llmtypes.ToolCallResponse{ToolCallID: "call_1", Name: "lookup", Content: "alice@example.com manages bob@example.com"},
+		}},
+	}
+	original := describeMessages(messages)
+
+	var sent string
+	model := fakeModelFunc(func(ctx context.Context, messages []llmtypes.MessageContent, opts *llmtypes.CallOptions) (*llmtypes.ContentResponse, error) {
+		sent = describeMessages(messages)
+		return streamChoice(opts, &llmtypes.ContentChoice{Content: "I'll write to [EMAIL_1] and carol@example.com.", StopReason: "stop"}), nil
+	})
+	logger := &captureLogger{}
+	emitter := NewTestEventEmitter()
+	llm := llmproviders.NewProviderAwareLLM(model, llmproviders.ProviderOpenAI, model.GetModelID(), emitter, "redact-test", logger)
+
+	redactor := redact.New()
+	resp, err := llm.GenerateContent(context.Background(), messages, redactor.Options()...)
+	if err != nil {
+		log.Printf("❌ Call failed: %v", err)
+		return false
+	}
+
+	passed := true
+	want := strings.Join([]string{
+		"human: text(I'm [EMAIL_1], SSN [SSN_1], card [CREDIT_CARD_1], order 1234 5678 9012 3456.) image(base64 image/png iVBORw0KGgo=)",
+		`ai: call(call_1 lookup {"email":"[EMAIL_1]"})`,
+		"tool: result(call_1 lookup [EMAIL_1] manages [EMAIL_2] error=false)",
+	}, "\n")
+	if sent != want {
+		log.Printf("❌ The provider should be sent\n%s\ngot\n%s", want, sent)
+		passed = false
+	} else {
+		log.Printf("✅ PII reaches the provider as placeholders; images and Luhn-invalid numbers are unchanged")
+	}
+
+	leaked := 0
+	for _, line := range logger.all {
+		if strings.Contains(line, "alice@example.com") {
+			leaked++
+		}
+	}
+	for _, event := range emitter.GenerationSuccessEvents {
+		if strings.Contains(event["message_content"].(string), "alice@example.com") {
+			leaked++
+		}
+	}
+	if leaked > 0 || len(emitter.GenerationSuccessEvents) != 1 {
+		log.Printf("❌ Expected only redacted messages in the logs and the success event, found PII in %d of them", leaked)
+		passed = false
+	} else {
+		log.Printf("✅ The logs and events show the messages as the provider received them")
+	}
+
+	reply := resp.Choices[0].Content
+	if reply != "I'll write to [EMAIL_1] and [EMAIL_3]." {
+		log.Printf("❌ Expected the PII in the response redacted, got %q", reply)
+		passed = false
+	} else if restored := redactor.Unredact(reply); restored != "I'll write to alice@example.com and carol@example.com." {
+		log.Printf("❌ Unredact should restore the original values, got %q", restored)
+		passed = false
+	} else {
+		log.Printf("✅ The response is redacted and Unredact restores it: %q", restored)
+	}
+
+	if mapping := redactor.Mapping(); len(mapping) != 5 || mapping["[EMAIL_1]"] != "alice@example.com" {
+		log.Printf("❌ Expected 5 placeholders with [EMAIL_1] for alice@example.com, got %v", mapping)
+		passed = false
+	} else {
+		log.Printf("✅ The same value always maps to the same placeholder")
+	}
+
+	if got := describeMessages(messages); got != original {
+		log.Printf("❌ The caller's messages were modified:\n%s", got)
+		passed = false
+	} else {
+		log.Printf("✅ The caller's messages are unchanged")
+	}
+
+	// Stream the reply in pieces that split the email and the card number
+	streamChan := make(chan llmtypes.StreamChunk, 20)
+	in, finish := redactor.RedactStream(context.Background(), streamChan)
+	streamModel := fakeModelFunc(func(ctx context.Context, messages []llmtypes.MessageContent, opts *llmtypes.CallOptions) (*llmtypes.ContentResponse, error) {
+		return streamChoice(opts, &llmtypes.ContentChoice{Content: "Mail carol@example.com, card 4111 1111 1111 1111 now.", StopReason: "stop"},
+			"Mail carol@exa", "mple.com, card 4111 1111 ", "1111 1111 now."), nil
+	})
+	streamLLM := newFakeLLM(streamModel, llmproviders.ProviderOpenAI)
+	_, err = streamLLM.GenerateContent(context.Background(), messages, append(redactor.Options(), llmtypes.WithStreamingChan(in))...)
+	finish()
+	var streamed strings.Builder
+	for chunk := range streamChan {
+		if chunk.Type == llmtypes.StreamChunkTypeContent {
+			streamed.WriteString(chunk.Content)
+		}
+	}
+	if err != nil || streamed.String() != "Mail [EMAIL_3], card [CREDIT_CARD_1] now." {
+		log.Printf("❌ Expected the streamed content redacted, got %q (error: %v)", streamed.String(), err)
+		passed = false
+	} else {
+		log.Printf("✅ RedactStream redacts values split across streamed chunks: %q", streamed.String())
+	}
+	return passed
+}
@@ -0,0 +1,125 @@
+package shared
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+
+	llmproviders "github.com/manishiitg/multi-llm-provider-go"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+
+	"github.com/spf13/cobra"
+)
+
+// RegionsTestCmd checks Config.Region validation and endpoint selection
+var RegionsTestCmd = &cobra.Command{
+	Use:   "regions",
+	Short: "Test regional endpoint selection with Config.Region and WithRegion",
+	Long: `This test checks, against a local fake OpenAI server, that:
+- Config.Region is rejected for providers without regional endpoints, for unknown regions
+  and for models the registry says are not served in the region
+- an OpenAI model in region "eu" sends requests to eu.api.openai.com
+- WithRegion fails before sending anything when the call requires another region
+
+No API calls are made.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !RunRegionsTest() {
+			os.Exit(1)
+		}
+	},
+}
+
+// hostRecorder records the host of each request before passing it on
+type hostRecorder struct {
+	mu    sync.Mutex
+	hosts []string
+	next  http.RoundTripper
+}
+
+func (h *hostRecorder) RoundTrip(req *http.Request) (*http.Response, error) {
+	h.mu.Lock()
+	h.hosts = append(h.hosts, req.URL.Host)
+	h.mu.Unlock()
+	return h.next.RoundTrip(req)
+}
+
+// RunRegionsTest verifies region validation, endpoints and per-call region checks
+func RunRegionsTest() bool {
+	log.Printf("\n🌍 Test: Regions")
+	passed := true
+	apiKey := "test"
+
+	invalid := []struct {
+		name   string
+		config llmproviders.Config
+		want   string
+	}{
+		{"Anthropic has no regional endpoints", llmproviders.Config{Provider: llmproviders.ProviderAnthropic, ModelID: "claude-sonnet-4-20250514", Region: "eu", APIKeys: &llmproviders.ProviderAPIKeys{Anthropic: &apiKey}}, "use provider bedrock or vertex"},
+		{"unknown OpenAI region", llmproviders.Config{Provider: llmproviders.ProviderOpenAI, ModelID: "gpt-4.1", Region: "eu-west-1", APIKeys: &llmproviders.ProviderAPIKeys{OpenAI: &apiKey}}, "not supported"},
+		{"Claude 3.5 Haiku outside us-east5 on Vertex", llmproviders.Config{Provider: llmproviders.ProviderVertex, ModelID: "claude-3-5-haiku@20241022", Region: "europe-west1"}, "not available"},
+		{"fallback model outside the region", llmproviders.Config{Provider: llmproviders.ProviderVertex, ModelID: "claude-sonnet-4@20250514", FallbackModels: []string{"claude-3-5-haiku@20241022"}, Region: "europe-west1"}, "not available"},
+	}
+	for _, tc := range invalid {
+		if _, err := llmproviders.InitializeLLM(tc.config); err == nil || !strings.Contains(err.Error(), tc.want) {
+			log.Printf("❌ %s: expected an initialization error mentioning %q, got %v", tc.name, tc.want, err)
+			passed = false
+		} else {
+			log.Printf("✅ %s is rejected: %v", tc.name, err)
+		}
+	}
+
+	if !llmproviders.IsModelAvailableInRegion(llmproviders.ProviderVertex, "claude-sonnet-4@20250514", "europe-west1") ||
+		llmproviders.IsModelAvailableInRegion(llmproviders.ProviderVertex, "claude-sonnet-4@20250514", "us-central1") ||
+		!llmproviders.IsModelAvailableInRegion(llmproviders.ProviderVertex, "gemini-2.5-flash", "europe-west4") ||
+		!llmproviders.IsModelAvailableInRegion(llmproviders.ProviderBedrock, "eu.anthropic.claude-sonnet-4-20250514-v1:0", "eu-central-1") {
+		log.Printf("❌ IsModelAvailableInRegion does not follow the registry")
+		passed = false
+	} else {
+		log.Printf("✅ IsModelAvailableInRegion follows the registry")
+	}
+
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"id":"chatcmpl-1","object":"chat.completion","created":1,"model":"gpt-4.1","choices":[{"index":0,"message":{"role":"assistant","content":"Hello"},"finish_reason":"stop"}]}`)
+	}))
+	defer server.Close()
+	target, _ := url.Parse(server.URL)
+	recorder := &hostRecorder{next: redirectTransport{target: target}}
+
+	llm, err := llmproviders.InitializeLLM(llmproviders.Config{
+		Provider:   llmproviders.ProviderOpenAI,
+		ModelID:    "gpt-4.1",
+		Region:     "eu",
+		APIKeys:    &llmproviders.ProviderAPIKeys{OpenAI: &apiKey},
+		HTTPClient: &http.Client{Transport: recorder},
+	})
+	if err != nil {
+		log.Printf("❌ Failed to initialize OpenAI in region eu: %v", err)
+		return false
+	}
+	messages := []llmtypes.MessageContent{llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "Hi")}
+
+	if _, err := llm.GenerateContent(context.Background(), messages, llmproviders.WithRegion("eu")); err != nil || len(recorder.hosts) != 1 || recorder.hosts[0] != "eu.api.openai.com" {
+		log.Printf("❌ OpenAI region eu: expected one request to eu.api.openai.com, got hosts %v, err %v", recorder.hosts, err)
+		passed = false
+	} else {
+		log.Printf("✅ OpenAI region eu sends requests to %s", recorder.hosts[0])
+	}
+
+	requests = 0
+	if _, err := llm.GenerateContent(context.Background(), messages, llmproviders.WithRegion("us")); err == nil || requests != 0 {
+		log.Printf("❌ WithRegion(\"us\") on an eu model: expected an error without requests, got %d requests, err %v", requests, err)
+		passed = false
+	} else {
+		log.Printf("✅ WithRegion(\"us\") on an eu model fails without a request: %v", err)
+	}
+	return passed
+}
@@ -0,0 +1,150 @@
+package shared
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	llmproviders "github.com/manishiitg/multi-llm-provider-go"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+
+	"github.com/spf13/cobra"
+)
+
+// RequiredToolArgsTestCmd checks the follow-up of WithEnforceRequiredToolArgs
+var RequiredToolArgsTestCmd = &cobra.Command{
+	Use:   "required-tool-args",
+	Short: "Test that WithEnforceRequiredToolArgs completes tool calls missing required parameters",
+	Long: `This test uses a fake model that leaves out a required tool parameter and checks that
+WithEnforceRequiredToolArgs:
+- sends one follow-up naming the missing parameters, forcing the tool
+- merges the corrected arguments into the original call, which keeps its ID
+- leaves complete calls, and calls the model doesn't correct, as they were
+- does nothing without the option
+
+No API calls are made.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !RunRequiredToolArgsTest() {
+			os.Exit(1)
+		}
+	},
+}
+
+// forgetfulToolModel is a fake model that calls read_file without its required path, and
+// with it (when correct is set) once told which arguments it must provide
+type forgetfulToolModel struct {
+	fakeModelID
+	correct  bool
+	calls    int
+	feedback string
+	forced   string
+}
+
+func (m *forgetfulToolModel) GenerateContent(ctx context.Context, messages []llmtypes.MessageContent, options ...llmtypes.CallOption) (*llmtypes.ContentResponse, error) {
+	m.calls++
+	opts := callOptions(options)
+	usage := &llmtypes.Usage{InputTokens: 10, OutputTokens: 5, TotalTokens: 15}
+	call := func(id, name, args string) llmtypes.ToolCall {
+		return llmtypes.ToolCall{ID: id, Type: "function", FunctionCall: &llmtypes.FunctionCall{Name: name, Arguments: args}}
+	}
+
+	last := messages[len(messages)-1]
+	if text, ok := last.Parts[0].(llmtypes.TextContent); ok && strings.Contains(text.Text, "You must provide") {
+		m.feedback = text.Text
+		if opts.ToolChoice != nil && opts.ToolChoice.Function != nil {
+			m.forced = opts.ToolChoice.Function.Name
+		}
+		args := `{"encoding":"utf-8"}`
+		if m.correct {
+			args = `{"path":"/tmp/notes.txt"}`
+		}
+		return &llmtypes.ContentResponse{Usage: usage, Choices: []*llmtypes.ContentChoice{{
+			StopReason: "tool_calls",
+			ToolCalls:  []llmtypes.ToolCall{call("call_retry", "read_file", args)},
+		}}}, nil
+	}
+	return &llmtypes.ContentResponse{Usage: usage, Choices: []*llmtypes.ContentChoice{{
+		StopReason: "tool_calls",
+		ToolCalls: []llmtypes.ToolCall{
+			call("call_1", "read_file", `{"encoding":"utf-8"}`),
+			call("call_2", "list_dir", `{"path":"/tmp"}`),
+		},
+	}}}, nil
+}
+
+// RunRequiredToolArgsTest verifies WithEnforceRequiredToolArgs
+func RunRequiredToolArgsTest() bool {
+	log.Printf("\n🩹 Test: Enforce Required Tool Args")
+
+	var tools []llmtypes.Tool
+	for _, name := range []string{"read_file", "list_dir"} {
+		tools = append(tools, llmtypes.Tool{Type: "function", Function: &llmtypes.FunctionDefinition{
+			Name: name,
+			Parameters: llmtypes.NewParameters(map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"path":     map[string]interface{}{"type": "string"},
+					"encoding": map[string]interface{}{"type": "string"},
+				},
+				"required": []string{"path"},
+			}),
+		}})
+	}
+	messages := []llmtypes.MessageContent{llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "Read my notes.")}
+	generate := func(model *forgetfulToolModel, options ...llmtypes.CallOption) (*llmtypes.ContentResponse, error) {
+		llm := newFakeLLM(model, llmproviders.ProviderBedrock)
+		return llm.GenerateContent(context.Background(), messages, append([]llmtypes.CallOption{llmtypes.WithTools(tools)}, options...)...)
+	}
+	arguments := func(resp *llmtypes.ContentResponse) string {
+		var args []string
+		for _, toolCall := range resp.Choices[0].ToolCalls {
+			args = append(args, fmt.Sprintf("%s=%s", toolCall.ID, toolCall.FunctionCall.Arguments))
+		}
+		return strings.Join(args, " ")
+	}
+
+	passed := true
+
+	// The model completes the call when told what is missing
+	model := &forgetfulToolModel{correct: true}
+	resp, err := generate(model, llmproviders.WithEnforceRequiredToolArgs())
+	switch {
+	case err != nil:
+		log.Printf("❌ Call failed: %v", err)
+		passed = false
+	case arguments(resp) != `call_1={"encoding":"utf-8","path":"/tmp/notes.txt"} call_2={"path":"/tmp"}`:
+		log.Printf("❌ Expected the corrected arguments merged into call_1, got %s", arguments(resp))
+		passed = false
+	case model.calls != 2 || model.forced != "read_file" || !strings.Contains(model.feedback, "You must provide: path"):
+		log.Printf("❌ Expected one follow-up forcing read_file and naming path, got %d calls, forced %q, feedback %q", model.calls, model.forced, model.feedback)
+		passed = false
+	case resp.Usage == nil || resp.Usage.TotalTokens != 30:
+		log.Printf("❌ Expected the usage of both calls, got %+v", resp.Usage)
+		passed = false
+	default:
+		log.Printf("✅ Incomplete call completed after one follow-up: %s", arguments(resp))
+	}
+
+	// The model doesn't correct the call: it is returned as it was, after a single follow-up
+	model = &forgetfulToolModel{}
+	resp, err = generate(model, llmproviders.WithEnforceRequiredToolArgs())
+	if err != nil || model.calls != 2 || !strings.Contains(arguments(resp), `call_1={"encoding":"utf-8"}`) {
+		log.Printf("❌ Expected the uncorrected call returned after one follow-up, got %d calls, %v, error %v", model.calls, resp, err)
+		passed = false
+	} else {
+		log.Printf("✅ Uncorrected call returned as it was after one follow-up")
+	}
+
+	// Without the option there is no follow-up
+	model = &forgetfulToolModel{correct: true}
+	resp, err = generate(model)
+	if err != nil || model.calls != 1 || !strings.Contains(arguments(resp), `call_1={"encoding":"utf-8"}`) {
+		log.Printf("❌ Expected no follow-up without the option, got %d calls, error %v", model.calls, err)
+		passed = false
+	} else {
+		log.Printf("✅ No follow-up without WithEnforceRequiredToolArgs")
+	}
+	return passed
+}
@@ -0,0 +1,146 @@
+package shared
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+
+	llmproviders "github.com/manishiitg/multi-llm-provider-go"
+	"github.com/manishiitg/multi-llm-provider-go/internal/testing"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+	openaiadapter "github.com/manishiitg/multi-llm-provider-go/pkg/adapters/openai"
+
+	"github.com/openai/openai-go/v3"
+	"github.com/openai/openai-go/v3/option"
+	"github.com/spf13/cobra"
+)
+
+// ResponseUsageTestCmd checks that ContentResponse.Usage holds the usage of the whole response
+var ResponseUsageTestCmd = &cobra.Command{
+	Use:   "response-usage",
+	Short: "Test the top-level Usage of responses, including cache tokens and cost",
+	Long: `This test checks that:
+- ContentResponse.Usage is filled from GenerationInfo for models that only report usage per choice
+- usage set by the model is kept as it is
+- the OpenRouter cost and cached tokens are reported in Usage, with and without streaming
+  (against a local fake OpenRouter server)
+
+No API calls are made.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !RunResponseUsageTest() {
+			os.Exit(1)
+		}
+	},
+}
+
+// generationInfoModel is a fake model that reports usage only in the GenerationInfo of its
+// choices, or in resp.Usage when usage is set
+type generationInfoModel struct {
+	fakeModelID
+	usage *llmtypes.Usage
+}
+
+func (m *generationInfoModel) GenerateContent(ctx context.Context, messages []llmtypes.MessageContent, options ...llmtypes.CallOption) (*llmtypes.ContentResponse, error) {
+	input, output, cached, cost := 100, 20, 60, 0.0015
+	info := func() *llmtypes.GenerationInfo {
+		return &llmtypes.GenerationInfo{InputTokens: &input, OutputTokens: &output, CachedContentTokens: &cached, Cost: &cost}
+	}
+	return &llmtypes.ContentResponse{
+		Choices: []*llmtypes.ContentChoice{
+			{Content: "first", StopReason: "stop", GenerationInfo: info()},
+			{Content: "second", StopReason: "stop", GenerationInfo: info()},
+		},
+		Usage: m.usage,
+	}, nil
+}
+
+func (m *generationInfoModel) Call(ctx context.Context, prompt string, options ...llmtypes.CallOption) (string, error) {
+	return "", nil
+}
+
+// RunResponseUsageTest verifies the usage reported on responses
+func RunResponseUsageTest() bool {
+	log.Printf("\n📊 Test: Response Usage")
+
+	messages := []llmtypes.MessageContent{llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "Hi")}
+	describe := func(usage *llmtypes.Usage) string {
+		if usage == nil {
+			return "nil"
+		}
+		cache, cost := "nil", "nil"
+		if usage.CacheTokens != nil {
+			cache = fmt.Sprint(*usage.CacheTokens)
+		}
+		if usage.Cost != nil {
+			cost = fmt.Sprint(*usage.Cost)
+		}
+		return fmt.Sprintf("input=%d output=%d total=%d cache=%s cost=%s", usage.InputTokens, usage.OutputTokens, usage.TotalTokens, cache, cost)
+	}
+
+	passed := true
+	check := func(name string, usage *llmtypes.Usage, want string) {
+		if got := describe(usage); got != want {
+			log.Printf("❌ %s: expected usage %s, got %s", name, want, got)
+			passed = false
+		} else {
+			log.Printf("✅ %s: %s", name, got)
+		}
+	}
+
+	llm := llmproviders.NewProviderAwareLLM(&generationInfoModel{}, llmproviders.ProviderOpenAI, "fake-model", nil, "response-usage-test", nil)
+	resp, err := llm.GenerateContent(context.Background(), messages)
+	if err != nil {
+		log.Printf("❌ Call failed: %v", err)
+		return false
+	}
+	check("Usage filled from GenerationInfo once for two choices", resp.Usage, "input=100 output=20 total=120 cache=60 cost=0.0015")
+
+	llm = llmproviders.NewProviderAwareLLM(&generationInfoModel{usage: &llmtypes.Usage{InputTokens: 7, OutputTokens: 3, TotalTokens: 10}}, llmproviders.ProviderOpenAI, "fake-model", nil, "response-usage-test", nil)
+	if resp, err = llm.GenerateContent(context.Background(), messages); err != nil {
+		log.Printf("❌ Call failed: %v", err)
+		return false
+	}
+	check("Usage set by the model is kept", resp.Usage, "input=7 output=3 total=10 cache=nil cost=nil")
+
+	server := httptest.NewServer(http.HandlerFunc(fakeOpenRouterHandler))
+	defer server.Close()
+	client := openai.NewClient(option.WithAPIKey("test"), option.WithBaseURL(server.URL))
+	adapter := openaiadapter.NewOpenAIAdapter(&client, "openai/gpt-4.1", testing.GetTestLogger())
+	llm = llmproviders.NewProviderAwareLLM(adapter, llmproviders.ProviderOpenRouter, "openai/gpt-4.1", nil, "response-usage-test", nil)
+	if resp, err = llm.GenerateContent(context.Background(), messages); err != nil {
+		log.Printf("❌ OpenRouter call failed: %v", err)
+		return false
+	}
+	check("OpenRouter cost and cache tokens", resp.Usage, "input=10 output=5 total=15 cache=4 cost=0.00042")
+
+	streamChan := make(chan llmtypes.StreamChunk, 100)
+	if resp, err = llm.GenerateContent(context.Background(), messages, llmtypes.WithStreamingChan(streamChan)); err != nil {
+		log.Printf("❌ OpenRouter streaming call failed: %v", err)
+		return false
+	}
+	check("OpenRouter cost and cache tokens when streaming", resp.Usage, "input=10 output=5 total=15 cache=4 cost=0.00042")
+	return passed
+}
+
+// fakeOpenRouterHandler answers chat completions like OpenRouter with usage accounting
+func fakeOpenRouterHandler(w http.ResponseWriter, r *http.Request) {
+	var request struct {
+		Stream bool `json:"stream"`
+	}
+	_ = json.NewDecoder(r.Body).Decode(&request)
+	usage := `{"prompt_tokens":10,"completion_tokens":5,"total_tokens":15,"prompt_tokens_details":{"cached_tokens":4},"cost":0.00042}`
+	if !request.Stream {
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"id":"gen-1","object":"chat.completion","created":1,"model":"openai/gpt-4.1","choices":[{"index":0,"message":{"role":"assistant","content":"Hello"},"finish_reason":"stop"}],"usage":%s}`, usage)
+		return
+	}
+	w.Header().Set("Content-Type", "text/event-stream")
+	fmt.Fprint(w, `data: {"id":"gen-1","object":"chat.completion.chunk","created":1,"model":"openai/gpt-4.1","choices":[{"index":0,"delta":{"role":"assistant","content":"Hello"},"finish_reason":null}]}`+"\n\n")
+	fmt.Fprint(w, `data: {"id":"gen-1","object":"chat.completion.chunk","created":1,"model":"openai/gpt-4.1","choices":[{"index":0,"delta":{},"finish_reason":"stop"}]}`+"\n\n")
+	fmt.Fprintf(w, `data: {"id":"gen-1","object":"chat.completion.chunk","created":1,"model":"openai/gpt-4.1","choices":[],"usage":%s}`+"\n\n", usage)
+	fmt.Fprint(w, "data: [DONE]\n\n")
+}
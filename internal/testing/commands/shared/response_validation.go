@@ -0,0 +1,121 @@
+package shared
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	llmproviders "github.com/manishiitg/multi-llm-provider-go"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+
+	"github.com/spf13/cobra"
+)
+
+// ResponseValidationTestCmd checks WithResponseValidator and WithValidationRetries
+var ResponseValidationTestCmd = &cobra.Command{
+	Use:   "response-validation",
+	Short: "Test that responses rejected by a validator are regenerated",
+	Long: `This test uses a fake model to check that:
+- a response accepted by the validators is returned after one call
+- a rejected response is regenerated while WithValidationRetries allows
+- WithValidationFeedback sends the rejected answer and the validation error on retry
+- when every attempt is rejected the call fails with ErrResponseValidation
+- validators see the response after the response interceptors
+- validation retries are refused while streaming
+
+No API calls are made.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !RunResponseValidationTest() {
+			os.Exit(1)
+		}
+	},
+}
+
+// scriptedModel answers each call with the next of its answers, repeating the last one, and
+// records the messages of every call
+type scriptedModel struct {
+	fakeModelID
+	answers []string
+	calls   [][]llmtypes.MessageContent
+}
+
+func (m *scriptedModel) GenerateContent(ctx context.Context, messages []llmtypes.MessageContent, options ...llmtypes.CallOption) (*llmtypes.ContentResponse, error) {
+	m.calls = append(m.calls, messages)
+	answer := m.answers[min(len(m.calls), len(m.answers))-1]
+	return textResponse(answer), nil
+}
+
+// RunResponseValidationTest verifies response validators and their retries
+func RunResponseValidationTest() bool {
+	log.Printf("\n🛡️  Test: Response Validation")
+
+	messages := []llmtypes.MessageContent{llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "Who wrote Hamlet? Cite a source.")}
+	requireCitation := llmproviders.WithResponseValidator(func(resp *llmtypes.ContentResponse) error {
+		if !strings.Contains(resp.Choices[0].Content, "[source]") {
+			return fmt.Errorf("answer has no citation")
+		}
+		return nil
+	})
+	generate := func(model *scriptedModel, options ...llmtypes.CallOption) (*llmtypes.ContentResponse, error) {
+		llm := newFakeLLM(model, llmproviders.ProviderOpenAI)
+		return llm.GenerateContent(context.Background(), messages, options...)
+	}
+
+	passed := true
+	model := &scriptedModel{answers: []string{"Shakespeare [source]"}}
+	if resp, err := generate(model, requireCitation, llmproviders.WithValidationRetries(2)); err != nil || len(model.calls) != 1 {
+		log.Printf("❌ Expected a valid response after 1 call, got %d calls (error %v)", len(model.calls), err)
+		passed = false
+	} else {
+		log.Printf("✅ Valid response returned after 1 call: %s", resp.Choices[0].Content)
+	}
+
+	model = &scriptedModel{answers: []string{"Shakespeare", "Shakespeare [source]"}}
+	resp, err := generate(model, requireCitation, llmproviders.WithValidationRetries(2), llmproviders.WithValidationFeedback())
+	switch {
+	case err != nil || len(model.calls) != 2 || resp.Choices[0].Content != "Shakespeare [source]":
+		log.Printf("❌ Expected the rejected response to be regenerated once, got %d calls (error %v)", len(model.calls), err)
+		passed = false
+	case len(model.calls[1]) != 3 || !strings.Contains(fmt.Sprint(model.calls[1][2].Parts), "answer has no citation"):
+		log.Printf("❌ Expected the retry to carry the rejected answer and the validation error, got %v", model.calls[1])
+		passed = false
+	default:
+		log.Printf("✅ Rejected response regenerated with feedback: %s", resp.Choices[0].Content)
+	}
+
+	model = &scriptedModel{answers: []string{"Shakespeare"}}
+	_, err = generate(model, requireCitation, llmproviders.WithValidationRetries(1))
+	var validationErr *llmproviders.ResponseValidationError
+	if !errors.Is(err, llmproviders.ErrResponseValidation) || !errors.As(err, &validationErr) || validationErr.Attempts != 2 || len(model.calls) != 2 || len(model.calls[1]) != 1 {
+		log.Printf("❌ Expected ErrResponseValidation after 2 attempts without feedback, got %d calls (error %v)", len(model.calls), err)
+		passed = false
+	} else {
+		log.Printf("✅ Call failed after every attempt was rejected: %v", err)
+	}
+
+	// An interceptor adding the citation runs before the validators
+	model = &scriptedModel{answers: []string{"Shakespeare"}}
+	cite := llmproviders.WithResponseInterceptor(func(resp *llmtypes.ContentResponse) error {
+		resp.Choices[0].Content += " [source]"
+		return nil
+	})
+	if _, err := generate(model, cite, requireCitation); err != nil || len(model.calls) != 1 {
+		log.Printf("❌ Expected validators to see the intercepted response, got %d calls (error %v)", len(model.calls), err)
+		passed = false
+	} else {
+		log.Printf("✅ Validators ran after the response interceptors")
+	}
+
+	model = &scriptedModel{answers: []string{"Shakespeare [source]"}}
+	streamChan := make(chan llmtypes.StreamChunk, 10)
+	if _, err := generate(model, requireCitation, llmproviders.WithValidationRetries(1), llmtypes.WithStreamingChan(streamChan)); !errors.Is(err, llmproviders.ErrUnsupportedOptionCombination) || len(model.calls) != 0 {
+		log.Printf("❌ Expected validation retries while streaming to be refused, got %d calls (error %v)", len(model.calls), err)
+		passed = false
+	} else {
+		log.Printf("✅ Validation retries refused while streaming")
+	}
+	return passed
+}
@@ -0,0 +1,132 @@
+package shared
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	llmproviders "github.com/manishiitg/multi-llm-provider-go"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+
+	"github.com/spf13/cobra"
+)
+
+// RetriesTestCmd checks call-level retries with WithMaxRetries and WithRetryPolicy
+var RetriesTestCmd = &cobra.Command{
+	Use:   "retries",
+	Short: "Test WithMaxRetries and WithRetryPolicy",
+	Long: `This test checks, against a local fake OpenAI server failing a set number of times, that:
+- WithMaxRetries(n) makes at most n+1 requests, with the SDK's own retries turned off
+- WithMaxRetries overrides Config.MaxRetries for one call
+- WithRetryPolicy's Retryable predicate and the provider's Retry-After are honored
+- a streaming call is retried when it failed before sending output
+
+No API calls are made.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !RunRetriesTest() {
+			os.Exit(1)
+		}
+	},
+}
+
+// RunRetriesTest verifies the number of attempts and the backoff of retried calls
+func RunRetriesTest() bool {
+	log.Printf("\n🔁 Test: Retries")
+
+	var requests, failures int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		body, _ := io.ReadAll(r.Body)
+		if requests <= failures {
+			w.Header().Set("Content-Type", "application/json")
+			w.Header().Set("retry-after-ms", "100")
+			w.WriteHeader(http.StatusServiceUnavailable)
+			fmt.Fprint(w, `{"error":{"message":"The server is overloaded","type":"server_error"}}`)
+			return
+		}
+		if strings.Contains(string(body), `"stream":true`) {
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprint(w, "data: {\"id\":\"chatcmpl-1\",\"object\":\"chat.completion.chunk\",\"created\":1,\"model\":\"gpt-4.1\",\"choices\":[{\"index\":0,\"delta\":{\"role\":\"assistant\",\"content\":\"Hello\"},\"finish_reason\":\"stop\"}]}\n\ndata: [DONE]\n\n")
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprint(w, `{"id":"chatcmpl-1","object":"chat.completion","created":1,"model":"gpt-4.1","choices":[{"index":0,"message":{"role":"assistant","content":"Hello"},"finish_reason":"stop"}]}`)
+	}))
+	defer server.Close()
+	target, _ := url.Parse(server.URL)
+
+	apiKey := "test"
+	newLLM := func(maxRetries int) llmtypes.Model {
+		llm, err := llmproviders.InitializeLLM(llmproviders.Config{
+			Provider:   llmproviders.ProviderOpenAI,
+			ModelID:    "gpt-4.1",
+			MaxRetries: maxRetries,
+			APIKeys:    &llmproviders.ProviderAPIKeys{OpenAI: &apiKey},
+			HTTPClient: &http.Client{Transport: redirectTransport{target: target}},
+		})
+		if err != nil {
+			log.Fatalf("❌ Failed to initialize OpenAI: %v", err)
+		}
+		return llm
+	}
+	messages := []llmtypes.MessageContent{llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "Hi")}
+	fast := llmproviders.WithRetryPolicy(llmproviders.RetryPolicy{BaseDelay: time.Millisecond, MaxDelay: 5 * time.Millisecond})
+
+	passed := true
+	check := func(name string, llm llmtypes.Model, failing, wantRequests int, wantErr bool, options ...llmtypes.CallOption) {
+		requests, failures = 0, failing
+		_, err := llm.GenerateContent(context.Background(), messages, options...)
+		if requests != wantRequests || (err != nil) != wantErr {
+			log.Printf("❌ %s: expected %d requests (error %v), got %d requests, err %v", name, wantRequests, wantErr, requests, err)
+			passed = false
+			return
+		}
+		log.Printf("✅ %s: %d requests", name, requests)
+	}
+
+	llm := newLLM(0)
+	check("WithMaxRetries(3) with 2 failures", llm, 2, 3, false, llmproviders.WithMaxRetries(3), fast)
+	check("WithMaxRetries(1) with persistent failures", llm, 10, 2, true, llmproviders.WithMaxRetries(1), fast)
+	check("WithMaxRetries(0) turns off SDK retries", llm, 10, 1, true, llmproviders.WithMaxRetries(0))
+	check("Retryable predicate rejecting the error", llm, 10, 1, true, llmproviders.WithMaxRetries(3), llmproviders.WithRetryPolicy(llmproviders.RetryPolicy{
+		Retryable: func(error) bool { return false },
+	}))
+
+	configured := newLLM(2)
+	check("Config.MaxRetries 2", configured, 10, 3, true, fast)
+	check("WithMaxRetries(0) over Config.MaxRetries 2", configured, 10, 1, true, llmproviders.WithMaxRetries(0))
+
+	requests, failures = 0, 1
+	start := time.Now()
+	_, err := llm.GenerateContent(context.Background(), messages, llmproviders.WithMaxRetries(1), llmproviders.WithRetryPolicy(llmproviders.RetryPolicy{BaseDelay: time.Millisecond, MaxDelay: time.Second}))
+	if elapsed := time.Since(start); err != nil || requests != 2 || elapsed < 100*time.Millisecond {
+		log.Printf("❌ Retry-After: expected a retry after at least 100ms, got %d requests after %v, err %v", requests, elapsed, err)
+		passed = false
+	} else {
+		log.Printf("✅ Retry-After of 100ms is honored (retried after %v)", elapsed.Round(time.Millisecond))
+	}
+
+	requests, failures = 0, 1
+	streamChan := make(chan llmtypes.StreamChunk, 100)
+	resp, err := llm.GenerateContent(context.Background(), messages, llmproviders.WithMaxRetries(2), fast, llmtypes.WithStreamingChan(streamChan))
+	var streamed strings.Builder
+	for chunk := range streamChan {
+		if chunk.Type == llmtypes.StreamChunkTypeContent {
+			streamed.WriteString(chunk.Content)
+		}
+	}
+	if err != nil || resp == nil || requests != 2 || streamed.String() != "Hello" {
+		log.Printf("❌ Streaming retry: expected 2 requests and \"Hello\" streamed once, got %d requests, %q, err %v", requests, streamed.String(), err)
+		passed = false
+	} else {
+		log.Printf("✅ A streaming call failing before output is retried and streams once")
+	}
+	return passed
+}
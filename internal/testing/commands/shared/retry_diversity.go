@@ -0,0 +1,113 @@
+package shared
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	llmproviders "github.com/manishiitg/multi-llm-provider-go"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+
+	"github.com/spf13/cobra"
+)
+
+// RetryDiversityTestCmd checks that schema and validation retries vary the seed and temperature
+var RetryDiversityTestCmd = &cobra.Command{
+	Use:   "retry-diversity",
+	Short: "Test that schema and validation retries vary the seed and temperature",
+	Long: `This test uses a fake model that records the seed and temperature of every call and checks that:
+- validation retries offset a WithOpenAIParams seed by the attempt number
+- WithRetryDiversity lowers the temperature by its delta on each retry, and raises it instead
+  once lowering would reach 0
+- without WithTemperature the retries are lowered from the provider default of 1.0, so they
+  stay within Claude's maximum
+- schema retries (WithSchemaRetry) are varied the same way
+- without WithRetryDiversity the temperature of retries is left unchanged
+
+No API calls are made.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !RunRetryDiversityTest() {
+			os.Exit(1)
+		}
+	},
+}
+
+// samplingModel answers each call with the next of its answers, repeating the last one, and
+// records the seed and temperature of every call
+type samplingModel struct {
+	fakeModelID
+	answers []string
+	calls   []string
+}
+
+func (m *samplingModel) GenerateContent(ctx context.Context, messages []llmtypes.MessageContent, options ...llmtypes.CallOption) (*llmtypes.ContentResponse, error) {
+	opts := callOptions(options)
+	seed := "none"
+	if opts.OpenAIParams != nil && opts.OpenAIParams.Seed != nil {
+		seed = fmt.Sprint(*opts.OpenAIParams.Seed)
+	}
+	m.calls = append(m.calls, fmt.Sprintf("seed=%s/t=%.1f", seed, opts.Temperature))
+	answer := m.answers[min(len(m.calls), len(m.answers))-1]
+	return textResponse(answer), nil
+}
+
+// RunRetryDiversityTest verifies the seeds and temperatures of schema and validation retries
+func RunRetryDiversityTest() bool {
+	log.Printf("\n🎲 Test: Retry Diversity")
+
+	messages := []llmtypes.MessageContent{llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "Which city is the capital of France?")}
+	seed := int64(42)
+	seeded := llmproviders.WithOpenAIParams(llmproviders.OpenAIParams{Seed: &seed})
+	rejectFirst := func(n int) llmtypes.CallOption {
+		calls := 0
+		return llmproviders.WithResponseValidator(func(resp *llmtypes.ContentResponse) error {
+			if calls++; calls <= n {
+				return fmt.Errorf("rejected attempt %d", calls)
+			}
+			return nil
+		})
+	}
+	schema := map[string]interface{}{
+		"type":       "object",
+		"properties": map[string]interface{}{"city": map[string]interface{}{"type": "string"}},
+		"required":   []string{"city"},
+	}
+
+	passed := true
+	for _, c := range []struct {
+		name    string
+		answers []string
+		options []llmtypes.CallOption
+		want    string
+	}{
+		{"validation retries", []string{"Paris"}, []llmtypes.CallOption{
+			seeded, llmproviders.WithTemperature(0.2), llmproviders.WithRetryDiversity(0.3), rejectFirst(2), llmproviders.WithValidationRetries(2),
+		}, "seed=42/t=0.2 seed=43/t=0.5 seed=44/t=0.8"},
+		{"lowered diversity", []string{"Paris"}, []llmtypes.CallOption{
+			llmproviders.WithTemperature(0.7), llmproviders.WithRetryDiversity(0.2), rejectFirst(2), llmproviders.WithValidationRetries(2),
+		}, "seed=none/t=0.7 seed=none/t=0.5 seed=none/t=0.3"},
+		{"schema retries", []string{`{"town":"Paris"}`, `{"city":"Paris"}`}, []llmtypes.CallOption{
+			seeded, llmproviders.WithTemperature(0.2), llmproviders.WithRetryDiversity(0.3),
+			llmproviders.WithStructuredOutput(schema, "city", false), llmproviders.WithSchemaRetry(2),
+		}, "seed=42/t=0.2 seed=43/t=0.5"},
+		{"diversity without a temperature", []string{"Paris"}, []llmtypes.CallOption{
+			llmproviders.WithRetryDiversity(0.3), rejectFirst(2), llmproviders.WithValidationRetries(2),
+		}, "seed=none/t=0.0 seed=none/t=0.7 seed=none/t=0.4"},
+		{"seed without diversity", []string{"Paris"}, []llmtypes.CallOption{
+			seeded, llmproviders.WithTemperature(0.2), rejectFirst(1), llmproviders.WithValidationRetries(1),
+		}, "seed=42/t=0.2 seed=43/t=0.2"},
+	} {
+		model := &samplingModel{answers: c.answers}
+		llm := newFakeLLM(model, llmproviders.ProviderOpenAI)
+		_, err := llm.GenerateContent(context.Background(), messages, c.options...)
+		if got := strings.Join(model.calls, " "); err != nil || got != c.want {
+			log.Printf("❌ %s: expected %s, got %s (error %v)", c.name, c.want, got, err)
+			passed = false
+			continue
+		}
+		log.Printf("✅ %s: %s", c.name, c.want)
+	}
+	return passed
+}
@@ -0,0 +1,138 @@
+package shared
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"os"
+	"sync"
+
+	llmproviders "github.com/manishiitg/multi-llm-provider-go"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+
+	"github.com/spf13/cobra"
+)
+
+// RouterTestCmd checks that a Router dispatches calls to the provider of their model
+var RouterTestCmd = &cobra.Command{
+	Use:   "router",
+	Short: "Test that Router routes WithModel to the right provider",
+	Long: `This test checks that Router:
+- routes model IDs, provider-prefixed IDs and aliases to the right provider
+- prefers providers in the order given for IDs several of them serve
+- dispatches calls to lazily initialized clients, also concurrently (dry run)
+- rejects models it cannot route
+
+No API calls are made.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !RunRouterTest() {
+			os.Exit(1)
+		}
+	},
+}
+
+// RunRouterTest verifies Router routing and dispatch
+func RunRouterTest() bool {
+	log.Printf("\n🔀 Test: Router")
+
+	apiKey := "dry-run"
+	config := llmproviders.Config{
+		ModelID: "smart",
+		APIKeys: &llmproviders.ProviderAPIKeys{OpenAI: &apiKey, Anthropic: &apiKey, OpenRouter: &apiKey},
+		ModelAliases: map[string]llmproviders.ModelAlias{
+			"smart": {Provider: llmproviders.ProviderAnthropic, ModelID: "claude-sonnet-4-20250514"},
+		},
+	}
+	router, err := llmproviders.NewRouter(config)
+	if err != nil {
+		log.Printf("❌ NewRouter failed: %v", err)
+		return false
+	}
+
+	passed := true
+	for model, want := range map[string]struct {
+		provider llmproviders.Provider
+		modelID  string
+	}{
+		"gpt-4.1":                  {llmproviders.ProviderOpenAI, "gpt-4.1"},
+		"o3-mini":                  {llmproviders.ProviderOpenAI, "o3-mini"},
+		"claude-sonnet-4-20250514": {llmproviders.ProviderAnthropic, "claude-sonnet-4-20250514"},
+		"gemini-2.5-flash":         {llmproviders.ProviderVertex, "gemini-2.5-flash"},
+		"us.anthropic.claude-sonnet-4-20250514-v1:0":     {llmproviders.ProviderBedrock, "us.anthropic.claude-sonnet-4-20250514-v1:0"},
+		"anthropic/claude-sonnet-4":                      {llmproviders.ProviderOpenRouter, "anthropic/claude-sonnet-4"},
+		"vertex:claude-sonnet-4@20250514":                {llmproviders.ProviderVertex, "claude-sonnet-4@20250514"},
+		"bedrock:anthropic.claude-3-haiku-20240307-v1:0": {llmproviders.ProviderBedrock, "anthropic.claude-3-haiku-20240307-v1:0"},
+		"smart": {llmproviders.ProviderAnthropic, "claude-sonnet-4-20250514"},
+	} {
+		provider, modelID, err := router.Route(model)
+		if err != nil || provider != want.provider || modelID != want.modelID {
+			log.Printf("❌ %q should route to %s %s, got %s %s (%v)", model, want.provider, want.modelID, provider, modelID, err)
+			passed = false
+		}
+	}
+	if passed {
+		log.Printf("✅ Model IDs, provider prefixes and aliases route to the right provider")
+	}
+	if _, _, err := router.Route("llama-3-70b"); err == nil {
+		log.Printf("❌ An unknown model should not route")
+		passed = false
+	} else {
+		log.Printf("✅ An unknown model does not route: %v", err)
+	}
+	vertexFirst, _ := llmproviders.NewRouter(config, llmproviders.ProviderVertex, llmproviders.ProviderAnthropic)
+	if provider, _, _ := vertexFirst.Route("claude-sonnet-4-20250514"); provider != llmproviders.ProviderVertex {
+		log.Printf("❌ With Vertex first, claude models should route to Vertex, got %s", provider)
+		passed = false
+	} else {
+		log.Printf("✅ Provider order decides between providers serving the same model")
+	}
+
+	// requestModel returns the model of the dry-run request of a call
+	messages := []llmtypes.MessageContent{llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "Hi")}
+	requestModel := func(options ...llmtypes.CallOption) (string, error) {
+		resp, err := router.GenerateContent(context.Background(), messages, append(options, llmtypes.WithDryRun())...)
+		if err != nil {
+			return "", err
+		}
+		request, _ := json.Marshal(resp.Raw)
+		var body struct {
+			Model string `json:"model"`
+		}
+		_ = json.Unmarshal(request, &body)
+		return body.Model, nil
+	}
+	for _, call := range []struct {
+		options []llmtypes.CallOption
+		want    string
+	}{
+		{nil, "claude-sonnet-4-20250514"},
+		{[]llmtypes.CallOption{llmtypes.WithModel("gpt-4.1")}, "gpt-4.1"},
+		{[]llmtypes.CallOption{llmtypes.WithModel("openrouter:openai/gpt-4o")}, "openai/gpt-4o"},
+	} {
+		if model, err := requestModel(call.options...); err != nil || model != call.want {
+			log.Printf("❌ Expected a request for %s, got %q (%v)", call.want, model, err)
+			passed = false
+		} else {
+			log.Printf("✅ Call dispatched to a request for %s", model)
+		}
+	}
+
+	var wg sync.WaitGroup
+	errs := make([]error, 8)
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = requestModel(llmtypes.WithModel("gpt-4.1-mini"))
+		}(i)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			log.Printf("❌ Concurrent calls failed: %v", err)
+			return false
+		}
+	}
+	log.Printf("✅ Concurrent calls share the lazily initialized client")
+	return passed
+}
@@ -0,0 +1,141 @@
+package shared
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"os"
+	"strings"
+
+	llmproviders "github.com/manishiitg/multi-llm-provider-go"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+	"github.com/manishiitg/multi-llm-provider-go/pkg/jsonschema"
+
+	"github.com/spf13/cobra"
+)
+
+// SchemaRefsTestCmd checks that tool schemas with $defs/$ref are adapted per provider
+var SchemaRefsTestCmd = &cobra.Command{
+	Use:   "schema-refs",
+	Short: "Test tool parameter schemas with shared $defs, $ref and recursion",
+	Long: `This test builds a tool whose parameters share definitions through $defs and
+$ref, including a recursive reference, and checks that:
+- jsonschema.Adapt inlines the references, cuts the recursion and strips keywords
+- the adapted schema still accepts the same arguments
+- the Gemini request carries the inlined schema without $ref or unsupported formats
+- the OpenAI request keeps $defs and $ref, which OpenAI supports
+
+Requests are built with WithDryRun; no API calls are made.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !RunSchemaRefsTest() {
+			os.Exit(1)
+		}
+	},
+}
+
+// schemaRefsParameters describes a team with a lead and members sharing a Person definition
+// that refers to itself through reports
+func schemaRefsParameters() map[string]interface{} {
+	return map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"lead":    map[string]interface{}{"$ref": "#/$defs/Person", "description": "Team lead"},
+			"members": map[string]interface{}{"type": "array", "items": map[string]interface{}{"$ref": "#/$defs/Person"}},
+		},
+		"required": []interface{}{"lead"},
+		"$defs": map[string]interface{}{
+			"Person": map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"name":    map[string]interface{}{"type": "string"},
+					"email":   map[string]interface{}{"type": "string", "format": "email"},
+					"reports": map[string]interface{}{"type": "array", "items": map[string]interface{}{"$ref": "#/$defs/Person"}},
+				},
+				"required":          []interface{}{"name"},
+				"patternProperties": map[string]interface{}{"^x-": map[string]interface{}{"type": "string"}},
+			},
+		},
+	}
+}
+
+// RunSchemaRefsTest adapts a schema with $refs and checks the provider requests built from it
+func RunSchemaRefsTest() bool {
+	log.Printf("\n🧬 Test: Tool Schemas with $defs and $ref")
+	passed := true
+
+	schema := schemaRefsParameters()
+	adapted, changes := jsonschema.Adapt(schema, jsonschema.Compat{
+		InlineRefs:  true,
+		Unsupported: []string{"patternProperties"},
+		Formats:     []string{"date-time"},
+	})
+	for _, change := range changes {
+		log.Printf("   %s", change)
+	}
+	adaptedJSON, _ := json.Marshal(adapted)
+	if strings.Contains(string(adaptedJSON), "$ref") || strings.Contains(string(adaptedJSON), "$defs") ||
+		strings.Contains(string(adaptedJSON), "patternProperties") || strings.Contains(string(adaptedJSON), `"format"`) {
+		log.Printf("❌ Adapted schema still has $ref, $defs or stripped keywords: %s", adaptedJSON)
+		passed = false
+	} else {
+		log.Printf("✅ Adapted schema: %s", adaptedJSON)
+	}
+	if _, ok := schema["$defs"]; !ok {
+		log.Printf("❌ Adapt modified the original schema")
+		passed = false
+	}
+
+	arguments := []byte(`{"lead": {"name": "Ada", "email": "ada@example.com", "reports": [{"name": "Alan"}]}, "members": [{"name": "Grace"}]}`)
+	if violations := jsonschema.ValidateJSON(adapted, arguments); len(violations) > 0 {
+		log.Printf("❌ Adapted schema rejects valid arguments: %v", violations)
+		passed = false
+	} else if violations := jsonschema.ValidateJSON(adapted, []byte(`{"lead": {"email": "no name"}}`)); len(violations) == 0 {
+		log.Printf("❌ Adapted schema lost the required fields of the inlined definition")
+		passed = false
+	} else {
+		log.Printf("✅ Adapted schema accepts the same arguments and keeps required fields")
+	}
+
+	tools := []llmtypes.Tool{{
+		Type: "function",
+		Function: &llmtypes.FunctionDefinition{
+			Name:        "create_team",
+			Description: "Create a team",
+			Parameters:  llmtypes.NewParameters(schemaRefsParameters()),
+		},
+	}}
+	dryRun := func(provider llmproviders.Provider, modelID string, keys *llmproviders.ProviderAPIKeys) string {
+		llm, err := llmproviders.InitializeLLM(llmproviders.Config{Provider: provider, ModelID: modelID, APIKeys: keys})
+		if err != nil {
+			log.Printf("❌ %s initialization failed: %v", provider, err)
+			return ""
+		}
+		resp, err := llm.GenerateContent(context.Background(), []llmtypes.MessageContent{
+			llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "Create the platform team"),
+		}, llmtypes.WithTools(tools), llmtypes.WithDryRun())
+		if err != nil || resp == nil || !resp.DryRun {
+			log.Printf("❌ %s dry run failed: %v", provider, err)
+			return ""
+		}
+		request, _ := json.Marshal(resp.Raw)
+		return string(request)
+	}
+
+	apiKey := "dry-run"
+	gemini := dryRun(llmproviders.ProviderVertex, "gemini-2.5-flash", &llmproviders.ProviderAPIKeys{Vertex: &apiKey})
+	if gemini == "" || strings.Contains(gemini, "$ref") || strings.Contains(gemini, `"format":"email"`) || !strings.Contains(gemini, `"reports"`) {
+		log.Printf("❌ Gemini request does not carry the inlined schema: %s", gemini)
+		passed = false
+	} else {
+		log.Printf("✅ Gemini request has the schema inlined")
+	}
+
+	openaiRequest := dryRun(llmproviders.ProviderOpenAI, "gpt-4.1", &llmproviders.ProviderAPIKeys{OpenAI: &apiKey})
+	if openaiRequest == "" || !strings.Contains(openaiRequest, `"$ref":"#/$defs/Person"`) {
+		log.Printf("❌ OpenAI request does not keep $ref: %s", openaiRequest)
+		passed = false
+	} else {
+		log.Printf("✅ OpenAI request keeps $defs and $ref")
+	}
+	return passed
+}
@@ -0,0 +1,92 @@
+package shared
+
+import (
+	"context"
+	"log"
+	"os"
+	"strings"
+
+	llmproviders "github.com/manishiitg/multi-llm-provider-go"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+
+	"github.com/spf13/cobra"
+)
+
+// ServiceTierTestCmd checks that WithServiceTier is sent and the tier used is reported
+var ServiceTierTestCmd = &cobra.Command{
+	Use:   "service-tier",
+	Short: "Test that WithServiceTier is sent to OpenAI and Anthropic and the tier used is reported",
+	Long: `This test sends OpenAI and Anthropic calls to a local server and checks that:
+- OpenAI is sent the tier as service_tier unchanged
+- Anthropic is sent "auto" for "auto"/"priority" and "standard_only" for "default"/"standard"
+- tiers Anthropic does not support, and calls without a tier, send no service_tier
+- the tier reported by the provider is returned in GenerationInfo.Additional["service_tier"]
+
+No API calls are made.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !RunServiceTierTest() {
+			os.Exit(1)
+		}
+	},
+}
+
+// RunServiceTierTest verifies the service tier sent to and reported by each provider
+func RunServiceTierTest() bool {
+	log.Printf("\n🎟️  Test: Service Tier")
+
+	apiKey := "test"
+	keys := &llmproviders.ProviderAPIKeys{OpenAI: &apiKey, Anthropic: &apiKey}
+	messages := []llmtypes.MessageContent{llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "Hello")}
+	openAIResponse := strings.Replace(openAIHelloResponse, `"model":"gpt-4.1"`, `"model":"gpt-4.1","service_tier":"flex"`, 1)
+	anthropicResponse := strings.Replace(anthropicHelloResponse, `"output_tokens":1`, `"output_tokens":1,"service_tier":"priority"`, 1)
+
+	passed := true
+	for _, c := range []struct {
+		provider     llmproviders.Provider
+		modelID      string
+		response     string
+		tier         string
+		wantSent     string
+		wantReported string
+	}{
+		{llmproviders.ProviderOpenAI, "gpt-4.1", openAIResponse, "flex", `"service_tier":"flex"`, "flex"},
+		{llmproviders.ProviderOpenAI, "gpt-4.1", openAIHelloResponse, "", "", ""},
+		{llmproviders.ProviderAnthropic, "claude-sonnet-4-20250514", anthropicResponse, "priority", `"service_tier":"auto"`, "priority"},
+		{llmproviders.ProviderAnthropic, "claude-sonnet-4-20250514", anthropicHelloResponse, "default", `"service_tier":"standard_only"`, ""},
+		{llmproviders.ProviderAnthropic, "claude-sonnet-4-20250514", anthropicHelloResponse, "flex", "", ""},
+	} {
+		var options []llmtypes.CallOption
+		if c.tier != "" {
+			options = append(options, llmproviders.WithServiceTier(c.tier))
+		}
+		server := newRecordingServer(c.response)
+		llm, err := llmproviders.InitializeLLM(llmproviders.Config{Provider: c.provider, ModelID: c.modelID, APIKeys: keys, HTTPClient: server.client()})
+		var resp *llmtypes.ContentResponse
+		if err == nil {
+			resp, err = llm.GenerateContent(context.Background(), messages, options...)
+		}
+		server.Close()
+		if err != nil || resp == nil || len(resp.Choices) == 0 {
+			log.Printf("❌ %s tier %q: call failed: %v", c.provider, c.tier, err)
+			passed = false
+			continue
+		}
+
+		sent := ""
+		if start := strings.Index(server.body, `"service_tier":`); start >= 0 {
+			sent = server.body[start:]
+			sent = sent[:strings.IndexAny(sent, ",}")]
+		}
+		reported := ""
+		if info := resp.Choices[0].GenerationInfo; info != nil {
+			reported, _ = info.Additional["service_tier"].(string)
+		}
+		if sent != c.wantSent || reported != c.wantReported {
+			log.Printf("❌ %s tier %q: expected %q sent and %q reported, got %q and %q", c.provider, c.tier, c.wantSent, c.wantReported, sent, reported)
+			passed = false
+			continue
+		}
+		log.Printf("✅ %s tier %q: sent %q, reported %q", c.provider, c.tier, sent, reported)
+	}
+	return passed
+}
@@ -0,0 +1,155 @@
+package shared
+
+import (
+	"context"
+	"log"
+	"os"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	llmproviders "github.com/manishiitg/multi-llm-provider-go"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+
+	"github.com/spf13/cobra"
+)
+
+// SingleFlightTestCmd checks that WithSingleFlight coalesces identical concurrent calls
+var SingleFlightTestCmd = &cobra.Command{
+	Use:   "single-flight",
+	Short: "Test that identical concurrent calls with WithSingleFlight make one upstream call",
+	Long: `This test issues the same prompt from several goroutines at once with
+WithSingleFlight against a counting fake model, and checks that only one
+upstream call is made and every caller receives the response, also with a retry
+policy or a tool result summarizer set. It also checks that calls without the
+option, with different prompts, or with options holding functions (interceptors,
+validators, message transforms, a Retryable) are not coalesced, and that the
+first caller cancelling doesn't fail the others.
+
+No API calls are made.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !RunSingleFlightTest() {
+			os.Exit(1)
+		}
+	},
+}
+
+// countingModel is a fake model that counts calls and answers after a delay
+type countingModel struct {
+	fakeModelID
+	calls atomic.Int32
+	delay time.Duration
+}
+
+func (m *countingModel) GenerateContent(ctx context.Context, messages []llmtypes.MessageContent, options ...llmtypes.CallOption) (*llmtypes.ContentResponse, error) {
+	m.calls.Add(1)
+	select {
+	case <-time.After(m.delay):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	return textResponse("pong"), nil
+}
+
+func (m *countingModel) Call(ctx context.Context, prompt string, options ...llmtypes.CallOption) (string, error) {
+	return "pong", nil
+}
+
+// RunSingleFlightTest issues concurrent calls against a counting fake model and verifies how
+// many reach it with and without WithSingleFlight
+func RunSingleFlightTest() bool {
+	log.Printf("\n🔗 Test: Single-Flight Request Deduplication")
+
+	const callers = 10
+	run := func(prompt func(i int) string, options ...llmtypes.CallOption) (int32, int) {
+		model := &countingModel{delay: 200 * time.Millisecond}
+		llm := llmproviders.NewProviderAwareLLM(model, llmproviders.ProviderOpenAI, "fake-model", nil, "single-flight-test", nil)
+		var wg sync.WaitGroup
+		var answered atomic.Int32
+		for i := 0; i < callers; i++ {
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				messages := []llmtypes.MessageContent{llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, prompt(i))}
+				resp, err := llm.GenerateContent(context.Background(), messages, options...)
+				if err == nil && resp != nil && len(resp.Choices) == 1 && resp.Choices[0].Content == "pong" {
+					answered.Add(1)
+				}
+			}(i)
+		}
+		wg.Wait()
+		return model.calls.Load(), int(answered.Load())
+	}
+
+	samePrompt := func(int) string { return "ping" }
+	checks := []struct {
+		name          string
+		prompt        func(i int) string
+		options       []llmtypes.CallOption
+		expectedCalls int32
+	}{
+		{"identical calls with WithSingleFlight", samePrompt, []llmtypes.CallOption{llmtypes.WithSingleFlight(), llmtypes.WithTemperature(0)}, 1},
+		{"identical calls with a retry policy", samePrompt, []llmtypes.CallOption{llmtypes.WithSingleFlight(), llmtypes.WithRetryPolicy(llmtypes.RetryPolicy{
+			BaseDelay: time.Millisecond,
+		})}, 1},
+		{"identical calls with a tool result summarizer", samePrompt, []llmtypes.CallOption{llmtypes.WithSingleFlight(), llmtypes.WithToolResultSummarizer(&countingModel{})}, 1},
+		{"identical calls with a Retryable", samePrompt, []llmtypes.CallOption{llmtypes.WithSingleFlight(), llmtypes.WithRetryPolicy(llmtypes.RetryPolicy{
+			Retryable: func(error) bool { return true },
+		})}, callers},
+		{"identical calls with a request interceptor", samePrompt, []llmtypes.CallOption{llmtypes.WithSingleFlight(), llmtypes.WithRequestInterceptor(func(req *llmtypes.ProviderRequest) error {
+			return nil
+		})}, callers},
+		{"identical calls with a response validator", samePrompt, []llmtypes.CallOption{llmtypes.WithSingleFlight(), llmtypes.WithResponseValidator(func(resp *llmtypes.ContentResponse) error {
+			return nil
+		})}, callers},
+		{"identical calls with a message transform", samePrompt, []llmtypes.CallOption{llmtypes.WithSingleFlight(), llmtypes.WithMessageTransform(func(messages []llmtypes.MessageContent) []llmtypes.MessageContent {
+			return messages
+		})}, callers},
+		{"identical calls without WithSingleFlight", samePrompt, []llmtypes.CallOption{llmtypes.WithTemperature(0)}, callers},
+		{"different prompts with WithSingleFlight", func(i int) string { return "ping " + string(rune('a'+i)) }, []llmtypes.CallOption{llmtypes.WithSingleFlight()}, callers},
+	}
+
+	passed := true
+	for _, check := range checks {
+		calls, answered := run(check.prompt, check.options...)
+		if calls != check.expectedCalls || answered != callers {
+			log.Printf("❌ %s: %d upstream calls (expected %d), %d/%d callers answered", check.name, calls, check.expectedCalls, answered, callers)
+			passed = false
+			continue
+		}
+		log.Printf("✅ %s: %d upstream calls for %d callers", check.name, calls, callers)
+	}
+
+	// The first caller leaves before the answer; the shared call must still answer the others
+	model := &countingModel{delay: 200 * time.Millisecond}
+	llm := llmproviders.NewProviderAwareLLM(model, llmproviders.ProviderOpenAI, "fake-model", nil, "single-flight-test", nil)
+	messages := []llmtypes.MessageContent{llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "ping")}
+	leaderCtx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	leaderErr := make(chan error, 1)
+	go func() {
+		_, err := llm.GenerateContent(leaderCtx, messages, llmtypes.WithSingleFlight())
+		leaderErr <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+	var wg sync.WaitGroup
+	var answered atomic.Int32
+	for i := 1; i < callers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := llm.GenerateContent(context.Background(), messages, llmtypes.WithSingleFlight())
+			if err == nil && resp != nil && len(resp.Choices) == 1 && resp.Choices[0].Content == "pong" {
+				answered.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+	if err := <-leaderErr; err == nil || model.calls.Load() != 1 || answered.Load() != callers-1 {
+		log.Printf("❌ cancelled first caller: %d upstream calls, first caller error %v, %d/%d other callers answered", model.calls.Load(), err, answered.Load(), callers-1)
+		passed = false
+	} else {
+		log.Printf("✅ cancelled first caller: got %v, %d/%d other callers answered from 1 upstream call", err, answered.Load(), callers-1)
+	}
+	return passed
+}
@@ -0,0 +1,127 @@
+package shared
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	llmproviders "github.com/manishiitg/multi-llm-provider-go"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+
+	"github.com/spf13/cobra"
+)
+
+// StreamAggregatorTestCmd checks that StreamAggregator rebuilds responses from streamed chunks
+var StreamAggregatorTestCmd = &cobra.Command{
+	Use:   "stream-aggregator",
+	Short: "Test that StreamAggregator rebuilds the response from streamed chunks",
+	Long: `This test feeds chunks to a StreamAggregator and checks that:
+- chunks of interleaved choices are grouped by ChoiceIndex, in order
+- usage and heartbeat chunks are ignored and the usage of finish chunks is summed
+- Finished only reports true once every choice seen has a finish chunk
+- tool calls are copied, so changing a streamed tool call afterwards does not change the response
+- an empty stream gives a single empty choice
+- aggregating the stream of a call gives the response the call returns
+
+No API calls are made.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !RunStreamAggregatorTest() {
+			os.Exit(1)
+		}
+	},
+}
+
+// describeChoices renders the choices of resp one per line, for comparisons
+func describeChoices(resp *llmtypes.ContentResponse) string {
+	var lines []string
+	for i, choice := range resp.Choices {
+		var calls []string
+		for _, call := range choice.ToolCalls {
+			calls = append(calls, call.FunctionCall.Name+call.FunctionCall.Arguments)
+		}
+		lines = append(lines, fmt.Sprintf("%d: %q calls=[%s] stop=%s blocks=[%s]",
+			i, choice.Content, strings.Join(calls, " "), choice.StopReason, blockOrder(choice.OrderedBlocks())))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// RunStreamAggregatorTest verifies the responses StreamAggregator rebuilds from chunks
+func RunStreamAggregatorTest() bool {
+	log.Printf("\n🧩 Test: Stream Aggregator")
+
+	passed := true
+	search := llmtypes.ToolCall{ID: "call_1", Type: "function", FunctionCall: &llmtypes.FunctionCall{Name: "search", Arguments: `{"q":"go"}`}}
+	aggregator := llmtypes.NewStreamAggregator()
+	finishedEarly := false
+	for _, chunk := range []llmtypes.StreamChunk{
+		{Type: llmtypes.StreamChunkTypeContent, Content: "Hel"},
+		{Type: llmtypes.StreamChunkTypeContent, Content: "Bon", ChoiceIndex: 1},
+		{Type: llmtypes.StreamChunkTypeHeartbeat},
+		{Type: llmtypes.StreamChunkTypeReasoning, Content: "greet"},
+		{Type: llmtypes.StreamChunkTypeContent, Content: "lo"},
+		{Type: llmtypes.StreamChunkTypeToolCall, ToolCall: &search, ChoiceIndex: 1},
+		{Type: llmtypes.StreamChunkTypeUsage, Usage: &llmtypes.Usage{InputTokens: 100, OutputTokens: 100, TotalTokens: 200}},
+		{Type: llmtypes.StreamChunkTypeFinish, StopReason: "stop", Usage: &llmtypes.Usage{InputTokens: 5, OutputTokens: 2, TotalTokens: 7}},
+		{Type: llmtypes.StreamChunkTypeFinish, StopReason: "tool_calls", ChoiceIndex: 1, Usage: &llmtypes.Usage{InputTokens: 5, OutputTokens: 3, TotalTokens: 8}},
+	} {
+		aggregator.Add(chunk)
+		finishedEarly = finishedEarly || (aggregator.Finished() && chunk.ChoiceIndex == 0)
+	}
+	search.FunctionCall.Arguments = `{"q":"rust"}`
+
+	want := strings.Join([]string{
+		`0: "Hello" calls=[] stop=stop blocks=[text:Hel | reasoning:greet | text:lo]`,
+		`1: "Bon" calls=[search{"q":"go"}] stop=tool_calls blocks=[text:Bon | tool_call:search]`,
+	}, "\n")
+	resp := aggregator.Response()
+	if got := describeChoices(resp); got != want {
+		log.Printf("❌ Interleaved choices: expected\n%s\ngot\n%s", want, got)
+		passed = false
+	} else {
+		log.Printf("✅ Interleaved choices are grouped by ChoiceIndex and tool calls are copied")
+	}
+	if usage := resp.Usage; usage == nil || usage.InputTokens != 10 || usage.OutputTokens != 5 || usage.TotalTokens != 15 {
+		log.Printf("❌ Expected the finish chunks' usage summed to 10/5/15, got %+v", usage)
+		passed = false
+	} else {
+		log.Printf("✅ Usage is summed across finish chunks, running usage is ignored")
+	}
+	if finishedEarly || !aggregator.Finished() {
+		log.Printf("❌ Finished should only be true after the last choice finishes (early %t, at the end %t)", finishedEarly, aggregator.Finished())
+		passed = false
+	} else {
+		log.Printf("✅ Finished waits for every choice seen")
+	}
+
+	empty := llmtypes.NewStreamAggregator()
+	if resp := empty.Response(); empty.Finished() || len(resp.Choices) != 1 || resp.Choices[0].Content != "" || resp.Usage != nil {
+		log.Printf("❌ An empty stream should give one empty unfinished choice without usage, got %+v", resp)
+		passed = false
+	} else {
+		log.Printf("✅ An empty stream gives one empty choice")
+	}
+
+	// The stream of a call aggregates to the response the call returns
+	choice := &llmtypes.ContentChoice{Content: "Let me search.", StopReason: "tool_calls", ToolCalls: []llmtypes.ToolCall{
+		{ID: "call_2", Type: "function", FunctionCall: &llmtypes.FunctionCall{Name: "search", Arguments: `{"q":"go"}`}},
+	}}
+	llm := newFakeLLM(fakeModelFunc(func(ctx context.Context, messages []llmtypes.MessageContent, opts *llmtypes.CallOptions) (*llmtypes.ContentResponse, error) {
+		return streamChoice(opts, choice, "Let ", "me ", "search."), nil
+	}), llmproviders.ProviderOpenAI)
+	stream := make(chan llmtypes.StreamChunk, 16)
+	messages := []llmtypes.MessageContent{llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "Search for go")}
+	resp, err := llm.GenerateContent(context.Background(), messages, llmtypes.WithStreamingChan(stream))
+	if err != nil {
+		log.Printf("❌ Streaming call failed: %v", err)
+		return false
+	}
+	if got, want := describeChoices(llmtypes.AggregateStream(stream)), describeChoices(resp); got != want {
+		log.Printf("❌ The aggregated stream should match the response: expected\n%s\ngot\n%s", want, got)
+		passed = false
+	} else {
+		log.Printf("✅ The aggregated stream of a call matches its response: %s", want)
+	}
+	return passed
+}
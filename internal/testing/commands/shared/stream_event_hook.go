@@ -0,0 +1,169 @@
+package shared
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+
+	llmproviders "github.com/manishiitg/multi-llm-provider-go"
+	"github.com/manishiitg/multi-llm-provider-go/internal/testing"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+	bedrockadapter "github.com/manishiitg/multi-llm-provider-go/pkg/adapters/bedrock"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/aws/protocol/eventstream"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
+	"github.com/openai/openai-go/v3"
+	"github.com/spf13/cobra"
+)
+
+// StreamEventHookTestCmd checks that WithStreamEventHook receives the raw provider stream events
+var StreamEventHookTestCmd = &cobra.Command{
+	Use:   "stream-event-hook",
+	Short: "Test that WithStreamEventHook receives every raw provider stream event",
+	Long: `This test streams from local fake OpenAI and Bedrock servers with WithStreamEventHook and checks:
+- the hook receives every OpenAI chat completion chunk, unparsed
+- the hook receives every Bedrock ConverseStream event as its member type, including each
+  tool use input fragment before it is accumulated
+- the streamed response is unchanged by the hook
+
+No API calls are made.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !RunStreamEventHookTest() {
+			os.Exit(1)
+		}
+	},
+}
+
+// RunStreamEventHookTest verifies WithStreamEventHook on OpenAI and Bedrock streams
+func RunStreamEventHookTest() bool {
+	log.Printf("\n🪝 Test: Stream Event Hook")
+
+	passed := true
+	messages := []llmtypes.MessageContent{llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "What is the weather in Paris?")}
+
+	// OpenAI: one hook call per SSE chunk
+	openaiServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, delta := range []string{`{"role":"assistant","content":"Sunny"}`, `{"content":" in"}`, `{"content":" Paris"}`} {
+			fmt.Fprintf(w, "data: {\"id\":\"chatcmpl-1\",\"object\":\"chat.completion.chunk\",\"created\":1,\"model\":\"gpt-4.1\",\"choices\":[{\"index\":0,\"delta\":%s}]}\n\n", delta)
+		}
+		fmt.Fprint(w, "data: {\"id\":\"chatcmpl-1\",\"object\":\"chat.completion.chunk\",\"created\":1,\"model\":\"gpt-4.1\",\"choices\":[{\"index\":0,\"delta\":{},\"finish_reason\":\"stop\"}]}\n\n")
+		fmt.Fprint(w, "data: [DONE]\n\n")
+	}))
+	defer openaiServer.Close()
+	target, _ := url.Parse(openaiServer.URL)
+
+	apiKey := "test"
+	llm, err := llmproviders.InitializeLLM(llmproviders.Config{
+		Provider:   llmproviders.ProviderOpenAI,
+		ModelID:    "gpt-4.1",
+		APIKeys:    &llmproviders.ProviderAPIKeys{OpenAI: &apiKey},
+		HTTPClient: &http.Client{Transport: redirectTransport{target: target}},
+	})
+	if err != nil {
+		log.Printf("❌ Failed to initialize OpenAI: %v", err)
+		return false
+	}
+	var openaiEvents []interface{}
+	streamChan := make(chan llmtypes.StreamChunk, 100)
+	resp, err := llm.GenerateContent(context.Background(), messages, llmtypes.WithStreamingChan(streamChan),
+		llmtypes.WithStreamEventHook(func(event interface{}) { openaiEvents = append(openaiEvents, event) }))
+	switch {
+	case err != nil:
+		log.Printf("❌ OpenAI stream failed: %v", err)
+		passed = false
+	case resp.Choices[0].Content != "Sunny in Paris":
+		log.Printf("❌ OpenAI response changed: %q", resp.Choices[0].Content)
+		passed = false
+	case len(openaiEvents) != 4:
+		log.Printf("❌ Expected 4 OpenAI events, got %d", len(openaiEvents))
+		passed = false
+	default:
+		if chunk, ok := openaiEvents[1].(openai.ChatCompletionChunk); !ok || chunk.Choices[0].Delta.Content != " in" {
+			log.Printf("❌ Expected a raw ChatCompletionChunk with \" in\", got %T %+v", openaiEvents[1], openaiEvents[1])
+			passed = false
+		} else {
+			log.Printf("✅ OpenAI: hook received %d raw chat completion chunks", len(openaiEvents))
+		}
+	}
+
+	// Bedrock: one hook call per ConverseStream event, tool use fragments included
+	bedrockEvents := []struct{ eventType, payload string }{
+		{"messageStart", `{"role":"assistant"}`},
+		{"contentBlockStart", `{"contentBlockIndex":0,"start":{"toolUse":{"toolUseId":"tooluse_1","name":"get_weather"}}}`},
+		{"contentBlockDelta", `{"contentBlockIndex":0,"delta":{"toolUse":{"input":"{\"city\":"}}}`},
+		{"contentBlockDelta", `{"contentBlockIndex":0,"delta":{"toolUse":{"input":"\"Paris\"}"}}}`},
+		{"contentBlockStop", `{"contentBlockIndex":0}`},
+		{"messageStop", `{"stopReason":"tool_use"}`},
+		{"metadata", `{"usage":{"inputTokens":10,"outputTokens":5,"totalTokens":15},"metrics":{"latencyMs":1}}`},
+	}
+	bedrockServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/vnd.amazon.eventstream")
+		encoder := eventstream.NewEncoder()
+		for _, event := range bedrockEvents {
+			_ = encoder.Encode(w, eventstream.Message{
+				Headers: eventstream.Headers{
+					{Name: ":message-type", Value: eventstream.StringValue("event")},
+					{Name: ":event-type", Value: eventstream.StringValue(event.eventType)},
+					{Name: ":content-type", Value: eventstream.StringValue("application/json")},
+				},
+				Payload: []byte(event.payload),
+			})
+		}
+	}))
+	defer bedrockServer.Close()
+
+	client := bedrockruntime.New(bedrockruntime.Options{
+		Region:       "us-east-1",
+		Credentials:  aws.AnonymousCredentials{},
+		BaseEndpoint: aws.String(bedrockServer.URL),
+	})
+	adapter := bedrockadapter.NewBedrockAdapter(client, "anthropic.claude-3-haiku-20240307-v1:0", testing.GetTestLogger())
+	tool := llmtypes.Tool{Type: "function", Function: &llmtypes.FunctionDefinition{
+		Name:       "get_weather",
+		Parameters: llmtypes.NewParameters(map[string]interface{}{"type": "object", "properties": map[string]interface{}{"city": map[string]interface{}{"type": "string"}}}),
+	}}
+	var hooked []interface{}
+	streamChan = make(chan llmtypes.StreamChunk, 100)
+	resp, err = adapter.GenerateContent(context.Background(), messages, llmtypes.WithStreamingChan(streamChan), llmtypes.WithTools([]llmtypes.Tool{tool}),
+		llmtypes.WithStreamEventHook(func(event interface{}) { hooked = append(hooked, event) }))
+	if err != nil {
+		log.Printf("❌ Bedrock stream failed: %v", err)
+		return false
+	}
+	if len(resp.Choices[0].ToolCalls) != 1 || resp.Choices[0].ToolCalls[0].FunctionCall.Arguments != `{"city":"Paris"}` {
+		log.Printf("❌ Bedrock response changed: %+v", resp.Choices[0].ToolCalls)
+		passed = false
+	}
+	if len(hooked) != len(bedrockEvents) {
+		log.Printf("❌ Expected %d Bedrock events, got %d", len(bedrockEvents), len(hooked))
+		return false
+	}
+	var fragments []string
+	for _, event := range hooked {
+		if delta, ok := event.(*types.ConverseStreamOutputMemberContentBlockDelta); ok {
+			if toolUse, ok := delta.Value.Delta.(*types.ContentBlockDeltaMemberToolUse); ok && toolUse.Value.Input != nil {
+				fragments = append(fragments, *toolUse.Value.Input)
+			}
+		}
+	}
+	if strings.Join(fragments, "|") != `{"city":|"Paris"}` {
+		log.Printf("❌ Expected the raw tool use input fragments, got %q", fragments)
+		passed = false
+	}
+	if _, ok := hooked[len(hooked)-1].(*types.ConverseStreamOutputMemberMetadata); !ok {
+		log.Printf("❌ Expected the last Bedrock event to be metadata, got %T", hooked[len(hooked)-1])
+		passed = false
+	}
+	if passed {
+		log.Printf("✅ Bedrock: hook received %d raw ConverseStream events, tool use input fragments %q", len(hooked), fragments)
+	}
+	return passed
+}
@@ -0,0 +1,142 @@
+package shared
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+
+	llmproviders "github.com/manishiitg/multi-llm-provider-go"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+
+	openaisdk "github.com/openai/openai-go/v3"
+	"github.com/spf13/cobra"
+)
+
+// StreamFallbackTestCmd checks WithStreamFallback against a provider rejecting streaming
+var StreamFallbackTestCmd = &cobra.Command{
+	Use:   "stream-fallback",
+	Short: "Test WithStreamFallback for calls the provider cannot stream",
+	Long: `This test checks, against a local fake OpenAI server, that:
+- a streaming call the server rejects fails with ErrStreamingUnsupported by default
+- with WithStreamFallback(true) it is repeated without streaming and the response arrives on
+  the stream channel as whole chunks, and the channel is closed
+- with WithStreamFallback(true), calls the server can stream still stream normally
+
+No API calls are made.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !RunStreamFallbackTest() {
+			os.Exit(1)
+		}
+	},
+}
+
+// RunStreamFallbackTest verifies the streaming fallback and the typed error
+func RunStreamFallbackTest() bool {
+	log.Printf("\n📴 Test: Stream Fallback")
+
+	var requests, streamRequests int
+	rejectStreaming := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		body, _ := io.ReadAll(r.Body)
+		streaming := strings.Contains(string(body), `"stream":true`)
+		switch {
+		case streaming && rejectStreaming:
+			streamRequests++
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			fmt.Fprint(w, `{"error":{"message":"Your organization must be verified to stream this model.","type":"invalid_request_error","param":"stream","code":"unsupported_value"}}`)
+		case streaming:
+			streamRequests++
+			w.Header().Set("Content-Type", "text/event-stream")
+			for _, delta := range []string{`{"role":"assistant","content":"Hel"}`, `{"content":"lo"}`} {
+				fmt.Fprintf(w, "data: {\"id\":\"chatcmpl-1\",\"object\":\"chat.completion.chunk\",\"created\":1,\"model\":\"gpt-4.1\",\"choices\":[{\"index\":0,\"delta\":%s}]}\n\n", delta)
+			}
+			fmt.Fprint(w, "data: {\"id\":\"chatcmpl-1\",\"object\":\"chat.completion.chunk\",\"created\":1,\"model\":\"gpt-4.1\",\"choices\":[{\"index\":0,\"delta\":{},\"finish_reason\":\"stop\"}]}\n\ndata: [DONE]\n\n")
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"id":"chatcmpl-1","object":"chat.completion","created":1,"model":"gpt-4.1","choices":[{"index":0,"message":{"role":"assistant","content":"Hello"},"finish_reason":"stop"}]}`)
+		}
+	}))
+	defer server.Close()
+	target, _ := url.Parse(server.URL)
+
+	apiKey := "test"
+	llm, err := llmproviders.InitializeLLM(llmproviders.Config{
+		Provider:   llmproviders.ProviderOpenAI,
+		ModelID:    "gpt-4.1",
+		APIKeys:    &llmproviders.ProviderAPIKeys{OpenAI: &apiKey},
+		HTTPClient: &http.Client{Transport: redirectTransport{target: target}},
+	})
+	if err != nil {
+		log.Printf("❌ Failed to initialize OpenAI: %v", err)
+		return false
+	}
+	messages := []llmtypes.MessageContent{llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "Hi")}
+
+	// stream makes a streaming call and collects the chunks until the channel is closed
+	stream := func(options ...llmtypes.CallOption) (*llmtypes.ContentResponse, []llmtypes.StreamChunk, error) {
+		requests, streamRequests = 0, 0
+		streamChan := make(chan llmtypes.StreamChunk, 100)
+		done := make(chan []llmtypes.StreamChunk)
+		go func() {
+			var chunks []llmtypes.StreamChunk
+			for chunk := range streamChan {
+				chunks = append(chunks, chunk)
+			}
+			done <- chunks
+		}()
+		resp, err := llm.GenerateContent(context.Background(), messages, append(options, llmtypes.WithStreamingChan(streamChan))...)
+		return resp, <-done, err
+	}
+	content := func(chunks []llmtypes.StreamChunk) (string, bool) {
+		var sb strings.Builder
+		finished := false
+		for _, chunk := range chunks {
+			switch chunk.Type {
+			case llmtypes.StreamChunkTypeContent:
+				sb.WriteString(chunk.Content)
+			case llmtypes.StreamChunkTypeFinish:
+				finished = true
+			}
+		}
+		return sb.String(), finished
+	}
+
+	passed := true
+	_, _, err = stream()
+	var sdkErr *openaisdk.Error
+	if !errors.Is(err, llmproviders.ErrStreamingUnsupported) || !errors.As(err, &sdkErr) || requests != 1 {
+		log.Printf("❌ Without WithStreamFallback: expected one request and ErrStreamingUnsupported, got %d requests, err %v", requests, err)
+		passed = false
+	} else {
+		log.Printf("✅ Without WithStreamFallback the call fails with ErrStreamingUnsupported: %v", err)
+	}
+
+	resp, chunks, err := stream(llmproviders.WithStreamFallback(true))
+	text, finished := content(chunks)
+	if err != nil || resp == nil || resp.Choices[0].Content != "Hello" || text != "Hello" || !finished || requests != 2 || streamRequests != 1 {
+		log.Printf("❌ WithStreamFallback: expected a rejected streaming request, a plain request and \"Hello\" streamed as a whole, got %d requests, chunks %q (finish %v), err %v", requests, text, finished, err)
+		passed = false
+	} else {
+		log.Printf("✅ WithStreamFallback retries without streaming and streams the response as %d whole chunks", len(chunks))
+	}
+
+	rejectStreaming = false
+	resp, chunks, err = stream(llmproviders.WithStreamFallback(true))
+	text, finished = content(chunks)
+	if err != nil || resp == nil || resp.Choices[0].Content != "Hello" || text != "Hello" || !finished || requests != 1 || streamRequests != 1 {
+		log.Printf("❌ WithStreamFallback on a streaming model: expected one streamed request, got %d requests, chunks %q (finish %v), err %v", requests, text, finished, err)
+		passed = false
+	} else {
+		log.Printf("✅ WithStreamFallback leaves streaming models streaming (%d chunks)", len(chunks))
+	}
+	return passed
+}
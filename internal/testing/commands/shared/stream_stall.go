@@ -0,0 +1,130 @@
+package shared
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	llmproviders "github.com/manishiitg/multi-llm-provider-go"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+
+	"github.com/spf13/cobra"
+)
+
+// StreamStallTestCmd checks WithStreamStallTimeout and WithStreamHeartbeat
+var StreamStallTestCmd = &cobra.Command{
+	Use:   "stream-stall",
+	Short: "Test that stalled streams are cancelled and quiet streams send heartbeats",
+	Long: `This test uses a fake model that pauses between stream chunks and checks that:
+- a pause longer than WithStreamStallTimeout cancels the call with ErrStreamStalled,
+  returning the content streamed before the stall
+- pauses within the timeout leave the call untouched
+- WithStreamHeartbeat sends heartbeat chunks during pauses, which are not part of the response
+- heartbeats still reach the caller with WithStreamTextOnly
+
+No API calls are made.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !RunStreamStallTest() {
+			os.Exit(1)
+		}
+	},
+}
+
+// pausingModel streams its pieces, waiting the matching delay before each one, and stops
+// when the context is cancelled
+type pausingModel struct {
+	fakeModelID
+	pieces []string
+	delays []time.Duration
+}
+
+func (m *pausingModel) GenerateContent(ctx context.Context, messages []llmtypes.MessageContent, options ...llmtypes.CallOption) (*llmtypes.ContentResponse, error) {
+	opts := callOptions(options)
+	for i, piece := range m.pieces {
+		select {
+		case <-time.After(m.delays[i]):
+		case <-ctx.Done():
+			close(opts.StreamChan)
+			return nil, ctx.Err()
+		}
+		opts.StreamChan <- llmtypes.StreamChunk{Type: llmtypes.StreamChunkTypeContent, Content: piece}
+	}
+	opts.StreamChan <- llmtypes.StreamChunk{Type: llmtypes.StreamChunkTypeFinish, StopReason: "stop"}
+	close(opts.StreamChan)
+	return textResponse(strings.Join(m.pieces, "")), nil
+}
+
+// RunStreamStallTest verifies stall detection and heartbeats of streaming calls
+func RunStreamStallTest() bool {
+	log.Printf("\n💓 Test: Stream Stall and Heartbeat")
+
+	messages := []llmtypes.MessageContent{llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "Tell me a story.")}
+	// generate streams the pieces and returns the response, the streamed content and the
+	// number of heartbeats received
+	generate := func(pieces []string, delays []time.Duration, options ...llmtypes.CallOption) (*llmtypes.ContentResponse, string, int, error) {
+		model := &pausingModel{pieces: pieces, delays: delays}
+		llm := newFakeLLM(model, llmproviders.ProviderOpenAI)
+		streamChan := make(chan llmtypes.StreamChunk, 100)
+		var streamed strings.Builder
+		heartbeats := 0
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for chunk := range streamChan {
+				switch chunk.Type {
+				case llmtypes.StreamChunkTypeContent:
+					streamed.WriteString(chunk.Content)
+				case llmtypes.StreamChunkTypeHeartbeat:
+					heartbeats++
+				}
+			}
+		}()
+		resp, err := llm.GenerateContent(context.Background(), messages, append(options, llmtypes.WithStreamingChan(streamChan))...)
+		<-done
+		return resp, streamed.String(), heartbeats, err
+	}
+	pieces := []string{"Once upon", " a time"}
+	ms := time.Millisecond
+
+	passed := true
+	resp, _, _, err := generate(pieces, []time.Duration{0, 500 * ms}, llmproviders.WithStreamStallTimeout(100*ms))
+	var stallErr *llmproviders.StreamStalledError
+	switch {
+	case !errors.Is(err, llmproviders.ErrStreamStalled) || !errors.As(err, &stallErr):
+		log.Printf("❌ Expected ErrStreamStalled, got %v", err)
+		passed = false
+	case resp == nil || resp.Choices[0].Content != "Once upon" || stallErr.Partial != resp:
+		log.Printf("❌ Expected the content streamed before the stall, got %+v", resp)
+		passed = false
+	default:
+		log.Printf("✅ Stalled stream cancelled with the partial content %q: %v", resp.Choices[0].Content, err)
+	}
+
+	resp, _, _, err = generate(pieces, []time.Duration{0, 20 * ms}, llmproviders.WithStreamStallTimeout(200*ms))
+	if err != nil || resp.Choices[0].Content != "Once upon a time" {
+		log.Printf("❌ Expected a stream within the stall timeout to complete, got %+v (error %v)", resp, err)
+		passed = false
+	} else {
+		log.Printf("✅ Stream within the stall timeout completed")
+	}
+
+	for _, c := range []struct {
+		name    string
+		options []llmtypes.CallOption
+	}{
+		{"heartbeats", []llmtypes.CallOption{llmproviders.WithStreamHeartbeat(50 * ms)}},
+		{"heartbeats with text only", []llmtypes.CallOption{llmproviders.WithStreamHeartbeat(50 * ms), llmproviders.WithStreamTextOnly()}},
+	} {
+		resp, streamed, heartbeats, err := generate(pieces, []time.Duration{0, 300 * ms}, c.options...)
+		if err != nil || heartbeats < 2 || streamed != "Once upon a time" || resp.Choices[0].Content != streamed {
+			log.Printf("❌ %s: expected heartbeats during the pause and unchanged content, got %d heartbeats, streamed %q (error %v)", c.name, heartbeats, streamed, err)
+			passed = false
+			continue
+		}
+		log.Printf("✅ %s: %d sent during the pause", c.name, heartbeats)
+	}
+	return passed
+}
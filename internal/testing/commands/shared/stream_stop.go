@@ -0,0 +1,136 @@
+package shared
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"sync"
+	"sync/atomic"
+
+	llmproviders "github.com/manishiitg/multi-llm-provider-go"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+
+	"github.com/spf13/cobra"
+)
+
+// StreamStopTestCmd checks StreamContent and StreamHandle.Stop
+var StreamStopTestCmd = &cobra.Command{
+	Use:   "stream-stop",
+	Short: "Test that StreamHandle.Stop halts a streaming call and returns its partial response",
+	Long: `This test checks, against a local fake OpenAI server that stalls mid-stream, that
+StreamHandle.Stop:
+- halts the request and closes the stream channel
+- returns the content streamed so far without an error
+- can be called concurrently and repeatedly with the same result
+- returns the full response when the call has already completed
+
+No API calls are made.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !RunStreamStopTest() {
+			os.Exit(1)
+		}
+	},
+}
+
+// RunStreamStopTest verifies stopping streaming calls through their handle
+func RunStreamStopTest() bool {
+	log.Printf("\n⏹️ Test: Stream Stop")
+
+	var stall atomic.Bool
+	stall.Store(true)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		for _, delta := range []string{`{"role":"assistant","content":"Once upon"}`, `{"content":" a time"}`} {
+			fmt.Fprintf(w, "data: {\"id\":\"chatcmpl-1\",\"object\":\"chat.completion.chunk\",\"created\":1,\"model\":\"gpt-4.1\",\"choices\":[{\"index\":0,\"delta\":%s}]}\n\n", delta)
+		}
+		w.(http.Flusher).Flush()
+		if stall.Load() {
+			<-r.Context().Done()
+			return
+		}
+		fmt.Fprint(w, "data: {\"id\":\"chatcmpl-1\",\"object\":\"chat.completion.chunk\",\"created\":1,\"model\":\"gpt-4.1\",\"choices\":[{\"index\":0,\"delta\":{},\"finish_reason\":\"stop\"}]}\n\ndata: [DONE]\n\n")
+	}))
+	defer server.Close()
+	target, _ := url.Parse(server.URL)
+
+	apiKey := "test"
+	model, err := llmproviders.InitializeLLM(llmproviders.Config{
+		Provider:   llmproviders.ProviderOpenAI,
+		ModelID:    "gpt-4.1",
+		APIKeys:    &llmproviders.ProviderAPIKeys{OpenAI: &apiKey},
+		HTTPClient: &http.Client{Transport: redirectTransport{target: target}},
+	})
+	if err != nil {
+		log.Printf("❌ Failed to initialize OpenAI: %v", err)
+		return false
+	}
+	llm, ok := model.(*llmproviders.ProviderAwareLLM)
+	if !ok {
+		log.Printf("❌ InitializeLLM returned %T, not a ProviderAwareLLM", model)
+		return false
+	}
+	messages := []llmtypes.MessageContent{llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "Tell me a story.")}
+
+	passed := true
+
+	// Stop from several goroutines once the server has stalled
+	streamChan := make(chan llmtypes.StreamChunk, 100)
+	handle := llm.StreamContent(context.Background(), messages, streamChan)
+	var streamed string
+	for chunk := range streamChan {
+		streamed += chunk.Content
+		if streamed == "Once upon a time" {
+			break
+		}
+	}
+	type result struct {
+		resp *llmtypes.ContentResponse
+		err  error
+	}
+	results := make([]result, 3)
+	var wg sync.WaitGroup
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			resp, err := handle.Stop()
+			results[i] = result{resp, err}
+		}(i)
+	}
+	wg.Wait()
+	_, open := <-streamChan
+	for i, r := range results {
+		if r.err != nil || r.resp == nil || r.resp.Choices[0].Content != streamed || r.resp != results[0].resp {
+			log.Printf("❌ Stop %d: expected the partial response %q and no error, got %+v, err %v", i, streamed, r.resp, r.err)
+			passed = false
+		}
+	}
+	if open {
+		log.Printf("❌ The stream channel is still open after Stop")
+		passed = false
+	}
+	if passed {
+		log.Printf("✅ Concurrent Stop calls returned the partial response %q and closed the channel", streamed)
+	}
+
+	// Stop after completion returns the full response
+	stall.Store(false)
+	streamChan = make(chan llmtypes.StreamChunk, 100)
+	handle = llm.StreamContent(context.Background(), messages, streamChan)
+	for range streamChan {
+	}
+	<-handle.Done()
+	resp, err := handle.Stop()
+	if err != nil || resp == nil || resp.Choices[0].Content != "Once upon a time" || resp.Choices[0].StopReason == "" {
+		log.Printf("❌ Stop after completion: expected the full response, got %+v, err %v", resp, err)
+		passed = false
+	} else {
+		log.Printf("✅ Stop after completion returns the full response (stop reason %q)", resp.Choices[0].StopReason)
+	}
+
+	return passed
+}
@@ -0,0 +1,134 @@
+package shared
+
+import (
+	"context"
+	"log"
+	"os"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	llmproviders "github.com/manishiitg/multi-llm-provider-go"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+	"github.com/manishiitg/multi-llm-provider-go/pkg/utils"
+
+	"github.com/spf13/cobra"
+)
+
+// StreamUTF8TestCmd checks that multibyte characters split across stream chunks survive
+var StreamUTF8TestCmd = &cobra.Command{
+	Use:   "stream-utf8",
+	Short: "Test that emoji and CJK split across stream chunks are reassembled exactly",
+	Long: `This test uses a fake model that streams emoji and CJK text cut every few bytes, splitting
+UTF-8 sequences across chunks, and checks that:
+- the streamed content is byte-identical to the non-streaming result, also with stream
+  buffering, reasoning tag stripping and text-only streaming
+- StreamAggregator responses are valid UTF-8 after every chunk and exact once finished
+- a stream cancelled mid-character returns valid UTF-8 partial content
+- utils.TruncateUTF8 never splits a character
+
+No API calls are made.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !RunStreamUTF8Test() {
+			os.Exit(1)
+		}
+	},
+}
+
+// RunStreamUTF8Test verifies the reassembly of multibyte characters split across chunks
+func RunStreamUTF8Test() bool {
+	log.Printf("\n🈶 Test: Stream UTF-8")
+
+	text := "Hello 👋 世界! <think>考え中 🤔</think>你好。Ça va? Bye 🎉\n"
+	var pieces []string
+	for i := 0; i < len(text); i += 3 {
+		pieces = append(pieces, text[i:min(i+3, len(text))])
+	}
+	messages := []llmtypes.MessageContent{llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "Say hello in three languages.")}
+	generate := func(options ...llmtypes.CallOption) (*llmtypes.ContentResponse, string, error) {
+		llm := newFakeLLM(&chunkedContentModel{pieces: pieces}, llmproviders.ProviderOpenAI)
+		streamChan := make(chan llmtypes.StreamChunk, 100)
+		var streamed strings.Builder
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			for chunk := range streamChan {
+				if chunk.Type == llmtypes.StreamChunkTypeContent {
+					streamed.WriteString(chunk.Content)
+				}
+			}
+		}()
+		resp, err := llm.GenerateContent(context.Background(), messages, append(options, llmtypes.WithStreamingChan(streamChan))...)
+		<-done
+		return resp, streamed.String(), err
+	}
+
+	passed := true
+	stripped := strings.Replace(text, "<think>考え中 🤔</think>", "", 1)
+	for _, c := range []struct {
+		name    string
+		options []llmtypes.CallOption
+		want    string
+	}{
+		{"plain stream", nil, text},
+		{"sentence buffering", []llmtypes.CallOption{llmproviders.WithStreamBuffering(llmtypes.StreamBufferingSentence)}, text},
+		{"line buffering", []llmtypes.CallOption{llmproviders.WithStreamBuffering(llmtypes.StreamBufferingLine)}, text},
+		{"reasoning tags stripped", []llmtypes.CallOption{llmproviders.WithStripReasoningTags([]string{"think"})}, stripped},
+		{"text only", []llmtypes.CallOption{llmproviders.WithStreamTextOnly()}, text},
+	} {
+		resp, streamed, err := generate(c.options...)
+		if err != nil || streamed != c.want || resp.Choices[0].Content != streamed {
+			log.Printf("❌ %s: expected %q, streamed %q (error %v)", c.name, c.want, streamed, err)
+			passed = false
+			continue
+		}
+		log.Printf("✅ %s: streamed content is byte-identical to the response", c.name)
+	}
+
+	aggregator := llmtypes.NewStreamAggregator()
+	valid := true
+	for _, piece := range pieces {
+		aggregator.Add(llmtypes.StreamChunk{Type: llmtypes.StreamChunkTypeContent, Content: piece})
+		resp := aggregator.Response()
+		if !utf8.ValidString(resp.Choices[0].Content) || !utf8.ValidString(resp.Choices[0].Blocks[0].Text) {
+			valid = false
+		}
+	}
+	aggregator.Add(llmtypes.StreamChunk{Type: llmtypes.StreamChunkTypeFinish, StopReason: "stop"})
+	if resp := aggregator.Response(); !valid || resp.Choices[0].Content != text {
+		log.Printf("❌ Expected valid UTF-8 after every chunk and %q once finished, got %q", text, resp.Choices[0].Content)
+		passed = false
+	} else {
+		log.Printf("✅ Aggregated content is valid UTF-8 after every chunk and exact once finished")
+	}
+
+	// "Hello 👋" cut inside the emoji, then a stall
+	model := &pausingModel{pieces: []string{"Hello \xf0\x9f", "\x91\x8b"}, delays: []time.Duration{0, 500 * time.Millisecond}}
+	llm := newFakeLLM(model, llmproviders.ProviderOpenAI)
+	streamChan := make(chan llmtypes.StreamChunk, 100)
+	go func() {
+		for range streamChan {
+		}
+	}()
+	resp, err := llm.GenerateContent(context.Background(), messages, llmtypes.WithStreamingChan(streamChan), llmproviders.WithStreamStallTimeout(100*time.Millisecond))
+	if err == nil || resp == nil || resp.Choices[0].Content != "Hello " {
+		log.Printf("❌ Expected the partial content %q without the split emoji, got %+v (error %v)", "Hello ", resp, err)
+		passed = false
+	} else {
+		log.Printf("✅ Stream cancelled mid-character returned %q", resp.Choices[0].Content)
+	}
+
+	truncated := true
+	for n := 0; n <= len(text); n++ {
+		if cut := utils.TruncateUTF8(text, n); !utf8.ValidString(cut) || !strings.HasPrefix(text, cut) || len(cut) > n || len(cut) < n-3 {
+			truncated = false
+		}
+	}
+	if !truncated {
+		log.Printf("❌ utils.TruncateUTF8 split a character or cut too much")
+		passed = false
+	} else {
+		log.Printf("✅ utils.TruncateUTF8 keeps every prefix valid UTF-8")
+	}
+	return passed
+}
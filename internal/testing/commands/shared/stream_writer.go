@@ -0,0 +1,145 @@
+package shared
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"log"
+	"os"
+	"strings"
+	"time"
+
+	llmproviders "github.com/manishiitg/multi-llm-provider-go"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+
+	"github.com/spf13/cobra"
+)
+
+// StreamWriterTestCmd checks that StreamToWriter writes streamed text to an io.Writer
+var StreamWriterTestCmd = &cobra.Command{
+	Use:   "stream-to-writer",
+	Short: "Test streaming a response to an io.Writer",
+	Long: `This test checks that StreamToWriter:
+- writes every content chunk to the writer and flushes buffered writers
+- returns the complete response
+- stops the request when a write fails or the context is canceled
+
+No API calls are made.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !RunStreamWriterTest() {
+			os.Exit(1)
+		}
+	},
+}
+
+// chunkedTextModel is a fake model that streams words one chunk at a time, waiting delay
+// between chunks, and stops when ctx is canceled
+type chunkedTextModel struct {
+	fakeModelID
+	words    []string
+	delay    time.Duration
+	canceled bool
+}
+
+func (m *chunkedTextModel) GenerateContent(ctx context.Context, messages []llmtypes.MessageContent, options ...llmtypes.CallOption) (*llmtypes.ContentResponse, error) {
+	opts := callOptions(options)
+	if opts.StreamChan != nil {
+		defer close(opts.StreamChan)
+	}
+	var content strings.Builder
+	for _, word := range m.words {
+		select {
+		case <-ctx.Done():
+			m.canceled = true
+			return nil, ctx.Err()
+		case <-time.After(m.delay):
+		}
+		content.WriteString(word)
+		if opts.StreamChan != nil {
+			opts.StreamChan <- llmtypes.StreamChunk{Type: llmtypes.StreamChunkTypeContent, Content: word}
+		}
+	}
+	return textResponse(content.String()), nil
+}
+
+func (m *chunkedTextModel) Call(ctx context.Context, prompt string, options ...llmtypes.CallOption) (string, error) {
+	return "", nil
+}
+
+// failingWriter accepts limit bytes and then fails
+type failingWriter struct {
+	limit int
+}
+
+var errWriterFull = errors.New("writer full")
+
+func (w *failingWriter) Write(b []byte) (int, error) {
+	if len(b) > w.limit {
+		return 0, errWriterFull
+	}
+	w.limit -= len(b)
+	return len(b), nil
+}
+
+// RunStreamWriterTest verifies the text written by StreamToWriter and how it stops
+func RunStreamWriterTest() bool {
+	log.Printf("\n🖨️  Test: Stream To Writer")
+
+	messages := []llmtypes.MessageContent{llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "Count to five")}
+	words := []string{"one ", "two ", "three ", "four ", "five"}
+	newLLM := func(model *chunkedTextModel) *llmproviders.ProviderAwareLLM {
+		return llmproviders.NewProviderAwareLLM(model, llmproviders.ProviderOpenAI, "fake-model", nil, "stream-writer-test", nil)
+	}
+
+	passed := true
+	var out bytes.Buffer
+	// A large bufio.Writer only reaches out if StreamToWriter flushes it
+	buffered := bufio.NewWriterSize(&out, 4096)
+	var seenMidStream bool
+	model := &chunkedTextModel{words: words}
+	resp, err := newLLM(model).StreamToWriter(context.Background(), flushingWriter{write: func(b []byte) (int, error) {
+		seenMidStream = seenMidStream || out.Len() > 0
+		return buffered.Write(b)
+	}, flush: buffered.Flush}, messages)
+	if err != nil || resp == nil || resp.Choices[0].Content != strings.Join(words, "") || out.String() != strings.Join(words, "") || !seenMidStream {
+		log.Printf("❌ Expected %q written and returned, got %q (flushed mid-stream: %v, err: %v)", strings.Join(words, ""), out.String(), seenMidStream, err)
+		passed = false
+	} else {
+		log.Printf("✅ Streamed text written and flushed: %q", out.String())
+	}
+
+	model = &chunkedTextModel{words: words, delay: 10 * time.Millisecond}
+	if _, err := newLLM(model).StreamToWriter(context.Background(), &failingWriter{limit: 8}, messages); !errors.Is(err, errWriterFull) || !model.canceled {
+		log.Printf("❌ Expected a failed write to cancel the request, got err %v (canceled: %v)", err, model.canceled)
+		passed = false
+	} else {
+		log.Printf("✅ Failed write cancels the request: %v", err)
+	}
+
+	model = &chunkedTextModel{words: words, delay: 20 * time.Millisecond}
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	out.Reset()
+	if _, err := newLLM(model).StreamToWriter(ctx, &out, messages); err == nil || out.Len() == 0 || out.String() == strings.Join(words, "") {
+		log.Printf("❌ Expected a canceled context to stop the stream part way, got %q (err: %v)", out.String(), err)
+		passed = false
+	} else {
+		log.Printf("✅ Canceled context stops the stream after %q", out.String())
+	}
+	return passed
+}
+
+// flushingWriter is an io.Writer with a Flush method built from functions
+type flushingWriter struct {
+	write func([]byte) (int, error)
+	flush func() error
+}
+
+func (w flushingWriter) Write(b []byte) (int, error) {
+	return w.write(b)
+}
+
+func (w flushingWriter) Flush() error {
+	return w.flush()
+}
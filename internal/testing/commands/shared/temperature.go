@@ -0,0 +1,97 @@
+package shared
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"os"
+	"strings"
+
+	llmproviders "github.com/manishiitg/multi-llm-provider-go"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+
+	"github.com/spf13/cobra"
+)
+
+// TemperatureTestCmd checks how temperatures are adjusted to what each model accepts
+var TemperatureTestCmd = &cobra.Command{
+	Use:   "temperature",
+	Short: "Test that temperatures are omitted or clamped for models that don't accept them",
+	Long: `This test builds requests with WithDryRun and checks that:
+- OpenAI o-series and gpt-5 models (also through OpenRouter) are sent no temperature
+- other OpenAI models are sent the temperature, clamped to 2
+- Anthropic and Claude requests are clamped to 1, Gemini requests to 2
+
+No API calls are made.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !RunTemperatureTest() {
+			os.Exit(1)
+		}
+	},
+}
+
+// RunTemperatureTest verifies the temperature of requests built for each provider
+func RunTemperatureTest() bool {
+	log.Printf("\n🌡️  Test: Temperature Handling")
+
+	apiKey := "dry-run"
+	keys := &llmproviders.ProviderAPIKeys{OpenAI: &apiKey, OpenRouter: &apiKey, Anthropic: &apiKey, Vertex: &apiKey}
+	messages := []llmtypes.MessageContent{llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "Hello")}
+	dryRun := func(provider llmproviders.Provider, modelID string, temperature float64) string {
+		llm, err := llmproviders.InitializeLLM(llmproviders.Config{Provider: provider, ModelID: modelID, APIKeys: keys})
+		if err != nil {
+			log.Printf("❌ %s initialization failed: %v", provider, err)
+			return ""
+		}
+		resp, err := llm.GenerateContent(context.Background(), messages, llmtypes.WithTemperature(temperature), llmtypes.WithDryRun())
+		if err != nil || resp == nil || !resp.DryRun {
+			log.Printf("❌ %s dry run failed: %v", provider, err)
+			return ""
+		}
+		request, _ := json.Marshal(resp.Raw)
+		return string(request)
+	}
+
+	passed := true
+	for _, c := range []struct {
+		provider    llmproviders.Provider
+		modelID     string
+		temperature float64
+		want        string // temperature in the request, "" for none
+	}{
+		{llmproviders.ProviderOpenAI, "o3-mini", 0.2, ""},
+		{llmproviders.ProviderOpenAI, "o4-mini", 0.2, ""},
+		{llmproviders.ProviderOpenAI, "o1", 0.5, ""},
+		{llmproviders.ProviderOpenAI, "gpt-5-mini", 0.5, ""},
+		{llmproviders.ProviderOpenRouter, "openai/o3", 0.2, ""},
+		{llmproviders.ProviderOpenAI, "gpt-4.1", 0.3, `"temperature":0.3`},
+		{llmproviders.ProviderOpenAI, "gpt-4o", 3.5, `"temperature":2`},
+		{llmproviders.ProviderAnthropic, "claude-sonnet-4-20250514", 1.5, `"temperature":1`},
+		{llmproviders.ProviderAnthropic, "claude-sonnet-4-20250514", 0.7, `"temperature":0.7`},
+		{llmproviders.ProviderVertex, "gemini-2.5-flash", 2.5, `"temperature":2`},
+	} {
+		request := dryRun(c.provider, c.modelID, c.temperature)
+		if request == "" {
+			passed = false
+			continue
+		}
+		hasTemperature := strings.Contains(request, `"temperature"`)
+		switch {
+		case c.want == "" && hasTemperature:
+			log.Printf("❌ %s %s should be sent no temperature: %s", c.provider, c.modelID, request)
+			passed = false
+		case c.want != "" && !strings.Contains(request, c.want):
+			log.Printf("❌ %s %s should be sent %s: %s", c.provider, c.modelID, c.want, request)
+			passed = false
+		case c.want == "":
+			log.Printf("✅ %s %s: temperature %.1f omitted", c.provider, c.modelID, c.temperature)
+		default:
+			log.Printf("✅ %s %s: temperature %.1f sent as %s", c.provider, c.modelID, c.temperature, c.want)
+		}
+	}
+	if !llmproviders.IsO3O4Model("o3-mini") || !llmproviders.IsO3O4Model("openai/o4-mini") || llmproviders.IsO3O4Model("gpt-4o") {
+		log.Printf("❌ IsO3O4Model misclassified o3-mini, openai/o4-mini or gpt-4o")
+		passed = false
+	}
+	return passed
+}
@@ -7,15 +7,15 @@ import (
 	"errors"
 	"fmt"
 	"log"
-	"mime"
 	"os"
-	"path/filepath"
 	"strings"
 	"sync"
 	"time"
 
+	llmproviders "github.com/manishiitg/multi-llm-provider-go"
 	"github.com/manishiitg/multi-llm-provider-go/interfaces"
 	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+	"github.com/manishiitg/multi-llm-provider-go/pkg/llmtest"
 )
 
 // RunEmbeddingTest runs embedding generation tests
@@ -98,6 +98,10 @@ func testSingleEmbedding(ctx context.Context, embeddingModel llmtypes.EmbeddingM
 		expectedDims = 1536 // Amazon Titan v1 default dimensions
 	} else if strings.Contains(modelID, "titan-embed-text-v2") {
 		expectedDims = 1024 // Amazon Titan v2 default dimensions
+	} else if strings.Contains(modelID, "cohere.embed-english-v3") || strings.Contains(modelID, "cohere.embed-multilingual-v3") {
+		expectedDims = 1024 // Cohere embed v3 dimensions
+	} else if strings.Contains(modelID, "cohere.embed-v4") {
+		expectedDims = 1536 // Cohere embed v4 default dimensions
 	}
 
 	if len(embedding.Embedding) != expectedDims {
@@ -147,15 +151,16 @@ func testEmbeddingWithDimensions(ctx context.Context, embeddingModel llmtypes.Em
 	// Test dimensions for models that support it
 	// OpenAI: text-embedding-3 models
 	// Vertex AI: text-embedding-004 and newer models
-	// Bedrock: titan-embed-text-v2 (v1 doesn't support custom dimensions)
+	// Bedrock: titan-embed-text-v2 and cohere.embed-v4 (Titan v1 and Cohere v3 don't support custom dimensions)
 	supportsDimensions := strings.Contains(modelID, "text-embedding-3") ||
 		strings.Contains(modelID, "text-embedding-004") ||
 		strings.Contains(modelID, "text-embedding-preview") ||
 		strings.Contains(modelID, "text-multilingual-embedding") ||
-		strings.Contains(modelID, "titan-embed-text-v2")
+		strings.Contains(modelID, "titan-embed-text-v2") ||
+		strings.Contains(modelID, "cohere.embed-v4")
 
 	if !supportsDimensions {
-		log.Printf("⏭️  Skipping dimensions test (only supported for text-embedding-3, text-embedding-004+, and titan-embed-text-v2 models)")
+		log.Printf("⏭️  Skipping dimensions test (only supported for text-embedding-3, text-embedding-004+, titan-embed-text-v2 and cohere.embed-v4 models)")
 		return
 	}
 
@@ -247,8 +252,8 @@ func validateRequiredToolArguments(tool llmtypes.Tool, toolCall llmtypes.ToolCal
 			if hasNoArgs {
 				// CRITICAL: Bedrock model did not provide ANY arguments
 				// This suggests the streaming/accumulation logic may not be working correctly
-				// Check debug logs for [BEDROCK STREAM] to see what Input values were received during streaming
-				return fmt.Errorf("CRITICAL: Bedrock model called tool with NO arguments (tool: %s, received args: %q). Check [BEDROCK STREAM] debug logs to see if Input was received during ContentBlockDeltaMemberToolUse events. This may indicate a streaming/accumulation issue rather than a model limitation", toolCall.FunctionCall.Name, argsStr)
+				// Check debug logs for [BEDROCK STREAM], or the raw events from WithStreamEventHook, to see what Input values were received during streaming
+				return fmt.Errorf("CRITICAL: Bedrock model called tool with NO arguments (tool: %s, received args: %q). Check [BEDROCK STREAM] debug logs, or the raw events passed to WithStreamEventHook, to see if Input was received during ContentBlockDeltaMemberToolUse events. This may indicate a streaming/accumulation issue rather than a model limitation", toolCall.FunctionCall.Name, argsStr)
 			} else {
 				// Bedrock provided some arguments but missing required ones
 				return fmt.Errorf("CRITICAL: Bedrock model called tool with missing required arguments: %s (tool: %s, received args: %s). Model provided some arguments but not all required ones", strings.Join(missingParams, ", "), toolCall.FunctionCall.Name, argsStr)
@@ -1302,6 +1307,11 @@ func RunStreamingMixedTest(llm llmtypes.Model, modelID string) {
 	log.Printf("      Final content length: %d chars", len(finalContent))
 	log.Printf("      Final tool calls: %d", len(finalToolCalls))
 	log.Printf("   📋 Chunk order: %v", chunkOrder)
+	var blockOrder []string
+	for _, block := range resp.Choices[0].OrderedBlocks() {
+		blockOrder = append(blockOrder, string(block.Type))
+	}
+	log.Printf("   📋 Response block order: %v", blockOrder)
 
 	// CRITICAL: Validate content streaming (if there's content, it must be streamed)
 	streamedContentStr := streamedContent.String()
@@ -1464,30 +1474,20 @@ func RunStreamingParallelToolCallsTest(llm llmtypes.Model, modelID string) {
 		return
 	}
 
-	// CRITICAL: Verify all tool calls were streamed
-	if len(streamedToolCalls) != len(finalToolCalls) {
-		log.Printf("❌ Test failed - streamed tool call count (%d) doesn't match final count (%d)", len(streamedToolCalls), len(finalToolCalls))
+	// CRITICAL: Verify all tool calls were streamed with the IDs, names and arguments of the response
+	if err := llmtest.CheckStreamedToolCalls(streamedToolCalls, finalToolCalls); err != nil {
+		log.Printf("❌ Test failed - %v", err)
 		log.Printf("      Model: %s", modelID)
 		log.Printf("      This indicates a streaming implementation bug")
 		return
 	}
-
-	// CRITICAL: Verify all tool call IDs match and validate required arguments
-	streamedIDs := make(map[string]*llmtypes.ToolCall)
-	for i := range streamedToolCalls {
-		streamedIDs[streamedToolCalls[i].ID] = &streamedToolCalls[i]
+	if err := llmtest.CheckParallelToolCalls(resp, len(finalToolCalls)); err != nil {
+		log.Printf("❌ Test failed - %v", err)
+		return
 	}
-	for _, finalTC := range finalToolCalls {
-		streamedTC, exists := streamedIDs[finalTC.ID]
-		if !exists {
-			log.Printf("❌ Test failed - tool call ID %s in final response but not in streamed tool calls", finalTC.ID)
-			return
-		}
-		if streamedTC.FunctionCall.Name != finalTC.FunctionCall.Name {
-			log.Printf("❌ Test failed - tool call name mismatch for ID %s: streamed=%s, final=%s", finalTC.ID, streamedTC.FunctionCall.Name, finalTC.FunctionCall.Name)
-			return
-		}
 
+	// CRITICAL: Validate required arguments for each tool call
+	for _, finalTC := range finalToolCalls {
 		// CRITICAL: Validate required arguments are present for each tool call
 		var toolToValidate llmtypes.Tool
 		if finalTC.FunctionCall.Name == "read_file" {
@@ -1665,7 +1665,7 @@ func RunStreamingCancellationTest(llm llmtypes.Model, modelID string) {
 	}()
 
 	startTime := time.Now()
-	_, err := llm.GenerateContent(ctx, messages,
+	resp, err := llm.GenerateContent(ctx, messages,
 		llmtypes.WithModel(modelID),
 		llmtypes.WithStreamingChan(streamChan),
 	)
@@ -1682,10 +1682,20 @@ func RunStreamingCancellationTest(llm llmtypes.Model, modelID string) {
 			strings.Contains(errStr, "context deadline exceeded")
 
 		if isCanceled {
+			// The partial response holds what was streamed before the cancellation
+			if resp == nil || len(resp.Choices) == 0 {
+				log.Printf("❌ Test failed: cancelled call returned no partial response")
+				return
+			}
+			if !strings.HasPrefix(resp.Choices[0].Content, streamedContent.String()) {
+				log.Printf("❌ Test failed: partial content %q does not start with the streamed content %q", resp.Choices[0].Content, streamedContent.String())
+				return
+			}
 			log.Printf("✅ Test passed in %s - cancellation handled correctly", duration)
 			log.Printf("   📊 Streaming stats:")
 			log.Printf("      Chunks received before cancellation: %d", chunksReceived)
 			log.Printf("      Streamed content length: %d chars", streamedContent.Len())
+			log.Printf("      Partial response length: %d chars", len(resp.Choices[0].Content))
 			log.Printf("      Error (expected): %v", err)
 		} else {
 			log.Printf("❌ Test failed with unexpected error: %v", err)
@@ -2980,8 +2990,11 @@ func RunStructuredOutputTest(llm llmtypes.Model, modelID string, useJSONMode boo
 			}
 		}
 	} else {
-		// Extract from content
+		// Extract from content, without any code fences or text around the JSON
 		content := strings.TrimSpace(choice.Content)
+		if document, err := llmproviders.ParseJSONFromContent(content); err == nil {
+			content = string(document)
+		}
 
 		// Try to parse as JSON array first
 		if err := json.Unmarshal([]byte(content), &recipes); err != nil {
@@ -3082,33 +3095,12 @@ func RunImageTest(llm llmtypes.Model, modelID string, imagePath, imageURL string
 			return
 		}
 
-		// Detect MIME type from file extension
-		ext := strings.ToLower(filepath.Ext(imagePath))
-		mediaType := mime.TypeByExtension(ext)
-		if mediaType == "" {
-			// Fallback to common types
-			switch ext {
-			case ".jpg", ".jpeg":
-				mediaType = "image/jpeg"
-			case ".png":
-				mediaType = "image/png"
-			case ".gif":
-				mediaType = "image/gif"
-			case ".webp":
-				mediaType = "image/webp"
-			default:
-				log.Printf("❌ Unsupported image format: %s. Supported: JPEG, PNG, GIF, WebP", ext)
-				return
-			}
-		}
-
-		// Encode to base64
+		// Encode to base64; the adapter detects the MIME type from the data
 		base64Data := base64.StdEncoding.EncodeToString(imageData)
-		log.Printf("✅ Image loaded: %d bytes, MIME type: %s", len(imageData), mediaType)
+		log.Printf("✅ Image loaded: %d bytes", len(imageData))
 
 		imageParts = append(imageParts, llmtypes.ImageContent{
 			SourceType: "base64",
-			MediaType:  mediaType,
 			Data:       base64Data,
 		})
 	} else if imageURL != "" {
@@ -3242,6 +3234,94 @@ func RunImageTest(llm llmtypes.Model, modelID string, imagePath, imageURL string
 	log.Printf("\n🎯 All image understanding tests completed successfully!")
 }
 
+// RunToolErrorTest verifies the model recovers when a tool result is marked as an error:
+// it should report the failure or retry the tool instead of inventing a result
+func RunToolErrorTest(ctx context.Context, llm llmtypes.Model, modelID string) {
+	log.Printf("\n📝 Test: Errored tool result (%s)", modelID)
+
+	weatherTool := llmtypes.Tool{
+		Type: "function",
+		Function: &llmtypes.FunctionDefinition{
+			Name:        "get_weather",
+			Description: "Get the current weather for a city",
+			Parameters: llmtypes.NewParameters(map[string]interface{}{
+				"type": "object",
+				"properties": map[string]interface{}{
+					"city": map[string]interface{}{
+						"type":        "string",
+						"description": "City name",
+					},
+				},
+				"required": []string{"city"},
+			}),
+		},
+	}
+
+	messages := []llmtypes.MessageContent{
+		llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "What is the weather in Paris right now?"),
+		{
+			Role: llmtypes.ChatMessageTypeAI,
+			Parts: []llmtypes.ContentPart{llmtypes.ToolCall{
+				ID:   "call_weather_1",
+				Type: "function",
+				FunctionCall: &llmtypes.FunctionCall{
+					Name:      "get_weather",
+					Arguments: `{"city":"Paris"}`,
+				},
+			}},
+		},
+		{
+			Role: llmtypes.ChatMessageTypeTool,
+			Parts: []llmtypes.ContentPart{llmtypes.ToolCallResponse{
+				ToolCallID: "call_weather_1",
+				Name:       "get_weather",
+				Content:    "weather service unavailable: upstream timeout after 30s",
+				IsError:    true,
+			}},
+		},
+	}
+
+	startTime := time.Now()
+	resp, err := llm.GenerateContent(ctx, messages,
+		llmtypes.WithModel(modelID),
+		llmtypes.WithTools([]llmtypes.Tool{weatherTool}),
+	)
+	duration := time.Since(startTime)
+
+	if err != nil {
+		log.Printf("❌ Errored tool result rejected by provider: %v", err)
+		return
+	}
+	if len(resp.Choices) == 0 {
+		log.Printf("❌ No choices returned")
+		return
+	}
+
+	choice := resp.Choices[0]
+	if len(choice.ToolCalls) > 0 {
+		log.Printf("✅ Model retried the failed tool in %s (%d tool call(s))", duration, len(choice.ToolCalls))
+		logTokenUsage(choice.GenerationInfo)
+		return
+	}
+
+	content := strings.ToLower(choice.Content)
+	acknowledged := false
+	for _, keyword := range []string{"error", "unavailable", "unable", "couldn't", "could not", "can't", "cannot", "timeout", "timed out", "sorry", "issue", "problem", "try again"} {
+		if strings.Contains(content, keyword) {
+			acknowledged = true
+			break
+		}
+	}
+	if !acknowledged {
+		log.Printf("⚠️  Response did not mention the tool failure: %s", choice.Content)
+		return
+	}
+
+	log.Printf("✅ Model acknowledged the tool failure in %s", duration)
+	log.Printf("   Content: %s", choice.Content)
+	logTokenUsage(choice.GenerationInfo)
+}
+
 // logTokenUsage logs token usage information
 func logTokenUsage(info *llmtypes.GenerationInfo) {
 	if info == nil {
@@ -3868,6 +3948,8 @@ type TestEventEmitter struct {
 	GenerationSuccessEvents     []map[string]interface{}
 	GenerationErrorEvents       []map[string]interface{}
 	ToolCallDetectedEvents      []map[string]interface{}
+	InputTrimmedEvents          []map[string]interface{}
+	ToolCallsTruncatedEvents    []map[string]interface{}
 	mu                          sync.Mutex
 }
 
@@ -3966,6 +4048,33 @@ func (e *TestEventEmitter) EmitToolCallDetected(provider string, modelID string,
 	})
 }
 
+func (e *TestEventEmitter) EmitInputTrimmed(provider string, modelID string, droppedMessages int, reclaimedTokens int, inputTokens int, traceID interfaces.TraceID, metadata interfaces.LLMMetadata) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.InputTrimmedEvents = append(e.InputTrimmedEvents, map[string]interface{}{
+		"provider":         provider,
+		"model_id":         modelID,
+		"dropped_messages": droppedMessages,
+		"reclaimed_tokens": reclaimedTokens,
+		"input_tokens":     inputTokens,
+		"trace_id":         string(traceID),
+		"metadata":         metadata,
+	})
+}
+
+func (e *TestEventEmitter) EmitToolCallsTruncated(provider string, modelID string, receivedToolCalls int, keptToolCalls int, traceID interfaces.TraceID, metadata interfaces.LLMMetadata) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.ToolCallsTruncatedEvents = append(e.ToolCallsTruncatedEvents, map[string]interface{}{
+		"provider":            provider,
+		"model_id":            modelID,
+		"received_tool_calls": receivedToolCalls,
+		"kept_tool_calls":     keptToolCalls,
+		"trace_id":            string(traceID),
+		"metadata":            metadata,
+	})
+}
+
 // RunToolCallEventTestWithContext tests that tool call events are emitted correctly
 func RunToolCallEventTestWithContext(ctx context.Context, llm llmtypes.Model, modelID string, eventEmitter interfaces.EventEmitter) {
 	log.Printf("🧪 Testing tool call events with model: %s", modelID)
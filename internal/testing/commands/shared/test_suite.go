@@ -113,6 +113,11 @@ func discoverTestFiles(baseDir string) ([]testCase, error) {
 			return err
 		}
 
+		// Fixtures are replayed by the fixtures command
+		if info.IsDir() && path != baseDir && info.Name() == "fixtures" {
+			return filepath.SkipDir
+		}
+
 		if !strings.HasSuffix(path, ".json") {
 			return nil
 		}
@@ -194,6 +199,12 @@ func initTestRegistry() {
 		return true, ""
 	})
 
+	// Register errored tool result tests
+	registerTest("tool_error", func(ctx context.Context, llm llmtypes.Model, modelID string, provider string, logger interfaces.Logger) (bool, string) {
+		RunToolErrorTest(ctx, llm, modelID)
+		return true, ""
+	})
+
 	// Register tool call events tests
 	registerTest("tool_call_events", func(ctx context.Context, _ llmtypes.Model, modelID string, provider string, logger interfaces.Logger) (bool, string) {
 		// Create test event emitter to capture events
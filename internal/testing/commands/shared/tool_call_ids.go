@@ -0,0 +1,151 @@
+package shared
+
+import (
+	"log"
+	"os"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+	"github.com/manishiitg/multi-llm-provider-go/pkg/adapters/vertex"
+	"github.com/manishiitg/multi-llm-provider-go/pkg/history"
+	"github.com/manishiitg/multi-llm-provider-go/pkg/utils"
+
+	"github.com/spf13/cobra"
+	"google.golang.org/genai"
+)
+
+// ToolCallIDsTestCmd checks that tool calls sent without IDs get unique, stable IDs
+var ToolCallIDsTestCmd = &cobra.Command{
+	Use:   "tool-call-ids",
+	Short: "Test ID generation for parallel tool calls that arrive without IDs",
+	Long: `This test converts a Gemini turn with two identical parallel function calls
+and no IDs (as older Gemini models send them) and checks that:
+- every call gets a non-empty, unique ID, and the same IDs on every conversion
+- the function responses are assigned the IDs of their calls
+- the results survive history normalization and convert back to Gemini
+- repeated provider IDs are made unique
+
+No API calls are made.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !RunToolCallIDsTest() {
+			os.Exit(1)
+		}
+	},
+}
+
+// RunToolCallIDsTest verifies generated tool call IDs are unique and round-trip
+func RunToolCallIDsTest() bool {
+	log.Printf("\n🎯 Test: Tool Call IDs")
+
+	args := map[string]any{"location": "Paris"}
+	contents := []*genai.Content{
+		{Role: "user", Parts: []*genai.Part{{Text: "Weather in Paris, twice please"}}},
+		{Role: "model", Parts: []*genai.Part{
+			{FunctionCall: &genai.FunctionCall{Name: "get_weather", Args: args}},
+			{FunctionCall: &genai.FunctionCall{Name: "get_weather", Args: args}},
+		}},
+		{Role: "user", Parts: []*genai.Part{
+			{FunctionResponse: &genai.FunctionResponse{Name: "get_weather", Response: map[string]any{"result": "sunny"}}},
+			{FunctionResponse: &genai.FunctionResponse{Name: "get_weather", Response: map[string]any{"result": "cloudy"}}},
+		}},
+	}
+
+	messages, err := vertex.FromGeminiContents(contents)
+	if err != nil || len(messages) != 3 {
+		log.Printf("❌ Conversion failed: %v (%d messages)", err, len(messages))
+		return false
+	}
+	passed := true
+
+	calls := toolCallsOf(messages[1])
+	if len(calls) != 2 || calls[0].ID == "" || calls[1].ID == "" || calls[0].ID == calls[1].ID {
+		log.Printf("❌ Expected two unique non-empty IDs, got %v", toolCallIDList(calls))
+		return false
+	}
+	log.Printf("✅ Generated IDs: %v", toolCallIDList(calls))
+
+	again, _ := vertex.FromGeminiContents(contents)
+	if againCalls := toolCallsOf(again[1]); len(againCalls) != 2 || againCalls[0].ID != calls[0].ID || againCalls[1].ID != calls[1].ID {
+		log.Printf("❌ IDs are not stable across conversions: %v vs %v", toolCallIDList(againCalls), toolCallIDList(calls))
+		passed = false
+	} else {
+		log.Printf("✅ IDs are stable across conversions")
+	}
+
+	var responseIDs []string
+	for _, part := range messages[2].Parts {
+		if resp, ok := part.(llmtypes.ToolCallResponse); ok {
+			responseIDs = append(responseIDs, resp.ToolCallID)
+		}
+	}
+	if len(responseIDs) != 2 || responseIDs[0] != calls[0].ID || responseIDs[1] != calls[1].ID {
+		log.Printf("❌ Responses %v don't match calls %v", responseIDs, toolCallIDList(calls))
+		passed = false
+	} else {
+		log.Printf("✅ Responses are assigned the IDs of their calls")
+	}
+
+	// Sending the history back must keep both results
+	normalized := history.NormalizeHistory(messages)
+	answered := 0
+	for _, part := range normalized[len(normalized)-1].Parts {
+		if resp, ok := part.(llmtypes.ToolCallResponse); ok && !resp.IsError {
+			answered++
+		}
+	}
+	functionResponses := 0
+	for _, content := range vertex.ToGeminiContents(normalized, "gemini-2.5-flash") {
+		for _, part := range content.Parts {
+			if part.FunctionResponse != nil {
+				functionResponses++
+			}
+		}
+	}
+	if answered != 2 || functionResponses != 2 {
+		log.Printf("❌ Round trip lost results: %d answered after normalization, %d function responses sent", answered, functionResponses)
+		passed = false
+	} else {
+		log.Printf("✅ Both results round-trip through normalization and Gemini conversion")
+	}
+
+	duplicated := []llmtypes.ToolCall{
+		{ID: "call_a", FunctionCall: &llmtypes.FunctionCall{Name: "search"}},
+		{ID: "call_a", FunctionCall: &llmtypes.FunctionCall{Name: "search"}},
+		{FunctionCall: &llmtypes.FunctionCall{Name: "search"}},
+	}
+	utils.EnsureToolCallIDs(duplicated)
+	seen := make(map[string]bool)
+	for _, call := range duplicated {
+		if call.ID == "" || seen[call.ID] {
+			log.Printf("❌ Duplicate provider IDs were not made unique: %v", toolCallIDList(duplicated))
+			return false
+		}
+		seen[call.ID] = true
+	}
+	if duplicated[0].ID != "call_a" {
+		log.Printf("❌ First provider ID was changed to %q", duplicated[0].ID)
+		passed = false
+	} else {
+		log.Printf("✅ Repeated provider IDs deduplicated: %v", toolCallIDList(duplicated))
+	}
+	return passed
+}
+
+// toolCallsOf returns the tool calls in msg
+func toolCallsOf(msg llmtypes.MessageContent) []llmtypes.ToolCall {
+	var calls []llmtypes.ToolCall
+	for _, part := range msg.Parts {
+		if call, ok := part.(llmtypes.ToolCall); ok {
+			calls = append(calls, call)
+		}
+	}
+	return calls
+}
+
+// toolCallIDList returns the IDs of calls
+func toolCallIDList(calls []llmtypes.ToolCall) []string {
+	ids := make([]string, 0, len(calls))
+	for _, call := range calls {
+		ids = append(ids, call.ID)
+	}
+	return ids
+}
@@ -0,0 +1,126 @@
+package shared
+
+import (
+	"context"
+	"log"
+	"os"
+	"strings"
+
+	llmproviders "github.com/manishiitg/multi-llm-provider-go"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+
+	"github.com/spf13/cobra"
+)
+
+// ToolEmulationTestCmd checks that WithToolEmulation lets models without native tools call tools
+var ToolEmulationTestCmd = &cobra.Command{
+	Use:   "tool-emulation",
+	Short: "Test that WithToolEmulation describes tools in the prompt and parses tagged tool calls",
+	Long: `This test generates with WithToolEmulation through fake models registered as gemma-3
+(no native tool calling) and gpt-4.1, and checks that:
+- the tools are removed from the request and described in the system prompt
+- tool calls and results in the history are rewritten as tagged text
+- tagged tool calls in the response are parsed into ToolCalls with IDs, and blocks naming
+  an unknown tool are left in the content
+- when streaming, the parsed content, tool calls and a finish chunk are streamed, without
+  the tagged blocks
+- a tool choice of none leaves the tools out of the prompt
+- models with native tool calling get the tools unchanged
+
+No API calls are made.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !RunToolEmulationTest() {
+			os.Exit(1)
+		}
+	},
+}
+
+// emulatedToolCallAnswer answers with a tool call of a known tool and one of an unknown tool
+const emulatedToolCallAnswer = "Let me check.\n" +
+	`<tool_call>{"name": "get_weather", "arguments": {"city": "Paris"}}</tool_call>` + "\n" +
+	`<tool_call>{"name": "get_time", "arguments": {}}</tool_call>`
+
+// RunToolEmulationTest verifies emulated tool calling
+func RunToolEmulationTest() bool {
+	log.Printf("\n🧰 Test: Tool Emulation")
+
+	ctx := context.Background()
+	tools := []llmtypes.Tool{{Type: "function", Function: &llmtypes.FunctionDefinition{Name: "get_weather", Description: "Get the weather in a city"}}}
+	call := toolCall("call_0", "get_weather", `{"city":"Rome"}`)
+	messages := []llmtypes.MessageContent{
+		llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "Weather in Rome, then Paris?"),
+		{Role: llmtypes.ChatMessageTypeAI, Parts: []llmtypes.ContentPart{call}},
+		{Role: llmtypes.ChatMessageTypeTool, Parts: []llmtypes.ContentPart{llmtypes.ToolCallResponse{ToolCallID: "call_0", Name: "get_weather", Content: "Sunny"}}},
+	}
+	passed := true
+
+	// A model answering text and recording the request it was sent
+	var sent []llmtypes.MessageContent
+	sentOpts := &llmtypes.CallOptions{}
+	answering := func(text string) llmtypes.Model {
+		return fakeModelFunc(func(ctx context.Context, messages []llmtypes.MessageContent, opts *llmtypes.CallOptions) (*llmtypes.ContentResponse, error) {
+			sent, sentOpts = messages, opts
+			return streamChoice(opts, &llmtypes.ContentChoice{Content: text, StopReason: "stop"}), nil
+		})
+	}
+	generate := func(model llmtypes.Model, modelID string, options ...llmtypes.CallOption) (*llmtypes.ContentResponse, error) {
+		llm := llmproviders.NewProviderAwareLLM(model, llmproviders.ProviderOpenRouter, modelID, nil, "tool-emulation-test", nil)
+		return llm.GenerateContent(ctx, messages, append([]llmtypes.CallOption{llmproviders.WithTools(tools), llmproviders.WithToolEmulation()}, options...)...)
+	}
+
+	// The request describes the tools and carries the history as text
+	resp, err := generate(answering(emulatedToolCallAnswer), "google/gemma-3-27b-it")
+	history := describeMessages(sent)
+	if err != nil || len(sentOpts.Tools) != 0 || sent[0].Role != llmtypes.ChatMessageTypeSystem || !strings.Contains(history, `"name":"get_weather"`) {
+		log.Printf("❌ Expected the tools described in a system message and none in the request, got %d tools and\n%s\n(error %v)", len(sentOpts.Tools), history, err)
+		passed = false
+	} else if !strings.Contains(history, `ai: text(<tool_call>{"name":"get_weather","arguments":{"city":"Rome"}}</tool_call>)`) ||
+		!strings.Contains(history, `human: text(<tool_result name="get_weather">Sunny</tool_result>)`) {
+		log.Printf("❌ Expected the tool call and result in the history as tagged text, got\n%s", history)
+		passed = false
+	} else {
+		log.Printf("✅ The tools are described in the system prompt and the history is sent as tagged text")
+	}
+
+	// The tagged tool call is parsed, the unknown tool's block is kept
+	if err == nil {
+		choice := resp.Choices[0]
+		if len(choice.ToolCalls) != 1 || choice.ToolCalls[0].ID == "" || choice.ToolCalls[0].FunctionCall.Arguments != `{"city": "Paris"}` ||
+			choice.StopReason != "tool_calls" || !strings.HasPrefix(choice.Content, "Let me check.") || strings.Contains(choice.Content, "get_weather") || !strings.Contains(choice.Content, "get_time") {
+			log.Printf("❌ Expected the get_weather call parsed and the get_time block kept, got %q and %d tool calls (%s)", choice.Content, len(choice.ToolCalls), choice.StopReason)
+			passed = false
+		} else {
+			log.Printf("✅ The response is parsed into %s(%s) with ID %s, the unknown tool's block is kept in the content", choice.ToolCalls[0].FunctionCall.Name, choice.ToolCalls[0].FunctionCall.Arguments, choice.ToolCalls[0].ID)
+		}
+	}
+
+	// Streaming sends the parsed response, never the tagged blocks
+	streamChan := make(chan llmtypes.StreamChunk, 10)
+	_, err = generate(answering("Let me check.\n"+`<tool_call>{"name": "get_weather", "arguments": {"city": "Paris"}}</tool_call>`), "google/gemma-3-27b-it", llmtypes.WithStreamingChan(streamChan))
+	stream := describeStream(streamChan)
+	if err != nil || sentOpts.StreamChan != nil || stream != "content(Let me check.) call(get_weather) finish(tool_calls)" {
+		log.Printf("❌ Streaming: expected the parsed content, tool call and finish chunk, got %s (error %v)", stream, err)
+		passed = false
+	} else {
+		log.Printf("✅ Streaming: %s", stream)
+	}
+
+	// A tool choice of none leaves the tools out of the prompt
+	_, err = generate(answering("Sunny in Rome."), "google/gemma-3-27b-it", llmtypes.WithToolChoiceString("none"))
+	if err != nil || sent[0].Role == llmtypes.ChatMessageTypeSystem {
+		log.Printf("❌ Expected no tool instruction with tool choice none, got\n%s\n(error %v)", describeMessages(sent), err)
+		passed = false
+	} else {
+		log.Printf("✅ A tool choice of none leaves the tools out of the prompt")
+	}
+
+	// Native tool calling is left alone
+	resp, err = generate(answering(emulatedToolCallAnswer), "gpt-4.1")
+	if err != nil || len(sentOpts.Tools) != 1 || len(resp.Choices[0].ToolCalls) != 0 || sent[0].Role == llmtypes.ChatMessageTypeSystem {
+		log.Printf("❌ Expected gpt-4.1 to get the tools natively, got %d tools (error %v)", len(sentOpts.Tools), err)
+		passed = false
+	} else {
+		log.Printf("✅ gpt-4.1 gets the tools natively and its response is not parsed")
+	}
+	return passed
+}
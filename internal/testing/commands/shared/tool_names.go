@@ -0,0 +1,166 @@
+package shared
+
+import (
+	"context"
+	"log"
+	"os"
+
+	llmproviders "github.com/manishiitg/multi-llm-provider-go"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+
+	"github.com/spf13/cobra"
+)
+
+// ToolNamesTestCmd checks that invalid tool names are sanitized and mapped back
+var ToolNamesTestCmd = &cobra.Command{
+	Use:   "tool-names",
+	Short: "Test sanitization of tool names that break provider naming rules",
+	Long: `This test sends tools with dotted, spaced and over-long names through a fake
+model and checks that:
+- the provider only sees names matching [a-zA-Z0-9_-]{1,64}
+- tool calls and tool results in the history are renamed the same way
+- tool calls in the response and on the stream carry the original names
+- WithToolNameSanitization(false) sends the names unchanged
+
+No API calls are made.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !RunToolNamesTest() {
+			os.Exit(1)
+		}
+	},
+}
+
+// toolNameModel is a fake model that records the tool names it receives and calls the
+// first tool
+type toolNameModel struct {
+	fakeModelID
+	tools   []string
+	history []string
+}
+
+func (m *toolNameModel) GenerateContent(ctx context.Context, messages []llmtypes.MessageContent, options ...llmtypes.CallOption) (*llmtypes.ContentResponse, error) {
+	opts := callOptions(options)
+	m.tools, m.history = nil, nil
+	for _, tool := range opts.Tools {
+		m.tools = append(m.tools, tool.Function.Name)
+	}
+	for _, msg := range messages {
+		for _, part := range msg.Parts {
+			switch p := part.(type) {
+			case llmtypes.ToolCall:
+				m.history = append(m.history, p.FunctionCall.Name)
+			case llmtypes.ToolCallResponse:
+				m.history = append(m.history, p.Name)
+			}
+		}
+	}
+
+	call := llmtypes.ToolCall{ID: "call_1", Type: "function", FunctionCall: &llmtypes.FunctionCall{Name: m.tools[0], Arguments: "{}"}}
+	if opts.StreamChan != nil {
+		opts.StreamChan <- llmtypes.StreamChunk{Type: llmtypes.StreamChunkTypeToolCall, ToolCall: &call}
+		close(opts.StreamChan)
+	}
+	return &llmtypes.ContentResponse{Choices: []*llmtypes.ContentChoice{{ToolCalls: []llmtypes.ToolCall{call}, StopReason: "tool_calls"}}}, nil
+}
+
+func (m *toolNameModel) Call(ctx context.Context, prompt string, options ...llmtypes.CallOption) (string, error) {
+	return "", nil
+}
+
+// RunToolNamesTest verifies invalid tool names are renamed for the provider and restored
+// in the response
+func RunToolNamesTest() bool {
+	log.Printf("\n🏷️  Test: Tool Name Sanitization")
+
+	longName := "workspace.documents.search_by_title_and_author_with_full_text_fallback"
+	tool := func(name string) llmtypes.Tool {
+		return llmtypes.Tool{Type: "function", Function: &llmtypes.FunctionDefinition{Name: name, Description: name}}
+	}
+	tools := []llmtypes.Tool{tool("files.read"), tool("web search"), tool(longName), tool("files_read")}
+	messages := []llmtypes.MessageContent{
+		llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "Read the notes"),
+		{Role: llmtypes.ChatMessageTypeAI, Parts: []llmtypes.ContentPart{
+			llmtypes.ToolCall{ID: "call_0", Type: "function", FunctionCall: &llmtypes.FunctionCall{Name: "files.read", Arguments: "{}"}},
+		}},
+		{Role: llmtypes.ChatMessageTypeTool, Parts: []llmtypes.ContentPart{
+			llmtypes.ToolCallResponse{ToolCallID: "call_0", Name: "files.read", Content: "notes"},
+		}},
+	}
+
+	model := &toolNameModel{}
+	llm := llmproviders.NewProviderAwareLLM(model, llmproviders.ProviderOpenAI, "fake-model", nil, "tool-names-test", nil)
+	streamChan := make(chan llmtypes.StreamChunk, 10)
+	resp, err := llm.GenerateContent(context.Background(), messages, llmtypes.WithTools(tools), llmtypes.WithStreamingChan(streamChan))
+	if err != nil || resp == nil || len(resp.Choices) != 1 || len(resp.Choices[0].ToolCalls) != 1 {
+		log.Printf("❌ Call failed: %v", err)
+		return false
+	}
+	passed := true
+
+	seen := make(map[string]bool)
+	for _, name := range model.tools {
+		if !validToolName(name) || seen[name] {
+			log.Printf("❌ Provider received invalid or duplicate tool names: %q", model.tools)
+			passed = false
+			break
+		}
+		seen[name] = true
+	}
+	if passed {
+		log.Printf("✅ Provider received: %q", model.tools)
+	}
+	if len(model.history) != 2 || model.history[0] != model.tools[0] || model.history[1] != model.tools[0] {
+		log.Printf("❌ History names %q don't match the sanitized tool %q", model.history, model.tools[0])
+		passed = false
+	} else {
+		log.Printf("✅ History tool call and result renamed to %q", model.history[0])
+	}
+
+	if name := resp.Choices[0].ToolCalls[0].FunctionCall.Name; name != "files.read" {
+		log.Printf("❌ Response tool call names %q, expected files.read", name)
+		passed = false
+	} else {
+		log.Printf("✅ Response tool call restored to %q", name)
+	}
+	var streamed []string
+	for chunk := range streamChan {
+		if chunk.ToolCall != nil {
+			streamed = append(streamed, chunk.ToolCall.FunctionCall.Name)
+		}
+	}
+	if len(streamed) != 1 || streamed[0] != "files.read" {
+		log.Printf("❌ Streamed tool calls %q, expected [files.read]", streamed)
+		passed = false
+	} else {
+		log.Printf("✅ Streamed tool call restored to %q", streamed[0])
+	}
+	if tools[0].Function.Name != "files.read" {
+		log.Printf("❌ Caller's tools were modified")
+		passed = false
+	}
+
+	if _, err := llm.GenerateContent(context.Background(), messages, llmtypes.WithTools(tools), llmtypes.WithToolNameSanitization(false)); err != nil {
+		log.Printf("❌ Call without sanitization failed: %v", err)
+		return false
+	}
+	if model.tools[0] != "files.read" || model.history[0] != "files.read" {
+		log.Printf("❌ WithToolNameSanitization(false) still renamed tools: %q", model.tools)
+		passed = false
+	} else {
+		log.Printf("✅ WithToolNameSanitization(false) sends names unchanged")
+	}
+	return passed
+}
+
+// validToolName reports whether name matches [a-zA-Z0-9_-]{1,64}
+func validToolName(name string) bool {
+	if name == "" || len(name) > 64 {
+		return false
+	}
+	for _, r := range name {
+		if !(r == '_' || r == '-' || (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9')) {
+			return false
+		}
+	}
+	return true
+}
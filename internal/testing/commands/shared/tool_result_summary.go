@@ -0,0 +1,125 @@
+package shared
+
+import (
+	"context"
+	"log"
+	"os"
+	"strings"
+
+	llmproviders "github.com/manishiitg/multi-llm-provider-go"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+
+	"github.com/spf13/cobra"
+)
+
+// ToolResultSummaryTestCmd checks that WithToolResultMaxTokens summarizes long tool results
+var ToolResultSummaryTestCmd = &cobra.Command{
+	Use:   "tool-result-summary",
+	Short: "Test that WithToolResultMaxTokens summarizes long tool results",
+	Long: `This test checks that WithToolResultMaxTokens:
+- replaces long tool results with a summary noting it was summarized, keeping images
+- leaves short results alone
+- sends JSON results verbatim unless their tool opted in
+- summarizes each result once across turns
+- uses the WithToolResultSummarizer model when set
+
+No API calls are made.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !RunToolResultSummaryTest() {
+			os.Exit(1)
+		}
+	},
+}
+
+// summarizingModel is a fake model that answers summary requests with a fixed summary and
+// records the tool results of other requests
+type summarizingModel struct {
+	fakeModelID
+	summaries   int
+	toolResults []llmtypes.ToolCallResponse
+}
+
+func (m *summarizingModel) GenerateContent(ctx context.Context, messages []llmtypes.MessageContent, options ...llmtypes.CallOption) (*llmtypes.ContentResponse, error) {
+	if len(messages) > 0 && messages[0].Role == llmtypes.ChatMessageTypeSystem && strings.Contains(messages[0].Parts[0].(llmtypes.TextContent).Text, "Summarize") {
+		m.summaries++
+		return &llmtypes.ContentResponse{Choices: []*llmtypes.ContentChoice{{Content: "The log shows 3 errors in auth.go."}}}, nil
+	}
+	m.toolResults = nil
+	for _, msg := range messages {
+		for _, part := range msg.Parts {
+			if result, ok := part.(llmtypes.ToolCallResponse); ok {
+				m.toolResults = append(m.toolResults, result)
+			}
+		}
+	}
+	return textResponse("ok"), nil
+}
+
+// RunToolResultSummaryTest verifies the summaries of long tool results
+func RunToolResultSummaryTest() bool {
+	log.Printf("\n📝 Test: Tool Result Summary")
+
+	longLog := strings.Repeat("2024-01-01 INFO request handled in 3ms\n", 200)
+	longJSON := "[" + strings.Repeat(`{"id":1,"name":"item"},`, 200) + `{"id":2}]`
+	image := llmtypes.ImageContent{SourceType: "base64", MediaType: "image/png", Data: "iVBORw0KGgo="}
+	messages := []llmtypes.MessageContent{
+		llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "Check the logs and the items."),
+		{Role: llmtypes.ChatMessageTypeAI, Parts: []llmtypes.ContentPart{
+			llmtypes.ToolCall{ID: "call_1", Type: "function", FunctionCall: &llmtypes.FunctionCall{Name: "read_log", Arguments: "{}"}},
+			llmtypes.ToolCall{ID: "call_2", Type: "function", FunctionCall: &llmtypes.FunctionCall{Name: "list_items", Arguments: "{}"}},
+			llmtypes.ToolCall{ID: "call_3", Type: "function", FunctionCall: &llmtypes.FunctionCall{Name: "status", Arguments: "{}"}},
+		}},
+		{Role: llmtypes.ChatMessageTypeTool, Parts: []llmtypes.ContentPart{
+			llmtypes.ToolCallResponse{ToolCallID: "call_1", Name: "read_log", Content: longLog, Parts: []llmtypes.ContentPart{image}},
+			llmtypes.ToolCallResponse{ToolCallID: "call_2", Name: "list_items", Content: longJSON},
+			llmtypes.ToolCallResponse{ToolCallID: "call_3", Name: "status", Content: "all good"},
+		}},
+	}
+
+	model := &summarizingModel{}
+	llm := llmproviders.NewProviderAwareLLM(model, llmproviders.ProviderOpenAI, "fake-model", nil, "tool-result-summary-test", nil)
+	passed := true
+
+	for turn := 1; turn <= 2; turn++ {
+		if _, err := llm.GenerateContent(context.Background(), messages, llmproviders.WithToolResultMaxTokens(100)); err != nil {
+			log.Printf("❌ Turn %d failed: %v", turn, err)
+			return false
+		}
+	}
+	results := model.toolResults
+	switch {
+	case len(results) != 3:
+		log.Printf("❌ Expected 3 tool results, got %d", len(results))
+		passed = false
+	case !strings.HasPrefix(results[0].Content, "[Summary of a") || !strings.Contains(results[0].Content, "3 errors") || len(results[0].Images()) != 1:
+		log.Printf("❌ The long log should be summarized with its image kept, got %q with %d images", results[0].Content, len(results[0].Images()))
+		passed = false
+	case results[1].Content != longJSON || results[2].Content != "all good":
+		log.Printf("❌ The JSON and short results should be sent verbatim")
+		passed = false
+	case model.summaries != 1:
+		log.Printf("❌ The log should be summarized once across two turns, got %d summaries", model.summaries)
+		passed = false
+	default:
+		log.Printf("✅ The long log is summarized once; JSON and short results are sent verbatim")
+	}
+	if len(messages[2].Parts[0].(llmtypes.ToolCallResponse).Content) != len(longLog) {
+		log.Printf("❌ The caller's messages should not be modified")
+		passed = false
+	}
+
+	summarizer := &summarizingModel{}
+	model.summaries = 0
+	if _, err := llm.GenerateContent(context.Background(), messages, llmproviders.WithToolResultMaxTokens(50),
+		llmproviders.WithToolResultSummarizer(summarizer), llmproviders.WithSummarizeStructuredToolResults("list_items")); err != nil {
+		log.Printf("❌ Call with a summarizer failed: %v", err)
+		return false
+	}
+	if summarizer.summaries != 2 || model.summaries != 0 || !strings.HasPrefix(model.toolResults[1].Content, "[Summary of a") {
+		log.Printf("❌ The summarizer should summarize the log and the opted-in JSON, got %d summaries (call model %d)", summarizer.summaries, model.summaries)
+		passed = false
+	} else {
+		log.Printf("✅ WithToolResultSummarizer writes the summaries, including opted-in JSON results")
+	}
+	return passed
+}
@@ -0,0 +1,152 @@
+package shared
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"strings"
+	"sync"
+
+	llmproviders "github.com/manishiitg/multi-llm-provider-go"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+
+	"github.com/spf13/cobra"
+)
+
+// TraceIDTestCmd checks that trace IDs are sent to providers
+var TraceIDTestCmd = &cobra.Command{
+	Use:   "trace-id",
+	Short: "Test sending trace IDs with provider requests (WithMetadataTraceID)",
+	Long: `This test checks, against a local fake provider server, that:
+- Config.PropagateTraceID sends Config.TraceID as the X-Trace-Id header to OpenAI, OpenRouter,
+  Anthropic and Gemini, and as X-Client-Request-Id to OpenAI
+- WithMetadataTraceID overrides it for one call
+- nothing is sent by default
+- Bedrock requests carry the trace ID as request metadata
+
+No API calls are made.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !RunTraceIDTest() {
+			os.Exit(1)
+		}
+	},
+}
+
+// RunTraceIDTest verifies the trace ID headers and metadata sent for each provider
+func RunTraceIDTest() bool {
+	log.Printf("\n🪪 Test: Trace ID")
+
+	var mu sync.Mutex
+	var headers http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		headers = r.Header.Clone()
+		mu.Unlock()
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/messages"):
+			w.Header().Set("Content-Type", "text/event-stream")
+			for _, event := range []string{
+				`message_start`, `{"type":"message_start","message":{"id":"msg_1","type":"message","role":"assistant","model":"claude-sonnet-4-20250514","content":[],"stop_reason":null,"usage":{"input_tokens":5,"output_tokens":1}}}`,
+				`content_block_start`, `{"type":"content_block_start","index":0,"content_block":{"type":"text","text":""}}`,
+				`content_block_delta`, `{"type":"content_block_delta","index":0,"delta":{"type":"text_delta","text":"Hello"}}`,
+				`content_block_stop`, `{"type":"content_block_stop","index":0}`,
+				`message_delta`, `{"type":"message_delta","delta":{"stop_reason":"end_turn"},"usage":{"output_tokens":2}}`,
+				`message_stop`, `{"type":"message_stop"}`,
+			} {
+				if strings.HasPrefix(event, "{") {
+					fmt.Fprintf(w, "data: %s\n\n", event)
+				} else {
+					fmt.Fprintf(w, "event: %s\n", event)
+				}
+			}
+		case strings.Contains(r.URL.Path, ":streamGenerateContent"):
+			w.Header().Set("Content-Type", "text/event-stream")
+			fmt.Fprint(w, "data: {\"candidates\":[{\"content\":{\"role\":\"model\",\"parts\":[{\"text\":\"Hello\"}]},\"finishReason\":\"STOP\"}]}\n\n")
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, `{"id":"chatcmpl-1","object":"chat.completion","created":1,"model":"gpt-4.1","choices":[{"index":0,"message":{"role":"assistant","content":"Hello"},"finish_reason":"stop"}]}`)
+		}
+	}))
+	defer server.Close()
+	target, _ := url.Parse(server.URL)
+	httpClient := &http.Client{Transport: redirectTransport{target: target}}
+
+	apiKey := "test"
+	messages := []llmtypes.MessageContent{llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "Hi")}
+	call := func(config llmproviders.Config, options ...llmtypes.CallOption) (http.Header, error) {
+		mu.Lock()
+		headers = nil
+		mu.Unlock()
+		config.HTTPClient = httpClient
+		llm, err := llmproviders.InitializeLLM(config)
+		if err != nil {
+			return nil, err
+		}
+		if _, err = llm.GenerateContent(context.Background(), messages, options...); err != nil {
+			return nil, err
+		}
+		mu.Lock()
+		defer mu.Unlock()
+		return headers, nil
+	}
+
+	passed := true
+	check := func(name string, config llmproviders.Config, want map[string]string, options ...llmtypes.CallOption) {
+		got, err := call(config, options...)
+		for key, value := range want {
+			if err != nil || got.Get(key) != value {
+				log.Printf("❌ %s: expected %s %q, got %q (err %v)", name, key, value, got.Get(key), err)
+				passed = false
+				return
+			}
+		}
+		log.Printf("✅ %s sends %v", name, want)
+	}
+
+	configs := []llmproviders.Config{
+		{Provider: llmproviders.ProviderOpenAI, ModelID: "gpt-4.1", APIKeys: &llmproviders.ProviderAPIKeys{OpenAI: &apiKey}},
+		{Provider: llmproviders.ProviderOpenRouter, ModelID: "openai/gpt-4.1", APIKeys: &llmproviders.ProviderAPIKeys{OpenRouter: &apiKey}},
+		{Provider: llmproviders.ProviderAnthropic, ModelID: "claude-sonnet-4-20250514", APIKeys: &llmproviders.ProviderAPIKeys{Anthropic: &apiKey}},
+		{Provider: llmproviders.ProviderVertex, ModelID: "gemini-2.5-flash", APIKeys: &llmproviders.ProviderAPIKeys{Vertex: &apiKey}},
+	}
+	for _, config := range configs {
+		config.TraceID = "trace-123"
+		config.PropagateTraceID = true
+		want := map[string]string{"X-Trace-Id": "trace-123"}
+		if config.Provider == llmproviders.ProviderOpenAI {
+			want["X-Client-Request-Id"] = "trace-123"
+		}
+		check(fmt.Sprintf("%s with PropagateTraceID", config.Provider), config, want)
+	}
+
+	openAI := configs[0]
+	openAI.TraceID = "trace-123"
+	openAI.PropagateTraceID = true
+	check("WithMetadataTraceID", openAI, map[string]string{"X-Trace-Id": "call-456", "X-Client-Request-Id": "call-456"}, llmproviders.WithMetadataTraceID("call-456"))
+
+	openAI.PropagateTraceID = false
+	if got, err := call(openAI); err != nil || got.Get("X-Trace-Id") != "" || got.Get("X-Client-Request-Id") != "" {
+		log.Printf("❌ Default: expected no trace headers, got %q / %q (err %v)", got.Get("X-Trace-Id"), got.Get("X-Client-Request-Id"), err)
+		passed = false
+	} else {
+		log.Printf("✅ Trace IDs are not sent by default")
+	}
+
+	bedrock, err := llmproviders.InitializeLLM(llmproviders.Config{Provider: llmproviders.ProviderBedrock, ModelID: "us.anthropic.claude-sonnet-4-20250514-v1:0", TraceID: "trace-123", PropagateTraceID: true})
+	if err != nil {
+		log.Printf("⚠️  Bedrock: skipped, %v", err)
+		return passed
+	}
+	resp, err := bedrock.GenerateContent(context.Background(), messages, llmtypes.WithDryRun())
+	if err != nil || !strings.Contains(fmt.Sprintf("%+v", resp.Raw), "trace_id:trace-123") {
+		log.Printf("❌ Bedrock: expected request metadata trace_id, got %+v (err %v)", resp, err)
+		passed = false
+	} else {
+		log.Printf("✅ Bedrock sends the trace ID as request metadata")
+	}
+	return passed
+}
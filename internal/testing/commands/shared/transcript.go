@@ -0,0 +1,138 @@
+package shared
+
+import (
+	"encoding/json"
+	"log"
+	"os"
+	"reflect"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+
+	"github.com/spf13/cobra"
+)
+
+// TranscriptTestCmd checks that conversations survive a MarshalTranscript/UnmarshalTranscript round trip
+var TranscriptTestCmd = &cobra.Command{
+	Use:   "transcript",
+	Short: "Test transcript serialization round trip for every content part type",
+	Long: `This test serializes a conversation containing every ContentPart type
+(TextContent, ImageContent, ToolCall, ToolCallResponse with multimodal parts)
+with MarshalTranscript and with plain encoding/json, and checks that decoding
+restores the same concrete types.
+
+No API calls are made.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !RunTranscriptRoundTripTest() {
+			os.Exit(1)
+		}
+	},
+}
+
+// RunTranscriptRoundTripTest marshals a conversation with every part type and verifies the
+// unmarshalled conversation is identical, including the concrete type of every part
+func RunTranscriptRoundTripTest() bool {
+	log.Printf("\n📝 Test: Transcript Round Trip")
+
+	conversation := []llmtypes.MessageContent{
+		llmtypes.TextParts(llmtypes.ChatMessageTypeSystem, "You are a helpful assistant."),
+		{
+			Role: llmtypes.ChatMessageTypeHuman,
+			Name: "alice",
+			Parts: []llmtypes.ContentPart{
+				llmtypes.TextContent{Text: "What is in this image?"},
+				llmtypes.ImageContent{SourceType: "base64", MediaType: "image/png", Data: "iVBORw0KGgo="},
+				llmtypes.ImageContent{SourceType: "url", Data: "https://example.com/cat.jpg"},
+			},
+		},
+		{
+			Role: llmtypes.ChatMessageTypeAI,
+			Parts: []llmtypes.ContentPart{
+				llmtypes.TextContent{Text: "Let me take a screenshot."},
+				llmtypes.ToolCall{
+					ID:               "call_1",
+					Type:             "function",
+					FunctionCall:     &llmtypes.FunctionCall{Name: "screenshot", Arguments: `{"url": "https://example.com"}`},
+					ThoughtSignature: "sig_123",
+				},
+				llmtypes.ToolCall{
+					ID:           "call_2",
+					Type:         "function",
+					FunctionCall: &llmtypes.FunctionCall{Name: "lookup", Arguments: `{}`},
+				},
+			},
+		},
+		{
+			Role: llmtypes.ChatMessageTypeTool,
+			Parts: []llmtypes.ContentPart{
+				llmtypes.ToolCallResponse{
+					ToolCallID: "call_1",
+					Name:       "screenshot",
+					Content:    "Captured page",
+					Parts: []llmtypes.ContentPart{
+						llmtypes.TextContent{Text: "1280x720"},
+						llmtypes.ImageContent{SourceType: "base64", MediaType: "image/jpeg", Data: "/9j/4AAQ"},
+					},
+				},
+				llmtypes.ToolCallResponse{ToolCallID: "call_2", Name: "lookup", Content: "not found", IsError: true},
+			},
+		},
+		llmtypes.TextParts(llmtypes.ChatMessageTypeAI, "It is a cat."),
+	}
+
+	data, err := llmtypes.MarshalTranscript(conversation)
+	if err != nil {
+		log.Printf("❌ MarshalTranscript failed: %v", err)
+		return false
+	}
+	log.Printf("   Serialized %d messages into %d bytes", len(conversation), len(data))
+
+	restored, err := llmtypes.UnmarshalTranscript(data)
+	if err != nil {
+		log.Printf("❌ UnmarshalTranscript failed: %v", err)
+		return false
+	}
+
+	passed := len(restored) == len(conversation)
+	if !passed {
+		log.Printf("❌ Expected %d messages, got %d", len(conversation), len(restored))
+		return false
+	}
+	for i := range conversation {
+		if !reflect.DeepEqual(conversation[i], restored[i]) {
+			log.Printf("❌ Message %d differs after round trip:\n   want %#v\n   got  %#v", i, conversation[i], restored[i])
+			passed = false
+			continue
+		}
+		for j, part := range restored[i].Parts {
+			log.Printf("✅ Message %d part %d restored as %T", i, j, part)
+		}
+	}
+
+	// Plain encoding/json round trip through MessageContent's (un)marshaller
+	plain, err := json.Marshal(conversation)
+	if err != nil {
+		log.Printf("❌ json.Marshal of []MessageContent failed: %v", err)
+		return false
+	}
+	var decoded []llmtypes.MessageContent
+	if err := json.Unmarshal(plain, &decoded); err != nil {
+		log.Printf("❌ json.Unmarshal into []MessageContent failed: %v", err)
+		return false
+	}
+	if !reflect.DeepEqual(conversation, decoded) {
+		log.Printf("❌ []MessageContent differs after a json.Marshal/json.Unmarshal round trip")
+		passed = false
+	} else {
+		log.Printf("✅ []MessageContent survives a plain json.Marshal/json.Unmarshal round trip")
+	}
+
+	if _, err := llmtypes.MarshalTranscript([]llmtypes.MessageContent{{Role: llmtypes.ChatMessageTypeHuman, Parts: []llmtypes.ContentPart{42}}}); err == nil {
+		log.Printf("❌ Expected an error for an unsupported part type")
+		passed = false
+	}
+
+	if passed {
+		log.Printf("\n✅ Transcript round trip preserved every message and part type")
+	}
+	return passed
+}
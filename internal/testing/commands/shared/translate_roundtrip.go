@@ -0,0 +1,119 @@
+package shared
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+	anthropicadapter "github.com/manishiitg/multi-llm-provider-go/pkg/adapters/anthropic"
+	bedrockadapter "github.com/manishiitg/multi-llm-provider-go/pkg/adapters/bedrock"
+	openaiadapter "github.com/manishiitg/multi-llm-provider-go/pkg/adapters/openai"
+	vertexadapter "github.com/manishiitg/multi-llm-provider-go/pkg/adapters/vertex"
+
+	"github.com/spf13/cobra"
+)
+
+// TranslateRoundTripTestCmd checks that the adapters' message translation helpers round-trip
+var TranslateRoundTripTestCmd = &cobra.Command{
+	Use:   "translate-roundtrip",
+	Short: "Test that messages survive a round trip through each provider's To/From helpers",
+	Long: `This test converts a conversation with a system prompt, text, an image, a tool call
+and a tool result to each provider's wire format and back (ToOpenAIMessages /
+FromOpenAIMessages, ToAnthropicMessages / FromAnthropicMessages, ToBedrockConverse /
+FromBedrockConverse, ToGeminiContents / FromGeminiContents) and checks that it comes back
+unchanged, apart from Gemini sending the system prompt as a user turn and splitting text
+from tool calls.
+
+No API calls are made.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !RunTranslateRoundTripTest() {
+			os.Exit(1)
+		}
+	},
+}
+
+// describeMessages renders messages one per line with their role and parts, for comparisons
+func describeMessages(messages []llmtypes.MessageContent) string {
+	var lines []string
+	for _, msg := range messages {
+		line := string(msg.Role) + ":"
+		for _, part := range msg.Parts {
+			switch p := part.(type) {
+			case llmtypes.TextContent:
+				line += fmt.Sprintf(" text(%s)", p.Text)
+			case llmtypes.ImageContent:
+				line += fmt.Sprintf(" image(%s %s %s)", p.SourceType, p.MediaType, p.Data)
+			case llmtypes.ToolCall:
+				line += fmt.Sprintf(" call(%s %s %s)", p.ID, p.FunctionCall.Name, p.FunctionCall.Arguments)
+			case llmtypes.ToolCallResponse:
+				line += fmt.Sprintf(" result(%s %s %s error=%t)", p.ToolCallID, p.Name, p.Content, p.IsError)
+			default:
+				line += fmt.Sprintf(" %T", p)
+			}
+		}
+		lines = append(lines, line)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// RunTranslateRoundTripTest round-trips a conversation through every provider's helpers
+func RunTranslateRoundTripTest() bool {
+	log.Printf("\n🔄 Test: Translate Round Trip")
+
+	messages := []llmtypes.MessageContent{
+		llmtypes.TextParts(llmtypes.ChatMessageTypeSystem, "You are terse."),
+		{Role: llmtypes.ChatMessageTypeHuman, Parts: []llmtypes.ContentPart{
+			llmtypes.TextContent{Text: "What's the weather where this was taken?"},
+			llmtypes.ImageContent{SourceType: "base64", MediaType: "image/png", Data: "iVBORw0KGgo="},
+		}},
+		{Role: llmtypes.ChatMessageTypeAI, Parts: []llmtypes.ContentPart{
+			llmtypes.TextContent{Text: "Let me check."},
+			llmtypes.ToolCall{ID: "call_1", Type: "function", FunctionCall: &llmtypes.FunctionCall{Name: "get_weather", Arguments: `{"city":"Paris"}`}},
+		}},
+		{Role: llmtypes.ChatMessageTypeTool, Parts: []llmtypes.ContentPart{
+			llmtypes.ToolCallResponse{ToolCallID: "call_1", Name: "get_weather", Content: "18C and sunny"},
+		}},
+		llmtypes.TextParts(llmtypes.ChatMessageTypeAI, "It is 18C and sunny."),
+	}
+	unchanged := describeMessages(messages)
+
+	passed := true
+	for _, c := range []struct {
+		name      string
+		roundTrip func() ([]llmtypes.MessageContent, error)
+		want      string
+	}{
+		{"OpenAI", func() ([]llmtypes.MessageContent, error) {
+			return openaiadapter.FromOpenAIMessages(openaiadapter.ToOpenAIMessages(messages))
+		}, unchanged},
+		{"Anthropic", func() ([]llmtypes.MessageContent, error) {
+			converted, system := anthropicadapter.ToAnthropicMessages(messages)
+			return anthropicadapter.FromAnthropicMessages(converted, system)
+		}, unchanged},
+		{"Bedrock", func() ([]llmtypes.MessageContent, error) {
+			converted, system := bedrockadapter.ToBedrockConverse(messages)
+			return bedrockadapter.FromBedrockConverse(converted, system)
+		}, unchanged},
+		{"Gemini", func() ([]llmtypes.MessageContent, error) {
+			return vertexadapter.FromGeminiContents(vertexadapter.ToGeminiContents(messages, "gemini-2.5-flash"))
+		}, strings.Join([]string{
+			"human: text(You are terse.)",
+			"human: text(What's the weather where this was taken?) image(base64 image/png iVBORw0KGgo=)",
+			"ai: text(Let me check.)",
+			`ai: call(call_1 get_weather {"city":"Paris"})`,
+			"tool: result(call_1 get_weather 18C and sunny error=false)",
+			"ai: text(It is 18C and sunny.)",
+		}, "\n")},
+	} {
+		converted, err := c.roundTrip()
+		if got := describeMessages(converted); err != nil || got != c.want {
+			log.Printf("❌ %s: expected\n%s\ngot (error %v)\n%s", c.name, c.want, err, got)
+			passed = false
+			continue
+		}
+		log.Printf("✅ %s round-trips text, image, tool call and tool result", c.name)
+	}
+	return passed
+}
@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+	"github.com/manishiitg/multi-llm-provider-go/pkg/utils"
 )
 
 // RunTypeConversionTest validates that all ContentPart types can be properly handled
@@ -125,6 +126,16 @@ func RunTypeConversionTest(llm llmtypes.Model, modelID string) {
 				},
 			},
 		},
+		{
+			name: "NamedParticipant",
+			message: llmtypes.MessageContent{
+				Role: llmtypes.ChatMessageTypeHuman,
+				Name: "reviewer",
+				Parts: []llmtypes.ContentPart{
+					llmtypes.TextContent{Text: "Looks good to me"},
+				},
+			},
+		},
 	}
 
 	// Validate that all parts are from llm-providers package
@@ -152,6 +163,15 @@ func RunTypeConversionTest(llm llmtypes.Model, modelID string) {
 		}
 	}
 
+	// Participant names must survive the emulation used for providers without a name field
+	named := utils.PrefixMessageNames([]llmtypes.MessageContent{testCases[len(testCases)-1].message})
+	if text, ok := named[0].Parts[0].(llmtypes.TextContent); !ok || text.Text != "reviewer: Looks good to me" {
+		log.Printf("❌ NamedParticipant - name was not prefixed to the message text: %+v", named[0].Parts)
+		allValid = false
+	} else {
+		log.Printf("✅ NamedParticipant - name emulated as %q", text.Text)
+	}
+
 	if !allValid {
 		log.Printf("\n❌ Type validation failed - some types are not properly converted")
 		log.Printf("   This indicates a problem with the conversion layer")
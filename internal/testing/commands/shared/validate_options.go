@@ -0,0 +1,168 @@
+package shared
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+	"strings"
+
+	llmproviders "github.com/manishiitg/multi-llm-provider-go"
+	"github.com/manishiitg/multi-llm-provider-go/internal/testing"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+
+	"github.com/spf13/cobra"
+)
+
+// ValidateOptionsTestCmd checks ValidateOptions and the validation run before each call
+var ValidateOptionsTestCmd = &cobra.Command{
+	Use:   "validate-options",
+	Short: "Test that invalid call options fail with ErrInvalidOptions before any request",
+	Long: `This test checks that ValidateOptions reports:
+- unknown tool choice types, forced tools that aren't among the call's tools and required
+  tool calls without tools
+- unnamed and duplicate tools, and tools for models without tool calling
+- WithJSONSchema without a schema
+- negative temperatures and max tokens
+- image and audio output outside Gemini
+and that valid options pass, including WithJSONSchema for Claude and max tokens above the
+registry's output limit. Through ProviderAwareLLM it checks that the tool choice type is
+normalized, that WithJSONSchema is dropped for Claude and max tokens above the registry's
+limit are sent unchanged, both with a warning, that images sent to a model without vision
+fail, and that the model is not called for invalid options.
+
+No API calls are made.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !RunValidateOptionsTest() {
+			os.Exit(1)
+		}
+	},
+}
+
+// toolChoiceModel is a fake model that records the tool choice and options it is called with
+type toolChoiceModel struct {
+	fakeModelID
+	calls  int
+	choice *llmtypes.ToolChoice
+	opts   *llmtypes.CallOptions
+}
+
+func (m *toolChoiceModel) GenerateContent(ctx context.Context, messages []llmtypes.MessageContent, options ...llmtypes.CallOption) (*llmtypes.ContentResponse, error) {
+	opts := callOptions(options)
+	m.calls++
+	m.choice = opts.ToolChoice
+	m.opts = opts
+	return textResponse("Done"), nil
+}
+
+// RunValidateOptionsTest verifies ValidateOptions
+func RunValidateOptionsTest() bool {
+	log.Printf("\n🧪 Test: Validate Options")
+
+	tool := func(name string) llmtypes.Tool {
+		return llmtypes.Tool{Type: "function", Function: &llmtypes.FunctionDefinition{Name: name, Description: "Looks things up"}}
+	}
+	options := func(options ...llmtypes.CallOption) *llmtypes.CallOptions {
+		opts := callOptions(options)
+		return opts
+	}
+	schema := map[string]interface{}{"type": "object"}
+	forced := &llmtypes.ToolChoice{Type: "function", Function: &llmtypes.FunctionName{Name: "search"}}
+
+	passed := true
+	for _, c := range []struct {
+		name     string
+		provider llmproviders.Provider
+		modelID  string
+		opts     *llmtypes.CallOptions
+		problems int
+	}{
+		{"valid call", llmproviders.ProviderOpenAI, "gpt-4.1", options(llmproviders.WithTools([]llmtypes.Tool{tool("lookup")}), llmtypes.WithToolChoiceString("required"), llmproviders.WithMaxTokens(32768), llmtypes.WithJSONSchema(schema, "answer", "", true)), 0},
+		{"forced tool not among the tools", llmproviders.ProviderOpenAI, "gpt-4.1", options(llmproviders.WithTools([]llmtypes.Tool{tool("lookup")}), llmproviders.WithToolChoice(forced)), 1},
+		{"unknown tool choice type", llmproviders.ProviderOpenAI, "gpt-4.1", options(llmproviders.WithTools([]llmtypes.Tool{tool("lookup")}), llmtypes.WithToolChoiceString("sometimes")), 1},
+		{"required tool call without tools", llmproviders.ProviderAnthropic, "claude-sonnet-4-20250514", options(llmtypes.WithToolChoiceString("required")), 1},
+		{"unnamed and duplicate tools", llmproviders.ProviderOpenAI, "gpt-4.1", options(llmproviders.WithTools([]llmtypes.Tool{tool("lookup"), tool("lookup"), {Type: "function"}})), 2},
+		{"tools without tool calling", llmproviders.ProviderOpenAI, "o1-mini", options(llmproviders.WithTools([]llmtypes.Tool{tool("lookup")})), 1},
+		{"tools with tool emulation", llmproviders.ProviderOpenAI, "o1-mini", options(llmproviders.WithTools([]llmtypes.Tool{tool("lookup")}), llmproviders.WithToolEmulation()), 0},
+		{"disabled tools", llmproviders.ProviderOpenAI, "gpt-4.1", options(llmproviders.WithToolChoice(forced), llmproviders.WithDisableTools()), 0},
+		{"JSON schema ignored by Anthropic", llmproviders.ProviderAnthropic, "claude-sonnet-4-20250514", options(llmtypes.WithJSONSchema(schema, "answer", "", true)), 0},
+		{"JSON schema without a schema", llmproviders.ProviderOpenAI, "gpt-4.1", options(llmtypes.WithJSONSchema(nil, "answer", "", true)), 1},
+		{"negative temperature", llmproviders.ProviderOpenAI, "gpt-4.1", options(llmproviders.WithTemperature(-0.5)), 1},
+		{"temperature above the maximum is clamped", llmproviders.ProviderAnthropic, "claude-sonnet-4-20250514", options(llmproviders.WithTemperature(1.5)), 0},
+		{"max tokens above the registry's output limit", llmproviders.ProviderAnthropic, "claude-3-7-sonnet-20250219", options(llmproviders.WithMaxTokens(128000)), 0},
+		{"max tokens of an unknown model", llmproviders.ProviderOpenAI, "my-fine-tune", options(llmproviders.WithMaxTokens(100000)), 0},
+		{"image output outside Gemini", llmproviders.ProviderOpenAI, "gpt-4.1", options(llmproviders.WithResponseModalities("TEXT", "IMAGE")), 1},
+		{"image output from Gemini", llmproviders.ProviderVertex, "gemini-2.5-flash-image", options(llmproviders.WithResponseModalities("TEXT", "IMAGE")), 0},
+		{"several problems", llmproviders.ProviderAnthropic, "claude-sonnet-4-20250514", options(llmproviders.WithTemperature(-1), llmproviders.WithMaxTokens(-1), llmproviders.WithResponseModalities("video")), 3},
+	} {
+		err := llmproviders.ValidateOptions(c.provider, c.modelID, c.opts)
+		var invalid *llmproviders.InvalidOptionsError
+		switch {
+		case c.problems == 0 && err != nil:
+			log.Printf("❌ %s: expected no problems, got %v", c.name, err)
+			passed = false
+		case c.problems == 0:
+			log.Printf("✅ %s: valid", c.name)
+		case !errors.Is(err, llmproviders.ErrInvalidOptions) || !errors.As(err, &invalid):
+			log.Printf("❌ %s: expected ErrInvalidOptions, got %v", c.name, err)
+			passed = false
+		case len(invalid.Problems) != c.problems:
+			log.Printf("❌ %s: expected %d problems, got %q", c.name, c.problems, invalid.Problems)
+			passed = false
+		default:
+			log.Printf("✅ %s: %v", c.name, err)
+		}
+	}
+
+	// Through the wrapper: the tool choice type is normalized and invalid calls never reach the model
+	model := &toolChoiceModel{}
+	llm := llmproviders.NewProviderAwareLLM(model, llmproviders.ProviderOpenAI, "gpt-4.1", nil, "trace", testing.GetTestLogger())
+	messages := []llmtypes.MessageContent{llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "Look it up")}
+	_, err := llm.GenerateContent(context.Background(), messages, llmproviders.WithTools([]llmtypes.Tool{tool("lookup")}), llmtypes.WithToolChoiceString(" Required "))
+	if err != nil || model.choice == nil || model.choice.Type != "required" {
+		log.Printf("❌ Expected the tool choice type normalized to \"required\", got %+v (error %v)", model.choice, err)
+		passed = false
+	} else {
+		log.Printf("✅ Tool choice type \" Required \" normalized to %q", model.choice.Type)
+	}
+
+	// Options Claude would ignore or may accept beyond the registry are logged, not refused
+	for _, c := range []struct {
+		name    string
+		option  llmtypes.CallOption
+		check   func(opts *llmtypes.CallOptions) bool
+		warning string
+	}{
+		{"WithJSONSchema on Claude is dropped", llmtypes.WithJSONSchema(schema, "answer", "", true),
+			func(opts *llmtypes.CallOptions) bool { return opts.JSONSchema == nil }, "WithJSONSchema is not supported"},
+		{"Max tokens above the registry's limit are sent", llmproviders.WithMaxTokens(128000),
+			func(opts *llmtypes.CallOptions) bool { return opts.MaxTokens == 128000 }, "Max tokens 128000 is above the 64000"},
+	} {
+		model := &toolChoiceModel{}
+		logger := &captureLogger{}
+		llm := llmproviders.NewProviderAwareLLM(model, llmproviders.ProviderAnthropic, "claude-3-7-sonnet-20250219", nil, "trace", logger)
+		_, err := llm.GenerateContent(context.Background(), messages, c.option)
+		warned := strings.Contains(strings.Join(logger.all, "\n"), c.warning)
+		if err != nil || model.calls != 1 || !c.check(model.opts) || !warned {
+			log.Printf("❌ %s: expected the call made with a warning, got %d calls, warning %t (error %v)", c.name, model.calls, warned, err)
+			passed = false
+			continue
+		}
+		log.Printf("✅ %s with a warning", c.name)
+	}
+
+	model = &toolChoiceModel{}
+	llm = llmproviders.NewProviderAwareLLM(model, llmproviders.ProviderOpenAI, "o1-mini", nil, "trace", testing.GetTestLogger())
+	imageMessage := llmtypes.MessageContent{Role: llmtypes.ChatMessageTypeHuman, Parts: []llmtypes.ContentPart{
+		llmtypes.TextContent{Text: "What is in this picture?"},
+		llmtypes.ImageContent{SourceType: "url", Data: "https://example.com/cat.png"},
+	}}
+	_, err = llm.GenerateContent(context.Background(), []llmtypes.MessageContent{imageMessage})
+	if !errors.Is(err, llmproviders.ErrInvalidOptions) || model.calls != 0 {
+		log.Printf("❌ Expected ErrInvalidOptions for an image sent to o1-mini without calling it, got %v after %d calls", err, model.calls)
+		passed = false
+	} else {
+		log.Printf("✅ Image sent to a model without vision refused: %v", err)
+	}
+	return passed
+}
@@ -0,0 +1,150 @@
+package shared
+
+import (
+	"fmt"
+	"log"
+	"math/rand"
+	"os"
+	"path/filepath"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+	"github.com/manishiitg/multi-llm-provider-go/pkg/vectorindex"
+
+	"github.com/spf13/cobra"
+)
+
+// VectorIndexTestCmd checks the in-memory embedding index
+var VectorIndexTestCmd = &cobra.Command{
+	Use:   "vector-index",
+	Short: "Test the in-memory embedding similarity-search index",
+	Long: `This test checks, with generated vectors, that vectorindex:
+- returns the k most similar embeddings best first, and replaces and removes by ID
+- rejects embeddings of the wrong size
+- ranks the same way when storing int8-quantized vectors
+- returns the same results after a save and load round trip
+
+No API calls are made.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		if !RunVectorIndexTest() {
+			os.Exit(1)
+		}
+	},
+}
+
+// RunVectorIndexTest verifies search, quantization and persistence of vectorindex
+func RunVectorIndexTest() bool {
+	log.Printf("\n🧭 Test: Vector Index")
+
+	const dimensions, count = 64, 500
+	random := rand.New(rand.NewSource(1))
+	randomVector := func() []float32 {
+		vector := make([]float32, dimensions)
+		for i := range vector {
+			vector[i] = float32(random.NormFloat64())
+		}
+		return vector
+	}
+	ids := make([]string, count)
+	vectors := make([][]float32, count)
+	for i := range vectors {
+		ids[i] = fmt.Sprintf("doc-%d", i)
+		vectors[i] = randomVector()
+	}
+	// The query is a slightly perturbed doc 42
+	query := llmtypes.Embedding{Embedding: append([]float32(nil), vectors[42]...)}
+	for i := range query.Embedding {
+		query.Embedding[i] += float32(random.NormFloat64() * 0.1)
+	}
+
+	passed := true
+	build := func(index *vectorindex.VectorIndex) *vectorindex.VectorIndex {
+		for i, vector := range vectors {
+			if err := index.Add(ids[i], llmtypes.Embedding{Embedding: vector}); err != nil {
+				log.Printf("❌ Add %s: %v", ids[i], err)
+				passed = false
+			}
+		}
+		return index
+	}
+
+	index := build(vectorindex.New())
+	matches := index.Search(query, 5)
+	if len(matches) != 5 || matches[0].ID != ids[42] || matches[0].Score < 0.9 || matches[1].Score > matches[0].Score {
+		log.Printf("❌ Search: expected %s first of 5 matches, got %+v", ids[42], matches)
+		passed = false
+	} else {
+		log.Printf("✅ Search finds the nearest embedding (score %.3f) among %d", matches[0].Score, index.Len())
+	}
+	if score := vectorindex.CosineSimilarity(query.Embedding, vectors[42]); score != matches[0].Score {
+		log.Printf("❌ CosineSimilarity: expected the search score %.6f, got %.6f", matches[0].Score, score)
+		passed = false
+	}
+
+	if err := index.Add("short", llmtypes.Embedding{Embedding: []float32{1, 2}}); err == nil {
+		log.Printf("❌ Add: expected an error for an embedding of the wrong size")
+		passed = false
+	} else {
+		log.Printf("✅ Add rejects the wrong size: %v", err)
+	}
+
+	negated := make([]float32, dimensions)
+	for i, v := range vectors[42] {
+		negated[i] = -v
+	}
+	_ = index.Add(ids[42], llmtypes.Embedding{Embedding: negated})
+	removed := index.Remove(ids[7])
+	if top := index.Search(query, 1); index.Len() != count-1 || !removed || len(top) != 1 || top[0].ID == ids[42] || top[0].ID == ids[7] {
+		log.Printf("❌ Replace/Remove: expected %d embeddings and neither %s nor %s first, got %d, %+v", count-1, ids[42], ids[7], index.Len(), top)
+		passed = false
+	} else {
+		log.Printf("✅ Add replaces and Remove deletes by ID")
+	}
+
+	quantized := build(vectorindex.NewQuantized())
+	quantizedMatches := quantized.Search(query, 5)
+	overlap := 0
+	for _, a := range matches {
+		for _, b := range quantizedMatches {
+			if a.ID == b.ID {
+				overlap++
+			}
+		}
+	}
+	if len(quantizedMatches) != 5 || quantizedMatches[0].ID != ids[42] || overlap < 4 || abs32(quantizedMatches[0].Score-matches[0].Score) > 0.01 {
+		log.Printf("❌ Quantized: expected the float top 5 (%+v), got %+v", matches, quantizedMatches)
+		passed = false
+	} else {
+		log.Printf("✅ Quantized index ranks like the float index (%d/5 shared, score %.3f)", overlap, quantizedMatches[0].Score)
+	}
+
+	path := filepath.Join(os.TempDir(), "vector-index-test.gob")
+	defer os.Remove(path)
+	if err := quantized.SaveFile(path); err != nil {
+		log.Printf("❌ SaveFile: %v", err)
+		return false
+	}
+	loaded, err := vectorindex.LoadFile(path)
+	if err != nil {
+		log.Printf("❌ LoadFile: %v", err)
+		return false
+	}
+	loadedMatches := loaded.Search(query, 5)
+	same := loaded.Len() == count && loaded.Quantized() && len(loadedMatches) == len(quantizedMatches)
+	for i := range loadedMatches {
+		same = same && loadedMatches[i] == quantizedMatches[i]
+	}
+	if !same {
+		log.Printf("❌ Save/Load: expected %+v, got %+v", quantizedMatches, loadedMatches)
+		passed = false
+	} else {
+		log.Printf("✅ Saved and loaded index returns the same matches")
+	}
+	return passed
+}
+
+func abs32(v float32) float32 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
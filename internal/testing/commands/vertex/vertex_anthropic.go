@@ -4,9 +4,7 @@ import (
 	"context"
 	"encoding/base64"
 	"fmt"
-	"mime"
 	"os"
-	"path/filepath"
 	"strings"
 
 	llmproviders "github.com/manishiitg/multi-llm-provider-go"
@@ -553,32 +551,12 @@ func RunVertexAnthropicImageTest(modelID, imagePath, imageURL string) error {
 			return fmt.Errorf("failed to read image file: %w", err)
 		}
 
-		// Detect MIME type from file extension
-		ext := strings.ToLower(filepath.Ext(imagePath))
-		mediaType := mime.TypeByExtension(ext)
-		if mediaType == "" {
-			// Fallback to common types
-			switch ext {
-			case ".jpg", ".jpeg":
-				mediaType = "image/jpeg"
-			case ".png":
-				mediaType = "image/png"
-			case ".gif":
-				mediaType = "image/gif"
-			case ".webp":
-				mediaType = "image/webp"
-			default:
-				return fmt.Errorf("unsupported image format: %s. Supported: JPEG, PNG, GIF, WebP", ext)
-			}
-		}
-
-		// Encode to base64
+		// Encode to base64; the adapter detects the MIME type from the data
 		base64Data := base64.StdEncoding.EncodeToString(imageData)
-		logger.Infof("✅ Image loaded: %d bytes, MIME type: %s", len(imageData), mediaType)
+		logger.Infof("✅ Image loaded: %d bytes", len(imageData))
 
 		imageParts = append(imageParts, llmtypes.ImageContent{
 			SourceType: "base64",
-			MediaType:  mediaType,
 			Data:       base64Data,
 		})
 	} else if imageURL != "" {
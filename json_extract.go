@@ -0,0 +1,144 @@
+package llmproviders
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+// ErrNoJSON is returned by ParseJSONFromContent when content holds no JSON object or array
+var ErrNoJSON = errors.New("no JSON object or array found in content")
+
+// ParseJSONFromContent extracts the JSON document from a model's answer: the whole content
+// when it is JSON, else the first fenced code block that is, else the first balanced
+// object or array that is valid JSON, skipping preamble ("Here's your JSON:") and any text
+// after it. Braces and brackets inside JSON strings are handled. It returns ErrNoJSON when
+// nothing valid is found.
+func ParseJSONFromContent(content string) (json.RawMessage, error) {
+	content = strings.TrimSpace(content)
+	if content != "" && json.Valid([]byte(content)) {
+		return json.RawMessage(content), nil
+	}
+	for _, block := range fencedBlocks(content) {
+		if block != "" && json.Valid([]byte(block)) {
+			return json.RawMessage(block), nil
+		}
+	}
+	for start := 0; start < len(content); start++ {
+		if content[start] != '{' && content[start] != '[' {
+			continue
+		}
+		if end := balancedEnd(content, start); end > 0 && json.Valid([]byte(content[start:end])) {
+			return json.RawMessage(content[start:end]), nil
+		}
+	}
+	return nil, ErrNoJSON
+}
+
+// fencedBlocks returns the trimmed bodies of the markdown code blocks in content, in order.
+// The language tag after the opening fence is dropped; an unclosed block runs to the end.
+func fencedBlocks(content string) []string {
+	var blocks []string
+	for {
+		start := strings.Index(content, "```")
+		if start < 0 {
+			return blocks
+		}
+		body := content[start+3:]
+		if newline := strings.IndexByte(body, '\n'); newline >= 0 && !strings.ContainsAny(body[:newline], "{[") {
+			body = body[newline+1:]
+		}
+		end := strings.Index(body, "```")
+		if end < 0 {
+			return append(blocks, strings.TrimSpace(body))
+		}
+		blocks = append(blocks, strings.TrimSpace(body[:end]))
+		content = body[end+3:]
+	}
+}
+
+// balancedEnd returns the end of the object or array opening at content[start], skipping
+// brackets inside strings, or -1 if it is not closed
+func balancedEnd(content string, start int) int {
+	var stack []byte
+	inString, escaped := false, false
+	for i := start; i < len(content); i++ {
+		c := content[i]
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			}
+			continue
+		}
+		switch c {
+		case '"':
+			inString = true
+		case '{':
+			stack = append(stack, '}')
+		case '[':
+			stack = append(stack, ']')
+		case '}', ']':
+			if len(stack) == 0 || stack[len(stack)-1] != c {
+				return -1
+			}
+			stack = stack[:len(stack)-1]
+			if len(stack) == 0 {
+				return i + 1
+			}
+		}
+	}
+	return -1
+}
+
+// extractJSONDocument returns the JSON document in content (ParseJSONFromContent), or the
+// trimmed content when there is none
+func extractJSONDocument(content string) string {
+	if document, err := ParseJSONFromContent(content); err == nil {
+		return string(document)
+	}
+	return strings.TrimSpace(content)
+}
+
+// jsonRequested reports whether opts ask for JSON content: JSON mode, a JSON schema,
+// structured output or a JSON response MIME type
+func jsonRequested(opts *llmtypes.CallOptions) bool {
+	return opts.JSONMode || opts.JSONSchema != nil || opts.StructuredOutput != nil || opts.ResponseMimeType == "application/json"
+}
+
+// setParsedJSON sets resp.ParsedJSON and resp.JSONValid from the first choice: the arguments
+// of the forced tool call when a tool is forced, else the JSON document of the content when
+// opts ask for JSON. Responses asking for neither are left unchanged.
+func setParsedJSON(resp *llmtypes.ContentResponse, opts *llmtypes.CallOptions) {
+	if len(resp.Choices) == 0 || resp.Choices[0] == nil {
+		return
+	}
+	choice := resp.Choices[0]
+	if name := forcedToolName(opts.ToolChoice); name != "" {
+		for _, call := range choice.ToolCalls {
+			if call.FunctionCall == nil || call.FunctionCall.Name != name {
+				continue
+			}
+			arguments := strings.TrimSpace(call.FunctionCall.Arguments)
+			resp.JSONValid = arguments != "" && json.Valid([]byte(arguments))
+			if resp.JSONValid {
+				resp.ParsedJSON = json.RawMessage(arguments)
+			}
+			return
+		}
+	}
+	if !jsonRequested(opts) {
+		return
+	}
+	if document, err := ParseJSONFromContent(choice.Content); err == nil {
+		resp.ParsedJSON, resp.JSONValid = document, true
+		return
+	}
+	resp.ParsedJSON, resp.JSONValid = nil, false
+}
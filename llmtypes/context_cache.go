@@ -0,0 +1,21 @@
+package llmtypes
+
+import (
+	"context"
+	"time"
+)
+
+// CacheName identifies a context cache created on the provider, e.g. "cachedContents/abc123"
+type CacheName string
+
+// ContextCacheModel is implemented by models with explicit server-side context caching
+// (Gemini): a large shared prefix is uploaded once and later requests reference it by name
+// (WithCachedContent) instead of resending it. This differs from Anthropic's inline
+// cache_control, which needs no separate call.
+type ContextCacheModel interface {
+	// CreateCachedContent caches messages, plus the tools and tool choice of options, for ttl.
+	// System messages become the cache's system instruction.
+	CreateCachedContent(ctx context.Context, messages []MessageContent, ttl time.Duration, options ...CallOption) (CacheName, error)
+	// DeleteCachedContent deletes a cache before it expires
+	DeleteCachedContent(ctx context.Context, name CacheName) error
+}
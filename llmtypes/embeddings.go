@@ -1,6 +1,9 @@
 package llmtypes
 
-import "context"
+import (
+	"context"
+	"strings"
+)
 
 // EmbeddingModel is an interface for models that support embedding generation
 // This is separate from the Model interface since not all models support embeddings
@@ -10,3 +13,34 @@ type EmbeddingModel interface {
 	// Returns an EmbeddingResponse with embeddings and usage information
 	GenerateEmbeddings(ctx context.Context, input interface{}, options ...EmbeddingOption) (*EmbeddingResponse, error)
 }
+
+// EmbeddingTaskType tells the embedding model what the embeddings are for. For retrieval,
+// documents and queries are embedded differently (asymmetric retrieval): embed documents
+// with EmbeddingTaskRetrievalDocument and search queries with EmbeddingTaskRetrievalQuery.
+// Each adapter maps it to its provider's field (Vertex AI task_type, Cohere input_type).
+type EmbeddingTaskType string
+
+const (
+	EmbeddingTaskRetrievalQuery     EmbeddingTaskType = "retrieval_query"
+	EmbeddingTaskRetrievalDocument  EmbeddingTaskType = "retrieval_document"
+	EmbeddingTaskClassification     EmbeddingTaskType = "classification"
+	EmbeddingTaskClustering         EmbeddingTaskType = "clustering"
+	EmbeddingTaskSemanticSimilarity EmbeddingTaskType = "semantic_similarity"
+)
+
+// embeddingInputTypeAliases maps provider input type names to task types
+var embeddingInputTypeAliases = map[string]EmbeddingTaskType{
+	"search_query":    EmbeddingTaskRetrievalQuery,
+	"search_document": EmbeddingTaskRetrievalDocument,
+}
+
+// ParseEmbeddingTaskType returns the task type named by inputType, which may be a task type
+// or a provider name for one ("search_query", "RETRIEVAL_DOCUMENT"). Names it doesn't know
+// are returned lowercased, for the adapter to accept or reject.
+func ParseEmbeddingTaskType(inputType string) EmbeddingTaskType {
+	name := strings.ToLower(strings.TrimSpace(inputType))
+	if taskType, ok := embeddingInputTypeAliases[name]; ok {
+		return taskType
+	}
+	return EmbeddingTaskType(name)
+}
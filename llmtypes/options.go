@@ -1,5 +1,7 @@
 package llmtypes
 
+import "time"
+
 // WithModel sets the model ID
 func WithModel(model string) CallOption {
 	return func(opts *CallOptions) {
@@ -68,6 +70,8 @@ func WithToolChoiceString(choiceType string) CallOption {
 // WithStreamingChan sets the streaming channel for receiving chunks
 // The channel receives structured StreamChunk objects that can be either content or tool calls
 // The channel will be closed when streaming completes
+// If the context is cancelled mid-stream, ProviderAwareLLM returns the partial response
+// streamed so far together with the context error
 func WithStreamingChan(ch chan<- StreamChunk) CallOption {
 	return func(opts *CallOptions) {
 		opts.StreamChan = ch
@@ -149,6 +153,23 @@ func WithDimensions(dimensions int) EmbeddingOption {
 	}
 }
 
+// WithEmbeddingTaskType sets what the embeddings are for, e.g. EmbeddingTaskRetrievalQuery for
+// search queries and EmbeddingTaskRetrievalDocument for the documents searched, so one model
+// embeds both sides of asymmetric retrieval. Task types a model doesn't support are rejected;
+// models without task types (OpenAI, Amazon Titan) ignore it.
+func WithEmbeddingTaskType(taskType EmbeddingTaskType) EmbeddingOption {
+	return func(opts *EmbeddingOptions) {
+		opts.TaskType = taskType
+	}
+}
+
+// WithInputType sets the embedding task type by name, accepting provider names such as the
+// Cohere input_type ("search_query", "search_document", "classification", "clustering") or the
+// Vertex AI task_type ("RETRIEVAL_QUERY"). See WithEmbeddingTaskType.
+func WithInputType(inputType string) EmbeddingOption {
+	return WithEmbeddingTaskType(ParseEmbeddingTaskType(inputType))
+}
+
 // WithReasoningEffort sets the reasoning effort level for models that support it (e.g., gpt-5.1)
 // Valid values: "minimal", "low", "medium", "high"
 // When set to "minimal", the model uses minimal reasoning effort
@@ -177,3 +198,565 @@ func WithThinkingLevel(level string) CallOption {
 		opts.ThinkingLevel = level
 	}
 }
+
+// WithServiceTier sets the service tier, trading latency for cost
+// Valid values: "auto", "default", "flex", "priority"
+// OpenAI passes the tier through as service_tier; Anthropic maps "auto"/"priority" to "auto"
+// and "default"/"standard" to "standard_only". Other providers ignore it.
+// The tier actually used is reported in GenerationInfo.Additional["service_tier"].
+func WithServiceTier(tier string) CallOption {
+	return func(opts *CallOptions) {
+		opts.ServiceTier = tier
+	}
+}
+
+// WithN sets the number of choices (completions) to generate
+// OpenAI and Gemini generate them natively (n / candidateCount); other providers
+// emulate it with concurrent requests whose choices are merged in order.
+// Streaming with n > 1 always uses concurrent requests; chunks carry their ChoiceIndex.
+func WithN(n int) CallOption {
+	return func(opts *CallOptions) {
+		opts.N = n
+	}
+}
+
+// WithAbortOnToolCall stops a streaming request as soon as the first complete tool call is received
+// The provider request is cancelled and the response holds the content streamed so far plus
+// the tool call, with StopReason "tool_calls". Usage is not available for aborted requests.
+// The stream channel still receives a finish chunk and is closed. Has no effect without streaming.
+func WithAbortOnToolCall() CallOption {
+	return func(opts *CallOptions) {
+		opts.AbortOnToolCall = true
+	}
+}
+
+// WithAssistantPrefill makes the assistant response start with the given text (e.g. "{" to force JSON)
+// Anthropic (direct and on Vertex AI) and Bedrock Claude models prefill the assistant turn natively
+// and the prefill is prepended to the returned content; trailing whitespace is removed from it.
+// Other providers are instructed to begin with the prefill, and the call fails if they do not.
+func WithAssistantPrefill(prefill string) CallOption {
+	return func(opts *CallOptions) {
+		opts.AssistantPrefill = prefill
+	}
+}
+
+// WithDryRun builds the provider request without calling the API
+// GenerateContent returns a ContentResponse with DryRun set, no choices, and Raw holding
+// the fully converted provider request (after all options are applied)
+func WithDryRun() CallOption {
+	return func(opts *CallOptions) {
+		opts.DryRun = true
+	}
+}
+
+// WithReasoningVisibility controls whether reasoning from thinking models is streamed
+// "visible" streams reasoning as StreamChunkTypeReasoning chunks, "summary" asks the provider
+// for reasoning summaries where supported (e.g. Gemini thought summaries) and streams those,
+// and "hidden" (the default) suppresses reasoning chunks. Reasoning tokens are reported in
+// usage regardless of visibility.
+func WithReasoningVisibility(visibility string) CallOption {
+	return func(opts *CallOptions) {
+		opts.ReasoningVisibility = visibility
+	}
+}
+
+// WithStructuredOutput requests a JSON response conforming to schema without choosing a
+// provider-specific mechanism. The best strategy for the model is picked automatically
+// (native JSON schema, a forced tool call, or JSON mode with schema instructions) and the
+// first choice's Content always holds the JSON document.
+func WithStructuredOutput(schema map[string]interface{}, name string, strict bool) CallOption {
+	return func(opts *CallOptions) {
+		opts.StructuredOutput = &JSONSchemaConfig{
+			Name:   name,
+			Schema: schema,
+			Strict: strict,
+		}
+	}
+}
+
+// WithResponseMimeType sets the response MIME type (Gemini responseMimeType), e.g. "text/plain",
+// "application/json" or "text/x.enum". A JSON schema always implies "application/json"
+// unless "text/x.enum" is requested.
+func WithResponseMimeType(mimeType string) CallOption {
+	return func(opts *CallOptions) {
+		opts.ResponseMimeType = mimeType
+	}
+}
+
+// WithResponseModalities sets the output modalities (Gemini responseModalities), e.g.
+// "TEXT" and "IMAGE" for models that can generate images. Generated images are returned
+// in ContentChoice.Images.
+func WithResponseModalities(modalities ...string) CallOption {
+	return func(opts *CallOptions) {
+		opts.ResponseModalities = modalities
+	}
+}
+
+// WithToolResultMaxTokens summarizes tool results longer than maxTokens (estimated) before
+// they are sent, so large file contents or API dumps don't fill the context. The summary
+// replaces the result's text, with a note that it was summarized; images are kept. JSON
+// results are sent verbatim unless their tool is listed in WithSummarizeStructuredToolResults.
+// Summaries are written by WithToolResultSummarizer, or the call's model without it.
+func WithToolResultMaxTokens(maxTokens int) CallOption {
+	return func(opts *CallOptions) {
+		opts.ToolResultMaxTokens = maxTokens
+	}
+}
+
+// WithToolResultSummarizer sets the model that summarizes long tool results, usually a
+// cheap, fast one (see WithToolResultMaxTokens)
+func WithToolResultSummarizer(model Model) CallOption {
+	return func(opts *CallOptions) {
+		opts.ToolResultSummarizer = model
+	}
+}
+
+// WithSummarizeStructuredToolResults lets WithToolResultMaxTokens summarize the JSON results
+// of these tools, which are otherwise always sent verbatim
+func WithSummarizeStructuredToolResults(toolNames ...string) CallOption {
+	return func(opts *CallOptions) {
+		opts.SummarizeStructuredTools = append(opts.SummarizeStructuredTools, toolNames...)
+	}
+}
+
+// WithToolResultImageMaxBytes downscales tool result images (e.g. large screenshots) that are
+// larger than maxBytes or exceed the provider's size or dimension limits, re-encoding them
+// as JPEG. Without it, such images fail with a descriptive error before the request is sent.
+func WithToolResultImageMaxBytes(maxBytes int) CallOption {
+	return func(opts *CallOptions) {
+		opts.ToolResultImageMaxBytes = maxBytes
+	}
+}
+
+// WithImageAutoConvert converts base64 images in formats the provider does not accept (e.g.
+// GIF for Gemini, BMP or TIFF for any provider) to PNG, or JPEG where PNG is not accepted,
+// before the request is sent. Without it, an unsupported format detected from the data fails
+// with a descriptive error.
+func WithImageAutoConvert() CallOption {
+	return func(opts *CallOptions) {
+		opts.ImageAutoConvert = true
+	}
+}
+
+// WithMaxImageCount fails calls holding more than n images, counting tool result images,
+// before the request is sent. Requests are always checked against the provider's own limit
+// (e.g. 20 images on Bedrock, 100 on Anthropic).
+func WithMaxImageCount(n int) CallOption {
+	return func(opts *CallOptions) {
+		opts.MaxImageCount = n
+	}
+}
+
+// WithImageDownsampling downscales the largest base64 images, re-encoding them as JPEG, when
+// the images of a request add up to more than the provider accepts in one request (e.g. 32 MB
+// on Anthropic, 20 MB on Gemini). Without it, such requests fail with a descriptive error
+// before they are sent.
+func WithImageDownsampling() CallOption {
+	return func(opts *CallOptions) {
+		opts.ImageDownsampling = true
+	}
+}
+
+// WithAutoContinue continues output that stops at the token limit, issuing up to
+// maxContinuations follow-up requests and stitching their content into the first choice.
+// If a continuation ends in tool calls, they are returned on the stitched choice.
+func WithAutoContinue(maxContinuations int) CallOption {
+	return func(opts *CallOptions) {
+		opts.AutoContinue = maxContinuations
+	}
+}
+
+// WithMaxInputTokens trims the oldest messages before sending when the estimated input
+// exceeds maxTokens. System messages and the current turn (the last user message and the
+// tool calls and results answering it) are kept, and tool calls are dropped together with
+// their results. This is a budget guard, not the model's context limit: the token count is
+// an offline estimate.
+func WithMaxInputTokens(maxTokens int) CallOption {
+	return func(opts *CallOptions) {
+		opts.MaxInputTokens = maxTokens
+	}
+}
+
+// WithToolEmulation lets models without native tool calling use tools: ProviderAwareLLM
+// describes the tools in the system prompt, asks for tagged JSON tool call blocks and parses
+// them back into ToolCalls. Models with native tool support are called normally.
+func WithToolEmulation() CallOption {
+	return func(opts *CallOptions) {
+		opts.ToolEmulation = true
+	}
+}
+
+// WithStreamUsage sends StreamChunkTypeUsage chunks with the running token usage whenever
+// the provider reports it while streaming (OpenAI's final usage chunk, Anthropic's
+// message_start and message_delta, Bedrock's metadata event, Gemini's usageMetadata).
+// The finish chunk carries the final usage either way.
+func WithStreamUsage() CallOption {
+	return func(opts *CallOptions) {
+		opts.StreamUsage = true
+	}
+}
+
+// WithStreamEventHook calls hook with every raw event of the provider stream, before the
+// adapter parses it: Bedrock ConverseStreamOutput member types, OpenAI chat completion
+// chunks or Responses API events, Anthropic message stream events, Gemini
+// GenerateContentResponses and, for Claude on Vertex, the SSE data as json.RawMessage.
+// It is meant for debugging how a provider streams, e.g. tool call arguments; the hook
+// runs on the streaming goroutine and must not retain or modify the events.
+func WithStreamEventHook(hook func(event interface{})) CallOption {
+	return func(opts *CallOptions) {
+		opts.StreamEventHook = hook
+	}
+}
+
+// WithStripReasoningTags removes reasoning that models without native reasoning output
+// write into their answer, such as <thinking>...</thinking>, from Content and from streamed
+// content chunks. tags are the tag names, "thinking" and "think" when none are given. The
+// removed spans become reasoning blocks of the choice and, with WithReasoningVisibility
+// "visible" or "summary", are streamed as reasoning chunks. For JSON mode and structured
+// output only spans before the JSON are removed, so JSON values containing a tag are kept.
+func WithStripReasoningTags(tags []string) CallOption {
+	if len(tags) == 0 {
+		tags = []string{"thinking", "think"}
+	}
+	return func(opts *CallOptions) {
+		opts.StripReasoningTags = tags
+	}
+}
+
+// WithEnforceRequiredToolArgs checks the tool calls of the response against the required
+// parameters of their tools. When a call misses some (Bedrock models don't always enforce
+// them), ProviderAwareLLM sends one follow-up telling the model which parameters it must
+// provide and merges the arguments of its corrected call into the original call, which
+// keeps its ID. Calls still incomplete after the follow-up are returned as they are. When
+// streaming, the tool call chunks already sent are not corrected; the response is.
+func WithEnforceRequiredToolArgs() CallOption {
+	return func(opts *CallOptions) {
+		opts.EnforceRequiredToolArgs = true
+	}
+}
+
+// WithFallbackModels tries models in order, on the same provider, when the call fails with a
+// retryable error (rate limiting, overload or a server error), e.g. to escalate from a
+// cheap model. Tools, streaming and other options are kept for every attempt.
+func WithFallbackModels(models []string) CallOption {
+	return func(opts *CallOptions) {
+		opts.FallbackModels = models
+	}
+}
+
+// WithStreamStallTimeout cancels a streaming call when no chunk arrives from the provider for
+// d, including the wait for the first chunk, and fails it with an error matching
+// ErrStreamStalled. What was streamed until then is returned along with the error.
+// Non-streaming calls ignore it.
+func WithStreamStallTimeout(d time.Duration) CallOption {
+	return func(opts *CallOptions) {
+		opts.StreamStallTimeout = d
+	}
+}
+
+// WithStreamHeartbeat sends a StreamChunkTypeHeartbeat chunk every interval while no other
+// chunk arrives on a streaming call, so a UI can show the model is still working.
+// Heartbeats carry nothing and are not part of the response.
+func WithStreamHeartbeat(interval time.Duration) CallOption {
+	return func(opts *CallOptions) {
+		opts.StreamHeartbeat = interval
+	}
+}
+
+// WithStreamTextOnly sends only text (StreamChunkTypeContent) chunks on the streaming
+// channel, dropping tool call, usage and finish chunks. Heartbeats requested with
+// WithStreamHeartbeat are still sent. Tool calls are still returned in the final
+// ContentResponse, whose content equals the concatenated text chunks.
+func WithStreamTextOnly() CallOption {
+	return func(opts *CallOptions) {
+		opts.StreamTextOnly = true
+	}
+}
+
+// WithStreamBuffering regroups streamed content chunks: StreamBufferingLine sends whole
+// lines and StreamBufferingSentence whole sentences (e.g. for speech synthesis), instead
+// of the provider's token-sized deltas. The concatenated content is unchanged and other
+// chunks, such as tool calls, are sent immediately. StreamBufferingToken is the default.
+func WithStreamBuffering(mode StreamBufferingMode) CallOption {
+	return func(opts *CallOptions) {
+		opts.StreamBuffering = mode
+	}
+}
+
+// WithLogitBias adjusts the likelihood of specific tokens, mapping token IDs of the
+// model's tokenizer to a bias from -100 (ban the token) to 100 (force it). Biases are
+// rounded to integers. Only OpenAI and OpenAI-compatible providers (logit_bias) support
+// it; other providers ignore it.
+func WithLogitBias(bias map[int]float64) CallOption {
+	return func(opts *CallOptions) {
+		opts.LogitBias = bias
+	}
+}
+
+// WithSingleFlight coalesces concurrent identical calls (same model, messages and options)
+// into one upstream call whose result is shared, saving cost and quota when the same
+// deterministic request is issued several times at once. Streaming and multi-choice calls,
+// and calls with interceptors, validators, message transforms, a stream event hook or a
+// retry policy's Retryable, are never coalesced. Only use it where any caller may receive
+// another caller's result.
+func WithSingleFlight() CallOption {
+	return func(opts *CallOptions) {
+		opts.SingleFlight = true
+	}
+}
+
+// WithDisableTools makes the model answer in text for this call, e.g. in the final turn of
+// an agent, regardless of WithTools anywhere in the options. Tool calls and results already
+// in the history are still sent; providers that require tool definitions alongside them
+// get the tools with tool choice "none" instead.
+func WithDisableTools() CallOption {
+	return func(opts *CallOptions) {
+		opts.DisableTools = true
+	}
+}
+
+// WithRegion requires the call to be served in region: it fails before anything is sent
+// unless the model was initialized with the same Config.Region, so data residency
+// requirements can be asserted per call
+func WithRegion(region string) CallOption {
+	return func(opts *CallOptions) {
+		opts.Region = region
+	}
+}
+
+// WithStreamFallback controls streaming calls the provider cannot stream for the model and
+// options, e.g. OpenAI models that need a verified organization to stream. When enabled, the
+// call is made (or repeated, if the provider rejects streaming before sending anything)
+// without streaming, and the response is sent to the stream channel as one content chunk,
+// the tool calls and a finish chunk per choice. When disabled (the default), such calls fail
+// with an error matching ErrStreamingUnsupported.
+func WithStreamFallback(enabled bool) CallOption {
+	return func(opts *CallOptions) {
+		opts.StreamFallback = enabled
+	}
+}
+
+// WithMaxRetries retries a failed call up to n times (0 disables retries), overriding
+// Config.MaxRetries for this call. The wrapper then owns retries: the provider SDK's own
+// retries are turned off, retries back off as set by WithRetryPolicy, and only once they are
+// exhausted does the call move on to its fallback models (WithFallbackModels). Quota errors
+// and a stream that has already sent output are never retried.
+func WithMaxRetries(n int) CallOption {
+	return func(opts *CallOptions) {
+		opts.MaxRetries = &n
+	}
+}
+
+// WithRetryPolicy sets the backoff and the retryable errors of this call's retries. Without
+// WithMaxRetries or Config.MaxRetries the call is retried up to 2 times, the SDK default.
+func WithRetryPolicy(policy RetryPolicy) CallOption {
+	return func(opts *CallOptions) {
+		opts.RetryPolicy = &policy
+	}
+}
+
+// WithRetryOnEmptyContent repeats the call, up to n times, when the provider answers
+// successfully with no content and no tool calls, which a flaky provider often fixes on the
+// next attempt. Responses stopped by a content filter or safety block are not retried, nor
+// is a stream that has already sent output. These retries don't count towards WithMaxRetries.
+func WithRetryOnEmptyContent(n int) CallOption {
+	return func(opts *CallOptions) {
+		opts.RetryOnEmptyContent = n
+	}
+}
+
+// WithAllowedTools limits the tools the model may call this turn to names, a subset of
+// WithTools, so an agent can stage tools per phase without rebuilding the tool list. Gemini
+// gets every tool with allowedFunctionNames; other providers are only sent the allowed
+// tools. A response calling any other tool is an error, and an empty list allows no tool
+// (as WithDisableTools).
+func WithAllowedTools(names []string) CallOption {
+	return func(opts *CallOptions) {
+		opts.AllowedTools = names
+		if opts.AllowedTools == nil {
+			opts.AllowedTools = []string{}
+		}
+	}
+}
+
+// WithCachedContent answers from the context cache name, created with CreateCachedContent,
+// followed by the call's messages, which should only hold what comes after the cached prefix.
+// Gemini only: the cache carries the tools, so the call's tools are not sent. Cached tokens
+// are reported in GenerationInfo.CachedContentTokens.
+func WithCachedContent(name CacheName) CallOption {
+	return func(opts *CallOptions) {
+		opts.CachedContent = name
+	}
+}
+
+// WithMaxToolCallsPerResponse caps the tool calls of each choice at the first n, in the
+// order the model sent them. Calls beyond n are dropped from the response and are not
+// streamed, including emulated tool calls (WithToolEmulation). The event emitter is told
+// when calls are dropped if it implements ToolCallTruncateEmitter.
+func WithMaxToolCallsPerResponse(n int) CallOption {
+	return func(opts *CallOptions) {
+		opts.MaxToolCallsPerResponse = n
+	}
+}
+
+// WithToolNameSanitization controls renaming of tool names that providers reject. Names
+// must match [a-zA-Z0-9_-], start with a letter or underscore and be at most 64 characters,
+// the rules shared by OpenAI, Anthropic, Bedrock and Gemini. When enabled (the default),
+// invalid characters such as dots and spaces become underscores, long names are shortened,
+// and tool calls in the response are mapped back to the original names.
+func WithToolNameSanitization(enabled bool) CallOption {
+	return func(opts *CallOptions) {
+		opts.DisableToolNameSanitization = !enabled
+	}
+}
+
+// WithSchemaValidation validates structured output (WithStructuredOutput) against the
+// requested schema and fails with an ErrSchemaValidation error listing the violations
+func WithSchemaValidation() CallOption {
+	return func(opts *CallOptions) {
+		opts.SchemaValidation = true
+	}
+}
+
+// WithSchemaRetry validates structured output like WithSchemaValidation and, when it
+// does not conform, retries up to n times with the violations fed back to the model
+func WithSchemaRetry(n int) CallOption {
+	return func(opts *CallOptions) {
+		opts.SchemaValidation = true
+		opts.SchemaRetries = n
+	}
+}
+
+// WithRequestInterceptor adds a request interceptor that can inspect or mutate the outgoing request
+// Interceptors are run in the order they were added, before every attempt (retries and
+// fallback models included); an error aborts the call
+func WithRequestInterceptor(interceptor RequestInterceptor) CallOption {
+	return func(opts *CallOptions) {
+		opts.RequestInterceptors = append(opts.RequestInterceptors, interceptor)
+	}
+}
+
+// WithMessageTransform adds a transform that rewrites the messages just before they are
+// converted for the provider, e.g. to redact them, inject the current date or normalize the
+// history. Transforms see the portable messages after the library's own rewrites (structured
+// output, tool emulation, trimming) and run in the order they were added, after the
+// Config.MessageTransforms of the client.
+func WithMessageTransform(transform MessageTransform) CallOption {
+	return func(opts *CallOptions) {
+		opts.MessageTransforms = append(opts.MessageTransforms, transform)
+	}
+}
+
+// WithResponseInterceptor adds a response interceptor that can inspect or mutate the response
+// Interceptors are run in the order they were added, on the response of every attempt; an
+// error fails the attempt
+func WithResponseInterceptor(interceptor ResponseInterceptor) CallOption {
+	return func(opts *CallOptions) {
+		opts.ResponseInterceptors = append(opts.ResponseInterceptors, interceptor)
+	}
+}
+
+// WithResponseValidator adds a validator run on the final response, after the response
+// interceptors. Validators run in the order they were added; the first error rejects the
+// response, which is regenerated while WithValidationRetries allows and fails the call after.
+func WithResponseValidator(validator ResponseValidator) CallOption {
+	return func(opts *CallOptions) {
+		opts.ResponseValidators = append(opts.ResponseValidators, validator)
+	}
+}
+
+// WithValidationRetries regenerates a response rejected by a response validator up to n times
+func WithValidationRetries(n int) CallOption {
+	return func(opts *CallOptions) {
+		opts.ValidationRetries = n
+	}
+}
+
+// WithValidationFeedback sends the rejected answer and the validation error to the model when
+// a response is regenerated (WithValidationRetries), so it can correct itself
+func WithValidationFeedback() CallOption {
+	return func(opts *CallOptions) {
+		opts.ValidationFeedback = true
+	}
+}
+
+// WithRetryDiversity lowers the temperature by delta on each schema retry (WithSchemaRetry)
+// and validation retry (WithValidationRetries), so the retries explore different outputs
+// instead of repeating the one that failed. Without WithTemperature the retries start from
+// the provider default of 1.0. Lowering keeps the temperature within every provider's
+// range, including Claude's maximum of 1.0; once it would reach 0 the temperature is raised
+// by the same amount instead. A seed set with WithOpenAIParams or WithGeminiParams is offset
+// by the attempt number on these retries whether or not this option is set, since the same
+// seed returns the same output.
+func WithRetryDiversity(delta float64) CallOption {
+	return func(opts *CallOptions) {
+		opts.RetryDiversity = delta
+	}
+}
+
+// WithExtraBody merges arbitrary fields into the provider request body
+// Fields set by typed options win on conflict; objects are merged recursively
+// Bedrock sends these as additionalModelRequestFields
+func WithExtraBody(body map[string]interface{}) CallOption {
+	return func(opts *CallOptions) {
+		if opts.ExtraBody == nil {
+			opts.ExtraBody = make(map[string]interface{}, len(body))
+		}
+		for key, value := range body {
+			opts.ExtraBody[key] = value
+		}
+	}
+}
+
+// WithOpenAIParams sets OpenAI-native parameters for providers that use the OpenAI API
+// (OpenAI, OpenRouter). They are applied after the generic options and win over them,
+// e.g. ParallelToolCalls; WithExtraBody only fills fields still unset. Other providers
+// ignore them.
+func WithOpenAIParams(params OpenAIParams) CallOption {
+	return func(opts *CallOptions) {
+		opts.OpenAIParams = &params
+	}
+}
+
+// WithAnthropicParams sets Anthropic-native parameters for the Anthropic provider and
+// Claude models on Vertex AI. They are applied after the generic options and win over
+// them; WithExtraBody only fills fields still unset. Other providers ignore them.
+func WithAnthropicParams(params AnthropicParams) CallOption {
+	return func(opts *CallOptions) {
+		opts.AnthropicParams = &params
+	}
+}
+
+// WithGeminiParams sets Gemini-native parameters for Gemini models on Vertex AI. They are
+// applied after the generic options and win over them; WithExtraBody only fills fields
+// still unset. Other providers ignore them.
+func WithGeminiParams(params GeminiParams) CallOption {
+	return func(opts *CallOptions) {
+		opts.GeminiParams = &params
+	}
+}
+
+// WithMetadataTraceID sends traceID with the provider request so the provider's logs can be
+// correlated with ours, e.g. when debugging with provider support: the X-Client-Request-Id
+// header for OpenAI, request metadata for Bedrock (shown in its invocation logs) and the
+// X-Trace-Id header for every provider. Config.PropagateTraceID sends Config.TraceID this way
+// on every call.
+func WithMetadataTraceID(traceID string) CallOption {
+	return func(opts *CallOptions) {
+		opts.TraceID = traceID
+	}
+}
+
+// WithExtraHeaders adds arbitrary HTTP headers to the provider request
+// Headers set by the adapter itself (auth, content type) win on conflict,
+// except anthropic-beta which is combined with the adapter's betas
+func WithExtraHeaders(headers map[string]string) CallOption {
+	return func(opts *CallOptions) {
+		if opts.ExtraHeaders == nil {
+			opts.ExtraHeaders = make(map[string]string, len(headers))
+		}
+		for key, value := range headers {
+			opts.ExtraHeaders[key] = value
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package llmtypes
+
+import (
+	"context"
+)
+
+// RealtimeEventType identifies the kind of RealtimeEvent
+type RealtimeEventType string
+
+const (
+	RealtimeEventSessionStarted  RealtimeEventType = "session_started"  // The session is ready for input
+	RealtimeEventTextDelta       RealtimeEventType = "text_delta"       // Text output from the model
+	RealtimeEventAudioDelta      RealtimeEventType = "audio_delta"      // Audio output from the model
+	RealtimeEventTranscriptDelta RealtimeEventType = "transcript_delta" // Transcript of the model's audio output
+	RealtimeEventInputTranscript RealtimeEventType = "input_transcript" // Transcript of the user's audio input
+	RealtimeEventTurnComplete    RealtimeEventType = "turn_complete"    // The model finished its response
+	RealtimeEventInterrupted     RealtimeEventType = "interrupted"      // The user interrupted the model's response
+	RealtimeEventError           RealtimeEventType = "error"            // The provider reported an error
+)
+
+// RealtimeEvent is an event received from a RealtimeSession
+type RealtimeEvent struct {
+	Type RealtimeEventType
+	// Text is the text, transcript or error message
+	Text string
+	// Audio is the audio output (RealtimeEventAudioDelta)
+	Audio *AudioContent
+	// Usage is the token usage of the response, when reported (RealtimeEventTurnComplete)
+	Usage *Usage
+	// Err is the error (RealtimeEventError)
+	Err error
+}
+
+// RealtimeSession is a bidirectional, low-latency text and audio session over a WebSocket
+// (OpenAI Realtime, Gemini Live). Audio input is answered automatically when the provider
+// detects the end of speech.
+type RealtimeSession interface {
+	// SendText sends a user message and asks the model to respond
+	SendText(ctx context.Context, text string) error
+	// SendAudio streams a chunk of user audio
+	SendAudio(ctx context.Context, audio AudioContent) error
+	// Events returns the events received from the provider. The channel is closed when
+	// the session ends.
+	Events() <-chan RealtimeEvent
+	// Close ends the session
+	Close() error
+}
+
+// RealtimeOptions configures a realtime session
+type RealtimeOptions struct {
+	// Instructions is the system prompt for the session
+	Instructions string
+	// Modalities are the output modalities: "text", "audio" or both (default: text)
+	Modalities []string
+	// Voice is the provider voice used for audio output
+	Voice string
+	// InputTranscription requests transcripts of the user's audio input
+	InputTranscription bool
+}
+
+// RealtimeOption is a function type for setting realtime session options
+type RealtimeOption func(*RealtimeOptions)
+
+// WithRealtimeInstructions sets the system prompt of a realtime session
+func WithRealtimeInstructions(instructions string) RealtimeOption {
+	return func(opts *RealtimeOptions) {
+		opts.Instructions = instructions
+	}
+}
+
+// WithRealtimeModalities sets the output modalities of a realtime session ("text", "audio")
+func WithRealtimeModalities(modalities ...string) RealtimeOption {
+	return func(opts *RealtimeOptions) {
+		opts.Modalities = modalities
+	}
+}
+
+// WithRealtimeVoice sets the voice used for audio output
+func WithRealtimeVoice(voice string) RealtimeOption {
+	return func(opts *RealtimeOptions) {
+		opts.Voice = voice
+	}
+}
+
+// WithRealtimeInputTranscription requests transcripts of the user's audio input
+func WithRealtimeInputTranscription() RealtimeOption {
+	return func(opts *RealtimeOptions) {
+		opts.InputTranscription = true
+	}
+}
@@ -0,0 +1,229 @@
+package llmtypes
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// NewFinishChunk builds the terminal stream chunk for a completed response.
+// Adapters send it as the last chunk before closing the stream channel so that
+// channel-only consumers can still observe the stop reason and token usage.
+func NewFinishChunk(resp *ContentResponse) StreamChunk {
+	chunk := StreamChunk{Type: StreamChunkTypeFinish}
+	if resp == nil {
+		return chunk
+	}
+	chunk.Usage = resp.Usage
+	if len(resp.Choices) > 0 && resp.Choices[0] != nil {
+		chunk.StopReason = resp.Choices[0].StopReason
+		chunk.GenerationInfo = resp.Choices[0].GenerationInfo
+		if chunk.Usage == nil {
+			chunk.Usage = ExtractUsageFromGenerationInfo(resp.Choices[0].GenerationInfo)
+		}
+	}
+	return chunk
+}
+
+// StreamAggregator reconstructs a ContentResponse from streamed chunks.
+// It is useful for consumers that only have access to the stream channel
+// (e.g. a proxy forwarding chunks) but still want the assembled result.
+// Chunks are grouped into choices by their ChoiceIndex.
+//
+// A StreamAggregator is not safe for concurrent use.
+type StreamAggregator struct {
+	choices []*choiceAggregate
+	usage   *Usage
+}
+
+// choiceAggregate holds the state accumulated for a single choice
+type choiceAggregate struct {
+	content    strings.Builder
+	toolCalls  []ToolCall
+	blocks     []ContentBlock
+	stopReason string
+	seen       bool
+	finished   bool
+}
+
+// NewStreamAggregator creates an empty StreamAggregator
+func NewStreamAggregator() *StreamAggregator {
+	return &StreamAggregator{}
+}
+
+// Add folds a single chunk into the aggregated response
+func (a *StreamAggregator) Add(chunk StreamChunk) {
+	// Running usage is superseded by the finish chunk's totals; heartbeats carry nothing
+	if chunk.Type == StreamChunkTypeUsage || chunk.Type == StreamChunkTypeHeartbeat {
+		return
+	}
+	choice := a.choice(chunk.ChoiceIndex)
+	switch chunk.Type {
+	case StreamChunkTypeContent:
+		choice.content.WriteString(chunk.Content)
+		choice.blocks = AppendTextBlock(choice.blocks, ContentBlockTypeText, chunk.Content)
+	case StreamChunkTypeReasoning:
+		choice.blocks = AppendTextBlock(choice.blocks, ContentBlockTypeReasoning, chunk.Content)
+	case StreamChunkTypeToolCall:
+		if chunk.ToolCall != nil {
+			toolCall := *chunk.ToolCall
+			if toolCall.FunctionCall != nil {
+				functionCall := *toolCall.FunctionCall
+				toolCall.FunctionCall = &functionCall
+			}
+			choice.blocks = append(choice.blocks, ContentBlock{Type: ContentBlockTypeToolCall, ToolCallIndex: len(choice.toolCalls)})
+			choice.toolCalls = append(choice.toolCalls, toolCall)
+		}
+	case StreamChunkTypeFinish:
+		choice.stopReason = chunk.StopReason
+		choice.finished = true
+		a.usage = AddUsage(a.usage, chunk.Usage)
+	}
+}
+
+// choice returns the aggregate for index, growing the slice as needed
+func (a *StreamAggregator) choice(index int) *choiceAggregate {
+	if index < 0 {
+		index = 0
+	}
+	for len(a.choices) <= index {
+		a.choices = append(a.choices, &choiceAggregate{})
+	}
+	a.choices[index].seen = true
+	return a.choices[index]
+}
+
+// Consume reads chunks from ch until it is closed and returns the aggregated response
+func (a *StreamAggregator) Consume(ch <-chan StreamChunk) *ContentResponse {
+	for chunk := range ch {
+		a.Add(chunk)
+	}
+	return a.Response()
+}
+
+// Finished reports whether a terminal finish chunk has been received for every choice seen
+func (a *StreamAggregator) Finished() bool {
+	finished := false
+	for _, choice := range a.choices {
+		if !choice.seen {
+			continue
+		}
+		if !choice.finished {
+			return false
+		}
+		finished = true
+	}
+	return finished
+}
+
+// Response returns the response assembled from the chunks received so far.
+// Choices are ordered by ChoiceIndex; there is always at least one choice.
+// StopReason and Usage are only populated once finish chunks have been seen,
+// and Usage is summed across finish chunks. The content of unfinished choices leaves out
+// a character whose UTF-8 bytes were split across chunks and have not all arrived.
+func (a *StreamAggregator) Response() *ContentResponse {
+	aggregates := a.choices
+	if len(aggregates) == 0 {
+		aggregates = []*choiceAggregate{{}}
+	}
+	choices := make([]*ContentChoice, 0, len(aggregates))
+	for _, aggregate := range aggregates {
+		choice := &ContentChoice{
+			Content:    aggregate.content.String(),
+			StopReason: aggregate.stopReason,
+		}
+		if len(aggregate.toolCalls) > 0 {
+			choice.ToolCalls = append([]ToolCall(nil), aggregate.toolCalls...)
+		}
+		if len(aggregate.blocks) > 0 {
+			choice.Blocks = append([]ContentBlock(nil), aggregate.blocks...)
+		}
+		if !aggregate.finished {
+			trimIncompleteRune(choice)
+		}
+		choices = append(choices, choice)
+	}
+	return &ContentResponse{
+		Choices: choices,
+		Usage:   AddUsage(a.usage, nil),
+	}
+}
+
+// trimIncompleteRune removes the bytes of a character split across chunks from the end of
+// the content of choice and of its last text block
+func trimIncompleteRune(choice *ContentChoice) {
+	content := choice.Content
+	start := len(content) - 1
+	for start > 0 && start >= len(content)-utf8.UTFMax && !utf8.RuneStart(content[start]) {
+		start--
+	}
+	if start < 0 || utf8.FullRuneInString(content[start:]) {
+		return
+	}
+	cut := len(content) - start
+	choice.Content = content[:start]
+	for i := len(choice.Blocks) - 1; i >= 0; i-- {
+		if block := &choice.Blocks[i]; block.Type == ContentBlockTypeText {
+			if strings.HasSuffix(block.Text, content[start:]) {
+				block.Text = block.Text[:len(block.Text)-cut]
+			}
+			break
+		}
+	}
+}
+
+// AddUsage returns the sum of two usages, treating nil as zero.
+// Neither argument is modified; the result is nil only if both are nil.
+func AddUsage(a, b *Usage) *Usage {
+	if a == nil && b == nil {
+		return nil
+	}
+	sum := &Usage{}
+	for _, usage := range []*Usage{a, b} {
+		if usage == nil {
+			continue
+		}
+		sum.InputTokens += usage.InputTokens
+		sum.OutputTokens += usage.OutputTokens
+		sum.TotalTokens += usage.TotalTokens
+		sum.ReasoningTokens = addIntPtr(sum.ReasoningTokens, usage.ReasoningTokens)
+		sum.ThoughtsTokens = addIntPtr(sum.ThoughtsTokens, usage.ThoughtsTokens)
+		sum.CacheTokens = addIntPtr(sum.CacheTokens, usage.CacheTokens)
+		sum.Cost = addFloatPtr(sum.Cost, usage.Cost)
+	}
+	return sum
+}
+
+// addFloatPtr returns the sum of two optional floats, or nil if both are nil
+func addFloatPtr(a, b *float64) *float64 {
+	if a == nil && b == nil {
+		return nil
+	}
+	sum := 0.0
+	if a != nil {
+		sum += *a
+	}
+	if b != nil {
+		sum += *b
+	}
+	return &sum
+}
+
+// addIntPtr returns the sum of two optional ints, or nil if both are nil
+func addIntPtr(a, b *int) *int {
+	if a == nil && b == nil {
+		return nil
+	}
+	sum := 0
+	if a != nil {
+		sum += *a
+	}
+	if b != nil {
+		sum += *b
+	}
+	return &sum
+}
+
+// AggregateStream drains ch and returns the reconstructed ContentResponse
+func AggregateStream(ch <-chan StreamChunk) *ContentResponse {
+	return NewStreamAggregator().Consume(ch)
+}
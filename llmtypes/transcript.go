@@ -0,0 +1,198 @@
+package llmtypes
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// TranscriptVersion is the version of the format written by MarshalTranscript
+const TranscriptVersion = 1
+
+// Part types used as the "type" discriminator of serialized content parts
+const (
+	PartTypeText       = "text"
+	PartTypeImage      = "image"
+	PartTypeAudio      = "audio"
+	PartTypeToolCall   = "tool_call"
+	PartTypeToolResult = "tool_result"
+)
+
+// transcript is the portable JSON form of a conversation
+type transcript struct {
+	Version  int              `json:"version"`
+	Messages []MessageContent `json:"messages"`
+}
+
+// transcriptMessage is the JSON form of a MessageContent
+type transcriptMessage struct {
+	Role  ChatMessageType  `json:"role"`
+	Name  string           `json:"name,omitempty"`
+	Parts []transcriptPart `json:"parts,omitempty"`
+}
+
+// transcriptPart is the JSON form of a ContentPart; Type selects which fields are used
+type transcriptPart struct {
+	Type string `json:"type"`
+
+	// text
+	Text string `json:"text,omitempty"`
+
+	// image, audio
+	SourceType string `json:"source_type,omitempty"`
+	MediaType  string `json:"media_type,omitempty"`
+	Data       string `json:"data,omitempty"`
+
+	// tool_call
+	ID               string              `json:"id,omitempty"`
+	ToolType         string              `json:"tool_type,omitempty"`
+	Function         *transcriptFunction `json:"function,omitempty"`
+	ThoughtSignature string              `json:"thought_signature,omitempty"`
+
+	// tool_result
+	ToolCallID string           `json:"tool_call_id,omitempty"`
+	Name       string           `json:"name,omitempty"`
+	Content    string           `json:"content,omitempty"`
+	IsError    bool             `json:"is_error,omitempty"`
+	Parts      []transcriptPart `json:"parts,omitempty"`
+}
+
+// transcriptFunction is the JSON form of a FunctionCall
+type transcriptFunction struct {
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
+}
+
+// MarshalTranscript serializes a conversation to a versioned, portable JSON transcript.
+// Every content part is tagged with its type (see MessageContent.MarshalJSON), so
+// UnmarshalTranscript restores text, images, tool calls and tool results as the same
+// concrete types.
+func MarshalTranscript(messages []MessageContent) ([]byte, error) {
+	if messages == nil {
+		messages = []MessageContent{}
+	}
+	return json.Marshal(transcript{Version: TranscriptVersion, Messages: messages})
+}
+
+// UnmarshalTranscript restores a conversation written by MarshalTranscript
+func UnmarshalTranscript(data []byte) ([]MessageContent, error) {
+	var t transcript
+	if err := json.Unmarshal(data, &t); err != nil {
+		return nil, fmt.Errorf("invalid transcript: %w", err)
+	}
+	if t.Version > TranscriptVersion {
+		return nil, fmt.Errorf("unsupported transcript version %d (latest supported is %d)", t.Version, TranscriptVersion)
+	}
+	if t.Messages == nil {
+		t.Messages = []MessageContent{}
+	}
+	return t.Messages, nil
+}
+
+// MarshalJSON encodes the message with a "type" discriminator ("text", "image", "audio",
+// "tool_call" or "tool_result") on every part, so the ContentPart interface values can
+// be decoded back into their concrete types
+func (m MessageContent) MarshalJSON() ([]byte, error) {
+	parts, err := encodeParts(m.Parts)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(transcriptMessage{Role: m.Role, Name: m.Name, Parts: parts})
+}
+
+// UnmarshalJSON decodes a message written by MarshalJSON, restoring the concrete type of
+// every part
+func (m *MessageContent) UnmarshalJSON(data []byte) error {
+	var msg transcriptMessage
+	if err := json.Unmarshal(data, &msg); err != nil {
+		return err
+	}
+	parts, err := decodeParts(msg.Parts)
+	if err != nil {
+		return err
+	}
+	*m = MessageContent{Role: msg.Role, Name: msg.Name, Parts: parts}
+	return nil
+}
+
+// encodeParts converts content parts to their tagged JSON form
+func encodeParts(parts []ContentPart) ([]transcriptPart, error) {
+	encoded := make([]transcriptPart, 0, len(parts))
+	for i, part := range parts {
+		p, err := encodePart(part)
+		if err != nil {
+			return nil, fmt.Errorf("part %d: %w", i, err)
+		}
+		encoded = append(encoded, p)
+	}
+	return encoded, nil
+}
+
+// encodePart converts a content part to its tagged JSON form
+func encodePart(part ContentPart) (transcriptPart, error) {
+	switch p := part.(type) {
+	case TextContent:
+		return transcriptPart{Type: PartTypeText, Text: p.Text}, nil
+	case ImageContent:
+		return transcriptPart{Type: PartTypeImage, SourceType: p.SourceType, MediaType: p.MediaType, Data: p.Data}, nil
+	case AudioContent:
+		return transcriptPart{Type: PartTypeAudio, MediaType: p.MediaType, Data: p.Data}, nil
+	case ToolCall:
+		encoded := transcriptPart{Type: PartTypeToolCall, ID: p.ID, ToolType: p.Type, ThoughtSignature: p.ThoughtSignature}
+		if p.FunctionCall != nil {
+			encoded.Function = &transcriptFunction{Name: p.FunctionCall.Name, Arguments: p.FunctionCall.Arguments}
+		}
+		return encoded, nil
+	case ToolCallResponse:
+		parts, err := encodeParts(p.Parts)
+		if err != nil {
+			return transcriptPart{}, err
+		}
+		return transcriptPart{Type: PartTypeToolResult, ToolCallID: p.ToolCallID, Name: p.Name, Content: p.Content, IsError: p.IsError, Parts: parts}, nil
+	}
+	return transcriptPart{}, fmt.Errorf("unsupported content part type %T", part)
+}
+
+// decodeParts converts tagged JSON parts back to content parts
+func decodeParts(parts []transcriptPart) ([]ContentPart, error) {
+	if parts == nil {
+		return nil, nil
+	}
+	decoded := make([]ContentPart, 0, len(parts))
+	for i, p := range parts {
+		part, err := decodePart(p)
+		if err != nil {
+			return nil, fmt.Errorf("part %d: %w", i, err)
+		}
+		decoded = append(decoded, part)
+	}
+	return decoded, nil
+}
+
+// decodePart converts a tagged JSON part back to its concrete content part
+func decodePart(p transcriptPart) (ContentPart, error) {
+	switch p.Type {
+	case PartTypeText:
+		return TextContent{Text: p.Text}, nil
+	case PartTypeImage:
+		return ImageContent{SourceType: p.SourceType, MediaType: p.MediaType, Data: p.Data}, nil
+	case PartTypeAudio:
+		return AudioContent{MediaType: p.MediaType, Data: p.Data}, nil
+	case PartTypeToolCall:
+		tc := ToolCall{ID: p.ID, Type: p.ToolType, ThoughtSignature: p.ThoughtSignature}
+		if p.Function != nil {
+			tc.FunctionCall = &FunctionCall{Name: p.Function.Name, Arguments: p.Function.Arguments}
+		}
+		return tc, nil
+	case PartTypeToolResult:
+		result := ToolCallResponse{ToolCallID: p.ToolCallID, Name: p.Name, Content: p.Content, IsError: p.IsError}
+		if len(p.Parts) > 0 {
+			parts, err := decodeParts(p.Parts)
+			if err != nil {
+				return nil, err
+			}
+			result.Parts = parts
+		}
+		return result, nil
+	}
+	return nil, fmt.Errorf("unknown content part type %q", p.Type)
+}
@@ -1,6 +1,11 @@
 package llmtypes
 
-import "context"
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"time"
+)
 
 // Model is the core interface for LLM implementations
 type Model interface {
@@ -10,6 +15,13 @@ type Model interface {
 	GetModelID() string
 }
 
+// CompletionModel is implemented by models that accept a raw prompt instead of chat
+// messages, such as base and fine-tuned base models served through a legacy completions
+// endpoint. The prompt is sent as is, without any chat template.
+type CompletionModel interface {
+	Complete(ctx context.Context, prompt string, options ...CallOption) (*ContentResponse, error)
+}
+
 // ChatMessageType represents the role of a chat message
 type ChatMessageType string
 
@@ -36,7 +48,7 @@ type ImageContent struct {
 	// SourceType is either "base64" or "url"
 	SourceType string
 	// MediaType is the MIME type (e.g., "image/jpeg", "image/png", "image/gif", "image/webp")
-	// Required for base64 source type
+	// For base64 source type it is detected from the data when empty
 	MediaType string
 	// Data contains either:
 	// - Base64-encoded image data (without data: URL prefix) for SourceType "base64"
@@ -44,20 +56,48 @@ type ImageContent struct {
 	Data string
 }
 
+// AudioContent represents an audio content part
+type AudioContent struct {
+	// MediaType is the MIME type, e.g. "audio/pcm;rate=16000" for 16-bit little-endian PCM
+	MediaType string
+	// Data is the base64-encoded audio
+	Data string
+}
+
 // StreamChunkType represents the type of a streaming chunk
 type StreamChunkType string
 
 const (
-	StreamChunkTypeContent  StreamChunkType = "content"   // Text content chunk
-	StreamChunkTypeToolCall StreamChunkType = "tool_call" // Complete tool call
+	StreamChunkTypeContent   StreamChunkType = "content"   // Text content chunk
+	StreamChunkTypeToolCall  StreamChunkType = "tool_call" // Complete tool call
+	StreamChunkTypeFinish    StreamChunkType = "finish"    // Terminal chunk with stop reason and usage
+	StreamChunkTypeReasoning StreamChunkType = "reasoning" // Reasoning text, only sent when requested with WithReasoningVisibility
+	StreamChunkTypeUsage     StreamChunkType = "usage"     // Running token usage, only sent when requested with WithStreamUsage
+	StreamChunkTypeHeartbeat StreamChunkType = "heartbeat" // No-op sent while no other chunk arrives, only when requested with WithStreamHeartbeat
+)
+
+// StreamBufferingMode controls how streamed content is grouped into chunks
+type StreamBufferingMode string
+
+const (
+	StreamBufferingToken    StreamBufferingMode = "token"    // Provider deltas as they arrive (default)
+	StreamBufferingLine     StreamBufferingMode = "line"     // Whole lines, split after each newline
+	StreamBufferingSentence StreamBufferingMode = "sentence" // Whole sentences, split after sentence punctuation or newlines
 )
 
 // StreamChunk represents a single chunk in a streaming response
-// It can contain either content text or a complete tool call
+// It can contain either content text, a complete tool call, or the terminal
+// finish chunk that is sent right before the channel is closed
 type StreamChunk struct {
-	Type     StreamChunkType // Type of chunk: "content" or "tool_call"
-	Content  string          // Text content (when Type is "content")
-	ToolCall *ToolCall       // Complete tool call (when Type is "tool_call")
+	Type        StreamChunkType // Type of chunk: "content", "reasoning", "tool_call", "usage" or "finish"
+	Content     string          // Text content (when Type is "content" or "reasoning")
+	ToolCall    *ToolCall       // Complete tool call (when Type is "tool_call")
+	StopReason  string          // Stop reason reported by the provider (when Type is "finish")
+	Usage       *Usage          // Token usage for the whole response (when Type is "finish", may be nil), or so far (when Type is "usage")
+	ChoiceIndex int             // Index of the choice this chunk belongs to (0 unless n > 1)
+
+	// GenerationInfo is the provider's usage and generation metadata (when Type is "finish", may be nil)
+	GenerationInfo *GenerationInfo
 }
 
 // ToolCall represents a tool/function call request
@@ -79,28 +119,142 @@ type ToolCallResponse struct {
 	ToolCallID string
 	Name       string // Name of the tool/function that was called
 	Content    string
+	// Parts holds typed result content (TextContent and ImageContent), e.g. screenshots.
+	// It is sent after Content; providers without multimodal tool results get the images
+	// in a follow-up user message instead.
+	Parts []ContentPart `json:",omitempty"`
+	// IsError marks the result of a failed tool execution so the model can recover.
+	// It maps to the provider's native error flag, or an "Error: " prefix where there is none.
+	IsError bool `json:",omitempty"`
+}
+
+// Text returns Content followed by the text of any TextContent parts, joined by newlines
+func (r ToolCallResponse) Text() string {
+	texts := make([]string, 0, len(r.Parts)+1)
+	if r.Content != "" {
+		texts = append(texts, r.Content)
+	}
+	for _, part := range r.Parts {
+		if text, ok := part.(TextContent); ok && text.Text != "" {
+			texts = append(texts, text.Text)
+		}
+	}
+	return strings.Join(texts, "\n")
+}
+
+// Images returns the ImageContent parts of the result
+func (r ToolCallResponse) Images() []ImageContent {
+	var images []ImageContent
+	for _, part := range r.Parts {
+		if image, ok := part.(ImageContent); ok {
+			images = append(images, image)
+		}
+	}
+	return images
 }
 
 // MessageContent represents a message in the conversation
 type MessageContent struct {
 	Role  ChatMessageType
 	Parts []ContentPart
+	// Name identifies the participant in multi-agent conversations. It is sent as the
+	// message name to OpenAI and prefixed to the text ("Name: ...") for other providers.
+	Name string
 }
 
 // ContentResponse represents the response from an LLM
 type ContentResponse struct {
 	Choices []*ContentChoice
-	Usage   *Usage `json:"usage,omitempty"` // Token usage information (LLM-agnostic)
+	// Usage is the token usage and cost of the whole response, across all choices, in the
+	// same form for every provider. Per-choice provider details stay in GenerationInfo.
+	Usage *Usage `json:"usage,omitempty"`
+
+	// ParsedJSON is the JSON document of the first choice when JSON output was requested
+	// (JSON mode, a JSON schema or structured output), or the arguments of the forced tool
+	// call. It is only set when the JSON is valid, which JSONValid reports.
+	ParsedJSON json.RawMessage `json:"parsed_json,omitempty"`
+	JSONValid  bool            `json:"json_valid,omitempty"`
+
+	// DryRun is set when the request was built but not sent (see WithDryRun).
+	// Raw then holds the provider request exactly as it would have been sent.
+	DryRun bool        `json:"dry_run,omitempty"`
+	Raw    interface{} `json:"raw,omitempty"`
 }
 
 // ContentChoice represents a single choice in the response
 type ContentChoice struct {
-	Content        string
-	StopReason     string
-	ToolCalls      []ToolCall
+	Content    string
+	StopReason string
+	ToolCalls  []ToolCall
+	// Images holds images generated by the model (Gemini with WithResponseModalities)
+	Images         []ImageContent  `json:"images,omitempty"`
 	GenerationInfo *GenerationInfo `json:"generation_info,omitempty"`
 	// FuncCall is a legacy field for backwards compatibility (deprecated, use ToolCalls instead)
 	FuncCall *FunctionCall
+	// Blocks is the text, reasoning and tool calls of the choice in the order the model
+	// produced them, for providers that interleave them (Anthropic, Bedrock, Gemini). Use
+	// OrderedBlocks, which also covers choices without Blocks.
+	Blocks []ContentBlock `json:"blocks,omitempty"`
+}
+
+// ContentBlockType is the kind of a ContentBlock
+type ContentBlockType string
+
+const (
+	ContentBlockTypeText      ContentBlockType = "text"
+	ContentBlockTypeReasoning ContentBlockType = "reasoning"
+	ContentBlockTypeToolCall  ContentBlockType = "tool_call"
+)
+
+// ContentBlock is one block of a response: a text or reasoning block, or a tool call
+type ContentBlock struct {
+	Type ContentBlockType `json:"type"`
+	// Text is the text of text and reasoning blocks
+	Text string `json:"text,omitempty"`
+	// ToolCallIndex is the index in ContentChoice.ToolCalls of a tool call block
+	ToolCallIndex int `json:"tool_call_index,omitempty"`
+	// ToolCall is the tool call of a tool call block, set by ContentChoice.OrderedBlocks
+	ToolCall *ToolCall `json:"-"`
+}
+
+// AppendTextBlock adds streamed text or reasoning to blocks, extending the last block when
+// it has the same type, and returns the updated slice
+func AppendTextBlock(blocks []ContentBlock, blockType ContentBlockType, text string) []ContentBlock {
+	if text == "" {
+		return blocks
+	}
+	if n := len(blocks); n > 0 && blocks[n-1].Type == blockType {
+		blocks[n-1].Text += text
+		return blocks
+	}
+	return append(blocks, ContentBlock{Type: blockType, Text: text})
+}
+
+// OrderedBlocks returns the blocks of the choice in order with their tool calls set. Blocks
+// of tool calls no longer in ToolCalls (e.g. dropped by WithMaxToolCallsPerResponse) are left out.
+// Without Blocks, it returns Content followed by ToolCalls.
+func (c *ContentChoice) OrderedBlocks() []ContentBlock {
+	if len(c.Blocks) == 0 {
+		var blocks []ContentBlock
+		if c.Content != "" {
+			blocks = append(blocks, ContentBlock{Type: ContentBlockTypeText, Text: c.Content})
+		}
+		for i := range c.ToolCalls {
+			blocks = append(blocks, ContentBlock{Type: ContentBlockTypeToolCall, ToolCallIndex: i, ToolCall: &c.ToolCalls[i]})
+		}
+		return blocks
+	}
+	blocks := make([]ContentBlock, 0, len(c.Blocks))
+	for _, block := range c.Blocks {
+		if block.Type == ContentBlockTypeToolCall {
+			if block.ToolCallIndex < 0 || block.ToolCallIndex >= len(c.ToolCalls) {
+				continue
+			}
+			block.ToolCall = &c.ToolCalls[block.ToolCallIndex]
+		}
+		blocks = append(blocks, block)
+	}
+	return blocks
 }
 
 // Usage represents token usage information
@@ -111,6 +265,8 @@ type Usage struct {
 	ReasoningTokens *int `json:"reasoning_tokens,omitempty"` // Reasoning tokens (OpenAI gpt-5.1, etc.)
 	ThoughtsTokens  *int `json:"thoughts_tokens,omitempty"`  // Thoughts tokens (Gemini 3 Pro, etc.)
 	CacheTokens     *int `json:"cache_tokens,omitempty"`     // Cache tokens (sum of all cache-related tokens from various providers)
+	// Cost is the cost in USD reported by the provider (OpenRouter usage accounting), nil if unknown
+	Cost *float64 `json:"cost,omitempty"`
 }
 
 // GenerationInfo contains token usage and generation metadata from LLM providers.
@@ -138,6 +294,7 @@ type GenerationInfo struct {
 	ThoughtsTokens      *int     `json:"thoughts_tokens,omitempty"`
 	ReasoningTokens     *int     `json:"ReasoningTokens,omitempty"`
 	CacheDiscount       *float64 `json:"cache_discount,omitempty"`
+	Cost                *float64 `json:"cost,omitempty"` // Cost in USD, when the provider reports it
 
 	// Additional fields for extensibility (provider-specific)
 	Additional map[string]interface{} `json:"-"`
@@ -242,6 +399,11 @@ func ExtractUsageFromGenerationInfo(genInfo *GenerationInfo) *Usage {
 		usage.CacheTokens = &cacheTokens
 	}
 
+	if genInfo.Cost != nil {
+		cost := *genInfo.Cost
+		usage.Cost = &cost
+	}
+
 	// Calculate total tokens if not provided by the provider
 	// Note: TotalTokens from provider may already include reasoning/thoughts tokens
 	if usage.TotalTokens == 0 && usage.InputTokens > 0 && usage.OutputTokens > 0 {
@@ -339,25 +501,257 @@ type JSONSchemaConfig struct {
 	Strict      bool                   // Whether to enforce strict schema compliance
 }
 
+// RetryPolicy controls how ProviderAwareLLM retries a failed call (WithRetryPolicy)
+type RetryPolicy struct {
+	// BaseDelay is the wait before the first retry, doubled for each further retry (default 500ms)
+	BaseDelay time.Duration
+	// MaxDelay caps the wait between retries, including a provider's Retry-After (default 30s)
+	MaxDelay time.Duration
+	// Retryable reports whether a failed call is retried (default: rate limits, overload and
+	// server errors, as IsRetryableError of the root package)
+	Retryable func(error) bool
+}
+
+// OpenAIParams holds OpenAI Chat Completions parameters without a generic option
+// (WithOpenAIParams). Unset fields are not sent. They are used by the OpenAI and
+// OpenRouter providers. Seed is offset by the attempt number on schema and validation
+// retries (WithRetryDiversity).
+type OpenAIParams struct {
+	TopP             *float64
+	Seed             *int64
+	PresencePenalty  *float64
+	FrequencyPenalty *float64
+	Stop             []string
+	Logprobs         *bool
+	TopLogprobs      *int64
+	// ParallelToolCalls turns parallel tool calls on or off
+	ParallelToolCalls *bool
+	// Store keeps the completion for OpenAI's evals and distillation
+	Store *bool
+	// Metadata tags stored completions
+	Metadata         map[string]string
+	User             string
+	PromptCacheKey   string
+	SafetyIdentifier string
+}
+
+// AnthropicParams holds Anthropic Messages parameters without a generic option
+// (WithAnthropicParams). Unset fields are not sent. They are used by the Anthropic
+// provider and by Claude models on Vertex AI.
+type AnthropicParams struct {
+	TopP          *float64
+	TopK          *int64
+	StopSequences []string
+	// UserID is sent as metadata.user_id, an opaque identifier of the end user
+	UserID string
+}
+
+// GeminiParams holds Gemini generation parameters without a generic option
+// (WithGeminiParams). Unset fields are not sent. They are used by the Vertex AI
+// provider for Gemini models. Seed is offset by the attempt number on schema and
+// validation retries (WithRetryDiversity).
+type GeminiParams struct {
+	TopP             *float32
+	TopK             *float32
+	Seed             *int32
+	PresencePenalty  *float32
+	FrequencyPenalty *float32
+	StopSequences    []string
+	ResponseLogprobs bool
+	Logprobs         *int32
+	// MediaResolution is "MEDIA_RESOLUTION_LOW", "MEDIA_RESOLUTION_MEDIUM" or "MEDIA_RESOLUTION_HIGH"
+	MediaResolution string
+	// Labels are billing labels, only accepted by Vertex AI
+	Labels map[string]string
+}
+
 // CallOptions holds all call options for LLM generation
 type CallOptions struct {
-	Model           string
-	Temperature     float64
-	MaxTokens       int
-	JSONMode        bool
-	JSONSchema      *JSONSchemaConfig // JSON Schema for structured outputs
-	Tools           []Tool
-	ToolChoice      *ToolChoice
-	StreamChan      chan<- StreamChunk // Channel for streaming chunks (content and tool calls)
-	Metadata        *Metadata          `json:"metadata,omitempty"` // Provider-specific metadata
-	ReasoningEffort string             // Reasoning effort level: "minimal", "low", "medium", "high" (for gpt-5.1 and similar models)
-	Verbosity       string             // Response verbosity level: "low", "medium", "high" (for reasoning models)
-	ThinkingLevel   string             // Thinking level: "low", "high" (for Gemini 3 Pro)
+	Model            string
+	Temperature      float64
+	MaxTokens        int
+	JSONMode         bool
+	JSONSchema       *JSONSchemaConfig // JSON Schema for structured outputs
+	Tools            []Tool
+	ToolChoice       *ToolChoice
+	StreamChan       chan<- StreamChunk  // Channel for streaming chunks (content and tool calls)
+	Metadata         *Metadata           `json:"metadata,omitempty"` // Provider-specific metadata
+	ReasoningEffort  string              // Reasoning effort level: "minimal", "low", "medium", "high" (for gpt-5.1 and similar models)
+	Verbosity        string              // Response verbosity level: "low", "medium", "high" (for reasoning models)
+	ThinkingLevel    string              // Thinking level: "low", "high" (for Gemini 3 Pro)
+	ServiceTier      string              // Service tier: "auto", "default", "flex", "priority" (OpenAI, Anthropic)
+	N                int                 // Number of choices to generate (0 or 1 means a single choice)
+	AbortOnToolCall  bool                // Stop streaming at the first complete tool call
+	AssistantPrefill string              // Text the assistant response must start with
+	DryRun           bool                // Build the provider request but do not send it
+	AutoContinue     int                 // Maximum continuations requested for output cut off at the token limit
+	MaxInputTokens   int                 // Trim the oldest history to fit this estimated input token budget
+	ToolEmulation    bool                // Emulate tool calling through the prompt for models without native support
+	StreamUsage      bool                // Send StreamChunkTypeUsage chunks as providers report usage
+	FallbackModels   []string            // Models tried in order when the call fails with a retryable error
+	SingleFlight     bool                // Coalesce concurrent identical non-streaming calls into one upstream call
+	StreamTextOnly   bool                // Only send StreamChunkTypeContent chunks on StreamChan
+	StreamBuffering  StreamBufferingMode // Regroup streamed content into lines or sentences
+	LogitBias        map[int]float64     // Token ID to bias (-100 to 100), OpenAI only
+
+	// StreamEventHook receives every raw provider stream event before it is parsed (WithStreamEventHook)
+	StreamEventHook func(event interface{})
+
+	// StreamStallTimeout cancels a streaming call when no chunk arrives for this long
+	// (WithStreamStallTimeout)
+	StreamStallTimeout time.Duration
+	// StreamHeartbeat is how often a StreamChunkTypeHeartbeat chunk is sent while no other
+	// chunk arrives (WithStreamHeartbeat)
+	StreamHeartbeat time.Duration
+
+	// StripReasoningTags are the tags whose spans are removed from content (WithStripReasoningTags)
+	StripReasoningTags []string
+
+	// EnforceRequiredToolArgs asks the model to complete tool calls that miss required
+	// parameters (WithEnforceRequiredToolArgs)
+	EnforceRequiredToolArgs bool
+
+	// DisableTools makes the model answer in text even when tools are set (WithDisableTools)
+	DisableTools bool
+
+	// Region is the region the call must be served in (WithRegion)
+	Region string
+
+	// StreamFallback repeats a streaming call without streaming when the provider cannot
+	// stream it, sending the response as whole chunks (WithStreamFallback)
+	StreamFallback bool
+
+	// MaxRetries, when set, is how many times ProviderAwareLLM retries a failed call
+	// (WithMaxRetries). Adapters then turn off the provider SDK's own retries.
+	MaxRetries *int
+	// RetryPolicy sets the backoff and the retryable errors of those retries (WithRetryPolicy)
+	RetryPolicy *RetryPolicy
+	// RetryOnEmptyContent is how many times ProviderAwareLLM repeats a call whose response
+	// has no content and no tool calls (WithRetryOnEmptyContent)
+	RetryOnEmptyContent int
+
+	// AllowedTools limits the tools the model may call this turn to these names (WithAllowedTools);
+	// nil allows every tool
+	AllowedTools []string
+
+	// CachedContent references a context cache created with CreateCachedContent (WithCachedContent)
+	CachedContent CacheName
+
+	// MaxToolCallsPerResponse keeps only the first n tool calls of each choice (0 means no limit)
+	MaxToolCallsPerResponse int
+
+	// DisableToolNameSanitization sends tool names as-is instead of renaming those that
+	// break provider naming rules (see WithToolNameSanitization)
+	DisableToolNameSanitization bool
+
+	// ReasoningVisibility controls reasoning chunks: "visible", "summary" or "hidden" (default)
+	ReasoningVisibility string
+
+	// StructuredOutput is the schema the response must follow; ProviderAwareLLM picks
+	// JSON schema, a forced tool call or JSON mode depending on the model
+	StructuredOutput *JSONSchemaConfig
+	// ResponseMimeType is the response MIME type for Gemini, e.g. "text/plain" or "application/json"
+	ResponseMimeType string
+	// ResponseModalities are the output modalities for Gemini, e.g. "TEXT" and "IMAGE"
+	ResponseModalities []string
+
+	// ToolResultMaxTokens summarizes tool results longer than this many tokens before they
+	// are sent (WithToolResultMaxTokens); 0 sends them as they are
+	ToolResultMaxTokens int
+	// ToolResultSummarizer is the model writing those summaries, nil for the call's model
+	ToolResultSummarizer Model
+	// SummarizeStructuredTools lists the tools whose JSON results may be summarized too
+	SummarizeStructuredTools []string
+
+	// ToolResultImageMaxBytes enables downscaling of tool result images larger than this
+	// size or the provider's limits; 0 rejects oversized images instead
+	ToolResultImageMaxBytes int
+
+	// ImageAutoConvert re-encodes images in formats the provider does not accept as PNG or JPEG
+	ImageAutoConvert bool
+
+	// MaxImageCount caps the images of a request below the provider's limit (WithMaxImageCount)
+	MaxImageCount int
+	// ImageDownsampling downscales images when their total size is above the provider's
+	// request limit (WithImageDownsampling); otherwise such requests fail
+	ImageDownsampling bool
+
+	// SchemaValidation validates structured output against its schema
+	SchemaValidation bool
+	// SchemaRetries is how many times a structured output call is retried with the
+	// validation errors fed back before failing
+	SchemaRetries int
+
+	// Escape hatches for provider parameters not covered by typed options.
+	// Typed options win on conflict.
+	ExtraBody    map[string]interface{}
+	ExtraHeaders map[string]string
+
+	// Provider-native parameters (WithOpenAIParams, WithAnthropicParams, WithGeminiParams),
+	// applied after the generic options
+	OpenAIParams    *OpenAIParams
+	AnthropicParams *AnthropicParams
+	GeminiParams    *GeminiParams
+
+	// TraceID is sent to the provider to correlate its logs with ours (WithMetadataTraceID)
+	TraceID string
+
+	// Interceptors run by ProviderAwareLLM around each call, in the order they were added
+	RequestInterceptors  []RequestInterceptor
+	ResponseInterceptors []ResponseInterceptor
+
+	// ResponseValidators check the final response, after the response interceptors
+	// (WithResponseValidator)
+	ResponseValidators []ResponseValidator
+	// ValidationRetries is how many times a response failing a validator is regenerated
+	// (WithValidationRetries)
+	ValidationRetries int
+	// ValidationFeedback sends the rejected answer and the validation error to the model on
+	// each regeneration (WithValidationFeedback)
+	ValidationFeedback bool
+	// RetryDiversity moves the temperature of each schema or validation retry by this much
+	// per attempt (WithRetryDiversity)
+	RetryDiversity float64
+
+	// MessageTransforms rewrite the messages just before they are sent, in the order they
+	// were added (WithMessageTransform)
+	MessageTransforms []MessageTransform
 }
 
 // CallOption is a function type for setting call options
 type CallOption func(*CallOptions)
 
+// ProviderRequest is the outgoing request of one attempt as seen by request interceptors.
+// ModelID is the model of the attempt, e.g. a fallback model. Interceptors may rewrite
+// Messages, append to Options to tweak parameters or set Headers, which are sent on top of
+// WithExtraHeaders.
+type ProviderRequest struct {
+	Provider string
+	ModelID  string
+	Messages []MessageContent
+	Options  []CallOption
+	Headers  map[string]string
+}
+
+// RequestInterceptor inspects or mutates an outgoing request. It runs before every attempt
+// sent to the provider, retries and fallback models included.
+// Returning an error aborts the call.
+type RequestInterceptor func(*ProviderRequest) error
+
+// ResponseInterceptor inspects or mutates the response of each attempt as the provider
+// returned it, before retries, validation and the wrapper's post-processing.
+// Returning an error fails the attempt.
+type ResponseInterceptor func(*ContentResponse) error
+
+// MessageTransform rewrites the messages of a call before they are converted for the provider.
+// It returns the messages to send; it may modify the slice it gets but not the parts of its
+// messages in place.
+type MessageTransform func([]MessageContent) []MessageContent
+
+// ResponseValidator checks a response against a custom invariant.
+// Returning an error rejects the response.
+type ResponseValidator func(*ContentResponse) error
+
 // NewParameters creates a new Parameters struct from a map.
 // This is a convenience function for converting maps to typed Parameters.
 func NewParameters(paramsMap map[string]interface{}) *Parameters {
@@ -439,6 +833,8 @@ type EmbeddingResponse struct {
 type EmbeddingOptions struct {
 	Model      string // Model ID (e.g., "text-embedding-3-small")
 	Dimensions *int   // Optional dimensions parameter (for text-embedding-3 models)
+	// TaskType is what the embeddings are for (WithEmbeddingTaskType); empty uses the provider default
+	TaskType EmbeddingTaskType
 }
 
 // EmbeddingOption is a function type for setting embedding options
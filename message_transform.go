@@ -0,0 +1,15 @@
+package llmproviders
+
+import (
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+// transformMessages runs transforms in order on a copy of messages, so that transforms
+// modifying their slice leave the caller's untouched
+func transformMessages(messages []llmtypes.MessageContent, transforms []llmtypes.MessageTransform) []llmtypes.MessageContent {
+	messages = append([]llmtypes.MessageContent(nil), messages...)
+	for _, transform := range transforms {
+		messages = transform(messages)
+	}
+	return messages
+}
@@ -0,0 +1,103 @@
+package llmproviders
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+// ModelAlias is the concrete model behind a logical model name such as "fast" or "smart"
+// (Config.ModelAliases)
+type ModelAlias struct {
+	Provider Provider
+	ModelID  string
+}
+
+// resolveModelAliases validates config.ModelAliases and returns config with its ModelID,
+// Provider and FallbackModels resolved. Without Config.Provider, ModelID must be an alias.
+func resolveModelAliases(config Config) (Config, error) {
+	if err := validateModelAliases(config.ModelAliases); err != nil {
+		return config, err
+	}
+
+	if alias, ok := config.ModelAliases[config.ModelID]; ok {
+		if config.Provider != "" && config.Provider != alias.Provider {
+			return config, fmt.Errorf("model alias %q resolves to provider %s but Config.Provider is %s", config.ModelID, alias.Provider, config.Provider)
+		}
+		config.Provider, config.ModelID = alias.Provider, alias.ModelID
+	} else if config.Provider == "" && len(config.ModelAliases) > 0 {
+		return config, fmt.Errorf("unknown model alias %q, known aliases: %s", config.ModelID, aliasNames(config.ModelAliases))
+	}
+
+	if len(config.FallbackModels) > 0 {
+		fallbackModels, err := resolveAliasedModels(config.ModelAliases, config.Provider, config.FallbackModels)
+		if err != nil {
+			return config, err
+		}
+		config.FallbackModels = fallbackModels
+	}
+	return config, nil
+}
+
+// validateModelAliases checks that every alias names a model of a supported provider
+func validateModelAliases(aliases map[string]ModelAlias) error {
+	for name, alias := range aliases {
+		if name == "" || alias.ModelID == "" {
+			return fmt.Errorf("model alias %q: name and model ID are required", name)
+		}
+		if _, err := ValidateProvider(string(alias.Provider)); err != nil {
+			return fmt.Errorf("model alias %q: %w", name, err)
+		}
+	}
+	return nil
+}
+
+// resolveAliasedModels replaces the aliases among models by their model IDs; aliases must
+// resolve to provider, since a model can only switch models on its own provider
+func resolveAliasedModels(aliases map[string]ModelAlias, provider Provider, models []string) ([]string, error) {
+	resolved := make([]string, len(models))
+	for i, model := range models {
+		alias, ok := aliases[model]
+		if !ok {
+			resolved[i] = model
+			continue
+		}
+		if alias.Provider != provider {
+			return nil, fmt.Errorf("model alias %q resolves to provider %s, not %s", model, alias.Provider, provider)
+		}
+		resolved[i] = alias.ModelID
+	}
+	return resolved, nil
+}
+
+// aliasNames returns the sorted names of aliases, comma-separated
+func aliasNames(aliases map[string]ModelAlias) string {
+	names := make([]string, 0, len(aliases))
+	for name := range aliases {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return strings.Join(names, ", ")
+}
+
+// resolveCallAliases returns options with the aliases passed to WithModel and
+// WithFallbackModels replaced by their model IDs
+func (p *ProviderAwareLLM) resolveCallAliases(options []llmtypes.CallOption, opts *llmtypes.CallOptions) ([]llmtypes.CallOption, error) {
+	if opts.Model != "" {
+		models, err := resolveAliasedModels(p.modelAliases, p.provider, []string{opts.Model})
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, llmtypes.WithModel(models[0]))
+	}
+	if len(opts.FallbackModels) > 0 {
+		models, err := resolveAliasedModels(p.modelAliases, p.provider, opts.FallbackModels)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, llmtypes.WithFallbackModels(models))
+	}
+	return options, nil
+}
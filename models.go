@@ -0,0 +1,180 @@
+package llmproviders
+
+import (
+	"strings"
+
+	"github.com/manishiitg/multi-llm-provider-go/pkg/utils"
+)
+
+// ModelCapabilities reports the features a model supports
+type ModelCapabilities struct {
+	TextGeneration bool
+	Tools          bool
+	Vision         bool
+	JSONSchema     bool // Native JSON schema structured outputs
+	Streaming      bool
+	Reasoning      bool // Thinking / reasoning models
+	Embeddings     bool
+}
+
+// String returns the capabilities as a comma-separated list of Capability* names,
+// the format used in initialization events
+func (c ModelCapabilities) String() string {
+	var names []string
+	for _, capability := range []struct {
+		enabled bool
+		name    string
+	}{
+		{c.TextGeneration, CapabilityTextGeneration},
+		{c.Tools, CapabilityToolCalling},
+		{c.Vision, CapabilityVision},
+		{c.JSONSchema, CapabilityJSONSchema},
+		{c.Streaming, CapabilityStreaming},
+		{c.Reasoning, CapabilityReasoning},
+		{c.Embeddings, CapabilityEmbeddings},
+	} {
+		if capability.enabled {
+			names = append(names, capability.name)
+		}
+	}
+	return strings.Join(names, ",")
+}
+
+var (
+	// defaultCapabilities is assumed for chat models missing from the registry
+	defaultCapabilities = ModelCapabilities{TextGeneration: true, Tools: true, Streaming: true}
+
+	claudeCapabilities          = ModelCapabilities{TextGeneration: true, Tools: true, Vision: true, Streaming: true}
+	claudeReasoningCapabilities = ModelCapabilities{TextGeneration: true, Tools: true, Vision: true, Streaming: true, Reasoning: true}
+	gptCapabilities             = ModelCapabilities{TextGeneration: true, Tools: true, Vision: true, JSONSchema: true, Streaming: true}
+	gptReasoningCapabilities    = ModelCapabilities{TextGeneration: true, Tools: true, Vision: true, JSONSchema: true, Streaming: true, Reasoning: true}
+	geminiCapabilities          = gptCapabilities
+	geminiThinkingCapabilities  = gptReasoningCapabilities
+	embeddingCapabilities       = ModelCapabilities{Embeddings: true}
+
+	// Open-weight and early reasoning models without native tool calling (WithToolEmulation)
+	textOnlyCapabilities      = ModelCapabilities{TextGeneration: true, Streaming: true}
+	textReasoningCapabilities = ModelCapabilities{TextGeneration: true, Streaming: true, Reasoning: true}
+
+	// vertexClaudeRegions are the Vertex locations serving current Claude models
+	vertexClaudeRegions = map[Provider][]string{ProviderVertex: {"global", "us-east5", "europe-west1", "asia-east1"}}
+)
+
+// ModelInfo describes the limits and capabilities of a model family
+type ModelInfo struct {
+	// Pattern identifies the family in a model ID, e.g. "claude-sonnet-4" matches
+	// "claude-sonnet-4-20250514", "us.anthropic.claude-sonnet-4-20250514-v1:0" and
+	// "anthropic/claude-sonnet-4"
+	Pattern string
+	// MaxOutputTokens is the largest max_tokens the model accepts
+	MaxOutputTokens int
+	// DefaultMaxTokens is sent when the caller doesn't set max tokens; 0 leaves it to the provider
+	DefaultMaxTokens int
+	// Capabilities lists the features the family supports
+	Capabilities ModelCapabilities
+	// Regions lists, per provider, the regions the family is served in when it is not
+	// available everywhere; providers without an entry serve it in all their regions
+	Regions map[Provider][]string
+}
+
+// modelRegistry lists known model families. Providers whose own default is the model's
+// full output budget (OpenAI, Gemini) have no DefaultMaxTokens.
+var modelRegistry = []ModelInfo{
+	// Anthropic (direct, Bedrock, Vertex and OpenRouter IDs)
+	{Pattern: "claude-3-haiku", MaxOutputTokens: 4096, DefaultMaxTokens: 4096, Capabilities: claudeCapabilities},
+	{Pattern: "claude-3-opus", MaxOutputTokens: 4096, DefaultMaxTokens: 4096, Capabilities: claudeCapabilities},
+	{Pattern: "claude-3-5-haiku", MaxOutputTokens: 8192, DefaultMaxTokens: 8192, Capabilities: claudeCapabilities, Regions: map[Provider][]string{ProviderVertex: {"us-east5"}}},
+	{Pattern: "claude-3-5-sonnet", MaxOutputTokens: 8192, DefaultMaxTokens: 8192, Capabilities: claudeCapabilities},
+	{Pattern: "claude-3-7-sonnet", MaxOutputTokens: 64000, DefaultMaxTokens: 16384, Capabilities: claudeReasoningCapabilities, Regions: vertexClaudeRegions},
+	{Pattern: "claude-sonnet-4", MaxOutputTokens: 64000, DefaultMaxTokens: 16384, Capabilities: claudeReasoningCapabilities, Regions: vertexClaudeRegions},
+	{Pattern: "claude-haiku-4", MaxOutputTokens: 64000, DefaultMaxTokens: 16384, Capabilities: claudeReasoningCapabilities, Regions: vertexClaudeRegions},
+	{Pattern: "claude-opus-4", MaxOutputTokens: 32000, DefaultMaxTokens: 16384, Capabilities: claudeReasoningCapabilities, Regions: vertexClaudeRegions},
+	{Pattern: "claude-opus-4-5", MaxOutputTokens: 64000, DefaultMaxTokens: 16384, Capabilities: claudeReasoningCapabilities, Regions: vertexClaudeRegions},
+
+	// OpenAI
+	{Pattern: "gpt-4-turbo", MaxOutputTokens: 4096, Capabilities: ModelCapabilities{TextGeneration: true, Tools: true, Vision: true, Streaming: true}},
+	{Pattern: "gpt-4o", MaxOutputTokens: 16384, Capabilities: gptCapabilities},
+	{Pattern: "gpt-4.1", MaxOutputTokens: 32768, Capabilities: gptCapabilities},
+	{Pattern: "gpt-5", MaxOutputTokens: 128000, Capabilities: gptReasoningCapabilities},
+	{Pattern: "o1", MaxOutputTokens: 100000, Capabilities: gptReasoningCapabilities},
+	{Pattern: "o1-mini", MaxOutputTokens: 65536, Capabilities: textReasoningCapabilities},
+	{Pattern: "o1-preview", MaxOutputTokens: 32768, Capabilities: textReasoningCapabilities},
+	{Pattern: "o3", MaxOutputTokens: 100000, Capabilities: gptReasoningCapabilities},
+	{Pattern: "o4-mini", MaxOutputTokens: 100000, Capabilities: gptReasoningCapabilities},
+
+	// Gemini
+	{Pattern: "gemini-1.5", MaxOutputTokens: 8192, Capabilities: geminiCapabilities},
+	{Pattern: "gemini-2.0", MaxOutputTokens: 8192, Capabilities: geminiCapabilities},
+	{Pattern: "gemini-2.5", MaxOutputTokens: 65536, Capabilities: geminiThinkingCapabilities},
+	{Pattern: "gemini-3", MaxOutputTokens: 65536, Capabilities: geminiThinkingCapabilities},
+
+	// Open-weight models commonly served without tool calling (OpenRouter, self-hosted)
+	{Pattern: "gemma", Capabilities: textOnlyCapabilities},
+	{Pattern: "deepseek-r1", Capabilities: textReasoningCapabilities},
+
+	// Embedding models
+	{Pattern: "text-embedding", Capabilities: embeddingCapabilities},
+	{Pattern: "gemini-embedding", Capabilities: embeddingCapabilities},
+	{Pattern: "titan-embed", Capabilities: embeddingCapabilities},
+	{Pattern: "embed-english", Capabilities: embeddingCapabilities},
+	{Pattern: "embed-multilingual", Capabilities: embeddingCapabilities},
+	{Pattern: "embed-v4", Capabilities: embeddingCapabilities},
+}
+
+// LookupModelInfo returns the registry entry for modelID. When several patterns match,
+// the longest (most specific) one wins. Fine-tuned OpenAI models ("ft:gpt-4o-mini:org::id")
+// get the entry of their base model.
+func LookupModelInfo(modelID string) (ModelInfo, bool) {
+	id := strings.ToLower(utils.BaseModelID(modelID))
+	var best ModelInfo
+	found := false
+	for _, info := range modelRegistry {
+		if len(info.Pattern) <= len(best.Pattern) || !containsModelPattern(id, info.Pattern) {
+			continue
+		}
+		best = info
+		found = true
+	}
+	return best, found
+}
+
+// containsModelPattern reports whether pattern occurs in id at the start of a name segment,
+// so "o3" matches "openai/o3-mini" but not "gpt-4o3"
+func containsModelPattern(id, pattern string) bool {
+	for offset := 0; offset < len(id); {
+		i := strings.Index(id[offset:], pattern)
+		if i < 0 {
+			return false
+		}
+		i += offset
+		if i == 0 || strings.ContainsRune("/.:-_", rune(id[i-1])) {
+			return true
+		}
+		offset = i + 1
+	}
+	return false
+}
+
+// LookupCapabilities returns the capabilities of modelID from the registry. Unknown models
+// are assumed to support text generation, tool calling and streaming.
+func LookupCapabilities(modelID string) ModelCapabilities {
+	if info, ok := LookupModelInfo(modelID); ok {
+		return info.Capabilities
+	}
+	return defaultCapabilities
+}
+
+// resolveDefaultMaxTokens returns the max tokens to send for modelID when the caller
+// didn't set any: configured (if positive) or the registry default, capped at the
+// model's maximum output. It returns 0 when there is no default to apply.
+func resolveDefaultMaxTokens(configured int, modelID string) int {
+	info, ok := LookupModelInfo(modelID)
+	maxTokens := configured
+	if maxTokens <= 0 {
+		maxTokens = info.DefaultMaxTokens
+	}
+	if ok && info.MaxOutputTokens > 0 && maxTokens > info.MaxOutputTokens {
+		maxTokens = info.MaxOutputTokens
+	}
+	return maxTokens
+}
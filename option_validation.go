@@ -0,0 +1,275 @@
+package llmproviders
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+// ErrUnsupportedOptionCombination is matched (errors.Is) by the error of a call whose options
+// conflict with each other or with the provider; use errors.As with *OptionCombinationError
+// to get the conflicts
+var ErrUnsupportedOptionCombination = errors.New("unsupported option combination")
+
+// OptionCombinationError lists the option conflicts that stopped a call before any request
+// was sent
+type OptionCombinationError struct {
+	Provider  Provider
+	ModelID   string
+	Conflicts []string
+}
+
+// Error lists the conflicts
+func (e *OptionCombinationError) Error() string {
+	return fmt.Sprintf("%v for %s model %s: %s", ErrUnsupportedOptionCombination, e.Provider, e.ModelID, strings.Join(e.Conflicts, "; "))
+}
+
+// Is reports whether target is ErrUnsupportedOptionCombination
+func (e *OptionCombinationError) Is(target error) bool {
+	return target == ErrUnsupportedOptionCombination
+}
+
+// optionRule describes how the options of a call conflict, or returns "" when they don't
+type optionRule func(p *ProviderAwareLLM, opts *llmtypes.CallOptions) string
+
+// optionRules are the option combinations a call cannot be made with. Streaming n>1 choices
+// is emulated for every provider with chunks tagged by ChoiceIndex, so the rules cover the
+// options whose handling only follows a single choice.
+var optionRules = []optionRule{
+	func(p *ProviderAwareLLM, opts *llmtypes.CallOptions) string {
+		if opts.N > 1 && opts.AbortOnToolCall && opts.StreamChan != nil {
+			return fmt.Sprintf("WithN(%d) with WithAbortOnToolCall while streaming: the first tool call of any choice would cut the other choices short", opts.N)
+		}
+		return ""
+	},
+	func(p *ProviderAwareLLM, opts *llmtypes.CallOptions) string {
+		if opts.N > 1 && opts.AutoContinue > 0 {
+			return fmt.Sprintf("WithN(%d) with WithAutoContinue: only the first choice would be continued", opts.N)
+		}
+		return ""
+	},
+	func(p *ProviderAwareLLM, opts *llmtypes.CallOptions) string {
+		if opts.ValidationRetries > 0 && len(opts.ResponseValidators) > 0 && opts.StreamChan != nil {
+			return fmt.Sprintf("WithValidationRetries(%d) while streaming: a rejected response would already have been streamed", opts.ValidationRetries)
+		}
+		return ""
+	},
+	func(p *ProviderAwareLLM, opts *llmtypes.CallOptions) string {
+		if opts.CachedContent == "" {
+			return ""
+		}
+		if _, ok := p.Model.(llmtypes.ContextCacheModel); !ok {
+			return fmt.Sprintf("WithCachedContent: %s does not support explicit context caching, the cache would be ignored", p.provider)
+		}
+		return ""
+	},
+}
+
+// checkOptionCombinations returns an OptionCombinationError listing every conflict of opts
+func (p *ProviderAwareLLM) checkOptionCombinations(opts *llmtypes.CallOptions) error {
+	var conflicts []string
+	for _, rule := range optionRules {
+		if conflict := rule(p, opts); conflict != "" {
+			conflicts = append(conflicts, conflict)
+		}
+	}
+	if len(conflicts) == 0 {
+		return nil
+	}
+	modelID := p.modelID
+	if opts.Model != "" {
+		modelID = opts.Model
+	}
+	return &OptionCombinationError{Provider: p.provider, ModelID: modelID, Conflicts: conflicts}
+}
+
+// ErrInvalidOptions is matched (errors.Is) by the error of a call whose options the model
+// cannot accept; use errors.As with *InvalidOptionsError to get the problems
+var ErrInvalidOptions = errors.New("invalid call options")
+
+// InvalidOptionsError lists the problems that stopped a call before any request was sent,
+// each saying how to fix it
+type InvalidOptionsError struct {
+	Provider Provider
+	ModelID  string
+	Problems []string
+}
+
+// Error lists the problems
+func (e *InvalidOptionsError) Error() string {
+	return fmt.Sprintf("%v for %s model %s: %s", ErrInvalidOptions, e.Provider, e.ModelID, strings.Join(e.Problems, "; "))
+}
+
+// Is reports whether target is ErrInvalidOptions
+func (e *InvalidOptionsError) Is(target error) bool {
+	return target == ErrInvalidOptions
+}
+
+// toolChoiceTypes are the tool choice types adapters understand
+var toolChoiceTypes = []string{"auto", "none", "required", "any", "function"}
+
+// ValidateOptions checks opts against provider and modelID without calling the model: the
+// tools and tool choice, the JSON schema, temperature, max tokens and response modalities.
+// Capabilities come from the model registry, so models missing from it are only checked for
+// provider-wide rules. It returns an *InvalidOptionsError listing every problem, or nil.
+// ProviderAwareLLM runs it before each call.
+//
+// Options a provider would ignore or may accept beyond the registry are not problems:
+// ProviderAwareLLM logs them and drops or sends them (see normalizeOptions).
+func ValidateOptions(provider Provider, modelID string, opts *llmtypes.CallOptions) error {
+	problems := optionProblems(provider, modelID, opts)
+	if len(problems) == 0 {
+		return nil
+	}
+	return &InvalidOptionsError{Provider: provider, ModelID: modelID, Problems: problems}
+}
+
+// optionProblems returns the problems ValidateOptions reports
+func optionProblems(provider Provider, modelID string, opts *llmtypes.CallOptions) []string {
+	var problems []string
+	info, known := LookupModelInfo(modelID)
+
+	// Tools and tool choice; WithDisableTools removes both
+	if !opts.DisableTools {
+		names := make(map[string]bool, len(opts.Tools))
+		for i, tool := range opts.Tools {
+			switch {
+			case tool.Function == nil || tool.Function.Name == "":
+				problems = append(problems, fmt.Sprintf("tool %d has no function name", i))
+			case names[tool.Function.Name]:
+				problems = append(problems, fmt.Sprintf("tool %q is defined more than once", tool.Function.Name))
+			default:
+				names[tool.Function.Name] = true
+			}
+		}
+		if len(opts.Tools) > 0 && known && !info.Capabilities.Tools && !opts.ToolEmulation {
+			problems = append(problems, fmt.Sprintf("%s has no native tool calling; set WithToolEmulation to describe the tools in the prompt", modelID))
+		}
+		if choice := opts.ToolChoice; choice != nil {
+			name := forcedToolName(choice)
+			switch {
+			case choice.Type != "" && !slices.Contains(toolChoiceTypes, choice.Type):
+				problems = append(problems, fmt.Sprintf("tool choice type %q is not one of %s", choice.Type, strings.Join(toolChoiceTypes, ", ")))
+			case choice.Type == "function" && name == "":
+				problems = append(problems, "tool choice type \"function\" needs a function name")
+			case name != "" && !names[name]:
+				problems = append(problems, fmt.Sprintf("tool choice %q is not one of the call's tools", name))
+			case len(opts.Tools) == 0 && toolChoiceRequired(choice):
+				problems = append(problems, "tool choice requires a tool call but the call has no tools")
+			}
+		}
+	}
+
+	// Native JSON schema output; Claude models ignore it (see normalizeOptions)
+	if opts.JSONSchema != nil && opts.JSONSchema.Schema == nil {
+		problems = append(problems, "WithJSONSchema needs a schema")
+	}
+
+	// Temperatures above the model's maximum are clamped, negative ones are never valid
+	if opts.Temperature < 0 {
+		problems = append(problems, fmt.Sprintf("temperature %.2f is negative", opts.Temperature))
+	}
+
+	// Max tokens above the registry's output limit are sent as they are (see normalizeOptions)
+	if opts.MaxTokens < 0 {
+		problems = append(problems, fmt.Sprintf("max tokens %d is negative", opts.MaxTokens))
+	}
+
+	// Only Gemini generates images and audio
+	gemini := provider == ProviderVertex && !strings.HasPrefix(modelID, "claude-")
+	for _, modality := range opts.ResponseModalities {
+		switch strings.ToLower(modality) {
+		case "text":
+		case "image", "audio":
+			if !gemini {
+				problems = append(problems, fmt.Sprintf("response modality %q is only supported by Gemini models", modality))
+			}
+		default:
+			problems = append(problems, fmt.Sprintf("response modality %q is not one of text, image, audio", modality))
+		}
+	}
+	return problems
+}
+
+// normalizeOptions returns options and opts with the tool choice type trimmed and lower
+// case, so that "Required" is not taken for "auto" by the adapters. It also logs the options
+// the call is made without failing on:
+//   - WithJSONSchema is dropped for Claude and Bedrock models, which have no native JSON
+//     schema output and ignored it anyway; WithStructuredOutput falls back to JSON mode
+//     for them instead
+//   - max tokens above the registry's output limit are sent unchanged, since the provider may
+//     accept more than the registry knows of, e.g. Claude 3.7 Sonnet with the 128k output beta
+func (p *ProviderAwareLLM) normalizeOptions(options []llmtypes.CallOption, opts *llmtypes.CallOptions) ([]llmtypes.CallOption, *llmtypes.CallOptions) {
+	modelID := p.modelID
+	if opts.Model != "" {
+		modelID = opts.Model
+	}
+
+	if opts.JSONSchema != nil && opts.JSONSchema.Schema != nil && ignoresJSONSchema(p.provider, modelID) {
+		p.logger.Infof("⚠️  WithJSONSchema is not supported by %s model %s and is dropped; use WithStructuredOutput, which falls back to JSON mode", string(p.provider), modelID)
+		options = append(append([]llmtypes.CallOption{}, options...), func(o *llmtypes.CallOptions) { o.JSONSchema = nil })
+		opts.JSONSchema = nil
+	}
+
+	if info, known := LookupModelInfo(modelID); known && info.MaxOutputTokens > 0 && opts.MaxTokens > info.MaxOutputTokens {
+		p.logger.Infof("⚠️  Max tokens %d is above the %d output tokens known for %s; sending it unchanged", opts.MaxTokens, info.MaxOutputTokens, modelID)
+	}
+
+	if opts.ToolChoice == nil {
+		return options, opts
+	}
+	choiceType := strings.ToLower(strings.TrimSpace(opts.ToolChoice.Type))
+	if choiceType == opts.ToolChoice.Type {
+		return options, opts
+	}
+	choice := *opts.ToolChoice
+	choice.Type = choiceType
+	options = append(append([]llmtypes.CallOption{}, options...), llmtypes.WithToolChoice(&choice))
+	opts.ToolChoice = &choice
+	return options, opts
+}
+
+// ignoresJSONSchema reports whether provider serves modelID without native JSON schema output:
+// Claude models, and every Bedrock model
+func ignoresJSONSchema(provider Provider, modelID string) bool {
+	return provider == ProviderAnthropic || provider == ProviderBedrock || (provider == ProviderVertex && strings.HasPrefix(modelID, "claude-"))
+}
+
+// validateRequest runs ValidateOptions for the call's model, checks that the model can see
+// the images of messages, then checks the option combinations (checkOptionCombinations)
+func (p *ProviderAwareLLM) validateRequest(messages []llmtypes.MessageContent, opts *llmtypes.CallOptions) error {
+	modelID := p.modelID
+	if opts.Model != "" {
+		modelID = opts.Model
+	}
+	problems := optionProblems(p.provider, modelID, opts)
+	if info, known := LookupModelInfo(modelID); known && !info.Capabilities.Vision && hasImages(messages) {
+		problems = append(problems, fmt.Sprintf("%s does not accept images; use a vision model or remove the image parts", modelID))
+	}
+	if len(problems) > 0 {
+		return &InvalidOptionsError{Provider: p.provider, ModelID: modelID, Problems: problems}
+	}
+	return p.checkOptionCombinations(opts)
+}
+
+// hasImages reports whether messages hold an image, including in tool results
+func hasImages(messages []llmtypes.MessageContent) bool {
+	for _, message := range messages {
+		for _, part := range message.Parts {
+			switch p := part.(type) {
+			case llmtypes.ImageContent:
+				return true
+			case llmtypes.ToolCallResponse:
+				for _, nested := range p.Parts {
+					if _, ok := nested.(llmtypes.ImageContent); ok {
+						return true
+					}
+				}
+			}
+		}
+	}
+	return false
+}
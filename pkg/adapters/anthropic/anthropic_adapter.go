@@ -8,6 +8,7 @@ import (
 
 	"github.com/manishiitg/multi-llm-provider-go/interfaces"
 	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+	"github.com/manishiitg/multi-llm-provider-go/pkg/utils"
 
 	"github.com/anthropics/anthropic-sdk-go"
 	anthropicoption "github.com/anthropics/anthropic-sdk-go/option"
@@ -49,6 +50,45 @@ func (a *AnthropicAdapter) GenerateContent(ctx context.Context, messages []llmty
 		modelID = opts.Model
 	}
 
+	// Apply the assistant prefill
+	if opts.AssistantPrefill != "" {
+		return utils.GenerateWithPrefill(ctx, a, messages, options, true)
+	}
+
+	// Stop at the first complete tool call when requested
+	if opts.AbortOnToolCall && opts.StreamChan != nil {
+		return utils.GenerateUntilToolCall(ctx, a, messages, options)
+	}
+
+	// Anthropic has no native n>1, emulate it with concurrent requests
+	if opts.N > 1 {
+		return utils.GenerateChoicesConcurrently(ctx, a, opts.N, messages, options)
+	}
+
+	// Continue output cut off at the token limit when requested
+	if opts.AutoContinue > 0 {
+		return utils.GenerateWithAutoContinue(ctx, a, messages, options, true)
+	}
+
+	// Detect the media type of base64 images sent without one, converting unsupported formats
+	// when enabled
+	messages, err := utils.ResolveImageMediaTypes(messages, utils.AnthropicImageLimits, opts.ImageAutoConvert)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check tool result images against the provider limits, downscaling when allowed
+	messages, err = utils.PrepareToolResultImages(messages, utils.AnthropicImageLimits, opts.ToolResultImageMaxBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check the number and total size of images against the per-request limits
+	messages, err = utils.CheckImagePayload(messages, utils.AnthropicImageLimits, opts.MaxImageCount, opts.ImageDownsampling)
+	if err != nil {
+		return nil, err
+	}
+
 	// Convert messages from llm format to Anthropic format
 	anthropicMessages, systemMessage := convertMessages(messages)
 
@@ -78,7 +118,11 @@ func (a *AnthropicAdapter) GenerateContent(ctx context.Context, messages []llmty
 
 	// Set temperature
 	if opts.Temperature > 0 {
-		params.Temperature = anthropic.Float(opts.Temperature)
+		temperature, clamped := utils.ClampTemperature(opts.Temperature, utils.MaxTemperatureAnthropic)
+		if clamped && a.logger != nil {
+			a.logger.Infof("🌡️  Temperature %.2f is above the Anthropic maximum, using %.1f", opts.Temperature, temperature)
+		}
+		params.Temperature = anthropic.Float(temperature)
 	}
 
 	// Set max tokens
@@ -98,11 +142,30 @@ func (a *AnthropicAdapter) GenerateContent(ctx context.Context, messages []llmty
 		}
 	}
 
+	// Map service tier: priority capacity is used when available with "auto"
+	if opts.ServiceTier != "" {
+		if serviceTier, ok := convertServiceTier(opts.ServiceTier); ok {
+			params.ServiceTier = serviceTier
+		} else if a.logger != nil {
+			a.logger.Debugf("Service tier %q is not supported by Anthropic, ignoring", opts.ServiceTier)
+		}
+	}
+
+	// Provider-native parameters are applied last and win over the generic options
+	if opts.AnthropicParams != nil {
+		applyAnthropicParams(&params, opts.AnthropicParams)
+	}
+
 	// Log input details if logger is available (for debugging errors)
 	if a.logger != nil {
 		a.logInputDetails(modelID, messages, params, opts)
 	}
 
+	// Return the constructed request without calling the API
+	if opts.DryRun {
+		return utils.DryRunResponse(opts, params), nil
+	}
+
 	// Always use streaming API for Anthropic to avoid "streaming is required" error
 	// Anthropic requires streaming for operations that may take longer than 10 minutes
 	// Using NewStreaming() disables this error check regardless of actual request size
@@ -113,7 +176,7 @@ func (a *AnthropicAdapter) GenerateContent(ctx context.Context, messages []llmty
 		a.logger.Debugf("[ANTHROPIC DEBUG] Model: %s, Messages: %d, System blocks: %d",
 			params.Model, len(params.Messages), len(params.System))
 	}
-	stream := a.client.Messages.NewStreaming(ctx, params, anthropicoption.WithHeader("anthropic-beta", "prompt-caching-2024-07-31"))
+	stream := a.client.Messages.NewStreaming(ctx, params, requestOptions(params, opts)...)
 
 	// Ensure channel is closed when done (if streaming is enabled)
 	defer func() {
@@ -125,8 +188,13 @@ func (a *AnthropicAdapter) GenerateContent(ctx context.Context, messages []llmty
 	// Use Message.Accumulate to build the final message
 	message := anthropic.Message{}
 	var contentChunksSent int
+	// Tool calls are streamed as soon as their block ends, so callers can act on them mid-stream
+	toolCallsStreamed := map[int64]bool{}
 	for stream.Next() {
 		event := stream.Current()
+		if opts.StreamEventHook != nil {
+			opts.StreamEventHook(event)
+		}
 
 		// Accumulate event into message
 		if err := message.Accumulate(event); err != nil {
@@ -137,9 +205,48 @@ func (a *AnthropicAdapter) GenerateContent(ctx context.Context, messages []llmty
 			return nil, fmt.Errorf("anthropic streaming accumulate error: %w", err)
 		}
 
+		// message_delta carries cumulative usage; Accumulate only keeps its output tokens
+		switch eventVariant := event.AsAny().(type) {
+		case anthropic.MessageStartEvent:
+			if err := utils.SendUsageChunk(ctx, opts, streamUsage(message.Usage)); err != nil {
+				return nil, err
+			}
+		case anthropic.MessageDeltaEvent:
+			if eventVariant.Usage.InputTokens > 0 {
+				message.Usage.InputTokens = eventVariant.Usage.InputTokens
+			}
+			if eventVariant.Usage.CacheReadInputTokens > 0 {
+				message.Usage.CacheReadInputTokens = eventVariant.Usage.CacheReadInputTokens
+			}
+			if eventVariant.Usage.CacheCreationInputTokens > 0 {
+				message.Usage.CacheCreationInputTokens = eventVariant.Usage.CacheCreationInputTokens
+			}
+			if err := utils.SendUsageChunk(ctx, opts, streamUsage(message.Usage)); err != nil {
+				return nil, err
+			}
+		}
+
 		// If streaming channel is provided, extract and send text chunks
 		if opts.StreamChan != nil {
 			switch eventVariant := event.AsAny().(type) {
+			case anthropic.ContentBlockStopEvent:
+				if eventVariant.Index < 0 || eventVariant.Index >= int64(len(message.Content)) {
+					break
+				}
+				block := message.Content[eventVariant.Index]
+				if block.Type != "tool_use" {
+					break
+				}
+				toolCall := toolUseCall(block)
+				toolCallsStreamed[eventVariant.Index] = true
+				select {
+				case opts.StreamChan <- llmtypes.StreamChunk{
+					Type:     llmtypes.StreamChunkTypeToolCall,
+					ToolCall: &toolCall,
+				}:
+				case <-ctx.Done():
+					return nil, ctx.Err()
+				}
 			case anthropic.ContentBlockDeltaEvent:
 				// Check if this is a text delta
 				switch deltaVariant := eventVariant.Delta.AsAny().(type) {
@@ -155,6 +262,18 @@ func (a *AnthropicAdapter) GenerateContent(ctx context.Context, messages []llmty
 							return nil, ctx.Err()
 						}
 					}
+				case anthropic.ThinkingDelta:
+					// Extended thinking (enabled through the thinking request parameter)
+					if deltaVariant.Thinking != "" && utils.ReasoningVisible(opts) {
+						select {
+						case opts.StreamChan <- llmtypes.StreamChunk{
+							Type:    llmtypes.StreamChunkTypeReasoning,
+							Content: deltaVariant.Thinking,
+						}:
+						case <-ctx.Done():
+							return nil, ctx.Err()
+						}
+					}
 				}
 			}
 		}
@@ -170,7 +289,7 @@ func (a *AnthropicAdapter) GenerateContent(ctx context.Context, messages []llmty
 	stream.Close()
 
 	// After streaming completes, extract and stream any tool calls from the accumulated message
-	var toolCallsSent int
+	toolCallsSent := len(toolCallsStreamed)
 	if opts.StreamChan != nil {
 		// FALLBACK: If no content chunks were sent during streaming but message has text content,
 		// stream it now (this handles cases where deltas weren't captured)
@@ -197,24 +316,10 @@ func (a *AnthropicAdapter) GenerateContent(ctx context.Context, messages []llmty
 			}
 		}
 
-		// Extract tool calls from accumulated message
-		for _, block := range message.Content {
-			if block.Type == "tool_use" {
-				var argsJSON []byte
-				if len(block.Input) > 0 {
-					argsJSON = block.Input
-				} else {
-					argsJSON = []byte("{}")
-				}
-
-				toolCall := llmtypes.ToolCall{
-					ID:   block.ID,
-					Type: "function",
-					FunctionCall: &llmtypes.FunctionCall{
-						Name:      block.Name,
-						Arguments: string(argsJSON),
-					},
-				}
+		// Extract tool calls from accumulated message that were not streamed when their block ended
+		for i, block := range message.Content {
+			if block.Type == "tool_use" && !toolCallsStreamed[int64(i)] {
+				toolCall := toolUseCall(block)
 
 				// Stream the complete tool call
 				toolCallsSent++
@@ -246,7 +351,47 @@ func (a *AnthropicAdapter) GenerateContent(ctx context.Context, messages []llmty
 	}
 
 	// Convert the accumulated message to llm format
-	return convertResponse(&message), nil
+	resp := convertResponse(&message)
+
+	// Send terminal chunk with stop reason and usage before the channel is closed
+	if opts.StreamChan != nil {
+		select {
+		case opts.StreamChan <- llmtypes.NewFinishChunk(resp):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	return resp, nil
+}
+
+// streamUsage converts the usage accumulated so far while streaming
+func streamUsage(usage anthropic.Usage) *llmtypes.Usage {
+	result := &llmtypes.Usage{
+		InputTokens:  int(usage.InputTokens),
+		OutputTokens: int(usage.OutputTokens),
+		TotalTokens:  int(usage.InputTokens + usage.OutputTokens),
+	}
+	if cacheTokens := int(usage.CacheReadInputTokens + usage.CacheCreationInputTokens); cacheTokens > 0 {
+		result.CacheTokens = &cacheTokens
+	}
+	return result
+}
+
+// toolUseCall converts a streamed tool_use block to a tool call
+func toolUseCall(block anthropic.ContentBlockUnion) llmtypes.ToolCall {
+	argsJSON := []byte("{}")
+	if len(block.Input) > 0 {
+		argsJSON = block.Input
+	}
+	return llmtypes.ToolCall{
+		ID:   block.ID,
+		Type: "function",
+		FunctionCall: &llmtypes.FunctionCall{
+			Name:      block.Name,
+			Arguments: string(argsJSON),
+		},
+	}
 }
 
 // Call implements a convenience method that wraps GenerateContent for simple text generation
@@ -277,13 +422,13 @@ func (a *AnthropicAdapter) Call(ctx context.Context, prompt string, options ...l
 func convertMessages(langMessages []llmtypes.MessageContent) ([]anthropic.MessageParam, string) {
 	anthropicMessages := make([]anthropic.MessageParam, 0, len(langMessages))
 	var systemMessage string
+	langMessages = utils.PrefixMessageNames(langMessages)
 
 	for _, msg := range langMessages {
 		// Extract content parts
 		var contentParts []string
 		var imageParts []llmtypes.ImageContent
-		var toolCallID string
-		var toolResponseContent string
+		var toolResponses []llmtypes.ToolCallResponse
 		var toolCalls []llmtypes.ToolCall
 
 		for _, part := range msg.Parts {
@@ -293,9 +438,8 @@ func convertMessages(langMessages []llmtypes.MessageContent) ([]anthropic.Messag
 			case llmtypes.ImageContent:
 				imageParts = append(imageParts, p)
 			case llmtypes.ToolCallResponse:
-				// Tool response - extract tool call ID and content
-				toolCallID = p.ToolCallID
-				toolResponseContent = p.Content
+				// Tool response - collect all responses for a single user message
+				toolResponses = append(toolResponses, p)
 			case llmtypes.ToolCall:
 				// Tool call in assistant message
 				toolCalls = append(toolCalls, p)
@@ -406,14 +550,16 @@ func convertMessages(langMessages []llmtypes.MessageContent) ([]anthropic.Messag
 			}
 		case string(llmtypes.ChatMessageTypeTool):
 			// Tool message - handle tool responses
-			if toolCallID != "" {
-				// Create tool result content block using helper
-				// isError is false - we could enhance this to detect errors
-				contentBlock := anthropic.NewToolResultBlock(toolCallID, toolResponseContent, false)
-
+			contentBlocks := []anthropic.ContentBlockParamUnion{}
+			for _, toolResponse := range toolResponses {
+				if toolResponse.ToolCallID != "" {
+					contentBlocks = append(contentBlocks, createToolResultBlock(toolResponse))
+				}
+			}
+			if len(contentBlocks) > 0 {
 				anthropicMessages = append(anthropicMessages, anthropic.MessageParam{
 					Role:    anthropic.MessageParamRoleUser,
-					Content: []anthropic.ContentBlockParamUnion{contentBlock},
+					Content: contentBlocks,
 				})
 			}
 		default:
@@ -447,6 +593,38 @@ func convertMessages(langMessages []llmtypes.MessageContent) ([]anthropic.Messag
 	return anthropicMessages, systemMessage
 }
 
+// createToolResultBlock creates an Anthropic tool_result block, including any text and image parts
+func createToolResultBlock(toolResponse llmtypes.ToolCallResponse) anthropic.ContentBlockParamUnion {
+	if len(toolResponse.Parts) == 0 {
+		return anthropic.NewToolResultBlock(toolResponse.ToolCallID, toolResponse.Content, toolResponse.IsError)
+	}
+
+	var content []anthropic.ToolResultBlockParamContentUnion
+	if toolResponse.Content != "" {
+		content = append(content, anthropic.ToolResultBlockParamContentUnion{OfText: &anthropic.TextBlockParam{Text: toolResponse.Content}})
+	}
+	for _, part := range toolResponse.Parts {
+		switch p := part.(type) {
+		case llmtypes.TextContent:
+			if p.Text != "" {
+				content = append(content, anthropic.ToolResultBlockParamContentUnion{OfText: &anthropic.TextBlockParam{Text: p.Text}})
+			}
+		case llmtypes.ImageContent:
+			if imageBlock := createImageBlock(p); imageBlock != nil && imageBlock.OfImage != nil {
+				content = append(content, anthropic.ToolResultBlockParamContentUnion{OfImage: imageBlock.OfImage})
+			}
+		}
+	}
+	toolResult := &anthropic.ToolResultBlockParam{
+		ToolUseID: toolResponse.ToolCallID,
+		Content:   content,
+	}
+	if toolResponse.IsError {
+		toolResult.IsError = anthropic.Bool(true)
+	}
+	return anthropic.ContentBlockParamUnion{OfToolResult: toolResult}
+}
+
 // createImageBlock creates an Anthropic image content block from ImageContent
 func createImageBlock(img llmtypes.ImageContent) *anthropic.ContentBlockParamUnion {
 	if img.SourceType == "base64" {
@@ -623,6 +801,11 @@ func convertResponse(result *anthropic.Message) *llmtypes.ContentResponse {
 		case "text":
 			if block.Text != "" {
 				textParts = append(textParts, block.Text)
+				choice.Blocks = append(choice.Blocks, llmtypes.ContentBlock{Type: llmtypes.ContentBlockTypeText, Text: block.Text})
+			}
+		case "thinking":
+			if block.Thinking != "" {
+				choice.Blocks = append(choice.Blocks, llmtypes.ContentBlock{Type: llmtypes.ContentBlockTypeReasoning, Text: block.Thinking})
 			}
 		case "tool_use":
 			// Convert tool use to tool call
@@ -641,6 +824,7 @@ func convertResponse(result *anthropic.Message) *llmtypes.ContentResponse {
 					Arguments: string(argsJSON),
 				},
 			}
+			choice.Blocks = append(choice.Blocks, llmtypes.ContentBlock{Type: llmtypes.ContentBlockTypeToolCall, ToolCallIndex: len(toolCalls)})
 			toolCalls = append(toolCalls, toolCall)
 		}
 	}
@@ -702,6 +886,11 @@ func convertResponse(result *anthropic.Message) *llmtypes.ContentResponse {
 		genInfo.Additional["CacheCreationInputTokens"] = cacheCreationTokens
 	}
 
+	// Report the service tier actually used
+	if result.Usage.ServiceTier != "" {
+		genInfo.Additional["service_tier"] = string(result.Usage.ServiceTier)
+	}
+
 	choice.GenerationInfo = genInfo
 
 	choices = append(choices, choice)
@@ -735,7 +924,7 @@ func (a *AnthropicAdapter) logInputDetails(modelID string, messages []llmtypes.M
 			if textPart, ok := msg.Parts[0].(llmtypes.TextContent); ok {
 				content := textPart.Text
 				if len(content) > 200 {
-					contentPreview = content[:200] + "..."
+					contentPreview = utils.TruncateUTF8(content, 200) + "..."
 				} else {
 					contentPreview = content
 				}
@@ -857,7 +1046,7 @@ func (a *AnthropicAdapter) logErrorDetails(modelID string, messages []llmtypes.M
 			if block.Type == "text" && block.Text != "" {
 				content := block.Text
 				if len(content) > 500 {
-					content = content[:500] + "..."
+					content = utils.TruncateUTF8(content, 500) + "..."
 				}
 				responseInfo["content_preview"] = content
 				responseInfo["content_length"] = len(block.Text)
@@ -889,3 +1078,58 @@ func (a *AnthropicAdapter) logErrorDetails(modelID string, messages []llmtypes.M
 	// Also log input details for full context
 	a.logInputDetails(modelID, messages, params, opts)
 }
+
+// convertServiceTier maps a generic service tier to Anthropic's service_tier values
+func convertServiceTier(tier string) (anthropic.MessageNewParamsServiceTier, bool) {
+	switch tier {
+	case "auto", "priority":
+		return anthropic.MessageNewParamsServiceTierAuto, true
+	case "default", "standard", "standard_only":
+		return anthropic.MessageNewParamsServiceTierStandardOnly, true
+	default:
+		return "", false
+	}
+}
+
+// applyAnthropicParams sets the fields of p that are set (WithAnthropicParams) on params
+func applyAnthropicParams(params *anthropic.MessageNewParams, p *llmtypes.AnthropicParams) {
+	if p.TopP != nil {
+		params.TopP = anthropic.Float(*p.TopP)
+	}
+	if p.TopK != nil {
+		params.TopK = anthropic.Int(*p.TopK)
+	}
+	if len(p.StopSequences) > 0 {
+		params.StopSequences = p.StopSequences
+	}
+	if p.UserID != "" {
+		params.Metadata = anthropic.MetadataParam{UserID: anthropic.String(p.UserID)}
+	}
+}
+
+// promptCachingBeta is the beta header value required for cache_control to work
+const promptCachingBeta = "prompt-caching-2024-07-31"
+
+// requestOptions builds per-request options: the prompt caching beta header plus
+// ExtraHeaders and ExtraBody. Fields already set on params win on conflict, and
+// an extra anthropic-beta header is combined with the prompt caching beta. SDK retries
+// are turned off when the caller retries (CallOptions.MaxRetries).
+func requestOptions(params anthropic.MessageNewParams, opts *llmtypes.CallOptions) []anthropicoption.RequestOption {
+	beta := promptCachingBeta
+	var reqOpts []anthropicoption.RequestOption
+	if opts.MaxRetries != nil {
+		reqOpts = append(reqOpts, anthropicoption.WithMaxRetries(0))
+	}
+	for key, value := range utils.RequestHeaders(opts) {
+		if strings.EqualFold(key, "anthropic-beta") {
+			beta = beta + "," + value
+			continue
+		}
+		reqOpts = append(reqOpts, anthropicoption.WithHeader(key, value))
+	}
+	reqOpts = append(reqOpts, anthropicoption.WithHeader("anthropic-beta", beta))
+	for key, value := range utils.ExtraBodyFields(params, opts.ExtraBody) {
+		reqOpts = append(reqOpts, anthropicoption.WithJSONSet(key, value))
+	}
+	return reqOpts
+}
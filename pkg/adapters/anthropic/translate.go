@@ -0,0 +1,106 @@
+package anthropic
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+// ToAnthropicMessages converts llmtypes messages to the Anthropic Messages API format.
+// It returns the conversation messages and the system prompt (empty if none).
+// This is the exact conversion used by AnthropicAdapter.GenerateContent.
+func ToAnthropicMessages(messages []llmtypes.MessageContent) ([]anthropic.MessageParam, string) {
+	return convertMessages(messages)
+}
+
+// FromAnthropicMessages converts Anthropic Messages API messages (and an optional system prompt)
+// back to llmtypes messages. User messages carrying tool_result blocks become Tool messages.
+func FromAnthropicMessages(messages []anthropic.MessageParam, system string) ([]llmtypes.MessageContent, error) {
+	result := make([]llmtypes.MessageContent, 0, len(messages)+1)
+	if system != "" {
+		result = append(result, llmtypes.TextPart(llmtypes.ChatMessageTypeSystem, system))
+	}
+
+	// Tool names are only carried on tool_use blocks, remember them for tool_result blocks
+	toolNames := make(map[string]string)
+
+	for i, msg := range messages {
+		var parts []llmtypes.ContentPart
+		var toolResponses []llmtypes.ContentPart
+
+		for _, block := range msg.Content {
+			switch {
+			case block.OfText != nil:
+				parts = append(parts, llmtypes.TextContent{Text: block.OfText.Text})
+			case block.OfImage != nil:
+				image, err := fromAnthropicImage(block.OfImage)
+				if err != nil {
+					return nil, fmt.Errorf("message %d: %w", i, err)
+				}
+				parts = append(parts, image)
+			case block.OfToolUse != nil:
+				args, err := json.Marshal(block.OfToolUse.Input)
+				if err != nil || string(args) == "null" {
+					args = []byte("{}")
+				}
+				toolNames[block.OfToolUse.ID] = block.OfToolUse.Name
+				parts = append(parts, llmtypes.ToolCall{
+					ID:   block.OfToolUse.ID,
+					Type: "function",
+					FunctionCall: &llmtypes.FunctionCall{
+						Name:      block.OfToolUse.Name,
+						Arguments: string(args),
+					},
+				})
+			case block.OfToolResult != nil:
+				var texts []string
+				for _, content := range block.OfToolResult.Content {
+					if content.OfText != nil {
+						texts = append(texts, content.OfText.Text)
+					}
+				}
+				toolResponses = append(toolResponses, llmtypes.ToolCallResponse{
+					ToolCallID: block.OfToolResult.ToolUseID,
+					Name:       toolNames[block.OfToolResult.ToolUseID],
+					Content:    strings.Join(texts, "\n"),
+					IsError:    block.OfToolResult.IsError.Value,
+				})
+			}
+		}
+
+		switch msg.Role {
+		case anthropic.MessageParamRoleAssistant:
+			result = append(result, llmtypes.MessageContent{Role: llmtypes.ChatMessageTypeAI, Parts: parts})
+		case anthropic.MessageParamRoleUser:
+			if len(toolResponses) > 0 {
+				result = append(result, llmtypes.MessageContent{Role: llmtypes.ChatMessageTypeTool, Parts: toolResponses})
+			}
+			if len(parts) > 0 {
+				result = append(result, llmtypes.MessageContent{Role: llmtypes.ChatMessageTypeHuman, Parts: parts})
+			}
+		default:
+			return nil, fmt.Errorf("message %d: unsupported role %q", i, msg.Role)
+		}
+	}
+
+	return result, nil
+}
+
+// fromAnthropicImage converts an Anthropic image block to ImageContent
+func fromAnthropicImage(image *anthropic.ImageBlockParam) (llmtypes.ImageContent, error) {
+	switch {
+	case image.Source.OfBase64 != nil:
+		return llmtypes.ImageContent{
+			SourceType: "base64",
+			MediaType:  string(image.Source.OfBase64.MediaType),
+			Data:       image.Source.OfBase64.Data,
+		}, nil
+	case image.Source.OfURL != nil:
+		return llmtypes.ImageContent{SourceType: "url", Data: image.Source.OfURL.URL}, nil
+	default:
+		return llmtypes.ImageContent{}, fmt.Errorf("unsupported image source")
+	}
+}
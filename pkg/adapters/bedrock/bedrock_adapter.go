@@ -2,6 +2,7 @@ package bedrock
 
 import (
 	"context"
+	"encoding/base64"
 	"encoding/json"
 	"fmt"
 	"math"
@@ -10,11 +11,14 @@ import (
 	"github.com/manishiitg/multi-llm-provider-go/interfaces"
 	"github.com/manishiitg/multi-llm-provider-go/internal/recorder"
 	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+	"github.com/manishiitg/multi-llm-provider-go/pkg/jsonschema"
+	"github.com/manishiitg/multi-llm-provider-go/pkg/utils"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
 	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/document"
 	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
 )
 
 // BedrockAdapter is an adapter that implements llmtypes.Model interface
@@ -53,22 +57,50 @@ func (b *BedrockAdapter) GenerateContent(ctx context.Context, messages []llmtype
 		modelID = opts.Model
 	}
 
+	// Apply the assistant prefill (natively for Claude models)
+	if opts.AssistantPrefill != "" {
+		return utils.GenerateWithPrefill(ctx, b, messages, options, isClaudeModel(modelID))
+	}
+
+	// Stop at the first complete tool call when requested
+	if opts.AbortOnToolCall && opts.StreamChan != nil {
+		return utils.GenerateUntilToolCall(ctx, b, messages, options)
+	}
+
+	// Bedrock has no native n>1, emulate it with concurrent requests
+	if opts.N > 1 {
+		return utils.GenerateChoicesConcurrently(ctx, b, opts.N, messages, options)
+	}
+
+	// Continue output cut off at the token limit when requested
+	if opts.AutoContinue > 0 {
+		return utils.GenerateWithAutoContinue(ctx, b, messages, options, isClaudeModel(modelID))
+	}
+
+	// Detect the media type of base64 images sent without one, converting unsupported formats
+	// when enabled
+	messages, err := utils.ResolveImageMediaTypes(messages, utils.BedrockImageLimits, opts.ImageAutoConvert)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check tool result images against the provider limits, downscaling when allowed
+	messages, err = utils.PrepareToolResultImages(messages, utils.BedrockImageLimits, opts.ToolResultImageMaxBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check the number and total size of images against the per-request limits
+	messages, err = utils.CheckImagePayload(messages, utils.BedrockImageLimits, opts.MaxImageCount, opts.ImageDownsampling)
+	if err != nil {
+		return nil, err
+	}
+
 	// Convert messages to Converse API format
 	converseMessages := convertMessagesToConverse(messages)
 
 	// Extract system message if present
-	var systemMessage []types.SystemContentBlock
-	for _, msg := range messages {
-		if string(msg.Role) == string(llmtypes.ChatMessageTypeSystem) {
-			for _, part := range msg.Parts {
-				if textPart, ok := part.(llmtypes.TextContent); ok {
-					systemMessage = append(systemMessage, &types.SystemContentBlockMemberText{
-						Value: textPart.Text,
-					})
-				}
-			}
-		}
-	}
+	systemMessage := convertSystemToConverse(messages)
 
 	// Build inference configuration
 	maxTokens := opts.MaxTokens
@@ -83,7 +115,11 @@ func (b *BedrockAdapter) GenerateContent(ctx context.Context, messages []llmtype
 		MaxTokens: aws.Int32(int32(maxTokens)),
 	}
 	if opts.Temperature > 0 {
-		temp := float32(opts.Temperature)
+		temperature, clamped := utils.ClampTemperature(opts.Temperature, utils.MaxTemperatureAnthropic)
+		if clamped && b.logger != nil {
+			b.logger.Infof("🌡️  Temperature %.2f is above the Bedrock maximum, using %.1f", opts.Temperature, temperature)
+		}
+		temp := float32(temperature)
 		inferenceConfig.Temperature = &temp
 	}
 
@@ -138,25 +174,107 @@ func (b *BedrockAdapter) GenerateContent(ctx context.Context, messages []llmtype
 		converseInput.System = systemMessage
 	}
 
-	// TODO: Set AdditionalModelRequestFields once correct format is confirmed
-	// if additionalFields != nil {
-	// 	converseInput.AdditionalModelRequestFields = additionalFields
-	// }
+	// Service tiers are not supported by Bedrock
+	if opts.ServiceTier != "" && b.logger != nil {
+		b.logger.Debugf("Service tier %q is not supported by Bedrock, ignoring", opts.ServiceTier)
+	}
+
+	// Pass extra body fields through as model-native request fields
+	if len(opts.ExtraBody) > 0 {
+		converseInput.AdditionalModelRequestFields = document.NewLazyDocument(opts.ExtraBody)
+	}
 
 	if toolConfig != nil {
 		converseInput.ToolConfig = toolConfig
 	}
 
+	// The trace ID is also sent as request metadata, shown in Bedrock's model invocation logs
+	if opts.TraceID != "" {
+		converseInput.RequestMetadata = map[string]string{"trace_id": opts.TraceID}
+	}
+
 	// Log input details if logger is available (for debugging errors)
 	if b.logger != nil {
 		b.logInputDetailsConverse(modelID, messages, converseInput, opts)
 	}
 
+	// Return the constructed request without calling the API
+	if opts.DryRun {
+		return utils.DryRunResponse(opts, converseInput), nil
+	}
+
 	// Always use streaming internally - for non-streaming requests, StreamChan is nil
 	// and we accumulate internally without sending chunks to the channel
 	return b.generateContentStreaming(ctx, modelID, converseInput, opts, messages)
 }
 
+// convertToolResultContent converts a tool response to Converse tool result blocks.
+// Images are only supported as base64 png/jpeg/gif/webp; URL images are referenced in text instead.
+func convertToolResultContent(toolResponse llmtypes.ToolCallResponse) []types.ToolResultContentBlock {
+	if len(toolResponse.Parts) == 0 {
+		return []types.ToolResultContentBlock{
+			&types.ToolResultContentBlockMemberText{Value: toolResponse.Content},
+		}
+	}
+
+	var content []types.ToolResultContentBlock
+	if toolResponse.Content != "" {
+		content = append(content, &types.ToolResultContentBlockMemberText{Value: toolResponse.Content})
+	}
+	for _, part := range toolResponse.Parts {
+		switch p := part.(type) {
+		case llmtypes.TextContent:
+			if p.Text != "" {
+				content = append(content, &types.ToolResultContentBlockMemberText{Value: p.Text})
+			}
+		case llmtypes.ImageContent:
+			if image, ok := convertImageToConverse(p); ok {
+				content = append(content, &types.ToolResultContentBlockMemberImage{Value: image})
+			} else if p.SourceType == "url" {
+				content = append(content, &types.ToolResultContentBlockMemberText{Value: fmt.Sprintf("[image: %s]", p.Data)})
+			}
+		}
+	}
+	if len(content) == 0 {
+		content = append(content, &types.ToolResultContentBlockMemberText{Value: ""})
+	}
+	return content
+}
+
+// convertImageToConverse converts a base64 image to a Converse image block
+func convertImageToConverse(img llmtypes.ImageContent) (types.ImageBlock, bool) {
+	if img.SourceType != "base64" {
+		return types.ImageBlock{}, false
+	}
+	data, err := base64.StdEncoding.DecodeString(img.Data)
+	if err != nil {
+		return types.ImageBlock{}, false
+	}
+	var format types.ImageFormat
+	switch strings.TrimPrefix(strings.ToLower(img.MediaType), "image/") {
+	case "png":
+		format = types.ImageFormatPng
+	case "jpeg", "jpg":
+		format = types.ImageFormatJpeg
+	case "gif":
+		format = types.ImageFormatGif
+	case "webp":
+		format = types.ImageFormatWebp
+	default:
+		return types.ImageBlock{}, false
+	}
+	return types.ImageBlock{
+		Format: format,
+		Source: &types.ImageSourceMemberBytes{Value: data},
+	}, true
+}
+
+// isClaudeModel reports whether a Bedrock model ID (or inference profile) refers to an Anthropic Claude model
+func isClaudeModel(modelID string) bool {
+	modelID = strings.ToLower(modelID)
+	return strings.Contains(modelID, "anthropic.") || strings.Contains(modelID, "claude")
+}
+
 // generateContentStreaming handles streaming responses from Bedrock ConverseStream API
 func (b *BedrockAdapter) generateContentStreaming(ctx context.Context, modelID string, converseInput *bedrockruntime.ConverseInput, opts *llmtypes.CallOptions, messages []llmtypes.MessageContent) (*llmtypes.ContentResponse, error) {
 	// Check for recorder in context (only if recording/replay might be enabled)
@@ -186,10 +304,28 @@ func (b *BedrockAdapter) generateContentStreaming(ctx context.Context, modelID s
 		System:          converseInput.System,
 		InferenceConfig: converseInput.InferenceConfig,
 		ToolConfig:      converseInput.ToolConfig,
+
+		AdditionalModelRequestFields: converseInput.AdditionalModelRequestFields,
+		RequestMetadata:              converseInput.RequestMetadata,
+	}
+
+	// Add extra headers to the request
+	var optFns []func(*bedrockruntime.Options)
+	for key, value := range utils.RequestHeaders(opts) {
+		header, headerValue := key, value
+		optFns = append(optFns, func(o *bedrockruntime.Options) {
+			o.APIOptions = append(o.APIOptions, smithyhttp.AddHeaderValue(header, headerValue))
+		})
+	}
+	// Make a single attempt when the caller retries (CallOptions.MaxRetries)
+	if opts.MaxRetries != nil {
+		optFns = append(optFns, func(o *bedrockruntime.Options) {
+			o.RetryMaxAttempts = 1
+		})
 	}
 
 	// Create streaming request
-	streamOutput, err := b.client.ConverseStream(ctx, streamInput)
+	streamOutput, err := b.client.ConverseStream(ctx, streamInput, optFns...)
 	if err != nil {
 		if b.logger != nil {
 			b.logErrorDetailsConverse(modelID, nil, converseInput, opts, err, nil)
@@ -220,11 +356,18 @@ func (b *BedrockAdapter) generateContentStreaming(ctx context.Context, modelID s
 	// Track content block index to tool use ID mapping
 	contentBlockIndexToToolUseID := make(map[int32]string)
 
+	// Blocks and tool use IDs in the order the model produced them
+	var blocks []llmtypes.ContentBlock
+	var toolUseIDs []string
+
 	// Collect events for recording
 	var recordedEventChunks []interface{}
 
 	// Process streaming events from channel
 	for event := range stream.Events() {
+		if opts.StreamEventHook != nil {
+			opts.StreamEventHook(event)
+		}
 		// Record event if recording is enabled
 		if rec != nil && rec.IsRecordingEnabled() {
 			eventJSON, err := json.Marshal(event)
@@ -246,6 +389,7 @@ func (b *BedrockAdapter) generateContentStreaming(ctx context.Context, modelID s
 					// Text content delta
 					if deltaVariant.Value != "" {
 						accumulatedContent.WriteString(deltaVariant.Value)
+						blocks = llmtypes.AppendTextBlock(blocks, llmtypes.ContentBlockTypeText, deltaVariant.Value)
 
 						// Stream content chunks immediately
 						if opts.StreamChan != nil {
@@ -337,6 +481,8 @@ func (b *BedrockAdapter) generateContentStreaming(ctx context.Context, modelID s
 
 					// Map index to tool use ID
 					contentBlockIndexToToolUseID[contentBlockIndex] = toolUseID
+					blocks = append(blocks, llmtypes.ContentBlock{Type: llmtypes.ContentBlockTypeToolCall, ToolCallIndex: len(toolUseIDs)})
+					toolUseIDs = append(toolUseIDs, toolUseID)
 
 					// Initialize tool call
 					toolCallMap[toolUseID] = &llmtypes.ToolCall{
@@ -451,6 +597,9 @@ func (b *BedrockAdapter) generateContentStreaming(ctx context.Context, modelID s
 			metadata := eventVariant.Value
 			if metadata.Usage != nil {
 				usage = metadata.Usage
+				if err := utils.SendUsageChunk(ctx, opts, streamUsage(usage)); err != nil {
+					return nil, err
+				}
 			}
 		}
 	}
@@ -463,8 +612,9 @@ func (b *BedrockAdapter) generateContentStreaming(ctx context.Context, modelID s
 		return nil, fmt.Errorf("bedrock streaming error: %w", err)
 	}
 
-	// Convert accumulated tool calls to slice
-	for _, toolCall := range toolCallMap {
+	// Convert accumulated tool calls to slice, in the order they were started
+	for _, toolUseID := range toolUseIDs {
+		toolCall := toolCallMap[toolUseID]
 		accumulatedToolCalls = append(accumulatedToolCalls, *toolCall)
 		// If tool call wasn't streamed yet, stream it now
 		if !completedToolCallIDs[toolCall.ID] && opts.StreamChan != nil {
@@ -490,6 +640,7 @@ func (b *BedrockAdapter) generateContentStreaming(ctx context.Context, modelID s
 		Content:    accumulatedContent.String(),
 		StopReason: stopReason,
 		ToolCalls:  accumulatedToolCalls,
+		Blocks:     blocks,
 	}
 
 	// Extract token usage
@@ -514,6 +665,15 @@ func (b *BedrockAdapter) generateContentStreaming(ctx context.Context, modelID s
 		resp.Usage = llmtypes.ExtractUsageFromGenerationInfo(resp.Choices[0].GenerationInfo)
 	}
 
+	// Send terminal chunk with stop reason and usage before the channel is closed
+	if opts.StreamChan != nil {
+		select {
+		case opts.StreamChan <- llmtypes.NewFinishChunk(resp):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
 	// Record events if recording is enabled (only build requestInfo if needed)
 	if rec != nil && rec.IsRecordingEnabled() && len(recordedEventChunks) > 0 {
 		requestInfo := buildRequestInfo(messages, modelID, opts)
@@ -550,151 +710,6 @@ func (b *BedrockAdapter) Call(ctx context.Context, prompt string, options ...llm
 	return resp.Choices[0].Content, nil
 }
 
-// GenerateEmbeddings implements the llmtypes.EmbeddingModel interface
-// Input can be a single string or a slice of strings
-// Supports Amazon Titan Text Embeddings models (v1 and v2)
-func (b *BedrockAdapter) GenerateEmbeddings(ctx context.Context, input interface{}, options ...llmtypes.EmbeddingOption) (*llmtypes.EmbeddingResponse, error) {
-	// Parse embedding options
-	opts := &llmtypes.EmbeddingOptions{
-		Model: "amazon.titan-embed-text-v1", // Default model
-	}
-	for _, opt := range options {
-		opt(opts)
-	}
-
-	// Use provided model or default
-	modelID := opts.Model
-	if modelID == "" {
-		modelID = "amazon.titan-embed-text-v1"
-	}
-
-	// Convert input to slice of strings
-	var inputTexts []string
-	switch v := input.(type) {
-	case string:
-		// Validate single string input
-		if strings.TrimSpace(v) == "" {
-			return nil, fmt.Errorf("input cannot be empty")
-		}
-		inputTexts = []string{v}
-	case []string:
-		// Array of strings input
-		if len(v) == 0 {
-			return nil, fmt.Errorf("input cannot be empty")
-		}
-		// Validate that no string in the array is empty
-		for i, text := range v {
-			if strings.TrimSpace(text) == "" {
-				return nil, fmt.Errorf("input at index %d cannot be empty", i)
-			}
-		}
-		inputTexts = v
-	default:
-		return nil, fmt.Errorf("input must be a string or []string, got %T", input)
-	}
-
-	// Determine default dimensions based on model
-	defaultDimensions := 1536 // Titan v1 default
-	if strings.Contains(modelID, "titan-embed-text-v2") {
-		defaultDimensions = 1024 // Titan v2 default
-	}
-
-	// Use provided dimensions or default
-	dimensions := defaultDimensions
-	if opts.Dimensions != nil {
-		dimensions = *opts.Dimensions
-	}
-
-	// Log input details if logger is available
-	if b.logger != nil {
-		b.logger.Debugf("Bedrock GenerateEmbeddings INPUT - model: %s, input_count: %d, dimensions: %d",
-			modelID, len(inputTexts), dimensions)
-	}
-
-	// Process each input text (Bedrock Titan supports single embedding per request)
-	// For batch, we'll make multiple requests
-	embeddings := make([]llmtypes.Embedding, 0, len(inputTexts))
-	var totalPromptTokens int
-
-	for i, text := range inputTexts {
-		// Build request body for Titan embedding
-		requestBody := map[string]interface{}{
-			"inputText": text,
-		}
-
-		// Add dimensions for Titan v2 (v1 doesn't support custom dimensions)
-		if strings.Contains(modelID, "titan-embed-text-v2") && dimensions != 1024 {
-			requestBody["dimensions"] = dimensions
-		}
-
-		// Marshal request body to JSON
-		bodyJSON, err := json.Marshal(requestBody)
-		if err != nil {
-			return nil, fmt.Errorf("failed to marshal request body: %w", err)
-		}
-
-		// Create InvokeModel input
-		invokeInput := &bedrockruntime.InvokeModelInput{
-			ModelId:     aws.String(modelID),
-			Body:        bodyJSON,
-			ContentType: aws.String("application/json"),
-			Accept:      aws.String("application/json"),
-		}
-
-		// Call InvokeModel
-		result, err := b.client.InvokeModel(ctx, invokeInput)
-		if err != nil {
-			if b.logger != nil {
-				b.logger.Errorf("Bedrock GenerateEmbeddings ERROR - model: %s, input_index: %d, error: %v", modelID, i, err)
-			}
-			return nil, fmt.Errorf("bedrock invoke model: %w", err)
-		}
-
-		// Parse response (result.Body is already []byte)
-		var embeddingResponse struct {
-			Embedding       []float64 `json:"embedding"`
-			InputTokenCount int       `json:"inputTokenCount,omitempty"`
-		}
-		if err := json.Unmarshal(result.Body, &embeddingResponse); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal response: %w", err)
-		}
-
-		// Convert float64 to float32
-		embedding32 := make([]float32, len(embeddingResponse.Embedding))
-		for j, v := range embeddingResponse.Embedding {
-			embedding32[j] = float32(v)
-		}
-
-		embeddings = append(embeddings, llmtypes.Embedding{
-			Index:     i,
-			Embedding: embedding32,
-			Object:    "embedding",
-		})
-
-		// Accumulate token usage
-		if embeddingResponse.InputTokenCount > 0 {
-			totalPromptTokens += embeddingResponse.InputTokenCount
-		}
-	}
-
-	// Build response
-	response := &llmtypes.EmbeddingResponse{
-		Embeddings: embeddings,
-		Model:      modelID,
-		Object:     "list",
-	}
-
-	// Add usage information if available
-	if totalPromptTokens > 0 {
-		response.Usage = &llmtypes.EmbeddingUsage{
-			PromptTokens: totalPromptTokens,
-			TotalTokens:  totalPromptTokens,
-		}
-	}
-
-	return response, nil
-}
-
 // convertMessagesToConverse converts llmtypes messages to Converse API format
 // processRecordedEvents processes recorded events as if they came from a live stream
 func (b *BedrockAdapter) processRecordedEvents(ctx context.Context, recordedEvents []map[string]interface{}, opts *llmtypes.CallOptions, modelID string) (*llmtypes.ContentResponse, error) {
@@ -711,6 +726,10 @@ func (b *BedrockAdapter) processRecordedEvents(ctx context.Context, recordedEven
 	// Track content block index to tool use ID mapping
 	contentBlockIndexToToolUseID := make(map[int32]string)
 
+	// Blocks and tool use IDs in the order the model produced them
+	var blocks []llmtypes.ContentBlock
+	var toolUseIDs []string
+
 	// Process each recorded event directly from map structure
 	for _, eventMap := range recordedEvents {
 		// Events are stored as {"Value": {...}} where Value contains the event data
@@ -727,6 +746,7 @@ func (b *BedrockAdapter) processRecordedEvents(ctx context.Context, recordedEven
 				// Check if it's text content
 				if textValue, hasText := deltaMap["Value"].(string); hasText && textValue != "" {
 					accumulatedContent.WriteString(textValue)
+					blocks = llmtypes.AppendTextBlock(blocks, llmtypes.ContentBlockTypeText, textValue)
 					if opts.StreamChan != nil {
 						select {
 						case opts.StreamChan <- llmtypes.StreamChunk{
@@ -780,6 +800,8 @@ func (b *BedrockAdapter) processRecordedEvents(ctx context.Context, recordedEven
 						index := int32(contentBlockIndex.(float64))
 
 						contentBlockIndexToToolUseID[index] = toolUseID
+						blocks = append(blocks, llmtypes.ContentBlock{Type: llmtypes.ContentBlockTypeToolCall, ToolCallIndex: len(toolUseIDs)})
+						toolUseIDs = append(toolUseIDs, toolUseID)
 						toolCallMap[toolUseID] = &llmtypes.ToolCall{
 							ID:   toolUseID,
 							Type: "function",
@@ -829,13 +851,17 @@ func (b *BedrockAdapter) processRecordedEvents(ctx context.Context, recordedEven
 			var tokenUsage types.TokenUsage
 			if json.Unmarshal(usageJSON, &tokenUsage) == nil {
 				usage = &tokenUsage
+				if err := utils.SendUsageChunk(ctx, opts, streamUsage(usage)); err != nil {
+					return nil, err
+				}
 			}
 		}
 		continue // Skip the switch statement below since we processed directly
 	}
 
-	// Convert accumulated tool calls to slice
-	for _, toolCall := range toolCallMap {
+	// Convert accumulated tool calls to slice, in the order they were started
+	for _, toolUseID := range toolUseIDs {
+		toolCall := toolCallMap[toolUseID]
 		accumulatedToolCalls = append(accumulatedToolCalls, *toolCall)
 		if !completedToolCallIDs[toolCall.ID] && opts.StreamChan != nil {
 			toolCallCopy := *toolCall
@@ -859,6 +885,7 @@ func (b *BedrockAdapter) processRecordedEvents(ctx context.Context, recordedEven
 		Content:    accumulatedContent.String(),
 		StopReason: stopReason,
 		ToolCalls:  accumulatedToolCalls,
+		Blocks:     blocks,
 	}
 
 	// Extract token usage
@@ -883,6 +910,15 @@ func (b *BedrockAdapter) processRecordedEvents(ctx context.Context, recordedEven
 		resp.Usage = llmtypes.ExtractUsageFromGenerationInfo(resp.Choices[0].GenerationInfo)
 	}
 
+	// Send terminal chunk with stop reason and usage before the channel is closed
+	if opts.StreamChan != nil {
+		select {
+		case opts.StreamChan <- llmtypes.NewFinishChunk(resp):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
 	return resp, nil
 }
 
@@ -926,6 +962,7 @@ func buildRequestInfo(messages []llmtypes.MessageContent, modelID string, opts *
 
 func convertMessagesToConverse(langMessages []llmtypes.MessageContent) []types.Message {
 	converseMessages := make([]types.Message, 0, len(langMessages))
+	langMessages = utils.PrefixMessageNames(langMessages)
 
 	for _, msg := range langMessages {
 		var contentBlocks []types.ContentBlock
@@ -948,21 +985,22 @@ func convertMessagesToConverse(langMessages []llmtypes.MessageContent) []types.M
 					})
 				}
 			case llmtypes.ImageContent:
-				// Handle image content (simplified - would need base64 conversion)
-				// For now, skip images in Converse API migration
-				// TODO: Implement image support
+				// Converse only takes image bytes; URL images are referenced in text
+				if image, ok := convertImageToConverse(p); ok {
+					contentBlocks = append(contentBlocks, &types.ContentBlockMemberImage{Value: image})
+				} else if p.SourceType == "url" {
+					contentBlocks = append(contentBlocks, &types.ContentBlockMemberText{Value: fmt.Sprintf("[image: %s]", p.Data)})
+				}
 			case llmtypes.ToolCallResponse:
 				// Tool response - convert to ToolResult content block
-				contentBlocks = append(contentBlocks, &types.ContentBlockMemberToolResult{
-					Value: types.ToolResultBlock{
-						ToolUseId: aws.String(p.ToolCallID),
-						Content: []types.ToolResultContentBlock{
-							&types.ToolResultContentBlockMemberText{
-								Value: p.Content,
-							},
-						},
-					},
-				})
+				toolResult := types.ToolResultBlock{
+					ToolUseId: aws.String(p.ToolCallID),
+					Content:   convertToolResultContent(p),
+				}
+				if p.IsError {
+					toolResult.Status = types.ToolResultStatusError
+				}
+				contentBlocks = append(contentBlocks, &types.ContentBlockMemberToolResult{Value: toolResult})
 			case llmtypes.ToolCall:
 				// Tool call in assistant message - convert to ToolUse content block
 				var inputDoc document.Interface
@@ -1018,6 +1056,23 @@ func convertMessagesToConverse(langMessages []llmtypes.MessageContent) []types.M
 	return converseMessages
 }
 
+// convertSystemToConverse extracts system message text parts as Converse system blocks
+func convertSystemToConverse(langMessages []llmtypes.MessageContent) []types.SystemContentBlock {
+	var systemMessage []types.SystemContentBlock
+	for _, msg := range langMessages {
+		if string(msg.Role) == string(llmtypes.ChatMessageTypeSystem) {
+			for _, part := range msg.Parts {
+				if textPart, ok := part.(llmtypes.TextContent); ok {
+					systemMessage = append(systemMessage, &types.SystemContentBlockMemberText{
+						Value: textPart.Text,
+					})
+				}
+			}
+		}
+	}
+	return systemMessage
+}
+
 // convertToolsToConverse converts llmtypes tools to Converse API format
 func (b *BedrockAdapter) convertToolsToConverse(llmTools []llmtypes.Tool) []types.Tool {
 	converseTools := make([]types.Tool, 0, len(llmTools))
@@ -1066,6 +1121,14 @@ func (b *BedrockAdapter) convertToolsToConverse(llmTools []llmtypes.Tool) []type
 			inputSchema["type"] = "object"
 		}
 
+		// Models other than Claude may not resolve $refs, so send definitions inline
+		inputSchema, changes := jsonschema.Adapt(inputSchema, jsonschema.Compat{InlineRefs: true})
+		if b.logger != nil {
+			for _, change := range changes {
+				b.logger.Infof("🔧 [BEDROCK] Tool %s schema: %s", tool.Function.Name, change)
+			}
+		}
+
 		// Convert schema to document for ToolInputSchema
 		// document.NewLazyDocument can accept Go types directly (map, struct, etc.)
 		// This is the recommended approach per AWS SDK documentation
@@ -1285,7 +1348,7 @@ func (b *BedrockAdapter) logInputDetailsConverse(modelID string, messages []llmt
 			if textPart, ok := msg.Parts[0].(llmtypes.TextContent); ok {
 				content := textPart.Text
 				if len(content) > 200 {
-					contentPreview = content[:200] + "..."
+					contentPreview = utils.TruncateUTF8(content, 200) + "..."
 				} else {
 					contentPreview = content
 				}
@@ -1496,7 +1559,7 @@ func (b *BedrockAdapter) logErrorDetailsConverse(modelID string, messages []llmt
 					if textBlock, ok := message.Content[0].(*types.ContentBlockMemberText); ok {
 						content := textBlock.Value
 						if len(content) > 500 {
-							content = content[:500] + "..."
+							content = utils.TruncateUTF8(content, 500) + "..."
 						}
 						responseInfo["content_preview"] = content
 						responseInfo["content_length"] = len(textBlock.Value)
@@ -1583,3 +1646,12 @@ func (b *BedrockAdapter) logErrorDetailsConverse(modelID string, messages []llmt
 		}
 	}
 }
+
+// streamUsage converts the usage reported in a stream metadata event
+func streamUsage(usage *types.TokenUsage) *llmtypes.Usage {
+	return &llmtypes.Usage{
+		InputTokens:  int(aws.ToInt32(usage.InputTokens)),
+		OutputTokens: int(aws.ToInt32(usage.OutputTokens)),
+		TotalTokens:  int(aws.ToInt32(usage.TotalTokens)),
+	}
+}
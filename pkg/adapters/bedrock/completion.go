@@ -0,0 +1,271 @@
+package bedrock
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+	"github.com/manishiitg/multi-llm-provider-go/pkg/utils"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
+)
+
+// completionFamily is the InvokeModel request and response format of a text model family
+type completionFamily struct {
+	// marker identifies the family in a model ID (model IDs may carry a region prefix)
+	marker string
+	// request builds the request body
+	request func(prompt string, opts *llmtypes.CallOptions) map[string]interface{}
+	// response parses the response body
+	response func(body []byte) (*llmtypes.ContentResponse, error)
+}
+
+// completionFamilies are the text model families Complete supports
+var completionFamilies = []completionFamily{
+	{
+		marker: "meta.llama",
+		request: func(prompt string, opts *llmtypes.CallOptions) map[string]interface{} {
+			body := map[string]interface{}{"prompt": prompt}
+			setIfPositive(body, "max_gen_len", opts.MaxTokens)
+			setIfPositiveFloat(body, "temperature", opts.Temperature)
+			return body
+		},
+		response: func(body []byte) (*llmtypes.ContentResponse, error) {
+			var result struct {
+				Generation           string `json:"generation"`
+				StopReason           string `json:"stop_reason"`
+				PromptTokenCount     int    `json:"prompt_token_count"`
+				GenerationTokenCount int    `json:"generation_token_count"`
+			}
+			if err := json.Unmarshal(body, &result); err != nil {
+				return nil, err
+			}
+			resp := completionResponse([]string{result.Generation}, []string{result.StopReason})
+			resp.Usage = completionUsage(result.PromptTokenCount, result.GenerationTokenCount)
+			return resp, nil
+		},
+	},
+	{
+		marker: "amazon.titan-text",
+		request: func(prompt string, opts *llmtypes.CallOptions) map[string]interface{} {
+			config := map[string]interface{}{}
+			setIfPositive(config, "maxTokenCount", opts.MaxTokens)
+			setIfPositiveFloat(config, "temperature", opts.Temperature)
+			return map[string]interface{}{"inputText": prompt, "textGenerationConfig": config}
+		},
+		response: func(body []byte) (*llmtypes.ContentResponse, error) {
+			var result struct {
+				InputTextTokenCount int `json:"inputTextTokenCount"`
+				Results             []struct {
+					TokenCount       int    `json:"tokenCount"`
+					OutputText       string `json:"outputText"`
+					CompletionReason string `json:"completionReason"`
+				} `json:"results"`
+			}
+			if err := json.Unmarshal(body, &result); err != nil {
+				return nil, err
+			}
+			texts := make([]string, len(result.Results))
+			reasons := make([]string, len(result.Results))
+			outputTokens := 0
+			for i, r := range result.Results {
+				texts[i], reasons[i] = r.OutputText, r.CompletionReason
+				outputTokens += r.TokenCount
+			}
+			resp := completionResponse(texts, reasons)
+			resp.Usage = completionUsage(result.InputTextTokenCount, outputTokens)
+			return resp, nil
+		},
+	},
+	{
+		marker:  "mistral.",
+		request: promptMaxTokensRequest,
+		response: func(body []byte) (*llmtypes.ContentResponse, error) {
+			var result struct {
+				Outputs []struct {
+					Text       string `json:"text"`
+					StopReason string `json:"stop_reason"`
+				} `json:"outputs"`
+			}
+			if err := json.Unmarshal(body, &result); err != nil {
+				return nil, err
+			}
+			texts := make([]string, len(result.Outputs))
+			reasons := make([]string, len(result.Outputs))
+			for i, output := range result.Outputs {
+				texts[i], reasons[i] = output.Text, output.StopReason
+			}
+			return completionResponse(texts, reasons), nil
+		},
+	},
+	{
+		marker:  "cohere.command",
+		request: promptMaxTokensRequest,
+		response: func(body []byte) (*llmtypes.ContentResponse, error) {
+			var result struct {
+				Generations []struct {
+					Text         string `json:"text"`
+					FinishReason string `json:"finish_reason"`
+				} `json:"generations"`
+			}
+			if err := json.Unmarshal(body, &result); err != nil {
+				return nil, err
+			}
+			texts := make([]string, len(result.Generations))
+			reasons := make([]string, len(result.Generations))
+			for i, generation := range result.Generations {
+				texts[i], reasons[i] = generation.Text, generation.FinishReason
+			}
+			return completionResponse(texts, reasons), nil
+		},
+	},
+	{
+		marker:  "deepseek.",
+		request: promptMaxTokensRequest,
+		response: func(body []byte) (*llmtypes.ContentResponse, error) {
+			var result struct {
+				Choices []struct {
+					Text       string `json:"text"`
+					StopReason string `json:"stop_reason"`
+				} `json:"choices"`
+			}
+			if err := json.Unmarshal(body, &result); err != nil {
+				return nil, err
+			}
+			texts := make([]string, len(result.Choices))
+			reasons := make([]string, len(result.Choices))
+			for i, choice := range result.Choices {
+				texts[i], reasons[i] = choice.Text, choice.StopReason
+			}
+			return completionResponse(texts, reasons), nil
+		},
+	},
+}
+
+// Complete implements the llmtypes.CompletionModel interface with InvokeModel for text
+// models that take a raw prompt: Meta Llama, Amazon Titan Text, Mistral, Cohere Command
+// and DeepSeek. Model, temperature, max tokens and dry run are supported. Responses are
+// not streamed; with a stream channel the whole text is sent as one chunk.
+func (b *BedrockAdapter) Complete(ctx context.Context, prompt string, options ...llmtypes.CallOption) (*llmtypes.ContentResponse, error) {
+	opts := &llmtypes.CallOptions{}
+	for _, opt := range options {
+		opt(opts)
+	}
+	if opts.StreamChan != nil {
+		defer close(opts.StreamChan)
+	}
+	if len(opts.Tools) > 0 {
+		return nil, fmt.Errorf("tools are not supported by raw prompt completions")
+	}
+
+	modelID := b.modelID
+	if opts.Model != "" {
+		modelID = opts.Model
+	}
+	var family *completionFamily
+	for i := range completionFamilies {
+		if strings.Contains(modelID, completionFamilies[i].marker) {
+			family = &completionFamilies[i]
+			break
+		}
+	}
+	if family == nil {
+		return nil, fmt.Errorf("raw prompt completions are not supported for bedrock model %s", modelID)
+	}
+
+	requestBody := family.request(prompt, opts)
+	if opts.DryRun {
+		return &llmtypes.ContentResponse{DryRun: true, Raw: requestBody}, nil
+	}
+	bodyJSON, err := json.Marshal(requestBody)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request body: %w", err)
+	}
+
+	if b.logger != nil {
+		b.logger.Debugf("Bedrock Complete INPUT - model: %s, prompt_chars: %d", modelID, len(prompt))
+	}
+	result, err := b.client.InvokeModel(ctx, &bedrockruntime.InvokeModelInput{
+		ModelId:     aws.String(modelID),
+		Body:        bodyJSON,
+		ContentType: aws.String("application/json"),
+		Accept:      aws.String("application/json"),
+	})
+	if err != nil {
+		if b.logger != nil {
+			b.logger.Errorf("Bedrock Complete ERROR - model: %s, error: %v", modelID, err)
+		}
+		return nil, fmt.Errorf("bedrock invoke model: %w", err)
+	}
+
+	resp, err := family.response(result.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+	}
+	if len(resp.Choices) == 0 {
+		return nil, fmt.Errorf("no choices in response")
+	}
+
+	if opts.StreamChan != nil {
+		for i, choice := range resp.Choices {
+			if choice.Content == "" {
+				continue
+			}
+			select {
+			case opts.StreamChan <- llmtypes.StreamChunk{Type: llmtypes.StreamChunkTypeContent, Content: choice.Content, ChoiceIndex: i}:
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+		utils.SendUsageChunk(ctx, opts, resp.Usage)
+		select {
+		case opts.StreamChan <- llmtypes.NewFinishChunk(resp):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	return resp, nil
+}
+
+// promptMaxTokensRequest builds the {prompt, max_tokens, temperature} body shared by
+// several families
+func promptMaxTokensRequest(prompt string, opts *llmtypes.CallOptions) map[string]interface{} {
+	body := map[string]interface{}{"prompt": prompt}
+	setIfPositive(body, "max_tokens", opts.MaxTokens)
+	setIfPositiveFloat(body, "temperature", opts.Temperature)
+	return body
+}
+
+// setIfPositive sets body[key] when value is set
+func setIfPositive(body map[string]interface{}, key string, value int) {
+	if value > 0 {
+		body[key] = value
+	}
+}
+
+// setIfPositiveFloat sets body[key] when value is set
+func setIfPositiveFloat(body map[string]interface{}, key string, value float64) {
+	if value > 0 {
+		body[key] = value
+	}
+}
+
+// completionResponse builds a response with one choice per generated text
+func completionResponse(texts, stopReasons []string) *llmtypes.ContentResponse {
+	resp := &llmtypes.ContentResponse{Choices: make([]*llmtypes.ContentChoice, len(texts))}
+	for i, text := range texts {
+		resp.Choices[i] = &llmtypes.ContentChoice{Content: text, StopReason: stopReasons[i]}
+	}
+	return resp
+}
+
+// completionUsage returns the token usage, or nil when the model reported none
+func completionUsage(inputTokens, outputTokens int) *llmtypes.Usage {
+	if inputTokens == 0 && outputTokens == 0 {
+		return nil
+	}
+	return &llmtypes.Usage{InputTokens: inputTokens, OutputTokens: outputTokens, TotalTokens: inputTokens + outputTokens}
+}
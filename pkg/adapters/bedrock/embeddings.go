@@ -0,0 +1,290 @@
+package bedrock
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
+)
+
+// defaultEmbeddingModel is used when neither the adapter nor the call names a model
+const defaultEmbeddingModel = "amazon.titan-embed-text-v1"
+
+// defaultCohereInputType is the Cohere input_type sent without a task type: documents to index
+const defaultCohereInputType = "search_document"
+
+// cohereInputTypes maps task types to Cohere input_type values
+var cohereInputTypes = map[llmtypes.EmbeddingTaskType]string{
+	llmtypes.EmbeddingTaskRetrievalQuery:    "search_query",
+	llmtypes.EmbeddingTaskRetrievalDocument: "search_document",
+	llmtypes.EmbeddingTaskClassification:    "classification",
+	llmtypes.EmbeddingTaskClustering:        "clustering",
+}
+
+// embeddingFamily describes the InvokeModel request format and limits of a family of
+// Bedrock embedding models
+type embeddingFamily struct {
+	name string
+	// dimensions lists the accepted output sizes, the first being the default
+	dimensions []int
+	// batchSize is the number of texts sent per request
+	batchSize int
+	// maxChars is the longest text accepted, 0 for no check
+	maxChars int
+	// inputTypes maps the supported task types to the model's input type, nil if the
+	// model has none (the task type is then ignored)
+	inputTypes map[llmtypes.EmbeddingTaskType]string
+	// request builds the request body for texts
+	request func(texts []string, dimensions int, inputType string) map[string]interface{}
+	// parse returns the embeddings and input tokens (0 if not reported) of a response body
+	parse func(body []byte) ([][]float64, int, error)
+}
+
+var (
+	titanTextV1Family = &embeddingFamily{
+		name:       "Titan Text Embeddings v1",
+		dimensions: []int{1536},
+		batchSize:  1,
+		maxChars:   50000,
+		request: func(texts []string, dimensions int, inputType string) map[string]interface{} {
+			return map[string]interface{}{"inputText": texts[0]}
+		},
+		parse: parseTitanEmbedding,
+	}
+	titanTextV2Family = &embeddingFamily{
+		name:       "Titan Text Embeddings v2",
+		dimensions: []int{1024, 512, 256},
+		batchSize:  1,
+		maxChars:   50000,
+		request: func(texts []string, dimensions int, inputType string) map[string]interface{} {
+			return map[string]interface{}{"inputText": texts[0], "dimensions": dimensions}
+		},
+		parse: parseTitanEmbedding,
+	}
+	titanMultimodalFamily = &embeddingFamily{
+		name:       "Titan Multimodal Embeddings",
+		dimensions: []int{1024, 384, 256},
+		batchSize:  1,
+		request: func(texts []string, dimensions int, inputType string) map[string]interface{} {
+			return map[string]interface{}{"inputText": texts[0], "embeddingConfig": map[string]interface{}{"outputEmbeddingLength": dimensions}}
+		},
+		parse: parseTitanEmbedding,
+	}
+	cohereV3Family = &embeddingFamily{
+		name:       "Cohere Embed v3",
+		dimensions: []int{1024},
+		batchSize:  96,
+		maxChars:   2048,
+		inputTypes: cohereInputTypes,
+		request: func(texts []string, dimensions int, inputType string) map[string]interface{} {
+			return map[string]interface{}{"texts": texts, "input_type": inputType}
+		},
+		parse: parseCohereEmbeddings,
+	}
+	cohereV4Family = &embeddingFamily{
+		name:       "Cohere Embed v4",
+		dimensions: []int{1536, 1024, 512, 256},
+		batchSize:  96,
+		inputTypes: cohereInputTypes,
+		request: func(texts []string, dimensions int, inputType string) map[string]interface{} {
+			return map[string]interface{}{"texts": texts, "input_type": inputType, "output_dimension": dimensions, "embedding_types": []string{"float"}}
+		},
+		parse: parseCohereEmbeddings,
+	}
+)
+
+// bedrockEmbeddingFamily returns the family of modelID, which may carry a cross-region
+// prefix ("us.cohere.embed-v4:0") or be an ARN
+func bedrockEmbeddingFamily(modelID string) (*embeddingFamily, error) {
+	id := strings.ToLower(modelID)
+	switch {
+	case strings.Contains(id, "amazon.titan-embed-text-v2"):
+		return titanTextV2Family, nil
+	case strings.Contains(id, "amazon.titan-embed-text-v1"), strings.Contains(id, "amazon.titan-embed-g1-text"):
+		return titanTextV1Family, nil
+	case strings.Contains(id, "amazon.titan-embed-image"):
+		return titanMultimodalFamily, nil
+	case strings.Contains(id, "cohere.embed-v4"):
+		return cohereV4Family, nil
+	case strings.Contains(id, "cohere.embed-english-v3"), strings.Contains(id, "cohere.embed-multilingual-v3"):
+		return cohereV3Family, nil
+	}
+	return nil, fmt.Errorf("unsupported Bedrock embedding model %q: supported families are amazon.titan-embed-text-v1/v2, amazon.titan-embed-image-v1, cohere.embed-english-v3, cohere.embed-multilingual-v3 and cohere.embed-v4", modelID)
+}
+
+// parseTitanEmbedding parses an Amazon Titan embedding response
+func parseTitanEmbedding(body []byte) ([][]float64, int, error) {
+	var response struct {
+		Embedding       []float64 `json:"embedding"`
+		InputTokenCount int       `json:"inputTextTokenCount"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, 0, err
+	}
+	return [][]float64{response.Embedding}, response.InputTokenCount, nil
+}
+
+// parseCohereEmbeddings parses a Cohere embed response: embeddings is a list of vectors
+// (v3, embeddings_floats) or an object keyed by embedding type (v4, embeddings_by_type)
+func parseCohereEmbeddings(body []byte) ([][]float64, int, error) {
+	var response struct {
+		Embeddings json.RawMessage `json:"embeddings"`
+	}
+	if err := json.Unmarshal(body, &response); err != nil {
+		return nil, 0, err
+	}
+	var vectors [][]float64
+	if err := json.Unmarshal(response.Embeddings, &vectors); err == nil {
+		return vectors, 0, nil
+	}
+	var byType struct {
+		Float [][]float64 `json:"float"`
+	}
+	if err := json.Unmarshal(response.Embeddings, &byType); err != nil {
+		return nil, 0, err
+	}
+	return byType.Float, 0, nil
+}
+
+// GenerateEmbeddings implements the llmtypes.EmbeddingModel interface for the Amazon Titan
+// and Cohere embedding models on Bedrock. Input can be a single string or a slice of
+// strings; Titan embeds one text per request and Cohere up to 96. Dimensions, text
+// lengths and the task type (the Cohere input_type, search_document by default) are
+// checked against the model family's limits before any request is made.
+func (b *BedrockAdapter) GenerateEmbeddings(ctx context.Context, input interface{}, options ...llmtypes.EmbeddingOption) (*llmtypes.EmbeddingResponse, error) {
+	opts := &llmtypes.EmbeddingOptions{}
+	for _, opt := range options {
+		opt(opts)
+	}
+
+	modelID := opts.Model
+	if modelID == "" {
+		modelID = b.modelID
+	}
+	if modelID == "" {
+		modelID = defaultEmbeddingModel
+	}
+	family, err := bedrockEmbeddingFamily(modelID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Convert input to slice of strings
+	var inputTexts []string
+	switch v := input.(type) {
+	case string:
+		// Validate single string input
+		if strings.TrimSpace(v) == "" {
+			return nil, fmt.Errorf("input cannot be empty")
+		}
+		inputTexts = []string{v}
+	case []string:
+		// Array of strings input
+		if len(v) == 0 {
+			return nil, fmt.Errorf("input cannot be empty")
+		}
+		// Validate that no string in the array is empty
+		for i, text := range v {
+			if strings.TrimSpace(text) == "" {
+				return nil, fmt.Errorf("input at index %d cannot be empty", i)
+			}
+		}
+		inputTexts = v
+	default:
+		return nil, fmt.Errorf("input must be a string or []string, got %T", input)
+	}
+
+	dimensions := family.dimensions[0]
+	if opts.Dimensions != nil {
+		dimensions = *opts.Dimensions
+		if !slices.Contains(family.dimensions, dimensions) {
+			return nil, fmt.Errorf("%s (%s) does not support %d dimensions, supported: %v", family.name, modelID, dimensions, family.dimensions)
+		}
+	}
+	var inputType string
+	if family.inputTypes != nil {
+		inputType = defaultCohereInputType
+		if opts.TaskType != "" {
+			var ok bool
+			if inputType, ok = family.inputTypes[opts.TaskType]; !ok {
+				return nil, fmt.Errorf("%s (%s) does not support embedding task type %q, supported: %v", family.name, modelID, opts.TaskType, slices.Sorted(maps.Keys(family.inputTypes)))
+			}
+		}
+	}
+	if family.maxChars > 0 {
+		for i, text := range inputTexts {
+			if len(text) > family.maxChars {
+				return nil, fmt.Errorf("input at index %d is %d characters, %s accepts at most %d", i, len(text), family.name, family.maxChars)
+			}
+		}
+	}
+
+	// Log input details if logger is available
+	if b.logger != nil {
+		b.logger.Debugf("Bedrock GenerateEmbeddings INPUT - model: %s (%s), input_count: %d, dimensions: %d, input_type: %s",
+			modelID, family.name, len(inputTexts), dimensions, inputType)
+	}
+
+	embeddings := make([]llmtypes.Embedding, 0, len(inputTexts))
+	var totalPromptTokens int
+	for start := 0; start < len(inputTexts); start += family.batchSize {
+		batch := inputTexts[start:min(start+family.batchSize, len(inputTexts))]
+		bodyJSON, err := json.Marshal(family.request(batch, dimensions, inputType))
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal request body: %w", err)
+		}
+
+		result, err := b.client.InvokeModel(ctx, &bedrockruntime.InvokeModelInput{
+			ModelId:     aws.String(modelID),
+			Body:        bodyJSON,
+			ContentType: aws.String("application/json"),
+			Accept:      aws.String("application/json"),
+		})
+		if err != nil {
+			if b.logger != nil {
+				b.logger.Errorf("Bedrock GenerateEmbeddings ERROR - model: %s, input_index: %d, error: %v", modelID, start, err)
+			}
+			return nil, fmt.Errorf("bedrock invoke model: %w", err)
+		}
+
+		vectors, inputTokens, err := family.parse(result.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to unmarshal response: %w", err)
+		}
+		if len(vectors) != len(batch) {
+			return nil, fmt.Errorf("bedrock returned %d embeddings for %d inputs", len(vectors), len(batch))
+		}
+		for i, vector := range vectors {
+			embedding32 := make([]float32, len(vector))
+			for j, v := range vector {
+				embedding32[j] = float32(v)
+			}
+			embeddings = append(embeddings, llmtypes.Embedding{
+				Index:     start + i,
+				Embedding: embedding32,
+				Object:    "embedding",
+			})
+		}
+		totalPromptTokens += inputTokens
+	}
+
+	response := &llmtypes.EmbeddingResponse{
+		Embeddings: embeddings,
+		Model:      modelID,
+		Object:     "list",
+	}
+	if totalPromptTokens > 0 {
+		response.Usage = &llmtypes.EmbeddingUsage{
+			PromptTokens: totalPromptTokens,
+			TotalTokens:  totalPromptTokens,
+		}
+	}
+	return response, nil
+}
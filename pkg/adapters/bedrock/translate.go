@@ -0,0 +1,112 @@
+package bedrock
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime/types"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+// ToBedrockConverse converts llmtypes messages to the Bedrock Converse API format.
+// It returns the conversation messages and the system blocks.
+// This is the exact conversion used by BedrockAdapter.GenerateContent.
+func ToBedrockConverse(messages []llmtypes.MessageContent) ([]types.Message, []types.SystemContentBlock) {
+	return convertMessagesToConverse(messages), convertSystemToConverse(messages)
+}
+
+// FromBedrockConverse converts Bedrock Converse messages and system blocks back to llmtypes messages.
+// User messages carrying toolResult blocks become Tool messages.
+func FromBedrockConverse(messages []types.Message, system []types.SystemContentBlock) ([]llmtypes.MessageContent, error) {
+	result := make([]llmtypes.MessageContent, 0, len(messages)+1)
+
+	var systemParts []llmtypes.ContentPart
+	for _, block := range system {
+		if text, ok := block.(*types.SystemContentBlockMemberText); ok {
+			systemParts = append(systemParts, llmtypes.TextContent{Text: text.Value})
+		}
+	}
+	if len(systemParts) > 0 {
+		result = append(result, llmtypes.MessageContent{Role: llmtypes.ChatMessageTypeSystem, Parts: systemParts})
+	}
+
+	// Tool names are only carried on toolUse blocks, remember them for toolResult blocks
+	toolNames := make(map[string]string)
+
+	for i, msg := range messages {
+		var parts []llmtypes.ContentPart
+		var toolResponses []llmtypes.ContentPart
+
+		for _, block := range msg.Content {
+			switch b := block.(type) {
+			case *types.ContentBlockMemberText:
+				parts = append(parts, llmtypes.TextContent{Text: b.Value})
+			case *types.ContentBlockMemberImage:
+				source, ok := b.Value.Source.(*types.ImageSourceMemberBytes)
+				if !ok {
+					return nil, fmt.Errorf("message %d: unsupported image source", i)
+				}
+				parts = append(parts, llmtypes.ImageContent{
+					SourceType: "base64",
+					MediaType:  "image/" + string(b.Value.Format),
+					Data:       base64.StdEncoding.EncodeToString(source.Value),
+				})
+			case *types.ContentBlockMemberToolUse:
+				args := "{}"
+				if b.Value.Input != nil {
+					if data, err := b.Value.Input.MarshalSmithyDocument(); err == nil && len(data) > 0 && string(data) != "null" {
+						args = string(data)
+					}
+				}
+				id := aws.ToString(b.Value.ToolUseId)
+				name := aws.ToString(b.Value.Name)
+				toolNames[id] = name
+				parts = append(parts, llmtypes.ToolCall{
+					ID:   id,
+					Type: "function",
+					FunctionCall: &llmtypes.FunctionCall{
+						Name:      name,
+						Arguments: args,
+					},
+				})
+			case *types.ContentBlockMemberToolResult:
+				var texts []string
+				for _, content := range b.Value.Content {
+					switch c := content.(type) {
+					case *types.ToolResultContentBlockMemberText:
+						texts = append(texts, c.Value)
+					case *types.ToolResultContentBlockMemberJson:
+						if c.Value != nil {
+							if data, err := c.Value.MarshalSmithyDocument(); err == nil {
+								texts = append(texts, string(data))
+							}
+						}
+					}
+				}
+				id := aws.ToString(b.Value.ToolUseId)
+				toolResponses = append(toolResponses, llmtypes.ToolCallResponse{
+					ToolCallID: id,
+					Name:       toolNames[id],
+					Content:    strings.Join(texts, "\n"),
+					IsError:    b.Value.Status == types.ToolResultStatusError,
+				})
+			}
+		}
+
+		switch msg.Role {
+		case types.ConversationRoleAssistant:
+			result = append(result, llmtypes.MessageContent{Role: llmtypes.ChatMessageTypeAI, Parts: parts})
+		default:
+			if len(toolResponses) > 0 {
+				result = append(result, llmtypes.MessageContent{Role: llmtypes.ChatMessageTypeTool, Parts: toolResponses})
+			}
+			if len(parts) > 0 {
+				result = append(result, llmtypes.MessageContent{Role: llmtypes.ChatMessageTypeHuman, Parts: parts})
+			}
+		}
+	}
+
+	return result, nil
+}
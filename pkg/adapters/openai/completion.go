@@ -0,0 +1,154 @@
+package openai
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+	"github.com/manishiitg/multi-llm-provider-go/pkg/utils"
+
+	"github.com/openai/openai-go/v3"
+	"github.com/openai/openai-go/v3/option"
+	"github.com/openai/openai-go/v3/packages/param"
+)
+
+// Complete implements the llmtypes.CompletionModel interface with the legacy /completions
+// endpoint (e.g. gpt-3.5-turbo-instruct, davinci-002 and OpenAI-compatible servers hosting
+// base models). Model, temperature, max tokens, N, logit bias, streaming, dry run and the
+// ExtraBody/ExtraHeaders escape hatches are supported; tools and structured output are not.
+func (o *OpenAIAdapter) Complete(ctx context.Context, prompt string, options ...llmtypes.CallOption) (*llmtypes.ContentResponse, error) {
+	opts := &llmtypes.CallOptions{}
+	for _, opt := range options {
+		opt(opts)
+	}
+	if len(opts.Tools) > 0 {
+		return nil, fmt.Errorf("tools are not supported by the completions endpoint")
+	}
+
+	modelID := o.modelID
+	if opts.Model != "" {
+		modelID = opts.Model
+	}
+
+	params := openai.CompletionNewParams{
+		Model:  openai.CompletionNewParamsModel(modelID),
+		Prompt: openai.CompletionNewParamsPromptUnion{OfString: param.NewOpt(prompt)},
+	}
+	if opts.Temperature > 0 {
+		params.Temperature = o.temperature(modelID, opts.Temperature)
+	}
+	if opts.MaxTokens > 0 {
+		params.MaxTokens = param.NewOpt(int64(opts.MaxTokens))
+	}
+	if opts.N > 1 {
+		params.N = param.NewOpt(int64(opts.N))
+	}
+	if len(opts.LogitBias) > 0 {
+		params.LogitBias = make(map[string]int64, len(opts.LogitBias))
+		for tokenID, bias := range opts.LogitBias {
+			params.LogitBias[strconv.Itoa(tokenID)] = int64(math.Round(math.Max(-100, math.Min(100, bias))))
+		}
+	}
+
+	if o.logger != nil {
+		o.logger.Debugf("OpenAI Complete INPUT - model: %s, prompt_chars: %d, stream: %v", modelID, len(prompt), opts.StreamChan != nil)
+	}
+
+	if opts.DryRun {
+		if opts.StreamChan != nil {
+			params.StreamOptions = openai.ChatCompletionStreamOptionsParam{IncludeUsage: param.NewOpt(true)}
+		}
+		return utils.DryRunResponse(opts, params), nil
+	}
+
+	var reqOpts []option.RequestOption
+	for key, value := range utils.RequestHeaders(opts) {
+		reqOpts = append(reqOpts, option.WithHeader(key, value))
+	}
+	for key, value := range utils.ExtraBodyFields(params, opts.ExtraBody) {
+		reqOpts = append(reqOpts, option.WithJSONSet(key, value))
+	}
+
+	if opts.StreamChan != nil {
+		params.StreamOptions = openai.ChatCompletionStreamOptionsParam{IncludeUsage: param.NewOpt(true)}
+		return o.completeStreaming(ctx, modelID, params, opts, reqOpts)
+	}
+
+	result, err := o.client.Completions.New(ctx, params, reqOpts...)
+	if err != nil {
+		if o.logger != nil {
+			o.logger.Errorf("OpenAI Complete ERROR - model: %s, error: %v", modelID, err)
+		}
+		return nil, fmt.Errorf("openai completion: %w", err)
+	}
+	return convertCompletion(result), nil
+}
+
+// completeStreaming streams a completion to opts.StreamChan, which is closed on return
+func (o *OpenAIAdapter) completeStreaming(ctx context.Context, modelID string, params openai.CompletionNewParams, opts *llmtypes.CallOptions, reqOpts []option.RequestOption) (*llmtypes.ContentResponse, error) {
+	defer close(opts.StreamChan)
+
+	stream := o.client.Completions.NewStreaming(ctx, params, reqOpts...)
+	defer stream.Close()
+
+	send := func(chunk llmtypes.StreamChunk) bool {
+		select {
+		case opts.StreamChan <- chunk:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	aggregator := llmtypes.NewStreamAggregator()
+	var usage *llmtypes.Usage
+	for stream.Next() {
+		event := stream.Current()
+		if opts.StreamEventHook != nil {
+			opts.StreamEventHook(event)
+		}
+		if event.Usage.TotalTokens > 0 {
+			usage = streamUsage(&event.Usage)
+			utils.SendUsageChunk(ctx, opts, usage)
+		}
+		for _, choice := range event.Choices {
+			if choice.Text != "" {
+				chunk := llmtypes.StreamChunk{Type: llmtypes.StreamChunkTypeContent, Content: choice.Text, ChoiceIndex: int(choice.Index)}
+				aggregator.Add(chunk)
+				if !send(chunk) {
+					return nil, ctx.Err()
+				}
+			}
+			if choice.FinishReason != "" {
+				aggregator.Add(llmtypes.StreamChunk{Type: llmtypes.StreamChunkTypeFinish, StopReason: string(choice.FinishReason), ChoiceIndex: int(choice.Index)})
+			}
+		}
+	}
+	if err := stream.Err(); err != nil {
+		if o.logger != nil {
+			o.logger.Errorf("OpenAI Complete streaming ERROR - model: %s, error: %v", modelID, err)
+		}
+		return nil, fmt.Errorf("openai completion stream: %w", err)
+	}
+
+	resp := aggregator.Response()
+	resp.Usage = usage
+	if !send(llmtypes.NewFinishChunk(resp)) {
+		return nil, ctx.Err()
+	}
+	return resp, nil
+}
+
+// convertCompletion converts a completions endpoint response to llmtypes format
+func convertCompletion(result *openai.Completion) *llmtypes.ContentResponse {
+	resp := &llmtypes.ContentResponse{Choices: make([]*llmtypes.ContentChoice, len(result.Choices))}
+	for i, choice := range result.Choices {
+		resp.Choices[i] = &llmtypes.ContentChoice{Content: choice.Text, StopReason: string(choice.FinishReason)}
+	}
+	if result.Usage.TotalTokens > 0 {
+		resp.Usage = streamUsage(&result.Usage)
+	}
+	return resp
+}
@@ -5,13 +5,20 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
+	"sort"
+	"strconv"
 	"strings"
+	"unicode"
 
 	"github.com/manishiitg/multi-llm-provider-go/interfaces"
 	"github.com/manishiitg/multi-llm-provider-go/internal/recorder"
 	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+	"github.com/manishiitg/multi-llm-provider-go/pkg/jsonschema"
+	"github.com/manishiitg/multi-llm-provider-go/pkg/utils"
 
 	"github.com/openai/openai-go/v3"
+	"github.com/openai/openai-go/v3/option"
 	"github.com/openai/openai-go/v3/packages/param"
 	"github.com/openai/openai-go/v3/shared"
 )
@@ -69,6 +76,40 @@ func (o *OpenAIAdapter) GenerateContent(ctx context.Context, messages []llmtypes
 		modelID = opts.Model
 	}
 
+	// Apply the assistant prefill (emulated, OpenAI has no assistant prefill)
+	if opts.AssistantPrefill != "" {
+		return utils.GenerateWithPrefill(ctx, o, messages, options, false)
+	}
+
+	// Stop at the first complete tool call when requested
+	if opts.AbortOnToolCall && opts.StreamChan != nil {
+		return utils.GenerateUntilToolCall(ctx, o, messages, options)
+	}
+
+	// Continue output cut off at the token limit when requested
+	if opts.AutoContinue > 0 {
+		return utils.GenerateWithAutoContinue(ctx, o, messages, options, false)
+	}
+
+	// Detect the media type of base64 images sent without one, converting unsupported formats
+	// when enabled
+	messages, err := utils.ResolveImageMediaTypes(messages, utils.OpenAIImageLimits, opts.ImageAutoConvert)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check tool result images against the provider limits, downscaling when allowed
+	messages, err = utils.PrepareToolResultImages(messages, utils.OpenAIImageLimits, opts.ToolResultImageMaxBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check the number and total size of images against the per-request limits
+	messages, err = utils.CheckImagePayload(messages, utils.OpenAIImageLimits, opts.MaxImageCount, opts.ImageDownsampling)
+	if err != nil {
+		return nil, err
+	}
+
 	// Convert messages from llmtypes format to OpenAI format
 	openaiMessages := convertMessages(messages, o.logger)
 
@@ -78,29 +119,37 @@ func (o *OpenAIAdapter) GenerateContent(ctx context.Context, messages []llmtypes
 		Messages: openaiMessages,
 	}
 
-	// Set temperature - some models (gpt-5, o1, o3, o4) only support default temperature (1.0)
-	// Check if model has temperature restrictions
-	if opts.Temperature > 0 && !hasTemperatureRestrictions(modelID) {
-		params.Temperature = param.NewOpt(opts.Temperature)
-	} else if opts.Temperature > 0 && hasTemperatureRestrictions(modelID) {
-		// Model has temperature restrictions - use default (1.0) or omit
-		// For models that only support default, we omit the parameter to let OpenAI use default
-		if o.logger != nil {
-			o.logger.Debugf("Model %s only supports default temperature (1.0), omitting temperature parameter", modelID)
-		}
-		// Don't set temperature - OpenAI will use default
+	// Set temperature - some models (gpt-5, o1, o3, o4) only support the default (1.0) and
+	// reject any other value, so it is omitted for them
+	if opts.Temperature > 0 {
+		params.Temperature = o.temperature(modelID, opts.Temperature)
 	}
 
-	// Note: max_tokens is omitted - OpenAI API will use model defaults
-	// Some newer models (o1, o3, o4, gpt-4.1) don't support max_tokens and require max_completion_tokens instead
-	// To avoid parameter compatibility issues, we omit it entirely
+	// Reasoning models reject max_tokens and take max_completion_tokens instead
+	if opts.MaxTokens > 0 {
+		if utils.UsesMaxCompletionTokens(modelID) {
+			params.MaxCompletionTokens = param.NewOpt(int64(opts.MaxTokens))
+		} else {
+			params.MaxTokens = param.NewOpt(int64(opts.MaxTokens))
+		}
+	}
 
 	// Handle JSON Schema structured outputs
 	if opts.JSONSchema != nil {
+		schema := opts.JSONSchema.Schema
+		if opts.JSONSchema.Strict {
+			var changes []string
+			schema, changes = jsonschema.Adapt(schema, strictSchemaCompat)
+			if o.logger != nil {
+				for _, change := range changes {
+					o.logger.Infof("🔧 [OPENAI] Strict schema %s: %s", opts.JSONSchema.Name, change)
+				}
+			}
+		}
 		schemaParam := openai.ResponseFormatJSONSchemaJSONSchemaParam{
 			Name:        opts.JSONSchema.Name,
 			Description: param.NewOpt(opts.JSONSchema.Description),
-			Schema:      opts.JSONSchema.Schema,
+			Schema:      schema,
 			Strict:      param.NewOpt(opts.JSONSchema.Strict),
 		}
 		params.ResponseFormat = openai.ChatCompletionNewParamsResponseFormatUnion{
@@ -146,6 +195,27 @@ func (o *OpenAIAdapter) GenerateContent(ctx context.Context, messages []llmtypes
 		params.Verbosity = verbosity
 	}
 
+	// Handle service tier (trades latency for cost)
+	if opts.ServiceTier != "" {
+		params.ServiceTier = openai.ChatCompletionNewParamsServiceTier(opts.ServiceTier)
+	}
+
+	// Handle logit bias (token ID -> bias, clamped to the API range of -100 to 100)
+	if len(opts.LogitBias) > 0 {
+		params.LogitBias = make(map[string]int64, len(opts.LogitBias))
+		for tokenID, bias := range opts.LogitBias {
+			params.LogitBias[strconv.Itoa(tokenID)] = int64(math.Round(math.Max(-100, math.Min(100, bias))))
+		}
+	}
+
+	// Handle n>1 choices; streamed choices are emulated with concurrent requests
+	if opts.N > 1 {
+		if opts.StreamChan != nil {
+			return utils.GenerateChoicesConcurrently(ctx, o, opts.N, messages, options)
+		}
+		params.N = param.NewOpt(int64(opts.N))
+	}
+
 	// Check if we're using OpenRouter and need to add usage parameter
 	isOpenRouter := strings.Contains(modelID, "/")
 	if isOpenRouter && opts.Metadata != nil && opts.Metadata.Usage != nil && opts.Metadata.Usage.Include {
@@ -160,14 +230,29 @@ func (o *OpenAIAdapter) GenerateContent(ctx context.Context, messages []llmtypes
 		// If not, we may need to use a custom HTTP client or modify the request
 	}
 
+	// Provider-native parameters are applied last and win over the generic options
+	if opts.OpenAIParams != nil {
+		applyOpenAIParams(&params, opts.OpenAIParams)
+	}
+
 	// Log input details if logger is available (for debugging errors)
 	if o.logger != nil {
 		o.logInputDetails(modelID, messages, params, opts)
 	}
 
-	// Check for recorder in context
+	// Return the constructed request without calling the API
+	if opts.DryRun {
+		if opts.StreamChan != nil {
+			params.StreamOptions = openai.ChatCompletionStreamOptionsParam{
+				IncludeUsage: param.NewOpt(true),
+			}
+		}
+		return utils.DryRunResponse(opts, params), nil
+	}
+
+	// Check for recorder in context (streaming calls replay recorded chunks in generateContentStreaming)
 	rec, _ := recorder.FromContext(ctx)
-	if rec != nil {
+	if rec != nil && opts.StreamChan == nil {
 		if rec.IsReplayEnabled() {
 			// Build request info for matching
 			requestInfo := buildRequestInfo(messages, modelID, opts)
@@ -211,7 +296,7 @@ func (o *OpenAIAdapter) GenerateContent(ctx context.Context, messages []llmtypes
 	}
 
 	// Call OpenAI API (non-streaming)
-	result, err := o.client.Chat.Completions.New(ctx, params)
+	result, err := o.client.Chat.Completions.New(ctx, params, requestOptions(params, opts)...)
 	if err != nil {
 		// Log error with input and response details
 		if o.logger != nil {
@@ -277,6 +362,7 @@ func (o *OpenAIAdapter) generateContentStreaming(ctx context.Context, modelID st
 		var usage *openai.CompletionUsage
 		toolCallMap := make(map[int64]*llmtypes.ToolCall)
 		completedToolCallIndices := make(map[int64]bool)
+		var toolCallIDs utils.ToolCallIDs
 
 		for _, chunkMap := range chunks {
 			// Convert chunk map back to OpenAI format for processing
@@ -327,6 +413,9 @@ func (o *OpenAIAdapter) generateContentStreaming(ctx context.Context, modelID st
 					CompletionTokens: int64(chunkData.Usage.CompletionTokens),
 					TotalTokens:      int64(chunkData.Usage.TotalTokens),
 				}
+				if err := utils.SendUsageChunk(ctx, opts, streamUsage(usage)); err != nil {
+					return nil, err
+				}
 			}
 
 			// Process each choice in the chunk
@@ -396,9 +485,10 @@ func (o *OpenAIAdapter) generateContentStreaming(ctx context.Context, modelID st
 					// When finish_reason is "tool_calls", all tool calls are complete
 					if choiceData.FinishReason == "tool_calls" {
 						// Mark all accumulated tool calls as complete and stream them
-						for index := range toolCallMap {
+						for _, index := range sortedToolCallIndices(toolCallMap) {
 							if !completedToolCallIndices[index] {
 								completedToolCallIndices[index] = true
+								toolCallIDs.Assign(toolCallMap[index])
 								// Stream complete tool call
 								if opts.StreamChan != nil {
 									toolCall := toolCallMap[index]
@@ -420,7 +510,11 @@ func (o *OpenAIAdapter) generateContentStreaming(ctx context.Context, modelID st
 		}
 
 		// Convert accumulated tool calls to slice
-		for index, toolCall := range toolCallMap {
+		for _, index := range sortedToolCallIndices(toolCallMap) {
+			toolCall := toolCallMap[index]
+			if !completedToolCallIndices[index] {
+				toolCallIDs.Assign(toolCall)
+			}
 			accumulatedToolCalls = append(accumulatedToolCalls, *toolCall)
 			// If tool call wasn't streamed yet and we have finish_reason, stream it now
 			if !completedToolCallIndices[index] && finishReason == "tool_calls" && opts.StreamChan != nil {
@@ -462,13 +556,24 @@ func (o *OpenAIAdapter) generateContentStreaming(ctx context.Context, modelID st
 
 		// Extract token usage from GenerationInfo
 		tokenUsage := llmtypes.ExtractUsageFromGenerationInfo(choice.GenerationInfo)
-		return &llmtypes.ContentResponse{
+		resp := &llmtypes.ContentResponse{
 			Choices: []*llmtypes.ContentChoice{choice},
 			Usage:   tokenUsage,
-		}, nil
+		}
+
+		// Send terminal chunk with stop reason and usage
+		if opts.StreamChan != nil {
+			select {
+			case opts.StreamChan <- llmtypes.NewFinishChunk(resp):
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+		}
+
+		return resp, nil
 	}
 	// Create streaming request
-	stream := o.client.Chat.Completions.NewStreaming(ctx, params)
+	stream := o.client.Chat.Completions.NewStreaming(ctx, params, requestOptions(params, opts)...)
 	defer stream.Close()
 
 	// Ensure channel is closed when done
@@ -483,16 +588,21 @@ func (o *OpenAIAdapter) generateContentStreaming(ctx context.Context, modelID st
 	var accumulatedToolCalls []llmtypes.ToolCall
 	var finishReason string
 	var streamModel string
+	var serviceTier string
 	var usage *openai.CompletionUsage
 
 	// Track tool calls by index (OpenAI streams tool calls incrementally)
 	// Track which tool calls are complete (ready to stream)
 	toolCallMap := make(map[int64]*llmtypes.ToolCall)
 	completedToolCallIndices := make(map[int64]bool)
+	var toolCallIDs utils.ToolCallIDs
 
 	// Process streaming chunks
 	for stream.Next() {
 		chunk := stream.Current()
+		if opts.StreamEventHook != nil {
+			opts.StreamEventHook(chunk)
+		}
 
 		// Record chunk if recording is enabled
 		if rec != nil && rec.IsRecordingEnabled() {
@@ -513,13 +623,34 @@ func (o *OpenAIAdapter) generateContentStreaming(ctx context.Context, modelID st
 			streamModel = chunk.Model
 		}
 
+		// Store service tier actually used
+		if chunk.ServiceTier != "" {
+			serviceTier = string(chunk.ServiceTier)
+		}
+
 		// Extract usage from chunk if available (only in last chunk when include_usage is true)
 		if chunk.Usage.PromptTokens > 0 || chunk.Usage.CompletionTokens > 0 {
 			usage = &chunk.Usage
+			if err := utils.SendUsageChunk(ctx, opts, streamUsage(usage)); err != nil {
+				return nil, err
+			}
 		}
 
 		// Process each choice in the chunk
 		for _, choice := range chunk.Choices {
+			// Reasoning text from OpenAI-compatible providers (e.g. OpenRouter, DeepSeek)
+			if reasoning := reasoningDelta(choice.Delta); reasoning != "" && opts.StreamChan != nil && utils.ReasoningVisible(opts) {
+				select {
+				case opts.StreamChan <- llmtypes.StreamChunk{
+					Type:        llmtypes.StreamChunkTypeReasoning,
+					Content:     reasoning,
+					ChoiceIndex: int(choice.Index),
+				}:
+				case <-ctx.Done():
+					return nil, ctx.Err()
+				}
+			}
+
 			// Extract text delta and accumulate
 			if choice.Delta.Content != "" {
 				deltaText := choice.Delta.Content
@@ -529,8 +660,9 @@ func (o *OpenAIAdapter) generateContentStreaming(ctx context.Context, modelID st
 				if opts.StreamChan != nil {
 					select {
 					case opts.StreamChan <- llmtypes.StreamChunk{
-						Type:    llmtypes.StreamChunkTypeContent,
-						Content: deltaText,
+						Type:        llmtypes.StreamChunkTypeContent,
+						Content:     deltaText,
+						ChoiceIndex: int(choice.Index),
 					}:
 					case <-ctx.Done():
 						return nil, ctx.Err()
@@ -585,9 +717,10 @@ func (o *OpenAIAdapter) generateContentStreaming(ctx context.Context, modelID st
 				// When finish_reason is "tool_calls", all tool calls are complete
 				if choice.FinishReason == "tool_calls" {
 					// Mark all accumulated tool calls as complete and stream them
-					for index := range toolCallMap {
+					for _, index := range sortedToolCallIndices(toolCallMap) {
 						if !completedToolCallIndices[index] {
 							completedToolCallIndices[index] = true
+							toolCallIDs.Assign(toolCallMap[index])
 							// Stream complete tool call
 							if opts.StreamChan != nil {
 								toolCall := toolCallMap[index]
@@ -619,7 +752,11 @@ func (o *OpenAIAdapter) generateContentStreaming(ctx context.Context, modelID st
 
 	// Convert accumulated tool calls to slice
 	// Also handle any remaining incomplete tool calls (shouldn't happen, but safety check)
-	for index, toolCall := range toolCallMap {
+	for _, index := range sortedToolCallIndices(toolCallMap) {
+		toolCall := toolCallMap[index]
+		if !completedToolCallIndices[index] {
+			toolCallIDs.Assign(toolCall)
+		}
 		accumulatedToolCalls = append(accumulatedToolCalls, *toolCall)
 		// If tool call wasn't streamed yet and we have finish_reason, stream it now
 		if !completedToolCallIndices[index] && finishReason == "tool_calls" && opts.StreamChan != nil {
@@ -715,45 +852,154 @@ func (o *OpenAIAdapter) generateContentStreaming(ctx context.Context, modelID st
 			reasoningTokens := int(usage.CompletionTokensDetails.ReasoningTokens)
 			choice.GenerationInfo.ReasoningTokens = &reasoningTokens
 		}
+
+		if isOpenRouter {
+			choice.GenerationInfo.Cost = openRouterCost(usage)
+		}
+	}
+
+	// Report the service tier actually used
+	if serviceTier != "" {
+		if choice.GenerationInfo == nil {
+			choice.GenerationInfo = &llmtypes.GenerationInfo{}
+		}
+		if choice.GenerationInfo.Additional == nil {
+			choice.GenerationInfo.Additional = make(map[string]interface{})
+		}
+		choice.GenerationInfo.Additional["service_tier"] = serviceTier
 	}
 
 	// Extract token usage from GenerationInfo
 	tokenUsage := llmtypes.ExtractUsageFromGenerationInfo(choice.GenerationInfo)
-	return &llmtypes.ContentResponse{
+	resp := &llmtypes.ContentResponse{
 		Choices: []*llmtypes.ContentChoice{choice},
 		Usage:   tokenUsage,
-	}, nil
+	}
+
+	// Send terminal chunk with stop reason and usage before the channel is closed
+	if opts.StreamChan != nil {
+		select {
+		case opts.StreamChan <- llmtypes.NewFinishChunk(resp):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	return resp, nil
 }
 
-// hasTemperatureRestrictions checks if a model only supports default temperature (1.0)
-// Models like gpt-5, gpt-5-mini, o1, o3, o4 only support the default temperature value
-func hasTemperatureRestrictions(modelID string) bool {
-	modelIDLower := strings.ToLower(modelID)
-	restrictedModels := []string{
-		"gpt-5",
-		"gpt-5-mini",
-		"o1",
-		"o1-mini",
-		"o1-preview",
-		"o3",
-		"o3-mini",
-		"o4",
-		"o4-mini",
+// openRouterCost returns the cost in USD that OpenRouter adds to the usage (usage accounting),
+// or nil when it is absent
+func openRouterCost(usage *openai.CompletionUsage) *float64 {
+	var parsed struct {
+		Cost *float64 `json:"cost"`
+	}
+	if raw := usage.RawJSON(); raw == "" || json.Unmarshal([]byte(raw), &parsed) != nil {
+		return nil
 	}
+	return parsed.Cost
+}
 
-	for _, restricted := range restrictedModels {
-		if strings.Contains(modelIDLower, restricted) {
-			return true
+// streamUsage converts the usage reported in a stream chunk
+func streamUsage(usage *openai.CompletionUsage) *llmtypes.Usage {
+	return &llmtypes.Usage{
+		InputTokens:  int(usage.PromptTokens),
+		OutputTokens: int(usage.CompletionTokens),
+		TotalTokens:  int(usage.TotalTokens),
+	}
+}
+
+// temperature returns the temperature parameter for modelID: omitted for models that only
+// support the default, clamped to the range OpenAI accepts otherwise
+func (o *OpenAIAdapter) temperature(modelID string, temperature float64) param.Opt[float64] {
+	if utils.DefaultTemperatureOnly(modelID) {
+		if o.logger != nil {
+			o.logger.Infof("🌡️  %s only supports the default temperature (1.0), omitting temperature %.2f", modelID, temperature)
+		}
+		return param.Opt[float64]{}
+	}
+	if clamped, changed := utils.ClampTemperature(temperature, utils.MaxTemperatureOpenAI); changed {
+		if o.logger != nil {
+			o.logger.Infof("🌡️  Temperature %.2f is above the maximum of %s, using %.1f", temperature, modelID, clamped)
 		}
+		temperature = clamped
+	}
+	return param.NewOpt(temperature)
+}
+
+// applyOpenAIParams sets the fields of p that are set (WithOpenAIParams) on params
+func applyOpenAIParams(params *openai.ChatCompletionNewParams, p *llmtypes.OpenAIParams) {
+	if p.TopP != nil {
+		params.TopP = param.NewOpt(*p.TopP)
+	}
+	if p.Seed != nil {
+		params.Seed = param.NewOpt(*p.Seed)
+	}
+	if p.PresencePenalty != nil {
+		params.PresencePenalty = param.NewOpt(*p.PresencePenalty)
+	}
+	if p.FrequencyPenalty != nil {
+		params.FrequencyPenalty = param.NewOpt(*p.FrequencyPenalty)
+	}
+	if len(p.Stop) > 0 {
+		params.Stop = openai.ChatCompletionNewParamsStopUnion{OfStringArray: p.Stop}
+	}
+	if p.Logprobs != nil {
+		params.Logprobs = param.NewOpt(*p.Logprobs)
+	}
+	if p.TopLogprobs != nil {
+		params.TopLogprobs = param.NewOpt(*p.TopLogprobs)
+	}
+	if p.ParallelToolCalls != nil {
+		params.ParallelToolCalls = param.NewOpt(*p.ParallelToolCalls)
+	}
+	if p.Store != nil {
+		params.Store = param.NewOpt(*p.Store)
+	}
+	if len(p.Metadata) > 0 {
+		params.Metadata = shared.Metadata(p.Metadata)
+	}
+	if p.User != "" {
+		params.User = param.NewOpt(p.User)
+	}
+	if p.PromptCacheKey != "" {
+		params.PromptCacheKey = param.NewOpt(p.PromptCacheKey)
+	}
+	if p.SafetyIdentifier != "" {
+		params.SafetyIdentifier = param.NewOpt(p.SafetyIdentifier)
+	}
+}
+
+// requestOptions builds per-request options for ExtraBody and ExtraHeaders.
+// Fields already set on params win on conflict. SDK retries are turned off when the
+// caller retries (CallOptions.MaxRetries). A trace ID is also sent as X-Client-Request-Id,
+// which OpenAI logs for support requests.
+func requestOptions(params openai.ChatCompletionNewParams, opts *llmtypes.CallOptions) []option.RequestOption {
+	var reqOpts []option.RequestOption
+	if opts.MaxRetries != nil {
+		reqOpts = append(reqOpts, option.WithMaxRetries(0))
+	}
+	if opts.TraceID != "" {
+		reqOpts = append(reqOpts, option.WithHeader("X-Client-Request-Id", opts.TraceID))
+	}
+	for key, value := range utils.RequestHeaders(opts) {
+		reqOpts = append(reqOpts, option.WithHeader(key, value))
+	}
+	for key, value := range utils.ExtraBodyFields(params, opts.ExtraBody) {
+		reqOpts = append(reqOpts, option.WithJSONSet(key, value))
 	}
-	return false
+	return reqOpts
 }
 
 // convertMessages converts llmtypes messages to OpenAI message format
 func convertMessages(langMessages []llmtypes.MessageContent, logger interfaces.Logger) []openai.ChatCompletionMessageParamUnion {
+	// Each tool result must be its own tool message, right after the calls and in their order
+	langMessages = orderToolResults(langMessages)
 	openaiMessages := make([]openai.ChatCompletionMessageParamUnion, 0, len(langMessages))
 
 	for _, msg := range langMessages {
+		start := len(openaiMessages)
+
 		// Extract content parts
 		var contentParts []string
 		var imageParts []llmtypes.ImageContent
@@ -874,6 +1120,8 @@ func convertMessages(langMessages []llmtypes.MessageContent, logger interfaces.L
 			// Tool message - handle tool responses
 			// A single message can contain multiple tool responses, each needs to be a separate tool message
 			if len(toolResponses) > 0 {
+				// Tool messages only accept text, so images from tool results follow in a user message
+				var toolImageParts []openai.ChatCompletionContentPartUnionParam
 				for _, toolResp := range toolResponses {
 					if toolResp.ToolCallID == "" {
 						// Skip tool responses without a tool call ID (invalid)
@@ -884,11 +1132,28 @@ func convertMessages(langMessages []llmtypes.MessageContent, logger interfaces.L
 					}
 					// Use raw content directly (can be JSON string or plain text)
 					// OpenAI allows empty content for tool responses
-					openaiMessages = append(openaiMessages, openai.ToolMessage(toolResp.Content, toolResp.ToolCallID))
+					toolContent := toolResp.Text()
+					if toolResp.IsError {
+						// OpenAI has no error flag on tool messages, so mark failures in the content
+						toolContent = "Error: " + toolContent
+					}
+					openaiMessages = append(openaiMessages, openai.ToolMessage(toolContent, toolResp.ToolCallID))
 					if logger != nil {
-						logger.Debugf("✅ Added tool message - ToolCallID: %s, Name: %s, Content length: %d", toolResp.ToolCallID, toolResp.Name, len(toolResp.Content))
+						logger.Debugf("✅ Added tool message - ToolCallID: %s, Name: %s, Content length: %d", toolResp.ToolCallID, toolResp.Name, len(toolContent))
+					}
+
+					if images := toolResp.Images(); len(images) > 0 {
+						toolImageParts = append(toolImageParts, openai.TextContentPart(fmt.Sprintf("Images returned by tool %s (call %s):", toolResp.Name, toolResp.ToolCallID)))
+						for _, img := range images {
+							if imagePart := createImageContentPart(img); imagePart != nil {
+								toolImageParts = append(toolImageParts, *imagePart)
+							}
+						}
 					}
 				}
+				if len(toolImageParts) > 0 {
+					openaiMessages = append(openaiMessages, openai.UserMessage(toolImageParts))
+				}
 			} else {
 				// No tool responses found in a tool message - this is unusual
 				if logger != nil {
@@ -933,11 +1198,38 @@ func convertMessages(langMessages []llmtypes.MessageContent, logger interfaces.L
 				openaiMessages = append(openaiMessages, openai.UserMessage(content))
 			}
 		}
+
+		if msg.Name != "" {
+			for i := start; i < len(openaiMessages); i++ {
+				setMessageName(&openaiMessages[i], msg.Name)
+			}
+		}
 	}
 
 	return openaiMessages
 }
 
+// setMessageName sets the participant name on system, user and assistant messages.
+// OpenAI rejects names containing whitespace or any of <|\/>, so those are replaced.
+func setMessageName(msg *openai.ChatCompletionMessageParamUnion, name string) {
+	name = strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) || strings.ContainsRune("<|\\/>", r) {
+			return '_'
+		}
+		return r
+	}, name)
+	switch {
+	case msg.OfSystem != nil:
+		msg.OfSystem.Name = param.NewOpt(name)
+	case msg.OfDeveloper != nil:
+		msg.OfDeveloper.Name = param.NewOpt(name)
+	case msg.OfUser != nil:
+		msg.OfUser.Name = param.NewOpt(name)
+	case msg.OfAssistant != nil:
+		msg.OfAssistant.Name = param.NewOpt(name)
+	}
+}
+
 // createImageContentPart creates an OpenAI image content part from ImageContent
 func createImageContentPart(img llmtypes.ImageContent) *openai.ChatCompletionContentPartUnionParam {
 	if img.SourceType == "base64" {
@@ -960,6 +1252,10 @@ func createImageContentPart(img llmtypes.ImageContent) *openai.ChatCompletionCon
 	return nil
 }
 
+// strictSchemaCompat drops the keywords OpenAI rejects in strict JSON schemas. $defs and
+// $ref are supported, including recursion.
+var strictSchemaCompat = jsonschema.Compat{Unsupported: []string{"patternProperties"}}
+
 // convertTools converts llmtypes tools to OpenAI tools format
 func convertTools(llmTools []llmtypes.Tool) []openai.ChatCompletionToolUnionParam {
 	openaiTools := make([]openai.ChatCompletionToolUnionParam, 0, len(llmTools))
@@ -1073,9 +1369,20 @@ func convertToolChoice(toolChoice interface{}) *openai.ChatCompletionToolChoiceO
 
 	// Handle ToolChoice struct if it's that type
 	if tc, ok := toolChoice.(*llmtypes.ToolChoice); ok && tc != nil {
-		// For now, default to auto - could be enhanced to handle function-specific choices
+		if tc.Function != nil && tc.Function.Name != "" {
+			result := openai.ToolChoiceOptionFunctionToolChoice(openai.ChatCompletionNamedToolChoiceFunctionParam{
+				Name: tc.Function.Name,
+			})
+			return &result
+		}
+		mode := "auto"
+		if tc.None || tc.Type == "none" {
+			mode = "none"
+		} else if tc.Any || tc.Type == "required" || tc.Type == "any" {
+			mode = "required"
+		}
 		result := openai.ChatCompletionToolChoiceOptionUnionParam{
-			OfAuto: param.NewOpt("auto"),
+			OfAuto: param.NewOpt(mode),
 		}
 		return &result
 	}
@@ -1197,6 +1504,8 @@ func convertResponse(result *openai.ChatCompletion, logger interfaces.Logger, is
 
 				toolCalls = append(toolCalls, langToolCall)
 			}
+			// OpenAI-compatible servers (e.g. local models) may omit tool call IDs
+			utils.EnsureToolCallIDs(toolCalls)
 			langChoice.ToolCalls = toolCalls
 		}
 
@@ -1227,6 +1536,11 @@ func convertResponse(result *openai.ChatCompletion, logger interfaces.Logger, is
 			langChoice.GenerationInfo.Additional = make(map[string]interface{})
 		}
 
+		// Report the service tier actually used
+		if result.ServiceTier != "" {
+			langChoice.GenerationInfo.Additional["service_tier"] = string(result.ServiceTier)
+		}
+
 		// Handle reasoning tokens for o3 models (if available)
 		// CompletionTokensDetails is not a pointer
 		if result.Usage.CompletionTokensDetails.ReasoningTokens > 0 {
@@ -1234,6 +1548,10 @@ func convertResponse(result *openai.ChatCompletion, logger interfaces.Logger, is
 			langChoice.GenerationInfo.ReasoningTokens = &reasoningTokens
 		}
 
+		if isOpenRouter {
+			langChoice.GenerationInfo.Cost = openRouterCost(&result.Usage)
+		}
+
 		// Extract cache tokens if available (for both native OpenAI and OpenRouter)
 		if cachedTokens > 0 {
 			// Set cached tokens in GenerationInfo
@@ -1486,7 +1804,7 @@ func (o *OpenAIAdapter) logInputDetails(modelID string, messages []llmtypes.Mess
 			if textPart, ok := msg.Parts[0].(llmtypes.TextContent); ok {
 				content := textPart.Text
 				if len(content) > 200 {
-					contentPreview = content[:200] + "..."
+					contentPreview = utils.TruncateUTF8(content, 200) + "..."
 				} else {
 					contentPreview = content
 				}
@@ -1635,7 +1953,7 @@ func (o *OpenAIAdapter) logErrorDetails(modelID string, messages []llmtypes.Mess
 			if choice.Message.Content != "" {
 				content := choice.Message.Content
 				if len(content) > 500 {
-					content = content[:500] + "..."
+					content = utils.TruncateUTF8(content, 500) + "..."
 				}
 				responseInfo["content_preview"] = content
 				responseInfo["content_length"] = len(choice.Message.Content)
@@ -1739,3 +2057,30 @@ func buildRequestInfo(messages []llmtypes.MessageContent, modelID string, opts *
 		Options:  optionsInfo,
 	}
 }
+
+// reasoningDelta returns reasoning text from a streamed delta. OpenAI does not stream reasoning
+// on chat completions, but compatible providers send it as "reasoning" or "reasoning_content".
+func reasoningDelta(delta openai.ChatCompletionChunkChoiceDelta) string {
+	for _, key := range []string{"reasoning", "reasoning_content"} {
+		field, ok := delta.JSON.ExtraFields[key]
+		if !ok {
+			continue
+		}
+		var text string
+		if err := json.Unmarshal([]byte(field.Raw()), &text); err == nil && text != "" {
+			return text
+		}
+	}
+	return ""
+}
+
+// sortedToolCallIndices returns the indices of streamed tool calls in order, so parallel
+// calls keep the order the model sent them in
+func sortedToolCallIndices(toolCallMap map[int64]*llmtypes.ToolCall) []int64 {
+	indices := make([]int64, 0, len(toolCallMap))
+	for index := range toolCallMap {
+		indices = append(indices, index)
+	}
+	sort.Slice(indices, func(i, j int) bool { return indices[i] < indices[j] })
+	return indices
+}
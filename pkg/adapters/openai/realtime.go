@@ -0,0 +1,237 @@
+package openai
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"github.com/manishiitg/multi-llm-provider-go/interfaces"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+// RealtimeURL is the OpenAI Realtime API WebSocket endpoint
+const RealtimeURL = "wss://api.openai.com/v1/realtime"
+
+// realtimeAudioMediaType is the audio format of the Realtime API (16-bit PCM, 24kHz, mono)
+const realtimeAudioMediaType = "audio/pcm;rate=24000"
+
+// RealtimeSession is a llmtypes.RealtimeSession over the OpenAI Realtime API
+type RealtimeSession struct {
+	conn    *websocket.Conn
+	events  chan llmtypes.RealtimeEvent
+	logger  interfaces.Logger
+	writeMu sync.Mutex
+	once    sync.Once
+	done    chan struct{}
+}
+
+// realtimeEvent is the subset of OpenAI Realtime server events that is mapped to
+// llmtypes.RealtimeEvent
+type realtimeEvent struct {
+	Type       string `json:"type"`
+	Delta      string `json:"delta"`
+	Transcript string `json:"transcript"`
+	Error      *struct {
+		Type    string `json:"type"`
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+	Response *struct {
+		Usage *struct {
+			InputTokens  int `json:"input_tokens"`
+			OutputTokens int `json:"output_tokens"`
+			TotalTokens  int `json:"total_tokens"`
+		} `json:"usage"`
+	} `json:"response"`
+}
+
+// NewRealtimeSession connects to the OpenAI Realtime API with modelID (e.g.
+// "gpt-4o-realtime-preview") and configures the session from opts
+func NewRealtimeSession(ctx context.Context, apiKey, modelID string, logger interfaces.Logger, opts ...llmtypes.RealtimeOption) (*RealtimeSession, error) {
+	options := &llmtypes.RealtimeOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	header := http.Header{}
+	header.Set("Authorization", "Bearer "+apiKey)
+	header.Set("OpenAI-Beta", "realtime=v1")
+	endpoint := RealtimeURL + "?model=" + url.QueryEscape(modelID)
+	conn, resp, err := websocket.DefaultDialer.DialContext(ctx, endpoint, header)
+	if err != nil {
+		if resp != nil {
+			return nil, fmt.Errorf("openai realtime connect failed (HTTP %d): %w", resp.StatusCode, err)
+		}
+		return nil, fmt.Errorf("openai realtime connect failed: %w", err)
+	}
+
+	s := &RealtimeSession{
+		conn:   conn,
+		events: make(chan llmtypes.RealtimeEvent, 64),
+		logger: logger,
+		done:   make(chan struct{}),
+	}
+	if err := s.send(ctx, map[string]interface{}{"type": "session.update", "session": realtimeSessionConfig(options)}); err != nil {
+		conn.Close()
+		return nil, err
+	}
+	go s.readLoop()
+	return s, nil
+}
+
+// realtimeSessionConfig builds the session.update payload from options
+func realtimeSessionConfig(options *llmtypes.RealtimeOptions) map[string]interface{} {
+	modalities := options.Modalities
+	if len(modalities) == 0 {
+		modalities = []string{"text"}
+	}
+	session := map[string]interface{}{
+		"modalities":          modalities,
+		"input_audio_format":  "pcm16",
+		"output_audio_format": "pcm16",
+	}
+	if options.Instructions != "" {
+		session["instructions"] = options.Instructions
+	}
+	if options.Voice != "" {
+		session["voice"] = options.Voice
+	}
+	if options.InputTranscription {
+		session["input_audio_transcription"] = map[string]interface{}{"model": "whisper-1"}
+	}
+	return session
+}
+
+// SendText adds a user message to the conversation and requests a response
+func (s *RealtimeSession) SendText(ctx context.Context, text string) error {
+	item := map[string]interface{}{
+		"type": "conversation.item.create",
+		"item": map[string]interface{}{
+			"type":    "message",
+			"role":    "user",
+			"content": []map[string]interface{}{{"type": "input_text", "text": text}},
+		},
+	}
+	if err := s.send(ctx, item); err != nil {
+		return err
+	}
+	return s.send(ctx, map[string]interface{}{"type": "response.create"})
+}
+
+// SendAudio appends base64 16-bit PCM (24kHz, mono) audio to the input buffer. The server
+// detects the end of speech and responds.
+func (s *RealtimeSession) SendAudio(ctx context.Context, audio llmtypes.AudioContent) error {
+	return s.send(ctx, map[string]interface{}{"type": "input_audio_buffer.append", "audio": audio.Data})
+}
+
+// Events returns the session events; the channel is closed when the connection ends
+func (s *RealtimeSession) Events() <-chan llmtypes.RealtimeEvent {
+	return s.events
+}
+
+// Close closes the connection
+func (s *RealtimeSession) Close() error {
+	var err error
+	s.once.Do(func() {
+		close(s.done)
+		s.writeMu.Lock()
+		_ = s.conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
+		s.writeMu.Unlock()
+		err = s.conn.Close()
+	})
+	return err
+}
+
+// send writes a client event as JSON
+func (s *RealtimeSession) send(ctx context.Context, event interface{}) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = s.conn.SetWriteDeadline(deadline)
+		defer func() { _ = s.conn.SetWriteDeadline(time.Time{}) }()
+	}
+	if err := s.conn.WriteJSON(event); err != nil {
+		return fmt.Errorf("openai realtime send failed: %w", err)
+	}
+	return nil
+}
+
+// readLoop maps server events to RealtimeEvents until the connection ends
+func (s *RealtimeSession) readLoop() {
+	defer close(s.events)
+	for {
+		_, data, err := s.conn.ReadMessage()
+		if err != nil {
+			select {
+			case <-s.done:
+			default:
+				if !websocket.IsCloseError(err, websocket.CloseNormalClosure) {
+					s.emit(llmtypes.RealtimeEvent{Type: llmtypes.RealtimeEventError, Text: err.Error(), Err: err})
+				}
+			}
+			return
+		}
+
+		var event realtimeEvent
+		if err := json.Unmarshal(data, &event); err != nil {
+			s.logger.Debugf("OpenAI realtime: ignoring malformed event: %v", err)
+			continue
+		}
+		if mapped, ok := mapRealtimeEvent(event); ok && !s.emit(mapped) {
+			return
+		}
+	}
+}
+
+// emit delivers an event unless the session was closed
+func (s *RealtimeSession) emit(event llmtypes.RealtimeEvent) bool {
+	select {
+	case s.events <- event:
+		return true
+	case <-s.done:
+		return false
+	}
+}
+
+// mapRealtimeEvent converts an OpenAI server event; events without a counterpart are dropped
+func mapRealtimeEvent(event realtimeEvent) (llmtypes.RealtimeEvent, bool) {
+	switch event.Type {
+	case "session.created":
+		return llmtypes.RealtimeEvent{Type: llmtypes.RealtimeEventSessionStarted}, true
+	case "response.text.delta", "response.output_text.delta":
+		return llmtypes.RealtimeEvent{Type: llmtypes.RealtimeEventTextDelta, Text: event.Delta}, true
+	case "response.audio.delta", "response.output_audio.delta":
+		return llmtypes.RealtimeEvent{
+			Type:  llmtypes.RealtimeEventAudioDelta,
+			Audio: &llmtypes.AudioContent{MediaType: realtimeAudioMediaType, Data: event.Delta},
+		}, true
+	case "response.audio_transcript.delta", "response.output_audio_transcript.delta":
+		return llmtypes.RealtimeEvent{Type: llmtypes.RealtimeEventTranscriptDelta, Text: event.Delta}, true
+	case "conversation.item.input_audio_transcription.completed":
+		return llmtypes.RealtimeEvent{Type: llmtypes.RealtimeEventInputTranscript, Text: event.Transcript}, true
+	case "input_audio_buffer.speech_started":
+		return llmtypes.RealtimeEvent{Type: llmtypes.RealtimeEventInterrupted}, true
+	case "response.done":
+		done := llmtypes.RealtimeEvent{Type: llmtypes.RealtimeEventTurnComplete}
+		if event.Response != nil && event.Response.Usage != nil {
+			u := event.Response.Usage
+			done.Usage = &llmtypes.Usage{InputTokens: u.InputTokens, OutputTokens: u.OutputTokens, TotalTokens: u.TotalTokens}
+		}
+		return done, true
+	case "error":
+		message := "unknown error"
+		if event.Error != nil {
+			message = event.Error.Message
+		}
+		return llmtypes.RealtimeEvent{Type: llmtypes.RealtimeEventError, Text: message, Err: fmt.Errorf("openai realtime error: %s", message)}, true
+	}
+	return llmtypes.RealtimeEvent{}, false
+}
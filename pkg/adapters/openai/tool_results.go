@@ -0,0 +1,79 @@
+package openai
+
+import (
+	"sort"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+// orderToolResults reshapes the tool results of messages into what OpenAI requires: one tool
+// message per tool call, directly after the assistant message that made the calls. Results
+// are gathered from every message following that assistant message, whatever its role (the
+// Bedrock shape puts them in a user message), and ordered like the calls. Other parts of those
+// messages follow the results in a user message. The input is not modified.
+func orderToolResults(messages []llmtypes.MessageContent) []llmtypes.MessageContent {
+	result := make([]llmtypes.MessageContent, 0, len(messages))
+	var callOrder map[string]int
+	for i := 0; i < len(messages); i++ {
+		msg := messages[i]
+		if msg.Role == llmtypes.ChatMessageTypeAI {
+			callOrder = make(map[string]int)
+			for _, part := range msg.Parts {
+				if call, ok := part.(llmtypes.ToolCall); ok {
+					callOrder[call.ID] = len(callOrder)
+				}
+			}
+			result = append(result, msg)
+			continue
+		}
+
+		responses, others := splitToolResults(msg)
+		if len(responses) == 0 {
+			result = append(result, msg)
+			continue
+		}
+		// Gather the results of the following messages too, so no other message ends up
+		// between the tool messages of one turn
+		for i+1 < len(messages) && messages[i+1].Role != llmtypes.ChatMessageTypeAI {
+			more, moreOthers := splitToolResults(messages[i+1])
+			if len(more) == 0 {
+				break
+			}
+			responses = append(responses, more...)
+			others = append(others, moreOthers...)
+			i++
+		}
+
+		// Results for calls of the assistant message go in the order of the calls, others after them
+		position := func(part llmtypes.ContentPart) int {
+			if index, ok := callOrder[part.(llmtypes.ToolCallResponse).ToolCallID]; ok {
+				return index
+			}
+			return len(callOrder)
+		}
+		sort.SliceStable(responses, func(a, b int) bool {
+			return position(responses[a]) < position(responses[b])
+		})
+		result = append(result, llmtypes.MessageContent{Role: llmtypes.ChatMessageTypeTool, Parts: responses})
+		if len(others) > 0 {
+			result = append(result, llmtypes.MessageContent{Role: llmtypes.ChatMessageTypeHuman, Parts: others, Name: msg.Name})
+		}
+	}
+	return result
+}
+
+// splitToolResults separates the tool results of msg from its other parts. The other parts of
+// tool messages are dropped, as tool messages only carry their results.
+func splitToolResults(msg llmtypes.MessageContent) (responses, others []llmtypes.ContentPart) {
+	for _, part := range msg.Parts {
+		switch part.(type) {
+		case llmtypes.ToolCallResponse:
+			responses = append(responses, part)
+		default:
+			if msg.Role != llmtypes.ChatMessageTypeTool {
+				others = append(others, part)
+			}
+		}
+	}
+	return responses, others
+}
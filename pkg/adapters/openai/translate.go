@@ -0,0 +1,134 @@
+package openai
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+
+	"github.com/openai/openai-go/v3"
+	"github.com/openai/openai-go/v3/packages/param"
+)
+
+// ToOpenAIMessages converts llmtypes messages to the OpenAI Chat Completions format.
+// This is the exact conversion used by OpenAIAdapter.GenerateContent.
+func ToOpenAIMessages(messages []llmtypes.MessageContent) []openai.ChatCompletionMessageParamUnion {
+	return convertMessages(messages, nil)
+}
+
+// FromOpenAIMessages converts OpenAI Chat Completions messages back to llmtypes messages.
+// Tool messages are mapped to Tool messages with a single ToolCallResponse part.
+func FromOpenAIMessages(messages []openai.ChatCompletionMessageParamUnion) ([]llmtypes.MessageContent, error) {
+	result := make([]llmtypes.MessageContent, 0, len(messages))
+
+	// Tool names are only carried on assistant tool calls, remember them for tool messages
+	toolNames := make(map[string]string)
+
+	for i, msg := range messages {
+		switch {
+		case msg.OfSystem != nil:
+			text := msg.OfSystem.Content.OfString.Value
+			if len(msg.OfSystem.Content.OfArrayOfContentParts) > 0 {
+				text = joinTextParts(msg.OfSystem.Content.OfArrayOfContentParts)
+			}
+			result = append(result, named(llmtypes.TextPart(llmtypes.ChatMessageTypeSystem, text), msg.OfSystem.Name))
+		case msg.OfDeveloper != nil:
+			text := msg.OfDeveloper.Content.OfString.Value
+			if len(msg.OfDeveloper.Content.OfArrayOfContentParts) > 0 {
+				text = joinTextParts(msg.OfDeveloper.Content.OfArrayOfContentParts)
+			}
+			result = append(result, named(llmtypes.TextPart(llmtypes.ChatMessageTypeSystem, text), msg.OfDeveloper.Name))
+		case msg.OfUser != nil:
+			content := msg.OfUser.Content
+			if len(content.OfArrayOfContentParts) == 0 {
+				result = append(result, named(llmtypes.TextPart(llmtypes.ChatMessageTypeHuman, content.OfString.Value), msg.OfUser.Name))
+				continue
+			}
+			parts := make([]llmtypes.ContentPart, 0, len(content.OfArrayOfContentParts))
+			for _, part := range content.OfArrayOfContentParts {
+				switch {
+				case part.OfText != nil:
+					parts = append(parts, llmtypes.TextContent{Text: part.OfText.Text})
+				case part.OfImageURL != nil:
+					parts = append(parts, imageContentFromURL(part.OfImageURL.ImageURL.URL))
+				default:
+					return nil, fmt.Errorf("message %d: unsupported user content part", i)
+				}
+			}
+			result = append(result, named(llmtypes.MessageContent{Role: llmtypes.ChatMessageTypeHuman, Parts: parts}, msg.OfUser.Name))
+		case msg.OfAssistant != nil:
+			var parts []llmtypes.ContentPart
+			if text := msg.OfAssistant.Content.OfString.Value; text != "" {
+				parts = append(parts, llmtypes.TextContent{Text: text})
+			}
+			for _, part := range msg.OfAssistant.Content.OfArrayOfContentParts {
+				if part.OfText != nil {
+					parts = append(parts, llmtypes.TextContent{Text: part.OfText.Text})
+				}
+			}
+			for _, tc := range msg.OfAssistant.ToolCalls {
+				if tc.OfFunction == nil {
+					continue
+				}
+				toolNames[tc.OfFunction.ID] = tc.OfFunction.Function.Name
+				parts = append(parts, llmtypes.ToolCall{
+					ID:   tc.OfFunction.ID,
+					Type: "function",
+					FunctionCall: &llmtypes.FunctionCall{
+						Name:      tc.OfFunction.Function.Name,
+						Arguments: tc.OfFunction.Function.Arguments,
+					},
+				})
+			}
+			result = append(result, named(llmtypes.MessageContent{Role: llmtypes.ChatMessageTypeAI, Parts: parts}, msg.OfAssistant.Name))
+		case msg.OfTool != nil:
+			content := msg.OfTool.Content.OfString.Value
+			if len(msg.OfTool.Content.OfArrayOfContentParts) > 0 {
+				content = joinTextParts(msg.OfTool.Content.OfArrayOfContentParts)
+			}
+			result = append(result, llmtypes.MessageContent{
+				Role: llmtypes.ChatMessageTypeTool,
+				Parts: []llmtypes.ContentPart{llmtypes.ToolCallResponse{
+					ToolCallID: msg.OfTool.ToolCallID,
+					Name:       toolNames[msg.OfTool.ToolCallID],
+					Content:    content,
+				}},
+			})
+		default:
+			return nil, fmt.Errorf("message %d: unsupported message type", i)
+		}
+	}
+
+	return result, nil
+}
+
+// named sets the participant name of msg from an OpenAI message name
+func named(msg llmtypes.MessageContent, name param.Opt[string]) llmtypes.MessageContent {
+	msg.Name = name.Value
+	return msg
+}
+
+// joinTextParts joins text content parts with newlines
+func joinTextParts(parts []openai.ChatCompletionContentPartTextParam) string {
+	texts := make([]string, 0, len(parts))
+	for _, part := range parts {
+		texts = append(texts, part.Text)
+	}
+	return strings.Join(texts, "\n")
+}
+
+// imageContentFromURL converts an OpenAI image URL (http(s) or data: URL) to ImageContent.
+// This is the inverse of createImageContentPart.
+func imageContentFromURL(url string) llmtypes.ImageContent {
+	if strings.HasPrefix(url, "data:") {
+		header, data, found := strings.Cut(strings.TrimPrefix(url, "data:"), ",")
+		if found {
+			return llmtypes.ImageContent{
+				SourceType: "base64",
+				MediaType:  strings.TrimSuffix(header, ";base64"),
+				Data:       data,
+			}
+		}
+	}
+	return llmtypes.ImageContent{SourceType: "url", Data: url}
+}
@@ -0,0 +1,74 @@
+package vertex
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+	"github.com/manishiitg/multi-llm-provider-go/pkg/utils"
+	"google.golang.org/genai"
+)
+
+// CreateCachedContent implements llmtypes.ContextCacheModel with Gemini explicit caching.
+// System messages become the cache's system instruction; WithModel, WithTools and
+// WithToolChoice in options apply to the cache.
+func (g *GoogleGenAIAdapter) CreateCachedContent(ctx context.Context, messages []llmtypes.MessageContent, ttl time.Duration, options ...llmtypes.CallOption) (llmtypes.CacheName, error) {
+	opts := &llmtypes.CallOptions{}
+	for _, opt := range options {
+		opt(opts)
+	}
+	modelID := g.modelID
+	if opts.Model != "" {
+		modelID = opts.Model
+	}
+
+	config := &genai.CreateCachedContentConfig{TTL: ttl}
+	var conversation []llmtypes.MessageContent
+	for _, msg := range messages {
+		if msg.Role != llmtypes.ChatMessageTypeSystem {
+			conversation = append(conversation, msg)
+			continue
+		}
+		if config.SystemInstruction == nil {
+			config.SystemInstruction = &genai.Content{}
+		}
+		for _, part := range msg.Parts {
+			if text, ok := part.(llmtypes.TextContent); ok {
+				config.SystemInstruction.Parts = append(config.SystemInstruction.Parts, genai.NewPartFromText(text.Text))
+			}
+		}
+	}
+	conversation, err := utils.ResolveImageMediaTypes(conversation, utils.GeminiImageLimits, opts.ImageAutoConvert)
+	if err != nil {
+		return "", err
+	}
+	config.Contents, _ = g.convertMessages(conversation, modelID)
+	if len(opts.Tools) > 0 {
+		config.Tools = convertTools(opts.Tools, g.logger)
+		if opts.ToolChoice != nil {
+			config.ToolConfig = convertToolChoice(opts.ToolChoice)
+		}
+	}
+
+	cache, err := g.client.Caches.Create(ctx, modelID, config)
+	if err != nil {
+		return "", fmt.Errorf("create cached content: %w", err)
+	}
+	if g.logger != nil {
+		tokens := int32(0)
+		if cache.UsageMetadata != nil {
+			tokens = cache.UsageMetadata.TotalTokenCount
+		}
+		g.logger.Infof("🗄️ [GEMINI] Created cached content %s (%d tokens, expires %s)", cache.Name, tokens, cache.ExpireTime.Format(time.RFC3339))
+	}
+	return llmtypes.CacheName(cache.Name), nil
+}
+
+// DeleteCachedContent implements llmtypes.ContextCacheModel
+func (g *GoogleGenAIAdapter) DeleteCachedContent(ctx context.Context, name llmtypes.CacheName) error {
+	if _, err := g.client.Caches.Delete(ctx, string(name), nil); err != nil {
+		return fmt.Errorf("delete cached content %s: %w", name, err)
+	}
+	return nil
+}
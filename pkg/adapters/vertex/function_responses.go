@@ -0,0 +1,121 @@
+package vertex
+
+import (
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+	"google.golang.org/genai"
+)
+
+// combineFunctionResponses gathers the tool results that follow a model turn with function
+// calls into a single tool message, as Gemini requires all function responses of a turn in
+// one user content. Results are taken from the consecutive messages after the calls whatever
+// their role (the Bedrock shape puts them in a user message); other parts of those messages
+// follow the results in a user message. The input is not modified.
+func combineFunctionResponses(messages []llmtypes.MessageContent) []llmtypes.MessageContent {
+	result := make([]llmtypes.MessageContent, 0, len(messages))
+	for i := 0; i < len(messages); i++ {
+		msg := messages[i]
+		if i == 0 || !hasToolCalls(messages[i-1]) || !hasToolResponses(msg) {
+			result = append(result, msg)
+			continue
+		}
+
+		var responses, others []llmtypes.ContentPart
+		for ; i < len(messages) && messages[i].Role != llmtypes.ChatMessageTypeAI && hasToolResponses(messages[i]); i++ {
+			for _, part := range messages[i].Parts {
+				switch part.(type) {
+				case llmtypes.ToolCallResponse:
+					responses = append(responses, part)
+				default:
+					// Tool messages only carry their results
+					if messages[i].Role != llmtypes.ChatMessageTypeTool {
+						others = append(others, part)
+					}
+				}
+			}
+		}
+		i--
+		result = append(result, llmtypes.MessageContent{Role: llmtypes.ChatMessageTypeTool, Parts: responses})
+		if len(others) > 0 {
+			result = append(result, llmtypes.MessageContent{Role: llmtypes.ChatMessageTypeHuman, Parts: others})
+		}
+	}
+	return result
+}
+
+// pairFunctionResponses orders responses like the function calls callIDs of the previous model
+// turn, as Gemini pairs them by position and name. Responses for a call go in its slot; the
+// others fill the remaining slots in order and take the ID and name of that call. Responses
+// beyond the calls are kept at the end.
+func pairFunctionResponses(responses []llmtypes.ToolCallResponse, callIDs []string, callNames map[string]string) []llmtypes.ContentPart {
+	slots := make([]*llmtypes.ToolCallResponse, len(callIDs))
+	slotOf := make(map[string]int, len(callIDs))
+	for i, id := range callIDs {
+		if _, ok := slotOf[id]; !ok {
+			slotOf[id] = i
+		}
+	}
+	var unmatched []llmtypes.ToolCallResponse
+	for i := range responses {
+		if slot, ok := slotOf[responses[i].ToolCallID]; ok && slots[slot] == nil {
+			slots[slot] = &responses[i]
+			continue
+		}
+		unmatched = append(unmatched, responses[i])
+	}
+
+	ordered := make([]llmtypes.ContentPart, 0, len(responses))
+	for i, resp := range slots {
+		if resp == nil {
+			if len(unmatched) == 0 {
+				continue
+			}
+			filler := unmatched[0]
+			unmatched = unmatched[1:]
+			filler.ToolCallID = callIDs[i]
+			resp = &filler
+		}
+		if name := callNames[callIDs[i]]; name != "" {
+			resp.Name = name
+		}
+		ordered = append(ordered, *resp)
+	}
+	for _, resp := range unmatched {
+		ordered = append(ordered, resp)
+	}
+	return ordered
+}
+
+// newFunctionResponsePart converts resp to a functionResponse part named after the function
+// that was called, with the ID of the call
+func newFunctionResponsePart(resp llmtypes.ToolCallResponse) *genai.Part {
+	name := resp.Name
+	if name == "" {
+		name = resp.ToolCallID
+	}
+	part := genai.NewPartFromFunctionResponse(name, functionResponseMap(resp))
+	part.FunctionResponse.ID = resp.ToolCallID
+	return part
+}
+
+// hasToolCalls reports whether msg is a model turn with function calls
+func hasToolCalls(msg llmtypes.MessageContent) bool {
+	if msg.Role != llmtypes.ChatMessageTypeAI {
+		return false
+	}
+	for _, part := range msg.Parts {
+		if _, ok := part.(llmtypes.ToolCall); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// hasToolResponses reports whether msg holds tool results
+func hasToolResponses(msg llmtypes.MessageContent) bool {
+	for _, part := range msg.Parts {
+		if _, ok := part.(llmtypes.ToolCallResponse); ok {
+			return true
+		}
+	}
+	return false
+}
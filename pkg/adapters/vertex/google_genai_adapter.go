@@ -5,15 +5,16 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
-	"io"
 	"math"
 	"net/http"
+	"slices"
 	"strings"
 	"time"
 
 	"github.com/manishiitg/multi-llm-provider-go/interfaces"
 	"github.com/manishiitg/multi-llm-provider-go/internal/recorder"
 	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+	"github.com/manishiitg/multi-llm-provider-go/pkg/jsonschema"
 	"github.com/manishiitg/multi-llm-provider-go/pkg/utils"
 
 	"google.golang.org/genai"
@@ -63,8 +64,256 @@ func (g *GoogleGenAIAdapter) GenerateContent(ctx context.Context, messages []llm
 		modelID = opts.Model
 	}
 
+	// Apply the assistant prefill (emulated, Gemini has no assistant prefill)
+	if opts.AssistantPrefill != "" {
+		return utils.GenerateWithPrefill(ctx, g, messages, options, false)
+	}
+
+	// Stop at the first complete tool call when requested
+	if opts.AbortOnToolCall && opts.StreamChan != nil {
+		return utils.GenerateUntilToolCall(ctx, g, messages, options)
+	}
+
+	// Continue output cut off at the token limit when requested
+	if opts.AutoContinue > 0 {
+		return utils.GenerateWithAutoContinue(ctx, g, messages, options, false)
+	}
+
+	// Detect the media type of base64 images sent without one, converting unsupported formats
+	// when enabled
+	messages, err := utils.ResolveImageMediaTypes(messages, utils.GeminiImageLimits, opts.ImageAutoConvert)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check tool result images against the provider limits, downscaling when allowed
+	messages, err = utils.PrepareToolResultImages(messages, utils.GeminiImageLimits, opts.ToolResultImageMaxBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check the number and total size of images against the per-request limits
+	messages, err = utils.CheckImagePayload(messages, utils.GeminiImageLimits, opts.MaxImageCount, opts.ImageDownsampling)
+	if err != nil {
+		return nil, err
+	}
+
 	// Convert messages from llmtypes format to genai format
+	// messages is replaced with the combined form (consecutive tool responses merged)
+	genaiContents, messages := g.convertMessages(messages, modelID)
+
+	// Build GenerateContentConfig from options
+	config := &genai.GenerateContentConfig{}
+
+	// Set temperature
+	if opts.Temperature > 0 {
+		temperature, clamped := utils.ClampTemperature(opts.Temperature, utils.MaxTemperatureOpenAI)
+		if clamped && g.logger != nil {
+			g.logger.Infof("🌡️  Temperature %.2f is above the Gemini maximum, using %.1f", opts.Temperature, temperature)
+		}
+		temp := float32(temperature)
+		config.Temperature = &temp
+	}
+
+	// Set max output tokens
+	if opts.MaxTokens > 0 {
+		// Clamp to int32 max to prevent integer overflow
+		maxTokens := opts.MaxTokens
+		if maxTokens > math.MaxInt32 {
+			maxTokens = math.MaxInt32
+		}
+		config.MaxOutputTokens = int32(maxTokens)
+	}
+
+	// Handle JSON mode if specified
+	if opts.JSONMode {
+		config.ResponseMIMEType = "application/json"
+	}
+
+	// Handle JSON schema structured outputs
+	if opts.JSONSchema != nil && opts.JSONSchema.Schema != nil {
+		config.ResponseMIMEType = "application/json"
+		config.ResponseJsonSchema = opts.JSONSchema.Schema
+	}
+
+	// Handle ResponseSchema from context (for structured output)
+	if schema, ok := ctx.Value(ResponseSchemaKey).(*genai.Schema); ok && schema != nil {
+		config.ResponseSchema = schema
+		// If ResponseSchema is set, ensure JSON mode is enabled
+		if config.ResponseMIMEType == "" {
+			config.ResponseMIMEType = "application/json"
+		}
+	}
+
+	// Explicit response MIME type; a schema only combines with JSON or enum output
+	if opts.ResponseMimeType != "" {
+		hasSchema := config.ResponseJsonSchema != nil || config.ResponseSchema != nil
+		if !hasSchema || opts.ResponseMimeType == "application/json" || opts.ResponseMimeType == "text/x.enum" {
+			config.ResponseMIMEType = opts.ResponseMimeType
+		} else if g.logger != nil {
+			g.logger.Debugf("Ignoring response MIME type %q: a response schema requires application/json", opts.ResponseMimeType)
+		}
+	}
+
+	// Output modalities, e.g. TEXT and IMAGE for image generation models
+	for _, modality := range opts.ResponseModalities {
+		config.ResponseModalities = append(config.ResponseModalities, strings.ToUpper(modality))
+	}
+
+	// Handle thinking level for Gemini 3 Pro
+	if opts.ThinkingLevel != "" {
+		if g.logger != nil {
+			g.logger.Debugf("Setting thinking_level to: %s", opts.ThinkingLevel)
+		}
+		// Check if model is Gemini 3 Pro
+		if strings.Contains(modelID, "gemini-3") {
+			if g.logger != nil {
+				g.logger.Infof("🔍 [GEMINI] Setting thinking level to %s for model %s", opts.ThinkingLevel, modelID)
+			}
+			// Set thinking level via ThinkingConfig
+			thinkingLevel := genai.ThinkingLevel(opts.ThinkingLevel)
+			config.ThinkingConfig = &genai.ThinkingConfig{
+				ThinkingLevel: thinkingLevel,
+			}
+		} else if g.logger != nil {
+			g.logger.Debugf("⚠️  [GEMINI] Thinking level specified but model %s is not Gemini 3 Pro, ignoring", modelID)
+		}
+	}
+
+	// Ask thinking models for thought summaries when reasoning should be streamed
+	if utils.ReasoningVisible(opts) && supportsThinking(modelID) {
+		if config.ThinkingConfig == nil {
+			config.ThinkingConfig = &genai.ThinkingConfig{}
+		}
+		config.ThinkingConfig.IncludeThoughts = true
+	}
+
+	// Convert tools if provided
+	if len(opts.Tools) > 0 {
+		if g.logger != nil {
+			g.logger.Infof("🔍 [VERTEX] Converting %d tools to Gemini format", len(opts.Tools))
+			for i, tool := range opts.Tools {
+				if tool.Function != nil {
+					g.logger.Infof("🔍 [VERTEX] Tool %d: Name=%s, Description length=%d, HasParameters=%v",
+						i+1, tool.Function.Name, len(tool.Function.Description), tool.Function.Parameters != nil)
+				}
+			}
+		}
+		genaiTools := convertTools(opts.Tools, g.logger)
+		config.Tools = genaiTools
+		if g.logger != nil && genaiTools != nil && len(genaiTools) > 0 {
+			if len(genaiTools[0].FunctionDeclarations) > 0 {
+				g.logger.Infof("🔍 [VERTEX] Converted to %d function declarations in 1 Tool", len(genaiTools[0].FunctionDeclarations))
+			}
+		}
+
+		// Handle tool choice
+		if opts.ToolChoice != nil {
+			toolConfig := convertToolChoice(opts.ToolChoice)
+			if toolConfig != nil {
+				config.ToolConfig = toolConfig
+			}
+		}
+		restrictFunctionNames(config, opts.AllowedTools)
+	}
+
+	// Reference a context cache; its tools were set when it was created and Gemini rejects
+	// tools on requests using it
+	if opts.CachedContent != "" {
+		config.CachedContent = string(opts.CachedContent)
+		if config.Tools != nil && g.logger != nil {
+			g.logger.Debugf("Using cached content %s, not sending the call's %d tools", opts.CachedContent, len(opts.Tools))
+		}
+		config.Tools, config.ToolConfig = nil, nil
+	}
+
+	// Service tiers are not supported by Gemini
+	if opts.ServiceTier != "" && g.logger != nil {
+		g.logger.Debugf("Service tier %q is not supported by Gemini, ignoring", opts.ServiceTier)
+	}
+
+	// Provider-native parameters are applied last and win over the generic options
+	if opts.GeminiParams != nil {
+		applyGeminiParams(config, opts.GeminiParams)
+	}
+
+	// Pass extra headers and body fields through the SDK's HTTP options
+	if headers := utils.RequestHeaders(opts); len(headers) > 0 || len(opts.ExtraBody) > 0 {
+		httpOptions := &genai.HTTPOptions{}
+		if len(headers) > 0 {
+			httpOptions.Headers = make(http.Header, len(headers))
+			for key, value := range headers {
+				httpOptions.Headers.Set(key, value)
+			}
+		}
+		if len(opts.ExtraBody) > 0 {
+			extraBody := opts.ExtraBody
+			// Merge ourselves rather than using HTTPOptions.ExtraBody so typed fields win on conflict
+			httpOptions.ExtrasRequestProvider = func(body map[string]any) map[string]any {
+				return utils.MergeExtraBody(body, extraBody)
+			}
+		}
+		config.HTTPOptions = httpOptions
+	}
+
+	// Generate unique request ID for tracking request/response correlation (only logged on errors)
+	requestID := fmt.Sprintf("req_%d", time.Now().UnixNano())
+
+	// Track if we had to split any mixed messages - this helps correlate with empty responses
+	var hadMixedMessages bool
+	for _, msg := range messages {
+		if msg.Role == llmtypes.ChatMessageTypeAI {
+			hasText := false
+			hasToolCall := false
+			for _, part := range msg.Parts {
+				if _, ok := part.(llmtypes.TextContent); ok {
+					hasText = true
+				}
+				if _, ok := part.(llmtypes.ToolCall); ok {
+					hasToolCall = true
+				}
+			}
+			if hasText && hasToolCall {
+				hadMixedMessages = true
+				break
+			}
+		}
+	}
+
+	// Multiple candidates are requested with candidateCount on the non-streaming API;
+	// streamed candidates are emulated with concurrent requests
+	if opts.N > 1 {
+		if opts.StreamChan != nil {
+			return utils.GenerateChoicesConcurrently(ctx, g, opts.N, messages, options)
+		}
+		config.CandidateCount = int32(opts.N)
+	}
+
+	// Return the constructed request without calling the API
+	if opts.DryRun {
+		return utils.DryRunResponse(opts, map[string]interface{}{
+			"model":    modelID,
+			"contents": genaiContents,
+			"config":   config,
+		}), nil
+	}
+
+	if opts.N > 1 {
+		return g.generateContentCandidates(ctx, modelID, genaiContents, config, opts, requestID, messages)
+	}
+
+	// Use streaming path for both streaming and non-streaming requests
+	// For non-streaming (StreamChan == nil), the streaming function will accumulate tokens
+	// without sending chunks to the channel, ensuring consistent thought signature handling
+	return g.generateContentStreaming(ctx, modelID, genaiContents, config, opts, hadMixedMessages, requestID, messages)
+}
+
+// convertMessages converts llmtypes messages to genai contents.
+// It also returns the messages after consecutive tool responses have been combined,
+// which is the form used for recording and request matching.
+func (g *GoogleGenAIAdapter) convertMessages(messages []llmtypes.MessageContent, modelID string) ([]*genai.Content, []llmtypes.MessageContent) {
 	genaiContents := make([]*genai.Content, 0, len(messages))
+	messages = utils.PrefixMessageNames(messages)
 
 	// Track function calls from previous AI message to ensure function responses match
 	var previousFunctionCallIDs []string
@@ -86,61 +335,8 @@ func (g *GoogleGenAIAdapter) GenerateContent(ctx context.Context, messages []llm
 			len(messages), len(allFunctionCallIDs))
 	}
 
-	// CRITICAL FIX: Combine consecutive Tool messages that follow an AI message with function calls
-	// Gemini requires ALL function responses to be in a SINGLE message, matching the order of function calls
-	combinedMessages := make([]llmtypes.MessageContent, 0, len(messages))
-	for i := 0; i < len(messages); i++ {
-		msg := messages[i]
-
-		// If this is a Tool message and the previous message (in original array) was an AI message with function calls
-		if msg.Role == llmtypes.ChatMessageTypeTool && i > 0 {
-			prevMsg := messages[i-1]
-			if prevMsg.Role == llmtypes.ChatMessageTypeAI {
-				// Check if previous message has function calls
-				hasFunctionCalls := false
-				for _, part := range prevMsg.Parts {
-					if _, ok := part.(llmtypes.ToolCall); ok {
-						hasFunctionCalls = true
-						break
-					}
-				}
-
-				// If previous message has function calls, combine this and following Tool messages
-				if hasFunctionCalls {
-					combinedParts := make([]llmtypes.ContentPart, 0)
-
-					// Collect all ToolCallResponse parts from consecutive Tool messages
-					j := i
-					for j < len(messages) && messages[j].Role == llmtypes.ChatMessageTypeTool {
-						for _, part := range messages[j].Parts {
-							if _, ok := part.(llmtypes.ToolCallResponse); ok {
-								combinedParts = append(combinedParts, part)
-							}
-						}
-						j++
-					}
-
-					// Create a single combined Tool message
-					if len(combinedParts) > 0 {
-						combinedMessages = append(combinedMessages, llmtypes.MessageContent{
-							Role:  llmtypes.ChatMessageTypeTool,
-							Parts: combinedParts,
-						})
-						if g.logger != nil {
-							g.logger.Debugf("🔍 [GEMINI] Combined %d Tool messages (indices %d-%d) into single message with %d responses",
-								j-i, i, j-1, len(combinedParts))
-						}
-						// Skip the individual Tool messages we just combined
-						i = j - 1
-						continue
-					}
-				}
-			}
-		}
-
-		// Add message as-is if not combined
-		combinedMessages = append(combinedMessages, msg)
-	}
+	// Gemini requires ALL function responses of a turn in a SINGLE message, matching the order of function calls
+	combinedMessages := combineFunctionResponses(messages)
 
 	// Use combined messages for processing
 	messages = combinedMessages
@@ -179,7 +375,7 @@ func (g *GoogleGenAIAdapter) GenerateContent(ctx context.Context, messages []llm
 					if tc, ok := textParts[0].(llmtypes.TextContent); ok {
 						textPreview = tc.Text
 						if len(textPreview) > 100 {
-							textPreview = textPreview[:100] + "..."
+							textPreview = utils.TruncateUTF8(textPreview, 100) + "..."
 						}
 					}
 				}
@@ -277,73 +473,8 @@ func (g *GoogleGenAIAdapter) GenerateContent(ctx context.Context, messages []llm
 			// Gemini requires: number of function response parts = number of function call parts
 			// IMPORTANT: Gemini matches responses to calls by POSITION/ORDER, not by ID
 			if len(previousFunctionCallIDs) > 0 {
-				// First, try to match by ID (for proper association)
-				responseMap := make(map[string]llmtypes.ToolCallResponse)
-				for _, resp := range functionResponses {
-					responseMap[resp.ToolCallID] = resp
-				}
-
-				// Try to match responses to calls by ID
-				orderedResponses := make([]llmtypes.ContentPart, 0, len(previousFunctionCallIDs))
-				matchedByID := 0
-				for _, callID := range previousFunctionCallIDs {
-					if resp, found := responseMap[callID]; found {
-						orderedResponses = append(orderedResponses, resp)
-						matchedByID++
-					} else {
-						// ID not found - will use order-based fallback
-						orderedResponses = append(orderedResponses, nil) // placeholder
-					}
-				}
-
-				// If ID matching failed, fall back to order-based matching
-				// This handles cases where IDs don't match but we have the right number of responses
-				if matchedByID < len(previousFunctionCallIDs) && len(functionResponses) == len(previousFunctionCallIDs) {
-					if g.logger != nil {
-						g.logger.Debugf("🔍 [GEMINI] ID matching incomplete (%d/%d matched), falling back to order-based matching", matchedByID, len(previousFunctionCallIDs))
-					}
-					// Use responses in order (Gemini matches by position)
-					orderedResponses = make([]llmtypes.ContentPart, 0, len(functionResponses))
-					for _, resp := range functionResponses {
-						orderedResponses = append(orderedResponses, resp)
-					}
-				} else if matchedByID == 0 && len(functionResponses) == len(previousFunctionCallIDs) {
-					// No IDs matched but count matches - use order-based
-					if g.logger != nil {
-						g.logger.Debugf("🔍 [GEMINI] No IDs matched, using order-based matching (%d responses for %d calls)", len(functionResponses), len(previousFunctionCallIDs))
-					}
-					orderedResponses = make([]llmtypes.ContentPart, 0, len(functionResponses))
-					for _, resp := range functionResponses {
-						orderedResponses = append(orderedResponses, resp)
-					}
-				} else if matchedByID < len(previousFunctionCallIDs) {
-					// Some IDs matched but not all - use what we have, fill missing with order
-					if g.logger != nil {
-						g.logger.Debugf("🔍 [GEMINI] Partial ID matching (%d/%d), using hybrid approach", matchedByID, len(previousFunctionCallIDs))
-					}
-					// Remove nil placeholders and use order for unmatched
-					orderedResponses = make([]llmtypes.ContentPart, 0, len(functionResponses))
-					usedResponses := make(map[int]bool)
-					// First add matched responses
-					for _, callID := range previousFunctionCallIDs {
-						if resp, found := responseMap[callID]; found {
-							orderedResponses = append(orderedResponses, resp)
-							// Find which response index this was
-							for j, r := range functionResponses {
-								if r.ToolCallID == resp.ToolCallID {
-									usedResponses[j] = true
-									break
-								}
-							}
-						}
-					}
-					// Then add unmatched responses in order
-					for i, resp := range functionResponses {
-						if !usedResponses[i] {
-							orderedResponses = append(orderedResponses, resp)
-						}
-					}
-				}
+				// Responses go in the slot of their call, matched by ID, else by order
+				orderedResponses := pairFunctionResponses(functionResponses, previousFunctionCallIDs, allFunctionCallIDs)
 
 				// Check if we have the right number of responses
 				if len(orderedResponses) != len(previousFunctionCallIDs) {
@@ -362,8 +493,8 @@ func (g *GoogleGenAIAdapter) GenerateContent(ctx context.Context, messages []llm
 				// Update message parts with ordered responses
 				if len(orderedResponses) > 0 {
 					if g.logger != nil {
-						g.logger.Debugf("🔍 [GEMINI] Message %d: Using %d responses for %d function calls (matched by ID: %d)",
-							msgIdx, len(orderedResponses), len(previousFunctionCallIDs), matchedByID)
+						g.logger.Debugf("🔍 [GEMINI] Message %d: Using %d responses for %d function calls",
+							msgIdx, len(orderedResponses), len(previousFunctionCallIDs))
 					}
 					msg.Parts = orderedResponses
 				}
@@ -379,7 +510,7 @@ func (g *GoogleGenAIAdapter) GenerateContent(ctx context.Context, messages []llm
 					for i, resp := range functionResponses {
 						contentPreview := resp.Content
 						if len(contentPreview) > 100 {
-							contentPreview = contentPreview[:100] + "..."
+							contentPreview = utils.TruncateUTF8(contentPreview, 100) + "..."
 						}
 						matched := ""
 						if _, exists := allFunctionCallIDs[resp.ToolCallID]; exists {
@@ -400,7 +531,7 @@ func (g *GoogleGenAIAdapter) GenerateContent(ctx context.Context, messages []llm
 					if g.logger != nil {
 						contentPreview := resp.Content
 						if len(contentPreview) > 50 {
-							contentPreview = contentPreview[:50] + "..."
+							contentPreview = utils.TruncateUTF8(contentPreview, 50) + "..."
 						}
 						g.logger.Debugf("🔍 [GEMINI]   Converting response to text: Tool %s returned: %s",
 							resp.Name, contentPreview)
@@ -418,7 +549,7 @@ func (g *GoogleGenAIAdapter) GenerateContent(ctx context.Context, messages []llm
 						if textPart, ok := part.(llmtypes.TextContent); ok {
 							preview := textPart.Text
 							if len(preview) > 100 {
-								preview = preview[:100] + "..."
+								preview = utils.TruncateUTF8(preview, 100) + "..."
 							}
 							g.logger.Debugf("🔍 [GEMINI]   Text part %d: %s", i+1, preview)
 						} else {
@@ -458,13 +589,13 @@ func (g *GoogleGenAIAdapter) GenerateContent(ctx context.Context, messages []llm
 				case llmtypes.TextContent:
 					preview := p.Text
 					if len(preview) > 50 {
-						preview = preview[:50] + "..."
+						preview = utils.TruncateUTF8(preview, 50) + "..."
 					}
 					g.logger.Infof("🔍 [GEMINI]   Part %d: TextContent: %s", i+1, preview)
 				case llmtypes.ToolCallResponse:
 					contentPreview := p.Content
 					if len(contentPreview) > 50 {
-						contentPreview = contentPreview[:50] + "..."
+						contentPreview = utils.TruncateUTF8(contentPreview, 50) + "..."
 					}
 					g.logger.Infof("🔍 [GEMINI]   Part %d: ToolCallResponse: ToolCallID=%s, Name=%s, Content: %s",
 						i+1, p.ToolCallID, p.Name, contentPreview)
@@ -498,7 +629,7 @@ func (g *GoogleGenAIAdapter) GenerateContent(ctx context.Context, messages []llm
 						if toolResp, ok := part.(llmtypes.ToolCallResponse); ok {
 							contentPreview := toolResp.Content
 							if len(contentPreview) > 100 {
-								contentPreview = contentPreview[:100] + "..."
+								contentPreview = utils.TruncateUTF8(contentPreview, 100) + "..."
 							}
 							g.logger.Infof("✅ [GEMINI] Sending tool response to Gemini - ToolCallID: %s, Name: %s, Content: %s",
 								toolResp.ToolCallID, toolResp.Name, contentPreview)
@@ -521,7 +652,7 @@ func (g *GoogleGenAIAdapter) GenerateContent(ctx context.Context, messages []llm
 					if textPart, ok := part.(llmtypes.TextContent); ok {
 						preview := textPart.Text
 						if len(preview) > 100 {
-							preview = preview[:100] + "..."
+							preview = utils.TruncateUTF8(preview, 100) + "..."
 						}
 						g.logger.Errorf("❌ [GEMINI]     Text content: %s", preview)
 					} else if toolResp, ok := part.(llmtypes.ToolCallResponse); ok {
@@ -533,115 +664,7 @@ func (g *GoogleGenAIAdapter) GenerateContent(ctx context.Context, messages []llm
 		}
 	}
 
-	// Build GenerateContentConfig from options
-	config := &genai.GenerateContentConfig{}
-
-	// Set temperature
-	if opts.Temperature > 0 {
-		temp := float32(opts.Temperature)
-		config.Temperature = &temp
-	}
-
-	// Set max output tokens
-	if opts.MaxTokens > 0 {
-		// Clamp to int32 max to prevent integer overflow
-		maxTokens := opts.MaxTokens
-		if maxTokens > math.MaxInt32 {
-			maxTokens = math.MaxInt32
-		}
-		config.MaxOutputTokens = int32(maxTokens)
-	}
-
-	// Handle JSON mode if specified
-	if opts.JSONMode {
-		config.ResponseMIMEType = "application/json"
-	}
-
-	// Handle ResponseSchema from context (for structured output)
-	if schema, ok := ctx.Value(ResponseSchemaKey).(*genai.Schema); ok && schema != nil {
-		config.ResponseSchema = schema
-		// If ResponseSchema is set, ensure JSON mode is enabled
-		if config.ResponseMIMEType == "" {
-			config.ResponseMIMEType = "application/json"
-		}
-	}
-
-	// Handle thinking level for Gemini 3 Pro
-	if opts.ThinkingLevel != "" {
-		if g.logger != nil {
-			g.logger.Debugf("Setting thinking_level to: %s", opts.ThinkingLevel)
-		}
-		// Check if model is Gemini 3 Pro
-		if strings.Contains(modelID, "gemini-3") {
-			if g.logger != nil {
-				g.logger.Infof("🔍 [GEMINI] Setting thinking level to %s for model %s", opts.ThinkingLevel, modelID)
-			}
-			// Set thinking level via ThinkingConfig
-			thinkingLevel := genai.ThinkingLevel(opts.ThinkingLevel)
-			config.ThinkingConfig = &genai.ThinkingConfig{
-				ThinkingLevel: thinkingLevel,
-			}
-		} else if g.logger != nil {
-			g.logger.Debugf("⚠️  [GEMINI] Thinking level specified but model %s is not Gemini 3 Pro, ignoring", modelID)
-		}
-	}
-
-	// Convert tools if provided
-	if len(opts.Tools) > 0 {
-		if g.logger != nil {
-			g.logger.Infof("🔍 [VERTEX] Converting %d tools to Gemini format", len(opts.Tools))
-			for i, tool := range opts.Tools {
-				if tool.Function != nil {
-					g.logger.Infof("🔍 [VERTEX] Tool %d: Name=%s, Description length=%d, HasParameters=%v",
-						i+1, tool.Function.Name, len(tool.Function.Description), tool.Function.Parameters != nil)
-				}
-			}
-		}
-		genaiTools := convertTools(opts.Tools, g.logger)
-		config.Tools = genaiTools
-		if g.logger != nil && genaiTools != nil && len(genaiTools) > 0 {
-			if len(genaiTools[0].FunctionDeclarations) > 0 {
-				g.logger.Infof("🔍 [VERTEX] Converted to %d function declarations in 1 Tool", len(genaiTools[0].FunctionDeclarations))
-			}
-		}
-
-		// Handle tool choice
-		if opts.ToolChoice != nil {
-			toolConfig := convertToolChoice(opts.ToolChoice)
-			if toolConfig != nil {
-				config.ToolConfig = toolConfig
-			}
-		}
-	}
-
-	// Generate unique request ID for tracking request/response correlation (only logged on errors)
-	requestID := fmt.Sprintf("req_%d", time.Now().UnixNano())
-
-	// Track if we had to split any mixed messages - this helps correlate with empty responses
-	var hadMixedMessages bool
-	for _, msg := range messages {
-		if msg.Role == llmtypes.ChatMessageTypeAI {
-			hasText := false
-			hasToolCall := false
-			for _, part := range msg.Parts {
-				if _, ok := part.(llmtypes.TextContent); ok {
-					hasText = true
-				}
-				if _, ok := part.(llmtypes.ToolCall); ok {
-					hasToolCall = true
-				}
-			}
-			if hasText && hasToolCall {
-				hadMixedMessages = true
-				break
-			}
-		}
-	}
-
-	// Use streaming path for both streaming and non-streaming requests
-	// For non-streaming (StreamChan == nil), the streaming function will accumulate tokens
-	// without sending chunks to the channel, ensuring consistent thought signature handling
-	return g.generateContentStreaming(ctx, modelID, genaiContents, config, opts, hadMixedMessages, requestID, messages)
+	return genaiContents, messages
 }
 
 // generateContentStreaming handles streaming responses from Google GenAI API
@@ -673,7 +696,11 @@ func (g *GoogleGenAIAdapter) generateContentStreaming(ctx context.Context, model
 	// Accumulate response data
 	var accumulatedContent strings.Builder
 	var accumulatedToolCalls []llmtypes.ToolCall
+	var blocks []llmtypes.ContentBlock
+	var toolCallIDs utils.ToolCallIDs
+	var accumulatedImages []llmtypes.ImageContent
 	var usage *genai.GenerateContentResponseUsageMetadata
+	var finishReason string
 	var sharedThoughtSignature string // For parallel tool calls, share thought signature across all
 
 	// Handle replay mode - create iterator from recorded chunks
@@ -701,14 +728,24 @@ func (g *GoogleGenAIAdapter) generateContentStreaming(ctx context.Context, model
 			// Process this replayed chunk
 			if response.UsageMetadata != nil {
 				usage = response.UsageMetadata
+				if err := utils.SendUsageChunk(ctx, opts, llmtypes.ExtractUsageFromGenerationInfo(utils.ExtractGenerationInfoFromVertexUsage(usage))); err != nil {
+					return nil, err
+				}
 			}
 
 			// Process candidates (same logic as below)
 			for _, candidate := range response.Candidates {
+				if candidate.FinishReason != "" {
+					finishReason = string(candidate.FinishReason)
+				}
 				if candidate.Content != nil {
 					for _, part := range candidate.Content.Parts {
+						if image, ok := generatedImage(part); ok {
+							accumulatedImages = append(accumulatedImages, image)
+						}
 						if part.Text != "" {
 							accumulatedContent.WriteString(part.Text)
+							blocks = llmtypes.AppendTextBlock(blocks, llmtypes.ContentBlockTypeText, part.Text)
 							if opts.StreamChan != nil {
 								select {
 								case opts.StreamChan <- llmtypes.StreamChunk{
@@ -725,10 +762,9 @@ func (g *GoogleGenAIAdapter) generateContentStreaming(ctx context.Context, model
 							if thoughtSignature == "" && sharedThoughtSignature != "" {
 								thoughtSignature = sharedThoughtSignature
 							}
-							toolCallID := generateToolCallID()
 							argsJSON := convertArgumentsToString(part.FunctionCall.Args)
 							toolCall := llmtypes.ToolCall{
-								ID:               toolCallID,
+								ID:               part.FunctionCall.ID,
 								Type:             "function",
 								ThoughtSignature: thoughtSignature,
 								FunctionCall: &llmtypes.FunctionCall{
@@ -736,6 +772,8 @@ func (g *GoogleGenAIAdapter) generateContentStreaming(ctx context.Context, model
 									Arguments: argsJSON,
 								},
 							}
+							toolCallIDs.Assign(&toolCall)
+							blocks = append(blocks, llmtypes.ContentBlock{Type: llmtypes.ContentBlockTypeToolCall, ToolCallIndex: len(accumulatedToolCalls)})
 							accumulatedToolCalls = append(accumulatedToolCalls, toolCall)
 							if opts.StreamChan != nil {
 								toolCallCopy := toolCall
@@ -765,6 +803,9 @@ func (g *GoogleGenAIAdapter) generateContentStreaming(ctx context.Context, model
 				}
 				return nil, fmt.Errorf("genai streaming error: %w", err)
 			}
+			if opts.StreamEventHook != nil {
+				opts.StreamEventHook(response)
+			}
 
 			// Record chunk if recording is enabled
 			if rec != nil && rec.IsRecordingEnabled() {
@@ -786,10 +827,18 @@ func (g *GoogleGenAIAdapter) generateContentStreaming(ctx context.Context, model
 			// Extract usage metadata if available
 			if response.UsageMetadata != nil {
 				usage = response.UsageMetadata
+				if err := utils.SendUsageChunk(ctx, opts, llmtypes.ExtractUsageFromGenerationInfo(utils.ExtractGenerationInfoFromVertexUsage(usage))); err != nil {
+					return nil, err
+				}
 			}
 
 			// Process candidates
 			for _, candidate := range response.Candidates {
+				// Store finish reason (only set on the last chunk)
+				if candidate.FinishReason != "" {
+					finishReason = string(candidate.FinishReason)
+				}
+
 				// First pass: Extract thought signature from any part (for parallel calls)
 				if candidate.Content != nil {
 					for _, part := range candidate.Content.Parts {
@@ -817,9 +866,31 @@ func (g *GoogleGenAIAdapter) generateContentStreaming(ctx context.Context, model
 				// Second pass: Extract content and tool calls
 				if candidate.Content != nil {
 					for _, part := range candidate.Content.Parts {
+						// Thought summaries are streamed separately and never become content
+						if part.Thought {
+							blocks = llmtypes.AppendTextBlock(blocks, llmtypes.ContentBlockTypeReasoning, part.Text)
+							if part.Text != "" && opts.StreamChan != nil && utils.ReasoningVisible(opts) {
+								select {
+								case opts.StreamChan <- llmtypes.StreamChunk{
+									Type:    llmtypes.StreamChunkTypeReasoning,
+									Content: part.Text,
+								}:
+								case <-ctx.Done():
+									return nil, ctx.Err()
+								}
+							}
+							continue
+						}
+
+						// Collect generated images
+						if image, ok := generatedImage(part); ok {
+							accumulatedImages = append(accumulatedImages, image)
+						}
+
 						// Extract text content and stream immediately
 						if part.Text != "" {
 							accumulatedContent.WriteString(part.Text)
+							blocks = llmtypes.AppendTextBlock(blocks, llmtypes.ContentBlockTypeText, part.Text)
 							if opts.StreamChan != nil {
 								select {
 								case opts.StreamChan <- llmtypes.StreamChunk{
@@ -844,11 +915,9 @@ func (g *GoogleGenAIAdapter) generateContentStreaming(ctx context.Context, model
 								}
 							}
 
-							// Generate tool call ID
-							toolCallID := generateToolCallID()
 							argsJSON := convertArgumentsToString(part.FunctionCall.Args)
 							toolCall := llmtypes.ToolCall{
-								ID:               toolCallID,
+								ID:               part.FunctionCall.ID,
 								Type:             "function",
 								ThoughtSignature: thoughtSignature,
 								FunctionCall: &llmtypes.FunctionCall{
@@ -856,6 +925,8 @@ func (g *GoogleGenAIAdapter) generateContentStreaming(ctx context.Context, model
 									Arguments: argsJSON,
 								},
 							}
+							toolCallIDs.Assign(&toolCall)
+							blocks = append(blocks, llmtypes.ContentBlock{Type: llmtypes.ContentBlockTypeToolCall, ToolCallIndex: len(accumulatedToolCalls)})
 							accumulatedToolCalls = append(accumulatedToolCalls, toolCall)
 
 							// Stream tool call when complete
@@ -879,11 +950,18 @@ func (g *GoogleGenAIAdapter) generateContentStreaming(ctx context.Context, model
 
 	// Build final response
 	choice := &llmtypes.ContentChoice{
-		Content: accumulatedContent.String(),
+		Content:    accumulatedContent.String(),
+		StopReason: finishReason,
 	}
 	if len(accumulatedToolCalls) > 0 {
 		choice.ToolCalls = accumulatedToolCalls
 	}
+	if len(blocks) > 0 {
+		choice.Blocks = blocks
+	}
+	if len(accumulatedImages) > 0 {
+		choice.Images = accumulatedImages
+	}
 
 	// Extract token usage if available
 	choice.GenerationInfo = utils.ExtractGenerationInfoFromVertexUsage(usage)
@@ -904,10 +982,113 @@ func (g *GoogleGenAIAdapter) generateContentStreaming(ctx context.Context, model
 
 	// Extract usage from GenerationInfo
 	usageExtracted := llmtypes.ExtractUsageFromGenerationInfo(choice.GenerationInfo)
-	return &llmtypes.ContentResponse{
+	resp := &llmtypes.ContentResponse{
 		Choices: []*llmtypes.ContentChoice{choice},
 		Usage:   usageExtracted,
-	}, nil
+	}
+
+	// Send terminal chunk with stop reason and usage before the channel is closed
+	if opts.StreamChan != nil {
+		select {
+		case opts.StreamChan <- llmtypes.NewFinishChunk(resp):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	return resp, nil
+}
+
+// generateContentCandidates handles requests for multiple candidates using the non-streaming API
+// Each candidate becomes a choice; usage is reported for the whole request on every choice
+func (g *GoogleGenAIAdapter) generateContentCandidates(ctx context.Context, modelID string, genaiContents []*genai.Content, config *genai.GenerateContentConfig, opts *llmtypes.CallOptions, requestID string, messages []llmtypes.MessageContent) (*llmtypes.ContentResponse, error) {
+	result, err := g.client.Models.GenerateContent(ctx, modelID, genaiContents, config)
+	if err != nil {
+		if g.logger != nil {
+			g.logErrorDetails(requestID, modelID, messages, config, opts, err, result)
+		}
+		return nil, fmt.Errorf("genai generate content: %w", err)
+	}
+
+	choices := make([]*llmtypes.ContentChoice, 0, len(result.Candidates))
+	for _, candidate := range result.Candidates {
+		if candidate == nil {
+			continue
+		}
+		var content strings.Builder
+		var toolCalls []llmtypes.ToolCall
+		var blocks []llmtypes.ContentBlock
+		var images []llmtypes.ImageContent
+		var sharedThoughtSignature string
+		if candidate.Content != nil {
+			// Parallel tool calls share the thought signature of the first part carrying one
+			for _, part := range candidate.Content.Parts {
+				if sig := extractThoughtSignature(part, g.logger); sig != "" {
+					sharedThoughtSignature = sig
+					break
+				}
+			}
+			for _, part := range candidate.Content.Parts {
+				if part.Thought {
+					if part.Text != "" {
+						blocks = append(blocks, llmtypes.ContentBlock{Type: llmtypes.ContentBlockTypeReasoning, Text: part.Text})
+					}
+					continue
+				}
+				if image, ok := generatedImage(part); ok {
+					images = append(images, image)
+				}
+				if part.Text != "" {
+					content.WriteString(part.Text)
+					blocks = append(blocks, llmtypes.ContentBlock{Type: llmtypes.ContentBlockTypeText, Text: part.Text})
+				}
+				if part.FunctionCall != nil {
+					thoughtSignature := extractThoughtSignature(part, g.logger)
+					if thoughtSignature == "" {
+						thoughtSignature = sharedThoughtSignature
+					}
+					blocks = append(blocks, llmtypes.ContentBlock{Type: llmtypes.ContentBlockTypeToolCall, ToolCallIndex: len(toolCalls)})
+					toolCalls = append(toolCalls, llmtypes.ToolCall{
+						ID:               part.FunctionCall.ID,
+						Type:             "function",
+						ThoughtSignature: thoughtSignature,
+						FunctionCall: &llmtypes.FunctionCall{
+							Name:      part.FunctionCall.Name,
+							Arguments: convertArgumentsToString(part.FunctionCall.Args),
+						},
+					})
+				}
+			}
+		}
+		// Gemini usually sends function calls without IDs
+		utils.EnsureToolCallIDs(toolCalls)
+		choices = append(choices, &llmtypes.ContentChoice{
+			Content:        content.String(),
+			StopReason:     string(candidate.FinishReason),
+			ToolCalls:      toolCalls,
+			Blocks:         blocks,
+			Images:         images,
+			GenerationInfo: utils.ExtractGenerationInfoFromVertexUsage(result.UsageMetadata),
+		})
+	}
+
+	resp := &llmtypes.ContentResponse{Choices: choices}
+	if len(choices) > 0 {
+		resp.Usage = llmtypes.ExtractUsageFromGenerationInfo(choices[0].GenerationInfo)
+	}
+	return resp, nil
+}
+
+// generatedImage converts an inline image part of a response to an ImageContent
+func generatedImage(part *genai.Part) (llmtypes.ImageContent, bool) {
+	if part == nil || part.Thought || part.InlineData == nil || !strings.HasPrefix(part.InlineData.MIMEType, "image/") {
+		return llmtypes.ImageContent{}, false
+	}
+	return llmtypes.ImageContent{
+		SourceType: "base64",
+		MediaType:  part.InlineData.MIMEType,
+		Data:       base64.StdEncoding.EncodeToString(part.InlineData.Data),
+	}, true
 }
 
 // buildRequestInfo creates a RequestInfo from messages and options for recording/matching
@@ -1024,6 +1205,14 @@ func convertTools(llmTools []llmtypes.Tool, logger interfaces.Logger) []*genai.T
 				}
 			}
 
+			// Gemini's Schema has no $ref and few formats
+			paramsMap, changes := jsonschema.Adapt(paramsMap, geminiSchemaCompat)
+			if logger != nil {
+				for _, change := range changes {
+					logger.Infof("🔧 [VERTEX] Function %s schema: %s", tool.Function.Name, change)
+				}
+			}
+
 			// Validate schema before conversion
 			if logger != nil {
 				logger.Infof("🔍 [VERTEX] Validating schema for function %s", tool.Function.Name)
@@ -1067,6 +1256,13 @@ func convertTools(llmTools []llmtypes.Tool, logger interfaces.Logger) []*genai.T
 	return nil
 }
 
+// geminiSchemaCompat is what genai.Schema can express for function parameters
+var geminiSchemaCompat = jsonschema.Compat{
+	InlineRefs:  true,
+	Unsupported: []string{"$schema", "additionalProperties", "patternProperties"},
+	Formats:     []string{"enum", "date-time", "int32", "int64", "float", "double"},
+}
+
 // validateSchemaForGemini validates JSON Schema for common issues that cause MALFORMED_FUNCTION_CALL
 func validateSchemaForGemini(schema map[string]interface{}, functionName string, logger interfaces.Logger) {
 	if schema == nil {
@@ -1163,6 +1359,64 @@ func buildSchemaManually(jsonSchema map[string]interface{}) *genai.Schema {
 	return schema
 }
 
+// applyGeminiParams sets the fields of p that are set (WithGeminiParams) on config
+func applyGeminiParams(config *genai.GenerateContentConfig, p *llmtypes.GeminiParams) {
+	if p.TopP != nil {
+		config.TopP = p.TopP
+	}
+	if p.TopK != nil {
+		config.TopK = p.TopK
+	}
+	if p.Seed != nil {
+		config.Seed = p.Seed
+	}
+	if p.PresencePenalty != nil {
+		config.PresencePenalty = p.PresencePenalty
+	}
+	if p.FrequencyPenalty != nil {
+		config.FrequencyPenalty = p.FrequencyPenalty
+	}
+	if len(p.StopSequences) > 0 {
+		config.StopSequences = p.StopSequences
+	}
+	if p.ResponseLogprobs {
+		config.ResponseLogprobs = true
+	}
+	if p.Logprobs != nil {
+		config.Logprobs = p.Logprobs
+	}
+	if p.MediaResolution != "" {
+		config.MediaResolution = genai.MediaResolution(p.MediaResolution)
+	}
+	if len(p.Labels) > 0 {
+		config.Labels = p.Labels
+	}
+}
+
+// restrictFunctionNames limits function calls to allowed (WithAllowedTools): mode ANY keeps
+// requiring a call, among the allowed functions, and AUTO becomes VALIDATED, which allows
+// either text or a call to one of them
+func restrictFunctionNames(config *genai.GenerateContentConfig, allowed []string) {
+	if len(allowed) == 0 {
+		return
+	}
+	if config.ToolConfig == nil || config.ToolConfig.FunctionCallingConfig == nil {
+		config.ToolConfig = &genai.ToolConfig{FunctionCallingConfig: &genai.FunctionCallingConfig{Mode: genai.FunctionCallingConfigModeAuto}}
+	}
+	callingConfig := config.ToolConfig.FunctionCallingConfig
+	switch callingConfig.Mode {
+	case genai.FunctionCallingConfigModeNone:
+		return
+	case genai.FunctionCallingConfigModeAny:
+		if len(callingConfig.AllowedFunctionNames) > 0 {
+			return // a forced function is already more specific
+		}
+	default:
+		callingConfig.Mode = genai.FunctionCallingConfigModeValidated
+	}
+	callingConfig.AllowedFunctionNames = allowed
+}
+
 // convertToolChoice converts llmtypes tool choice to genai tool config
 func convertToolChoice(toolChoice interface{}) *genai.ToolConfig {
 	if toolChoice == nil {
@@ -1190,12 +1444,17 @@ func convertToolChoice(toolChoice interface{}) *genai.ToolConfig {
 
 	// Handle ToolChoice struct if it's that type
 	if tc, ok := toolChoice.(*llmtypes.ToolChoice); ok && tc != nil {
-		// Note: llmtypes ToolChoice structure may vary, adjust as needed
-		// For now, default to AUTO
-		config.FunctionCallingConfig.Mode = genai.FunctionCallingConfigModeAuto
-
-		// If there's a function specified, we could set AllowedFunctionNames
-		// This would require knowing the actual ToolChoice structure
+		switch {
+		case tc.Function != nil && tc.Function.Name != "":
+			config.FunctionCallingConfig.Mode = genai.FunctionCallingConfigModeAny
+			config.FunctionCallingConfig.AllowedFunctionNames = []string{tc.Function.Name}
+		case tc.None || tc.Type == "none":
+			config.FunctionCallingConfig.Mode = genai.FunctionCallingConfigModeNone
+		case tc.Any || tc.Type == "required" || tc.Type == "any":
+			config.FunctionCallingConfig.Mode = genai.FunctionCallingConfigModeAny
+		default:
+			config.FunctionCallingConfig.Mode = genai.FunctionCallingConfigModeAuto
+		}
 		return config
 	}
 
@@ -1240,6 +1499,12 @@ func (g *GoogleGenAIAdapter) Call(ctx context.Context, prompt string, options ..
 	return resp.Choices[0].Content, nil
 }
 
+// vertexEmbeddingTaskTypes are the task_type values accepted by Vertex AI and Gemini embedding models
+var vertexEmbeddingTaskTypes = []string{
+	"RETRIEVAL_QUERY", "RETRIEVAL_DOCUMENT", "SEMANTIC_SIMILARITY", "CLASSIFICATION", "CLUSTERING",
+	"QUESTION_ANSWERING", "FACT_VERIFICATION", "CODE_RETRIEVAL_QUERY",
+}
+
 // GenerateEmbeddings implements the llmtypes.EmbeddingModel interface
 // Input can be a single string or a slice of strings
 func (g *GoogleGenAIAdapter) GenerateEmbeddings(ctx context.Context, input interface{}, options ...llmtypes.EmbeddingOption) (*llmtypes.EmbeddingResponse, error) {
@@ -1301,10 +1566,19 @@ func (g *GoogleGenAIAdapter) GenerateEmbeddings(ctx context.Context, input inter
 		config.OutputDimensionality = &dims
 	}
 
+	// Add the task type (RETRIEVAL_QUERY, RETRIEVAL_DOCUMENT, ...) if specified
+	if opts.TaskType != "" {
+		taskType := strings.ToUpper(string(opts.TaskType))
+		if !slices.Contains(vertexEmbeddingTaskTypes, taskType) {
+			return nil, fmt.Errorf("embedding task type %q is not supported by %s, supported: %v", opts.TaskType, modelID, vertexEmbeddingTaskTypes)
+		}
+		config.TaskType = taskType
+	}
+
 	// Log input details if logger is available
 	if g.logger != nil {
-		g.logger.Debugf("Vertex AI GenerateEmbeddings INPUT - model: %s, input_count: %d, dimensions: %v",
-			modelID, len(inputTexts), opts.Dimensions)
+		g.logger.Debugf("Vertex AI GenerateEmbeddings INPUT - model: %s, input_count: %d, dimensions: %v, task_type: %s",
+			modelID, len(inputTexts), opts.Dimensions, config.TaskType)
 	}
 
 	// Call Vertex AI EmbedContent API
@@ -1454,6 +1728,8 @@ func (g *GoogleGenAIAdapter) convertMessageParts(parts []llmtypes.ContentPart, m
 				}
 				continue
 			}
+			// The ID pairs the call with its functionResponse
+			genaiPart.FunctionCall.ID = toolCall.ID
 
 			// Handle thought signature
 			// CRITICAL: Gemini 3 Pro requires ALL function calls to have thought signatures
@@ -1535,16 +1811,13 @@ func (g *GoogleGenAIAdapter) convertMessageParts(parts []llmtypes.ContentPart, m
 			// Handle ToolCallResponse
 			contentPreview := toolResp.Content
 			if len(contentPreview) > 50 {
-				contentPreview = contentPreview[:50] + "..."
+				contentPreview = utils.TruncateUTF8(contentPreview, 50) + "..."
 			}
 			if g.logger != nil {
 				g.logger.Infof("🔍 [GEMINI] Converting ToolCallResponse: ToolCallID=%s, Name=%s, Content: %s",
 					toolResp.ToolCallID, toolResp.Name, contentPreview)
 			}
-			responseMap := map[string]interface{}{
-				"result": toolResp.Content,
-			}
-			genaiPart := genai.NewPartFromFunctionResponse(toolResp.ToolCallID, responseMap)
+			genaiPart := newFunctionResponsePart(toolResp)
 			if genaiPart == nil {
 				if g.logger != nil {
 					g.logger.Errorf("❌ [GEMINI] Failed to create genai.Part from ToolCallResponse: ToolCallID=%s, Name=%s", toolResp.ToolCallID, toolResp.Name)
@@ -1552,6 +1825,7 @@ func (g *GoogleGenAIAdapter) convertMessageParts(parts []llmtypes.ContentPart, m
 				continue
 			}
 			genaiParts = append(genaiParts, genaiPart)
+			genaiParts = append(genaiParts, g.attachToolResultImages(genaiPart, toolResp, modelID)...)
 			continue
 		}
 
@@ -1570,6 +1844,7 @@ func (g *GoogleGenAIAdapter) convertMessageParts(parts []llmtypes.ContentPart, m
 					argsMap := parseJSONObject(toolCall.FunctionCall.Arguments)
 					genaiPart := genai.NewPartFromFunctionCall(toolCall.FunctionCall.Name, argsMap)
 					if genaiPart != nil {
+						genaiPart.FunctionCall.ID = toolCall.ID
 						genaiParts = append(genaiParts, genaiPart)
 						continue
 					}
@@ -1586,10 +1861,7 @@ func (g *GoogleGenAIAdapter) convertMessageParts(parts []llmtypes.ContentPart, m
 					if g.logger != nil {
 						g.logger.Infof("🔍 [GEMINI] Converted ToolCallResponse via JSON fallback, ToolCallID=%s, Name=%s", toolResp.ToolCallID, toolResp.Name)
 					}
-					responseMap := map[string]interface{}{
-						"result": toolResp.Content,
-					}
-					genaiPart := genai.NewPartFromFunctionResponse(toolResp.ToolCallID, responseMap)
+					genaiPart := newFunctionResponsePart(toolResp)
 					if genaiPart != nil {
 						genaiParts = append(genaiParts, genaiPart)
 						continue
@@ -1633,6 +1905,39 @@ func (g *GoogleGenAIAdapter) convertMessageParts(parts []llmtypes.ContentPart, m
 	return genaiParts
 }
 
+// functionResponseMap builds the function response payload for a tool result.
+// Gemini expects failures under the "error" key rather than "result".
+func functionResponseMap(toolResp llmtypes.ToolCallResponse) map[string]interface{} {
+	if toolResp.IsError {
+		return map[string]interface{}{"error": toolResp.Text()}
+	}
+	return map[string]interface{}{"result": toolResp.Text()}
+}
+
+// attachToolResultImages adds the images of a tool result to a function response part.
+// Gemini 3 models accept images inside the function response; for older models the
+// images are returned as separate parts to follow the function response.
+func (g *GoogleGenAIAdapter) attachToolResultImages(functionResponsePart *genai.Part, toolResp llmtypes.ToolCallResponse, modelID string) []*genai.Part {
+	var extraParts []*genai.Part
+	for _, img := range toolResp.Images() {
+		imagePart := g.createImagePart(img)
+		if imagePart == nil || imagePart.InlineData == nil {
+			continue
+		}
+		if strings.Contains(strings.ToLower(modelID), "gemini-3") {
+			functionResponsePart.FunctionResponse.Parts = append(functionResponsePart.FunctionResponse.Parts, &genai.FunctionResponsePart{
+				InlineData: &genai.FunctionResponseBlob{
+					MIMEType: imagePart.InlineData.MIMEType,
+					Data:     imagePart.InlineData.Data,
+				},
+			})
+		} else {
+			extraParts = append(extraParts, imagePart)
+		}
+	}
+	return extraParts
+}
+
 // parseJSONObject parses a JSON string into a map
 func parseJSONObject(jsonStr string) map[string]interface{} {
 	var result map[string]interface{}
@@ -1664,7 +1969,7 @@ func (g *GoogleGenAIAdapter) logInputDetails(requestID, modelID string, messages
 			if textPart, ok := msg.Parts[0].(llmtypes.TextContent); ok {
 				content := textPart.Text
 				if len(content) > 200 {
-					contentPreview = content[:200] + "..."
+					contentPreview = utils.TruncateUTF8(content, 200) + "..."
 				} else {
 					contentPreview = content
 				}
@@ -1755,7 +2060,7 @@ func (g *GoogleGenAIAdapter) logErrorDetails(requestID, modelID string, messages
 					if part.Text != "" {
 						text := part.Text
 						if len(text) > 500 {
-							responsePreview = text[:500] + "..."
+							responsePreview = utils.TruncateUTF8(text, 500) + "..."
 						} else {
 							responsePreview = text
 						}
@@ -1814,7 +2119,7 @@ func (g *GoogleGenAIAdapter) logRawResponse(requestID, modelID string, result *g
 				if part.Text != "" {
 					textPreview := part.Text
 					if len(textPreview) > 200 {
-						textPreview = textPreview[:200] + "..."
+						textPreview = utils.TruncateUTF8(textPreview, 200) + "..."
 					}
 					g.logger.Infof("🔍 [REQUEST_ID: %s]      Part %d - Text: %q (length: %d)", requestID, j, textPreview, len(part.Text))
 				}
@@ -1822,7 +2127,7 @@ func (g *GoogleGenAIAdapter) logRawResponse(requestID, modelID string, result *g
 					// Log full FunctionCall arguments as JSON
 					argsJSON := convertArgumentsToString(part.FunctionCall.Args)
 					if len(argsJSON) > 1000 {
-						argsPreview := argsJSON[:1000] + "... (truncated, total length: " + fmt.Sprintf("%d", len(argsJSON)) + " bytes)"
+						argsPreview := utils.TruncateUTF8(argsJSON, 1000) + "... (truncated, total length: " + fmt.Sprintf("%d", len(argsJSON)) + " bytes)"
 						g.logger.Infof("🔍 [REQUEST_ID: %s]      Part %d - FunctionCall: Name=%q, Args=%s", requestID, j, part.FunctionCall.Name, argsPreview)
 					} else {
 						g.logger.Infof("🔍 [REQUEST_ID: %s]      Part %d - FunctionCall: Name=%q, Args=%s", requestID, j, part.FunctionCall.Name, argsJSON)
@@ -1909,7 +2214,7 @@ func (g *GoogleGenAIAdapter) logRawResponse(requestID, modelID string, result *g
 	if summaryJSON, err := json.MarshalIndent(summary, "   ", "  "); err == nil {
 		jsonStr := string(summaryJSON)
 		if len(jsonStr) > 5000 {
-			jsonStr = jsonStr[:5000] + "\n   ... (truncated)"
+			jsonStr = utils.TruncateUTF8(jsonStr, 5000) + "\n   ... (truncated)"
 		}
 		g.logger.Infof("🔍 [REQUEST_ID: %s] RAW VERTEX RESPONSE SUMMARY (JSON):\n   %s", requestID, jsonStr)
 	} else {
@@ -1923,7 +2228,7 @@ func (g *GoogleGenAIAdapter) logRawResponse(requestID, modelID string, result *g
 		// For very large responses, truncate but keep important parts
 		if len(jsonStr) > 10000 {
 			// Keep first 5000 chars and last 5000 chars
-			jsonStr = jsonStr[:5000] + "\n   ... (truncated, total length: " + fmt.Sprintf("%d", len(jsonStr)) + " bytes) ...\n   " + jsonStr[len(jsonStr)-5000:]
+			jsonStr = utils.TruncateUTF8(jsonStr, 5000) + "\n   ... (truncated, total length: " + fmt.Sprintf("%d", len(jsonStr)) + " bytes) ...\n   " + jsonStr[len(jsonStr)-5000:]
 		}
 		g.logger.Infof("🔍 [REQUEST_ID: %s] COMPLETE RAW VERTEX API RESPONSE (FULL JSON):\n   %s", requestID, jsonStr)
 	} else {
@@ -1974,7 +2279,7 @@ func (g *GoogleGenAIAdapter) createImagePart(img llmtypes.ImageContent) *genai.P
 		// Note: context is not available here, use background context with timeout
 		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 		defer cancel()
-		imageBytes, mimeType, err := g.fetchImageFromURL(ctx, img.Data)
+		imageBytes, mimeType, err := utils.FetchImage(ctx, img.Data)
 		if err != nil {
 			if g.logger != nil {
 				g.logger.Debugf("Failed to fetch image from URL %s: %v", img.Data, err)
@@ -1994,62 +2299,6 @@ func (g *GoogleGenAIAdapter) createImagePart(img llmtypes.ImageContent) *genai.P
 	return nil
 }
 
-// fetchImageFromURL fetches an image from a URL and returns the bytes and MIME type
-func (g *GoogleGenAIAdapter) fetchImageFromURL(ctx context.Context, url string) ([]byte, string, error) {
-	// Create HTTP client with timeout
-	client := &http.Client{
-		Timeout: 30 * time.Second,
-	}
-
-	// Fetch the image with context
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-	if err != nil {
-		return nil, "", fmt.Errorf("failed to create request: %w", err)
-	}
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, "", fmt.Errorf("failed to fetch image: %w", err)
-	}
-	defer resp.Body.Close()
-
-	// Check status code
-	if resp.StatusCode != http.StatusOK {
-		return nil, "", fmt.Errorf("unexpected status code: %d", resp.StatusCode)
-	}
-
-	// Read image data
-	imageBytes, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, "", fmt.Errorf("failed to read image data: %w", err)
-	}
-
-	// Detect MIME type from Content-Type header or URL extension
-	mimeType := resp.Header.Get("Content-Type")
-	if mimeType == "" || !strings.HasPrefix(mimeType, "image/") {
-		// Try to detect from URL extension
-		urlLower := strings.ToLower(url)
-		if strings.HasSuffix(urlLower, ".jpg") || strings.HasSuffix(urlLower, ".jpeg") {
-			mimeType = "image/jpeg"
-		} else if strings.HasSuffix(urlLower, ".png") {
-			mimeType = "image/png"
-		} else if strings.HasSuffix(urlLower, ".gif") {
-			mimeType = "image/gif"
-		} else if strings.HasSuffix(urlLower, ".webp") {
-			mimeType = "image/webp"
-		} else {
-			// Default to JPEG if we can't determine
-			mimeType = "image/jpeg"
-		}
-	}
-
-	// Clean up MIME type (remove charset if present)
-	if idx := strings.Index(mimeType, ";"); idx != -1 {
-		mimeType = mimeType[:idx]
-	}
-
-	return imageBytes, strings.TrimSpace(mimeType), nil
-}
-
 // extractThoughtSignature extracts thought signature from genai.Part's ExtraContent
 // Thought signatures are in extra_content.google.thought_signature according to Gemini API docs
 func extractThoughtSignature(part *genai.Part, logger interfaces.Logger) string {
@@ -2127,11 +2376,7 @@ func getMapKeysForDebug(m map[string]interface{}) []string {
 	return keys
 }
 
-// generateToolCallID generates a unique ID for tool calls
-// In a real implementation, you might want to use a proper ID generator
-var toolCallCounter int64 = 0
-
-func generateToolCallID() string {
-	toolCallCounter++
-	return fmt.Sprintf("call_%d", toolCallCounter)
+// supportsThinking reports whether modelID is a Gemini thinking model (2.5 and later)
+func supportsThinking(modelID string) bool {
+	return strings.Contains(modelID, "gemini-2.5") || strings.Contains(modelID, "gemini-3")
 }
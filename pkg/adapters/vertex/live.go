@@ -0,0 +1,215 @@
+package vertex
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/manishiitg/multi-llm-provider-go/interfaces"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+
+	"google.golang.org/genai"
+)
+
+// liveAudioMediaType is the audio output format of the Live API (16-bit PCM, 24kHz, mono)
+const liveAudioMediaType = "audio/pcm;rate=24000"
+
+// LiveSession is a llmtypes.RealtimeSession over the Gemini Live API
+type LiveSession struct {
+	session *genai.Session
+	events  chan llmtypes.RealtimeEvent
+	logger  interfaces.Logger
+	writeMu sync.Mutex
+	once    sync.Once
+	done    chan struct{}
+}
+
+// NewLiveSession connects to the Gemini Live API with modelID (e.g.
+// "gemini-2.0-flash-live-001") and configures the session from opts
+func NewLiveSession(ctx context.Context, client *genai.Client, modelID string, logger interfaces.Logger, opts ...llmtypes.RealtimeOption) (*LiveSession, error) {
+	options := &llmtypes.RealtimeOptions{}
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	session, err := client.Live.Connect(ctx, modelID, liveConnectConfig(options))
+	if err != nil {
+		return nil, fmt.Errorf("gemini live connect failed: %w", err)
+	}
+
+	s := &LiveSession{
+		session: session,
+		events:  make(chan llmtypes.RealtimeEvent, 64),
+		logger:  logger,
+		done:    make(chan struct{}),
+	}
+	go s.readLoop()
+	return s, nil
+}
+
+// liveConnectConfig builds the Live API setup from options
+func liveConnectConfig(options *llmtypes.RealtimeOptions) *genai.LiveConnectConfig {
+	config := &genai.LiveConnectConfig{}
+	modalities := options.Modalities
+	if len(modalities) == 0 {
+		modalities = []string{"text"}
+	}
+	for _, modality := range modalities {
+		config.ResponseModalities = append(config.ResponseModalities, genai.Modality(strings.ToUpper(modality)))
+		if strings.EqualFold(modality, "audio") {
+			config.OutputAudioTranscription = &genai.AudioTranscriptionConfig{}
+		}
+	}
+	if options.Instructions != "" {
+		config.SystemInstruction = &genai.Content{Parts: []*genai.Part{{Text: options.Instructions}}}
+	}
+	if options.Voice != "" {
+		config.SpeechConfig = &genai.SpeechConfig{
+			VoiceConfig: &genai.VoiceConfig{PrebuiltVoiceConfig: &genai.PrebuiltVoiceConfig{VoiceName: options.Voice}},
+		}
+	}
+	if options.InputTranscription {
+		config.InputAudioTranscription = &genai.AudioTranscriptionConfig{}
+	}
+	return config
+}
+
+// SendText sends a complete user turn
+func (s *LiveSession) SendText(ctx context.Context, text string) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	err := s.session.SendClientContent(genai.LiveClientContentInput{
+		Turns: []*genai.Content{{Role: genai.RoleUser, Parts: []*genai.Part{{Text: text}}}},
+	})
+	if err != nil {
+		return fmt.Errorf("gemini live send failed: %w", err)
+	}
+	return nil
+}
+
+// SendAudio streams base64 16-bit PCM audio (16kHz unless MediaType says otherwise, e.g.
+// "audio/pcm;rate=24000"). The server detects the end of speech and responds.
+func (s *LiveSession) SendAudio(ctx context.Context, audio llmtypes.AudioContent) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	data, err := base64.StdEncoding.DecodeString(audio.Data)
+	if err != nil {
+		return fmt.Errorf("invalid base64 audio data: %w", err)
+	}
+	mediaType := audio.MediaType
+	if mediaType == "" {
+		mediaType = "audio/pcm;rate=16000"
+	}
+	s.writeMu.Lock()
+	defer s.writeMu.Unlock()
+	if err := s.session.SendRealtimeInput(genai.LiveRealtimeInput{Audio: &genai.Blob{MIMEType: mediaType, Data: data}}); err != nil {
+		return fmt.Errorf("gemini live send failed: %w", err)
+	}
+	return nil
+}
+
+// Events returns the session events; the channel is closed when the connection ends
+func (s *LiveSession) Events() <-chan llmtypes.RealtimeEvent {
+	return s.events
+}
+
+// Close closes the connection
+func (s *LiveSession) Close() error {
+	var err error
+	s.once.Do(func() {
+		close(s.done)
+		err = s.session.Close()
+	})
+	return err
+}
+
+// readLoop maps server messages to RealtimeEvents until the connection ends
+func (s *LiveSession) readLoop() {
+	defer close(s.events)
+	for {
+		message, err := s.session.Receive()
+		if err != nil {
+			select {
+			case <-s.done:
+			default:
+				s.emit(llmtypes.RealtimeEvent{Type: llmtypes.RealtimeEventError, Text: err.Error(), Err: fmt.Errorf("gemini live receive failed: %w", err)})
+			}
+			return
+		}
+		for _, event := range mapLiveMessage(message) {
+			if !s.emit(event) {
+				return
+			}
+		}
+	}
+}
+
+// emit delivers an event unless the session was closed
+func (s *LiveSession) emit(event llmtypes.RealtimeEvent) bool {
+	select {
+	case s.events <- event:
+		return true
+	case <-s.done:
+		return false
+	}
+}
+
+// mapLiveMessage converts a Live API server message to RealtimeEvents. Tool calls are not
+// supported yet and are dropped.
+func mapLiveMessage(message *genai.LiveServerMessage) []llmtypes.RealtimeEvent {
+	var events []llmtypes.RealtimeEvent
+	if message.SetupComplete != nil {
+		events = append(events, llmtypes.RealtimeEvent{Type: llmtypes.RealtimeEventSessionStarted})
+	}
+
+	content := message.ServerContent
+	if content == nil {
+		return events
+	}
+	if content.InputTranscription != nil && content.InputTranscription.Text != "" {
+		events = append(events, llmtypes.RealtimeEvent{Type: llmtypes.RealtimeEventInputTranscript, Text: content.InputTranscription.Text})
+	}
+	if content.ModelTurn != nil {
+		for _, part := range content.ModelTurn.Parts {
+			switch {
+			case part == nil || part.Thought:
+			case part.InlineData != nil && strings.HasPrefix(part.InlineData.MIMEType, "audio/"):
+				events = append(events, llmtypes.RealtimeEvent{
+					Type:  llmtypes.RealtimeEventAudioDelta,
+					Audio: &llmtypes.AudioContent{MediaType: liveMediaType(part.InlineData.MIMEType), Data: base64.StdEncoding.EncodeToString(part.InlineData.Data)},
+				})
+			case part.Text != "":
+				events = append(events, llmtypes.RealtimeEvent{Type: llmtypes.RealtimeEventTextDelta, Text: part.Text})
+			}
+		}
+	}
+	if content.OutputTranscription != nil && content.OutputTranscription.Text != "" {
+		events = append(events, llmtypes.RealtimeEvent{Type: llmtypes.RealtimeEventTranscriptDelta, Text: content.OutputTranscription.Text})
+	}
+	if content.Interrupted {
+		events = append(events, llmtypes.RealtimeEvent{Type: llmtypes.RealtimeEventInterrupted})
+	}
+	if content.TurnComplete {
+		done := llmtypes.RealtimeEvent{Type: llmtypes.RealtimeEventTurnComplete}
+		if u := message.UsageMetadata; u != nil {
+			done.Usage = &llmtypes.Usage{InputTokens: int(u.PromptTokenCount), OutputTokens: int(u.ResponseTokenCount), TotalTokens: int(u.TotalTokenCount)}
+		}
+		events = append(events, done)
+	}
+	return events
+}
+
+// liveMediaType returns the audio MIME type reported by the server, defaulting the format
+// of the Live API output
+func liveMediaType(mimeType string) string {
+	if mimeType == "" || mimeType == "audio/pcm" {
+		return liveAudioMediaType
+	}
+	return mimeType
+}
@@ -0,0 +1,109 @@
+package vertex
+
+import (
+	"encoding/base64"
+	"encoding/json"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+	"github.com/manishiitg/multi-llm-provider-go/pkg/utils"
+	"google.golang.org/genai"
+)
+
+// ToGeminiContents converts llmtypes messages to Gemini contents for the given model.
+// This is the exact conversion used by GoogleGenAIAdapter.GenerateContent, including
+// combining consecutive tool responses and splitting mixed text/tool-call messages.
+func ToGeminiContents(messages []llmtypes.MessageContent, modelID string) []*genai.Content {
+	adapter := &GoogleGenAIAdapter{modelID: modelID}
+	contents, _ := adapter.convertMessages(messages, modelID)
+	return contents
+}
+
+// FromGeminiContents converts Gemini contents back to llmtypes messages.
+// Gemini matches function responses to function calls by position, so tool call IDs
+// are taken from the function call (or generated) and assigned to responses in order.
+func FromGeminiContents(contents []*genai.Content) ([]llmtypes.MessageContent, error) {
+	result := make([]llmtypes.MessageContent, 0, len(contents))
+
+	// Tool calls from the most recent model turn, in order
+	var pendingCalls []llmtypes.ToolCall
+
+	for _, content := range contents {
+		if content == nil {
+			continue
+		}
+
+		var parts []llmtypes.ContentPart
+		var toolResponses []llmtypes.ContentPart
+		var calls []llmtypes.ToolCall
+		var ids utils.ToolCallIDs
+
+		for _, part := range content.Parts {
+			if part == nil || part.Thought {
+				continue
+			}
+			switch {
+			case part.FunctionCall != nil:
+				toolCall := llmtypes.ToolCall{
+					ID:               part.FunctionCall.ID,
+					Type:             "function",
+					ThoughtSignature: extractThoughtSignature(part, nil),
+					FunctionCall: &llmtypes.FunctionCall{
+						Name:      part.FunctionCall.Name,
+						Arguments: convertArgumentsToString(part.FunctionCall.Args),
+					},
+				}
+				ids.Assign(&toolCall)
+				calls = append(calls, toolCall)
+				parts = append(parts, toolCall)
+			case part.FunctionResponse != nil:
+				response := llmtypes.ToolCallResponse{
+					ToolCallID: part.FunctionResponse.ID,
+					Name:       part.FunctionResponse.Name,
+				}
+				if index := len(toolResponses); index < len(pendingCalls) {
+					response.ToolCallID = pendingCalls[index].ID
+					response.Name = pendingCalls[index].FunctionCall.Name
+				}
+				if text, ok := part.FunctionResponse.Response["result"].(string); ok && len(part.FunctionResponse.Response) == 1 {
+					response.Content = text
+				} else if text, ok := part.FunctionResponse.Response["error"].(string); ok && len(part.FunctionResponse.Response) == 1 {
+					response.Content = text
+					response.IsError = true
+				} else if data, err := json.Marshal(part.FunctionResponse.Response); err == nil {
+					response.Content = string(data)
+				}
+				toolResponses = append(toolResponses, response)
+			case part.InlineData != nil:
+				parts = append(parts, llmtypes.ImageContent{
+					SourceType: "base64",
+					MediaType:  part.InlineData.MIMEType,
+					Data:       base64.StdEncoding.EncodeToString(part.InlineData.Data),
+				})
+			case part.FileData != nil:
+				parts = append(parts, llmtypes.ImageContent{
+					SourceType: "url",
+					MediaType:  part.FileData.MIMEType,
+					Data:       part.FileData.FileURI,
+				})
+			case part.Text != "":
+				parts = append(parts, llmtypes.TextContent{Text: part.Text})
+			}
+		}
+
+		if content.Role == "model" {
+			pendingCalls = calls
+			result = append(result, llmtypes.MessageContent{Role: llmtypes.ChatMessageTypeAI, Parts: parts})
+			continue
+		}
+
+		if len(toolResponses) > 0 {
+			pendingCalls = nil
+			result = append(result, llmtypes.MessageContent{Role: llmtypes.ChatMessageTypeTool, Parts: toolResponses})
+		}
+		if len(parts) > 0 {
+			result = append(result, llmtypes.MessageContent{Role: llmtypes.ChatMessageTypeHuman, Parts: parts})
+		}
+	}
+
+	return result, nil
+}
@@ -13,6 +13,7 @@ import (
 
 	"github.com/manishiitg/multi-llm-provider-go/interfaces"
 	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+	"github.com/manishiitg/multi-llm-provider-go/pkg/utils"
 )
 
 // VertexAnthropicAdapter implements llmtypes.Model for Vertex AI Anthropic models
@@ -42,6 +43,12 @@ func (v *VertexAnthropicAdapter) GetModelID() string {
 	return v.modelID
 }
 
+// SetHTTPClient replaces the HTTP client used for Vertex AI requests, e.g. to tune its
+// connection pool. The default client has a 5 minute timeout.
+func (v *VertexAnthropicAdapter) SetHTTPClient(client *http.Client) {
+	v.httpClient = client
+}
+
 // GenerateContent implements the llmtypes.Model interface
 func (v *VertexAnthropicAdapter) GenerateContent(ctx context.Context, messages []llmtypes.MessageContent, options ...llmtypes.CallOption) (*llmtypes.ContentResponse, error) {
 	// Parse call options
@@ -50,10 +57,43 @@ func (v *VertexAnthropicAdapter) GenerateContent(ctx context.Context, messages [
 		opt(opts)
 	}
 
-	// Get access token
-	accessToken, err := GetAccessToken(ctx, v.logger)
+	// Apply the assistant prefill
+	if opts.AssistantPrefill != "" {
+		return utils.GenerateWithPrefill(ctx, v, messages, options, true)
+	}
+
+	// Stop at the first complete tool call when requested
+	if opts.AbortOnToolCall && opts.StreamChan != nil {
+		return utils.GenerateUntilToolCall(ctx, v, messages, options)
+	}
+
+	// Anthropic on Vertex AI has no native n>1, emulate it with concurrent requests
+	if opts.N > 1 {
+		return utils.GenerateChoicesConcurrently(ctx, v, opts.N, messages, options)
+	}
+
+	// Continue output cut off at the token limit when requested
+	if opts.AutoContinue > 0 {
+		return utils.GenerateWithAutoContinue(ctx, v, messages, options, true)
+	}
+
+	// Detect the media type of base64 images sent without one, converting unsupported formats
+	// when enabled
+	messages, err := utils.ResolveImageMediaTypes(messages, utils.AnthropicImageLimits, opts.ImageAutoConvert)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get access token: %w", err)
+		return nil, err
+	}
+
+	// Check tool result images against the provider limits, downscaling when allowed
+	messages, err = utils.PrepareToolResultImages(messages, utils.AnthropicImageLimits, opts.ToolResultImageMaxBytes)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check the number and total size of images against the per-request limits
+	messages, err = utils.CheckImagePayload(messages, utils.AnthropicImageLimits, opts.MaxImageCount, opts.ImageDownsampling)
+	if err != nil {
+		return nil, err
 	}
 
 	// Handle JSON mode by adding instructions to messages (similar to direct Anthropic adapter)
@@ -82,6 +122,24 @@ func (v *VertexAnthropicAdapter) GenerateContent(ctx context.Context, messages [
 	if len(opts.Tools) > 0 {
 		tools := v.convertToolsToAnthropic(opts.Tools)
 		requestPayload["tools"] = tools
+		if toolChoice := convertAnthropicToolChoice(opts.ToolChoice); toolChoice != nil {
+			requestPayload["tool_choice"] = toolChoice
+		}
+	}
+
+	// Service tiers are not supported by Anthropic on Vertex AI
+	if opts.ServiceTier != "" && v.logger != nil {
+		v.logger.Debugf("Service tier %q is not supported by Vertex AI Anthropic, ignoring", opts.ServiceTier)
+	}
+
+	// Provider-native parameters are applied last and win over the generic options
+	if opts.AnthropicParams != nil {
+		applyAnthropicParams(requestPayload, opts.AnthropicParams)
+	}
+
+	// Merge extra body fields; typed fields above win on conflict
+	if len(opts.ExtraBody) > 0 {
+		requestPayload = utils.MergeExtraBody(requestPayload, opts.ExtraBody)
 	}
 
 	// Build endpoint URL
@@ -108,6 +166,20 @@ func (v *VertexAnthropicAdapter) GenerateContent(ctx context.Context, messages [
 		}
 	}
 
+	// Return the constructed request without calling the API
+	if opts.DryRun {
+		return utils.DryRunResponse(opts, map[string]interface{}{
+			"endpoint": endpoint,
+			"payload":  requestPayload,
+		}), nil
+	}
+
+	// Get access token
+	accessToken, err := GetAccessToken(ctx, v.logger)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get access token: %w", err)
+	}
+
 	// Vertex AI requires streaming for Anthropic models, but we accumulate all chunks
 	return v.generateContent(ctx, endpoint, accessToken, requestPayload, opts)
 }
@@ -135,6 +207,10 @@ func (v *VertexAnthropicAdapter) generateContent(ctx context.Context, endpoint,
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
+	// Extra headers are set first so auth and content type always win
+	for key, value := range utils.RequestHeaders(opts) {
+		req.Header.Set(key, value)
+	}
 	req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", accessToken))
 	req.Header.Set("Content-Type", "application/json; charset=utf-8")
 
@@ -150,14 +226,17 @@ func (v *VertexAnthropicAdapter) generateContent(ctx context.Context, endpoint,
 		if v.logger != nil {
 			v.logger.Infof("🔍 [VERTEX ANTHROPIC] Error response (status %d): %s", resp.StatusCode, string(body))
 		}
-		return nil, fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
+		return nil, &utils.HTTPError{StatusCode: resp.StatusCode, Body: string(body)}
 	}
 
 	// Parse streaming response
 	var fullContent strings.Builder
 	var toolCalls []llmtypes.ToolCall
+	var blocks []llmtypes.ContentBlock
 	var currentToolUseBlock map[string]interface{} // Accumulate tool_use block data
 	var partialJSONBuffer strings.Builder          // Accumulate partial_json fragments
+	var stopReason string
+	var inputTokens, outputTokens int
 	scanner := bufio.NewScanner(resp.Body)
 
 	for scanner.Scan() {
@@ -172,6 +251,9 @@ func (v *VertexAnthropicAdapter) generateContent(ctx context.Context, endpoint,
 			if data == "[DONE]" {
 				break
 			}
+			if opts.StreamEventHook != nil {
+				opts.StreamEventHook(json.RawMessage(data))
+			}
 
 			var event map[string]interface{}
 			if err := json.Unmarshal([]byte(data), &event); err != nil {
@@ -185,6 +267,41 @@ func (v *VertexAnthropicAdapter) generateContent(ctx context.Context, endpoint,
 			// Events have a "type" field indicating the event type
 			eventType, _ := event["type"].(string)
 
+			// Capture usage and stop reason from message-level events
+			if eventType == "message_start" {
+				if message, ok := event["message"].(map[string]interface{}); ok {
+					if usageMap, ok := message["usage"].(map[string]interface{}); ok {
+						if tokens, ok := usageMap["input_tokens"].(float64); ok {
+							inputTokens = int(tokens)
+						}
+						if tokens, ok := usageMap["output_tokens"].(float64); ok {
+							outputTokens = int(tokens)
+						}
+						if err := utils.SendUsageChunk(ctx, opts, &llmtypes.Usage{InputTokens: inputTokens, OutputTokens: outputTokens, TotalTokens: inputTokens + outputTokens}); err != nil {
+							return nil, err
+						}
+					}
+				}
+			}
+			if eventType == "message_delta" {
+				if delta, ok := event["delta"].(map[string]interface{}); ok {
+					if reason, ok := delta["stop_reason"].(string); ok && reason != "" {
+						stopReason = reason
+					}
+				}
+				if usageMap, ok := event["usage"].(map[string]interface{}); ok {
+					if tokens, ok := usageMap["input_tokens"].(float64); ok && tokens > 0 {
+						inputTokens = int(tokens)
+					}
+					if tokens, ok := usageMap["output_tokens"].(float64); ok {
+						outputTokens = int(tokens)
+					}
+					if err := utils.SendUsageChunk(ctx, opts, &llmtypes.Usage{InputTokens: inputTokens, OutputTokens: outputTokens, TotalTokens: inputTokens + outputTokens}); err != nil {
+						return nil, err
+					}
+				}
+			}
+
 			// Handle content_block_start events (for tool_use blocks)
 			if eventType == "content_block_start" {
 				if contentBlock, ok := event["content_block"].(map[string]interface{}); ok {
@@ -225,6 +342,7 @@ func (v *VertexAnthropicAdapter) generateContent(ctx context.Context, endpoint,
 					// Text delta
 					if text, ok := delta["text"].(string); ok && text != "" {
 						fullContent.WriteString(text)
+						blocks = llmtypes.AppendTextBlock(blocks, llmtypes.ContentBlockTypeText, text)
 						// Stream content chunks immediately
 						if opts.StreamChan != nil {
 							select {
@@ -238,6 +356,18 @@ func (v *VertexAnthropicAdapter) generateContent(ctx context.Context, endpoint,
 						}
 					}
 
+					// Thinking delta (extended thinking)
+					if thinking, ok := delta["thinking"].(string); ok && thinking != "" && opts.StreamChan != nil && utils.ReasoningVisible(opts) {
+						select {
+						case opts.StreamChan <- llmtypes.StreamChunk{
+							Type:    llmtypes.StreamChunkTypeReasoning,
+							Content: thinking,
+						}:
+						case <-ctx.Done():
+							return nil, ctx.Err()
+						}
+					}
+
 					// Check if this is a tool_use delta (for tool call arguments)
 					if currentToolUseBlock != nil {
 						// Vertex AI sends tool arguments via partial_json in the delta (not in tool_use.partial_input)
@@ -368,6 +498,7 @@ func (v *VertexAnthropicAdapter) generateContent(ctx context.Context, endpoint,
 					}
 					toolCall := v.parseToolUse(currentToolUseBlock)
 					if toolCall != nil {
+						blocks = append(blocks, llmtypes.ContentBlock{Type: llmtypes.ContentBlockTypeToolCall, ToolCallIndex: len(toolCalls)})
 						toolCalls = append(toolCalls, *toolCall)
 						if v.logger != nil {
 							v.logger.Infof("🔧 [VERTEX ANTHROPIC] Tool call detected: %s, args: %s", toolCall.FunctionCall.Name, toolCall.FunctionCall.Arguments)
@@ -395,6 +526,7 @@ func (v *VertexAnthropicAdapter) generateContent(ctx context.Context, endpoint,
 					if blockType, ok := contentBlock["type"].(string); ok && blockType == "tool_use" {
 						toolCall := v.parseToolUse(contentBlock)
 						if toolCall != nil {
+							blocks = append(blocks, llmtypes.ContentBlock{Type: llmtypes.ContentBlockTypeToolCall, ToolCallIndex: len(toolCalls)})
 							toolCalls = append(toolCalls, *toolCall)
 							if v.logger != nil {
 								v.logger.Infof("🔧 [VERTEX ANTHROPIC] Tool call detected from stop event: %s, args: %s", toolCall.FunctionCall.Name, toolCall.FunctionCall.Arguments)
@@ -424,6 +556,7 @@ func (v *VertexAnthropicAdapter) generateContent(ctx context.Context, endpoint,
 							if blockType == "text" {
 								if text, ok := blockMap["text"].(string); ok && text != "" {
 									fullContent.WriteString(text)
+									blocks = llmtypes.AppendTextBlock(blocks, llmtypes.ContentBlockTypeText, text)
 									// Stream content chunks immediately (legacy format)
 									if opts.StreamChan != nil {
 										select {
@@ -440,6 +573,7 @@ func (v *VertexAnthropicAdapter) generateContent(ctx context.Context, endpoint,
 								// Handle tool calls in legacy format
 								toolCall := v.parseToolUse(blockMap)
 								if toolCall != nil {
+									blocks = append(blocks, llmtypes.ContentBlock{Type: llmtypes.ContentBlockTypeToolCall, ToolCallIndex: len(toolCalls)})
 									toolCalls = append(toolCalls, *toolCall)
 									if v.logger != nil {
 										v.logger.Infof("🔧 [VERTEX ANTHROPIC] Tool call detected (legacy format): %s", toolCall.FunctionCall.Name)
@@ -470,29 +604,54 @@ func (v *VertexAnthropicAdapter) generateContent(ctx context.Context, endpoint,
 	}
 
 	choice := &llmtypes.ContentChoice{
-		Content: fullContent.String(),
+		Content:    fullContent.String(),
+		StopReason: stopReason,
 	}
 	if len(toolCalls) > 0 {
 		choice.ToolCalls = toolCalls
 	}
+	if len(blocks) > 0 {
+		choice.Blocks = blocks
+	}
+
+	// Build GenerationInfo from message_start/message_delta usage (if reported)
+	if inputTokens > 0 || outputTokens > 0 {
+		totalTokens := inputTokens + outputTokens
+		choice.GenerationInfo = &llmtypes.GenerationInfo{
+			InputTokens:  &inputTokens,
+			OutputTokens: &outputTokens,
+			TotalTokens:  &totalTokens,
+		}
+	}
 
 	// Extract usage from GenerationInfo (if available)
-	// Note: vertex_anthropic_adapter may not set GenerationInfo in streaming mode
 	var usage *llmtypes.Usage
 	if choice.GenerationInfo != nil {
 		usage = llmtypes.ExtractUsageFromGenerationInfo(choice.GenerationInfo)
 	}
 
-	// Return accumulated response
-	return &llmtypes.ContentResponse{
+	result := &llmtypes.ContentResponse{
 		Choices: []*llmtypes.ContentChoice{choice},
 		Usage:   usage,
-	}, nil
+	}
+
+	// Send terminal chunk with stop reason and usage before the channel is closed
+	if opts.StreamChan != nil {
+		select {
+		case opts.StreamChan <- llmtypes.NewFinishChunk(result):
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	// Return accumulated response
+	return result, nil
 }
 
 // convertMessagesToAnthropic converts llmtypes messages to Anthropic format
 func (v *VertexAnthropicAdapter) convertMessagesToAnthropic(messages []llmtypes.MessageContent) ([]map[string]interface{}, error) {
 	anthropicMessages := make([]map[string]interface{}, 0, len(messages))
+	messages = utils.PrefixMessageNames(messages)
 	// Track tool call IDs that have already been converted to tool_use blocks in inserted assistant messages
 	// This prevents duplicate tool_use IDs when the original AI message is processed later
 	convertedToolCallIDs := make(map[string]bool)
@@ -787,11 +946,7 @@ func (v *VertexAnthropicAdapter) convertMessagesToAnthropic(messages []llmtypes.
 				// Anthropic uses tool_result format
 				hasToolResults = true
 				toolResultIDs = append(toolResultIDs, p.ToolCallID)
-				content = append(content, map[string]interface{}{
-					"type":        "tool_result",
-					"tool_use_id": p.ToolCallID,
-					"content":     p.Content,
-				})
+				content = append(content, v.createToolResultBlock(p))
 			case llmtypes.ToolCall:
 				// Tool calls in assistant messages should be converted to tool_use blocks
 				if msg.Role == llmtypes.ChatMessageTypeAI {
@@ -897,7 +1052,7 @@ func (v *VertexAnthropicAdapter) convertMessagesToAnthropic(messages []llmtypes.
 													// Truncate base64 image data to 100 chars for logging
 													if dataStr, ok := sv.(string); ok {
 														if len(dataStr) > 100 {
-															logSource[sk] = dataStr[:100] + "... [truncated, total: " + fmt.Sprintf("%d", len(dataStr)) + " chars]"
+															logSource[sk] = utils.TruncateUTF8(dataStr, 100) + "... [truncated, total: " + fmt.Sprintf("%d", len(dataStr)) + " chars]"
 														} else {
 															logSource[sk] = sv
 														}
@@ -1019,18 +1174,59 @@ func (v *VertexAnthropicAdapter) parseToolUse(block map[string]interface{}) *llm
 	}
 }
 
+// convertAnthropicToolChoice converts a tool choice to the Anthropic tool_choice object,
+// or nil to leave the provider default (auto)
+func convertAnthropicToolChoice(toolChoice *llmtypes.ToolChoice) map[string]interface{} {
+	if toolChoice == nil {
+		return nil
+	}
+	if toolChoice.Function != nil && toolChoice.Function.Name != "" {
+		return map[string]interface{}{"type": "tool", "name": toolChoice.Function.Name}
+	}
+	switch {
+	case toolChoice.Type == "required" || toolChoice.Type == "any" || toolChoice.Any:
+		return map[string]interface{}{"type": "any"}
+	case toolChoice.Type == "none" || toolChoice.None:
+		return map[string]interface{}{"type": "none"}
+	case toolChoice.Type == "auto":
+		return map[string]interface{}{"type": "auto"}
+	}
+	return nil
+}
+
 // getMaxTokens returns max tokens from options or default
 func (v *VertexAnthropicAdapter) getMaxTokens(opts *llmtypes.CallOptions) int {
 	if opts.MaxTokens > 0 {
 		return opts.MaxTokens
 	}
-	return 512 // Default
+	return 4096 // Default, matching the Anthropic adapter
+}
+
+// applyAnthropicParams sets the fields of p that are set (WithAnthropicParams) on the
+// request payload
+func applyAnthropicParams(payload map[string]interface{}, p *llmtypes.AnthropicParams) {
+	if p.TopP != nil {
+		payload["top_p"] = *p.TopP
+	}
+	if p.TopK != nil {
+		payload["top_k"] = *p.TopK
+	}
+	if len(p.StopSequences) > 0 {
+		payload["stop_sequences"] = p.StopSequences
+	}
+	if p.UserID != "" {
+		payload["metadata"] = map[string]interface{}{"user_id": p.UserID}
+	}
 }
 
-// getTemperature returns temperature from options or default
+// getTemperature returns temperature from options, within the range Claude accepts, or default
 func (v *VertexAnthropicAdapter) getTemperature(opts *llmtypes.CallOptions) float64 {
 	if opts.Temperature > 0 {
-		return opts.Temperature
+		temperature, clamped := utils.ClampTemperature(opts.Temperature, utils.MaxTemperatureAnthropic)
+		if clamped && v.logger != nil {
+			v.logger.Infof("🌡️  Temperature %.2f is above the Anthropic maximum, using %.1f", opts.Temperature, temperature)
+		}
+		return temperature
 	}
 	return 1.0 // Default
 }
@@ -1098,6 +1294,42 @@ func (v *VertexAnthropicAdapter) Call(ctx context.Context, prompt string, option
 
 // createImageBlock creates an Anthropic image content block from ImageContent
 // Note: Vertex AI Anthropic API uses Anthropic format for images
+// createToolResultBlock converts a tool response to a tool_result block, using a content
+// array of text and image blocks when the response carries multimodal parts
+func (v *VertexAnthropicAdapter) createToolResultBlock(toolResp llmtypes.ToolCallResponse) map[string]interface{} {
+	block := map[string]interface{}{
+		"type":        "tool_result",
+		"tool_use_id": toolResp.ToolCallID,
+	}
+	if toolResp.IsError {
+		block["is_error"] = true
+	}
+	if len(toolResp.Parts) == 0 {
+		block["content"] = toolResp.Content
+		return block
+	}
+
+	var resultContent []map[string]interface{}
+	for _, part := range toolResp.Parts {
+		switch p := part.(type) {
+		case llmtypes.TextContent:
+			if p.Text != "" {
+				resultContent = append(resultContent, map[string]interface{}{"type": "text", "text": p.Text})
+			}
+		case llmtypes.ImageContent:
+			if imageBlock := v.createImageBlock(p); imageBlock != nil {
+				resultContent = append(resultContent, imageBlock)
+			}
+		}
+	}
+	if len(resultContent) == 0 {
+		block["content"] = toolResp.Content
+		return block
+	}
+	block["content"] = resultContent
+	return block
+}
+
 func (v *VertexAnthropicAdapter) createImageBlock(img llmtypes.ImageContent) map[string]interface{} {
 	if img.SourceType == "base64" {
 		// Anthropic format for base64 images
@@ -1120,7 +1352,7 @@ func (v *VertexAnthropicAdapter) createImageBlock(img llmtypes.ImageContent) map
 		// Note: context is not available here, use background context with timeout
 		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
 		defer cancel()
-		imageBytes, mediaType, err := v.fetchImageFromURL(ctx, img.Data)
+		imageBytes, mediaType, err := utils.FetchImage(ctx, img.Data)
 		if err != nil {
 			if v.logger != nil {
 				v.logger.Errorf("Failed to fetch image from URL: %v", err)
@@ -1160,59 +1392,3 @@ func getMapKeys(m map[string]interface{}) []string {
 	}
 	return keys
 }
-
-// fetchImageFromURL fetches an image from a URL and returns the bytes and MIME type
-func (v *VertexAnthropicAdapter) fetchImageFromURL(ctx context.Context, url string) ([]byte, string, error) {
-	// Create HTTP client with timeout
-	client := &http.Client{
-		Timeout: 30 * time.Second,
-	}
-
-	// Fetch the image with context
-	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
-	if err != nil {
-		return nil, "", fmt.Errorf("failed to create request: %w", err)
-	}
-	resp, err := client.Do(req)
-	if err != nil {
-		return nil, "", fmt.Errorf("failed to fetch image: %w", err)
-	}
-	defer resp.Body.Close()
-
-	// Check status code
-	if resp.StatusCode != http.StatusOK {
-		return nil, "", fmt.Errorf("unexpected status code: %d", resp.StatusCode)
-	}
-
-	// Read image data
-	imageBytes, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, "", fmt.Errorf("failed to read image data: %w", err)
-	}
-
-	// Detect MIME type from Content-Type header or URL extension
-	mimeType := resp.Header.Get("Content-Type")
-	if mimeType == "" || !strings.HasPrefix(mimeType, "image/") {
-		// Try to detect from URL extension
-		urlLower := strings.ToLower(url)
-		if strings.HasSuffix(urlLower, ".jpg") || strings.HasSuffix(urlLower, ".jpeg") {
-			mimeType = "image/jpeg"
-		} else if strings.HasSuffix(urlLower, ".png") {
-			mimeType = "image/png"
-		} else if strings.HasSuffix(urlLower, ".gif") {
-			mimeType = "image/gif"
-		} else if strings.HasSuffix(urlLower, ".webp") {
-			mimeType = "image/webp"
-		} else {
-			// Default to JPEG if we can't determine
-			mimeType = "image/jpeg"
-		}
-	}
-
-	// Clean up MIME type (remove charset if present)
-	if idx := strings.Index(mimeType, ";"); idx != -1 {
-		mimeType = mimeType[:idx]
-	}
-
-	return imageBytes, strings.TrimSpace(mimeType), nil
-}
@@ -0,0 +1,55 @@
+package agent
+
+import (
+	"time"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+// LoopEventType identifies the kind of LoopEvent
+type LoopEventType string
+
+const (
+	LoopEventTurnStart    LoopEventType = "turn_start"    // A model turn is about to be generated
+	LoopEventContentDelta LoopEventType = "content_delta" // Streamed text from the current model turn
+	LoopEventToolCall     LoopEventType = "tool_call"     // The model requested a tool call
+	LoopEventToolStart    LoopEventType = "tool_start"    // A tool handler started executing
+	LoopEventToolEnd      LoopEventType = "tool_end"      // A tool handler finished executing
+	LoopEventComplete     LoopEventType = "complete"      // The loop finished, successfully or not
+)
+
+// LoopEvent reports progress of a running Loop
+type LoopEvent struct {
+	Type LoopEventType
+	// Turn is the 1-based model turn the event belongs to
+	Turn int
+	// Chunk is the streamed content (LoopEventContentDelta)
+	Chunk *llmtypes.StreamChunk
+	// ToolCall is the tool call (LoopEventToolCall, LoopEventToolStart and LoopEventToolEnd)
+	ToolCall *llmtypes.ToolCall
+	// ToolResult is the result sent back to the model (LoopEventToolEnd)
+	ToolResult *llmtypes.ToolCallResponse
+	// Duration is how long the tool handler ran (LoopEventToolEnd)
+	Duration time.Duration
+	// Result and Err are the return values of Run (LoopEventComplete)
+	Result *Result
+	Err    error
+}
+
+// WithLoopObserver streams every model turn and reports loop progress to observer.
+// Events are delivered one at a time, so observer does not need to be safe for concurrent use.
+func WithLoopObserver(observer func(LoopEvent)) Option {
+	return func(l *Loop) {
+		l.observer = observer
+	}
+}
+
+// emit delivers event to the observer, if any
+func (l *Loop) emit(event LoopEvent) {
+	if l.observer == nil {
+		return
+	}
+	l.observerMu.Lock()
+	defer l.observerMu.Unlock()
+	l.observer(event)
+}
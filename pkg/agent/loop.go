@@ -0,0 +1,313 @@
+// Package agent runs the generate → execute tools → feed results loop on top of any
+// llmtypes.Model, with a guardrail on the number of tool-calling iterations.
+//
+//	loop := agent.NewLoop(llm, map[string]agent.ToolFunc{
+//		"get_weather": getWeather,
+//	}, agent.WithCallOptions(llmtypes.WithTools(tools)))
+//	result, err := loop.Run(ctx, messages)
+//
+// Tool definitions are passed to the model through WithCallOptions; the registry only
+// maps tool names to their implementations.
+package agent
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+	"github.com/manishiitg/multi-llm-provider-go/pkg/history"
+	"github.com/manishiitg/multi-llm-provider-go/pkg/utils"
+)
+
+// DefaultMaxToolIterations is the number of tool-calling iterations allowed when
+// WithMaxToolIterations is not set
+const DefaultMaxToolIterations = 10
+
+// DefaultMaxConcurrentTools is the number of tool calls executed at once when
+// WithMaxConcurrentTools is not set
+const DefaultMaxConcurrentTools = 4
+
+// ToolFunc executes a tool with the JSON arguments chosen by the model.
+// A returned error is sent back to the model as an errored tool result.
+type ToolFunc func(ctx context.Context, args json.RawMessage) (string, error)
+
+// Option configures a Loop
+type Option func(*Loop)
+
+// WithMaxToolIterations limits how many model turns may call tools before Run gives up
+func WithMaxToolIterations(n int) Option {
+	return func(l *Loop) {
+		l.maxIterations = n
+	}
+}
+
+// WithMaxConcurrentTools limits how many tool calls from one model turn run at the same time.
+// Use 1 to execute tool calls sequentially.
+func WithMaxConcurrentTools(n int) Option {
+	return func(l *Loop) {
+		l.maxConcurrentTools = n
+	}
+}
+
+// WithToolTimeout limits how long each tool handler may run. A handler that runs longer has
+// its context cancelled and the model is sent an errored tool result, so it can adapt instead
+// of the loop hanging. Handlers should return once their context is done.
+func WithToolTimeout(d time.Duration) Option {
+	return func(l *Loop) {
+		l.toolTimeout = d
+	}
+}
+
+// WithToolTimeoutFor overrides the WithToolTimeout limit for the tool called name.
+// Use 0 to let that tool run without a limit.
+func WithToolTimeoutFor(name string, d time.Duration) Option {
+	return func(l *Loop) {
+		if l.toolTimeouts == nil {
+			l.toolTimeouts = make(map[string]time.Duration)
+		}
+		l.toolTimeouts[name] = d
+	}
+}
+
+// WithCallOptions sets the call options used for every model turn (tools, model, temperature, ...)
+func WithCallOptions(options ...llmtypes.CallOption) Option {
+	return func(l *Loop) {
+		l.callOptions = append(l.callOptions, options...)
+	}
+}
+
+// Loop drives a model through tool calls until it produces a final answer
+type Loop struct {
+	model              llmtypes.Model
+	tools              map[string]ToolFunc
+	maxIterations      int
+	maxConcurrentTools int
+	callOptions        []llmtypes.CallOption
+	toolTimeout        time.Duration
+	toolTimeouts       map[string]time.Duration
+
+	observer   func(LoopEvent)
+	observerMu sync.Mutex
+}
+
+// Result is the outcome of Run
+type Result struct {
+	// Content is the text of the final model turn
+	Content string
+	// Messages is the full transcript: the input messages followed by every model turn and tool result
+	Messages []llmtypes.MessageContent
+	// Response is the last response returned by the model
+	Response *llmtypes.ContentResponse
+	// Iterations is the number of model turns that called tools
+	Iterations int
+}
+
+// NewLoop creates a Loop for model with the given tool registry
+func NewLoop(model llmtypes.Model, tools map[string]ToolFunc, opts ...Option) *Loop {
+	l := &Loop{
+		model:              model,
+		tools:              tools,
+		maxIterations:      DefaultMaxToolIterations,
+		maxConcurrentTools: DefaultMaxConcurrentTools,
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// Run generates responses and executes the requested tools until the model stops calling
+// tools. If the model is still calling tools after the maximum number of iterations, the
+// transcript so far is returned along with an error.
+func (l *Loop) Run(ctx context.Context, messages []llmtypes.MessageContent) (*Result, error) {
+	result, err := l.run(ctx, messages)
+	l.emit(LoopEvent{Type: LoopEventComplete, Turn: result.Iterations + 1, Result: result, Err: err})
+	return result, err
+}
+
+// run implements Run
+func (l *Loop) run(ctx context.Context, messages []llmtypes.MessageContent) (*Result, error) {
+	result := &Result{
+		Messages: append([]llmtypes.MessageContent(nil), messages...),
+	}
+
+	for {
+		result.Messages = history.NormalizeHistory(result.Messages)
+		turn := result.Iterations + 1
+		l.emit(LoopEvent{Type: LoopEventTurnStart, Turn: turn})
+		resp, err := l.generate(ctx, turn, result.Messages)
+		if err != nil {
+			return result, fmt.Errorf("agent loop turn %d: %w", result.Iterations+1, err)
+		}
+		if resp == nil || len(resp.Choices) == 0 || resp.Choices[0] == nil {
+			return result, fmt.Errorf("agent loop turn %d: no choices in response", result.Iterations+1)
+		}
+		result.Response = resp
+		choice := resp.Choices[0]
+		result.Content = choice.Content
+
+		if assistant := assistantMessage(choice); len(assistant.Parts) > 0 {
+			result.Messages = append(result.Messages, assistant)
+		}
+		if len(choice.ToolCalls) == 0 {
+			return result, nil
+		}
+		if result.Iterations >= l.maxIterations {
+			return result, fmt.Errorf("agent loop stopped after %d tool iterations with %d tool call(s) pending", result.Iterations, len(choice.ToolCalls))
+		}
+		result.Iterations++
+		for i := range choice.ToolCalls {
+			l.emit(LoopEvent{Type: LoopEventToolCall, Turn: turn, ToolCall: &choice.ToolCalls[i]})
+		}
+
+		result.Messages = append(result.Messages, llmtypes.MessageContent{
+			Role:  llmtypes.ChatMessageTypeTool,
+			Parts: l.executeTools(ctx, turn, choice.ToolCalls),
+		})
+		if err := ctx.Err(); err != nil {
+			return result, fmt.Errorf("agent loop turn %d: %w", result.Iterations, err)
+		}
+	}
+}
+
+// generate produces one model turn, streaming its content to the observer if one is set
+func (l *Loop) generate(ctx context.Context, turn int, messages []llmtypes.MessageContent) (*llmtypes.ContentResponse, error) {
+	if l.observer == nil {
+		return l.model.GenerateContent(ctx, messages, l.callOptions...)
+	}
+	return utils.GenerateStreaming(ctx, l.model, messages, l.callOptions, func(chunk llmtypes.StreamChunk) {
+		if chunk.Type == llmtypes.StreamChunkTypeContent {
+			l.emit(LoopEvent{Type: LoopEventContentDelta, Turn: turn, Chunk: &chunk})
+		}
+	})
+}
+
+// executeTools runs the tool calls of one turn concurrently (up to maxConcurrentTools at a
+// time) and returns their results in the order of the calls
+func (l *Loop) executeTools(ctx context.Context, turn int, toolCalls []llmtypes.ToolCall) []llmtypes.ContentPart {
+	limit := l.maxConcurrentTools
+	if limit <= 0 || limit > len(toolCalls) {
+		limit = len(toolCalls)
+	}
+
+	results := make([]llmtypes.ContentPart, len(toolCalls))
+	sem := make(chan struct{}, limit)
+	var wg sync.WaitGroup
+	for i, tc := range toolCalls {
+		wg.Add(1)
+		go func(i int, tc llmtypes.ToolCall) {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+				defer func() { <-sem }()
+				l.emit(LoopEvent{Type: LoopEventToolStart, Turn: turn, ToolCall: &tc})
+				start := time.Now()
+				response := l.executeTool(ctx, tc)
+				l.emit(LoopEvent{Type: LoopEventToolEnd, Turn: turn, ToolCall: &tc, ToolResult: &response, Duration: time.Since(start)})
+				results[i] = response
+			case <-ctx.Done():
+				results[i] = llmtypes.ToolCallResponse{
+					ToolCallID: tc.ID,
+					Name:       toolName(tc),
+					Content:    ctx.Err().Error(),
+					IsError:    true,
+				}
+			}
+		}(i, tc)
+	}
+	wg.Wait()
+	return results
+}
+
+// executeTool runs the handler for tc and converts the outcome to a tool result
+func (l *Loop) executeTool(ctx context.Context, tc llmtypes.ToolCall) llmtypes.ToolCallResponse {
+	response := llmtypes.ToolCallResponse{ToolCallID: tc.ID, Name: toolName(tc)}
+	if tc.FunctionCall == nil {
+		response.Content = "tool call has no function"
+		response.IsError = true
+		return response
+	}
+
+	handler, ok := l.tools[tc.FunctionCall.Name]
+	if !ok {
+		response.Content = fmt.Sprintf("unknown tool %q", tc.FunctionCall.Name)
+		response.IsError = true
+		return response
+	}
+
+	args := json.RawMessage(tc.FunctionCall.Arguments)
+	if len(args) == 0 {
+		args = json.RawMessage("{}")
+	}
+	content, err := l.callTool(ctx, tc.FunctionCall.Name, handler, args)
+	if err != nil {
+		response.Content = err.Error()
+		response.IsError = true
+		return response
+	}
+	response.Content = content
+	return response
+}
+
+// callTool runs handler under the timeout of the tool called name, if any. When the timeout
+// expires, the handler's context is cancelled and callTool returns without waiting for it.
+func (l *Loop) callTool(ctx context.Context, name string, handler ToolFunc, args json.RawMessage) (string, error) {
+	timeout := l.toolTimeout
+	if d, ok := l.toolTimeouts[name]; ok {
+		timeout = d
+	}
+	if timeout <= 0 {
+		return handler(ctx, args)
+	}
+
+	toolCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	timedOut := func() bool {
+		return ctx.Err() == nil && errors.Is(toolCtx.Err(), context.DeadlineExceeded)
+	}
+	type outcome struct {
+		content string
+		err     error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		content, err := handler(toolCtx, args)
+		done <- outcome{content, err}
+	}()
+	select {
+	case o := <-done:
+		if o.err != nil && timedOut() {
+			return "", fmt.Errorf("tool %q timed out after %s", name, timeout)
+		}
+		return o.content, o.err
+	case <-toolCtx.Done():
+		if timedOut() {
+			return "", fmt.Errorf("tool %q timed out after %s", name, timeout)
+		}
+		return "", toolCtx.Err()
+	}
+}
+
+// toolName returns the function name of tc, or "" if it has none
+func toolName(tc llmtypes.ToolCall) string {
+	if tc.FunctionCall == nil {
+		return ""
+	}
+	return tc.FunctionCall.Name
+}
+
+// assistantMessage converts a choice to the assistant message recorded in the transcript
+func assistantMessage(choice *llmtypes.ContentChoice) llmtypes.MessageContent {
+	msg := llmtypes.MessageContent{Role: llmtypes.ChatMessageTypeAI}
+	if choice.Content != "" {
+		msg.Parts = append(msg.Parts, llmtypes.TextContent{Text: choice.Content})
+	}
+	for _, tc := range choice.ToolCalls {
+		msg.Parts = append(msg.Parts, tc)
+	}
+	return msg
+}
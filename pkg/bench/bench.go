@@ -0,0 +1,231 @@
+// Package bench runs the same prompt suite against several models and compares their
+// latency, token usage and cost:
+//
+//	results := bench.Run(ctx, []bench.Target{
+//		{Name: "openai/gpt-4.1", Model: gpt, InputPricePerMillion: 2, OutputPricePerMillion: 8},
+//		{Name: "anthropic/claude-sonnet-4", Model: claude, InputPricePerMillion: 3, OutputPricePerMillion: 15},
+//	}, []string{"Summarize the plot of Hamlet in three sentences."}, bench.Options{Runs: 3})
+//	bench.WriteTable(os.Stdout, results)
+//
+// Targets run concurrently; the prompts of one target run one at a time so that its
+// latencies are not skewed by its own load. Every call streams, so time to first token
+// is measured the same way for every provider.
+package bench
+
+import (
+	"context"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+	"github.com/manishiitg/multi-llm-provider-go/pkg/utils"
+)
+
+// Target is a model to benchmark
+type Target struct {
+	// Name labels the target in reports, e.g. "openai/gpt-4.1"
+	Name  string
+	Model llmtypes.Model
+	// Prices in USD per million tokens; cost is reported as 0 when they are not set
+	InputPricePerMillion  float64
+	OutputPricePerMillion float64
+}
+
+// Options configures Run
+type Options struct {
+	// Runs is how many times each prompt is sent to each target (default 1)
+	Runs int
+	// Timeout bounds each call (0 means no timeout beyond ctx)
+	Timeout time.Duration
+	// CallOptions are passed to every call, e.g. WithMaxTokens or WithTemperature
+	CallOptions []llmtypes.CallOption
+}
+
+// Sample is the outcome of one call
+type Sample struct {
+	Target string
+	// Prompt is the index of the prompt in the suite
+	Prompt int
+	Run    int
+	// TTFT is the time to the first streamed chunk
+	TTFT         time.Duration
+	Latency      time.Duration
+	InputTokens  int
+	OutputTokens int
+	Cost         float64
+	Err          error
+}
+
+// Result holds the samples of one target
+type Result struct {
+	Target  string
+	Samples []Sample
+}
+
+// Run sends every prompt Runs times to every target and returns one Result per target,
+// in the order of targets
+func Run(ctx context.Context, targets []Target, prompts []string, opts Options) []Result {
+	runs := opts.Runs
+	if runs <= 0 {
+		runs = 1
+	}
+
+	results := make([]Result, len(targets))
+	var wg sync.WaitGroup
+	for i, target := range targets {
+		wg.Add(1)
+		go func(i int, target Target) {
+			defer wg.Done()
+			result := Result{Target: target.Name}
+			for run := 0; run < runs; run++ {
+				for p, prompt := range prompts {
+					if ctx.Err() != nil {
+						break
+					}
+					sample := measure(ctx, target, prompt, opts)
+					sample.Prompt, sample.Run = p, run
+					result.Samples = append(result.Samples, sample)
+				}
+			}
+			results[i] = result
+		}(i, target)
+	}
+	wg.Wait()
+	return results
+}
+
+// measure makes one streaming call and times it
+func measure(ctx context.Context, target Target, prompt string, opts Options) Sample {
+	sample := Sample{Target: target.Name}
+	if opts.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, opts.Timeout)
+		defer cancel()
+	}
+
+	messages := []llmtypes.MessageContent{llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, prompt)}
+	start := time.Now()
+	resp, err := utils.GenerateStreaming(ctx, target.Model, messages, opts.CallOptions, func(chunk llmtypes.StreamChunk) {
+		if sample.TTFT == 0 && chunk.Type != llmtypes.StreamChunkTypeUsage {
+			sample.TTFT = time.Since(start)
+		}
+	})
+	sample.Latency = time.Since(start)
+	if err != nil {
+		sample.Err = err
+		return sample
+	}
+	if resp != nil && resp.Usage != nil {
+		sample.InputTokens = resp.Usage.InputTokens
+		sample.OutputTokens = resp.Usage.OutputTokens
+	}
+	sample.Cost = (float64(sample.InputTokens)*target.InputPricePerMillion + float64(sample.OutputTokens)*target.OutputPricePerMillion) / 1e6
+	return sample
+}
+
+// Summary aggregates the successful samples of a Result
+type Summary struct {
+	Target     string
+	Calls      int
+	Errors     int
+	TTFTP50    time.Duration
+	TTFTP95    time.Duration
+	LatencyP50 time.Duration
+	LatencyP95 time.Duration
+	// Average tokens per successful call
+	InputTokens  float64
+	OutputTokens float64
+	// TotalCost is the cost of all successful calls in USD
+	TotalCost float64
+}
+
+// Summarize computes percentiles and averages over the successful samples of r
+func (r Result) Summarize() Summary {
+	summary := Summary{Target: r.Target, Calls: len(r.Samples)}
+	var ttfts, latencies []time.Duration
+	for _, sample := range r.Samples {
+		if sample.Err != nil {
+			summary.Errors++
+			continue
+		}
+		ttfts = append(ttfts, sample.TTFT)
+		latencies = append(latencies, sample.Latency)
+		summary.InputTokens += float64(sample.InputTokens)
+		summary.OutputTokens += float64(sample.OutputTokens)
+		summary.TotalCost += sample.Cost
+	}
+	if succeeded := len(latencies); succeeded > 0 {
+		summary.InputTokens /= float64(succeeded)
+		summary.OutputTokens /= float64(succeeded)
+	}
+	summary.TTFTP50, summary.TTFTP95 = percentile(ttfts, 50), percentile(ttfts, 95)
+	summary.LatencyP50, summary.LatencyP95 = percentile(latencies, 50), percentile(latencies, 95)
+	return summary
+}
+
+// percentile returns the p-th percentile (nearest rank) of durations, or 0 if empty
+func percentile(durations []time.Duration, p int) time.Duration {
+	if len(durations) == 0 {
+		return 0
+	}
+	sorted := append([]time.Duration(nil), durations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
+
+// WriteTable writes one summary line per target as an aligned table
+func WriteTable(w io.Writer, results []Result) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "TARGET\tCALLS\tERRORS\tTTFT P50\tTTFT P95\tLATENCY P50\tLATENCY P95\tAVG IN\tAVG OUT\tCOST (USD)")
+	for _, result := range results {
+		s := result.Summarize()
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%v\t%v\t%v\t%v\t%.0f\t%.0f\t%.6f\n",
+			s.Target, s.Calls, s.Errors,
+			s.TTFTP50.Round(time.Millisecond), s.TTFTP95.Round(time.Millisecond),
+			s.LatencyP50.Round(time.Millisecond), s.LatencyP95.Round(time.Millisecond),
+			s.InputTokens, s.OutputTokens, s.TotalCost)
+	}
+	return tw.Flush()
+}
+
+// WriteCSV writes one row per sample, with durations in milliseconds
+func WriteCSV(w io.Writer, results []Result) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"target", "prompt", "run", "ttft_ms", "latency_ms", "input_tokens", "output_tokens", "cost_usd", "error"}); err != nil {
+		return err
+	}
+	for _, result := range results {
+		for _, sample := range result.Samples {
+			errText := ""
+			if sample.Err != nil {
+				errText = sample.Err.Error()
+			}
+			row := []string{
+				sample.Target,
+				strconv.Itoa(sample.Prompt),
+				strconv.Itoa(sample.Run),
+				strconv.FormatInt(sample.TTFT.Milliseconds(), 10),
+				strconv.FormatInt(sample.Latency.Milliseconds(), 10),
+				strconv.Itoa(sample.InputTokens),
+				strconv.Itoa(sample.OutputTokens),
+				strconv.FormatFloat(sample.Cost, 'f', 6, 64),
+				errText,
+			}
+			if err := cw.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,83 @@
+package history
+
+import (
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+// unansweredToolCallContent is sent for tool calls that have no result in the history
+const unansweredToolCallContent = "tool call was not executed"
+
+// NormalizeHistory rewrites messages into the shape every provider accepts for tool use:
+//   - empty messages are removed
+//   - consecutive tool result messages are merged into one message, since Bedrock and
+//     Gemini expect all results for a turn of parallel tool calls together
+//   - tool results that don't answer a tool call of the preceding assistant message are dropped
+//   - tool calls without a result get an error result, since Anthropic and Bedrock reject
+//     a tool_use block that is never answered
+//
+// Tool calls are kept as-is, including Gemini thought signatures. The input is not modified.
+func NormalizeHistory(messages []llmtypes.MessageContent) []llmtypes.MessageContent {
+	result := make([]llmtypes.MessageContent, 0, len(messages))
+	for i := 0; i < len(messages); i++ {
+		msg := messages[i]
+		if len(msg.Parts) == 0 {
+			continue
+		}
+		if hasToolResponses(msg) && !hasToolCalls(msg) {
+			// A tool result that is not directly after an assistant tool call turn
+			continue
+		}
+		result = append(result, msg)
+		if !hasToolCalls(msg) {
+			continue
+		}
+
+		// Collect the results that follow this tool call turn into a single message
+		pending := make(map[string]bool)
+		var order []llmtypes.ToolCall
+		for _, part := range msg.Parts {
+			if tc, ok := part.(llmtypes.ToolCall); ok {
+				pending[tc.ID] = true
+				order = append(order, tc)
+			}
+		}
+		var responses []llmtypes.ContentPart
+		role := llmtypes.ChatMessageTypeTool
+		name := ""
+		for i+1 < len(messages) && (len(messages[i+1].Parts) == 0 || (hasToolResponses(messages[i+1]) && !hasToolCalls(messages[i+1]))) {
+			i++
+			if len(messages[i].Parts) > 0 && len(responses) == 0 {
+				role = messages[i].Role
+				name = messages[i].Name
+			}
+			for _, part := range messages[i].Parts {
+				if resp, ok := part.(llmtypes.ToolCallResponse); ok {
+					if pending[resp.ToolCallID] {
+						pending[resp.ToolCallID] = false
+						responses = append(responses, resp)
+					}
+					continue
+				}
+				// Keep other content (e.g. text next to the results) in the merged message
+				responses = append(responses, part)
+			}
+		}
+		for _, tc := range order {
+			if !pending[tc.ID] {
+				continue
+			}
+			toolName := ""
+			if tc.FunctionCall != nil {
+				toolName = tc.FunctionCall.Name
+			}
+			responses = append(responses, llmtypes.ToolCallResponse{
+				ToolCallID: tc.ID,
+				Name:       toolName,
+				Content:    unansweredToolCallContent,
+				IsError:    true,
+			})
+		}
+		result = append(result, llmtypes.MessageContent{Role: role, Parts: responses, Name: name})
+	}
+	return result
+}
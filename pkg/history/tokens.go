@@ -0,0 +1,110 @@
+// Package history provides helpers for managing conversation history before it is
+// sent to a provider, such as fitting it into a token budget without breaking the
+// pairing between tool calls and their results.
+package history
+
+import (
+	"fmt"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+const (
+	// charsPerToken is the average number of characters per token across providers' tokenizers
+	charsPerToken = 4
+	// messageOverheadTokens covers role markers and message framing
+	messageOverheadTokens = 4
+	// imageTokens approximates a single image (roughly a 1024x1024 image on most providers)
+	imageTokens = 1000
+	// audioBytesPerToken approximates audio at 32 tokens per second of 16kHz 16-bit PCM
+	audioBytesPerToken = 1000
+)
+
+// CountTokens estimates the number of input tokens used by messages.
+// It is an offline approximation (no provider call), so budgets should leave some headroom.
+func CountTokens(messages []llmtypes.MessageContent) int {
+	total := 0
+	for _, msg := range messages {
+		total += CountMessageTokens(msg)
+	}
+	return total
+}
+
+// CountMessageTokens estimates the number of input tokens used by a single message
+func CountMessageTokens(msg llmtypes.MessageContent) int {
+	tokens := messageOverheadTokens
+	for _, part := range msg.Parts {
+		_, partTokens := countPartTokens(part)
+		tokens += partTokens
+	}
+	return tokens
+}
+
+// MessageTokens is the estimated token count of one message, as reported by TokenBreakdown
+type MessageTokens struct {
+	// Index is the position of the message in the conversation
+	Index int
+	Role  llmtypes.ChatMessageType
+	// Tokens is the message total: its parts plus role markers and message framing
+	Tokens int
+	Parts  []PartTokens
+}
+
+// PartTokens is the estimated token count of one content part of a message
+type PartTokens struct {
+	// Type is the transcript part type (llmtypes.PartTypeText, PartTypeImage, ...)
+	Type   string
+	Tokens int
+}
+
+// TokenBreakdown estimates the tokens of every message and content part, e.g. to show
+// which parts of a conversation (system prompt, history, latest turn) drive its cost.
+// The totals match CountTokens and CountMessageTokens. An error is returned for content
+// part types that cannot be counted.
+func TokenBreakdown(messages []llmtypes.MessageContent) ([]MessageTokens, error) {
+	breakdown := make([]MessageTokens, 0, len(messages))
+	for i, msg := range messages {
+		entry := MessageTokens{Index: i, Role: msg.Role, Tokens: messageOverheadTokens, Parts: make([]PartTokens, 0, len(msg.Parts))}
+		for j, part := range msg.Parts {
+			partType, tokens := countPartTokens(part)
+			if partType == "" {
+				return nil, fmt.Errorf("message %d part %d: cannot count tokens of %T", i, j, part)
+			}
+			entry.Parts = append(entry.Parts, PartTokens{Type: partType, Tokens: tokens})
+			entry.Tokens += tokens
+		}
+		breakdown = append(breakdown, entry)
+	}
+	return breakdown, nil
+}
+
+// countPartTokens estimates the tokens of a content part and returns its transcript part
+// type, or "" for unknown part types
+func countPartTokens(part llmtypes.ContentPart) (string, int) {
+	switch p := part.(type) {
+	case llmtypes.TextContent:
+		return llmtypes.PartTypeText, textTokens(p.Text)
+	case llmtypes.ImageContent:
+		return llmtypes.PartTypeImage, imageTokens
+	case llmtypes.AudioContent:
+		return llmtypes.PartTypeAudio, (len(p.Data)*3/4 + audioBytesPerToken - 1) / audioBytesPerToken
+	case llmtypes.ToolCall:
+		if p.FunctionCall == nil {
+			return llmtypes.PartTypeToolCall, 0
+		}
+		return llmtypes.PartTypeToolCall, textTokens(p.FunctionCall.Name) + textTokens(p.FunctionCall.Arguments)
+	case llmtypes.ToolCallResponse:
+		return llmtypes.PartTypeToolResult, textTokens(p.Text()) + len(p.Images())*imageTokens
+	}
+	return "", 0
+}
+
+// CountTextTokens estimates the number of tokens in text
+func CountTextTokens(text string) int {
+	return textTokens(text)
+}
+
+// textTokens estimates the tokens in text, rounding up
+func textTokens(text string) int {
+	return (len(text) + charsPerToken - 1) / charsPerToken
+}
@@ -0,0 +1,152 @@
+package history
+
+import (
+	"fmt"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+// TruncateResult describes the outcome of Truncate
+type TruncateResult struct {
+	// Messages is the truncated history, in the original order
+	Messages []llmtypes.MessageContent
+	// DroppedMessages is the number of messages removed
+	DroppedMessages int
+	// ReclaimedTokens is the estimated number of tokens removed
+	ReclaimedTokens int
+}
+
+// messageGroup is a run of messages that must be kept or dropped together
+type messageGroup struct {
+	start, end int // messages[start:end]
+	tokens     int
+	pinned     bool
+}
+
+// Truncate drops the oldest messages until the history fits in maxTokens (as estimated by
+// CountTokens). An assistant message with tool calls and the tool results that answer it
+// are dropped together, so no tool result is ever left without its call or vice versa.
+// System messages are never dropped when keepSystem is true, and the current turn is always
+// kept: the last user message and every message after it, such as the tool calls and tool
+// results of an agent loop answering it. If the history still does not fit, the truncated
+// result is returned along with an error.
+func Truncate(messages []llmtypes.MessageContent, maxTokens int, keepSystem bool) (TruncateResult, error) {
+	if maxTokens <= 0 {
+		return TruncateResult{Messages: messages}, fmt.Errorf("maxTokens must be positive, got %d", maxTokens)
+	}
+
+	groups := groupMessages(messages, keepSystem)
+	total := 0
+	for _, g := range groups {
+		total += g.tokens
+	}
+	if total <= maxTokens {
+		return TruncateResult{Messages: messages}, nil
+	}
+
+	// The current turn is never dropped
+	last := currentTurn(messages, groups)
+
+	dropped := make([]bool, len(groups))
+	result := TruncateResult{}
+	drop := func(i int) {
+		dropped[i] = true
+		total -= groups[i].tokens
+		result.DroppedMessages += groups[i].end - groups[i].start
+		result.ReclaimedTokens += groups[i].tokens
+	}
+	for i := 0; i < last && total > maxTokens; i++ {
+		if !groups[i].pinned {
+			drop(i)
+		}
+	}
+
+	// Providers expect the conversation to open with a user turn, so don't leave an
+	// assistant message or a tool result at the front after dropping the turn before it
+	if result.DroppedMessages > 0 {
+		for i := 0; i < last; i++ {
+			if groups[i].pinned || dropped[i] {
+				continue
+			}
+			first := messages[groups[i].start]
+			if first.Role != llmtypes.ChatMessageTypeAI && !hasToolResponses(first) {
+				break
+			}
+			drop(i)
+		}
+	}
+
+	for i, g := range groups {
+		if !dropped[i] {
+			result.Messages = append(result.Messages, messages[g.start:g.end]...)
+		}
+	}
+
+	if total > maxTokens {
+		return result, fmt.Errorf("history needs about %d tokens after truncation, exceeding the budget of %d", total, maxTokens)
+	}
+	return result, nil
+}
+
+// groupMessages splits messages into groups that must be dropped atomically
+func groupMessages(messages []llmtypes.MessageContent, keepSystem bool) []messageGroup {
+	var groups []messageGroup
+	for i := 0; i < len(messages); {
+		g := messageGroup{start: i, end: i + 1}
+		msg := messages[i]
+		switch {
+		case keepSystem && msg.Role == llmtypes.ChatMessageTypeSystem:
+			g.pinned = true
+		case hasToolCalls(msg):
+			// Absorb the tool result messages that answer these calls
+			for g.end < len(messages) && hasToolResponses(messages[g.end]) {
+				g.end++
+			}
+		}
+		for _, m := range messages[g.start:g.end] {
+			g.tokens += CountMessageTokens(m)
+		}
+		groups = append(groups, g)
+		i = g.end
+	}
+	return groups
+}
+
+// currentTurn returns the index of the group starting the current turn: the last unpinned
+// group opening with a user message, or the last unpinned group if none does. -1 if every
+// group is pinned.
+func currentTurn(messages []llmtypes.MessageContent, groups []messageGroup) int {
+	last := -1
+	for i := len(groups) - 1; i >= 0; i-- {
+		if groups[i].pinned {
+			continue
+		}
+		if messages[groups[i].start].Role == llmtypes.ChatMessageTypeHuman {
+			return i
+		}
+		if last < 0 {
+			last = i
+		}
+	}
+	return last
+}
+
+// hasToolCalls reports whether msg contains any tool calls
+func hasToolCalls(msg llmtypes.MessageContent) bool {
+	for _, part := range msg.Parts {
+		if _, ok := part.(llmtypes.ToolCall); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// hasToolResponses reports whether msg contains any tool call responses
+func hasToolResponses(msg llmtypes.MessageContent) bool {
+	for _, part := range msg.Parts {
+		if _, ok := part.(llmtypes.ToolCallResponse); ok {
+			return true
+		}
+	}
+	return false
+}
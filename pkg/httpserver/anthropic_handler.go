@@ -0,0 +1,408 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+	anthropicadapter "github.com/manishiitg/multi-llm-provider-go/pkg/adapters/anthropic"
+)
+
+// anthropicMessagesRequest is the subset of the Anthropic /v1/messages request body we understand
+type anthropicMessagesRequest struct {
+	Model       string                   `json:"model"`
+	MaxTokens   int                      `json:"max_tokens"`
+	System      json.RawMessage          `json:"system,omitempty"`
+	Messages    []anthropic.MessageParam `json:"messages"`
+	Stream      bool                     `json:"stream"`
+	Temperature *float64                 `json:"temperature,omitempty"`
+	Tools       []anthropicTool          `json:"tools,omitempty"`
+	ToolChoice  *anthropicToolChoice     `json:"tool_choice,omitempty"`
+	Metadata    map[string]interface{}   `json:"metadata,omitempty"`
+}
+
+type anthropicTool struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description,omitempty"`
+	InputSchema map[string]interface{} `json:"input_schema"`
+}
+
+type anthropicToolChoice struct {
+	Type string `json:"type"`
+	Name string `json:"name,omitempty"`
+}
+
+type anthropicUsage struct {
+	InputTokens  int `json:"input_tokens"`
+	OutputTokens int `json:"output_tokens"`
+}
+
+type anthropicResponseBlock struct {
+	Type  string          `json:"type"`
+	Text  *string         `json:"text,omitempty"`
+	ID    string          `json:"id,omitempty"`
+	Name  string          `json:"name,omitempty"`
+	Input json.RawMessage `json:"input,omitempty"`
+}
+
+type anthropicMessagesResponse struct {
+	ID           string                   `json:"id"`
+	Type         string                   `json:"type"`
+	Role         string                   `json:"role"`
+	Model        string                   `json:"model"`
+	Content      []anthropicResponseBlock `json:"content"`
+	StopReason   *string                  `json:"stop_reason"`
+	StopSequence *string                  `json:"stop_sequence"`
+	Usage        anthropicUsage           `json:"usage"`
+}
+
+type anthropicErrorResponse struct {
+	Type  string         `json:"type"`
+	Error anthropicError `json:"error"`
+}
+
+type anthropicError struct {
+	Type    string `json:"type"`
+	Message string `json:"message"`
+}
+
+// MessagesHandler serves a Model over the Anthropic /v1/messages wire format
+type MessagesHandler struct {
+	model llmtypes.Model
+}
+
+// NewMessagesHandler creates an http.Handler that exposes model as an
+// Anthropic Messages API endpoint. Requests with "stream": true are answered
+// with the Anthropic SSE event sequence (message_start, content_block_*, message_delta, message_stop).
+// Failed model calls are answered with the provider's status and the matching Anthropic
+// error type (e.g. 429 rate_limit_error), or 502 api_error when the error carries none.
+func NewMessagesHandler(model llmtypes.Model) *MessagesHandler {
+	return &MessagesHandler{model: model}
+}
+
+// ServeHTTP implements http.Handler
+func (h *MessagesHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeAnthropicError(w, http.StatusMethodNotAllowed, "invalid_request_error", "method not allowed")
+		return
+	}
+
+	var req anthropicMessagesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeAnthropicError(w, http.StatusBadRequest, "invalid_request_error", fmt.Sprintf("invalid request body: %v", err))
+		return
+	}
+
+	system, err := anthropicSystemText(req.System)
+	if err != nil {
+		writeAnthropicError(w, http.StatusBadRequest, "invalid_request_error", err.Error())
+		return
+	}
+	messages, err := anthropicadapter.FromAnthropicMessages(req.Messages, system)
+	if err != nil {
+		writeAnthropicError(w, http.StatusBadRequest, "invalid_request_error", err.Error())
+		return
+	}
+	options := anthropicRequestOptions(&req)
+
+	modelName := h.model.GetModelID()
+	if modelName == "" {
+		modelName = req.Model
+	}
+
+	if req.Stream {
+		h.serveStream(w, r, modelName, messages, options)
+		return
+	}
+
+	resp, err := h.model.GenerateContent(r.Context(), messages, options...)
+	if err != nil {
+		status, errType := errorStatus(err)
+		writeAnthropicError(w, status, errType, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, contentResponseToAnthropic(resp, newID("msg_"), modelName))
+}
+
+// serveStream answers a streaming request using the Anthropic SSE event types
+func (h *MessagesHandler) serveStream(w http.ResponseWriter, r *http.Request, modelName string, messages []llmtypes.MessageContent, options []llmtypes.CallOption) {
+	sse := newSSEWriter(w)
+	id := newID("msg_")
+
+	messageStarted := false
+	blockIndex := 0
+	textBlockOpen := false
+	toolCallsSent := 0
+
+	startMessage := func() error {
+		if messageStarted {
+			return nil
+		}
+		messageStarted = true
+		return sse.writeEvent("message_start", map[string]interface{}{
+			"type": "message_start",
+			"message": anthropicMessagesResponse{
+				ID:      id,
+				Type:    "message",
+				Role:    "assistant",
+				Model:   modelName,
+				Content: []anthropicResponseBlock{},
+			},
+		})
+	}
+	closeTextBlock := func() error {
+		if !textBlockOpen {
+			return nil
+		}
+		textBlockOpen = false
+		err := sse.writeEvent("content_block_stop", map[string]interface{}{"type": "content_block_stop", "index": blockIndex})
+		blockIndex++
+		return err
+	}
+
+	var finish *llmtypes.StreamChunk
+	resp, err := streamGenerate(r.Context(), h.model, messages, options, func(chunk llmtypes.StreamChunk) error {
+		switch chunk.Type {
+		case llmtypes.StreamChunkTypeContent:
+			if err := startMessage(); err != nil {
+				return err
+			}
+			if !textBlockOpen {
+				textBlockOpen = true
+				empty := ""
+				if err := sse.writeEvent("content_block_start", map[string]interface{}{
+					"type":          "content_block_start",
+					"index":         blockIndex,
+					"content_block": anthropicResponseBlock{Type: "text", Text: &empty},
+				}); err != nil {
+					return err
+				}
+			}
+			return sse.writeEvent("content_block_delta", map[string]interface{}{
+				"type":  "content_block_delta",
+				"index": blockIndex,
+				"delta": map[string]string{"type": "text_delta", "text": chunk.Content},
+			})
+		case llmtypes.StreamChunkTypeToolCall:
+			if chunk.ToolCall == nil {
+				return nil
+			}
+			if err := startMessage(); err != nil {
+				return err
+			}
+			if err := closeTextBlock(); err != nil {
+				return err
+			}
+			block := toolCallToAnthropic(*chunk.ToolCall)
+			if err := sse.writeEvent("content_block_start", map[string]interface{}{
+				"type":          "content_block_start",
+				"index":         blockIndex,
+				"content_block": anthropicResponseBlock{Type: "tool_use", ID: block.ID, Name: block.Name, Input: json.RawMessage("{}")},
+			}); err != nil {
+				return err
+			}
+			if err := sse.writeEvent("content_block_delta", map[string]interface{}{
+				"type":  "content_block_delta",
+				"index": blockIndex,
+				"delta": map[string]string{"type": "input_json_delta", "partial_json": string(block.Input)},
+			}); err != nil {
+				return err
+			}
+			toolCallsSent++
+			err := sse.writeEvent("content_block_stop", map[string]interface{}{"type": "content_block_stop", "index": blockIndex})
+			blockIndex++
+			return err
+		case llmtypes.StreamChunkTypeFinish:
+			finishCopy := chunk
+			finish = &finishCopy
+		}
+		return nil
+	})
+	if err != nil {
+		status, errType := errorStatus(err)
+		if !sse.started {
+			writeAnthropicError(w, status, errType, err.Error())
+			return
+		}
+		_ = sse.writeEvent("error", anthropicErrorResponse{Type: "error", Error: anthropicError{Type: errType, Message: err.Error()}})
+		return
+	}
+
+	if err := startMessage(); err != nil {
+		return
+	}
+	if err := closeTextBlock(); err != nil {
+		return
+	}
+
+	// Prefer the terminal chunk; fall back to the returned response for stop reason and usage
+	stopReason := ""
+	var usage *llmtypes.Usage
+	if finish != nil {
+		stopReason = finish.StopReason
+		usage = finish.Usage
+	} else if resp != nil {
+		usage = resp.Usage
+		if len(resp.Choices) > 0 && resp.Choices[0] != nil {
+			stopReason = resp.Choices[0].StopReason
+		}
+	}
+
+	mappedStopReason := anthropicStopReason(stopReason, toolCallsSent > 0)
+	messageDelta := map[string]interface{}{
+		"type":  "message_delta",
+		"delta": map[string]interface{}{"stop_reason": mappedStopReason, "stop_sequence": nil},
+		"usage": usageToAnthropic(usage),
+	}
+	if err := sse.writeEvent("message_delta", messageDelta); err != nil {
+		return
+	}
+	_ = sse.writeEvent("message_stop", map[string]string{"type": "message_stop"})
+}
+
+// anthropicSystemText returns the system prompt of a request, either a string or an array of
+// text blocks joined with newlines
+func anthropicSystemText(raw json.RawMessage) (string, error) {
+	if len(raw) == 0 || string(raw) == "null" {
+		return "", nil
+	}
+	var text string
+	if err := json.Unmarshal(raw, &text); err == nil {
+		return text, nil
+	}
+	var blocks []anthropic.TextBlockParam
+	if err := json.Unmarshal(raw, &blocks); err != nil {
+		return "", fmt.Errorf("system: invalid content: %w", err)
+	}
+	texts := make([]string, 0, len(blocks))
+	for _, block := range blocks {
+		texts = append(texts, block.Text)
+	}
+	return strings.Join(texts, "\n"), nil
+}
+
+// anthropicRequestOptions converts Anthropic request parameters to CallOptions
+func anthropicRequestOptions(req *anthropicMessagesRequest) []llmtypes.CallOption {
+	var options []llmtypes.CallOption
+
+	if req.MaxTokens > 0 {
+		options = append(options, llmtypes.WithMaxTokens(req.MaxTokens))
+	}
+	if req.Temperature != nil {
+		options = append(options, llmtypes.WithTemperature(*req.Temperature))
+	}
+
+	if len(req.Tools) > 0 {
+		tools := make([]llmtypes.Tool, 0, len(req.Tools))
+		for _, tool := range req.Tools {
+			tools = append(tools, llmtypes.Tool{
+				Type: "function",
+				Function: &llmtypes.FunctionDefinition{
+					Name:        tool.Name,
+					Description: tool.Description,
+					Parameters:  llmtypes.NewParameters(tool.InputSchema),
+				},
+			})
+		}
+		options = append(options, llmtypes.WithTools(tools))
+	}
+
+	if req.ToolChoice != nil {
+		switch req.ToolChoice.Type {
+		case "auto":
+			options = append(options, llmtypes.WithToolChoice(&llmtypes.ToolChoice{Type: "auto"}))
+		case "any":
+			options = append(options, llmtypes.WithToolChoice(&llmtypes.ToolChoice{Type: "required"}))
+		case "none":
+			options = append(options, llmtypes.WithToolChoice(&llmtypes.ToolChoice{Type: "none"}))
+		case "tool":
+			options = append(options, llmtypes.WithToolChoice(&llmtypes.ToolChoice{
+				Type:     "function",
+				Function: &llmtypes.FunctionName{Name: req.ToolChoice.Name},
+			}))
+		}
+	}
+
+	return options
+}
+
+// contentResponseToAnthropic converts a ContentResponse to an Anthropic message response
+func contentResponseToAnthropic(resp *llmtypes.ContentResponse, id, modelName string) anthropicMessagesResponse {
+	out := anthropicMessagesResponse{
+		ID:      id,
+		Type:    "message",
+		Role:    "assistant",
+		Model:   modelName,
+		Content: []anthropicResponseBlock{},
+	}
+	if resp == nil || len(resp.Choices) == 0 || resp.Choices[0] == nil {
+		stopReason := "end_turn"
+		out.StopReason = &stopReason
+		return out
+	}
+
+	choice := resp.Choices[0]
+	if choice.Content != "" {
+		text := choice.Content
+		out.Content = append(out.Content, anthropicResponseBlock{Type: "text", Text: &text})
+	}
+	for _, tc := range choice.ToolCalls {
+		out.Content = append(out.Content, toolCallToAnthropic(tc))
+	}
+
+	stopReason := anthropicStopReason(choice.StopReason, len(choice.ToolCalls) > 0)
+	out.StopReason = &stopReason
+
+	usage := resp.Usage
+	if usage == nil {
+		usage = llmtypes.ExtractUsageFromGenerationInfo(choice.GenerationInfo)
+	}
+	out.Usage = usageToAnthropic(usage)
+	return out
+}
+
+// toolCallToAnthropic converts a ToolCall to an Anthropic tool_use block
+func toolCallToAnthropic(tc llmtypes.ToolCall) anthropicResponseBlock {
+	block := anthropicResponseBlock{Type: "tool_use", ID: tc.ID, Input: json.RawMessage("{}")}
+	if tc.FunctionCall != nil {
+		block.Name = tc.FunctionCall.Name
+		if tc.FunctionCall.Arguments != "" && json.Valid([]byte(tc.FunctionCall.Arguments)) {
+			block.Input = json.RawMessage(tc.FunctionCall.Arguments)
+		}
+	}
+	return block
+}
+
+// usageToAnthropic converts Usage to the Anthropic usage object
+func usageToAnthropic(usage *llmtypes.Usage) anthropicUsage {
+	if usage == nil {
+		return anthropicUsage{}
+	}
+	return anthropicUsage{InputTokens: usage.InputTokens, OutputTokens: usage.OutputTokens}
+}
+
+// anthropicStopReason maps provider stop reasons to Anthropic stop_reason values
+func anthropicStopReason(stopReason string, hasToolCalls bool) string {
+	if hasToolCalls {
+		return "tool_use"
+	}
+	switch strings.ToLower(stopReason) {
+	case "length", "max_tokens":
+		return "max_tokens"
+	case "stop_sequence":
+		return "stop_sequence"
+	case "tool_calls", "tool_use":
+		return "tool_use"
+	default:
+		return "end_turn"
+	}
+}
+
+// writeAnthropicError writes an error in the Anthropic error envelope
+func writeAnthropicError(w http.ResponseWriter, status int, errType, message string) {
+	writeJSON(w, status, anthropicErrorResponse{Type: "error", Error: anthropicError{Type: errType, Message: message}})
+}
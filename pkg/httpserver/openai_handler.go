@@ -0,0 +1,434 @@
+package httpserver
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+	openaiadapter "github.com/manishiitg/multi-llm-provider-go/pkg/adapters/openai"
+	"github.com/manishiitg/multi-llm-provider-go/pkg/utils"
+
+	"github.com/openai/openai-go/v3"
+)
+
+// openAIChatRequest is the subset of the OpenAI /v1/chat/completions request body we understand
+type openAIChatRequest struct {
+	Model               string                                   `json:"model"`
+	Messages            []openai.ChatCompletionMessageParamUnion `json:"messages"`
+	Stream              bool                                     `json:"stream"`
+	StreamOptions       *openAIStreamOpts                        `json:"stream_options,omitempty"`
+	Temperature         *float64                                 `json:"temperature,omitempty"`
+	MaxTokens           *int                                     `json:"max_tokens,omitempty"`
+	MaxCompletionTokens *int                                     `json:"max_completion_tokens,omitempty"`
+	Tools               []openAITool                             `json:"tools,omitempty"`
+	ToolChoice          json.RawMessage                          `json:"tool_choice,omitempty"`
+	ResponseFormat      *openAIRespFormat                        `json:"response_format,omitempty"`
+	ReasoningEffort     string                                   `json:"reasoning_effort,omitempty"`
+	Verbosity           string                                   `json:"verbosity,omitempty"`
+}
+
+type openAIStreamOpts struct {
+	IncludeUsage bool `json:"include_usage"`
+}
+
+type openAIToolCall struct {
+	Index    *int   `json:"index,omitempty"`
+	ID       string `json:"id,omitempty"`
+	Type     string `json:"type,omitempty"`
+	Function struct {
+		Name      string `json:"name,omitempty"`
+		Arguments string `json:"arguments"`
+	} `json:"function"`
+}
+
+type openAITool struct {
+	Type     string `json:"type"`
+	Function struct {
+		Name        string                 `json:"name"`
+		Description string                 `json:"description,omitempty"`
+		Parameters  map[string]interface{} `json:"parameters,omitempty"`
+	} `json:"function"`
+}
+
+type openAIRespFormat struct {
+	Type       string `json:"type"`
+	JSONSchema *struct {
+		Name        string                 `json:"name"`
+		Description string                 `json:"description,omitempty"`
+		Schema      map[string]interface{} `json:"schema"`
+		Strict      bool                   `json:"strict,omitempty"`
+	} `json:"json_schema,omitempty"`
+}
+
+type openAIUsage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+type openAIResponseMessage struct {
+	Role      string           `json:"role,omitempty"`
+	Content   *string          `json:"content,omitempty"`
+	ToolCalls []openAIToolCall `json:"tool_calls,omitempty"`
+}
+
+type openAIChoice struct {
+	Index        int                    `json:"index"`
+	Message      *openAIResponseMessage `json:"message,omitempty"`
+	Delta        *openAIResponseMessage `json:"delta,omitempty"`
+	FinishReason *string                `json:"finish_reason"`
+}
+
+type openAIChatResponse struct {
+	ID      string         `json:"id"`
+	Object  string         `json:"object"`
+	Created int64          `json:"created"`
+	Model   string         `json:"model"`
+	Choices []openAIChoice `json:"choices"`
+	Usage   *openAIUsage   `json:"usage,omitempty"`
+}
+
+type openAIErrorResponse struct {
+	Error openAIError `json:"error"`
+}
+
+type openAIError struct {
+	Message string `json:"message"`
+	Type    string `json:"type"`
+}
+
+// ChatCompletionsHandler serves a Model over the OpenAI /v1/chat/completions wire format
+type ChatCompletionsHandler struct {
+	model llmtypes.Model
+}
+
+// NewChatCompletionsHandler creates an http.Handler that exposes model as an
+// OpenAI-compatible chat completions endpoint. Requests with "stream": true are
+// answered with Server-Sent Events in the OpenAI chunk format. Failed model calls
+// are answered with the provider's status (e.g. 429 for a ProviderError rate limit),
+// or 502 when the error carries none.
+func NewChatCompletionsHandler(model llmtypes.Model) *ChatCompletionsHandler {
+	return &ChatCompletionsHandler{model: model}
+}
+
+// ServeHTTP implements http.Handler
+func (h *ChatCompletionsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		writeOpenAIError(w, http.StatusMethodNotAllowed, "invalid_request_error", "method not allowed")
+		return
+	}
+
+	var req openAIChatRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeOpenAIError(w, http.StatusBadRequest, "invalid_request_error", fmt.Sprintf("invalid request body: %v", err))
+		return
+	}
+
+	messages, err := openaiadapter.FromOpenAIMessages(req.Messages)
+	if err != nil {
+		writeOpenAIError(w, http.StatusBadRequest, "invalid_request_error", err.Error())
+		return
+	}
+	options, err := openAIRequestOptions(&req)
+	if err != nil {
+		writeOpenAIError(w, http.StatusBadRequest, "invalid_request_error", err.Error())
+		return
+	}
+
+	modelName := h.model.GetModelID()
+	if modelName == "" {
+		modelName = req.Model
+	}
+
+	if req.Stream {
+		h.serveStream(w, r, &req, modelName, messages, options)
+		return
+	}
+
+	resp, err := h.model.GenerateContent(r.Context(), messages, options...)
+	if err != nil {
+		status, errType := errorStatus(err)
+		writeOpenAIError(w, status, errType, err.Error())
+		return
+	}
+
+	writeJSON(w, http.StatusOK, contentResponseToOpenAI(resp, newID("chatcmpl-"), modelName))
+}
+
+// serveStream answers a streaming request using OpenAI chat.completion.chunk events
+func (h *ChatCompletionsHandler) serveStream(w http.ResponseWriter, r *http.Request, req *openAIChatRequest, modelName string, messages []llmtypes.MessageContent, options []llmtypes.CallOption) {
+	sse := newSSEWriter(w)
+	id := newID("chatcmpl-")
+	created := time.Now().Unix()
+
+	newChunk := func(delta *openAIResponseMessage, finishReason *string) openAIChatResponse {
+		return openAIChatResponse{
+			ID:      id,
+			Object:  "chat.completion.chunk",
+			Created: created,
+			Model:   modelName,
+			Choices: []openAIChoice{{Index: 0, Delta: delta, FinishReason: finishReason}},
+		}
+	}
+
+	toolCallIndex := 0
+	roleSent := false
+	sendRole := func() error {
+		if roleSent {
+			return nil
+		}
+		roleSent = true
+		return sse.writeEvent("", newChunk(&openAIResponseMessage{Role: "assistant"}, nil))
+	}
+
+	var finish *llmtypes.StreamChunk
+	resp, err := streamGenerate(r.Context(), h.model, messages, options, func(chunk llmtypes.StreamChunk) error {
+		switch chunk.Type {
+		case llmtypes.StreamChunkTypeContent:
+			if err := sendRole(); err != nil {
+				return err
+			}
+			content := chunk.Content
+			return sse.writeEvent("", newChunk(&openAIResponseMessage{Content: &content}, nil))
+		case llmtypes.StreamChunkTypeToolCall:
+			if chunk.ToolCall == nil {
+				return nil
+			}
+			if err := sendRole(); err != nil {
+				return err
+			}
+			toolCall := toolCallToOpenAI(*chunk.ToolCall)
+			index := toolCallIndex
+			toolCall.Index = &index
+			toolCallIndex++
+			return sse.writeEvent("", newChunk(&openAIResponseMessage{ToolCalls: []openAIToolCall{toolCall}}, nil))
+		case llmtypes.StreamChunkTypeFinish:
+			finishCopy := chunk
+			finish = &finishCopy
+		}
+		return nil
+	})
+	if err != nil {
+		status, errType := errorStatus(err)
+		if !sse.started {
+			writeOpenAIError(w, status, errType, err.Error())
+			return
+		}
+		_ = sse.writeEvent("", openAIErrorResponse{Error: openAIError{Message: err.Error(), Type: errType}})
+		_ = sse.writeEvent("", "[DONE]")
+		return
+	}
+
+	if err := sendRole(); err != nil {
+		return
+	}
+
+	// Prefer the terminal chunk; fall back to the returned response for stop reason and usage
+	stopReason := ""
+	var usage *llmtypes.Usage
+	if finish != nil {
+		stopReason = finish.StopReason
+		usage = finish.Usage
+	} else if resp != nil {
+		usage = resp.Usage
+		if len(resp.Choices) > 0 && resp.Choices[0] != nil {
+			stopReason = resp.Choices[0].StopReason
+		}
+	}
+
+	finishReason := openAIFinishReason(stopReason, toolCallIndex > 0)
+	if err := sse.writeEvent("", newChunk(&openAIResponseMessage{}, &finishReason)); err != nil {
+		return
+	}
+
+	if req.StreamOptions != nil && req.StreamOptions.IncludeUsage {
+		usageChunk := openAIChatResponse{
+			ID:      id,
+			Object:  "chat.completion.chunk",
+			Created: created,
+			Model:   modelName,
+			Choices: []openAIChoice{},
+			Usage:   usageToOpenAI(usage),
+		}
+		if usageChunk.Usage == nil {
+			usageChunk.Usage = &openAIUsage{}
+		}
+		if err := sse.writeEvent("", usageChunk); err != nil {
+			return
+		}
+	}
+
+	_ = sse.writeEvent("", "[DONE]")
+}
+
+// openAIRequestOptions converts OpenAI request parameters to CallOptions
+func openAIRequestOptions(req *openAIChatRequest) ([]llmtypes.CallOption, error) {
+	var options []llmtypes.CallOption
+
+	if req.Temperature != nil {
+		options = append(options, llmtypes.WithTemperature(*req.Temperature))
+	}
+	if req.MaxCompletionTokens != nil {
+		options = append(options, llmtypes.WithMaxTokens(*req.MaxCompletionTokens))
+	} else if req.MaxTokens != nil {
+		options = append(options, llmtypes.WithMaxTokens(*req.MaxTokens))
+	}
+	if req.ReasoningEffort != "" {
+		options = append(options, llmtypes.WithReasoningEffort(req.ReasoningEffort))
+	}
+	if req.Verbosity != "" {
+		options = append(options, llmtypes.WithVerbosity(req.Verbosity))
+	}
+
+	if len(req.Tools) > 0 {
+		tools := make([]llmtypes.Tool, 0, len(req.Tools))
+		for _, tool := range req.Tools {
+			tools = append(tools, llmtypes.Tool{
+				Type: "function",
+				Function: &llmtypes.FunctionDefinition{
+					Name:        tool.Function.Name,
+					Description: tool.Function.Description,
+					Parameters:  llmtypes.NewParameters(tool.Function.Parameters),
+				},
+			})
+		}
+		options = append(options, llmtypes.WithTools(tools))
+	}
+
+	if len(req.ToolChoice) > 0 && string(req.ToolChoice) != "null" {
+		toolChoice, err := parseOpenAIToolChoice(req.ToolChoice)
+		if err != nil {
+			return nil, err
+		}
+		options = append(options, llmtypes.WithToolChoice(toolChoice))
+	}
+
+	if req.ResponseFormat != nil {
+		switch req.ResponseFormat.Type {
+		case "json_object":
+			options = append(options, llmtypes.WithJSONMode())
+		case "json_schema":
+			if req.ResponseFormat.JSONSchema == nil {
+				return nil, fmt.Errorf("response_format json_schema is missing json_schema")
+			}
+			schema := req.ResponseFormat.JSONSchema
+			options = append(options, llmtypes.WithJSONSchema(schema.Schema, schema.Name, schema.Description, schema.Strict))
+		case "text", "":
+		default:
+			return nil, fmt.Errorf("unsupported response_format type %q", req.ResponseFormat.Type)
+		}
+	}
+
+	return options, nil
+}
+
+// parseOpenAIToolChoice parses tool_choice which is either a string or a function object
+func parseOpenAIToolChoice(raw json.RawMessage) (*llmtypes.ToolChoice, error) {
+	var choiceType string
+	if err := json.Unmarshal(raw, &choiceType); err == nil {
+		return &llmtypes.ToolChoice{Type: choiceType}, nil
+	}
+
+	var choice struct {
+		Type     string `json:"type"`
+		Function struct {
+			Name string `json:"name"`
+		} `json:"function"`
+	}
+	if err := json.Unmarshal(raw, &choice); err != nil {
+		return nil, fmt.Errorf("invalid tool_choice: %w", err)
+	}
+	return &llmtypes.ToolChoice{
+		Type:     "function",
+		Function: &llmtypes.FunctionName{Name: choice.Function.Name},
+	}, nil
+}
+
+// contentResponseToOpenAI converts a ContentResponse to an OpenAI chat.completion response
+func contentResponseToOpenAI(resp *llmtypes.ContentResponse, id, modelName string) openAIChatResponse {
+	out := openAIChatResponse{
+		ID:      id,
+		Object:  "chat.completion",
+		Created: time.Now().Unix(),
+		Model:   modelName,
+		Choices: []openAIChoice{},
+	}
+	if resp == nil {
+		return out
+	}
+
+	for i, choice := range resp.Choices {
+		if choice == nil {
+			continue
+		}
+		content := choice.Content
+		message := &openAIResponseMessage{Role: "assistant", Content: &content}
+		for _, tc := range choice.ToolCalls {
+			message.ToolCalls = append(message.ToolCalls, toolCallToOpenAI(tc))
+		}
+		finishReason := openAIFinishReason(choice.StopReason, len(choice.ToolCalls) > 0)
+		out.Choices = append(out.Choices, openAIChoice{Index: i, Message: message, FinishReason: &finishReason})
+	}
+
+	usage := resp.Usage
+	if usage == nil && len(resp.Choices) > 0 && resp.Choices[0] != nil {
+		usage = llmtypes.ExtractUsageFromGenerationInfo(resp.Choices[0].GenerationInfo)
+	}
+	out.Usage = usageToOpenAI(usage)
+	return out
+}
+
+// toolCallToOpenAI converts a ToolCall to its OpenAI wire representation
+func toolCallToOpenAI(tc llmtypes.ToolCall) openAIToolCall {
+	out := openAIToolCall{ID: tc.ID, Type: "function"}
+	if tc.FunctionCall != nil {
+		out.Function.Name = tc.FunctionCall.Name
+		out.Function.Arguments = tc.FunctionCall.Arguments
+	}
+	if out.Function.Arguments == "" {
+		out.Function.Arguments = "{}"
+	}
+	return out
+}
+
+// usageToOpenAI converts Usage to the OpenAI usage object
+func usageToOpenAI(usage *llmtypes.Usage) *openAIUsage {
+	if usage == nil {
+		return nil
+	}
+	total := usage.TotalTokens
+	if total == 0 {
+		total = usage.InputTokens + usage.OutputTokens
+	}
+	return &openAIUsage{
+		PromptTokens:     usage.InputTokens,
+		CompletionTokens: usage.OutputTokens,
+		TotalTokens:      total,
+	}
+}
+
+// openAIFinishReason maps provider stop reasons to OpenAI finish_reason values
+func openAIFinishReason(stopReason string, hasToolCalls bool) string {
+	if hasToolCalls {
+		return "tool_calls"
+	}
+	if utils.IsContentFilterStopReason(stopReason) {
+		return "content_filter"
+	}
+	switch strings.ToLower(stopReason) {
+	case "length", "max_tokens":
+		return "length"
+	case "tool_calls", "tool_use":
+		return "tool_calls"
+	default:
+		return "stop"
+	}
+}
+
+// writeOpenAIError writes an error in the OpenAI error envelope
+func writeOpenAIError(w http.ResponseWriter, status int, errType, message string) {
+	writeJSON(w, status, openAIErrorResponse{Error: openAIError{Message: message, Type: errType}})
+}
@@ -0,0 +1,175 @@
+package httpserver
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+// generateResult holds the outcome of a GenerateContent call made in the background
+type generateResult struct {
+	resp *llmtypes.ContentResponse
+	err  error
+}
+
+// streamGenerate calls model.GenerateContent with a streaming channel and invokes
+// onChunk for every chunk received. It returns once the model call has finished
+// and all chunks have been delivered.
+//
+// The channel is not relied upon to be closed: some adapters return an error
+// before they start streaming, so completion is tracked through the call itself.
+func streamGenerate(ctx context.Context, model llmtypes.Model, messages []llmtypes.MessageContent, options []llmtypes.CallOption, onChunk func(llmtypes.StreamChunk) error) (*llmtypes.ContentResponse, error) {
+	streamChan := make(chan llmtypes.StreamChunk, 100)
+	done := make(chan generateResult, 1)
+
+	callOptions := append(append([]llmtypes.CallOption{}, options...), llmtypes.WithStreamingChan(streamChan))
+	go func() {
+		resp, err := model.GenerateContent(ctx, messages, callOptions...)
+		done <- generateResult{resp: resp, err: err}
+	}()
+
+	var chunkErr error
+	handle := func(chunk llmtypes.StreamChunk) {
+		if chunkErr == nil {
+			chunkErr = onChunk(chunk)
+		}
+	}
+
+	for {
+		select {
+		case chunk, ok := <-streamChan:
+			if !ok {
+				result := <-done
+				if chunkErr != nil {
+					return nil, chunkErr
+				}
+				return result.resp, result.err
+			}
+			handle(chunk)
+		case result := <-done:
+			// All sends have completed once GenerateContent returns; drain what is buffered
+			for {
+				select {
+				case chunk, ok := <-streamChan:
+					if !ok {
+						if chunkErr != nil {
+							return nil, chunkErr
+						}
+						return result.resp, result.err
+					}
+					handle(chunk)
+				default:
+					if chunkErr != nil {
+						return nil, chunkErr
+					}
+					return result.resp, result.err
+				}
+			}
+		}
+	}
+}
+
+// sseWriter writes Server-Sent Events to an http.ResponseWriter
+type sseWriter struct {
+	w       http.ResponseWriter
+	flusher http.Flusher
+	started bool
+}
+
+func newSSEWriter(w http.ResponseWriter) *sseWriter {
+	flusher, _ := w.(http.Flusher)
+	return &sseWriter{w: w, flusher: flusher}
+}
+
+// start writes the SSE response headers (only once)
+func (s *sseWriter) start() {
+	if s.started {
+		return
+	}
+	s.started = true
+	s.w.Header().Set("Content-Type", "text/event-stream")
+	s.w.Header().Set("Cache-Control", "no-cache")
+	s.w.Header().Set("Connection", "keep-alive")
+	s.w.WriteHeader(http.StatusOK)
+}
+
+// writeEvent writes a single SSE event. An empty event name omits the "event:" line.
+func (s *sseWriter) writeEvent(event string, data interface{}) error {
+	s.start()
+
+	var payload []byte
+	switch d := data.(type) {
+	case string:
+		payload = []byte(d)
+	default:
+		var err error
+		payload, err = json.Marshal(d)
+		if err != nil {
+			return fmt.Errorf("marshal sse event: %w", err)
+		}
+	}
+
+	if event != "" {
+		if _, err := fmt.Fprintf(s.w, "event: %s\n", event); err != nil {
+			return err
+		}
+	}
+	if _, err := fmt.Fprintf(s.w, "data: %s\n\n", payload); err != nil {
+		return err
+	}
+	if s.flusher != nil {
+		s.flusher.Flush()
+	}
+	return nil
+}
+
+// errorStatus returns the HTTP status and error type to answer a failed model call with: the
+// status of the provider's response when the error carries one (e.g. ProviderError), so
+// clients see rate limits and invalid requests as such, and 502 otherwise
+func errorStatus(err error) (int, string) {
+	status := http.StatusBadGateway
+	var httpErr interface{ HTTPStatusCode() int }
+	if errors.As(err, &httpErr) && httpErr.HTTPStatusCode() >= 400 {
+		status = httpErr.HTTPStatusCode()
+	}
+	switch status {
+	case http.StatusBadRequest:
+		return status, "invalid_request_error"
+	case http.StatusUnauthorized:
+		return status, "authentication_error"
+	case http.StatusForbidden:
+		return status, "permission_error"
+	case http.StatusNotFound:
+		return status, "not_found_error"
+	case http.StatusRequestEntityTooLarge:
+		return status, "request_too_large"
+	case http.StatusTooManyRequests:
+		return status, "rate_limit_error"
+	case http.StatusServiceUnavailable, 529:
+		return status, "overloaded_error"
+	default:
+		return status, "api_error"
+	}
+}
+
+// writeJSON writes a JSON response body with the given status code
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+// newID generates a random identifier with the given prefix (e.g. "chatcmpl-")
+func newID(prefix string) string {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return prefix + "0"
+	}
+	return prefix + hex.EncodeToString(b)
+}
@@ -0,0 +1,195 @@
+package jsonschema
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Compat describes the JSON Schema features a provider accepts for tool parameters
+type Compat struct {
+	// InlineRefs replaces local $refs ("#/$defs/...", "#/definitions/...") with the
+	// definitions they point to and drops $defs and definitions. A $ref back into a
+	// definition that is being inlined (a recursive schema) becomes an object without
+	// properties.
+	InlineRefs bool
+	// Unsupported keywords are removed wherever they appear, e.g. "patternProperties"
+	Unsupported []string
+	// Formats lists the "format" values that are kept; others are removed. nil keeps all.
+	Formats []string
+}
+
+// Adapt returns a copy of schema rewritten to what compat allows, and a description of
+// every change made, e.g. for logging. schema is not modified.
+func Adapt(schema map[string]interface{}, compat Compat) (map[string]interface{}, []string) {
+	normalized, ok := normalize(schema).(map[string]interface{})
+	if !ok {
+		return schema, nil
+	}
+	a := &adapter{root: normalized, compat: compat}
+	result := a.adapt(normalized, "$", nil)
+	if compat.InlineRefs {
+		for _, keyword := range []string{"$defs", "definitions"} {
+			if _, ok := result[keyword]; ok {
+				delete(result, keyword)
+				a.changef("$: removed %s after inlining", keyword)
+			}
+		}
+	}
+	return result, a.changes
+}
+
+// adapter holds the state of one Adapt call
+type adapter struct {
+	root    map[string]interface{}
+	compat  Compat
+	changes []string
+}
+
+// changef records a change
+func (a *adapter) changef(format string, args ...interface{}) {
+	a.changes = append(a.changes, fmt.Sprintf(format, args...))
+}
+
+// adapt rewrites one schema at path. inlining holds the $refs being inlined above it.
+func (a *adapter) adapt(schema map[string]interface{}, path string, inlining []string) map[string]interface{} {
+	if ref, ok := schema["$ref"].(string); ok && a.compat.InlineRefs {
+		return a.inline(schema, ref, path, inlining)
+	}
+
+	result := make(map[string]interface{}, len(schema))
+	for _, key := range sortedKeys(schema) {
+		value := schema[key]
+		if a.unsupported(key) {
+			a.changef("%s: removed %s", path, key)
+			continue
+		}
+		if key == "format" && a.compat.Formats != nil {
+			if format, _ := value.(string); !containsString(a.compat.Formats, format) {
+				a.changef("%s: removed format %q", path, format)
+				continue
+			}
+		}
+
+		switch key {
+		case "$defs", "definitions":
+			if a.compat.InlineRefs {
+				// Definitions are adapted where they are inlined; Adapt drops the root's
+				break
+			}
+			fallthrough
+		case "properties", "patternProperties":
+			if subschemas, ok := value.(map[string]interface{}); ok {
+				adapted := make(map[string]interface{}, len(subschemas))
+				for _, name := range sortedKeys(subschemas) {
+					if sub, ok := subschemas[name].(map[string]interface{}); ok {
+						adapted[name] = a.adapt(sub, path+"."+name, inlining)
+					} else {
+						adapted[name] = subschemas[name]
+					}
+				}
+				value = adapted
+			}
+		case "items", "additionalProperties", "not", "if", "then", "else", "contains":
+			if sub, ok := value.(map[string]interface{}); ok {
+				value = a.adapt(sub, path+"."+key, inlining)
+			} else if list, ok := value.([]interface{}); ok {
+				value = a.adaptList(list, path+"."+key, inlining)
+			}
+		case "anyOf", "oneOf", "allOf", "prefixItems":
+			if list, ok := value.([]interface{}); ok {
+				value = a.adaptList(list, path+"."+key, inlining)
+			}
+		}
+		result[key] = value
+	}
+	return result
+}
+
+// adaptList rewrites a list of schemas
+func (a *adapter) adaptList(list []interface{}, path string, inlining []string) []interface{} {
+	adapted := make([]interface{}, len(list))
+	for i, item := range list {
+		if sub, ok := item.(map[string]interface{}); ok {
+			adapted[i] = a.adapt(sub, fmt.Sprintf("%s[%d]", path, i), inlining)
+		} else {
+			adapted[i] = item
+		}
+	}
+	return adapted
+}
+
+// inline replaces schema, which holds ref, with the definition ref points to. Keywords next
+// to the $ref (e.g. a description) override the definition's.
+func (a *adapter) inline(schema map[string]interface{}, ref, path string, inlining []string) map[string]interface{} {
+	siblings := make(map[string]interface{}, len(schema))
+	for key, value := range schema {
+		if key != "$ref" {
+			siblings[key] = value
+		}
+	}
+
+	target, ok := a.resolve(ref)
+	if !ok {
+		a.changef("%s: removed unresolvable $ref %q", path, ref)
+		return a.adapt(siblings, path, inlining)
+	}
+	if ref == "#" || containsString(inlining, ref) {
+		a.changef("%s: replaced recursive $ref %q with an object", path, ref)
+		cut := map[string]interface{}{"type": "object"}
+		if description, ok := target["description"]; ok {
+			cut["description"] = description
+		}
+		for key, value := range siblings {
+			cut[key] = value
+		}
+		return a.adapt(cut, path, inlining)
+	}
+
+	a.changef("%s: inlined $ref %q", path, ref)
+	merged := make(map[string]interface{}, len(target)+len(siblings))
+	for key, value := range target {
+		merged[key] = value
+	}
+	for key, value := range siblings {
+		merged[key] = value
+	}
+	return a.adapt(merged, path, append(inlining[:len(inlining):len(inlining)], ref))
+}
+
+// resolve returns the schema a local $ref points to
+func (a *adapter) resolve(ref string) (map[string]interface{}, bool) {
+	if ref == "#" {
+		return a.root, true
+	}
+	if !strings.HasPrefix(ref, "#/") {
+		return nil, false
+	}
+	var current interface{} = a.root
+	for _, token := range strings.Split(ref[2:], "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		object, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if current, ok = object[token]; !ok {
+			return nil, false
+		}
+	}
+	target, ok := current.(map[string]interface{})
+	return target, ok
+}
+
+// unsupported reports whether keyword is in compat.Unsupported
+func (a *adapter) unsupported(keyword string) bool {
+	return containsString(a.compat.Unsupported, keyword)
+}
+
+// containsString reports whether list contains s
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
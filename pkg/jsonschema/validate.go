@@ -0,0 +1,456 @@
+// Package jsonschema validates decoded JSON values against a JSON schema, as used for
+// structured outputs and tool parameters.
+//
+// The validator covers the subset of JSON Schema that providers accept for structured
+// outputs: type, enum, const, properties, required, additionalProperties, items,
+// prefixItems, string/number/array bounds, pattern, allOf/anyOf/oneOf/not and local
+// $ref to "#/$defs/..." or "#/definitions/...". Unknown keywords (format, title, ...)
+// are ignored.
+//
+// Adapt rewrites a schema for providers that accept less of JSON Schema, e.g. inlining
+// $refs for Gemini.
+package jsonschema
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxRefDepth guards against recursive $ref cycles that never consume input
+const maxRefDepth = 64
+
+// Violation is a single way in which a value fails a schema
+type Violation struct {
+	// Path locates the offending value, e.g. "$.items[2].name"
+	Path string
+	// Message describes the violation
+	Message string
+}
+
+// String returns the violation as "path: message"
+func (v Violation) String() string {
+	return v.Path + ": " + v.Message
+}
+
+// ValidateJSON decodes data and validates it against schema. Invalid JSON is reported
+// as a single violation at the root.
+func ValidateJSON(schema map[string]interface{}, data []byte) []Violation {
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return []Violation{{Path: "$", Message: fmt.Sprintf("invalid JSON: %v", err)}}
+	}
+	return Validate(schema, value)
+}
+
+// Validate checks a value decoded by encoding/json (maps, slices, float64, string, bool
+// and nil) against schema and returns every violation found, or nil if it conforms
+func Validate(schema map[string]interface{}, value interface{}) []Violation {
+	// Schemas built in Go may use []string, int, nested structs, ...; work on the JSON form
+	if normalized, ok := normalize(schema).(map[string]interface{}); ok {
+		schema = normalized
+	}
+	v := &validator{root: schema}
+	v.validate(schema, normalize(value), "$", 0)
+	return v.violations
+}
+
+// validator accumulates violations for one Validate call
+type validator struct {
+	root       map[string]interface{}
+	violations []Violation
+}
+
+// addf records a violation at path
+func (v *validator) addf(path, format string, args ...interface{}) {
+	v.violations = append(v.violations, Violation{Path: path, Message: fmt.Sprintf(format, args...)})
+}
+
+// validate checks value against schema, recording violations under path
+func (v *validator) validate(schema map[string]interface{}, value interface{}, path string, depth int) {
+	if schema == nil {
+		return
+	}
+
+	if ref, ok := schema["$ref"].(string); ok {
+		if depth >= maxRefDepth {
+			v.addf(path, "$ref %q nests too deeply", ref)
+			return
+		}
+		resolved, err := v.resolveRef(ref)
+		if err != nil {
+			v.addf(path, "%v", err)
+			return
+		}
+		v.validate(resolved, value, path, depth+1)
+	}
+
+	if !v.checkType(schema, value, path) {
+		// Other keywords are meaningless once the type is wrong
+		return
+	}
+
+	if enum, ok := schema["enum"].([]interface{}); ok {
+		found := false
+		for _, allowed := range enum {
+			if jsonEqual(allowed, value) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			v.addf(path, "value %s is not one of %s", describe(value), describe(enum))
+		}
+	}
+	if constant, ok := schema["const"]; ok && !jsonEqual(constant, value) {
+		v.addf(path, "value %s must equal %s", describe(value), describe(constant))
+	}
+
+	switch typed := value.(type) {
+	case map[string]interface{}:
+		v.validateObject(schema, typed, path, depth)
+	case []interface{}:
+		v.validateArray(schema, typed, path, depth)
+	case string:
+		v.validateString(schema, typed, path)
+	case float64:
+		v.validateNumber(schema, typed, path)
+	}
+
+	v.validateCombinators(schema, value, path, depth)
+}
+
+// checkType reports whether value matches the schema's "type" keyword, recording a violation if not
+func (v *validator) checkType(schema map[string]interface{}, value interface{}, path string) bool {
+	var types []string
+	switch t := schema["type"].(type) {
+	case string:
+		types = []string{t}
+	case []interface{}:
+		for _, item := range t {
+			if s, ok := item.(string); ok {
+				types = append(types, s)
+			}
+		}
+	default:
+		return true
+	}
+	if nullable, _ := schema["nullable"].(bool); nullable && value == nil {
+		return true
+	}
+	for _, t := range types {
+		if matchesType(t, value) {
+			return true
+		}
+	}
+	v.addf(path, "expected %s, got %s", strings.Join(types, " or "), typeName(value))
+	return false
+}
+
+// validateObject checks the object keywords
+func (v *validator) validateObject(schema map[string]interface{}, obj map[string]interface{}, path string, depth int) {
+	for _, name := range stringList(schema["required"]) {
+		if _, ok := obj[name]; !ok {
+			v.addf(path, "missing required property %q", name)
+		}
+	}
+
+	properties, _ := schema["properties"].(map[string]interface{})
+	for _, name := range sortedKeys(obj) {
+		propertyPath := path + "." + name
+		if propertySchema, ok := properties[name]; ok {
+			if sub, ok := propertySchema.(map[string]interface{}); ok {
+				v.validate(sub, obj[name], propertyPath, depth)
+			}
+			continue
+		}
+		switch additional := schema["additionalProperties"].(type) {
+		case bool:
+			if !additional {
+				v.addf(path, "unexpected property %q", name)
+			}
+		case map[string]interface{}:
+			v.validate(additional, obj[name], propertyPath, depth)
+		}
+	}
+
+	if n, ok := number(schema["minProperties"]); ok && float64(len(obj)) < n {
+		v.addf(path, "must have at least %v properties, got %d", n, len(obj))
+	}
+	if n, ok := number(schema["maxProperties"]); ok && float64(len(obj)) > n {
+		v.addf(path, "must have at most %v properties, got %d", n, len(obj))
+	}
+}
+
+// validateArray checks the array keywords
+func (v *validator) validateArray(schema map[string]interface{}, arr []interface{}, path string, depth int) {
+	prefix, _ := schema["prefixItems"].([]interface{})
+	for i, item := range arr {
+		itemPath := fmt.Sprintf("%s[%d]", path, i)
+		if i < len(prefix) {
+			if sub, ok := prefix[i].(map[string]interface{}); ok {
+				v.validate(sub, item, itemPath, depth)
+			}
+			continue
+		}
+		switch items := schema["items"].(type) {
+		case map[string]interface{}:
+			v.validate(items, item, itemPath, depth)
+		case bool:
+			if !items {
+				v.addf(path, "unexpected item at index %d", i)
+			}
+		}
+	}
+
+	if n, ok := number(schema["minItems"]); ok && float64(len(arr)) < n {
+		v.addf(path, "must have at least %v items, got %d", n, len(arr))
+	}
+	if n, ok := number(schema["maxItems"]); ok && float64(len(arr)) > n {
+		v.addf(path, "must have at most %v items, got %d", n, len(arr))
+	}
+	if unique, _ := schema["uniqueItems"].(bool); unique {
+		for i := 1; i < len(arr); i++ {
+			for j := 0; j < i; j++ {
+				if jsonEqual(arr[i], arr[j]) {
+					v.addf(path, "items %d and %d are equal but items must be unique", j, i)
+				}
+			}
+		}
+	}
+}
+
+// validateString checks the string keywords
+func (v *validator) validateString(schema map[string]interface{}, s string, path string) {
+	length := utf8.RuneCountInString(s)
+	if n, ok := number(schema["minLength"]); ok && float64(length) < n {
+		v.addf(path, "must be at least %v characters, got %d", n, length)
+	}
+	if n, ok := number(schema["maxLength"]); ok && float64(length) > n {
+		v.addf(path, "must be at most %v characters, got %d", n, length)
+	}
+	if pattern, ok := schema["pattern"].(string); ok {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			v.addf(path, "invalid pattern %q in schema: %v", pattern, err)
+		} else if !re.MatchString(s) {
+			v.addf(path, "value %q does not match pattern %q", s, pattern)
+		}
+	}
+}
+
+// validateNumber checks the numeric keywords
+func (v *validator) validateNumber(schema map[string]interface{}, n float64, path string) {
+	if min, ok := number(schema["minimum"]); ok && n < min {
+		v.addf(path, "must be >= %v, got %v", min, n)
+	}
+	if max, ok := number(schema["maximum"]); ok && n > max {
+		v.addf(path, "must be <= %v, got %v", max, n)
+	}
+	if min, ok := number(schema["exclusiveMinimum"]); ok && n <= min {
+		v.addf(path, "must be > %v, got %v", min, n)
+	}
+	if max, ok := number(schema["exclusiveMaximum"]); ok && n >= max {
+		v.addf(path, "must be < %v, got %v", max, n)
+	}
+	if multiple, ok := number(schema["multipleOf"]); ok && multiple > 0 {
+		if q := n / multiple; math.Abs(q-math.Round(q)) > 1e-9 {
+			v.addf(path, "must be a multiple of %v, got %v", multiple, n)
+		}
+	}
+}
+
+// validateCombinators checks allOf, anyOf, oneOf and not
+func (v *validator) validateCombinators(schema map[string]interface{}, value interface{}, path string, depth int) {
+	for _, sub := range schemaList(schema["allOf"]) {
+		v.validate(sub, value, path, depth)
+	}
+	if anyOf := schemaList(schema["anyOf"]); len(anyOf) > 0 {
+		if v.countMatches(anyOf, value, path, depth) == 0 {
+			v.addf(path, "value does not match any of the allowed schemas")
+		}
+	}
+	if oneOf := schemaList(schema["oneOf"]); len(oneOf) > 0 {
+		if n := v.countMatches(oneOf, value, path, depth); n != 1 {
+			v.addf(path, "value must match exactly one schema, matched %d", n)
+		}
+	}
+	if not, ok := schema["not"].(map[string]interface{}); ok {
+		if v.countMatches([]map[string]interface{}{not}, value, path, depth) == 1 {
+			v.addf(path, "value must not match the schema in \"not\"")
+		}
+	}
+}
+
+// countMatches returns how many of schemas value conforms to, without recording violations
+func (v *validator) countMatches(schemas []map[string]interface{}, value interface{}, path string, depth int) int {
+	matches := 0
+	for _, sub := range schemas {
+		probe := &validator{root: v.root}
+		probe.validate(sub, value, path, depth)
+		if len(probe.violations) == 0 {
+			matches++
+		}
+	}
+	return matches
+}
+
+// resolveRef resolves a local JSON pointer such as "#/$defs/address"
+func (v *validator) resolveRef(ref string) (map[string]interface{}, error) {
+	if ref == "#" {
+		return v.root, nil
+	}
+	if !strings.HasPrefix(ref, "#/") {
+		return nil, fmt.Errorf("unsupported $ref %q: only local references are supported", ref)
+	}
+	var current interface{} = v.root
+	for _, token := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		obj, ok := current.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("unresolvable $ref %q", ref)
+		}
+		if current, ok = obj[token]; !ok {
+			return nil, fmt.Errorf("unresolvable $ref %q", ref)
+		}
+	}
+	resolved, ok := current.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("$ref %q does not point to a schema", ref)
+	}
+	return resolved, nil
+}
+
+// matchesType reports whether value is of the JSON schema type t
+func matchesType(t string, value interface{}) bool {
+	switch t {
+	case "object":
+		_, ok := value.(map[string]interface{})
+		return ok
+	case "array":
+		_, ok := value.([]interface{})
+		return ok
+	case "string":
+		_, ok := value.(string)
+		return ok
+	case "number":
+		_, ok := value.(float64)
+		return ok
+	case "integer":
+		n, ok := value.(float64)
+		return ok && n == math.Trunc(n) && !math.IsInf(n, 0)
+	case "boolean":
+		_, ok := value.(bool)
+		return ok
+	case "null":
+		return value == nil
+	}
+	// Unknown types are not enforced
+	return true
+}
+
+// typeName returns the JSON type name of value
+func typeName(value interface{}) string {
+	switch n := value.(type) {
+	case map[string]interface{}:
+		return "object"
+	case []interface{}:
+		return "array"
+	case string:
+		return "string"
+	case float64:
+		if n == math.Trunc(n) {
+			return "integer"
+		}
+		return "number"
+	case bool:
+		return "boolean"
+	case nil:
+		return "null"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+// jsonEqual compares two decoded JSON values
+func jsonEqual(a, b interface{}) bool {
+	return reflect.DeepEqual(a, b)
+}
+
+// normalize converts a Go value to the representation produced by encoding/json
+func normalize(value interface{}) interface{} {
+	switch value.(type) {
+	case nil, bool, string, float64:
+		return value
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return value
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return value
+	}
+	return decoded
+}
+
+// describe renders value as compact JSON for messages
+func describe(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprintf("%v", value)
+	}
+	if len(data) > 100 {
+		// Cut at a rune boundary so non-ASCII values stay valid UTF-8
+		n := 100
+		for n > 0 && !utf8.RuneStart(data[n]) {
+			n--
+		}
+		return string(data[:n]) + "..."
+	}
+	return string(data)
+}
+
+// number returns a numeric schema keyword
+func number(value interface{}) (float64, bool) {
+	n, ok := value.(float64)
+	return n, ok
+}
+
+// stringList returns the strings of a schema keyword holding a list of strings
+func stringList(value interface{}) []string {
+	list, _ := value.([]interface{})
+	result := make([]string, 0, len(list))
+	for _, item := range list {
+		if s, ok := item.(string); ok {
+			result = append(result, s)
+		}
+	}
+	return result
+}
+
+// schemaList returns the schemas of a schema keyword holding a list of schemas
+func schemaList(value interface{}) []map[string]interface{} {
+	list, _ := value.([]interface{})
+	result := make([]map[string]interface{}, 0, len(list))
+	for _, item := range list {
+		if sub, ok := item.(map[string]interface{}); ok {
+			result = append(result, sub)
+		}
+	}
+	return result
+}
+
+// sortedKeys returns the keys of obj in order, so violations are reported deterministically
+func sortedKeys(obj map[string]interface{}) []string {
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,314 @@
+package llmtest
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"io"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+	"github.com/manishiitg/multi-llm-provider-go/pkg/jsonschema"
+)
+
+// ConformanceTimeout bounds each conformance test
+var ConformanceTimeout = 2 * time.Minute
+
+// Capabilities declares what a model supports, which decides the conformance tests run on
+// it. The fields match llmproviders.ModelCapabilities where they overlap.
+type Capabilities struct {
+	Tools bool
+	// ParallelToolCalls: the model calls several tools in one turn
+	ParallelToolCalls bool
+	Streaming         bool
+	// JSONSchema: structured output with WithJSONSchema
+	JSONSchema bool
+	Vision     bool
+}
+
+// Status is the outcome of a conformance test
+type Status string
+
+const (
+	StatusPassed  Status = "passed"
+	StatusFailed  Status = "failed"
+	StatusSkipped Status = "skipped"
+)
+
+// Result is the outcome of one conformance test
+type Result struct {
+	Name     string
+	Status   Status
+	Err      error // why the test failed, or which capability it needs when skipped
+	Duration time.Duration
+}
+
+// conformanceTest is a test of the standard battery and the capabilities it needs
+type conformanceTest struct {
+	name string
+	// requires names the capabilities the test needs, "" for none
+	requires string
+	enabled  func(Capabilities) bool
+	run      func(ctx context.Context, model llmtypes.Model, modelID string) error
+}
+
+// conformanceTests is the standard battery, in the order it runs
+var conformanceTests = []conformanceTest{
+	{name: "plain_text", run: conformPlainText},
+	{name: "tool_call", requires: "Tools", enabled: func(c Capabilities) bool { return c.Tools }, run: conformToolCall},
+	{name: "tool_result", requires: "Tools", enabled: func(c Capabilities) bool { return c.Tools }, run: conformToolResult},
+	{name: "parallel_tool_calls", requires: "Tools, ParallelToolCalls", enabled: func(c Capabilities) bool { return c.Tools && c.ParallelToolCalls }, run: conformParallelToolCalls},
+	{name: "streaming", requires: "Streaming", enabled: func(c Capabilities) bool { return c.Streaming }, run: conformStreaming},
+	{name: "streaming_tool_call", requires: "Streaming, Tools", enabled: func(c Capabilities) bool { return c.Streaming && c.Tools }, run: conformStreamingToolCall},
+	{name: "structured_output", requires: "JSONSchema", enabled: func(c Capabilities) bool { return c.JSONSchema }, run: conformStructuredOutput},
+	{name: "image", requires: "Vision", enabled: func(c Capabilities) bool { return c.Vision }, run: conformImage},
+}
+
+// RunConformance runs the standard battery on model (plain text, tool calls, parallel tool
+// calls, streaming, structured output and image input), skipping the tests that need
+// capabilities caps doesn't declare. Each failed test is reported with t.Errorf, and the
+// pass/fail matrix is logged if t has a Logf method (as *testing.T does). It returns the
+// result of every test, for WriteMatrix.
+//
+//	results := llmtest.RunConformance(t, llm, "gpt-4.1", llmtest.Capabilities{Tools: true, Streaming: true})
+func RunConformance(t TB, model llmtypes.Model, modelID string, caps Capabilities) []Result {
+	t.Helper()
+	results := make([]Result, 0, len(conformanceTests))
+	for _, test := range conformanceTests {
+		if test.enabled != nil && !test.enabled(caps) {
+			results = append(results, Result{Name: test.name, Status: StatusSkipped, Err: fmt.Errorf("requires %s", test.requires)})
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), ConformanceTimeout)
+		start := time.Now()
+		err := test.run(ctx, model, modelID)
+		cancel()
+		result := Result{Name: test.name, Status: StatusPassed, Duration: time.Since(start)}
+		if err != nil {
+			result.Status = StatusFailed
+			result.Err = err
+			t.Errorf("%s %s: %v", modelID, test.name, err)
+		}
+		results = append(results, result)
+	}
+	if logger, ok := t.(interface {
+		Logf(format string, args ...interface{})
+	}); ok {
+		var matrix strings.Builder
+		WriteMatrix(&matrix, modelID, results)
+		logger.Logf("\n%s", matrix.String())
+	}
+	return results
+}
+
+// WriteMatrix writes results as a table: one row per test with its status, duration and
+// the failure or skip reason
+func WriteMatrix(w io.Writer, modelID string, results []Result) {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(tw, "%s\tSTATUS\tDURATION\tDETAIL\n", modelID)
+	for _, result := range results {
+		detail := ""
+		if result.Err != nil {
+			detail = result.Err.Error()
+		}
+		duration := "-"
+		if result.Status != StatusSkipped {
+			duration = result.Duration.Round(time.Millisecond).String()
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", result.Name, result.Status, duration, detail)
+	}
+	tw.Flush()
+}
+
+// conformanceWeatherTool is the tool of the tool call tests
+var conformanceWeatherTool = llmtypes.Tool{
+	Type: "function",
+	Function: &llmtypes.FunctionDefinition{
+		Name:        "get_weather",
+		Description: "Get the current weather for a city",
+		Parameters: llmtypes.NewParameters(map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"city": map[string]interface{}{"type": "string", "description": "City name"},
+			},
+			"required": []string{"city"},
+		}),
+	},
+}
+
+// checkText returns the content of resp's first choice, or an error if it is empty
+func checkText(resp *llmtypes.ContentResponse) (string, error) {
+	choice, err := firstChoice(resp)
+	if err != nil {
+		return "", err
+	}
+	if strings.TrimSpace(choice.Content) == "" {
+		return "", fmt.Errorf("response has no content (stop reason %q, %d tool calls)", choice.StopReason, len(choice.ToolCalls))
+	}
+	return choice.Content, nil
+}
+
+func conformPlainText(ctx context.Context, model llmtypes.Model, modelID string) error {
+	resp, err := model.GenerateContent(ctx, []llmtypes.MessageContent{
+		llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "Reply with one short sentence greeting the user."),
+	}, llmtypes.WithModel(modelID))
+	if err != nil {
+		return err
+	}
+	_, err = checkText(resp)
+	return err
+}
+
+func conformToolCall(ctx context.Context, model llmtypes.Model, modelID string) error {
+	resp, err := model.GenerateContent(ctx, []llmtypes.MessageContent{
+		llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "What is the weather in Paris? Use the get_weather tool."),
+	}, llmtypes.WithModel(modelID), llmtypes.WithTools([]llmtypes.Tool{conformanceWeatherTool}))
+	if err != nil {
+		return err
+	}
+	toolCall, err := CheckToolCall(resp, "get_weather")
+	if err != nil {
+		return err
+	}
+	return CheckValidToolArgs(conformanceWeatherTool, toolCall)
+}
+
+func conformToolResult(ctx context.Context, model llmtypes.Model, modelID string) error {
+	resp, err := model.GenerateContent(ctx, []llmtypes.MessageContent{
+		llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "What is the weather in Paris?"),
+		{Role: llmtypes.ChatMessageTypeAI, Parts: []llmtypes.ContentPart{llmtypes.ToolCall{
+			ID:           "call_weather_1",
+			Type:         "function",
+			FunctionCall: &llmtypes.FunctionCall{Name: "get_weather", Arguments: `{"city":"Paris"}`},
+		}}},
+		{Role: llmtypes.ChatMessageTypeTool, Parts: []llmtypes.ContentPart{llmtypes.ToolCallResponse{
+			ToolCallID: "call_weather_1",
+			Name:       "get_weather",
+			Content:    "Sunny, 22°C",
+		}}},
+	}, llmtypes.WithModel(modelID), llmtypes.WithTools([]llmtypes.Tool{conformanceWeatherTool}))
+	if err != nil {
+		return err
+	}
+	_, err = checkText(resp)
+	return err
+}
+
+func conformParallelToolCalls(ctx context.Context, model llmtypes.Model, modelID string) error {
+	resp, err := model.GenerateContent(ctx, []llmtypes.MessageContent{
+		llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "What is the weather in Paris and in Tokyo? Call get_weather once for each city, in parallel."),
+	}, llmtypes.WithModel(modelID), llmtypes.WithTools([]llmtypes.Tool{conformanceWeatherTool}))
+	if err != nil {
+		return err
+	}
+	if err := CheckParallelToolCalls(resp, 2); err != nil {
+		return err
+	}
+	for _, toolCall := range resp.Choices[0].ToolCalls {
+		if err := CheckValidToolArgs(conformanceWeatherTool, toolCall); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func conformStreaming(ctx context.Context, model llmtypes.Model, modelID string) error {
+	streamChan, chunks := Collect()
+	resp, err := model.GenerateContent(ctx, []llmtypes.MessageContent{
+		llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "Count from 1 to 10, separated by commas."),
+	}, llmtypes.WithModel(modelID), llmtypes.WithStreamingChan(streamChan))
+	streamed := chunks()
+	if err != nil {
+		return err
+	}
+	if _, err := checkText(resp); err != nil {
+		return err
+	}
+	return CheckStreamedEqualsFinal(streamed, resp)
+}
+
+func conformStreamingToolCall(ctx context.Context, model llmtypes.Model, modelID string) error {
+	streamChan, chunks := Collect()
+	resp, err := model.GenerateContent(ctx, []llmtypes.MessageContent{
+		llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "What is the weather in Tokyo? Use the get_weather tool."),
+	}, llmtypes.WithModel(modelID), llmtypes.WithTools([]llmtypes.Tool{conformanceWeatherTool}), llmtypes.WithStreamingChan(streamChan))
+	streamed := chunks()
+	if err != nil {
+		return err
+	}
+	toolCall, err := CheckToolCall(resp, "get_weather")
+	if err != nil {
+		return err
+	}
+	if err := CheckValidToolArgs(conformanceWeatherTool, toolCall); err != nil {
+		return err
+	}
+	return CheckStreamedEqualsFinal(streamed, resp)
+}
+
+// conformanceCitySchema is the schema of the structured output test
+var conformanceCitySchema = map[string]interface{}{
+	"type": "object",
+	"properties": map[string]interface{}{
+		"city":       map[string]interface{}{"type": "string"},
+		"country":    map[string]interface{}{"type": "string"},
+		"population": map[string]interface{}{"type": "integer"},
+	},
+	"required":             []string{"city", "country", "population"},
+	"additionalProperties": false,
+}
+
+func conformStructuredOutput(ctx context.Context, model llmtypes.Model, modelID string) error {
+	resp, err := model.GenerateContent(ctx, []llmtypes.MessageContent{
+		llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, "Describe the capital of France: its name, country and approximate population."),
+	}, llmtypes.WithModel(modelID), llmtypes.WithJSONSchema(conformanceCitySchema, "city", "A city", true))
+	if err != nil {
+		return err
+	}
+	content, err := checkText(resp)
+	if err != nil {
+		return err
+	}
+	if violations := jsonschema.ValidateJSON(conformanceCitySchema, []byte(content)); len(violations) > 0 {
+		return fmt.Errorf("structured output doesn't match the schema: %v (content: %s)", violations, content)
+	}
+	return nil
+}
+
+func conformImage(ctx context.Context, model llmtypes.Model, modelID string) error {
+	// A solid red square, so the answer can be checked
+	img := image.NewRGBA(image.Rect(0, 0, 64, 64))
+	for x := 0; x < 64; x++ {
+		for y := 0; y < 64; y++ {
+			img.Set(x, y, color.RGBA{R: 255, A: 255})
+		}
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return err
+	}
+	resp, err := model.GenerateContent(ctx, []llmtypes.MessageContent{{
+		Role: llmtypes.ChatMessageTypeHuman,
+		Parts: []llmtypes.ContentPart{
+			llmtypes.TextContent{Text: "What color is this image? Answer with one word."},
+			llmtypes.ImageContent{SourceType: "base64", MediaType: "image/png", Data: base64.StdEncoding.EncodeToString(buf.Bytes())},
+		},
+	}}, llmtypes.WithModel(modelID))
+	if err != nil {
+		return err
+	}
+	content, err := checkText(resp)
+	if err != nil {
+		return err
+	}
+	if !strings.Contains(strings.ToLower(content), "red") {
+		return fmt.Errorf("expected the image to be described as red, got %q", content)
+	}
+	return nil
+}
@@ -0,0 +1,307 @@
+// Package llmtest provides assertions for testing model responses: tool calls, their
+// arguments against the tool's schema, parallel tool calls and streamed output. They are
+// the checks the repository's own provider tests use, for conformance tests of your own
+// tools and prompts:
+//
+//	resp, err := llm.GenerateContent(ctx, messages, llmtypes.WithTools(tools))
+//	if err != nil {
+//		t.Fatal(err)
+//	}
+//	call := llmtest.AssertToolCall(t, resp, "get_weather")
+//	llmtest.AssertValidToolArgs(t, weatherTool, call)
+//
+// Each Assert function reports failures with t.Errorf and returns whether it passed; the
+// Check function behind it returns the failure as an error instead, for code that is not
+// a Go test.
+package llmtest
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+	"github.com/manishiitg/multi-llm-provider-go/pkg/jsonschema"
+)
+
+// TB is the part of testing.TB the assertions use
+type TB interface {
+	Helper()
+	Errorf(format string, args ...interface{})
+}
+
+// firstChoice returns the first choice of resp
+func firstChoice(resp *llmtypes.ContentResponse) (*llmtypes.ContentChoice, error) {
+	if resp == nil || len(resp.Choices) == 0 || resp.Choices[0] == nil {
+		return nil, fmt.Errorf("response has no choices")
+	}
+	return resp.Choices[0], nil
+}
+
+// toolCallNames returns the names of toolCalls, for error messages
+func toolCallNames(toolCalls []llmtypes.ToolCall) []string {
+	names := make([]string, 0, len(toolCalls))
+	for _, toolCall := range toolCalls {
+		if toolCall.FunctionCall != nil {
+			names = append(names, toolCall.FunctionCall.Name)
+		}
+	}
+	return names
+}
+
+// CheckToolCall returns the first tool call to name in the first choice of resp
+func CheckToolCall(resp *llmtypes.ContentResponse, name string) (llmtypes.ToolCall, error) {
+	choice, err := firstChoice(resp)
+	if err != nil {
+		return llmtypes.ToolCall{}, err
+	}
+	for _, toolCall := range choice.ToolCalls {
+		if toolCall.FunctionCall != nil && toolCall.FunctionCall.Name == name {
+			return toolCall, nil
+		}
+	}
+	if len(choice.ToolCalls) == 0 {
+		return llmtypes.ToolCall{}, fmt.Errorf("expected a call to %s, got no tool calls (stop reason %q, content %q)", name, choice.StopReason, choice.Content)
+	}
+	return llmtypes.ToolCall{}, fmt.Errorf("expected a call to %s, got calls to %v", name, toolCallNames(choice.ToolCalls))
+}
+
+// AssertToolCall checks that the first choice of resp calls the tool name and returns the
+// call (zero if there is none)
+func AssertToolCall(t TB, resp *llmtypes.ContentResponse, name string) llmtypes.ToolCall {
+	t.Helper()
+	toolCall, err := CheckToolCall(resp, name)
+	if err != nil {
+		t.Errorf("%v", err)
+	}
+	return toolCall
+}
+
+// CheckValidToolArgs checks that toolCall calls tool with a JSON object of arguments that
+// conforms to the tool's parameter schema: required parameters present, types, enums,
+// additionalProperties and the other keywords jsonschema validates
+func CheckValidToolArgs(tool llmtypes.Tool, toolCall llmtypes.ToolCall) error {
+	if tool.Function == nil {
+		return fmt.Errorf("tool has no function definition")
+	}
+	if toolCall.FunctionCall == nil {
+		return fmt.Errorf("tool call %q has no function call", toolCall.ID)
+	}
+	if toolCall.FunctionCall.Name != tool.Function.Name {
+		return fmt.Errorf("tool call is to %s, expected %s", toolCall.FunctionCall.Name, tool.Function.Name)
+	}
+	arguments := toolCall.FunctionCall.Arguments
+	if strings.TrimSpace(arguments) == "" {
+		arguments = "{}"
+	}
+	var args interface{}
+	if err := json.Unmarshal([]byte(arguments), &args); err != nil {
+		return fmt.Errorf("%s arguments are not valid JSON: %v (arguments: %s)", tool.Function.Name, err, arguments)
+	}
+	if _, ok := args.(map[string]interface{}); !ok {
+		return fmt.Errorf("%s arguments are not a JSON object (arguments: %s)", tool.Function.Name, arguments)
+	}
+	if tool.Function.Parameters == nil {
+		return nil
+	}
+	if violations := jsonschema.Validate(parametersSchema(tool.Function.Parameters), args); len(violations) > 0 {
+		messages := make([]string, len(violations))
+		for i, violation := range violations {
+			messages[i] = violation.String()
+		}
+		return fmt.Errorf("%s arguments don't match the tool schema: %s (arguments: %s)", tool.Function.Name, strings.Join(messages, "; "), arguments)
+	}
+	return nil
+}
+
+// AssertValidToolArgs checks toolCall's arguments against tool's schema (CheckValidToolArgs)
+func AssertValidToolArgs(t TB, tool llmtypes.Tool, toolCall llmtypes.ToolCall) bool {
+	t.Helper()
+	if err := CheckValidToolArgs(tool, toolCall); err != nil {
+		t.Errorf("%v", err)
+		return false
+	}
+	return true
+}
+
+// parametersSchema returns params as a JSON schema
+func parametersSchema(params *llmtypes.Parameters) map[string]interface{} {
+	schema := make(map[string]interface{}, len(params.Additional)+4)
+	for key, value := range params.Additional {
+		schema[key] = value
+	}
+	if params.Type != "" {
+		schema["type"] = params.Type
+	}
+	if params.Properties != nil {
+		schema["properties"] = params.Properties
+	}
+	if len(params.Required) > 0 {
+		schema["required"] = params.Required
+	}
+	if params.AdditionalProperties != nil {
+		schema["additionalProperties"] = params.AdditionalProperties
+	}
+	if params.PatternProperties != nil {
+		schema["patternProperties"] = params.PatternProperties
+	}
+	if params.MinProperties != nil {
+		schema["minProperties"] = *params.MinProperties
+	}
+	if params.MaxProperties != nil {
+		schema["maxProperties"] = *params.MaxProperties
+	}
+	return schema
+}
+
+// CheckParallelToolCalls checks that the first choice of resp has at least n tool calls,
+// each with its own non-empty ID
+func CheckParallelToolCalls(resp *llmtypes.ContentResponse, n int) error {
+	choice, err := firstChoice(resp)
+	if err != nil {
+		return err
+	}
+	if len(choice.ToolCalls) < n {
+		return fmt.Errorf("expected at least %d parallel tool calls, got %d %v", n, len(choice.ToolCalls), toolCallNames(choice.ToolCalls))
+	}
+	seen := make(map[string]bool, len(choice.ToolCalls))
+	for i, toolCall := range choice.ToolCalls {
+		if toolCall.ID == "" {
+			return fmt.Errorf("tool call %d has no ID", i)
+		}
+		if seen[toolCall.ID] {
+			return fmt.Errorf("tool call ID %s is used by more than one tool call", toolCall.ID)
+		}
+		seen[toolCall.ID] = true
+	}
+	return nil
+}
+
+// AssertParallelToolCalls checks that resp has at least n tool calls with distinct IDs
+// (CheckParallelToolCalls)
+func AssertParallelToolCalls(t TB, resp *llmtypes.ContentResponse, n int) bool {
+	t.Helper()
+	if err := CheckParallelToolCalls(resp, n); err != nil {
+		t.Errorf("%v", err)
+		return false
+	}
+	return true
+}
+
+// CheckStreamedToolCalls checks that streamed, the tool calls sent as stream chunks, are
+// the tool calls of the final response: same IDs, names and arguments (as JSON values)
+func CheckStreamedToolCalls(streamed, final []llmtypes.ToolCall) error {
+	if len(streamed) != len(final) {
+		return fmt.Errorf("streamed %d tool calls %v, the response has %d %v", len(streamed), toolCallNames(streamed), len(final), toolCallNames(final))
+	}
+	byID := make(map[string]llmtypes.ToolCall, len(streamed))
+	for _, toolCall := range streamed {
+		byID[toolCall.ID] = toolCall
+	}
+	for _, finalCall := range final {
+		streamedCall, ok := byID[finalCall.ID]
+		if !ok {
+			return fmt.Errorf("tool call %s is in the response but was not streamed", finalCall.ID)
+		}
+		if streamedCall.FunctionCall == nil || finalCall.FunctionCall == nil {
+			if streamedCall.FunctionCall != finalCall.FunctionCall {
+				return fmt.Errorf("tool call %s has a function call only when streamed or only in the response", finalCall.ID)
+			}
+			continue
+		}
+		if streamedCall.FunctionCall.Name != finalCall.FunctionCall.Name {
+			return fmt.Errorf("tool call %s is to %s when streamed, %s in the response", finalCall.ID, streamedCall.FunctionCall.Name, finalCall.FunctionCall.Name)
+		}
+		if !sameJSON(streamedCall.FunctionCall.Arguments, finalCall.FunctionCall.Arguments) {
+			return fmt.Errorf("tool call %s has arguments %s when streamed, %s in the response", finalCall.ID, streamedCall.FunctionCall.Arguments, finalCall.FunctionCall.Arguments)
+		}
+	}
+	return nil
+}
+
+// sameJSON reports whether a and b are the same JSON value, or the same string when
+// either is not JSON
+func sameJSON(a, b string) bool {
+	var va, vb interface{}
+	if json.Unmarshal([]byte(a), &va) != nil || json.Unmarshal([]byte(b), &vb) != nil {
+		return a == b
+	}
+	return reflect.DeepEqual(va, vb)
+}
+
+// CheckStreamedEqualsFinal checks that chunks, everything streamed for resp, add up to its
+// first choice: the content chunks concatenate to its content, the tool call chunks are its
+// tool calls (CheckStreamedToolCalls) and a finish chunk, if any, has its stop reason
+func CheckStreamedEqualsFinal(chunks []llmtypes.StreamChunk, resp *llmtypes.ContentResponse) error {
+	choice, err := firstChoice(resp)
+	if err != nil {
+		return err
+	}
+	var content strings.Builder
+	var toolCalls []llmtypes.ToolCall
+	var finish *llmtypes.StreamChunk
+	for i, chunk := range chunks {
+		if chunk.ChoiceIndex != 0 {
+			continue
+		}
+		switch chunk.Type {
+		case llmtypes.StreamChunkTypeContent:
+			content.WriteString(chunk.Content)
+		case llmtypes.StreamChunkTypeToolCall:
+			if chunk.ToolCall != nil {
+				toolCalls = append(toolCalls, *chunk.ToolCall)
+			}
+		case llmtypes.StreamChunkTypeFinish:
+			finish = &chunks[i]
+		}
+	}
+	if content.String() != choice.Content {
+		return fmt.Errorf("streamed content %q, the response has %q", content.String(), choice.Content)
+	}
+	if err := CheckStreamedToolCalls(toolCalls, choice.ToolCalls); err != nil {
+		return err
+	}
+	if finish != nil && finish.StopReason != choice.StopReason {
+		return fmt.Errorf("streamed stop reason %q, the response has %q", finish.StopReason, choice.StopReason)
+	}
+	return nil
+}
+
+// AssertStreamedEqualsFinal checks that the streamed chunks add up to resp
+// (CheckStreamedEqualsFinal)
+func AssertStreamedEqualsFinal(t TB, chunks []llmtypes.StreamChunk, resp *llmtypes.ContentResponse) bool {
+	t.Helper()
+	if err := CheckStreamedEqualsFinal(chunks, resp); err != nil {
+		t.Errorf("%v", err)
+		return false
+	}
+	return true
+}
+
+// Collect returns a channel to pass to llmtypes.WithStreamingChan and a function that waits
+// for the stream to end and returns every chunk sent on it
+//
+//	streamChan, chunks := llmtest.Collect()
+//	resp, err := llm.GenerateContent(ctx, messages, llmtypes.WithStreamingChan(streamChan))
+//	llmtest.AssertStreamedEqualsFinal(t, chunks(), resp)
+func Collect() (chan llmtypes.StreamChunk, func() []llmtypes.StreamChunk) {
+	streamChan := make(chan llmtypes.StreamChunk, 100)
+	done := make(chan []llmtypes.StreamChunk, 1)
+	go func() {
+		var chunks []llmtypes.StreamChunk
+		for chunk := range streamChan {
+			chunks = append(chunks, chunk)
+		}
+		done <- chunks
+	}()
+	var chunks []llmtypes.StreamChunk
+	received := false
+	return streamChan, func() []llmtypes.StreamChunk {
+		if !received {
+			chunks = <-done
+			received = true
+		}
+		return chunks
+	}
+}
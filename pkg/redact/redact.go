@@ -0,0 +1,405 @@
+// Package redact masks personally identifiable information (emails, SSNs,
+// credit card numbers, or custom patterns) before it reaches an external
+// provider, and in the responses that come back.
+//
+// A Redactor plugs into ProviderAwareLLM through the interceptor hooks:
+//
+//	r := redact.New()
+//	resp, err := llm.GenerateContent(ctx, messages, r.Options()...)
+//	original := r.Unredact(resp.Choices[0].Content)
+//
+// Only text is rewritten: text parts, the string values in tool call arguments,
+// tool result content (including the text parts of multimodal tool results) and
+// the returned choice content. Image data is never touched, and placeholders
+// contain no characters that need escaping inside JSON strings.
+//
+// Interceptors do not see streamed chunks; to redact streamed content, stream
+// through RedactStream:
+//
+//	in, finish := r.RedactStream(ctx, streamChan)
+//	resp, err := llm.GenerateContent(ctx, messages, append(r.Options(), llmtypes.WithStreamingChan(in))...)
+//	finish()
+package redact
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+	"github.com/manishiitg/multi-llm-provider-go/pkg/utils"
+)
+
+// Rule describes one kind of sensitive entity to redact
+type Rule struct {
+	// Entity is used in placeholders, e.g. "EMAIL" produces "[EMAIL_1]"
+	Entity string
+	// Pattern matches candidate spans
+	Pattern *regexp.Regexp
+	// Validate optionally filters matches (e.g. a Luhn check); nil accepts every match
+	Validate func(match string) bool
+}
+
+var (
+	emailPattern      = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+	ssnPattern        = regexp.MustCompile(`\b\d{3}-\d{2}-\d{4}\b`)
+	creditCardPattern = regexp.MustCompile(`\b\d(?:[ \-]?\d){12,18}\b`)
+)
+
+// EmailRule matches email addresses
+func EmailRule() Rule {
+	return Rule{Entity: "EMAIL", Pattern: emailPattern}
+}
+
+// SSNRule matches US social security numbers in the form 123-45-6789
+func SSNRule() Rule {
+	return Rule{Entity: "SSN", Pattern: ssnPattern}
+}
+
+// CreditCardRule matches 13-19 digit card numbers (optionally separated by spaces or dashes)
+// that pass the Luhn checksum
+func CreditCardRule() Rule {
+	return Rule{Entity: "CREDIT_CARD", Pattern: creditCardPattern, Validate: luhnValid}
+}
+
+// DefaultRules returns the built-in email, SSN and credit card rules
+func DefaultRules() []Rule {
+	return []Rule{EmailRule(), SSNRule(), CreditCardRule()}
+}
+
+// Redactor masks sensitive spans and remembers what each placeholder stands for,
+// so responses can be unredacted locally. It is safe for concurrent use and the
+// same value always maps to the same placeholder.
+type Redactor struct {
+	rules []Rule
+
+	mu           sync.Mutex
+	placeholders map[string]string // placeholder -> original
+	originals    map[string]string // original -> placeholder
+	counters     map[string]int
+}
+
+// New creates a Redactor with the given rules, or DefaultRules if none are given
+func New(rules ...Rule) *Redactor {
+	if len(rules) == 0 {
+		rules = DefaultRules()
+	}
+	return &Redactor{
+		rules:        rules,
+		placeholders: make(map[string]string),
+		originals:    make(map[string]string),
+		counters:     make(map[string]int),
+	}
+}
+
+// Redact replaces every matched span in text with a placeholder
+func (r *Redactor) Redact(text string) string {
+	if text == "" {
+		return text
+	}
+	for _, rule := range r.rules {
+		if rule.Pattern == nil {
+			continue
+		}
+		text = rule.Pattern.ReplaceAllStringFunc(text, func(match string) string {
+			if rule.Validate != nil && !rule.Validate(match) {
+				return match
+			}
+			return r.placeholderFor(rule.Entity, match)
+		})
+	}
+	return text
+}
+
+// Unredact replaces known placeholders in text with their original values
+func (r *Redactor) Unredact(text string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if len(r.placeholders) == 0 || !strings.Contains(text, "[") {
+		return text
+	}
+
+	// Replace longer placeholders first so "[EMAIL_1]" never clobbers "[EMAIL_10]"
+	keys := make([]string, 0, len(r.placeholders))
+	for placeholder := range r.placeholders {
+		keys = append(keys, placeholder)
+	}
+	sort.Slice(keys, func(i, j int) bool { return len(keys[i]) > len(keys[j]) })
+
+	pairs := make([]string, 0, len(keys)*2)
+	for _, placeholder := range keys {
+		pairs = append(pairs, placeholder, r.placeholders[placeholder])
+	}
+	return strings.NewReplacer(pairs...).Replace(text)
+}
+
+// Mapping returns a copy of the redaction map (placeholder -> original value)
+func (r *Redactor) Mapping() map[string]string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	mapping := make(map[string]string, len(r.placeholders))
+	for placeholder, original := range r.placeholders {
+		mapping[placeholder] = original
+	}
+	return mapping
+}
+
+// RedactMessages returns a copy of messages with text parts, tool call arguments and tool
+// results redacted. The input slice and its parts are not modified.
+func (r *Redactor) RedactMessages(messages []llmtypes.MessageContent) []llmtypes.MessageContent {
+	result := make([]llmtypes.MessageContent, len(messages))
+	for i, msg := range messages {
+		parts := make([]llmtypes.ContentPart, len(msg.Parts))
+		for j, part := range msg.Parts {
+			switch p := part.(type) {
+			case llmtypes.TextContent:
+				p.Text = r.Redact(p.Text)
+				parts[j] = p
+			case llmtypes.ToolCallResponse:
+				p.Content = r.Redact(p.Content)
+				p.Parts = r.redactParts(p.Parts)
+				parts[j] = p
+			case llmtypes.ToolCall:
+				if p.FunctionCall != nil {
+					call := *p.FunctionCall
+					call.Arguments = r.RedactArguments(call.Arguments)
+					p.FunctionCall = &call
+				}
+				parts[j] = p
+			default:
+				// Images are passed through untouched
+				parts[j] = part
+			}
+		}
+		msg.Parts = parts
+		result[i] = msg
+	}
+	return result
+}
+
+// RedactArguments redacts the string values in JSON tool call arguments, leaving keys, numbers
+// and the formatting as they are when nothing matches. Arguments that are not valid JSON are
+// redacted as text.
+func (r *Redactor) RedactArguments(arguments string) string {
+	if strings.TrimSpace(arguments) == "" {
+		return arguments
+	}
+	decoder := json.NewDecoder(strings.NewReader(arguments))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil || decoder.More() {
+		return r.Redact(arguments)
+	}
+	redacted, changed := r.redactValue(value)
+	if !changed {
+		return arguments
+	}
+	var buf bytes.Buffer
+	encoder := json.NewEncoder(&buf)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(redacted); err != nil {
+		return r.Redact(arguments)
+	}
+	return strings.TrimSuffix(buf.String(), "\n")
+}
+
+// RedactResponse redacts the content of every choice in resp in place
+func (r *Redactor) RedactResponse(resp *llmtypes.ContentResponse) {
+	if resp == nil {
+		return
+	}
+	for _, choice := range resp.Choices {
+		if choice != nil {
+			choice.Content = r.Redact(choice.Content)
+		}
+	}
+}
+
+// RequestInterceptor returns an interceptor that redacts outgoing messages
+func (r *Redactor) RequestInterceptor() llmtypes.RequestInterceptor {
+	return func(req *llmtypes.ProviderRequest) error {
+		req.Messages = r.RedactMessages(req.Messages)
+		return nil
+	}
+}
+
+// ResponseInterceptor returns an interceptor that redacts returned content
+func (r *Redactor) ResponseInterceptor() llmtypes.ResponseInterceptor {
+	return func(resp *llmtypes.ContentResponse) error {
+		r.RedactResponse(resp)
+		return nil
+	}
+}
+
+// Options returns call options installing both interceptors
+func (r *Redactor) Options() []llmtypes.CallOption {
+	return []llmtypes.CallOption{
+		llmtypes.WithRequestInterceptor(r.RequestInterceptor()),
+		llmtypes.WithResponseInterceptor(r.ResponseInterceptor()),
+	}
+}
+
+// RedactStream returns a channel to stream into in place of out that redacts streamed content.
+// Content is held back per choice until the end of the last word that cannot be part of a
+// longer match, such as a card number split across chunks, and flushed before the choice's
+// finish chunk and when the stream ends; custom patterns that match across whitespace other
+// than card-number separators may be missed when split across chunks. Other chunks pass
+// through unchanged. Call finish once the call writing to the returned channel has returned;
+// it closes out.
+func (r *Redactor) RedactStream(ctx context.Context, out chan<- llmtypes.StreamChunk) (chan<- llmtypes.StreamChunk, func()) {
+	pending := make(map[int]string)
+	flushChoice := func(index int, emit func(llmtypes.StreamChunk)) {
+		if text := pending[index]; text != "" {
+			emit(llmtypes.StreamChunk{Type: llmtypes.StreamChunkTypeContent, Content: r.Redact(text), ChoiceIndex: index})
+			delete(pending, index)
+		}
+	}
+
+	return utils.RelayStream(ctx, out, func(chunk llmtypes.StreamChunk, emit func(llmtypes.StreamChunk)) {
+		switch chunk.Type {
+		case llmtypes.StreamChunkTypeContent:
+			text := pending[chunk.ChoiceIndex] + chunk.Content
+			end := redactBoundary(text)
+			pending[chunk.ChoiceIndex] = text[end:]
+			if end > 0 {
+				chunk.Content = r.Redact(text[:end])
+				emit(chunk)
+			}
+		case llmtypes.StreamChunkTypeFinish:
+			flushChoice(chunk.ChoiceIndex, emit)
+			emit(chunk)
+		default:
+			emit(chunk)
+		}
+	}, func(emit func(llmtypes.StreamChunk)) {
+		indexes := make([]int, 0, len(pending))
+		for index := range pending {
+			indexes = append(indexes, index)
+		}
+		sort.Ints(indexes)
+		for _, index := range indexes {
+			flushChoice(index, emit)
+		}
+	})
+}
+
+// redactBoundary returns how much of streamed text can be redacted on its own: everything
+// before the last word and, when digits precede it, those digits with the spaces and dashes
+// between them (a card number may continue into the last word) and the word they start in.
+// The boundary always follows whitespace.
+func redactBoundary(text string) int {
+	end := len(text)
+	for end > 0 && !isSpace(text[end-1]) {
+		end--
+	}
+	start, digits := end, false
+	for start > 0 && (isSpace(text[start-1]) || text[start-1] == '-' || isDigit(text[start-1])) {
+		digits = digits || isDigit(text[start-1])
+		start--
+	}
+	if !digits {
+		return end
+	}
+	for start > 0 && !isSpace(text[start-1]) {
+		start--
+	}
+	return start
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\n' || c == '\t' || c == '\r'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// redactValue redacts the strings in a decoded JSON value and reports whether any changed
+func (r *Redactor) redactValue(value interface{}) (interface{}, bool) {
+	switch v := value.(type) {
+	case string:
+		redacted := r.Redact(v)
+		return redacted, redacted != v
+	case map[string]interface{}:
+		changed := false
+		for key, item := range v {
+			redacted, itemChanged := r.redactValue(item)
+			v[key] = redacted
+			changed = changed || itemChanged
+		}
+		return v, changed
+	case []interface{}:
+		changed := false
+		for i, item := range v {
+			redacted, itemChanged := r.redactValue(item)
+			v[i] = redacted
+			changed = changed || itemChanged
+		}
+		return v, changed
+	default:
+		return value, false
+	}
+}
+
+// redactParts returns a copy of multimodal tool result parts with text redacted
+func (r *Redactor) redactParts(parts []llmtypes.ContentPart) []llmtypes.ContentPart {
+	if parts == nil {
+		return nil
+	}
+	result := make([]llmtypes.ContentPart, len(parts))
+	for i, part := range parts {
+		if text, ok := part.(llmtypes.TextContent); ok {
+			text.Text = r.Redact(text.Text)
+			result[i] = text
+			continue
+		}
+		result[i] = part
+	}
+	return result
+}
+
+// placeholderFor returns the placeholder for original, allocating a new one if needed
+func (r *Redactor) placeholderFor(entity, original string) string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if placeholder, ok := r.originals[original]; ok {
+		return placeholder
+	}
+	r.counters[entity]++
+	placeholder := fmt.Sprintf("[%s_%d]", entity, r.counters[entity])
+	r.placeholders[placeholder] = original
+	r.originals[original] = placeholder
+	return placeholder
+}
+
+// luhnValid reports whether the digits in s pass the Luhn checksum
+func luhnValid(s string) bool {
+	sum := 0
+	digits := 0
+	double := false
+	for i := len(s) - 1; i >= 0; i-- {
+		c := s[i]
+		if c < '0' || c > '9' {
+			continue
+		}
+		d := int(c - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		digits++
+		double = !double
+	}
+	return digits >= 13 && sum%10 == 0
+}
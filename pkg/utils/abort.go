@@ -0,0 +1,80 @@
+package utils
+
+import (
+	"context"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+// GenerateUntilToolCall streams a request and cancels it as soon as the first complete
+// tool call is received. The response holds the content streamed so far plus that tool
+// call, with StopReason "tool_calls" and no usage (the provider never reports it).
+// If the provider finishes before any tool call, its response is returned unchanged.
+//
+// The caller's stream channel receives every chunk up to and including the tool call,
+// followed by a finish chunk, and is always closed on return.
+func GenerateUntilToolCall(ctx context.Context, model llmtypes.Model, messages []llmtypes.MessageContent, options []llmtypes.CallOption) (*llmtypes.ContentResponse, error) {
+	opts := &llmtypes.CallOptions{}
+	for _, opt := range options {
+		opt(opts)
+	}
+	streamChan := opts.StreamChan
+	defer close(streamChan)
+
+	callCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	subChan := make(chan llmtypes.StreamChunk, 100)
+	callOptions := append(append([]llmtypes.CallOption{}, options...),
+		llmtypes.WithStreamingChan(subChan),
+		// The inner call must stream normally rather than recurse
+		func(o *llmtypes.CallOptions) { o.AbortOnToolCall = false },
+	)
+
+	aggregator := llmtypes.NewStreamAggregator()
+	aborted := false
+	callDone := make(chan struct{})
+	forwardDone := make(chan struct{})
+	go func() {
+		defer close(forwardDone)
+		forwardStream(subChan, callDone, func(chunk llmtypes.StreamChunk) {
+			// Drop anything the provider sends after the abort
+			if aborted || ctx.Err() != nil {
+				return
+			}
+			aggregator.Add(chunk)
+			select {
+			case streamChan <- chunk:
+			case <-ctx.Done():
+				return
+			}
+			if chunk.Type == llmtypes.StreamChunkTypeToolCall && chunk.ToolCall != nil {
+				aborted = true
+				cancel()
+			}
+		})
+	}()
+
+	resp, err := model.GenerateContent(callCtx, messages, callOptions...)
+	close(callDone)
+	<-forwardDone
+
+	if !aborted {
+		return resp, err
+	}
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+
+	// The request was cancelled by us; answer with what was streamed so far
+	resp = aggregator.Response()
+	for _, choice := range resp.Choices {
+		choice.StopReason = "tool_calls"
+	}
+	select {
+	case streamChan <- llmtypes.NewFinishChunk(resp):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	return resp, nil
+}
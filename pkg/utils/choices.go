@@ -0,0 +1,132 @@
+package utils
+
+import (
+	"context"
+	"sync"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+// GenerateChoicesConcurrently emulates n>1 completions for providers without native
+// support by issuing n concurrent requests and merging their choices in order.
+// Usage is summed across requests. The first error cancels the remaining requests.
+//
+// When streaming, every request streams into its own channel and chunks are forwarded
+// to the caller's channel tagged with the request's ChoiceIndex. The caller's channel
+// is closed once all requests have finished.
+func GenerateChoicesConcurrently(ctx context.Context, model llmtypes.Model, n int, messages []llmtypes.MessageContent, options []llmtypes.CallOption) (*llmtypes.ContentResponse, error) {
+	opts := &llmtypes.CallOptions{}
+	for _, opt := range options {
+		opt(opts)
+	}
+	if opts.StreamChan != nil {
+		defer close(opts.StreamChan)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	responses := make([]*llmtypes.ContentResponse, n)
+	var wg sync.WaitGroup
+	var once sync.Once
+	var firstErr error
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(index int) {
+			defer wg.Done()
+			resp, err := generateChoice(ctx, model, index, messages, options, opts.StreamChan)
+			if err != nil {
+				once.Do(func() {
+					firstErr = err
+					cancel()
+				})
+				return
+			}
+			responses[index] = resp
+		}(i)
+	}
+	wg.Wait()
+
+	if firstErr != nil {
+		return nil, firstErr
+	}
+
+	merged := &llmtypes.ContentResponse{}
+	for _, resp := range responses {
+		if resp == nil {
+			continue
+		}
+		merged.Choices = append(merged.Choices, resp.Choices...)
+		merged.Usage = llmtypes.AddUsage(merged.Usage, resp.Usage)
+		if resp.DryRun {
+			merged.DryRun = true
+			merged.Raw = resp.Raw
+		}
+	}
+	return merged, nil
+}
+
+// generateChoice makes a single-choice request, forwarding its stream chunks to
+// streamChan (if set) tagged with index
+func generateChoice(ctx context.Context, model llmtypes.Model, index int, messages []llmtypes.MessageContent, options []llmtypes.CallOption, streamChan chan<- llmtypes.StreamChunk) (*llmtypes.ContentResponse, error) {
+	// Each request asks for a single choice so the adapter does not recurse
+	callOptions := append(append([]llmtypes.CallOption{}, options...), llmtypes.WithN(1))
+	if streamChan == nil {
+		return model.GenerateContent(ctx, messages, callOptions...)
+	}
+
+	return GenerateStreaming(ctx, model, messages, callOptions, func(chunk llmtypes.StreamChunk) {
+		chunk.ChoiceIndex = index
+		select {
+		case streamChan <- chunk:
+		case <-ctx.Done():
+		}
+	})
+}
+
+// GenerateStreaming makes a streaming request and calls fn for every chunk. It returns
+// once the response is complete and every chunk has been passed to fn.
+func GenerateStreaming(ctx context.Context, model llmtypes.Model, messages []llmtypes.MessageContent, options []llmtypes.CallOption, fn func(llmtypes.StreamChunk)) (*llmtypes.ContentResponse, error) {
+	subChan := make(chan llmtypes.StreamChunk, 100)
+	callOptions := append(append([]llmtypes.CallOption{}, options...), llmtypes.WithStreamingChan(subChan))
+
+	callDone := make(chan struct{})
+	forwardDone := make(chan struct{})
+	go func() {
+		defer close(forwardDone)
+		forwardStream(subChan, callDone, fn)
+	}()
+
+	resp, err := model.GenerateContent(ctx, messages, callOptions...)
+	close(callDone)
+	<-forwardDone
+	return resp, err
+}
+
+// forwardStream calls fn for every chunk received on ch until ch is closed or,
+// once callDone is closed, until the buffered chunks have been drained.
+// Adapters send synchronously, so once the call has returned only buffered chunks
+// remain; the channel may never be closed when an adapter fails early.
+func forwardStream(ch <-chan llmtypes.StreamChunk, callDone <-chan struct{}, fn func(llmtypes.StreamChunk)) {
+	for {
+		select {
+		case chunk, ok := <-ch:
+			if !ok {
+				return
+			}
+			fn(chunk)
+		case <-callDone:
+			for {
+				select {
+				case chunk, ok := <-ch:
+					if !ok {
+						return
+					}
+					fn(chunk)
+				default:
+					return
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package utils
+
+import (
+	"context"
+	"strings"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+// continuePrompt asks a model without assistant prefill support to resume truncated output
+const continuePrompt = "Your previous response was cut off because it reached the maximum output length. Continue exactly where it stopped, without repeating any of it or adding any preamble."
+
+// IsMaxTokensStopReason reports whether a provider stop reason means the output was cut off
+// at the token limit ("max_tokens" for Anthropic and Bedrock, "length" for OpenAI,
+// "MAX_TOKENS" for Gemini)
+func IsMaxTokensStopReason(stopReason string) bool {
+	switch strings.ToLower(stopReason) {
+	case "max_tokens", "length", "max_output_tokens":
+		return true
+	}
+	return false
+}
+
+// GenerateWithAutoContinue generates a response and, while the first choice stops at the
+// token limit, requests up to opts.AutoContinue continuations and stitches them into that
+// choice. A truncated tool call is not continued. If a continuation calls tools, the
+// stitched choice carries those tool calls. Usage is summed across requests.
+//
+// With native set, the partial output is sent as a trailing assistant message that the
+// provider continues (assistant prefill); otherwise the model is asked to continue in a
+// follow-up user message.
+//
+// When streaming, every request's content is forwarded to the caller's channel, followed by
+// a single finish chunk for the stitched response, and the channel is closed on return.
+func GenerateWithAutoContinue(ctx context.Context, model llmtypes.Model, messages []llmtypes.MessageContent, options []llmtypes.CallOption, native bool) (*llmtypes.ContentResponse, error) {
+	opts := &llmtypes.CallOptions{}
+	for _, opt := range options {
+		opt(opts)
+	}
+	streamChan := opts.StreamChan
+	if streamChan != nil {
+		defer close(streamChan)
+	}
+
+	// The inner calls must not continue again
+	callOptions := append(append([]llmtypes.CallOption{}, options...), func(o *llmtypes.CallOptions) { o.AutoContinue = 0 })
+	generate := func(msgs []llmtypes.MessageContent) (*llmtypes.ContentResponse, error) {
+		if streamChan == nil {
+			return model.GenerateContent(ctx, msgs, callOptions...)
+		}
+		return GenerateStreaming(ctx, model, msgs, callOptions, func(chunk llmtypes.StreamChunk) {
+			if chunk.Type == llmtypes.StreamChunkTypeFinish {
+				return
+			}
+			select {
+			case streamChan <- chunk:
+			case <-ctx.Done():
+			}
+		})
+	}
+
+	resp, err := generate(messages)
+	if err != nil {
+		return nil, err
+	}
+
+	for continuation := 0; continuation < opts.AutoContinue; continuation++ {
+		if resp == nil || len(resp.Choices) == 0 || resp.Choices[0] == nil {
+			break
+		}
+		choice := resp.Choices[0]
+		if !IsMaxTokensStopReason(choice.StopReason) || len(choice.ToolCalls) > 0 || choice.Content == "" {
+			break
+		}
+
+		partial := choice.Content
+		continued := append([]llmtypes.MessageContent{}, messages...)
+		if native {
+			// Anthropic rejects a final assistant message ending in whitespace
+			continued = append(continued, llmtypes.TextPart(llmtypes.ChatMessageTypeAI, strings.TrimRight(partial, " \t\r\n")))
+		} else {
+			continued = append(continued,
+				llmtypes.TextPart(llmtypes.ChatMessageTypeAI, partial),
+				llmtypes.TextPart(llmtypes.ChatMessageTypeHuman, continuePrompt),
+			)
+		}
+
+		next, err := generate(continued)
+		if err != nil {
+			return nil, err
+		}
+		if next == nil || len(next.Choices) == 0 || next.Choices[0] == nil {
+			break
+		}
+		stitched := next.Choices[0]
+		stitched.Content = joinContinuation(partial, stitched.Content)
+		resp.Choices[0] = stitched
+		resp.Usage = llmtypes.AddUsage(resp.Usage, next.Usage)
+	}
+
+	if streamChan != nil && resp != nil && len(resp.Choices) > 0 && resp.Choices[0] != nil {
+		select {
+		case streamChan <- llmtypes.StreamChunk{Type: llmtypes.StreamChunkTypeFinish, StopReason: resp.Choices[0].StopReason, Usage: resp.Usage}:
+		case <-ctx.Done():
+		}
+	}
+	return resp, nil
+}
+
+// joinContinuation appends a continuation to partial output, dropping the continuation's
+// leading whitespace when partial already ends with whitespace
+func joinContinuation(partial, continuation string) string {
+	if strings.TrimRight(partial, " \t\r\n") != partial {
+		continuation = strings.TrimLeft(continuation, " \t\r\n")
+	}
+	return partial + continuation
+}
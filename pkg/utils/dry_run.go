@@ -0,0 +1,12 @@
+package utils
+
+import "github.com/manishiitg/multi-llm-provider-go/llmtypes"
+
+// DryRunResponse returns the response for a dry run holding the constructed provider
+// request. The stream channel, if any, is closed since nothing will be streamed.
+func DryRunResponse(opts *llmtypes.CallOptions, request interface{}) *llmtypes.ContentResponse {
+	if opts.StreamChan != nil {
+		close(opts.StreamChan)
+	}
+	return &llmtypes.ContentResponse{DryRun: true, Raw: request}
+}
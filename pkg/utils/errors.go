@@ -0,0 +1,100 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// HTTPError is returned by adapters calling a provider over plain HTTP when the response
+// status is not 200. Its body is parsed like the errors of provider SDKs (ParseErrorBody).
+type HTTPError struct {
+	StatusCode int
+	Body       string
+}
+
+func (e *HTTPError) Error() string {
+	return fmt.Sprintf("API error (status %d): %s", e.StatusCode, e.Body)
+}
+
+// HTTPStatusCode returns the status of the failed response
+func (e *HTTPError) HTTPStatusCode() int {
+	return e.StatusCode
+}
+
+// ErrorBody holds the fields of a provider's JSON error body
+type ErrorBody struct {
+	Code    string
+	Param   string
+	Type    string
+	Message string
+}
+
+// ParseErrorBody parses a provider error body, either the whole body or its "error" object:
+// OpenAI and OpenRouter ({"error":{"code","param","type","message"}}, with a numeric code
+// for OpenRouter), Anthropic ({"type":"error","error":{"type","message"}}) and Google
+// ({"error":{"code","status","message","details"}}, see GoogleErrorDetails, which Vertex
+// may send as a one-element list). ok is false when body is not a JSON object.
+func ParseErrorBody(body string) (parsed ErrorBody, ok bool) {
+	data := []byte(strings.TrimSpace(body))
+	var list []json.RawMessage
+	if json.Unmarshal(data, &list) == nil && len(list) > 0 {
+		data = list[0]
+	}
+	var fields map[string]json.RawMessage
+	if json.Unmarshal(data, &fields) != nil {
+		return parsed, false
+	}
+	var inner map[string]json.RawMessage
+	if raw, found := fields["error"]; found && json.Unmarshal(raw, &inner) == nil {
+		fields = inner
+	}
+
+	parsed.Code = jsonScalar(fields["code"])
+	parsed.Param = jsonScalar(fields["param"])
+	parsed.Type = jsonScalar(fields["type"])
+	parsed.Message = jsonScalar(fields["message"])
+	if status := jsonScalar(fields["status"]); status != "" {
+		// Google: the numeric code is the HTTP status, the status names the error
+		var details []map[string]any
+		_ = json.Unmarshal(fields["details"], &details)
+		parsed.Type = status
+		parsed.Code, parsed.Param = GoogleErrorDetails(status, details)
+	}
+	return parsed, true
+}
+
+// GoogleErrorDetails returns the code and param of a Google API error: the reason of its
+// ErrorInfo detail (e.g. "API_KEY_INVALID"), or its status without one, and the first field
+// of its BadRequest violations
+func GoogleErrorDetails(status string, details []map[string]any) (code, param string) {
+	code = status
+	for _, detail := range details {
+		if reason, ok := detail["reason"].(string); ok && reason != "" {
+			code = reason
+		}
+		violations, _ := detail["fieldViolations"].([]any)
+		for _, violation := range violations {
+			if field, ok := violation.(map[string]any)["field"].(string); ok && param == "" {
+				param = field
+			}
+		}
+	}
+	return code, param
+}
+
+// jsonScalar returns a JSON string or number as a string, "" for anything else
+func jsonScalar(raw json.RawMessage) string {
+	var s string
+	if json.Unmarshal(raw, &s) == nil {
+		return s
+	}
+	var n json.Number
+	if json.Unmarshal(raw, &n) == nil {
+		if _, err := strconv.ParseFloat(n.String(), 64); err == nil {
+			return n.String()
+		}
+	}
+	return ""
+}
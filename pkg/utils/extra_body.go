@@ -0,0 +1,48 @@
+package utils
+
+import "encoding/json"
+
+// MergeExtraBody merges extra fields into a request body map and returns it.
+// Fields already present in body win on conflict; when both values are objects
+// they are merged recursively, so extra can add nested fields without replacing
+// the ones set by typed options. body may be nil.
+func MergeExtraBody(body, extra map[string]interface{}) map[string]interface{} {
+	if body == nil {
+		body = make(map[string]interface{}, len(extra))
+	}
+	for key, value := range extra {
+		existing, ok := body[key]
+		if !ok {
+			body[key] = value
+			continue
+		}
+		existingMap, existingIsMap := existing.(map[string]interface{})
+		valueMap, valueIsMap := value.(map[string]interface{})
+		if existingIsMap && valueIsMap {
+			body[key] = MergeExtraBody(existingMap, valueMap)
+		}
+	}
+	return body
+}
+
+// ExtraBodyFields returns the top-level fields to set on a typed request so that
+// extra is merged into it with typed fields winning on conflict. params is
+// marshaled to JSON to find the fields that are already set. Only keys present
+// in extra are returned.
+func ExtraBodyFields(params interface{}, extra map[string]interface{}) map[string]interface{} {
+	if len(extra) == 0 {
+		return nil
+	}
+
+	var body map[string]interface{}
+	if data, err := json.Marshal(params); err == nil {
+		_ = json.Unmarshal(data, &body)
+	}
+	merged := MergeExtraBody(body, extra)
+
+	fields := make(map[string]interface{}, len(extra))
+	for key := range extra {
+		fields[key] = merged[key]
+	}
+	return fields
+}
@@ -0,0 +1,156 @@
+package utils
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+// DetectImageMediaType sniffs the MIME type of image data, e.g. "image/png". It fails when
+// the data is not a recognized image format.
+func DetectImageMediaType(data []byte) (string, error) {
+	mediaType := http.DetectContentType(data)
+	if !strings.HasPrefix(mediaType, "image/") {
+		return "", fmt.Errorf("unrecognized image format (detected %s); set MediaType explicitly", mediaType)
+	}
+	return mediaType, nil
+}
+
+// ResolveImageMediaTypes fills in the MediaType of base64 images that have none, in message
+// parts and tool results, by sniffing the decoded bytes. A sniffed format that limits does
+// not accept fails with a descriptive error. With convert (WithImageAutoConvert), images in
+// a format limits does not accept, sniffed or explicit, are instead re-encoded as PNG (JPEG
+// if the provider does not accept PNG) at their original size. The input is not modified.
+func ResolveImageMediaTypes(messages []llmtypes.MessageContent, limits ImageLimits, convert bool) ([]llmtypes.MessageContent, error) {
+	var result []llmtypes.MessageContent
+	for i, msg := range messages {
+		parts, err := resolvePartMediaTypes(msg.Parts, limits, convert)
+		if err != nil {
+			return nil, fmt.Errorf("message %d: %w", i, err)
+		}
+		if parts == nil {
+			continue
+		}
+		if result == nil {
+			result = append([]llmtypes.MessageContent{}, messages...)
+		}
+		result[i].Parts = parts
+	}
+	if result == nil {
+		return messages, nil
+	}
+	return result, nil
+}
+
+// resolvePartMediaTypes returns a copy of parts with the base64 images resolved, or nil if no
+// part changed
+func resolvePartMediaTypes(parts []llmtypes.ContentPart, limits ImageLimits, convert bool) ([]llmtypes.ContentPart, error) {
+	var result []llmtypes.ContentPart
+	for k, part := range parts {
+		var resolved llmtypes.ContentPart
+		switch p := part.(type) {
+		case llmtypes.ImageContent:
+			img, changed, err := resolveImage(p, limits, convert)
+			if err != nil {
+				return nil, fmt.Errorf("part %d: %w", k, err)
+			}
+			if !changed {
+				continue
+			}
+			resolved = img
+		case llmtypes.ToolCallResponse:
+			inner, err := resolvePartMediaTypes(p.Parts, limits, convert)
+			if err != nil {
+				return nil, fmt.Errorf("tool result %q (call %s): %w", p.Name, p.ToolCallID, err)
+			}
+			if inner == nil {
+				continue
+			}
+			p.Parts = inner
+			resolved = p
+		default:
+			continue
+		}
+		if result == nil {
+			result = append([]llmtypes.ContentPart{}, parts...)
+		}
+		result[k] = resolved
+	}
+	return result, nil
+}
+
+// resolveImage detects the media type of a base64 image without one and converts it when
+// limits does not accept its format and convert is set. It reports whether img changed.
+func resolveImage(img llmtypes.ImageContent, limits ImageLimits, convert bool) (llmtypes.ImageContent, bool, error) {
+	if img.SourceType != "base64" {
+		return img, false, nil
+	}
+	mediaType := strings.ToLower(img.MediaType)
+	supported := len(limits.MediaTypes) == 0 || containsString(limits.MediaTypes, mediaType)
+	if mediaType != "" && (supported || !convert) {
+		return img, false, nil
+	}
+
+	data, err := base64.StdEncoding.DecodeString(img.Data)
+	if err != nil {
+		return img, false, fmt.Errorf("invalid base64 image data: %w", err)
+	}
+	if mediaType == "" {
+		if mediaType, err = DetectImageMediaType(data); err != nil {
+			return img, false, err
+		}
+		supported = len(limits.MediaTypes) == 0 || containsString(limits.MediaTypes, mediaType)
+	}
+	if supported {
+		img.MediaType = mediaType
+		return img, true, nil
+	}
+	if !convert {
+		return img, false, fmt.Errorf("detected media type %q is not supported by %s (supported: %s); set WithImageAutoConvert to convert it",
+			mediaType, limits.Provider, strings.Join(limits.MediaTypes, ", "))
+	}
+	converted, err := convertImage(data, mediaType, limits)
+	if err != nil {
+		return img, false, err
+	}
+	return converted, true, nil
+}
+
+// FetchImage downloads the image at url and returns its bytes and MIME type. The type comes
+// from the Content-Type header, or is sniffed from the bytes when the header is missing or
+// not an image type (e.g. application/octet-stream).
+func FetchImage(ctx context.Context, url string) ([]byte, string, error) {
+	client := &http.Client{Timeout: 30 * time.Second}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to create request: %w", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to fetch image: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, "", fmt.Errorf("unexpected status code: %d", resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read image data: %w", err)
+	}
+	if mediaType, _, err := mime.ParseMediaType(resp.Header.Get("Content-Type")); err == nil && strings.HasPrefix(mediaType, "image/") {
+		return data, mediaType, nil
+	}
+	mediaType, err := DetectImageMediaType(data)
+	if err != nil {
+		return nil, "", fmt.Errorf("%s: %w", url, err)
+	}
+	return data, mediaType, nil
+}
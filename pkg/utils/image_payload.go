@@ -0,0 +1,183 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"sort"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+// payloadHeadroom is the share of MaxPayloadBytes images are downscaled to, leaving room for
+// the text of the request
+const payloadHeadroom = 0.9
+
+// CheckImagePayload checks the images of messages, in message parts and tool results, against
+// the per-request limits: at most limits.MaxImages images, or maxImages when lower
+// (WithMaxImageCount), and at most limits.MaxPayloadBytes of base64 image data. Too many
+// images fail with a descriptive error. Too much image data fails too unless downsample is
+// set (WithImageDownsampling): the largest base64 images are then downscaled and re-encoded
+// as JPEG until the data fits. URL images count towards the number of images only. The input
+// is not modified.
+func CheckImagePayload(messages []llmtypes.MessageContent, limits ImageLimits, maxImages int, downsample bool) ([]llmtypes.MessageContent, error) {
+	var count, payload int
+	var sizes []int
+	walkImages(messages, func(img llmtypes.ImageContent) {
+		count++
+		if img.SourceType == "base64" {
+			payload += len(img.Data)
+			sizes = append(sizes, len(img.Data))
+		}
+	})
+
+	switch {
+	case maxImages > 0 && count > maxImages && (limits.MaxImages == 0 || maxImages <= limits.MaxImages):
+		return nil, fmt.Errorf("request has %d images, more than the %d allowed by WithMaxImageCount; send them across several requests", count, maxImages)
+	case limits.MaxImages > 0 && count > limits.MaxImages:
+		return nil, fmt.Errorf("request has %d images, more than the %s limit of %d per request; send them across several requests", count, limits.Provider, limits.MaxImages)
+	}
+	if limits.MaxPayloadBytes == 0 || payload <= limits.MaxPayloadBytes {
+		return messages, nil
+	}
+	if !downsample {
+		return nil, fmt.Errorf("request has %d bytes of image data, more than the %s limit of %d; set WithImageDownsampling to downscale the images to fit", payload, limits.Provider, limits.MaxPayloadBytes)
+	}
+
+	// Downscale every image larger than the share left to it once the smaller images are counted
+	maxEncoded := payloadShare(sizes, int(float64(limits.MaxPayloadBytes)*payloadHeadroom))
+	var downscaleErr error
+	result := mapImages(messages, func(img llmtypes.ImageContent) (llmtypes.ImageContent, bool) {
+		if downscaleErr != nil || img.SourceType != "base64" || len(img.Data) <= maxEncoded {
+			return img, false
+		}
+		downscaled, err := downscaleToEncodedSize(img, limits.MaxDimension, maxEncoded)
+		if err != nil {
+			downscaleErr = err
+			return img, false
+		}
+		return downscaled, true
+	})
+	if downscaleErr != nil {
+		return nil, downscaleErr
+	}
+
+	payload = 0
+	walkImages(result, func(img llmtypes.ImageContent) {
+		if img.SourceType == "base64" {
+			payload += len(img.Data)
+		}
+	})
+	if payload > limits.MaxPayloadBytes {
+		return nil, fmt.Errorf("request has %d bytes of image data after downscaling, still more than the %s limit of %d; send the images across several requests", payload, limits.Provider, limits.MaxPayloadBytes)
+	}
+	return result, nil
+}
+
+// payloadShare returns the largest encoded size images may keep for sizes to fit within
+// budget: images no larger than their even share of the budget left keep their size, the
+// others share what remains evenly
+func payloadShare(sizes []int, budget int) int {
+	sorted := append([]int{}, sizes...)
+	sort.Ints(sorted)
+	for i, size := range sorted {
+		share := budget / (len(sorted) - i)
+		if size > share {
+			return share
+		}
+		budget -= size
+	}
+	return budget
+}
+
+// downscaleToEncodedSize downscales img so that its base64 encoding is at most maxEncoded bytes
+func downscaleToEncodedSize(img llmtypes.ImageContent, maxDimension, maxEncoded int) (llmtypes.ImageContent, error) {
+	data, err := base64.StdEncoding.DecodeString(img.Data)
+	if err != nil {
+		return img, fmt.Errorf("invalid base64 image data: %w", err)
+	}
+	decoded, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return img, fmt.Errorf("cannot downscale %s image (%d bytes): %w", img.MediaType, len(data), err)
+	}
+	encoded, err := downscaleImage(decoded, maxDimension, base64.StdEncoding.DecodedLen(maxEncoded))
+	if err != nil {
+		return img, err
+	}
+	return llmtypes.ImageContent{
+		SourceType: "base64",
+		MediaType:  "image/jpeg",
+		Data:       base64.StdEncoding.EncodeToString(encoded),
+	}, nil
+}
+
+// walkImages calls fn for every image of messages, including those in tool results
+func walkImages(messages []llmtypes.MessageContent, fn func(llmtypes.ImageContent)) {
+	for _, msg := range messages {
+		for _, part := range msg.Parts {
+			switch p := part.(type) {
+			case llmtypes.ImageContent:
+				fn(p)
+			case llmtypes.ToolCallResponse:
+				for _, inner := range p.Parts {
+					if img, ok := inner.(llmtypes.ImageContent); ok {
+						fn(img)
+					}
+				}
+			}
+		}
+	}
+}
+
+// mapImages returns messages with every image, including those in tool results, replaced by
+// fn's result. Messages and parts are copied only where an image changed.
+func mapImages(messages []llmtypes.MessageContent, fn func(llmtypes.ImageContent) (llmtypes.ImageContent, bool)) []llmtypes.MessageContent {
+	mapParts := func(parts []llmtypes.ContentPart) []llmtypes.ContentPart {
+		var result []llmtypes.ContentPart
+		for k, part := range parts {
+			img, ok := part.(llmtypes.ImageContent)
+			if !ok {
+				continue
+			}
+			if mapped, changed := fn(img); changed {
+				if result == nil {
+					result = append([]llmtypes.ContentPart{}, parts...)
+				}
+				result[k] = mapped
+			}
+		}
+		return result
+	}
+
+	var result []llmtypes.MessageContent
+	for i, msg := range messages {
+		parts := mapParts(msg.Parts)
+		for j, part := range msg.Parts {
+			resp, ok := part.(llmtypes.ToolCallResponse)
+			if !ok {
+				continue
+			}
+			inner := mapParts(resp.Parts)
+			if inner == nil {
+				continue
+			}
+			if parts == nil {
+				parts = append([]llmtypes.ContentPart{}, msg.Parts...)
+			}
+			resp.Parts = inner
+			parts[j] = resp
+		}
+		if parts == nil {
+			continue
+		}
+		if result == nil {
+			result = append([]llmtypes.MessageContent{}, messages...)
+		}
+		result[i].Parts = parts
+	}
+	if result == nil {
+		return messages
+	}
+	return result
+}
@@ -0,0 +1,236 @@
+package utils
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"strings"
+
+	// Register decoders for image.DecodeConfig and image.Decode
+	_ "image/gif"
+
+	_ "golang.org/x/image/bmp"
+	_ "golang.org/x/image/tiff"
+	_ "golang.org/x/image/webp"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+// ImageLimits are a provider's constraints on the images of a request
+type ImageLimits struct {
+	// Provider names the provider in error messages
+	Provider string
+	// MaxBytes is the largest decoded image size accepted (0 = unlimited)
+	MaxBytes int
+	// MaxDimension is the largest width or height in pixels accepted (0 = unlimited)
+	MaxDimension int
+	// MediaTypes lists the accepted MIME types
+	MediaTypes []string
+	// MaxImages is the most images a request may hold (0 = unlimited)
+	MaxImages int
+	// MaxPayloadBytes is the most base64 image data a request may hold (0 = unlimited)
+	MaxPayloadBytes int
+}
+
+// Documented image limits of each provider
+var (
+	AnthropicImageLimits = ImageLimits{Provider: "anthropic", MaxBytes: 5 * 1024 * 1024, MaxDimension: 8000, MediaTypes: []string{"image/jpeg", "image/png", "image/gif", "image/webp"}, MaxImages: 100, MaxPayloadBytes: 32 * 1024 * 1024}
+	BedrockImageLimits   = ImageLimits{Provider: "bedrock", MaxBytes: 3750 * 1024, MaxDimension: 8000, MediaTypes: []string{"image/jpeg", "image/png", "image/gif", "image/webp"}, MaxImages: 20}
+	OpenAIImageLimits    = ImageLimits{Provider: "openai", MaxBytes: 20 * 1024 * 1024, MediaTypes: []string{"image/jpeg", "image/png", "image/gif", "image/webp"}, MaxImages: 500, MaxPayloadBytes: 50 * 1024 * 1024}
+	GeminiImageLimits    = ImageLimits{Provider: "gemini", MaxBytes: 20 * 1024 * 1024, MediaTypes: []string{"image/jpeg", "image/png", "image/webp", "image/heic", "image/heif"}, MaxImages: 3000, MaxPayloadBytes: 20 * 1024 * 1024}
+)
+
+// PrepareToolResultImages checks the base64 images in tool results against limits before
+// they are sent. With maxBytes > 0 (WithToolResultImageMaxBytes), images larger than
+// maxBytes or the provider limit, or larger than the maximum dimension, are downscaled and
+// re-encoded as JPEG; otherwise they fail with a descriptive error. URL images are not
+// checked. The input is not modified.
+func PrepareToolResultImages(messages []llmtypes.MessageContent, limits ImageLimits, maxBytes int) ([]llmtypes.MessageContent, error) {
+	targetBytes := limits.MaxBytes
+	if maxBytes > 0 && (targetBytes == 0 || maxBytes < targetBytes) {
+		targetBytes = maxBytes
+	}
+
+	var result []llmtypes.MessageContent
+	for i, msg := range messages {
+		for j, part := range msg.Parts {
+			resp, ok := part.(llmtypes.ToolCallResponse)
+			if !ok || len(resp.Images()) == 0 {
+				continue
+			}
+			parts := make([]llmtypes.ContentPart, len(resp.Parts))
+			for k, p := range resp.Parts {
+				img, ok := p.(llmtypes.ImageContent)
+				if !ok || img.SourceType != "base64" {
+					parts[k] = p
+					continue
+				}
+				prepared, err := prepareImage(img, limits, targetBytes, maxBytes > 0)
+				if err != nil {
+					return nil, fmt.Errorf("tool result %q (call %s) image %d: %w", resp.Name, resp.ToolCallID, k, err)
+				}
+				parts[k] = prepared
+			}
+			if result == nil {
+				result = append([]llmtypes.MessageContent{}, messages...)
+			}
+			if sameParts(result[i].Parts, msg.Parts) {
+				result[i].Parts = append([]llmtypes.ContentPart{}, msg.Parts...)
+			}
+			resp.Parts = parts
+			result[i].Parts[j] = resp
+		}
+	}
+	if result == nil {
+		return messages, nil
+	}
+	return result, nil
+}
+
+// sameParts reports whether a and b share their backing array
+func sameParts(a, b []llmtypes.ContentPart) bool {
+	return len(a) > 0 && len(b) > 0 && &a[0] == &b[0]
+}
+
+// prepareImage validates img and downscales it to fit targetBytes and the maximum dimension
+// when downscale is set
+func prepareImage(img llmtypes.ImageContent, limits ImageLimits, targetBytes int, downscale bool) (llmtypes.ImageContent, error) {
+	data, err := base64.StdEncoding.DecodeString(img.Data)
+	if err != nil {
+		return img, fmt.Errorf("invalid base64 image data: %w", err)
+	}
+
+	mediaType := strings.ToLower(img.MediaType)
+	if !containsString(limits.MediaTypes, mediaType) {
+		return img, fmt.Errorf("media type %q is not supported by %s (supported: %s); set WithImageAutoConvert to convert it", img.MediaType, limits.Provider, strings.Join(limits.MediaTypes, ", "))
+	}
+
+	width, height := 0, 0
+	if config, _, err := image.DecodeConfig(bytes.NewReader(data)); err == nil {
+		width, height = config.Width, config.Height
+	}
+	tooLarge := targetBytes > 0 && len(data) > targetBytes
+	tooBig := limits.MaxDimension > 0 && (width > limits.MaxDimension || height > limits.MaxDimension)
+	if !tooLarge && !tooBig {
+		return img, nil
+	}
+
+	if !downscale {
+		if tooBig {
+			return img, fmt.Errorf("image is %dx%d pixels, larger than the %s limit of %dx%d; set WithToolResultImageMaxBytes to downscale automatically",
+				width, height, limits.Provider, limits.MaxDimension, limits.MaxDimension)
+		}
+		return img, fmt.Errorf("image is %d bytes, larger than the %s limit of %d bytes; set WithToolResultImageMaxBytes to downscale automatically",
+			len(data), limits.Provider, targetBytes)
+	}
+
+	decoded, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return img, fmt.Errorf("cannot downscale %s image (%d bytes): %w", img.MediaType, len(data), err)
+	}
+	encoded, err := downscaleImage(decoded, limits.MaxDimension, targetBytes)
+	if err != nil {
+		return img, err
+	}
+	return llmtypes.ImageContent{
+		SourceType: "base64",
+		MediaType:  "image/jpeg",
+		Data:       base64.StdEncoding.EncodeToString(encoded),
+	}, nil
+}
+
+// downscaleImage shrinks src until it fits within maxDimension and its JPEG encoding fits
+// within maxBytes, lowering the quality before the resolution
+func downscaleImage(src image.Image, maxDimension, maxBytes int) ([]byte, error) {
+	bounds := src.Bounds()
+	scale := 1.0
+	if longest := max(bounds.Dx(), bounds.Dy()); maxDimension > 0 && longest > maxDimension {
+		scale = float64(maxDimension) / float64(longest)
+	}
+
+	for attempt := 0; attempt < 8; attempt++ {
+		width := max(1, int(float64(bounds.Dx())*scale))
+		height := max(1, int(float64(bounds.Dy())*scale))
+		resized := src
+		if scale < 1 {
+			resized = resizeImage(src, width, height)
+		}
+		for _, quality := range []int{85, 70, 55} {
+			var buf bytes.Buffer
+			if err := jpeg.Encode(&buf, resized, &jpeg.Options{Quality: quality}); err != nil {
+				return nil, fmt.Errorf("encode downscaled image: %w", err)
+			}
+			if maxBytes <= 0 || buf.Len() <= maxBytes {
+				return buf.Bytes(), nil
+			}
+		}
+		scale *= 0.75
+	}
+	return nil, fmt.Errorf("could not downscale image below %d bytes", maxBytes)
+}
+
+// convertImage decodes data, an image of mediaType, and re-encodes it as PNG, or as JPEG when
+// limits does not accept PNG
+func convertImage(data []byte, mediaType string, limits ImageLimits) (llmtypes.ImageContent, error) {
+	decoded, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return llmtypes.ImageContent{}, fmt.Errorf("cannot convert %s image to a format %s supports: %w", mediaType, limits.Provider, err)
+	}
+	var buf bytes.Buffer
+	target := "image/png"
+	if containsString(limits.MediaTypes, target) {
+		err = png.Encode(&buf, decoded)
+	} else {
+		target = "image/jpeg"
+		err = jpeg.Encode(&buf, decoded, &jpeg.Options{Quality: 90})
+	}
+	if err != nil {
+		return llmtypes.ImageContent{}, fmt.Errorf("encode converted image: %w", err)
+	}
+	return llmtypes.ImageContent{
+		SourceType: "base64",
+		MediaType:  target,
+		Data:       base64.StdEncoding.EncodeToString(buf.Bytes()),
+	}, nil
+}
+
+// resizeImage scales src to width x height by averaging the source pixels covered by each
+// destination pixel
+func resizeImage(src image.Image, width, height int) image.Image {
+	bounds := src.Bounds()
+	dst := image.NewRGBA(image.Rect(0, 0, width, height))
+	xRatio := float64(bounds.Dx()) / float64(width)
+	yRatio := float64(bounds.Dy()) / float64(height)
+	for y := 0; y < height; y++ {
+		y0 := bounds.Min.Y + int(float64(y)*yRatio)
+		y1 := max(y0+1, bounds.Min.Y+int(float64(y+1)*yRatio))
+		for x := 0; x < width; x++ {
+			x0 := bounds.Min.X + int(float64(x)*xRatio)
+			x1 := max(x0+1, bounds.Min.X+int(float64(x+1)*xRatio))
+			var r, g, b, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					cr, cg, cb, ca := src.At(sx, sy).RGBA()
+					r, g, b, a = r+uint64(cr), g+uint64(cg), b+uint64(cb), a+uint64(ca)
+					n++
+				}
+			}
+			dst.Set(x, y, color.RGBA64{R: uint16(r / n), G: uint16(g / n), B: uint16(b / n), A: uint16(a / n)})
+		}
+	}
+	return dst
+}
+
+// containsString reports whether list contains s
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
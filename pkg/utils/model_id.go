@@ -0,0 +1,25 @@
+package utils
+
+import "strings"
+
+// fineTunedPrefix starts OpenAI fine-tuned model IDs, e.g. "ft:gpt-4o-mini-2024-07-18:org::abc123"
+const fineTunedPrefix = "ft:"
+
+// IsFineTunedModel reports whether modelID is an OpenAI fine-tuned model ID
+func IsFineTunedModel(modelID string) bool {
+	return strings.HasPrefix(strings.ToLower(modelID), fineTunedPrefix)
+}
+
+// BaseModelID returns the model a fine-tuned model ID was trained from
+// ("ft:gpt-4o-mini-2024-07-18:org::abc123" -> "gpt-4o-mini-2024-07-18"); other model IDs
+// are returned unchanged
+func BaseModelID(modelID string) string {
+	if !IsFineTunedModel(modelID) {
+		return modelID
+	}
+	base := modelID[len(fineTunedPrefix):]
+	if i := strings.IndexByte(base, ':'); i >= 0 {
+		base = base[:i]
+	}
+	return base
+}
@@ -0,0 +1,43 @@
+package utils
+
+import (
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+// PrefixMessageNames emulates participant names (MessageContent.Name) for providers without a
+// message name field by prefixing the first text part of each named message with "Name: ".
+// Named messages without text get a text part when they are human or system messages; other
+// messages are left as-is. The input is not modified.
+func PrefixMessageNames(messages []llmtypes.MessageContent) []llmtypes.MessageContent {
+	var result []llmtypes.MessageContent
+	for i, msg := range messages {
+		if msg.Name == "" || msg.Role == llmtypes.ChatMessageTypeTool {
+			continue
+		}
+		if result == nil {
+			result = append([]llmtypes.MessageContent{}, messages...)
+		}
+		result[i] = prefixMessageName(msg)
+	}
+	if result == nil {
+		return messages
+	}
+	return result
+}
+
+// prefixMessageName prefixes the first text part of msg with its name
+func prefixMessageName(msg llmtypes.MessageContent) llmtypes.MessageContent {
+	prefix := msg.Name + ": "
+	parts := append([]llmtypes.ContentPart{}, msg.Parts...)
+	for i, part := range parts {
+		if text, ok := part.(llmtypes.TextContent); ok {
+			parts[i] = llmtypes.TextContent{Text: prefix + text.Text}
+			msg.Parts = parts
+			return msg
+		}
+	}
+	if msg.Role == llmtypes.ChatMessageTypeHuman || msg.Role == llmtypes.ChatMessageTypeSystem {
+		msg.Parts = append([]llmtypes.ContentPart{llmtypes.TextContent{Text: prefix}}, parts...)
+	}
+	return msg
+}
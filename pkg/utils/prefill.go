@@ -0,0 +1,76 @@
+package utils
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+// GenerateWithPrefill generates a response whose content starts with prefill.
+//
+// With native set, prefill is sent as a trailing assistant message (without trailing
+// whitespace, which Anthropic rejects) and prepended to the returned content, since
+// the provider only returns the continuation. When streaming, prefill is sent as the
+// first content chunk so the streamed text matches the returned content.
+//
+// Without native support, the model is instructed to begin with prefill and the
+// response is rejected if it does not.
+func GenerateWithPrefill(ctx context.Context, model llmtypes.Model, messages []llmtypes.MessageContent, options []llmtypes.CallOption, native bool) (*llmtypes.ContentResponse, error) {
+	opts := &llmtypes.CallOptions{}
+	for _, opt := range options {
+		opt(opts)
+	}
+
+	// The inner call must not apply the prefill again
+	callOptions := append(append([]llmtypes.CallOption{}, options...), func(o *llmtypes.CallOptions) { o.AssistantPrefill = "" })
+
+	if !native {
+		instruction := fmt.Sprintf("Begin your response with exactly the following text and continue from it, without repeating it:\n%s", opts.AssistantPrefill)
+		prompted := append(append([]llmtypes.MessageContent{}, messages...), llmtypes.TextPart(llmtypes.ChatMessageTypeSystem, instruction))
+		resp, err := model.GenerateContent(ctx, prompted, callOptions...)
+		if err != nil {
+			return nil, err
+		}
+		for i, choice := range resp.Choices {
+			if choice == nil || (choice.Content == "" && len(choice.ToolCalls) > 0) {
+				continue
+			}
+			if !strings.HasPrefix(strings.TrimLeft(choice.Content, " \t\r\n"), strings.TrimSpace(opts.AssistantPrefill)) {
+				return nil, fmt.Errorf("choice %d does not start with the assistant prefill %q", i, opts.AssistantPrefill)
+			}
+		}
+		return resp, nil
+	}
+
+	prefill := strings.TrimRight(opts.AssistantPrefill, " \t\r\n")
+	prefilled := append(append([]llmtypes.MessageContent{}, messages...), llmtypes.TextPart(llmtypes.ChatMessageTypeAI, prefill))
+
+	if opts.StreamChan != nil {
+		n := opts.N
+		if n < 1 {
+			n = 1
+		}
+		for i := 0; i < n; i++ {
+			select {
+			case opts.StreamChan <- llmtypes.StreamChunk{Type: llmtypes.StreamChunkTypeContent, Content: prefill, ChoiceIndex: i}:
+			case <-ctx.Done():
+				close(opts.StreamChan)
+				return nil, ctx.Err()
+			}
+		}
+	}
+
+	resp, err := model.GenerateContent(ctx, prefilled, callOptions...)
+	if err != nil {
+		return nil, err
+	}
+	for _, choice := range resp.Choices {
+		// Only prepend when the model continued from the prefix rather than repeating it
+		if choice != nil && !strings.HasPrefix(choice.Content, prefill) {
+			choice.Content = prefill + choice.Content
+		}
+	}
+	return resp, nil
+}
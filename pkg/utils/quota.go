@@ -0,0 +1,47 @@
+package utils
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// quotaErrorMarkers identify exhausted quota or credits in provider error bodies, as opposed
+// to transient rate limits that share the 429 status
+var quotaErrorMarkers = []string{
+	"insufficient_quota",        // OpenAI, OpenAI-compatible servers
+	"billing_hard_limit",        // OpenAI
+	"billing_not_active",        // OpenAI
+	"billing_error",             // Anthropic
+	"credit balance is too low", // Anthropic
+	"insufficient credits",      // OpenRouter
+	"insufficient_credits",
+}
+
+// IsQuotaErrorMessage reports whether an error message or body says the account is out of
+// quota, credits or billing, which waiting and retrying does not fix
+func IsQuotaErrorMessage(message string) bool {
+	message = strings.ToLower(message)
+	for _, marker := range quotaErrorMarkers {
+		if strings.Contains(message, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// MarkQuotaResponseNotRetryable sets "x-should-retry: false" on 429 and 402 responses whose
+// body reports exhausted quota or credits, so that the OpenAI and Anthropic SDKs fail at once
+// instead of retrying. The body is restored for the SDK to read.
+func MarkQuotaResponseNotRetryable(res *http.Response) {
+	if res == nil || res.Body == nil || (res.StatusCode != http.StatusTooManyRequests && res.StatusCode != http.StatusPaymentRequired) {
+		return
+	}
+	body, err := io.ReadAll(res.Body)
+	res.Body.Close()
+	res.Body = io.NopCloser(bytes.NewReader(body))
+	if err == nil && (res.StatusCode == http.StatusPaymentRequired || IsQuotaErrorMessage(string(body))) {
+		res.Header.Set("x-should-retry", "false")
+	}
+}
@@ -0,0 +1,8 @@
+package utils
+
+import "github.com/manishiitg/multi-llm-provider-go/llmtypes"
+
+// ReasoningVisible reports whether reasoning should be streamed as StreamChunkTypeReasoning chunks
+func ReasoningVisible(opts *llmtypes.CallOptions) bool {
+	return opts.ReasoningVisibility == "visible" || opts.ReasoningVisibility == "summary"
+}
@@ -0,0 +1,186 @@
+package utils
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+// ReasoningTagStripper separates tag-delimited reasoning, such as <thinking>...</thinking>,
+// from text written to it in pieces. Tags split across pieces are held back until they
+// can be recognized. Whitespace right after a closing tag is dropped. With leadingOnly,
+// only spans before any other text are stripped, so JSON that happens to contain a tag is
+// left intact.
+type ReasoningTagStripper struct {
+	tags        []string
+	leadingOnly bool
+	pending     string
+	inTag       string // tag whose span is open, "" outside spans
+	skipSpace   bool
+	seenText    bool
+}
+
+// NewReasoningTagStripper returns a stripper for the given tag names (without brackets)
+func NewReasoningTagStripper(tags []string, leadingOnly bool) *ReasoningTagStripper {
+	return &ReasoningTagStripper{tags: tags, leadingOnly: leadingOnly}
+}
+
+// Write adds text and returns what can be classified so far, as text and reasoning blocks
+// in order
+func (s *ReasoningTagStripper) Write(text string) []llmtypes.ContentBlock {
+	s.pending += text
+	var blocks []llmtypes.ContentBlock
+	for {
+		if s.inTag != "" {
+			closing := "</" + s.inTag + ">"
+			if end := strings.Index(s.pending, closing); end >= 0 {
+				blocks = llmtypes.AppendTextBlock(blocks, llmtypes.ContentBlockTypeReasoning, s.pending[:end])
+				s.pending = s.pending[end+len(closing):]
+				s.inTag = ""
+				s.skipSpace = true
+				continue
+			}
+			keep := partialMarker(s.pending, []string{closing})
+			blocks = llmtypes.AppendTextBlock(blocks, llmtypes.ContentBlockTypeReasoning, s.pending[:len(s.pending)-keep])
+			s.pending = s.pending[len(s.pending)-keep:]
+			return blocks
+		}
+
+		if s.leadingOnly && s.seenText {
+			blocks = s.appendText(blocks, s.pending)
+			s.pending = ""
+			return blocks
+		}
+		start, tag := -1, ""
+		for _, name := range s.tags {
+			if i := strings.Index(s.pending, "<"+name+">"); i >= 0 && (start < 0 || i < start) {
+				start, tag = i, name
+			}
+		}
+		if start >= 0 && !(s.leadingOnly && strings.TrimSpace(s.pending[:start]) != "") {
+			blocks = s.appendText(blocks, s.pending[:start])
+			s.pending = s.pending[start+len(tag)+2:]
+			s.inTag = tag
+			continue
+		}
+		if start >= 0 {
+			// Text comes first: in leadingOnly mode the rest is all text
+			blocks = s.appendText(blocks, s.pending)
+			s.pending = ""
+			return blocks
+		}
+		openings := make([]string, len(s.tags))
+		for i, name := range s.tags {
+			openings[i] = "<" + name + ">"
+		}
+		keep := partialMarker(s.pending, openings)
+		blocks = s.appendText(blocks, s.pending[:len(s.pending)-keep])
+		s.pending = s.pending[len(s.pending)-keep:]
+		return blocks
+	}
+}
+
+// Flush returns what is held back: text, or reasoning if a span is still open
+func (s *ReasoningTagStripper) Flush() []llmtypes.ContentBlock {
+	var blocks []llmtypes.ContentBlock
+	if s.inTag != "" {
+		blocks = llmtypes.AppendTextBlock(blocks, llmtypes.ContentBlockTypeReasoning, s.pending)
+	} else {
+		blocks = s.appendText(blocks, s.pending)
+	}
+	s.pending = ""
+	return blocks
+}
+
+// appendText adds text outside reasoning spans to blocks, dropping the whitespace that
+// follows a closing tag
+func (s *ReasoningTagStripper) appendText(blocks []llmtypes.ContentBlock, text string) []llmtypes.ContentBlock {
+	if s.skipSpace {
+		text = strings.TrimLeftFunc(text, unicode.IsSpace)
+		if text == "" {
+			return blocks
+		}
+		s.skipSpace = false
+	}
+	if strings.TrimSpace(text) != "" {
+		s.seenText = true
+	}
+	return llmtypes.AppendTextBlock(blocks, llmtypes.ContentBlockTypeText, text)
+}
+
+// partialMarker returns the length of the longest suffix of text that begins one of markers
+func partialMarker(text string, markers []string) int {
+	longest := 0
+	for _, marker := range markers {
+		for n := min(len(marker)-1, len(text)); n > longest; n-- {
+			if strings.HasSuffix(text, marker[:n]) {
+				longest = n
+				break
+			}
+		}
+	}
+	return longest
+}
+
+// StripReasoningTags removes the tags' spans from content (see ReasoningTagStripper) and
+// returns the remaining text and the stripped reasoning, joined by newlines
+func StripReasoningTags(content string, tags []string, leadingOnly bool) (text, reasoning string) {
+	stripper := NewReasoningTagStripper(tags, leadingOnly)
+	var textParts, reasoningParts []string
+	for _, block := range append(stripper.Write(content), stripper.Flush()...) {
+		if block.Type == llmtypes.ContentBlockTypeReasoning {
+			reasoningParts = append(reasoningParts, block.Text)
+		} else {
+			textParts = append(textParts, block.Text)
+		}
+	}
+	return strings.Join(textParts, ""), strings.Join(reasoningParts, "\n")
+}
+
+// StripReasoningStream returns a channel to stream into in place of out that removes the
+// tags' spans from content chunks, per choice. The spans are sent as reasoning chunks when
+// visible is set and dropped otherwise. finish works as for FilterStream.
+func StripReasoningStream(ctx context.Context, out chan<- llmtypes.StreamChunk, tags []string, leadingOnly, visible bool) (chan<- llmtypes.StreamChunk, func()) {
+	strippers := make(map[int]*ReasoningTagStripper)
+	send := func(index int, blocks []llmtypes.ContentBlock, emit func(llmtypes.StreamChunk)) {
+		for _, block := range blocks {
+			switch {
+			case block.Type == llmtypes.ContentBlockTypeText:
+				emit(llmtypes.StreamChunk{Type: llmtypes.StreamChunkTypeContent, Content: block.Text, ChoiceIndex: index})
+			case visible:
+				emit(llmtypes.StreamChunk{Type: llmtypes.StreamChunkTypeReasoning, Content: block.Text, ChoiceIndex: index})
+			}
+		}
+	}
+
+	return relayStream(ctx, out, func(chunk llmtypes.StreamChunk, emit func(llmtypes.StreamChunk)) {
+		switch chunk.Type {
+		case llmtypes.StreamChunkTypeContent:
+			stripper := strippers[chunk.ChoiceIndex]
+			if stripper == nil {
+				stripper = NewReasoningTagStripper(tags, leadingOnly)
+				strippers[chunk.ChoiceIndex] = stripper
+			}
+			send(chunk.ChoiceIndex, stripper.Write(chunk.Content), emit)
+		case llmtypes.StreamChunkTypeFinish:
+			if stripper := strippers[chunk.ChoiceIndex]; stripper != nil {
+				send(chunk.ChoiceIndex, stripper.Flush(), emit)
+			}
+			emit(chunk)
+		default:
+			emit(chunk)
+		}
+	}, func(emit func(llmtypes.StreamChunk)) {
+		indexes := make([]int, 0, len(strippers))
+		for index := range strippers {
+			indexes = append(indexes, index)
+		}
+		sort.Ints(indexes)
+		for _, index := range indexes {
+			send(index, strippers[index].Flush(), emit)
+		}
+	})
+}
@@ -0,0 +1,25 @@
+package utils
+
+import "strings"
+
+// contentFilterStopReasons are the stop reasons of responses blocked by a provider's content
+// filter or safety system: OpenAI, Anthropic refusals, Gemini safety blocks and Bedrock
+// guardrails
+var contentFilterStopReasons = map[string]bool{
+	"content_filter":       true,
+	"refusal":              true,
+	"safety":               true,
+	"recitation":           true,
+	"prohibited_content":   true,
+	"blocklist":            true,
+	"spii":                 true,
+	"image_safety":         true,
+	"guardrail_intervened": true,
+	"content_filtered":     true,
+}
+
+// IsContentFilterStopReason reports whether stopReason, as reported by any provider, means the
+// response was blocked by a content filter or safety system
+func IsContentFilterStopReason(stopReason string) bool {
+	return contentFilterStopReasons[strings.ToLower(stopReason)]
+}
@@ -0,0 +1,96 @@
+package utils
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+// BufferStream returns a channel to stream into in place of out that regroups content
+// chunks by mode: whole lines for StreamBufferingLine, whole sentences for
+// StreamBufferingSentence. Content is buffered per choice and flushed at each boundary,
+// before the choice's finish chunk and when the stream ends, so the concatenated content
+// is unchanged. Other chunks, such as tool calls, pass through immediately. Call finish
+// once the call writing to the returned channel has returned; it closes out.
+func BufferStream(ctx context.Context, out chan<- llmtypes.StreamChunk, mode llmtypes.StreamBufferingMode) (chan<- llmtypes.StreamChunk, func()) {
+	boundary := lineBoundary
+	if mode == llmtypes.StreamBufferingSentence {
+		boundary = sentenceBoundary
+	}
+	pending := make(map[int]*strings.Builder)
+	flushChoice := func(index int, emit func(llmtypes.StreamChunk)) {
+		if buf := pending[index]; buf != nil && buf.Len() > 0 {
+			emit(llmtypes.StreamChunk{Type: llmtypes.StreamChunkTypeContent, Content: buf.String(), ChoiceIndex: index})
+			buf.Reset()
+		}
+	}
+
+	return relayStream(ctx, out, func(chunk llmtypes.StreamChunk, emit func(llmtypes.StreamChunk)) {
+		switch chunk.Type {
+		case llmtypes.StreamChunkTypeContent:
+			buf := pending[chunk.ChoiceIndex]
+			if buf == nil {
+				buf = &strings.Builder{}
+				pending[chunk.ChoiceIndex] = buf
+			}
+			buf.WriteString(chunk.Content)
+			text := buf.String()
+			if end := boundary(text); end > 0 {
+				chunk.Content = text[:end]
+				emit(chunk)
+				buf.Reset()
+				buf.WriteString(text[end:])
+			}
+		case llmtypes.StreamChunkTypeFinish:
+			flushChoice(chunk.ChoiceIndex, emit)
+			emit(chunk)
+		default:
+			emit(chunk)
+		}
+	}, func(emit func(llmtypes.StreamChunk)) {
+		indexes := make([]int, 0, len(pending))
+		for index := range pending {
+			indexes = append(indexes, index)
+		}
+		sort.Ints(indexes)
+		for _, index := range indexes {
+			flushChoice(index, emit)
+		}
+	})
+}
+
+// lineBoundary returns the end of the last complete line in text, or 0
+func lineBoundary(text string) int {
+	return strings.LastIndexByte(text, '\n') + 1
+}
+
+// sentenceBoundary returns the end of the last complete sentence in text, or 0. A sentence
+// ends at a newline, or at . ! ? (optionally followed by closing quotes or brackets) once
+// whitespace follows; the whitespace belongs to the sentence. CJK full stops end a
+// sentence on their own.
+func sentenceBoundary(text string) int {
+	end := 0
+	for i, r := range text {
+		switch r {
+		case '\n':
+			end = i + 1
+		case '。', '！', '？':
+			end = i + utf8.RuneLen(r)
+		case '.', '!', '?':
+			j := i + 1
+			next, size := utf8.DecodeRuneInString(text[j:])
+			for size > 0 && strings.ContainsRune(`"')]”’`, next) {
+				j += size
+				next, size = utf8.DecodeRuneInString(text[j:])
+			}
+			if size > 0 && unicode.IsSpace(next) {
+				end = j + size
+			}
+		}
+	}
+	return end
+}
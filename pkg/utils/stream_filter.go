@@ -0,0 +1,86 @@
+package utils
+
+import (
+	"context"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+// FilterStream returns a channel to stream into in place of out: chunks for which keep
+// returns true are forwarded to out, the rest are dropped. Call finish once the call
+// writing to the returned channel has returned; it forwards what is left and closes out.
+// The returned channel may be closed by the writer, as adapters do, or left open.
+func FilterStream(ctx context.Context, out chan<- llmtypes.StreamChunk, keep func(llmtypes.StreamChunk) bool) (chan<- llmtypes.StreamChunk, func()) {
+	return relayStream(ctx, out, func(chunk llmtypes.StreamChunk, emit func(llmtypes.StreamChunk)) {
+		if keep(chunk) {
+			emit(chunk)
+		}
+	}, nil)
+}
+
+// MapStream returns a channel to stream into in place of out: every chunk is passed through
+// transform before it is forwarded to out. finish works as for FilterStream.
+func MapStream(ctx context.Context, out chan<- llmtypes.StreamChunk, transform func(llmtypes.StreamChunk) llmtypes.StreamChunk) (chan<- llmtypes.StreamChunk, func()) {
+	return relayStream(ctx, out, func(chunk llmtypes.StreamChunk, emit func(llmtypes.StreamChunk)) {
+		emit(transform(chunk))
+	}, nil)
+}
+
+// ObserveStream returns a channel to stream into in place of out: every chunk is passed to
+// seen and forwarded to out unchanged. Unlike FilterStream, stop only waits for the relay
+// and leaves out open, so more chunks can be sent to it before the caller closes it.
+func ObserveStream(ctx context.Context, out chan<- llmtypes.StreamChunk, seen func(llmtypes.StreamChunk)) (chan<- llmtypes.StreamChunk, func()) {
+	return startRelay(ctx, out, func(chunk llmtypes.StreamChunk, emit func(llmtypes.StreamChunk)) {
+		seen(chunk)
+		emit(chunk)
+	}, nil)
+}
+
+// RelayStream returns a channel to stream into in place of out: handle is called for every
+// chunk and emits any number of chunks to out, and flush, if set, runs after the last chunk to
+// emit what handle held back. finish works as for FilterStream.
+func RelayStream(ctx context.Context, out chan<- llmtypes.StreamChunk, handle func(chunk llmtypes.StreamChunk, emit func(llmtypes.StreamChunk)), flush func(emit func(llmtypes.StreamChunk))) (chan<- llmtypes.StreamChunk, func()) {
+	return relayStream(ctx, out, handle, flush)
+}
+
+// relayStream forwards the chunks written to the returned channel to out through handle,
+// which emits any number of chunks for each one. flush, if set, runs after the last chunk.
+// finish waits for the relay and closes out; call it once the writer has returned.
+func relayStream(ctx context.Context, out chan<- llmtypes.StreamChunk, handle func(llmtypes.StreamChunk, func(llmtypes.StreamChunk)), flush func(func(llmtypes.StreamChunk))) (chan<- llmtypes.StreamChunk, func()) {
+	in, stop := startRelay(ctx, out, handle, flush)
+	finish := func() {
+		stop()
+		close(out)
+	}
+	return in, finish
+}
+
+// startRelay runs the relay of relayStream; stop waits for it without closing out
+func startRelay(ctx context.Context, out chan<- llmtypes.StreamChunk, handle func(llmtypes.StreamChunk, func(llmtypes.StreamChunk)), flush func(func(llmtypes.StreamChunk))) (chan<- llmtypes.StreamChunk, func()) {
+	in := make(chan llmtypes.StreamChunk, 100)
+	emit := func(chunk llmtypes.StreamChunk) {
+		if ctx.Err() != nil {
+			return
+		}
+		select {
+		case out <- chunk:
+		case <-ctx.Done():
+		}
+	}
+	callDone := make(chan struct{})
+	forwardDone := make(chan struct{})
+	go func() {
+		defer close(forwardDone)
+		forwardStream(in, callDone, func(chunk llmtypes.StreamChunk) {
+			handle(chunk, emit)
+		})
+		if flush != nil {
+			flush(emit)
+		}
+	}()
+	stop := func() {
+		close(callDone)
+		<-forwardDone
+	}
+	return in, stop
+}
@@ -0,0 +1,77 @@
+package utils
+
+import (
+	"context"
+	"time"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+// WatchStream returns a channel to stream into in place of out that watches the gaps between
+// chunks. When no chunk arrives for stall (if > 0), stalled is called once. When none arrives
+// for a heartbeat interval (if > 0), a StreamChunkTypeHeartbeat chunk is sent to out, and
+// again every interval until chunks resume. The wait for the first chunk counts as a gap.
+// finish works as for FilterStream.
+func WatchStream(ctx context.Context, out chan<- llmtypes.StreamChunk, stall, heartbeat time.Duration, stalled func()) (chan<- llmtypes.StreamChunk, func()) {
+	in := make(chan llmtypes.StreamChunk, 100)
+	emit := func(chunk llmtypes.StreamChunk) {
+		if ctx.Err() != nil {
+			return
+		}
+		select {
+		case out <- chunk:
+		case <-ctx.Done():
+		}
+	}
+
+	callDone := make(chan struct{})
+	forwardDone := make(chan struct{})
+	go func() {
+		defer close(forwardDone)
+		var stallC, heartbeatC <-chan time.Time
+		var stallTimer *time.Timer
+		if stall > 0 {
+			stallTimer = time.NewTimer(stall)
+			defer stallTimer.Stop()
+			stallC = stallTimer.C
+		}
+		if heartbeat > 0 {
+			ticker := time.NewTicker(heartbeat)
+			defer ticker.Stop()
+			heartbeatC = ticker.C
+		}
+
+		received := false // a chunk arrived since the last heartbeat tick
+		for {
+			select {
+			case chunk, ok := <-in:
+				if !ok {
+					return
+				}
+				emit(chunk)
+				received = true
+				if stallC != nil {
+					stallTimer.Reset(stall)
+				}
+			case <-heartbeatC:
+				if !received {
+					emit(llmtypes.StreamChunk{Type: llmtypes.StreamChunkTypeHeartbeat})
+				}
+				received = false
+			case <-stallC:
+				stallC = nil
+				stalled()
+			case <-callDone:
+				forwardStream(in, callDone, emit)
+				return
+			}
+		}
+	}()
+
+	finish := func() {
+		close(callDone)
+		<-forwardDone
+		close(out)
+	}
+	return in, finish
+}
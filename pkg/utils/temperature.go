@@ -0,0 +1,58 @@
+package utils
+
+import "strings"
+
+// DefaultTemperature is the temperature providers use when none is sent
+const DefaultTemperature = 1.0
+
+// Highest temperatures providers accept
+const (
+	MaxTemperatureOpenAI    = 2.0 // OpenAI, OpenRouter and Gemini
+	MaxTemperatureAnthropic = 1.0 // Anthropic, Claude on Vertex and the Bedrock Converse API
+)
+
+// openAIModelName returns modelID lower case, without a fine-tuning suffix or an OpenRouter
+// vendor prefix ("openai/o3")
+func openAIModelName(modelID string) string {
+	modelID = strings.ToLower(BaseModelID(modelID))
+	if slash := strings.LastIndexByte(modelID, '/'); slash >= 0 {
+		modelID = modelID[slash+1:]
+	}
+	return modelID
+}
+
+// IsO3O4Model reports whether modelID is an OpenAI o3 or o4 model
+func IsO3O4Model(modelID string) bool {
+	modelID = openAIModelName(modelID)
+	return strings.HasPrefix(modelID, "o3") || strings.HasPrefix(modelID, "o4")
+}
+
+// IsOpenAIReasoningModel reports whether modelID is an OpenAI reasoning model: the o-series
+// (o1, o3, o4) or gpt-5
+func IsOpenAIReasoningModel(modelID string) bool {
+	modelID = openAIModelName(modelID)
+	return IsO3O4Model(modelID) || strings.HasPrefix(modelID, "o1") || strings.HasPrefix(modelID, "gpt-5")
+}
+
+// DefaultTemperatureOnly reports whether modelID only accepts the default temperature
+// (1.0), so a temperature must be omitted: the OpenAI reasoning models
+func DefaultTemperatureOnly(modelID string) bool {
+	return IsOpenAIReasoningModel(modelID)
+}
+
+// UsesMaxCompletionTokens reports whether the output cap for modelID must be sent as
+// max_completion_tokens, which OpenAI reasoning models require (they reject max_tokens).
+// Models addressed with a vendor prefix ("openai/o3") go through OpenRouter, which takes
+// max_tokens and maps it itself.
+func UsesMaxCompletionTokens(modelID string) bool {
+	return !strings.Contains(modelID, "/") && IsOpenAIReasoningModel(modelID)
+}
+
+// ClampTemperature limits temperature to the highest value the provider accepts and reports
+// whether it was changed
+func ClampTemperature(temperature, max float64) (float64, bool) {
+	if temperature > max {
+		return max, true
+	}
+	return temperature, false
+}
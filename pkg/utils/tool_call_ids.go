@@ -0,0 +1,59 @@
+package utils
+
+import (
+	"fmt"
+	"hash/fnv"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+// ToolCallID returns the ID used for a tool call the provider sent without one:
+// "call_<index>_<hash>", where index is the position of the call in the response and hash
+// is derived from the tool name and arguments. The same call always gets the same ID, so
+// recorded responses replay with stable IDs.
+func ToolCallID(index int, name, arguments string) string {
+	h := fnv.New32a()
+	h.Write([]byte(name))
+	h.Write([]byte{0})
+	h.Write([]byte(arguments))
+	return fmt.Sprintf("call_%d_%08x", index, h.Sum32())
+}
+
+// ToolCallIDs assigns IDs to the tool calls of one response as they arrive, e.g. while
+// streaming. The zero value is ready to use.
+type ToolCallIDs struct {
+	seen  map[string]bool
+	count int
+}
+
+// Assign gives call an ID when it has none (see ToolCallID) and makes it unique among the
+// calls assigned so far by appending "_2", "_3", ... to a repeated ID
+func (ids *ToolCallIDs) Assign(call *llmtypes.ToolCall) {
+	if ids.seen == nil {
+		ids.seen = make(map[string]bool)
+	}
+	if call.ID == "" {
+		name, arguments := "", ""
+		if call.FunctionCall != nil {
+			name, arguments = call.FunctionCall.Name, call.FunctionCall.Arguments
+		}
+		call.ID = ToolCallID(ids.count, name, arguments)
+	}
+	if ids.seen[call.ID] {
+		base := call.ID
+		for n := 2; ids.seen[call.ID]; n++ {
+			call.ID = fmt.Sprintf("%s_%d", base, n)
+		}
+	}
+	ids.seen[call.ID] = true
+	ids.count++
+}
+
+// EnsureToolCallIDs gives every call in calls a non-empty ID that is unique within calls.
+// Calls are modified in place; IDs sent by the provider are kept unless they repeat.
+func EnsureToolCallIDs(calls []llmtypes.ToolCall) {
+	var ids ToolCallIDs
+	for i := range calls {
+		ids.Assign(&calls[i])
+	}
+}
@@ -0,0 +1,73 @@
+package utils
+
+import (
+	"fmt"
+	"hash/fnv"
+	"strings"
+)
+
+// MaxToolNameLength is the longest tool name every provider accepts
+const MaxToolNameLength = 64
+
+// IsValidToolName reports whether name follows the tool naming rules shared by all
+// providers: 1 to 64 characters from [a-zA-Z0-9_-], starting with a letter or underscore
+func IsValidToolName(name string) bool {
+	if name == "" || len(name) > MaxToolNameLength || !isToolNameStart(name[0]) {
+		return false
+	}
+	for i := 0; i < len(name); i++ {
+		if !isToolNameChar(name[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// SanitizeToolName returns a valid tool name for name (see IsValidToolName): invalid
+// characters become underscores, a name starting with a digit or hyphen gets a leading
+// underscore, and names over 64 characters are shortened and end with a hash of the
+// original so they stay distinct. Valid names are returned unchanged.
+func SanitizeToolName(name string) string {
+	if IsValidToolName(name) {
+		return name
+	}
+	var sb strings.Builder
+	if name == "" || !isToolNameStart(name[0]) {
+		sb.WriteByte('_')
+	}
+	for i := 0; i < len(name); i++ {
+		if isToolNameChar(name[i]) {
+			sb.WriteByte(name[i])
+		} else if name[i] < 0x80 || name[i] >= 0xC0 {
+			// One underscore per invalid character, not per byte of a multi-byte rune
+			sb.WriteByte('_')
+		}
+	}
+	sanitized := sb.String()
+	if len(sanitized) > MaxToolNameLength {
+		sanitized = ShortenToolName(sanitized, name)
+	}
+	return sanitized
+}
+
+// ShortenToolName truncates name to fit MaxToolNameLength with a suffix derived from
+// original, e.g. to tell apart two names that sanitize to the same string
+func ShortenToolName(name, original string) string {
+	h := fnv.New32a()
+	h.Write([]byte(original))
+	suffix := fmt.Sprintf("_%08x", h.Sum32())
+	if len(name) > MaxToolNameLength-len(suffix) {
+		name = name[:MaxToolNameLength-len(suffix)]
+	}
+	return name + suffix
+}
+
+// isToolNameStart reports whether c may start a tool name
+func isToolNameStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+// isToolNameChar reports whether c may appear in a tool name
+func isToolNameChar(c byte) bool {
+	return isToolNameStart(c) || c == '-' || (c >= '0' && c <= '9')
+}
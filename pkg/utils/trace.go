@@ -0,0 +1,27 @@
+package utils
+
+import (
+	"strings"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+// TraceIDHeader carries the caller's trace ID (WithMetadataTraceID) on provider requests
+const TraceIDHeader = "X-Trace-Id"
+
+// RequestHeaders returns the extra HTTP headers of a call: ExtraHeaders and, when the call
+// has a trace ID, TraceIDHeader (unless ExtraHeaders already sets it)
+func RequestHeaders(opts *llmtypes.CallOptions) map[string]string {
+	if opts.TraceID == "" {
+		return opts.ExtraHeaders
+	}
+	headers := make(map[string]string, len(opts.ExtraHeaders)+1)
+	headers[TraceIDHeader] = opts.TraceID
+	for key, value := range opts.ExtraHeaders {
+		if strings.EqualFold(key, TraceIDHeader) {
+			delete(headers, TraceIDHeader)
+		}
+		headers[key] = value
+	}
+	return headers
+}
@@ -0,0 +1,22 @@
+package utils
+
+import (
+	"context"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+// SendUsageChunk sends the running usage as a StreamChunkTypeUsage chunk when the caller
+// asked for usage chunks (WithStreamUsage). It is a no-op without a stream channel or usage.
+func SendUsageChunk(ctx context.Context, opts *llmtypes.CallOptions, usage *llmtypes.Usage) error {
+	if opts.StreamChan == nil || !opts.StreamUsage || usage == nil {
+		return nil
+	}
+	snapshot := *usage
+	select {
+	case opts.StreamChan <- llmtypes.StreamChunk{Type: llmtypes.StreamChunkTypeUsage, Usage: &snapshot}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
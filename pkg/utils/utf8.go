@@ -0,0 +1,15 @@
+package utils
+
+import "unicode/utf8"
+
+// TruncateUTF8 returns the longest prefix of s of at most n bytes that doesn't end inside a
+// UTF-8 sequence, for previews of text that may hold emoji or CJK
+func TruncateUTF8(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n]
+}
@@ -0,0 +1,292 @@
+// Package vectorindex is a small in-memory similarity-search index for embeddings
+// returned by an EmbeddingModel, for the "embed a few thousand documents and search
+// them" case. Search is an exact cosine-similarity scan, not an approximate nearest
+// neighbour index.
+//
+//	index := vectorindex.New()
+//	for i, embedding := range resp.Embeddings {
+//		_ = index.Add(docIDs[i], embedding)
+//	}
+//	matches := index.Search(query.Embeddings[0], 5)
+//
+// NewQuantized stores vectors as int8 (QuantizeInt8), a quarter of the memory, at a small
+// cost in score precision. Indexes are saved and loaded with Save/Load (gob).
+package vectorindex
+
+import (
+	"encoding/gob"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+	"sync"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+// formatVersion is written by Save and checked by Load
+const formatVersion = 1
+
+// Match is a search result
+type Match struct {
+	ID string
+	// Score is the cosine similarity to the query, from -1 to 1
+	Score float32
+}
+
+// entry is a stored vector: Vector, or Quantized and Scale in a quantized index
+type entry struct {
+	ID        string
+	Vector    []float32
+	Quantized []int8
+	Scale     float32
+	// Norm is the norm of the stored vector (of Quantized when quantized)
+	Norm float64
+}
+
+// VectorIndex stores embeddings by ID and searches them by cosine similarity. It is safe
+// for concurrent use.
+type VectorIndex struct {
+	mu         sync.RWMutex
+	quantized  bool
+	dimensions int
+	entries    []entry
+	positions  map[string]int
+}
+
+// New returns an empty index storing float32 vectors
+func New() *VectorIndex {
+	return &VectorIndex{positions: make(map[string]int)}
+}
+
+// NewQuantized returns an empty index storing int8 vectors (see QuantizeInt8)
+func NewQuantized() *VectorIndex {
+	index := New()
+	index.quantized = true
+	return index
+}
+
+// Quantized reports whether the index stores int8 vectors
+func (x *VectorIndex) Quantized() bool {
+	return x.quantized
+}
+
+// Len returns the number of stored embeddings
+func (x *VectorIndex) Len() int {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	return len(x.entries)
+}
+
+// Dimensions returns the vector size of the index, 0 while it is empty
+func (x *VectorIndex) Dimensions() int {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	return x.dimensions
+}
+
+// Add stores embedding under id, replacing any embedding already stored for id. The
+// first embedding sets the dimensions of the index; embeddings of another size and
+// zero vectors are rejected.
+func (x *VectorIndex) Add(id string, embedding llmtypes.Embedding) error {
+	vector := embedding.Embedding
+	if len(vector) == 0 {
+		return fmt.Errorf("embedding %q is empty", id)
+	}
+	e := entry{ID: id}
+	if x.quantized {
+		e.Quantized, e.Scale = QuantizeInt8(vector)
+		e.Norm = normInt8(e.Quantized)
+	} else {
+		e.Vector = append([]float32(nil), vector...)
+		e.Norm = norm(e.Vector)
+	}
+	if e.Norm == 0 {
+		return fmt.Errorf("embedding %q is a zero vector", id)
+	}
+
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	if x.dimensions != 0 && len(vector) != x.dimensions {
+		return fmt.Errorf("embedding %q has %d dimensions, the index has %d", id, len(vector), x.dimensions)
+	}
+	x.dimensions = len(vector)
+	if i, ok := x.positions[id]; ok {
+		x.entries[i] = e
+		return nil
+	}
+	x.positions[id] = len(x.entries)
+	x.entries = append(x.entries, e)
+	return nil
+}
+
+// Remove deletes the embedding stored for id and reports whether there was one
+func (x *VectorIndex) Remove(id string) bool {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	i, ok := x.positions[id]
+	if !ok {
+		return false
+	}
+	last := len(x.entries) - 1
+	x.entries[i] = x.entries[last]
+	x.positions[x.entries[i].ID] = i
+	x.entries = x.entries[:last]
+	delete(x.positions, id)
+	return true
+}
+
+// Search returns the k stored embeddings most similar to query, best first; equal scores
+// keep insertion order. It returns nil for a zero or empty query and for a query whose
+// size doesn't match the index.
+func (x *VectorIndex) Search(query llmtypes.Embedding, k int) []Match {
+	vector := query.Embedding
+	queryNorm := norm(vector)
+	if k <= 0 || queryNorm == 0 {
+		return nil
+	}
+
+	x.mu.RLock()
+	if len(vector) != x.dimensions {
+		x.mu.RUnlock()
+		return nil
+	}
+	matches := make([]Match, len(x.entries))
+	for i, e := range x.entries {
+		var dot float64
+		if x.quantized {
+			for j, v := range e.Quantized {
+				dot += float64(vector[j]) * float64(v)
+			}
+		} else {
+			for j, v := range e.Vector {
+				dot += float64(vector[j]) * float64(v)
+			}
+		}
+		matches[i] = Match{ID: e.ID, Score: float32(dot / (queryNorm * e.Norm))}
+	}
+	x.mu.RUnlock()
+
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Score > matches[j].Score })
+	if len(matches) > k {
+		matches = matches[:k]
+	}
+	return matches
+}
+
+// snapshot is the gob encoding of an index
+type snapshot struct {
+	Version    int
+	Quantized  bool
+	Dimensions int
+	Entries    []entry
+}
+
+// Save writes the index to w (gob)
+func (x *VectorIndex) Save(w io.Writer) error {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	return gob.NewEncoder(w).Encode(snapshot{Version: formatVersion, Quantized: x.quantized, Dimensions: x.dimensions, Entries: x.entries})
+}
+
+// SaveFile writes the index to the file at path, replacing it
+func (x *VectorIndex) SaveFile(path string) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := x.Save(f); err != nil {
+		f.Close()
+		return fmt.Errorf("save vector index: %w", err)
+	}
+	return f.Close()
+}
+
+// Load reads an index written by Save
+func Load(r io.Reader) (*VectorIndex, error) {
+	var s snapshot
+	if err := gob.NewDecoder(r).Decode(&s); err != nil {
+		return nil, fmt.Errorf("load vector index: %w", err)
+	}
+	if s.Version != formatVersion {
+		return nil, fmt.Errorf("load vector index: unsupported format version %d", s.Version)
+	}
+	x := New()
+	x.quantized = s.Quantized
+	x.dimensions = s.Dimensions
+	x.entries = s.Entries
+	for i, e := range x.entries {
+		x.positions[e.ID] = i
+	}
+	return x, nil
+}
+
+// LoadFile reads an index written by SaveFile
+func LoadFile(path string) (*VectorIndex, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Load(f)
+}
+
+// CosineSimilarity returns the cosine similarity of a and b, 0 if either is a zero vector
+// or their sizes differ
+func CosineSimilarity(a, b []float32) float32 {
+	if len(a) != len(b) {
+		return 0
+	}
+	normA, normB := norm(a), norm(b)
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	var dot float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+	}
+	return float32(dot / (normA * normB))
+}
+
+// QuantizeInt8 scales vector to int8 (symmetric, per vector): vector[i] is approximately
+// float32(quantized[i]) * scale
+func QuantizeInt8(vector []float32) (quantized []int8, scale float32) {
+	var maxAbs float64
+	for _, v := range vector {
+		maxAbs = math.Max(maxAbs, math.Abs(float64(v)))
+	}
+	quantized = make([]int8, len(vector))
+	if maxAbs == 0 {
+		return quantized, 0
+	}
+	for i, v := range vector {
+		quantized[i] = int8(math.Round(float64(v) / maxAbs * 127))
+	}
+	return quantized, float32(maxAbs / 127)
+}
+
+// DequantizeInt8 reverses QuantizeInt8
+func DequantizeInt8(quantized []int8, scale float32) []float32 {
+	vector := make([]float32, len(quantized))
+	for i, v := range quantized {
+		vector[i] = float32(v) * scale
+	}
+	return vector
+}
+
+func norm(vector []float32) float64 {
+	var sum float64
+	for _, v := range vector {
+		sum += float64(v) * float64(v)
+	}
+	return math.Sqrt(sum)
+}
+
+func normInt8(vector []int8) float64 {
+	var sum float64
+	for _, v := range vector {
+		sum += float64(v) * float64(v)
+	}
+	return math.Sqrt(sum)
+}
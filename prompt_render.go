@@ -0,0 +1,84 @@
+package llmproviders
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+	"github.com/manishiitg/multi-llm-provider-go/pkg/utils"
+)
+
+// promptTextLimit is how much of each text the debug rendering of a prompt shows
+const promptTextLimit = 500
+
+// truncateText shortens text to limit bytes without splitting a character, noting how much
+// was left out
+func truncateText(text string, limit int) string {
+	if len(text) <= limit {
+		return text
+	}
+	kept := utils.TruncateUTF8(text, limit)
+	return fmt.Sprintf("%s... [%d more chars]", kept, len(text)-len(kept))
+}
+
+// base64Size returns the decoded size of base64 data, without decoding it
+func base64Size(data string) int {
+	return base64.StdEncoding.DecodedLen(len(data)) - strings.Count(data[max(0, len(data)-2):], "=")
+}
+
+// renderPart renders a message part in one line: text truncated to limit, tool calls with
+// their arguments, tool results and placeholders for images and audio instead of their data
+func renderPart(part llmtypes.ContentPart, limit int) string {
+	switch part := part.(type) {
+	case llmtypes.TextContent:
+		return fmt.Sprintf("%q", truncateText(part.Text, limit))
+	case llmtypes.ImageContent:
+		if part.SourceType == "url" {
+			return fmt.Sprintf("[image url %s]", truncateText(part.Data, 100))
+		}
+		return fmt.Sprintf("[image %s, %d bytes]", part.MediaType, base64Size(part.Data))
+	case llmtypes.AudioContent:
+		return fmt.Sprintf("[audio %s, %d bytes]", part.MediaType, base64Size(part.Data))
+	case llmtypes.ToolCall:
+		if part.FunctionCall == nil {
+			return fmt.Sprintf("[tool call %s]", part.ID)
+		}
+		return fmt.Sprintf("[tool call %s] %s(%s)", part.ID, part.FunctionCall.Name, truncateText(part.FunctionCall.Arguments, limit))
+	case llmtypes.ToolCallResponse:
+		label := "tool result"
+		if part.IsError {
+			label = "tool error"
+		}
+		rendered := fmt.Sprintf("[%s %s] %s: %q", label, part.ToolCallID, part.Name, truncateText(part.Content, limit))
+		for _, nested := range part.Parts {
+			rendered += " + " + renderPart(nested, limit)
+		}
+		return rendered
+	default:
+		return fmt.Sprintf("[%T]", part)
+	}
+}
+
+// renderMessages renders messages for debugging, one line per message with its role and
+// its parts in order (renderPart)
+func renderMessages(messages []llmtypes.MessageContent) string {
+	var result strings.Builder
+	for i, msg := range messages {
+		fmt.Fprintf(&result, "   [%d] %s", i+1, msg.Role)
+		if msg.Name != "" {
+			fmt.Fprintf(&result, " (%s)", msg.Name)
+		}
+		result.WriteString(":")
+		for j, part := range msg.Parts {
+			if j > 0 {
+				result.WriteString(" |")
+			}
+			result.WriteString(" " + renderPart(part, promptTextLimit))
+		}
+		if i < len(messages)-1 {
+			result.WriteString("\n")
+		}
+	}
+	return result.String()
+}
@@ -0,0 +1,201 @@
+package llmproviders
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/manishiitg/multi-llm-provider-go/pkg/utils"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	anthropicoption "github.com/anthropics/anthropic-sdk-go/option"
+	"github.com/aws/smithy-go"
+	openaisdk "github.com/openai/openai-go/v3"
+	"github.com/openai/openai-go/v3/option"
+	"google.golang.org/genai"
+)
+
+// ErrQuotaExceeded matches (errors.Is) provider errors caused by exhausted quota, credits or
+// billing rather than a transient rate limit. Retrying on the same provider does not help.
+var ErrQuotaExceeded = errors.New("provider quota or credits exhausted")
+
+// ErrModelNotFound is returned (wrapped in ProviderError, see errors.As) when the provider
+// doesn't know the model, typically a typo in the model ID, or doesn't serve it in the
+// configured region. Retrying the same model doesn't help, but fallback models are tried.
+type ErrModelNotFound struct {
+	Provider Provider
+	ModelID  string
+	Err      error
+}
+
+func (e *ErrModelNotFound) Error() string {
+	return fmt.Sprintf("model %q not found on %s, check the model ID and that the model is available in the configured region: %v", e.ModelID, e.Provider, e.Err)
+}
+
+func (e *ErrModelNotFound) Unwrap() error {
+	return e.Err
+}
+
+// ProviderError is returned by ProviderAwareLLM when the provider call fails. It wraps the
+// provider SDK error, which errors.As still finds, and classifies it.
+type ProviderError struct {
+	Provider Provider
+	ModelID  string
+	// StatusCode is the HTTP status of the failed request, 0 if unknown
+	StatusCode int
+	// Retryable reports whether the call may succeed if repeated (see IsRetryableError)
+	Retryable bool
+	// QuotaExceeded is set when the account is out of quota or credits (ErrQuotaExceeded)
+	QuotaExceeded bool
+	// Code, Param, Type and Message come from the provider's error body, "" when it doesn't
+	// set them: e.g. Code "context_length_exceeded" and Param "messages" (OpenAI), Type
+	// "overloaded_error" (Anthropic), Type "INVALID_ARGUMENT" (Gemini), Code
+	// "ThrottlingException" (Bedrock)
+	Code    string
+	Param   string
+	Type    string
+	Message string
+	Err     error
+}
+
+func (e *ProviderError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *ProviderError) Unwrap() error {
+	return e.Err
+}
+
+// HTTPStatusCode returns StatusCode, for servers relaying the error to their clients
+func (e *ProviderError) HTTPStatusCode() int {
+	return e.StatusCode
+}
+
+// Is reports whether the error is ErrQuotaExceeded
+func (e *ProviderError) Is(target error) bool {
+	return target == ErrQuotaExceeded && e.QuotaExceeded
+}
+
+// newProviderError classifies err, returned by the provider call for modelID
+func newProviderError(provider Provider, modelID string, err error) *ProviderError {
+	var providerErr *ProviderError
+	if errors.As(err, &providerErr) {
+		return providerErr
+	}
+	details := errorDetails(err)
+	err = modelNotFoundError(provider, modelID, err)
+	return &ProviderError{
+		Provider:      provider,
+		ModelID:       modelID,
+		StatusCode:    errorStatusCode(err),
+		Retryable:     IsRetryableError(err),
+		QuotaExceeded: IsQuotaError(err),
+		Code:          details.Code,
+		Param:         details.Param,
+		Type:          details.Type,
+		Message:       details.Message,
+		Err:           err,
+	}
+}
+
+// errorDetails returns the fields of the error body carried by a provider SDK error
+func errorDetails(err error) utils.ErrorBody {
+	var openaiErr *openaisdk.Error
+	if errors.As(err, &openaiErr) {
+		details := utils.ErrorBody{Code: openaiErr.Code, Param: openaiErr.Param, Type: openaiErr.Type, Message: openaiErr.Message}
+		if parsed, ok := utils.ParseErrorBody(openaiErr.RawJSON()); ok && details.Code == "" {
+			// OpenRouter sends numeric codes, which the SDK leaves empty
+			details.Code = parsed.Code
+		}
+		return details
+	}
+	var anthropicErr *anthropic.Error
+	if errors.As(err, &anthropicErr) {
+		details, _ := utils.ParseErrorBody(anthropicErr.RawJSON())
+		return details
+	}
+	var genaiErr genai.APIError
+	if errors.As(err, &genaiErr) {
+		code, param := utils.GoogleErrorDetails(genaiErr.Status, genaiErr.Details)
+		return utils.ErrorBody{Code: code, Param: param, Type: genaiErr.Status, Message: genaiErr.Message}
+	}
+	var smithyErr smithy.APIError
+	if errors.As(err, &smithyErr) {
+		return utils.ErrorBody{Code: smithyErr.ErrorCode(), Message: smithyErr.ErrorMessage()}
+	}
+	var httpErr *utils.HTTPError
+	if errors.As(err, &httpErr) {
+		details, _ := utils.ParseErrorBody(httpErr.Body)
+		return details
+	}
+	return utils.ErrorBody{}
+}
+
+// modelNotFoundError wraps err in ErrModelNotFound when it says modelID doesn't exist
+func modelNotFoundError(provider Provider, modelID string, err error) error {
+	var notFound *ErrModelNotFound
+	if errors.As(err, &notFound) || !IsModelNotFoundError(err) {
+		return err
+	}
+	return &ErrModelNotFound{Provider: provider, ModelID: modelID, Err: err}
+}
+
+// modelNotFoundCodes are the error codes and types providers use for unknown models
+var modelNotFoundCodes = map[string]bool{
+	"model_not_found":           true, // OpenAI
+	"not_found_error":           true, // Anthropic
+	"NOT_FOUND":                 true, // Gemini
+	"ResourceNotFoundException": true, // Bedrock
+}
+
+// IsModelNotFoundError reports whether err says the model doesn't exist or isn't served
+// (ErrModelNotFound, a 404 or a provider's model-not-found code), e.g. a mistyped model ID or
+// a model missing from the region
+func IsModelNotFoundError(err error) bool {
+	if err == nil {
+		return false
+	}
+	var notFound *ErrModelNotFound
+	if errors.As(err, &notFound) || errorStatusCode(err) == http.StatusNotFound {
+		return true
+	}
+	details := errorDetails(err)
+	if modelNotFoundCodes[details.Code] || modelNotFoundCodes[details.Type] {
+		return true
+	}
+	message := strings.ToLower(details.Message)
+	// Bedrock ValidationException and OpenRouter 400 for unknown model IDs
+	return strings.Contains(message, "model identifier is invalid") || strings.Contains(message, "is not a valid model id")
+}
+
+// IsQuotaError reports whether err says the account is out of quota, credits or billing
+// (e.g. OpenAI insufficient_quota, Anthropic billing errors, OpenRouter 402), as opposed to a
+// transient rate limit
+func IsQuotaError(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, ErrQuotaExceeded) {
+		return true
+	}
+	var openaiErr *openaisdk.Error
+	if errors.As(err, &openaiErr) && (openaiErr.Code == "insufficient_quota" || openaiErr.Type == "insufficient_quota") {
+		return true
+	}
+	return errorStatusCode(err) == http.StatusPaymentRequired || utils.IsQuotaErrorMessage(err.Error())
+}
+
+// openAIQuotaMiddleware stops the OpenAI SDK from retrying quota and billing errors
+func openAIQuotaMiddleware(req *http.Request, next option.MiddlewareNext) (*http.Response, error) {
+	res, err := next(req)
+	utils.MarkQuotaResponseNotRetryable(res)
+	return res, err
+}
+
+// anthropicQuotaMiddleware stops the Anthropic SDK from retrying quota and billing errors
+func anthropicQuotaMiddleware(req *http.Request, next anthropicoption.MiddlewareNext) (*http.Response, error) {
+	res, err := next(req)
+	utils.MarkQuotaResponseNotRetryable(res)
+	return res, err
+}
@@ -3,9 +3,12 @@ package llmproviders
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
 	"os"
 	"path/filepath"
+	"strconv"
 	"strings"
 	"time"
 
@@ -15,6 +18,8 @@ import (
 	bedrockadapter "github.com/manishiitg/multi-llm-provider-go/pkg/adapters/bedrock"
 	openaiadapter "github.com/manishiitg/multi-llm-provider-go/pkg/adapters/openai"
 	vertexadapter "github.com/manishiitg/multi-llm-provider-go/pkg/adapters/vertex"
+	"github.com/manishiitg/multi-llm-provider-go/pkg/history"
+	"github.com/manishiitg/multi-llm-provider-go/pkg/utils"
 
 	"github.com/anthropics/anthropic-sdk-go"
 	anthropicoption "github.com/anthropics/anthropic-sdk-go/option"
@@ -49,13 +54,49 @@ type Config struct {
 	TraceID      interfaces.TraceID
 	// Fallback configuration for rate limiting
 	FallbackModels []string
-	MaxRetries     int
+	// MaxRetries retries failed calls up to this many times in the wrapper instead of the
+	// provider SDK's own retries (0 leaves retries to the SDK); see WithMaxRetries
+	MaxRetries int
 	// Logger for structured logging
 	Logger interfaces.Logger
 	// Context for LLM initialization (optional, uses background with timeout if not provided)
 	Context context.Context
 	// API keys for providers (optional, falls back to environment variables if not provided)
 	APIKeys *ProviderAPIKeys
+	// DefaultMaxTokens is used when a call doesn't pass WithMaxTokens (optional).
+	// If 0, the model's default from the ModelInfo registry is used. Either way it is
+	// capped at the model's maximum output tokens.
+	DefaultMaxTokens int
+	// HTTPClient is used for all provider requests (optional), e.g. to share one connection
+	// pool across models or add a proxy. It takes precedence over Transport.
+	HTTPClient *http.Client
+	// Transport tunes the connection pool of provider requests (optional), see TransportConfig
+	Transport *TransportConfig
+	// AppAttribution identifies the calling application to providers (optional), e.g. the
+	// OpenRouter HTTP-Referer and X-Title or the OpenAI organization and project
+	AppAttribution *AppAttribution
+	// Region pins requests to a regional endpoint for data residency (optional): the OpenAI
+	// data residency endpoint ("us", "eu"), the Vertex location or the Bedrock region. It must
+	// be one of SupportedRegions(Provider) and the model must be available there. The
+	// Anthropic API has no regional endpoint and rejects a region; use Bedrock or Vertex.
+	Region string
+	// ModelAliases maps logical model names ("fast", "smart") to concrete models (optional).
+	// ModelID, FallbackModels and WithModel / WithFallbackModels may name an alias; without
+	// Provider, ModelID must be one.
+	ModelAliases map[string]ModelAlias
+	// PropagateTraceID sends TraceID with every provider request (optional), so provider-side
+	// logs correlate with ours; see WithMetadataTraceID
+	PropagateTraceID bool
+	// DefaultSystemPrompt is sent as the system prompt of calls that have none (optional), e.g.
+	// safety instructions or a persona shared by every call of the client. It is also put
+	// in caches created with CreateCachedContent.
+	DefaultSystemPrompt string
+	// MergeSystemPrompt puts DefaultSystemPrompt before the system prompt of calls that have
+	// one instead of leaving it out
+	MergeSystemPrompt bool
+	// MessageTransforms rewrite the messages of every call just before they are sent
+	// (optional), before the call's own WithMessageTransform transforms
+	MessageTransforms []MessageTransform
 }
 
 // ProviderAPIKeys holds API keys for different providers
@@ -74,8 +115,15 @@ type BedrockConfig struct {
 
 // InitializeLLM creates and initializes an LLM based on the provider configuration
 func InitializeLLM(config Config) (llmtypes.Model, error) {
+	config, err := resolveModelAliases(config)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateRegion(config); err != nil {
+		return nil, err
+	}
+
 	var llm llmtypes.Model
-	var err error
 
 	switch config.Provider {
 	case ProviderBedrock:
@@ -97,14 +145,30 @@ func InitializeLLM(config Config) (llmtypes.Model, error) {
 	}
 
 	// Wrap the LLM with provider information and tracing
-	return NewProviderAwareLLM(llm, config.Provider, config.ModelID, config.EventEmitter, config.TraceID, config.Logger), nil
+	wrapped := NewProviderAwareLLM(llm, config.Provider, config.ModelID, config.EventEmitter, config.TraceID, config.Logger)
+	wrapped.defaultMaxTokens = config.DefaultMaxTokens
+	wrapped.region = config.Region
+	wrapped.maxRetries = config.MaxRetries
+	wrapped.modelAliases = config.ModelAliases
+	wrapped.propagateTraceID = config.PropagateTraceID
+	wrapped.defaultSystemPrompt = config.DefaultSystemPrompt
+	wrapped.mergeSystemPrompt = config.MergeSystemPrompt
+	wrapped.messageTransforms = config.MessageTransforms
+	return wrapped, nil
 }
 
 // InitializeEmbeddingModel creates and initializes an embedding model based on the provider configuration
 // Supported providers: OpenAI, OpenRouter, Vertex AI, Bedrock
 func InitializeEmbeddingModel(config Config) (llmtypes.EmbeddingModel, error) {
+	config, err := resolveModelAliases(config)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateRegion(config); err != nil {
+		return nil, err
+	}
+
 	var embeddingModel llmtypes.EmbeddingModel
-	var err error
 
 	switch config.Provider {
 	case ProviderOpenAI:
@@ -141,9 +205,15 @@ func initializeOpenAIEmbedding(config Config) (llmtypes.EmbeddingModel, error) {
 	}
 
 	// Create OpenAI client using official SDK
-	client := openaisdk.NewClient(
-		option.WithAPIKey(os.Getenv("OPENAI_API_KEY")),
-	)
+	clientOptions := []option.RequestOption{option.WithAPIKey(os.Getenv("OPENAI_API_KEY"))}
+	clientOptions = append(clientOptions, config.openAIAttributionOptions()...)
+	if config.Region != "" {
+		clientOptions = append(clientOptions, option.WithBaseURL(openAIBaseURL(config.Region)))
+	}
+	if httpClient := config.httpClient(); httpClient != nil {
+		clientOptions = append(clientOptions, option.WithHTTPClient(httpClient))
+	}
+	client := openaisdk.NewClient(clientOptions...)
 
 	// Create OpenAI adapter (it implements both Model and EmbeddingModel interfaces)
 	logger := config.Logger
@@ -178,7 +248,7 @@ func initializeVertexEmbedding(config Config) (llmtypes.EmbeddingModel, error) {
 		// Try alternative environment variable names
 		apiKey = os.Getenv("GOOGLE_API_KEY")
 	}
-	if apiKey == "" {
+	if apiKey == "" && config.Region == "" {
 		return nil, fmt.Errorf("VERTEX_API_KEY or GOOGLE_API_KEY environment variable is required for Vertex AI embedding models")
 	}
 
@@ -188,12 +258,12 @@ func initializeVertexEmbedding(config Config) (llmtypes.EmbeddingModel, error) {
 		ctx = context.Background()
 	}
 
-	// Create Google GenAI client with API key authentication
-	// Using BackendGeminiAPI for Gemini Developer API
-	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey:  apiKey,
-		Backend: genai.BackendGeminiAPI,
-	})
+	// Create Google GenAI client: Gemini Developer API with the API key, or Vertex AI in Config.Region
+	clientConfig, err := genaiClientConfig(config, apiKey)
+	if err != nil {
+		return nil, err
+	}
+	client, err := genai.NewClient(ctx, clientConfig)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create GenAI client: %w", err)
 	}
@@ -220,14 +290,18 @@ func initializeBedrockEmbedding(config Config) (llmtypes.EmbeddingModel, error)
 
 	logger.Infof("Initializing Bedrock Embedding Model - model_id: %s", modelID)
 
-	// Create AWS config
-	cfg, err := awsconfig.LoadDefaultConfig(config.Context)
+	// Create AWS config, in Config.Region if set
+	var awsOptions []func(*awsconfig.LoadOptions) error
+	if config.Region != "" {
+		awsOptions = append(awsOptions, awsconfig.WithRegion(config.Region))
+	}
+	cfg, err := awsconfig.LoadDefaultConfig(config.Context, awsOptions...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load AWS config: %w", err)
 	}
 
 	// Create Bedrock runtime client
-	client := bedrockruntime.NewFromConfig(cfg)
+	client := bedrockruntime.NewFromConfig(cfg, withBedrockHTTPClient(config))
 
 	// Create Bedrock adapter (it implements both Model and EmbeddingModel interfaces)
 	embeddingModel := bedrockadapter.NewBedrockAdapter(client, modelID, logger)
@@ -377,7 +451,7 @@ func initializeBedrock(config Config) (llmtypes.Model, error) {
 	// LLM Initialization event data - use typed structure directly
 	llmMetadata := LLMMetadata{
 		ModelVersion: config.ModelID,
-		MaxTokens:    40000, // Will be set at call time
+		MaxTokens:    resolveDefaultMaxTokens(config.DefaultMaxTokens, config.ModelID),
 		TopP:         config.Temperature,
 		User:         "bedrock_user",
 		CustomFields: map[string]string{
@@ -399,7 +473,10 @@ func initializeBedrock(config Config) (llmtypes.Model, error) {
 
 	// Get region from config first, then environment (default to us-east-1)
 	region := ""
-	if config.APIKeys != nil && config.APIKeys.Bedrock != nil && config.APIKeys.Bedrock.Region != "" {
+	if config.Region != "" {
+		region = config.Region
+		logger.Infof("Using region from config: %s", region)
+	} else if config.APIKeys != nil && config.APIKeys.Bedrock != nil && config.APIKeys.Bedrock.Region != "" {
 		region = config.APIKeys.Bedrock.Region
 		logger.Infof("Using region from config: %s", region)
 	} else {
@@ -438,7 +515,7 @@ func initializeBedrock(config Config) (llmtypes.Model, error) {
 	}
 
 	// Create Bedrock runtime client
-	client := bedrockruntime.NewFromConfig(cfg)
+	client := bedrockruntime.NewFromConfig(cfg, withBedrockHTTPClient(config))
 
 	// Set default model if not specified
 	modelID := config.ModelID
@@ -456,10 +533,10 @@ func initializeBedrock(config Config) (llmtypes.Model, error) {
 		CustomFields: map[string]string{
 			"provider":     "bedrock",
 			"status":       StatusLLMInitialized,
-			"capabilities": CapabilityTextGeneration + "," + CapabilityToolCalling,
+			"capabilities": LookupCapabilities(modelID).String(),
 		},
 	}
-	emitLLMInitializationSuccess(config.EventEmitter, string(config.Provider), config.ModelID, CapabilityTextGeneration+","+CapabilityToolCalling, config.TraceID, successMetadata)
+	emitLLMInitializationSuccess(config.EventEmitter, string(config.Provider), config.ModelID, LookupCapabilities(modelID).String(), config.TraceID, successMetadata)
 
 	logger.Infof("Initialized Bedrock LLM - model_id: %s", config.ModelID)
 	return llm, nil
@@ -467,54 +544,33 @@ func initializeBedrock(config Config) (llmtypes.Model, error) {
 
 // IsO3O4Model detects o3/o4 models (OpenAI) for conditional logic in agent
 func IsO3O4Model(modelID string) bool {
-	// Covers gpt-4o, gpt-4.0, gpt-4.1, gpt-4, gpt-3.5, etc
-	return strings.HasPrefix(modelID, "o3") ||
-		strings.HasPrefix(modelID, "o4")
+	return utils.IsO3O4Model(modelID)
 }
 
-// extractTokenUsageFromGenerationInfo extracts token usage from GenerationInfo
-func extractTokenUsageFromGenerationInfo(generationInfo *llmtypes.GenerationInfo) TokenUsage {
-	usage := TokenUsage{Unit: "TOKENS"}
-
-	if generationInfo == nil {
-		return usage
-	}
-
-	// Extract input tokens (check multiple naming conventions in priority order)
-	if generationInfo.InputTokens != nil {
-		usage.InputTokens = *generationInfo.InputTokens
-	} else if generationInfo.InputTokensCap != nil {
-		usage.InputTokens = *generationInfo.InputTokensCap
-	} else if generationInfo.PromptTokens != nil {
-		usage.InputTokens = *generationInfo.PromptTokens
-	} else if generationInfo.PromptTokensCap != nil {
-		usage.InputTokens = *generationInfo.PromptTokensCap
-	}
-
-	// Extract output tokens (check multiple naming conventions in priority order)
-	if generationInfo.OutputTokens != nil {
-		usage.OutputTokens = *generationInfo.OutputTokens
-	} else if generationInfo.OutputTokensCap != nil {
-		usage.OutputTokens = *generationInfo.OutputTokensCap
-	} else if generationInfo.CompletionTokens != nil {
-		usage.OutputTokens = *generationInfo.CompletionTokens
-	} else if generationInfo.CompletionTokensCap != nil {
-		usage.OutputTokens = *generationInfo.CompletionTokensCap
+// responseUsage returns the usage reported on the first choice with GenerationInfo, for
+// models that do not set ContentResponse.Usage. Providers report the usage of the whole
+// response, repeated on every choice.
+func responseUsage(resp *llmtypes.ContentResponse) *llmtypes.Usage {
+	for _, choice := range resp.Choices {
+		if choice != nil && choice.GenerationInfo != nil {
+			return llmtypes.ExtractUsageFromGenerationInfo(choice.GenerationInfo)
+		}
 	}
+	return nil
+}
 
-	// Extract total tokens (check multiple naming conventions in priority order)
-	if generationInfo.TotalTokens != nil {
-		usage.TotalTokens = *generationInfo.TotalTokens
-	} else if generationInfo.TotalTokensCap != nil {
-		usage.TotalTokens = *generationInfo.TotalTokensCap
+// eventTokenUsage converts usage to the TokenUsage reported in events
+func eventTokenUsage(usage *llmtypes.Usage) TokenUsage {
+	tokenUsage := TokenUsage{
+		InputTokens:  usage.InputTokens,
+		OutputTokens: usage.OutputTokens,
+		TotalTokens:  usage.TotalTokens,
+		Unit:         "TOKENS",
 	}
-
-	// Calculate total tokens if not provided by the provider
-	if usage.TotalTokens == 0 && usage.InputTokens > 0 && usage.OutputTokens > 0 {
-		usage.TotalTokens = usage.InputTokens + usage.OutputTokens
+	if usage.Cost != nil {
+		tokenUsage.Cost = strconv.FormatFloat(*usage.Cost, 'f', -1, 64)
 	}
-
-	return usage
+	return tokenUsage
 }
 
 // Helper functions for event emission
@@ -548,6 +604,18 @@ func emitLLMGenerationError(emitter interfaces.EventEmitter, provider string, mo
 	}
 }
 
+func emitInputTrimmed(emitter interfaces.EventEmitter, provider string, modelID string, droppedMessages int, reclaimedTokens int, inputTokens int, traceID interfaces.TraceID, metadata LLMMetadata) {
+	if trimEmitter, ok := emitter.(interfaces.InputTrimEmitter); ok {
+		trimEmitter.EmitInputTrimmed(provider, modelID, droppedMessages, reclaimedTokens, inputTokens, traceID, metadata)
+	}
+}
+
+func emitToolCallsTruncated(emitter interfaces.EventEmitter, provider string, modelID string, receivedToolCalls int, keptToolCalls int, traceID interfaces.TraceID, metadata LLMMetadata) {
+	if truncateEmitter, ok := emitter.(interfaces.ToolCallTruncateEmitter); ok {
+		truncateEmitter.EmitToolCallsTruncated(provider, modelID, receivedToolCalls, keptToolCalls, traceID, metadata)
+	}
+}
+
 func emitToolCallDetected(emitter interfaces.EventEmitter, provider string, modelID string, toolCallID string, toolName string, arguments string, traceID interfaces.TraceID, metadata LLMMetadata) {
 	if emitter != nil {
 		emitter.EmitToolCallDetected(provider, modelID, toolCallID, toolName, arguments, traceID, metadata)
@@ -571,7 +639,7 @@ func initializeOpenAI(config Config) (llmtypes.Model, error) {
 	// LLM Initialization event data - use typed structure directly
 	llmMetadata := LLMMetadata{
 		ModelVersion: config.ModelID,
-		MaxTokens:    0, // Will be set at call time
+		MaxTokens:    resolveDefaultMaxTokens(config.DefaultMaxTokens, config.ModelID),
 		TopP:         config.Temperature,
 		User:         "openai_user",
 		CustomFields: map[string]string{
@@ -590,9 +658,15 @@ func initializeOpenAI(config Config) (llmtypes.Model, error) {
 	}
 
 	// Create OpenAI client using official SDK
-	client := openaisdk.NewClient(
-		option.WithAPIKey(apiKey),
-	)
+	clientOptions := []option.RequestOption{option.WithAPIKey(apiKey), option.WithMiddleware(openAIQuotaMiddleware)}
+	clientOptions = append(clientOptions, config.openAIAttributionOptions()...)
+	if config.Region != "" {
+		clientOptions = append(clientOptions, option.WithBaseURL(openAIBaseURL(config.Region)))
+	}
+	if httpClient := config.httpClient(); httpClient != nil {
+		clientOptions = append(clientOptions, option.WithHTTPClient(httpClient))
+	}
+	client := openaisdk.NewClient(clientOptions...)
 
 	// Create OpenAI adapter
 	logger := config.Logger
@@ -608,10 +682,10 @@ func initializeOpenAI(config Config) (llmtypes.Model, error) {
 		CustomFields: map[string]string{
 			"provider":     "openai",
 			"status":       StatusLLMInitialized,
-			"capabilities": CapabilityTextGeneration + "," + CapabilityToolCalling,
+			"capabilities": LookupCapabilities(modelID).String(),
 		},
 	}
-	emitLLMInitializationSuccess(config.EventEmitter, string(config.Provider), modelID, CapabilityTextGeneration+","+CapabilityToolCalling, config.TraceID, successMetadata)
+	emitLLMInitializationSuccess(config.EventEmitter, string(config.Provider), modelID, LookupCapabilities(modelID).String(), config.TraceID, successMetadata)
 
 	logger.Infof("Initialized OpenAI LLM - model_id: %s", modelID)
 	return llm, nil
@@ -622,7 +696,7 @@ func initializeAnthropic(config Config) (llmtypes.Model, error) {
 	// LLM Initialization event data - use typed structure directly
 	llmMetadata := LLMMetadata{
 		ModelVersion: config.ModelID,
-		MaxTokens:    0, // Will be set at call time
+		MaxTokens:    resolveDefaultMaxTokens(config.DefaultMaxTokens, config.ModelID),
 		TopP:         config.Temperature,
 		User:         "anthropic_user",
 		CustomFields: map[string]string{
@@ -661,9 +735,11 @@ func initializeAnthropic(config Config) (llmtypes.Model, error) {
 	// Create Anthropic SDK client
 	// NewClient reads from environment by default, but we can explicitly set API key
 	// Note: Beta header for prompt caching must be added per-request, not at client level
-	client := anthropic.NewClient(
-		anthropicoption.WithAPIKey(apiKey),
-	)
+	clientOptions := []anthropicoption.RequestOption{anthropicoption.WithAPIKey(apiKey), anthropicoption.WithMiddleware(anthropicQuotaMiddleware)}
+	if httpClient := config.httpClient(); httpClient != nil {
+		clientOptions = append(clientOptions, anthropicoption.WithHTTPClient(httpClient))
+	}
+	client := anthropic.NewClient(clientOptions...)
 
 	// Create Anthropic adapter
 	llm := anthropicadapter.NewAnthropicAdapter(client, modelID, logger)
@@ -675,10 +751,10 @@ func initializeAnthropic(config Config) (llmtypes.Model, error) {
 		CustomFields: map[string]string{
 			"provider":     "anthropic",
 			"status":       StatusLLMInitialized,
-			"capabilities": CapabilityTextGeneration + "," + CapabilityToolCalling,
+			"capabilities": LookupCapabilities(modelID).String(),
 		},
 	}
-	emitLLMInitializationSuccess(config.EventEmitter, string(config.Provider), modelID, CapabilityTextGeneration+","+CapabilityToolCalling, config.TraceID, successMetadata)
+	emitLLMInitializationSuccess(config.EventEmitter, string(config.Provider), modelID, LookupCapabilities(modelID).String(), config.TraceID, successMetadata)
 
 	logger.Infof("Initialized Anthropic LLM - model_id: %s", modelID)
 	return llm, nil
@@ -689,7 +765,7 @@ func initializeOpenRouter(config Config) (llmtypes.Model, error) {
 	// LLM Initialization event data - use typed structure directly
 	llmMetadata := LLMMetadata{
 		ModelVersion: config.ModelID,
-		MaxTokens:    0, // Will be set at call time
+		MaxTokens:    resolveDefaultMaxTokens(config.DefaultMaxTokens, config.ModelID),
 		TopP:         config.Temperature,
 		User:         "openrouter_user",
 		CustomFields: map[string]string{
@@ -738,16 +814,17 @@ func initializeOpenRouter(config Config) (llmtypes.Model, error) {
 	clientOptions := []option.RequestOption{
 		option.WithAPIKey(apiKey),
 		option.WithBaseURL("https://openrouter.ai/api/v1"),
+		option.WithMiddleware(openAIQuotaMiddleware),
 	}
 
-	// Add optional OpenRouter headers if provided
-	if httpReferer := os.Getenv("OPENROUTER_HTTP_REFERER"); httpReferer != "" {
-		clientOptions = append(clientOptions, option.WithHeader("HTTP-Referer", httpReferer))
-		logger.Infof("🔧 [DEBUG] Added HTTP-Referer header: %s", httpReferer)
+	// Add the app attribution headers (HTTP-Referer, X-Title) from config or environment
+	if attributionOptions := config.openRouterAttributionOptions(); len(attributionOptions) > 0 {
+		clientOptions = append(clientOptions, attributionOptions...)
+		logger.Infof("🔧 [DEBUG] Added %d app attribution headers", len(attributionOptions))
 	}
-	if xTitle := os.Getenv("OPENROUTER_X_TITLE"); xTitle != "" {
-		clientOptions = append(clientOptions, option.WithHeader("X-Title", xTitle))
-		logger.Infof("🔧 [DEBUG] Added X-Title header: %s", xTitle)
+
+	if httpClient := config.httpClient(); httpClient != nil {
+		clientOptions = append(clientOptions, option.WithHTTPClient(httpClient))
 	}
 
 	client := openaisdk.NewClient(clientOptions...)
@@ -765,10 +842,10 @@ func initializeOpenRouter(config Config) (llmtypes.Model, error) {
 		CustomFields: map[string]string{
 			"provider":     "openrouter",
 			"status":       StatusLLMInitialized,
-			"capabilities": CapabilityTextGeneration + "," + CapabilityToolCalling,
+			"capabilities": LookupCapabilities(modelID).String(),
 		},
 	}
-	emitLLMInitializationSuccess(config.EventEmitter, string(config.Provider), modelID, CapabilityTextGeneration+","+CapabilityToolCalling, config.TraceID, successMetadata)
+	emitLLMInitializationSuccess(config.EventEmitter, string(config.Provider), modelID, LookupCapabilities(modelID).String(), config.TraceID, successMetadata)
 
 	logger.Infof("✅ Successfully initialized OpenRouter LLM - model_id: %s", modelID)
 	return llm, nil
@@ -780,7 +857,7 @@ func initializeVertex(config Config) (llmtypes.Model, error) {
 	// LLM Initialization event data - use typed structure directly
 	llmMetadata := LLMMetadata{
 		ModelVersion: config.ModelID,
-		MaxTokens:    0, // Will be set at call time
+		MaxTokens:    resolveDefaultMaxTokens(config.DefaultMaxTokens, config.ModelID),
 		TopP:         config.Temperature,
 		User:         "vertex_user",
 		CustomFields: map[string]string{
@@ -825,7 +902,10 @@ func initializeVertexAnthropic(config Config, modelID string, logger interfaces.
 		return nil, fmt.Errorf("VERTEX_PROJECT_ID environment variable is required for Anthropic models")
 	}
 
-	locationID := os.Getenv("VERTEX_LOCATION_ID")
+	locationID := config.Region
+	if locationID == "" {
+		locationID = os.Getenv("VERTEX_LOCATION_ID")
+	}
 	if locationID == "" {
 		locationID = "global" // Default location
 		logger.Infof("VERTEX_LOCATION_ID not set, using default: %s", locationID)
@@ -833,6 +913,9 @@ func initializeVertexAnthropic(config Config, modelID string, logger interfaces.
 
 	// Create Vertex Anthropic adapter
 	llm := vertexadapter.NewVertexAnthropicAdapter(projectID, locationID, modelID, logger)
+	if httpClient := config.httpClient(); httpClient != nil {
+		llm.SetHTTPClient(httpClient)
+	}
 
 	// Emit LLM initialization success event
 	successMetadata := LLMMetadata{
@@ -842,10 +925,10 @@ func initializeVertexAnthropic(config Config, modelID string, logger interfaces.
 			"provider":     "vertex",
 			"model_type":   "anthropic",
 			"status":       StatusLLMInitialized,
-			"capabilities": CapabilityTextGeneration + "," + CapabilityToolCalling,
+			"capabilities": LookupCapabilities(modelID).String(),
 		},
 	}
-	emitLLMInitializationSuccess(config.EventEmitter, string(config.Provider), modelID, CapabilityTextGeneration+","+CapabilityToolCalling, config.TraceID, successMetadata)
+	emitLLMInitializationSuccess(config.EventEmitter, string(config.Provider), modelID, LookupCapabilities(modelID).String(), config.TraceID, successMetadata)
 
 	logger.Infof("Initialized Vertex AI Anthropic LLM - model_id: %s, project: %s, location: %s", modelID, projectID, locationID)
 	return llm, nil
@@ -868,9 +951,6 @@ func initializeVertexGemini(config Config, modelID string, logger interfaces.Log
 			apiKey = os.Getenv("GOOGLE_API_KEY")
 		}
 	}
-	if apiKey == "" {
-		return nil, fmt.Errorf("VERTEX_API_KEY or GOOGLE_API_KEY is required for Gemini models (not found in config or environment)")
-	}
 
 	// Use provided context or use background context
 	ctx := config.Context
@@ -878,12 +958,12 @@ func initializeVertexGemini(config Config, modelID string, logger interfaces.Log
 		ctx = context.Background()
 	}
 
-	// Create Google GenAI client with API key authentication
-	// Using BackendGeminiAPI for Gemini Developer API
-	client, err := genai.NewClient(ctx, &genai.ClientConfig{
-		APIKey:  apiKey,
-		Backend: genai.BackendGeminiAPI,
-	})
+	// Create Google GenAI client: Gemini Developer API with the API key, or Vertex AI in Config.Region
+	clientConfig, err := genaiClientConfig(config, apiKey)
+	if err != nil {
+		return nil, err
+	}
+	client, err := genai.NewClient(ctx, clientConfig)
 	if err != nil {
 		logger.Errorf("Failed to create GenAI client: %w", err)
 
@@ -915,10 +995,10 @@ func initializeVertexGemini(config Config, modelID string, logger interfaces.Log
 			"provider":     "vertex",
 			"model_type":   "gemini",
 			"status":       StatusLLMInitialized,
-			"capabilities": CapabilityTextGeneration + "," + CapabilityToolCalling,
+			"capabilities": LookupCapabilities(modelID).String(),
 		},
 	}
-	emitLLMInitializationSuccess(config.EventEmitter, string(config.Provider), modelID, CapabilityTextGeneration+","+CapabilityToolCalling, config.TraceID, successMetadata)
+	emitLLMInitializationSuccess(config.EventEmitter, string(config.Provider), modelID, LookupCapabilities(modelID).String(), config.TraceID, successMetadata)
 
 	logger.Infof("Initialized Vertex AI Gemini LLM - model_id: %s", modelID)
 	return llm, nil
@@ -1091,6 +1171,26 @@ type ProviderAwareLLM struct {
 	eventEmitter interfaces.EventEmitter
 	traceID      interfaces.TraceID
 	logger       interfaces.Logger
+	// defaultMaxTokens is Config.DefaultMaxTokens (0 uses the registry default)
+	defaultMaxTokens int
+	// region is Config.Region, empty for the provider's default endpoint
+	region string
+	// maxRetries is Config.MaxRetries, the default of WithMaxRetries (0 leaves retries to the SDK)
+	maxRetries int
+	// modelAliases is Config.ModelAliases, resolved in WithModel and WithFallbackModels
+	modelAliases map[string]ModelAlias
+	// propagateTraceID is Config.PropagateTraceID: traceID is sent with every call
+	propagateTraceID bool
+	// defaultSystemPrompt and mergeSystemPrompt are Config.DefaultSystemPrompt and
+	// Config.MergeSystemPrompt
+	defaultSystemPrompt string
+	mergeSystemPrompt   bool
+	// messageTransforms is Config.MessageTransforms, run before the call's transforms
+	messageTransforms []llmtypes.MessageTransform
+	// flights coalesces concurrent identical calls made with WithSingleFlight
+	flights singleFlightGroup
+	// toolSummaries caches the summaries of WithToolResultMaxTokens
+	toolSummaries toolSummaryCache
 }
 
 // NewProviderAwareLLM creates a new provider-aware LLM wrapper
@@ -1119,6 +1219,17 @@ func (p *ProviderAwareLLM) GetModelID() string {
 	return p.modelID
 }
 
+// Region returns the region requests are pinned to (Config.Region), empty for the
+// provider's default endpoint
+func (p *ProviderAwareLLM) Region() string {
+	return p.region
+}
+
+// Capabilities returns what the model supports, from the ModelInfo registry
+func (p *ProviderAwareLLM) Capabilities() ModelCapabilities {
+	return LookupCapabilities(p.modelID)
+}
+
 // GenerateContent wraps the underlying LLM's GenerateContent method to automatically capture token usage
 // extractTextFromParts extracts text content from message parts
 func extractTextFromParts(parts []llmtypes.ContentPart) string {
@@ -1131,7 +1242,92 @@ func extractTextFromParts(parts []llmtypes.ContentPart) string {
 	return strings.Join(textParts, " ")
 }
 
+// trimToMaxInputTokens drops the oldest messages (history.Truncate) when their estimated
+// token count exceeds maxTokens and emits an input trimmed event. If the kept messages still
+// exceed the budget they are sent anyway and the provider enforces its own limit.
+func (p *ProviderAwareLLM) trimToMaxInputTokens(messages []llmtypes.MessageContent, maxTokens int) []llmtypes.MessageContent {
+	inputTokens := history.CountTokens(messages)
+	if inputTokens <= maxTokens {
+		return messages
+	}
+	result, err := history.Truncate(messages, maxTokens, true)
+	if err != nil {
+		p.logger.Infof("⚠️ Input still over budget after trimming - provider: %s, model: %s, error: %v", string(p.provider), p.modelID, err)
+	}
+	if result.DroppedMessages == 0 {
+		return messages
+	}
+	p.logger.Infof("✂️ Trimmed input to %d tokens - dropped %d messages (~%d tokens of %d)", maxTokens, result.DroppedMessages, result.ReclaimedTokens, inputTokens)
+	metadata := LLMMetadata{
+		User: "llm_generation_user",
+		CustomFields: map[string]string{
+			"provider":         string(p.provider),
+			"model_id":         p.modelID,
+			"max_input_tokens": fmt.Sprintf("%d", maxTokens),
+		},
+	}
+	emitInputTrimmed(p.eventEmitter, string(p.provider), p.modelID, result.DroppedMessages, result.ReclaimedTokens, inputTokens, p.traceID, metadata)
+	return result.Messages
+}
+
+// GenerateContent calls the model with the wrapper's options applied and reports usage.
+// When ctx is cancelled during a streaming call (WithStreamingChan), the response is not nil:
+// it holds the content and tool calls streamed before the cancellation, with no stop reason,
+// and the error matches (errors.Is) context.Canceled or context.DeadlineExceeded.
 func (p *ProviderAwareLLM) GenerateContent(ctx context.Context, messages []llmtypes.MessageContent, options ...llmtypes.CallOption) (*llmtypes.ContentResponse, error) {
+	// Coalesce concurrent identical non-streaming calls into one upstream call
+	opts := &llmtypes.CallOptions{}
+	for _, opt := range options {
+		opt(opts)
+	}
+	if opts.SingleFlight && opts.StreamChan == nil && opts.N <= 1 && !opts.DryRun {
+		if key, ok := singleFlightKey(p.provider, p.modelID, messages, opts); ok {
+			resp, err, shared := p.flights.do(ctx, key, func(ctx context.Context) (*llmtypes.ContentResponse, error) {
+				return p.generateValidated(ctx, messages, opts, options)
+			})
+			if shared {
+				p.logger.Infof("🔗 Single-flight: shared the result of an identical in-flight call - provider: %s, model: %s", string(p.provider), p.modelID)
+			}
+			return resp, err
+		}
+	}
+	return p.generateValidated(ctx, messages, opts, options)
+}
+
+// truncateToolCalls drops the tool calls of each choice beyond the first maxToolCalls and
+// emits a tool calls truncated event for each choice that had more
+func (p *ProviderAwareLLM) truncateToolCalls(resp *llmtypes.ContentResponse, maxToolCalls int) {
+	for i, choice := range resp.Choices {
+		if choice == nil || len(choice.ToolCalls) <= maxToolCalls {
+			continue
+		}
+		received := len(choice.ToolCalls)
+		choice.ToolCalls = choice.ToolCalls[:maxToolCalls:maxToolCalls]
+		p.logger.Infof("✂️ Dropped %d of %d tool calls in choice %d (max %d per response)", received-maxToolCalls, received, i, maxToolCalls)
+		metadata := LLMMetadata{
+			User: "llm_generation_user",
+			CustomFields: map[string]string{
+				"provider":     string(p.provider),
+				"model_id":     p.modelID,
+				"choice_index": fmt.Sprintf("%d", i),
+			},
+		}
+		emitToolCallsTruncated(p.eventEmitter, string(p.provider), p.modelID, received, maxToolCalls, p.traceID, metadata)
+	}
+}
+
+// reparse returns the CallOptions that options set, after a step of generateContent has
+// appended to or rewritten them
+func reparse(options []llmtypes.CallOption) *llmtypes.CallOptions {
+	opts := &llmtypes.CallOptions{}
+	for _, opt := range options {
+		opt(opts)
+	}
+	return opts
+}
+
+// generateContent is GenerateContent without call coalescing
+func (p *ProviderAwareLLM) generateContent(ctx context.Context, messages []llmtypes.MessageContent, options ...llmtypes.CallOption) (*llmtypes.ContentResponse, error) {
 	// Note: LLM generation start event is now emitted at the agent level to avoid duplication
 
 	// Automatically add usage parameter for OpenRouter requests to get cache token information
@@ -1141,67 +1337,257 @@ func (p *ProviderAwareLLM) GenerateContent(ctx context.Context, messages []llmty
 
 	// 🆕 USEFUL LOGGING - System prompts, messages, and tools
 	// Parse call options to extract tools
-	opts := &llmtypes.CallOptions{}
-	for _, opt := range options {
-		opt(opts)
+	opts := reparse(options)
+
+	// Apply the client's default system prompt; a context cache already carries it
+	if opts.CachedContent == "" {
+		messages = p.withDefaultSystemPrompt(messages)
 	}
 
-	// Extract and log system prompts
-	var systemPrompts []string
-	for _, msg := range messages {
-		if msg.Role == llmtypes.ChatMessageTypeSystem {
-			text := extractTextFromParts(msg.Parts)
-			if text != "" {
-				systemPrompts = append(systemPrompts, text)
-			}
+	// Resolve model aliases passed to WithModel and WithFallbackModels
+	if len(p.modelAliases) > 0 {
+		resolved, err := p.resolveCallAliases(options, opts)
+		if err != nil {
+			return nil, err
 		}
+		options = resolved
+		opts = reparse(options)
 	}
-	if len(systemPrompts) > 0 {
-		p.logger.Infof("📋 SYSTEM PROMPTS (%d):", len(systemPrompts))
-		for i, prompt := range systemPrompts {
-			p.logger.Infof("   [%d] %s", i+1, prompt)
+
+	// Send the wrapper's trace ID to the provider unless the call has its own
+	if p.propagateTraceID && p.traceID != "" && opts.TraceID == "" {
+		options = append(append([]llmtypes.CallOption{}, options...), llmtypes.WithMetadataTraceID(string(p.traceID)))
+		opts.TraceID = string(p.traceID)
+	}
+
+	// Refuse calls that require a region other than the one requests go to
+	if opts.Region != "" && opts.Region != p.region {
+		return nil, fmt.Errorf("call requires region %q but %s model %s is configured for %s", opts.Region, p.provider, p.modelID, regionName(p.region))
+	}
+
+	// Refuse invalid options and option combinations the call cannot honor before sending anything
+	options, opts = p.normalizeOptions(options, opts)
+	if err := p.validateRequest(messages, opts); err != nil {
+		p.logger.Infof("❌ Invalid call options - provider: %s, model: %s, error: %v", string(p.provider), p.modelID, err)
+		return nil, err
+	}
+
+	// Summarize tool results too long to send as they are
+	if opts.ToolResultMaxTokens > 0 {
+		summarized, err := p.summarizeToolResults(ctx, messages, opts)
+		if err != nil {
+			return nil, err
 		}
-	} else {
-		p.logger.Infof("📋 SYSTEM PROMPTS: None")
+		messages = summarized
 	}
 
-	// Log all messages
-	p.logger.Infof("💬 MESSAGES (%d):", len(messages))
-	for i, msg := range messages {
-		text := extractTextFromParts(msg.Parts)
-		// Truncate very long messages for readability
-		displayText := text
-		if len(displayText) > 500 {
-			displayText = displayText[:500] + "... [truncated]"
+	// Answer in text even if tools are set
+	if opts.DisableTools && (len(opts.Tools) > 0 || opts.ToolChoice != nil) {
+		messages, options = disableTools(p.provider, messages, options)
+		opts = reparse(options)
+	}
+
+	// Limit the tools the model may call this turn
+	allowedTools, planErr := planAllowedTools(opts)
+	if planErr != nil {
+		return nil, planErr
+	}
+	if allowedTools != nil {
+		modelID := p.modelID
+		if opts.Model != "" {
+			modelID = opts.Model
 		}
-		p.logger.Infof("   [%d] Role: %s, Content: %s", i+1, msg.Role, displayText)
+		messages, options = allowedTools.apply(p.provider, modelID, messages, options)
+		opts = reparse(options)
 	}
 
-	// Log tools if provided
-	if len(opts.Tools) > 0 {
-		p.logger.Infof("🔧 TOOLS (%d):", len(opts.Tools))
-		for i, tool := range opts.Tools {
-			if tool.Function != nil {
-				toolJSON, err := json.MarshalIndent(tool, "      ", "  ")
-				if err != nil {
-					p.logger.Infof("   [%d] %s (error marshaling: %v)", i+1, tool.Function.Name, err)
-				} else {
-					p.logger.Infof("   [%d] %s:\n%s", i+1, tool.Function.Name, string(toolJSON))
+	// Streaming wrappers relay in the order they are added: each wraps the stream of the one
+	// before, so the adapter streams into the last and the caller receives from the first.
+	// Each wrapper's finish runs when the call returns, the last added first.
+	var finishStreams []func()
+	defer func() {
+		for i := len(finishStreams) - 1; i >= 0; i-- {
+			finishStreams[i]()
+		}
+	}()
+	wrapStream := func(wrap func(out chan<- llmtypes.StreamChunk) (chan<- llmtypes.StreamChunk, func())) {
+		wrapped, finish := wrap(opts.StreamChan)
+		finishStreams = append(finishStreams, finish)
+		options = append(options, llmtypes.WithStreamingChan(wrapped))
+		opts.StreamChan = wrapped
+	}
+
+	// Record what reaches the caller's stream, returned if the call is cancelled mid-stream
+	var partial *partialStream
+	if opts.StreamChan != nil {
+		wrapStream(func(out chan<- llmtypes.StreamChunk) (chan<- llmtypes.StreamChunk, func()) {
+			var relayChan chan<- llmtypes.StreamChunk
+			partial, relayChan = newPartialStream(ctx, out)
+			return relayChan, partial.finish
+		})
+	}
+
+	// Deliver only text to the caller's stream; tool calls are still in the response
+	if opts.StreamTextOnly && opts.StreamChan != nil {
+		wrapStream(func(out chan<- llmtypes.StreamChunk) (chan<- llmtypes.StreamChunk, func()) {
+			return utils.FilterStream(ctx, out, func(chunk llmtypes.StreamChunk) bool {
+				return chunk.Type == llmtypes.StreamChunkTypeContent || chunk.Type == llmtypes.StreamChunkTypeHeartbeat
+			})
+		})
+	}
+
+	// Regroup streamed content into lines or sentences
+	if (opts.StreamBuffering == llmtypes.StreamBufferingLine || opts.StreamBuffering == llmtypes.StreamBufferingSentence) && opts.StreamChan != nil {
+		wrapStream(func(out chan<- llmtypes.StreamChunk) (chan<- llmtypes.StreamChunk, func()) {
+			return utils.BufferStream(ctx, out, opts.StreamBuffering)
+		})
+	}
+
+	// Remove reasoning spans from streamed content; the response is stripped below
+	if len(opts.StripReasoningTags) > 0 && opts.StreamChan != nil {
+		wrapStream(func(out chan<- llmtypes.StreamChunk) (chan<- llmtypes.StreamChunk, func()) {
+			return utils.StripReasoningStream(ctx, out, opts.StripReasoningTags, reasoningTagsLeadingOnly(opts), utils.ReasoningVisible(opts))
+		})
+	}
+
+	// Stop streaming tool calls beyond the cap; the response is truncated below
+	if opts.MaxToolCallsPerResponse > 0 && opts.StreamChan != nil {
+		maxToolCalls := opts.MaxToolCallsPerResponse
+		streamed := make(map[int]int)
+		wrapStream(func(out chan<- llmtypes.StreamChunk) (chan<- llmtypes.StreamChunk, func()) {
+			return utils.FilterStream(ctx, out, func(chunk llmtypes.StreamChunk) bool {
+				if chunk.Type != llmtypes.StreamChunkTypeToolCall {
+					return true
 				}
-			} else {
-				p.logger.Infof("   [%d] Tool with nil Function", i+1)
+				streamed[chunk.ChoiceIndex]++
+				return streamed[chunk.ChoiceIndex] <= maxToolCalls
+			})
+		})
+	}
+
+	// Rename tools that break provider naming rules; calls are mapped back below
+	toolNames := planToolNames(opts)
+	if toolNames != nil {
+		p.logger.Infof("🏷️  Sanitized %d tool names for %s", len(toolNames.toProvider), string(p.provider))
+		messages, options = toolNames.apply(messages, options, opts)
+		if opts.StreamChan != nil {
+			wrapStream(func(out chan<- llmtypes.StreamChunk) (chan<- llmtypes.StreamChunk, func()) {
+				return utils.MapStream(ctx, out, toolNames.restoreChunk)
+			})
+		}
+		opts = reparse(options)
+	}
+
+	// Cancel streams that stall and send heartbeats while they are quiet
+	var watch *streamWatch
+	if opts.StreamChan != nil && (opts.StreamStallTimeout > 0 || opts.StreamHeartbeat > 0) {
+		wrapStream(func(out chan<- llmtypes.StreamChunk) (chan<- llmtypes.StreamChunk, func()) {
+			var watchedChan chan<- llmtypes.StreamChunk
+			watch, ctx, watchedChan = newStreamWatch(ctx, out, opts)
+			return watchedChan, watch.done
+		})
+	}
+
+	// Pick a structured output strategy for the model
+	var structured *structuredOutputPlan
+	if opts.StructuredOutput != nil {
+		modelID := p.modelID
+		if opts.Model != "" {
+			modelID = opts.Model
+		}
+		structured = planStructuredOutput(opts.StructuredOutput, LookupCapabilities(modelID), opts.SchemaValidation)
+		p.logger.Infof("🧩 Structured output strategy: %s (schema: %s)", structured.strategy, structured.name)
+		messages, options = structured.apply(messages, options)
+		opts = reparse(options)
+	}
+
+	// Describe the tools in the prompt for models without native tool calling
+	var emulation *toolEmulationPlan
+	if opts.ToolEmulation {
+		modelID := p.modelID
+		if opts.Model != "" {
+			modelID = opts.Model
+		}
+		if emulation = planToolEmulation(opts, LookupCapabilities(modelID)); emulation != nil {
+			p.logger.Infof("🧰 Emulating tool calling for %d tools (no native support in %s)", len(opts.Tools), modelID)
+			messages, options = emulation.apply(messages, options)
+			if emulation.streamChan != nil {
+				defer close(emulation.streamChan)
 			}
+			opts = reparse(options)
 		}
-	} else {
-		p.logger.Infof("🔧 TOOLS: None")
 	}
 
+	// Call without streaming when the provider cannot stream the request (WithStreamFallback)
+	var streamFallback *streamFallbackPlan
+	if opts.StreamChan != nil {
+		modelID := p.modelID
+		if opts.Model != "" {
+			modelID = opts.Model
+		}
+		var err error
+		if streamFallback, err = planStreamFallback(opts, modelID, LookupCapabilities(modelID)); err != nil {
+			return nil, err
+		}
+		if streamFallback != nil {
+			if streamFallback.degraded {
+				p.logger.Infof("📴 %s does not stream, calling without streaming", modelID)
+			}
+			options = streamFallback.apply(ctx, options)
+			defer streamFallback.finish()
+			opts = reparse(options)
+		}
+	}
+
+	// Trim the oldest history to the input token budget
+	if opts.MaxInputTokens > 0 {
+		messages = p.trimToMaxInputTokens(messages, opts.MaxInputTokens)
+	}
+
+	// Apply the default max tokens when the caller didn't set any
+	if opts.MaxTokens == 0 {
+		modelID := p.modelID
+		if opts.Model != "" {
+			modelID = opts.Model
+		}
+		if maxTokens := resolveDefaultMaxTokens(p.defaultMaxTokens, modelID); maxTokens > 0 {
+			options = append(options, llmtypes.WithMaxTokens(maxTokens))
+			opts.MaxTokens = maxTokens
+		}
+	}
+
+	// Rewrite the messages with the client's and the call's message transforms
+	if len(p.messageTransforms) > 0 || len(opts.MessageTransforms) > 0 {
+		messages = transformMessages(messages, append(append([]llmtypes.MessageTransform{}, p.messageTransforms...), opts.MessageTransforms...))
+	}
+
+	// Log and report the messages as the provider receives them, after the request
+	// interceptors; until an attempt has run them there is nothing to report
+	sent := &sentMessages{}
+	if len(opts.RequestInterceptors) == 0 {
+		sent.record(messages)
+	}
+	ctx = withSentMessages(ctx, sent)
+
 	// Log request timing
 	requestStartTime := time.Now()
 	p.logger.Infof("⏱️  LLM REQUEST START - Time: %s", requestStartTime.Format(time.RFC3339))
 
-	// Call the underlying LLM
-	resp, err := p.Model.GenerateContent(ctx, messages, options...)
+	// Call the underlying LLM, falling back to the call's fallback models on retryable errors
+	var resp *llmtypes.ContentResponse
+	var err error
+	resp, err = p.callModel(ctx, messages, options, opts)
+	if err != nil && streamFallback != nil {
+		if retryOptions, ok := streamFallback.retry(err, options); ok {
+			p.logger.Infof("📴 Streaming rejected by %s, retrying without streaming - error: %v", string(p.provider), err)
+			options = retryOptions
+			opts.StreamChan = nil
+			resp, err = p.callModel(ctx, messages, options, opts)
+		}
+	}
+	if err == nil && streamFallback != nil {
+		streamFallback.stream(ctx, resp)
+	}
 
 	// Log response timing
 	requestEndTime := time.Now()
@@ -1210,6 +1596,19 @@ func (p *ProviderAwareLLM) GenerateContent(ctx context.Context, messages []llmty
 
 	// Check if we have a valid response
 	if err != nil {
+		err = watch.stallError(err)
+		if opts.StreamChan != nil && streamFallback == nil {
+			err = streamingUnsupportedError(err)
+		}
+		if opts.Model != "" {
+			// Name the model the call asked for (WithModel) in ErrModelNotFound
+			err = modelNotFoundError(p.provider, opts.Model, err)
+		}
+		providerErr := newProviderError(p.provider, p.modelID, err)
+		err = providerErr
+		if providerErr.QuotaExceeded {
+			p.logger.Infof("💳 Provider quota or credits exhausted - provider: %s, model: %s", string(p.provider), p.modelID)
+		}
 		p.logger.Infof("❌ LLM generation failed - provider: %s, model: %s, error: %v", string(p.provider), p.modelID, err)
 
 		// Emit LLM generation error event with rich debugging information
@@ -1218,16 +1617,28 @@ func (p *ProviderAwareLLM) GenerateContent(ctx context.Context, messages []llmty
 			CustomFields: map[string]string{
 				"provider":        string(p.provider),
 				"model_id":        p.modelID,
-				"messages":        fmt.Sprintf("%d", len(messages)),
+				"messages":        fmt.Sprintf("%d", len(sent.get())),
 				"temperature":     fmt.Sprintf("%f", getTemperatureFromOptions(options)),
-				"message_content": extractMessageContentAsString(messages),
+				"message_content": extractMessageContentAsString(sent.get()),
 				"error":           err.Error(),
-				"error_type":      fmt.Sprintf("%T", err),
+				"error_type":      fmt.Sprintf("%T", providerErr.Err),
+				"retryable":       strconv.FormatBool(providerErr.Retryable),
+				"quota_exceeded":  strconv.FormatBool(providerErr.QuotaExceeded),
 				"debug_note":      "Enhanced error logging for turn 2 debugging",
 			},
 		}
-		emitLLMGenerationError(p.eventEmitter, string(p.provider), p.modelID, OperationLLMGeneration, len(messages), getTemperatureFromOptions(options), extractMessageContentAsString(messages), err, p.traceID, errorMetadata)
+		emitLLMGenerationError(p.eventEmitter, string(p.provider), p.modelID, OperationLLMGeneration, len(sent.get()), getTemperatureFromOptions(options), extractMessageContentAsString(sent.get()), err, p.traceID, errorMetadata)
 
+		// A call cancelled mid-stream returns what was streamed so far along with the error
+		if partial != nil && ctx.Err() != nil {
+			resp = partial.response()
+			var stallErr *StreamStalledError
+			if errors.As(err, &stallErr) {
+				stallErr.Partial = resp
+			}
+			p.logger.Infof("🛑 Streaming call cancelled - returning %d chars streamed so far", len(resp.Choices[0].Content))
+			return resp, err
+		}
 		return nil, err
 	}
 
@@ -1242,11 +1653,69 @@ func (p *ProviderAwareLLM) GenerateContent(ctx context.Context, messages []llmty
 				"debug_note": "Response validation failed - nil response",
 			},
 		}
-		emitLLMGenerationError(p.eventEmitter, string(p.provider), p.modelID, OperationLLMGeneration, len(messages), getTemperatureFromOptions(options), extractMessageContentAsString(messages), fmt.Errorf("response validation failed - nil response"), p.traceID, errorMetadata)
+		emitLLMGenerationError(p.eventEmitter, string(p.provider), p.modelID, OperationLLMGeneration, len(sent.get()), getTemperatureFromOptions(options), extractMessageContentAsString(sent.get()), fmt.Errorf("response validation failed - nil response"), p.traceID, errorMetadata)
 
 		return nil, fmt.Errorf("response is nil")
 	}
 
+	// Report the usage of the whole response for models that only set it per choice
+	if resp.Usage == nil && !resp.DryRun {
+		resp.Usage = responseUsage(resp)
+	}
+
+	// Remove reasoning spans from the content
+	if len(opts.StripReasoningTags) > 0 && !resp.DryRun {
+		stripResponseReasoningTags(resp, opts.StripReasoningTags, reasoningTagsLeadingOnly(opts))
+	}
+
+	// Parse emulated tool calls and replay the response to the caller's stream
+	if emulation != nil && !resp.DryRun {
+		emulation.finalize(resp)
+		emulation.stream(ctx, resp)
+	}
+
+	// Ask the model once for required tool arguments it left out
+	if opts.EnforceRequiredToolArgs && len(opts.Tools) > 0 && !resp.DryRun {
+		p.enforceRequiredToolArgs(ctx, messages, options, opts.Tools, resp)
+	}
+
+	// Report tool calls under the caller's tool names
+	if toolNames != nil && !resp.DryRun {
+		toolNames.restore(resp)
+	}
+
+	// Reject calls to tools that are not allowed this turn
+	if allowedTools != nil && !resp.DryRun {
+		if err := allowedTools.finalize(resp); err != nil {
+			p.logger.Infof("❌ Disallowed tool call - provider: %s, model: %s, error: %v", string(p.provider), p.modelID, err)
+			return nil, err
+		}
+	}
+
+	// Keep only the first tool calls of each choice
+	if opts.MaxToolCallsPerResponse > 0 && !resp.DryRun {
+		p.truncateToolCalls(resp, opts.MaxToolCallsPerResponse)
+	}
+
+	// Put the structured output JSON into Content
+	if structured != nil && !resp.DryRun {
+		if resp, err = p.finalizeStructuredOutput(ctx, structured, messages, options, opts.SchemaRetries, resp); err != nil {
+			p.logger.Infof("❌ Structured output failed - provider: %s, model: %s, error: %v", string(p.provider), p.modelID, err)
+			return nil, err
+		}
+	}
+
+	// Parse the JSON of the answer or of the forced tool call for callers
+	if !resp.DryRun {
+		setParsedJSON(resp, opts)
+	}
+
+	// Dry runs carry the constructed request instead of choices
+	if resp.DryRun {
+		p.logger.Infof("🧪 DRY RUN - request built but not sent - provider: %s, model: %s", string(p.provider), p.modelID)
+		return resp, nil
+	}
+
 	if resp.Choices == nil {
 		p.logger.Infof("❌ Response.Choices is nil")
 
@@ -1256,14 +1725,14 @@ func (p *ProviderAwareLLM) GenerateContent(ctx context.Context, messages []llmty
 			CustomFields: map[string]string{
 				"provider":        string(p.provider),
 				"model_id":        p.modelID,
-				"messages":        fmt.Sprintf("%d", len(messages)),
+				"messages":        fmt.Sprintf("%d", len(sent.get())),
 				"temperature":     fmt.Sprintf("%f", getTemperatureFromOptions(options)),
-				"message_content": extractMessageContentAsString(messages),
+				"message_content": extractMessageContentAsString(sent.get()),
 				"error":           "Response.Choices is nil",
 				"debug_note":      "Response validation failed - nil choices",
 			},
 		}
-		emitLLMGenerationError(p.eventEmitter, string(p.provider), p.modelID, OperationLLMGeneration, len(messages), getTemperatureFromOptions(options), extractMessageContentAsString(messages), fmt.Errorf("response.Choices is nil"), p.traceID, errorMetadata)
+		emitLLMGenerationError(p.eventEmitter, string(p.provider), p.modelID, OperationLLMGeneration, len(sent.get()), getTemperatureFromOptions(options), extractMessageContentAsString(sent.get()), fmt.Errorf("response.Choices is nil"), p.traceID, errorMetadata)
 
 		return nil, fmt.Errorf("response.Choices is nil")
 	}
@@ -1293,7 +1762,7 @@ func (p *ProviderAwareLLM) GenerateContent(ctx context.Context, messages []llmty
 
 		// Log the messages that were sent to the LLM
 		p.logger.Errorf("🔍 MESSAGES SENT TO LLM:")
-		for i, msg := range messages {
+		for i, msg := range sent.get() {
 			p.logger.Errorf("   Message %d - Role: %s, Parts: %d", i+1, msg.Role, len(msg.Parts))
 			for j, part := range msg.Parts {
 				p.logger.Errorf("     Part %d - Type: %T, Content: %+v", j+1, part, part)
@@ -1306,14 +1775,14 @@ func (p *ProviderAwareLLM) GenerateContent(ctx context.Context, messages []llmty
 			CustomFields: map[string]string{
 				"provider":        string(p.provider),
 				"model_id":        p.modelID,
-				"messages":        fmt.Sprintf("%d", len(messages)),
+				"messages":        fmt.Sprintf("%d", len(sent.get())),
 				"temperature":     fmt.Sprintf("%f", getTemperatureFromOptions(options)),
-				"message_content": extractMessageContentAsString(messages),
+				"message_content": extractMessageContentAsString(sent.get()),
 				"error":           "Response.Choices is empty",
 				"debug_note":      "Response validation failed - empty choices array",
 			},
 		}
-		emitLLMGenerationError(p.eventEmitter, string(p.provider), p.modelID, OperationLLMGeneration, len(messages), getTemperatureFromOptions(options), extractMessageContentAsString(messages), fmt.Errorf("response.Choices is empty"), p.traceID, errorMetadata)
+		emitLLMGenerationError(p.eventEmitter, string(p.provider), p.modelID, OperationLLMGeneration, len(sent.get()), getTemperatureFromOptions(options), extractMessageContentAsString(sent.get()), fmt.Errorf("response.Choices is empty"), p.traceID, errorMetadata)
 
 		return nil, fmt.Errorf("response.Choices is empty")
 	}
@@ -1406,7 +1875,7 @@ func (p *ProviderAwareLLM) GenerateContent(ctx context.Context, messages []llmty
 				jsonStr := string(respJSON)
 				// Truncate if too long to avoid massive log files
 				if len(jsonStr) > 5000 {
-					jsonStr = jsonStr[:5000] + "\n   ... (truncated, total length: " + fmt.Sprintf("%d", len(jsonStr)) + " bytes)"
+					jsonStr = utils.TruncateUTF8(jsonStr, 5000) + "\n   ... (truncated, total length: " + fmt.Sprintf("%d", len(jsonStr)) + " bytes)"
 				}
 				p.logger.Errorf("🔍 RAW RESPONSE AS JSON (processed by langchaingo):")
 				p.logger.Errorf("%s", jsonStr)
@@ -1422,7 +1891,7 @@ func (p *ProviderAwareLLM) GenerateContent(ctx context.Context, messages []llmty
 
 			// Log the messages that were sent to the LLM
 			p.logger.Errorf("🔍 MESSAGES SENT TO LLM:")
-			for i, msg := range messages {
+			for i, msg := range sent.get() {
 				p.logger.Errorf("   Message %d - Role: %s, Parts: %d", i+1, msg.Role, len(msg.Parts))
 				for j, part := range msg.Parts {
 					p.logger.Errorf("     Part %d - Type: %T, Content: %+v", j+1, part, part)
@@ -1435,16 +1904,21 @@ func (p *ProviderAwareLLM) GenerateContent(ctx context.Context, messages []llmty
 				CustomFields: map[string]string{
 					"provider":        string(p.provider),
 					"model_id":        p.modelID,
-					"messages":        fmt.Sprintf("%d", len(messages)),
+					"messages":        fmt.Sprintf("%d", len(sent.get())),
 					"temperature":     fmt.Sprintf("%f", getTemperatureFromOptions(options)),
-					"message_content": extractMessageContentAsString(messages),
+					"message_content": extractMessageContentAsString(sent.get()),
 					"error":           "Choice.Content is empty",
 					"debug_note":      "Response validation failed - empty content",
 				},
 			}
-			emitLLMGenerationError(p.eventEmitter, string(p.provider), p.modelID, OperationLLMGeneration, len(messages), getTemperatureFromOptions(options), extractMessageContentAsString(messages), fmt.Errorf("choice.Content is empty"), p.traceID, errorMetadata)
+			emptyErr := fmt.Errorf("choice.Content is empty")
+			if utils.IsContentFilterStopReason(firstChoice.StopReason) {
+				// A genuine refusal or safety block, not a flaky empty response
+				emptyErr = fmt.Errorf("choice.Content is empty: response blocked by content filter (stop reason %q)", firstChoice.StopReason)
+			}
+			emitLLMGenerationError(p.eventEmitter, string(p.provider), p.modelID, OperationLLMGeneration, len(sent.get()), getTemperatureFromOptions(options), extractMessageContentAsString(sent.get()), emptyErr, p.traceID, errorMetadata)
 
-			return nil, fmt.Errorf("choice.Content is empty")
+			return nil, emptyErr
 		}
 	}
 
@@ -1504,15 +1978,9 @@ func (p *ProviderAwareLLM) GenerateContent(ctx context.Context, messages []llmty
 		}
 	}
 
-	// Extract token usage from GenerationInfo if available
-	if len(resp.Choices) > 0 && resp.Choices[0].GenerationInfo != nil {
-		// Extract token usage and create success event with comprehensive data
-		usage := extractTokenUsageFromGenerationInfo(resp.Choices[0].GenerationInfo)
-
-		// Calculate total tokens if not provided by the provider
-		if usage.TotalTokens == 0 && usage.InputTokens > 0 && usage.OutputTokens > 0 {
-			usage.TotalTokens = usage.InputTokens + usage.OutputTokens
-		}
+	// Report token usage if available
+	if resp.Usage != nil {
+		usage := eventTokenUsage(resp.Usage)
 
 		p.logger.Infof("Token usage extracted: Input=%d, Output=%d, Total=%d", usage.InputTokens, usage.OutputTokens, usage.TotalTokens)
 
@@ -1522,21 +1990,24 @@ func (p *ProviderAwareLLM) GenerateContent(ctx context.Context, messages []llmty
 			CustomFields: map[string]string{
 				"provider":        string(p.provider),
 				"model_id":        p.modelID,
-				"messages":        fmt.Sprintf("%d", len(messages)),
+				"messages":        fmt.Sprintf("%d", len(sent.get())),
 				"temperature":     fmt.Sprintf("%f", getTemperatureFromOptions(options)),
-				"message_content": extractMessageContentAsString(messages),
+				"message_content": extractMessageContentAsString(sent.get()),
 				"response_length": fmt.Sprintf("%d", len(resp.Choices[0].Content)),
 				"choices_count":   fmt.Sprintf("%d", len(resp.Choices)),
 				"input_tokens":    fmt.Sprintf("%d", usage.InputTokens),
 				"output_tokens":   fmt.Sprintf("%d", usage.OutputTokens),
 				"total_tokens":    fmt.Sprintf("%d", usage.TotalTokens),
-				"note":            "Token usage extracted from GenerationInfo",
+				"note":            "Token usage from ContentResponse.Usage",
 			},
 		}
-		emitLLMGenerationSuccess(p.eventEmitter, string(p.provider), p.modelID, OperationLLMGeneration, len(messages), getTemperatureFromOptions(options), extractMessageContentAsString(messages), len(resp.Choices[0].Content), len(resp.Choices), p.traceID, successMetadata)
+		if usage.Cost != "" {
+			successMetadata.CustomFields["cost"] = usage.Cost
+		}
+		emitLLMGenerationSuccess(p.eventEmitter, string(p.provider), p.modelID, OperationLLMGeneration, len(sent.get()), getTemperatureFromOptions(options), extractMessageContentAsString(sent.get()), len(resp.Choices[0].Content), len(resp.Choices), p.traceID, successMetadata)
 	} else {
 		// No token usage available, emit success event without usage
-		p.logger.Infof("No GenerationInfo available")
+		p.logger.Infof("No token usage available")
 
 		// Emit LLM generation success event without token usage
 		successMetadata := LLMMetadata{
@@ -1544,20 +2015,76 @@ func (p *ProviderAwareLLM) GenerateContent(ctx context.Context, messages []llmty
 			CustomFields: map[string]string{
 				"provider":        string(p.provider),
 				"model_id":        p.modelID,
-				"messages":        fmt.Sprintf("%d", len(messages)),
+				"messages":        fmt.Sprintf("%d", len(sent.get())),
 				"temperature":     fmt.Sprintf("%f", getTemperatureFromOptions(options)),
-				"message_content": extractMessageContentAsString(messages),
+				"message_content": extractMessageContentAsString(sent.get()),
 				"response_length": fmt.Sprintf("%d", len(resp.Choices[0].Content)),
 				"choices_count":   fmt.Sprintf("%d", len(resp.Choices)),
-				"note":            "No GenerationInfo available for token usage",
+				"note":            "No token usage available",
 			},
 		}
-		emitLLMGenerationSuccess(p.eventEmitter, string(p.provider), p.modelID, OperationLLMGeneration, len(messages), getTemperatureFromOptions(options), extractMessageContentAsString(messages), len(resp.Choices[0].Content), len(resp.Choices), p.traceID, successMetadata)
+		emitLLMGenerationSuccess(p.eventEmitter, string(p.provider), p.modelID, OperationLLMGeneration, len(sent.get()), getTemperatureFromOptions(options), extractMessageContentAsString(sent.get()), len(resp.Choices[0].Content), len(resp.Choices), p.traceID, successMetadata)
 	}
 
 	return resp, nil
 }
 
+// logRequest logs the system prompts, messages and tools of an attempt as sent to the provider
+func (p *ProviderAwareLLM) logRequest(messages []llmtypes.MessageContent, opts *llmtypes.CallOptions) {
+	// Extract and log system prompts
+	var systemPrompts []string
+	for _, msg := range messages {
+		if msg.Role == llmtypes.ChatMessageTypeSystem {
+			text := extractTextFromParts(msg.Parts)
+			if text != "" {
+				systemPrompts = append(systemPrompts, text)
+			}
+		}
+	}
+	if len(systemPrompts) > 0 {
+		p.logger.Infof("📋 SYSTEM PROMPTS (%d):", len(systemPrompts))
+		for i, prompt := range systemPrompts {
+			p.logger.Infof("   [%d] %s", i+1, prompt)
+		}
+	} else {
+		p.logger.Infof("📋 SYSTEM PROMPTS: None")
+	}
+
+	// Log all messages
+	p.logger.Infof("💬 MESSAGES (%d):", len(messages))
+	for i, msg := range messages {
+		text := extractTextFromParts(msg.Parts)
+		// Truncate very long messages for readability
+		displayText := text
+		if len(displayText) > 500 {
+			displayText = utils.TruncateUTF8(displayText, 500) + "... [truncated]"
+		}
+		p.logger.Infof("   [%d] Role: %s, Content: %s", i+1, msg.Role, displayText)
+	}
+
+	// Log tools if provided
+	if len(opts.Tools) > 0 {
+		p.logger.Infof("🔧 TOOLS (%d):", len(opts.Tools))
+		for i, tool := range opts.Tools {
+			if tool.Function != nil {
+				toolJSON, err := json.MarshalIndent(tool, "      ", "  ")
+				if err != nil {
+					p.logger.Infof("   [%d] %s (error marshaling: %v)", i+1, tool.Function.Name, err)
+				} else {
+					p.logger.Infof("   [%d] %s:\n%s", i+1, tool.Function.Name, string(toolJSON))
+				}
+			} else {
+				p.logger.Infof("   [%d] Tool with nil Function", i+1)
+			}
+		}
+	} else {
+		p.logger.Infof("🔧 TOOLS: None")
+	}
+
+	// Log the prompt as sent, with every part, at debug level
+	p.logger.Debugf("📝 FINAL PROMPT (%d messages):\n%s", len(messages), renderMessages(messages))
+}
+
 // extractMessageContentAsString converts message content to a readable string
 func extractMessageContentAsString(messages []llmtypes.MessageContent) string {
 	if len(messages) == 0 {
@@ -1578,11 +2105,11 @@ func extractMessageContentAsString(messages []llmtypes.MessageContent) string {
 			if textPart, ok := part.(llmtypes.TextContent); ok {
 				content := textPart.Text
 				if len(content) > 100 {
-					content = content[:100] + "..."
+					content = utils.TruncateUTF8(content, 100) + "..."
 				}
 				result.WriteString(fmt.Sprintf("Text:%s", content))
 			} else {
-				result.WriteString(fmt.Sprintf("Part:%T", part))
+				result.WriteString(renderPart(part, 100))
 			}
 		}
 	}
@@ -1596,12 +2123,12 @@ func getTemperatureFromOptions(options []llmtypes.CallOption) float64 {
 	return 0.7 // default temperature
 }
 
-// truncateString truncates a string to a specified length
+// truncateString truncates a string to a specified length, without splitting a character
 func truncateString(s string, length int) string {
 	if len(s) <= length {
 		return s
 	}
-	return s[:length] + "..."
+	return utils.TruncateUTF8(s, length) + "..."
 }
 
 // WithOpenRouterUsage enables usage parameter for OpenRouter requests to get cache token information
@@ -1965,11 +2492,14 @@ func validateOpenRouterAPIKey(apiKey string, modelID string) (bool, string, erro
 	})
 	if err != nil {
 		fmt.Printf("[OPENROUTER VALIDATION ERROR] OpenRouter test generation failed: %v\n", err)
+		if IsModelNotFoundError(err) {
+			return false, fmt.Sprintf("Model %s not found or not available", modelID), nil
+		}
 		// Check for specific error types
-		if strings.Contains(err.Error(), "unauthorized") || strings.Contains(err.Error(), "401") {
+		if strings.Contains(err.Error(), "unauthorized") || errorStatusCode(err) == http.StatusUnauthorized {
 			return false, "Invalid OpenRouter API key", nil
 		}
-		if strings.Contains(err.Error(), "rate limit") || strings.Contains(err.Error(), "429") {
+		if strings.Contains(err.Error(), "rate limit") || errorStatusCode(err) == http.StatusTooManyRequests {
 			return false, "OpenRouter API rate limit exceeded", nil
 		}
 		if strings.Contains(err.Error(), "timeout") {
@@ -2041,11 +2571,14 @@ func validateOpenAIAPIKey(apiKey string, modelID string) (bool, string, error) {
 	})
 	if err != nil {
 		fmt.Printf("[OPENAI VALIDATION ERROR] OpenAI test generation failed: %v\n", err)
+		if IsModelNotFoundError(err) {
+			return false, fmt.Sprintf("Model %s not found or not available", modelID), nil
+		}
 		// Check for specific error types
-		if strings.Contains(err.Error(), "unauthorized") || strings.Contains(err.Error(), "401") {
+		if strings.Contains(err.Error(), "unauthorized") || errorStatusCode(err) == http.StatusUnauthorized {
 			return false, "Invalid OpenAI API key", nil
 		}
-		if strings.Contains(err.Error(), "rate limit") || strings.Contains(err.Error(), "429") {
+		if strings.Contains(err.Error(), "rate limit") || errorStatusCode(err) == http.StatusTooManyRequests {
 			return false, "OpenAI API rate limit exceeded", nil
 		}
 		if strings.Contains(err.Error(), "timeout") {
@@ -2118,11 +2651,14 @@ func validateAnthropicAPIKey(apiKey string, modelID string) (bool, string, error
 	})
 	if err != nil {
 		fmt.Printf("[ANTHROPIC VALIDATION ERROR] Anthropic test generation failed: %v\n", err)
+		if IsModelNotFoundError(err) {
+			return false, fmt.Sprintf("Model %s not found or not available", modelID), nil
+		}
 		// Check for specific error types
-		if strings.Contains(err.Error(), "unauthorized") || strings.Contains(err.Error(), "401") {
+		if strings.Contains(err.Error(), "unauthorized") || errorStatusCode(err) == http.StatusUnauthorized {
 			return false, "Invalid Anthropic API key", nil
 		}
-		if strings.Contains(err.Error(), "rate limit") || strings.Contains(err.Error(), "429") {
+		if strings.Contains(err.Error(), "rate limit") || errorStatusCode(err) == http.StatusTooManyRequests {
 			return false, "Anthropic API rate limit exceeded", nil
 		}
 		if strings.Contains(err.Error(), "timeout") {
@@ -2242,6 +2778,9 @@ func validateVertexCredentials(modelID string) (bool, string, error) {
 	})
 	if err != nil {
 		fmt.Printf("[VERTEX VALIDATION ERROR] Vertex AI test generation failed: %v\n", err)
+		if IsModelNotFoundError(err) {
+			return false, fmt.Sprintf("Model %s not found or not available", modelID), nil
+		}
 		// Check for specific error types
 		if strings.Contains(err.Error(), "authentication") || strings.Contains(err.Error(), "unauthorized") {
 			return false, "OAuth authentication failed. Make sure you have run 'gcloud auth application-default login' or set up service account credentials.", nil
@@ -2325,17 +2864,17 @@ func validateVertexAPIKey(apiKey string, modelID string) (bool, string, error) {
 	})
 	if err != nil {
 		fmt.Printf("[VERTEX VALIDATION ERROR] Vertex AI test generation failed: %v\n", err)
+		if IsModelNotFoundError(err) {
+			return false, fmt.Sprintf("Model %s not found or not available", modelID), nil
+		}
 		// Check for specific error types
-		if strings.Contains(err.Error(), "unauthorized") || strings.Contains(err.Error(), "401") {
+		if strings.Contains(err.Error(), "unauthorized") || errorStatusCode(err) == http.StatusUnauthorized {
 			return false, "Invalid Vertex AI API key", nil
 		}
-		if strings.Contains(err.Error(), "permission") || strings.Contains(err.Error(), "forbidden") || strings.Contains(err.Error(), "403") {
+		if strings.Contains(err.Error(), "permission") || strings.Contains(err.Error(), "forbidden") || errorStatusCode(err) == http.StatusForbidden {
 			return false, "API key lacks required permissions", nil
 		}
-		if strings.Contains(err.Error(), "not found") || strings.Contains(err.Error(), "404") {
-			return false, fmt.Sprintf("Model %s not found", modelID), nil
-		}
-		if strings.Contains(err.Error(), "rate limit") || strings.Contains(err.Error(), "429") {
+		if strings.Contains(err.Error(), "rate limit") || errorStatusCode(err) == http.StatusTooManyRequests {
 			return false, "Vertex AI API rate limit exceeded", nil
 		}
 		if strings.Contains(err.Error(), "timeout") {
@@ -2476,8 +3015,11 @@ func validateBedrockCredentials(modelID string) (bool, string, error) {
 	})
 	if err != nil {
 		fmt.Printf("[BEDROCK VALIDATION ERROR] Bedrock test generation failed: %v\n", err)
+		if IsModelNotFoundError(err) {
+			return false, fmt.Sprintf("Model %s not found or not available", modelID), nil
+		}
 		// Check for specific error types
-		if strings.Contains(err.Error(), "AccessDenied") {
+		if errorDetails(err).Code == "AccessDeniedException" || strings.Contains(err.Error(), "AccessDenied") {
 			return false, "AWS credentials do not have permission to access Bedrock", nil
 		}
 		if strings.Contains(err.Error(), "InvalidUserID.NotFound") {
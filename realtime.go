@@ -0,0 +1,83 @@
+package llmproviders
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+	openaiadapter "github.com/manishiitg/multi-llm-provider-go/pkg/adapters/openai"
+	vertexadapter "github.com/manishiitg/multi-llm-provider-go/pkg/adapters/vertex"
+
+	"google.golang.org/genai"
+)
+
+// Default realtime models used when config.ModelID is empty
+const (
+	DefaultOpenAIRealtimeModel = "gpt-4o-realtime-preview"
+	DefaultGeminiLiveModel     = "gemini-2.0-flash-live-001"
+)
+
+// NewRealtimeSession opens a bidirectional text and audio session over a WebSocket:
+// the OpenAI Realtime API for ProviderOpenAI and the Gemini Live API for ProviderVertex.
+// API keys are resolved like InitializeLLM (config.APIKeys, then environment).
+// ctx bounds the connection handshake; the session stays open until Close is called.
+func NewRealtimeSession(ctx context.Context, config Config, opts ...llmtypes.RealtimeOption) (llmtypes.RealtimeSession, error) {
+	logger := config.Logger
+	if logger == nil {
+		logger = &noopLoggerImpl{}
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	switch config.Provider {
+	case ProviderOpenAI:
+		apiKey := os.Getenv("OPENAI_API_KEY")
+		if config.APIKeys != nil && config.APIKeys.OpenAI != nil && *config.APIKeys.OpenAI != "" {
+			apiKey = *config.APIKeys.OpenAI
+		}
+		if apiKey == "" {
+			return nil, fmt.Errorf("OPENAI_API_KEY is required for OpenAI realtime sessions (not found in config or environment)")
+		}
+		modelID := config.ModelID
+		if modelID == "" {
+			modelID = DefaultOpenAIRealtimeModel
+		}
+		logger.Infof("Opening OpenAI realtime session - model_id: %s", modelID)
+		session, err := openaiadapter.NewRealtimeSession(ctx, apiKey, modelID, logger, opts...)
+		if err != nil {
+			return nil, err
+		}
+		return session, nil
+
+	case ProviderVertex:
+		apiKey := ""
+		if config.APIKeys != nil && config.APIKeys.Vertex != nil && *config.APIKeys.Vertex != "" {
+			apiKey = *config.APIKeys.Vertex
+		} else if apiKey = os.Getenv("VERTEX_API_KEY"); apiKey == "" {
+			apiKey = os.Getenv("GOOGLE_API_KEY")
+		}
+		if apiKey == "" {
+			return nil, fmt.Errorf("VERTEX_API_KEY or GOOGLE_API_KEY is required for Gemini Live sessions (not found in config or environment)")
+		}
+		client, err := genai.NewClient(ctx, &genai.ClientConfig{
+			APIKey:  apiKey,
+			Backend: genai.BackendGeminiAPI,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create GenAI client: %w", err)
+		}
+		modelID := config.ModelID
+		if modelID == "" {
+			modelID = DefaultGeminiLiveModel
+		}
+		logger.Infof("Opening Gemini Live session - model_id: %s", modelID)
+		session, err := vertexadapter.NewLiveSession(ctx, client, modelID, logger, opts...)
+		if err != nil {
+			return nil, err
+		}
+		return session, nil
+	}
+	return nil, fmt.Errorf("realtime sessions are not supported for provider %q (supported: openai, vertex)", config.Provider)
+}
@@ -0,0 +1,49 @@
+package llmproviders
+
+import (
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+	"github.com/manishiitg/multi-llm-provider-go/pkg/utils"
+)
+
+// reasoningTagsLeadingOnly reports whether only leading reasoning spans may be stripped,
+// because the content is JSON that could contain the tags in its values
+func reasoningTagsLeadingOnly(opts *llmtypes.CallOptions) bool {
+	return jsonRequested(opts)
+}
+
+// stripResponseReasoningTags removes the spans of tags from the content of each choice of
+// resp (WithStripReasoningTags) and keeps them as reasoning blocks, in place of the text
+// they were part of
+func stripResponseReasoningTags(resp *llmtypes.ContentResponse, tags []string, leadingOnly bool) {
+	for _, choice := range resp.Choices {
+		if choice == nil || (choice.Content == "" && len(choice.Blocks) == 0) {
+			continue
+		}
+		text, _ := utils.StripReasoningTags(choice.Content, tags, leadingOnly)
+
+		stripper := utils.NewReasoningTagStripper(tags, leadingOnly)
+		var blocks []llmtypes.ContentBlock
+		stripped := false
+		add := func(parts []llmtypes.ContentBlock) {
+			for _, part := range parts {
+				stripped = stripped || part.Type == llmtypes.ContentBlockTypeReasoning
+				blocks = llmtypes.AppendTextBlock(blocks, part.Type, part.Text)
+			}
+		}
+		for _, block := range choice.OrderedBlocks() {
+			if block.Type != llmtypes.ContentBlockTypeText {
+				block.ToolCall = nil
+				blocks = append(blocks, block)
+				continue
+			}
+			add(stripper.Write(block.Text))
+		}
+		add(stripper.Flush())
+
+		if text == choice.Content && !stripped {
+			continue
+		}
+		choice.Content = text
+		choice.Blocks = blocks
+	}
+}
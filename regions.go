@@ -0,0 +1,110 @@
+package llmproviders
+
+import (
+	"fmt"
+	"os"
+	"slices"
+	"strings"
+
+	"google.golang.org/genai"
+)
+
+// supportedRegions lists the regions Config.Region accepts for each provider. OpenAI regions
+// are its data residency endpoints (the project must be set up for the region); Vertex
+// regions are Google Cloud locations and Bedrock regions are AWS regions.
+var supportedRegions = map[Provider][]string{
+	ProviderOpenAI: {"us", "eu"},
+	ProviderVertex: {
+		"global", "us-central1", "us-east1", "us-east4", "us-east5", "us-west1", "us-west4",
+		"europe-west1", "europe-west3", "europe-west4", "europe-west9", "asia-east1",
+		"asia-northeast1", "asia-southeast1", "australia-southeast1",
+	},
+	ProviderBedrock: {
+		"us-east-1", "us-east-2", "us-west-2", "ca-central-1", "eu-central-1", "eu-west-1",
+		"eu-west-2", "eu-west-3", "eu-north-1", "ap-northeast-1", "ap-northeast-2",
+		"ap-south-1", "ap-southeast-1", "ap-southeast-2",
+	},
+}
+
+// SupportedRegions returns the regions Config.Region accepts for provider, nil if the
+// provider has no regional endpoints (Anthropic and OpenRouter). Claude models are served
+// in region through Bedrock and Vertex.
+func SupportedRegions(provider Provider) []string {
+	return slices.Clone(supportedRegions[provider])
+}
+
+// IsModelAvailableInRegion reports whether modelID is served by provider in region, from the
+// ModelInfo registry. Models without region data for the provider are assumed to be
+// available in every supported region.
+func IsModelAvailableInRegion(provider Provider, modelID, region string) bool {
+	if !slices.Contains(supportedRegions[provider], region) {
+		return false
+	}
+	info, ok := LookupModelInfo(modelID)
+	if !ok || info.Regions[provider] == nil {
+		return true
+	}
+	return slices.Contains(info.Regions[provider], region)
+}
+
+// validateRegion checks Config.Region against the provider's regions and the availability
+// of the model and fallback models there
+func validateRegion(config Config) error {
+	if config.Region == "" {
+		return nil
+	}
+	regions := supportedRegions[config.Provider]
+	if config.Provider == ProviderAnthropic {
+		return fmt.Errorf("the Anthropic API has no regional endpoints; to keep Claude traffic in region %s use provider bedrock or vertex, whose Claude models accept Config.Region", config.Region)
+	}
+	if regions == nil {
+		return fmt.Errorf("provider %s has no regional endpoints; Config.Region is supported for openai, vertex and bedrock", config.Provider)
+	}
+	if !slices.Contains(regions, config.Region) {
+		return fmt.Errorf("region %q is not supported for provider %s (supported: %s)", config.Region, config.Provider, strings.Join(regions, ", "))
+	}
+	for _, modelID := range append([]string{config.ModelID}, config.FallbackModels...) {
+		if modelID != "" && !IsModelAvailableInRegion(config.Provider, modelID, config.Region) {
+			return fmt.Errorf("model %s is not available in region %s on provider %s", modelID, config.Region, config.Provider)
+		}
+	}
+	return nil
+}
+
+// regionName describes region in errors
+func regionName(region string) string {
+	if region == "" {
+		return "the provider's default endpoint"
+	}
+	return "region " + region
+}
+
+// openAIBaseURL returns the OpenAI API endpoint for region, e.g. https://eu.api.openai.com/v1
+func openAIBaseURL(region string) string {
+	if region == "" {
+		return "https://api.openai.com/v1"
+	}
+	return fmt.Sprintf("https://%s.api.openai.com/v1", region)
+}
+
+// genaiClientConfig returns the Google GenAI client configuration: the Gemini Developer API
+// with apiKey, or Vertex AI in Config.Region (project VERTEX_PROJECT_ID, Application Default
+// Credentials) when a region is set, since the Gemini Developer API cannot be pinned to one
+func genaiClientConfig(config Config, apiKey string) (*genai.ClientConfig, error) {
+	if config.Region == "" {
+		if apiKey == "" {
+			return nil, fmt.Errorf("VERTEX_API_KEY or GOOGLE_API_KEY is required for Gemini models (not found in config or environment)")
+		}
+		return &genai.ClientConfig{APIKey: apiKey, Backend: genai.BackendGeminiAPI, HTTPClient: config.httpClient()}, nil
+	}
+	projectID := os.Getenv("VERTEX_PROJECT_ID")
+	if projectID == "" {
+		return nil, fmt.Errorf("VERTEX_PROJECT_ID environment variable is required for Gemini models when Config.Region is set")
+	}
+	return &genai.ClientConfig{
+		Project:    projectID,
+		Location:   config.Region,
+		Backend:    genai.BackendVertexAI,
+		HTTPClient: config.httpClient(),
+	}, nil
+}
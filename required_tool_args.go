@@ -0,0 +1,132 @@
+package llmproviders
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+// missingRequiredArgs returns the required parameters of tool that toolCall leaves out,
+// null or empty. All of them are missing when the arguments are not a JSON object.
+func missingRequiredArgs(tool llmtypes.Tool, toolCall llmtypes.ToolCall) []string {
+	if tool.Function == nil || tool.Function.Parameters == nil || toolCall.FunctionCall == nil {
+		return nil
+	}
+	var args map[string]interface{}
+	if strings.TrimSpace(toolCall.FunctionCall.Arguments) != "" {
+		_ = json.Unmarshal([]byte(toolCall.FunctionCall.Arguments), &args)
+	}
+	var missing []string
+	for _, param := range tool.Function.Parameters.Required {
+		if value, ok := args[param]; !ok || value == nil || value == "" {
+			missing = append(missing, param)
+		}
+	}
+	return missing
+}
+
+// mergeToolArgs returns the arguments of original with those of corrected added or
+// replaced, as a JSON object
+func mergeToolArgs(original, corrected string) (string, error) {
+	merged := make(map[string]interface{})
+	if strings.TrimSpace(original) != "" {
+		// Arguments that are not an object have nothing worth keeping
+		_ = json.Unmarshal([]byte(original), &merged)
+	}
+	var correction map[string]interface{}
+	if err := json.Unmarshal([]byte(corrected), &correction); err != nil {
+		return "", fmt.Errorf("corrected arguments are not a JSON object: %w", err)
+	}
+	for key, value := range correction {
+		merged[key] = value
+	}
+	data, err := json.Marshal(merged)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// enforceRequiredToolArgs completes the tool calls of resp that miss required parameters
+// (WithEnforceRequiredToolArgs): for each choice with such calls, the model is told once
+// which parameters to provide and the arguments of its new calls to the same tools are
+// merged into the original calls, which keep their IDs. Calls the model doesn't correct are
+// returned as they were; a failed follow-up is logged and leaves resp unchanged.
+func (p *ProviderAwareLLM) enforceRequiredToolArgs(ctx context.Context, messages []llmtypes.MessageContent, options []llmtypes.CallOption, tools []llmtypes.Tool, resp *llmtypes.ContentResponse) {
+	toolsByName := make(map[string]llmtypes.Tool, len(tools))
+	for _, tool := range tools {
+		if tool.Function != nil {
+			toolsByName[tool.Function.Name] = tool
+		}
+	}
+
+	for choiceIndex, choice := range resp.Choices {
+		if choice == nil {
+			continue
+		}
+		var incomplete []int
+		var feedback strings.Builder
+		names := make(map[string]bool)
+		for i, toolCall := range choice.ToolCalls {
+			if toolCall.FunctionCall == nil {
+				continue
+			}
+			missing := missingRequiredArgs(toolsByName[toolCall.FunctionCall.Name], toolCall)
+			if len(missing) == 0 {
+				continue
+			}
+			incomplete = append(incomplete, i)
+			names[toolCall.FunctionCall.Name] = true
+			p.logger.Infof("🩹 Tool call %s (%s) is missing required arguments %v, asking the model to provide them", toolCall.ID, toolCall.FunctionCall.Name, missing)
+			fmt.Fprintf(&feedback, "Your call to %s with arguments %s is missing required arguments. You must provide: %s.\n", toolCall.FunctionCall.Name, toolCall.FunctionCall.Arguments, strings.Join(missing, ", "))
+		}
+		if len(incomplete) == 0 {
+			continue
+		}
+		feedback.WriteString("Call the tools again with all of their required arguments.")
+
+		// Force the tool when a single one needs correcting; the stream has already ended
+		retryOptions := append(append([]llmtypes.CallOption{}, options...), llmtypes.WithStreamingChan(nil))
+		if len(names) == 1 {
+			name := choice.ToolCalls[incomplete[0]].FunctionCall.Name
+			retryOptions = append(retryOptions, llmtypes.WithToolChoice(&llmtypes.ToolChoice{Type: "function", Function: &llmtypes.FunctionName{Name: name}}))
+		}
+		retryMessages := append(append([]llmtypes.MessageContent{}, messages...), llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, feedback.String()))
+		retry, err := p.generateAttempt(ctx, retryMessages, retryOptions)
+		if err != nil || retry == nil || len(retry.Choices) == 0 || retry.Choices[0] == nil {
+			p.logger.Infof("❌ Required tool arguments follow-up failed for choice %d: %v", choiceIndex, err)
+			continue
+		}
+		resp.Usage = llmtypes.AddUsage(resp.Usage, retry.Usage)
+
+		// Match corrected calls to the incomplete ones by tool name, in order
+		corrections := retry.Choices[0].ToolCalls
+		used := make([]bool, len(corrections))
+		for _, i := range incomplete {
+			toolCall := &choice.ToolCalls[i]
+			for j, correction := range corrections {
+				if used[j] || correction.FunctionCall == nil || correction.FunctionCall.Name != toolCall.FunctionCall.Name {
+					continue
+				}
+				used[j] = true
+				merged, err := mergeToolArgs(toolCall.FunctionCall.Arguments, correction.FunctionCall.Arguments)
+				if err != nil {
+					p.logger.Infof("❌ Tool call %s not corrected: %v", toolCall.ID, err)
+					break
+				}
+				functionCall := *toolCall.FunctionCall
+				functionCall.Arguments = merged
+				toolCall.FunctionCall = &functionCall
+				if missing := missingRequiredArgs(toolsByName[functionCall.Name], *toolCall); len(missing) > 0 {
+					p.logger.Infof("⚠️  Tool call %s (%s) is still missing required arguments %v", toolCall.ID, functionCall.Name, missing)
+				} else {
+					p.logger.Infof("✅ Tool call %s (%s) completed with the required arguments", toolCall.ID, functionCall.Name)
+				}
+				break
+			}
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package llmproviders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+// ErrResponseValidation is matched (errors.Is) by the error of a call whose response a
+// response validator rejected after every retry; use errors.As with *ResponseValidationError
+// to get the last response
+var ErrResponseValidation = errors.New("response failed validation")
+
+// ResponseValidationError is returned when the responses of every attempt failed a validator
+type ResponseValidationError struct {
+	// Attempts is the number of responses generated
+	Attempts int
+	// Response is the last rejected response
+	Response *llmtypes.ContentResponse
+	// Err is the error of the validator that rejected it
+	Err error
+}
+
+// Error names the validation error of the last attempt
+func (e *ResponseValidationError) Error() string {
+	return fmt.Sprintf("%v after %d attempt(s): %v", ErrResponseValidation, e.Attempts, e.Err)
+}
+
+// Is reports whether target is ErrResponseValidation
+func (e *ResponseValidationError) Is(target error) bool {
+	return target == ErrResponseValidation
+}
+
+// Unwrap returns the validator's error
+func (e *ResponseValidationError) Unwrap() error {
+	return e.Err
+}
+
+// generateValidated generates a response and checks it with the response validators of the
+// call, regenerating while one rejects it and WithValidationRetries allows. Each attempt is a
+// full call, so interceptors, structured output and retries apply to every response.
+func (p *ProviderAwareLLM) generateValidated(ctx context.Context, messages []llmtypes.MessageContent, opts *llmtypes.CallOptions, options []llmtypes.CallOption) (*llmtypes.ContentResponse, error) {
+	resp, err := p.generateContent(ctx, messages, options...)
+	if err != nil || len(opts.ResponseValidators) == 0 || resp.DryRun {
+		return resp, err
+	}
+
+	for attempt := 1; ; attempt++ {
+		err := validateResponse(opts.ResponseValidators, resp)
+		if err == nil {
+			return resp, nil
+		}
+		if attempt > opts.ValidationRetries {
+			p.logger.Infof("❌ Response failed validation - provider: %s, model: %s, attempts: %d, error: %v", string(p.provider), p.modelID, attempt, err)
+			return nil, &ResponseValidationError{Attempts: attempt, Response: resp, Err: err}
+		}
+		p.logger.Infof("🔁 Response failed validation, regenerating (%d/%d): %v", attempt, opts.ValidationRetries, err)
+
+		retryMessages := messages
+		if opts.ValidationFeedback {
+			retryMessages = append(append([]llmtypes.MessageContent{}, messages...),
+				llmtypes.TextParts(llmtypes.ChatMessageTypeAI, firstChoiceContent(resp)),
+				llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, fmt.Sprintf("Your previous response was rejected: %v\nRespond again, fixing this.", err)),
+			)
+		}
+		resp, err = p.generateContent(ctx, retryMessages, diversifyRetry(options, attempt)...)
+		if err != nil {
+			return nil, fmt.Errorf("validation retry %d: %w", attempt, err)
+		}
+	}
+}
+
+// validateResponse runs validators on resp in order and returns the first error
+func validateResponse(validators []llmtypes.ResponseValidator, resp *llmtypes.ContentResponse) error {
+	for _, validator := range validators {
+		if err := validator(resp); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// firstChoiceContent returns the content of the first choice of resp, or ""
+func firstChoiceContent(resp *llmtypes.ContentResponse) string {
+	if len(resp.Choices) == 0 || resp.Choices[0] == nil {
+		return ""
+	}
+	return resp.Choices[0].Content
+}
@@ -0,0 +1,144 @@
+package llmproviders
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/anthropics/anthropic-sdk-go"
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+	"github.com/manishiitg/multi-llm-provider-go/pkg/utils"
+
+	openaisdk "github.com/openai/openai-go/v3"
+)
+
+const (
+	// defaultMaxRetries applies when a call sets WithRetryPolicy without a retry count
+	defaultMaxRetries = 2
+	// defaultRetryBaseDelay and defaultRetryMaxDelay apply when the RetryPolicy leaves them 0
+	defaultRetryBaseDelay = 500 * time.Millisecond
+	defaultRetryMaxDelay  = 30 * time.Second
+)
+
+// retrySettings returns how many times the call is retried and how, from WithMaxRetries,
+// WithRetryPolicy and Config.MaxRetries. ok is false when none is set: retries are then
+// left to the provider SDK.
+func (p *ProviderAwareLLM) retrySettings(opts *llmtypes.CallOptions) (retries int, policy llmtypes.RetryPolicy, ok bool) {
+	switch {
+	case opts.MaxRetries != nil:
+		retries = *opts.MaxRetries
+	case p.maxRetries > 0:
+		retries = p.maxRetries
+	case opts.RetryPolicy != nil:
+		retries = defaultMaxRetries
+	default:
+		return 0, policy, false
+	}
+	if opts.RetryPolicy != nil {
+		policy = *opts.RetryPolicy
+	}
+	if policy.BaseDelay <= 0 {
+		policy.BaseDelay = defaultRetryBaseDelay
+	}
+	if policy.MaxDelay <= 0 {
+		policy.MaxDelay = defaultRetryMaxDelay
+	}
+	if policy.Retryable == nil {
+		policy.Retryable = IsRetryableError
+	}
+	return max(retries, 0), policy, true
+}
+
+// diversifyRetry returns options for retry number attempt (from 1) of a response that failed
+// schema or response validation, so the retry does not repeat it: a seed set with
+// WithOpenAIParams or WithGeminiParams is offset by attempt, and the temperature is moved by
+// attempt times the WithRetryDiversity delta, from the provider default if none was set.
+// The temperature is lowered so it stays within every provider's range (Claude accepts at
+// most the default of 1.0), and raised instead when lowering would reach 0, which means unset.
+func diversifyRetry(options []llmtypes.CallOption, attempt int) []llmtypes.CallOption {
+	opts := &llmtypes.CallOptions{}
+	for _, opt := range options {
+		opt(opts)
+	}
+	options = append([]llmtypes.CallOption{}, options...)
+	if opts.OpenAIParams != nil && opts.OpenAIParams.Seed != nil {
+		params := *opts.OpenAIParams
+		seed := *params.Seed + int64(attempt)
+		params.Seed = &seed
+		options = append(options, llmtypes.WithOpenAIParams(params))
+	}
+	if opts.GeminiParams != nil && opts.GeminiParams.Seed != nil {
+		params := *opts.GeminiParams
+		seed := *params.Seed + int32(attempt)
+		params.Seed = &seed
+		options = append(options, llmtypes.WithGeminiParams(params))
+	}
+	if opts.RetryDiversity > 0 {
+		temperature := opts.Temperature
+		if temperature <= 0 {
+			// Adapters omit an unset temperature, so the first attempt ran at the default
+			temperature = utils.DefaultTemperature
+		}
+		shift := opts.RetryDiversity * float64(attempt)
+		if temperature-shift > 0 {
+			temperature -= shift
+		} else {
+			temperature += shift
+		}
+		options = append(options, llmtypes.WithTemperature(temperature))
+	}
+	return options
+}
+
+// retryDelay returns the wait before retry number attempt (from 0): exponential backoff
+// with jitter, at least the provider's Retry-After, capped at the policy's MaxDelay
+func retryDelay(policy llmtypes.RetryPolicy, attempt int, err error) time.Duration {
+	delay := policy.BaseDelay << min(attempt, 20)
+	delay = delay/2 + rand.N(delay/2+1)
+	if after := retryAfter(err); after > delay {
+		delay = after
+	}
+	return min(delay, policy.MaxDelay)
+}
+
+// retryAfter returns the wait the provider asked for in the Retry-After headers of err's
+// response, 0 if none
+func retryAfter(err error) time.Duration {
+	var response *http.Response
+	var openaiErr *openaisdk.Error
+	var anthropicErr *anthropic.Error
+	switch {
+	case errors.As(err, &openaiErr):
+		response = openaiErr.Response
+	case errors.As(err, &anthropicErr):
+		response = anthropicErr.Response
+	}
+	if response == nil {
+		return 0
+	}
+	if ms, err := strconv.ParseFloat(response.Header.Get("retry-after-ms"), 64); err == nil && ms > 0 {
+		return time.Duration(ms * float64(time.Millisecond))
+	}
+	if seconds, err := strconv.ParseFloat(response.Header.Get("retry-after"), 64); err == nil && seconds > 0 {
+		return time.Duration(seconds * float64(time.Second))
+	}
+	if at, err := http.ParseTime(response.Header.Get("retry-after")); err == nil {
+		return time.Until(at)
+	}
+	return 0
+}
+
+// sleepContext waits for d or until ctx is done, returning ctx's error in that case
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
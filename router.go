@@ -0,0 +1,162 @@
+package llmproviders
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+// bedrockModelPattern matches Bedrock model IDs, e.g. "anthropic.claude-3-haiku-20240307-v1:0"
+// and inference profiles such as "us.anthropic.claude-sonnet-4-20250514-v1:0"
+var bedrockModelPattern = regexp.MustCompile(`^(?:[a-z]+\.)?(?:anthropic|amazon|meta|cohere|mistral|ai21|deepseek|openai|qwen)\.`)
+
+// routeMatchers report whether a provider serves a model ID, for routing IDs given without
+// an alias or provider prefix
+var routeMatchers = map[Provider]func(modelID string) bool{
+	ProviderOpenRouter: func(id string) bool { return strings.Contains(id, "/") },
+	ProviderBedrock:    bedrockModelPattern.MatchString,
+	ProviderAnthropic:  func(id string) bool { return strings.HasPrefix(id, "claude-") },
+	ProviderVertex: func(id string) bool {
+		return hasAnyPrefix(id, "gemini", "gemma", "claude-", "text-embedding-00", "text-multilingual-embedding")
+	},
+	ProviderOpenAI: func(id string) bool {
+		return hasAnyPrefix(id, "gpt-", "chatgpt-", "o1", "o3", "o4", "ft:", "text-embedding-3", "text-embedding-ada", "davinci", "babbage")
+	},
+}
+
+// defaultRouteProviders is the routing preference when NewRouter is given no providers
+var defaultRouteProviders = []Provider{ProviderAnthropic, ProviderOpenAI, ProviderVertex, ProviderBedrock, ProviderOpenRouter}
+
+// hasAnyPrefix reports whether s starts with one of prefixes
+func hasAnyPrefix(s string, prefixes ...string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// Router is a Model that serves several providers from one object: each call goes to the
+// provider of its WithModel value (or Config.ModelID), whose client is initialized on first
+// use and reused afterwards.
+type Router struct {
+	config    Config
+	providers []Provider
+
+	mu      sync.Mutex
+	clients map[string]*routerClient
+}
+
+// routerClient is the lazily initialized model of one provider and model ID
+type routerClient struct {
+	mu    sync.Mutex
+	model llmtypes.Model
+}
+
+// NewRouter creates a Router for providers, in order of preference for model IDs several of
+// them serve (e.g. "claude-" IDs on Anthropic and Vertex); without providers it routes to
+// all of them. config is the base of every client (API keys, logger, event emitter, HTTP
+// client, ...); its Provider and FallbackModels are ignored, and its ModelID, if set, is
+// the model of calls without WithModel. Config.ModelAliases are resolved first.
+func NewRouter(config Config, providers ...Provider) (*Router, error) {
+	if err := validateModelAliases(config.ModelAliases); err != nil {
+		return nil, err
+	}
+	for _, provider := range providers {
+		if _, err := ValidateProvider(string(provider)); err != nil {
+			return nil, err
+		}
+	}
+	if len(providers) == 0 {
+		providers = defaultRouteProviders
+	}
+	config.Provider = ""
+	config.FallbackModels = nil
+	return &Router{config: config, providers: providers, clients: make(map[string]*routerClient)}, nil
+}
+
+// Route returns the provider and model ID a call with WithModel(model) goes to. model may
+// be an alias of Config.ModelAliases, a model ID prefixed with its provider
+// ("openai:gpt-4.1", "bedrock:anthropic.claude-3-haiku-20240307-v1:0") or a model ID, routed
+// to the first of the router's providers serving it.
+func (r *Router) Route(model string) (Provider, string, error) {
+	if model == "" {
+		return "", "", fmt.Errorf("no model to route: set Config.ModelID or pass WithModel")
+	}
+	if alias, ok := r.config.ModelAliases[model]; ok {
+		return alias.Provider, alias.ModelID, nil
+	}
+	if prefix, modelID, ok := strings.Cut(model, ":"); ok {
+		if provider, err := ValidateProvider(prefix); err == nil {
+			return provider, modelID, nil
+		}
+	}
+	id := strings.ToLower(model)
+	for _, provider := range r.providers {
+		if routeMatchers[provider](id) {
+			return provider, model, nil
+		}
+	}
+	return "", "", fmt.Errorf("cannot route model %q to any of %v: use an alias or a provider prefix such as \"openai:%s\"", model, r.providers, model)
+}
+
+// GenerateContent routes the call to the model of WithModel, or Config.ModelID without it
+func (r *Router) GenerateContent(ctx context.Context, messages []llmtypes.MessageContent, options ...llmtypes.CallOption) (*llmtypes.ContentResponse, error) {
+	opts := &llmtypes.CallOptions{}
+	for _, opt := range options {
+		opt(opts)
+	}
+	model := opts.Model
+	if model == "" {
+		model = r.config.ModelID
+	}
+	provider, modelID, err := r.Route(model)
+	if err != nil {
+		return nil, err
+	}
+	llm, err := r.client(provider, modelID)
+	if err != nil {
+		return nil, err
+	}
+	// Pass the resolved ID so the provider's client doesn't see the alias or prefix
+	if opts.Model != "" {
+		options = append(append([]llmtypes.CallOption{}, options...), llmtypes.WithModel(modelID))
+	}
+	return llm.GenerateContent(ctx, messages, options...)
+}
+
+// GetModelID returns Config.ModelID, the model of calls without WithModel
+func (r *Router) GetModelID() string {
+	return r.config.ModelID
+}
+
+// client returns the model for provider and modelID, initializing it on first use. A failed
+// initialization is not cached, so the next call tries again.
+func (r *Router) client(provider Provider, modelID string) (llmtypes.Model, error) {
+	key := string(provider) + ":" + modelID
+	r.mu.Lock()
+	c, ok := r.clients[key]
+	if !ok {
+		c = &routerClient{}
+		r.clients[key] = c
+	}
+	r.mu.Unlock()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.model == nil {
+		config := r.config
+		config.Provider, config.ModelID = provider, modelID
+		model, err := InitializeLLM(config)
+		if err != nil {
+			return nil, fmt.Errorf("failed to initialize %s model %s: %w", provider, modelID, err)
+		}
+		c.model = model
+	}
+	return c.model, nil
+}
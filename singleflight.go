@@ -0,0 +1,282 @@
+package llmproviders
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sync"
+	"time"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+// singleFlightGroup coalesces concurrent identical calls into one upstream call
+type singleFlightGroup struct {
+	mu    sync.Mutex
+	calls map[string]*flightCall
+}
+
+// flightCall is an upstream call in progress; done is closed once resp and err are set.
+// waiters counts the callers still waiting for it, the first caller included.
+type flightCall struct {
+	done    chan struct{}
+	resp    *llmtypes.ContentResponse
+	err     error
+	waiters int
+	cancel  context.CancelFunc
+}
+
+// do runs fn for key unless a call for key is already in flight, in which case it waits for
+// that call's result. shared reports whether the result came from another caller's call.
+// fn runs on a context detached from the first caller's cancellation, so one caller leaving
+// doesn't fail the others; it is cancelled once every caller has left.
+func (g *singleFlightGroup) do(ctx context.Context, key string, fn func(ctx context.Context) (*llmtypes.ContentResponse, error)) (resp *llmtypes.ContentResponse, err error, shared bool) {
+	g.mu.Lock()
+	if g.calls == nil {
+		g.calls = make(map[string]*flightCall)
+	}
+	if call, ok := g.calls[key]; ok {
+		call.waiters++
+		g.mu.Unlock()
+		return g.wait(ctx, key, call, true)
+	}
+	callCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+	call := &flightCall{done: make(chan struct{}), waiters: 1, cancel: cancel}
+	g.calls[key] = call
+	g.mu.Unlock()
+
+	go func() {
+		defer cancel()
+		resp, err := fn(callCtx)
+		g.mu.Lock()
+		if g.calls[key] == call {
+			delete(g.calls, key)
+		}
+		call.resp, call.err = resp, err
+		g.mu.Unlock()
+		close(call.done)
+	}()
+	return g.wait(ctx, key, call, false)
+}
+
+// wait returns the result of call, or the error of ctx if it ends first. The last caller
+// to leave cancels the call, and later callers start a new one.
+func (g *singleFlightGroup) wait(ctx context.Context, key string, call *flightCall, shared bool) (*llmtypes.ContentResponse, error, bool) {
+	select {
+	case <-call.done:
+		if shared {
+			return cloneResponse(call.resp), call.err, true
+		}
+		return call.resp, call.err, false
+	case <-ctx.Done():
+		g.mu.Lock()
+		if call.waiters--; call.waiters == 0 {
+			call.cancel()
+			if g.calls[key] == call {
+				delete(g.calls, key)
+			}
+		}
+		g.mu.Unlock()
+		return nil, ctx.Err(), shared
+	}
+}
+
+// singleFlightKey hashes the provider, model, messages and options of a call. ok is false
+// when the options hold functions (hasFunctionOptions) or cannot be serialized (e.g. an
+// ExtraBody value), in which case the call is not coalesced.
+func singleFlightKey(provider Provider, modelID string, messages []llmtypes.MessageContent, opts *llmtypes.CallOptions) (string, bool) {
+	if hasFunctionOptions(opts) {
+		return "", false
+	}
+	data, err := json.Marshal(struct {
+		Provider Provider                  `json:"provider"`
+		ModelID  string                    `json:"model_id"`
+		Messages []llmtypes.MessageContent `json:"messages"`
+		Options  flightOptions             `json:"options"`
+	}{provider, modelID, messages, newFlightOptions(opts)})
+	if err != nil {
+		return "", false
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:]), true
+}
+
+// hasFunctionOptions reports whether opts holds functions: interceptors, validators, message
+// transforms, a stream event hook or a retry policy's Retryable. Two calls with such options
+// can only be told apart by the functions themselves, and each caller expects its own to
+// run, so they are never coalesced.
+func hasFunctionOptions(opts *llmtypes.CallOptions) bool {
+	return len(opts.RequestInterceptors) > 0 || len(opts.ResponseInterceptors) > 0 ||
+		len(opts.ResponseValidators) > 0 || len(opts.MessageTransforms) > 0 ||
+		opts.StreamEventHook != nil || (opts.RetryPolicy != nil && opts.RetryPolicy.Retryable != nil)
+}
+
+// cloneResponse copies resp and its choices so callers sharing a result can modify their copy
+func cloneResponse(resp *llmtypes.ContentResponse) *llmtypes.ContentResponse {
+	if resp == nil {
+		return nil
+	}
+	clone := *resp
+	clone.Choices = make([]*llmtypes.ContentChoice, len(resp.Choices))
+	for i, choice := range resp.Choices {
+		if choice == nil {
+			continue
+		}
+		c := *choice
+		c.ToolCalls = append([]llmtypes.ToolCall(nil), choice.ToolCalls...)
+		c.Blocks = append([]llmtypes.ContentBlock(nil), choice.Blocks...)
+		clone.Choices[i] = &c
+	}
+	if resp.Usage != nil {
+		usage := *resp.Usage
+		clone.Usage = &usage
+	}
+	return &clone
+}
+
+// flightOptions lists the call options that tell single-flight calls apart. Channels and
+// models cannot be serialized, so only whether they are set (or the model ID) is recorded;
+// calls with functions are not coalesced at all (hasFunctionOptions). New CallOptions fields
+// that change the response must be added here.
+type flightOptions struct {
+	Model                       string
+	Temperature                 float64
+	MaxTokens                   int
+	JSONMode                    bool
+	JSONSchema                  *llmtypes.JSONSchemaConfig
+	Tools                       []llmtypes.Tool
+	ToolChoice                  *llmtypes.ToolChoice
+	Streaming                   bool
+	Metadata                    *llmtypes.Metadata
+	ReasoningEffort             string
+	Verbosity                   string
+	ThinkingLevel               string
+	ServiceTier                 string
+	N                           int
+	AbortOnToolCall             bool
+	AssistantPrefill            string
+	DryRun                      bool
+	AutoContinue                int
+	MaxInputTokens              int
+	ToolEmulation               bool
+	StreamUsage                 bool
+	FallbackModels              []string
+	StreamTextOnly              bool
+	StreamBuffering             llmtypes.StreamBufferingMode
+	LogitBias                   map[int]float64
+	StreamStallTimeout          time.Duration
+	StreamHeartbeat             time.Duration
+	StripReasoningTags          []string
+	EnforceRequiredToolArgs     bool
+	DisableTools                bool
+	Region                      string
+	StreamFallback              bool
+	MaxRetries                  *int
+	RetryPolicy                 *flightRetryPolicy
+	RetryOnEmptyContent         int
+	AllowedTools                []string
+	CachedContent               llmtypes.CacheName
+	MaxToolCallsPerResponse     int
+	DisableToolNameSanitization bool
+	ReasoningVisibility         string
+	StructuredOutput            *llmtypes.JSONSchemaConfig
+	ResponseMimeType            string
+	ResponseModalities          []string
+	ToolResultMaxTokens         int
+	ToolResultSummarizer        string
+	SummarizeStructuredTools    []string
+	ToolResultImageMaxBytes     int
+	ImageAutoConvert            bool
+	MaxImageCount               int
+	ImageDownsampling           bool
+	SchemaValidation            bool
+	SchemaRetries               int
+	ExtraBody                   map[string]interface{}
+	ExtraHeaders                map[string]string
+	OpenAIParams                *llmtypes.OpenAIParams
+	AnthropicParams             *llmtypes.AnthropicParams
+	GeminiParams                *llmtypes.GeminiParams
+	TraceID                     string
+	ValidationRetries           int
+	ValidationFeedback          bool
+	RetryDiversity              float64
+}
+
+// flightRetryPolicy is the serializable part of a RetryPolicy
+type flightRetryPolicy struct {
+	BaseDelay time.Duration
+	MaxDelay  time.Duration
+}
+
+// newFlightOptions returns the single-flight view of opts
+func newFlightOptions(opts *llmtypes.CallOptions) flightOptions {
+	options := flightOptions{
+		Model:                       opts.Model,
+		Temperature:                 opts.Temperature,
+		MaxTokens:                   opts.MaxTokens,
+		JSONMode:                    opts.JSONMode,
+		JSONSchema:                  opts.JSONSchema,
+		Tools:                       opts.Tools,
+		ToolChoice:                  opts.ToolChoice,
+		Streaming:                   opts.StreamChan != nil,
+		Metadata:                    opts.Metadata,
+		ReasoningEffort:             opts.ReasoningEffort,
+		Verbosity:                   opts.Verbosity,
+		ThinkingLevel:               opts.ThinkingLevel,
+		ServiceTier:                 opts.ServiceTier,
+		N:                           opts.N,
+		AbortOnToolCall:             opts.AbortOnToolCall,
+		AssistantPrefill:            opts.AssistantPrefill,
+		DryRun:                      opts.DryRun,
+		AutoContinue:                opts.AutoContinue,
+		MaxInputTokens:              opts.MaxInputTokens,
+		ToolEmulation:               opts.ToolEmulation,
+		StreamUsage:                 opts.StreamUsage,
+		FallbackModels:              opts.FallbackModels,
+		StreamTextOnly:              opts.StreamTextOnly,
+		StreamBuffering:             opts.StreamBuffering,
+		LogitBias:                   opts.LogitBias,
+		StreamStallTimeout:          opts.StreamStallTimeout,
+		StreamHeartbeat:             opts.StreamHeartbeat,
+		StripReasoningTags:          opts.StripReasoningTags,
+		EnforceRequiredToolArgs:     opts.EnforceRequiredToolArgs,
+		DisableTools:                opts.DisableTools,
+		Region:                      opts.Region,
+		StreamFallback:              opts.StreamFallback,
+		MaxRetries:                  opts.MaxRetries,
+		RetryOnEmptyContent:         opts.RetryOnEmptyContent,
+		AllowedTools:                opts.AllowedTools,
+		CachedContent:               opts.CachedContent,
+		MaxToolCallsPerResponse:     opts.MaxToolCallsPerResponse,
+		DisableToolNameSanitization: opts.DisableToolNameSanitization,
+		ReasoningVisibility:         opts.ReasoningVisibility,
+		StructuredOutput:            opts.StructuredOutput,
+		ResponseMimeType:            opts.ResponseMimeType,
+		ResponseModalities:          opts.ResponseModalities,
+		ToolResultMaxTokens:         opts.ToolResultMaxTokens,
+		SummarizeStructuredTools:    opts.SummarizeStructuredTools,
+		ToolResultImageMaxBytes:     opts.ToolResultImageMaxBytes,
+		ImageAutoConvert:            opts.ImageAutoConvert,
+		MaxImageCount:               opts.MaxImageCount,
+		ImageDownsampling:           opts.ImageDownsampling,
+		SchemaValidation:            opts.SchemaValidation,
+		SchemaRetries:               opts.SchemaRetries,
+		ExtraBody:                   opts.ExtraBody,
+		ExtraHeaders:                opts.ExtraHeaders,
+		OpenAIParams:                opts.OpenAIParams,
+		AnthropicParams:             opts.AnthropicParams,
+		GeminiParams:                opts.GeminiParams,
+		TraceID:                     opts.TraceID,
+		ValidationRetries:           opts.ValidationRetries,
+		ValidationFeedback:          opts.ValidationFeedback,
+		RetryDiversity:              opts.RetryDiversity,
+	}
+	if opts.RetryPolicy != nil {
+		options.RetryPolicy = &flightRetryPolicy{BaseDelay: opts.RetryPolicy.BaseDelay, MaxDelay: opts.RetryPolicy.MaxDelay}
+	}
+	if opts.ToolResultSummarizer != nil {
+		options.ToolResultSummarizer = opts.ToolResultSummarizer.GetModelID()
+	}
+	return options
+}
@@ -0,0 +1,160 @@
+package llmproviders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+	"github.com/manishiitg/multi-llm-provider-go/pkg/utils"
+
+	openaisdk "github.com/openai/openai-go/v3"
+)
+
+// ErrStreamingUnsupported matches (errors.Is) the error of a streaming call the provider
+// cannot stream for the model and options, returned unless WithStreamFallback(true) is set
+var ErrStreamingUnsupported = errors.New("streaming is not supported for this model and options")
+
+// streamingUnsupportedMarkers identify provider errors rejecting a streaming request
+var streamingUnsupportedMarkers = []string{
+	"not supported", "unsupported", "does not support", "must be verified to stream",
+}
+
+// isStreamingUnsupportedError reports whether err is a provider rejecting the request
+// because it asked for streaming, e.g. OpenAI's unsupported_value error on "stream"
+func isStreamingUnsupportedError(err error) bool {
+	var openaiErr *openaisdk.Error
+	if errors.As(err, &openaiErr) && openaiErr.Param == "stream" {
+		return true
+	}
+	if status := errorStatusCode(err); status != 0 && status != http.StatusBadRequest && status != http.StatusUnprocessableEntity {
+		return false
+	}
+	message := strings.ToLower(err.Error())
+	if !strings.Contains(message, "stream") {
+		return false
+	}
+	for _, marker := range streamingUnsupportedMarkers {
+		if strings.Contains(message, marker) {
+			return true
+		}
+	}
+	return false
+}
+
+// streamFallbackPlan handles streaming calls the provider cannot stream. Models the registry
+// marks as not streaming are called without streaming from the start; otherwise the stream
+// is relayed so that, if the provider rejects streaming before sending anything, the call
+// can be repeated without it. Either way the response is then sent to the caller's channel
+// as whole chunks.
+type streamFallbackPlan struct {
+	streamChan chan<- llmtypes.StreamChunk
+	// degraded is set once the call is made without streaming
+	degraded  bool
+	forwarded atomic.Bool
+	stopRelay func()
+	stopOnce  sync.Once
+}
+
+// planStreamFallback returns a plan for a streaming call when WithStreamFallback is set or
+// the model cannot stream; otherwise nil. Without WithStreamFallback, a model that cannot
+// stream is an ErrStreamingUnsupported error.
+func planStreamFallback(opts *llmtypes.CallOptions, modelID string, capabilities ModelCapabilities) (*streamFallbackPlan, error) {
+	if opts.StreamChan == nil || (capabilities.Streaming && !opts.StreamFallback) {
+		return nil, nil
+	}
+	if !capabilities.Streaming && !opts.StreamFallback {
+		return nil, fmt.Errorf("%w: model %s does not stream (set WithStreamFallback(true) to receive the response as a single chunk)", ErrStreamingUnsupported, modelID)
+	}
+	return &streamFallbackPlan{streamChan: opts.StreamChan, degraded: !capabilities.Streaming}, nil
+}
+
+// apply makes the call stream through a relay, or not stream at all when degraded
+func (s *streamFallbackPlan) apply(ctx context.Context, options []llmtypes.CallOption) []llmtypes.CallOption {
+	if s.degraded {
+		return s.withoutStreaming(options)
+	}
+	relay, stop := utils.ObserveStream(ctx, s.streamChan, func(llmtypes.StreamChunk) {
+		s.forwarded.Store(true)
+	})
+	s.stopRelay = stop
+	return append(append([]llmtypes.CallOption{}, options...), llmtypes.WithStreamingChan(relay))
+}
+
+// withoutStreaming removes the stream channel from options
+func (s *streamFallbackPlan) withoutStreaming(options []llmtypes.CallOption) []llmtypes.CallOption {
+	return append(append([]llmtypes.CallOption{}, options...), func(o *llmtypes.CallOptions) {
+		o.StreamChan = nil
+	})
+}
+
+// stop waits for the relay to forward what the call streamed
+func (s *streamFallbackPlan) stop() {
+	s.stopOnce.Do(func() {
+		if s.stopRelay != nil {
+			s.stopRelay()
+		}
+	})
+}
+
+// retry reports whether err rejected streaming before anything reached the caller, in which
+// case the call is repeated without streaming using the returned options
+func (s *streamFallbackPlan) retry(err error, options []llmtypes.CallOption) ([]llmtypes.CallOption, bool) {
+	if s.degraded || !isStreamingUnsupportedError(err) {
+		return nil, false
+	}
+	s.stop()
+	if s.forwarded.Load() {
+		return nil, false
+	}
+	s.degraded = true
+	return s.withoutStreaming(options), true
+}
+
+// stream sends the response of a call made without streaming to the caller's channel
+func (s *streamFallbackPlan) stream(ctx context.Context, resp *llmtypes.ContentResponse) {
+	if s.degraded && resp != nil && !resp.DryRun {
+		streamResponse(ctx, s.streamChan, resp)
+	}
+}
+
+// finish waits for the relay and closes the caller's channel
+func (s *streamFallbackPlan) finish() {
+	s.stop()
+	close(s.streamChan)
+}
+
+// streamingUnsupportedError marks err, a provider rejecting streaming, as ErrStreamingUnsupported
+func streamingUnsupportedError(err error) error {
+	if !isStreamingUnsupportedError(err) || errors.Is(err, ErrStreamingUnsupported) {
+		return err
+	}
+	return fmt.Errorf("%w (set WithStreamFallback(true) to retry without streaming): %w", ErrStreamingUnsupported, err)
+}
+
+// streamResponse sends resp to streamChan as whole chunks: content, tool calls and a finish
+// chunk for every choice
+func streamResponse(ctx context.Context, streamChan chan<- llmtypes.StreamChunk, resp *llmtypes.ContentResponse) {
+	send := func(chunk llmtypes.StreamChunk) {
+		select {
+		case streamChan <- chunk:
+		case <-ctx.Done():
+		}
+	}
+	for i, choice := range resp.Choices {
+		if choice == nil {
+			continue
+		}
+		if choice.Content != "" {
+			send(llmtypes.StreamChunk{Type: llmtypes.StreamChunkTypeContent, Content: choice.Content, ChoiceIndex: i})
+		}
+		for j := range choice.ToolCalls {
+			send(llmtypes.StreamChunk{Type: llmtypes.StreamChunkTypeToolCall, ToolCall: &choice.ToolCalls[j], ChoiceIndex: i})
+		}
+		send(llmtypes.StreamChunk{Type: llmtypes.StreamChunkTypeFinish, StopReason: choice.StopReason, Usage: resp.Usage, ChoiceIndex: i})
+	}
+}
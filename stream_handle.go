@@ -0,0 +1,59 @@
+package llmproviders
+
+import (
+	"context"
+	"errors"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+// errStreamStopped is the cancellation cause of calls ended by StreamHandle.Stop
+var errStreamStopped = errors.New("stream stopped")
+
+// StreamHandle controls a streaming call started with StreamContent
+type StreamHandle struct {
+	cancel context.CancelCauseFunc
+	done   chan struct{}
+	resp   *llmtypes.ContentResponse
+	err    error
+}
+
+// StreamContent starts a streaming call in the background, sending chunks to streamChan as
+// GenerateContent does with WithStreamingChan; streamChan is closed when the call ends. The
+// returned handle stops the call (e.g. for a UI "stop" button) without the caller threading
+// a cancellable context, and waits for its result.
+func (p *ProviderAwareLLM) StreamContent(ctx context.Context, messages []llmtypes.MessageContent, streamChan chan<- llmtypes.StreamChunk, options ...llmtypes.CallOption) *StreamHandle {
+	ctx, cancel := context.WithCancelCause(ctx)
+	h := &StreamHandle{cancel: cancel, done: make(chan struct{})}
+	options = append(append([]llmtypes.CallOption{}, options...), llmtypes.WithStreamingChan(streamChan))
+	go func() {
+		defer close(h.done)
+		defer cancel(nil)
+		h.resp, h.err = p.GenerateContent(ctx, messages, options...)
+		// A call ended by Stop is not a failure: its response holds the partial content
+		if h.err != nil && h.resp != nil && errors.Is(context.Cause(ctx), errStreamStopped) {
+			h.err = nil
+		}
+	}()
+	return h
+}
+
+// Stop halts the call, waits for the stream channel to be closed and returns the response:
+// the content and tool calls streamed so far, or the full response if the call had already
+// completed. Stop may be called any number of times, from any goroutine.
+func (h *StreamHandle) Stop() (*llmtypes.ContentResponse, error) {
+	// Only the first cancellation sets the cause, so repeated calls are harmless
+	h.cancel(errStreamStopped)
+	return h.Wait()
+}
+
+// Wait waits for the call to end and returns its response and error
+func (h *StreamHandle) Wait() (*llmtypes.ContentResponse, error) {
+	<-h.done
+	return h.resp, h.err
+}
+
+// Done is closed when the call has ended and the stream channel is closed
+func (h *StreamHandle) Done() <-chan struct{} {
+	return h.done
+}
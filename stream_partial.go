@@ -0,0 +1,44 @@
+package llmproviders
+
+import (
+	"context"
+	"sync"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+	"github.com/manishiitg/multi-llm-provider-go/pkg/utils"
+)
+
+// partialStream records the chunks of a streaming call on their way to the caller, so that
+// a call cancelled mid-stream can return what was generated up to then
+type partialStream struct {
+	streamChan chan<- llmtypes.StreamChunk
+	aggregator *llmtypes.StreamAggregator
+	stopRelay  func()
+	stopOnce   sync.Once
+}
+
+// newPartialStream relays streamChan through the returned channel, recording every chunk
+func newPartialStream(ctx context.Context, streamChan chan<- llmtypes.StreamChunk) (*partialStream, chan<- llmtypes.StreamChunk) {
+	s := &partialStream{streamChan: streamChan, aggregator: llmtypes.NewStreamAggregator()}
+	relay, stop := utils.ObserveStream(ctx, streamChan, s.aggregator.Add)
+	s.stopRelay = stop
+	return s, relay
+}
+
+// stop waits for the relay to record what the call streamed
+func (s *partialStream) stop() {
+	s.stopOnce.Do(s.stopRelay)
+}
+
+// response returns the response assembled from the chunks streamed so far: the content and
+// the tool calls received, without a stop reason
+func (s *partialStream) response() *llmtypes.ContentResponse {
+	s.stop()
+	return s.aggregator.Response()
+}
+
+// finish waits for the relay and closes the caller's channel
+func (s *partialStream) finish() {
+	s.stop()
+	close(s.streamChan)
+}
@@ -0,0 +1,78 @@
+package llmproviders
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+	"github.com/manishiitg/multi-llm-provider-go/pkg/utils"
+)
+
+// ErrStreamStalled is matched (errors.Is) by the error of a streaming call cancelled because no
+// chunk arrived within WithStreamStallTimeout; use errors.As with *StreamStalledError to get
+// what was streamed until then
+var ErrStreamStalled = errors.New("stream stalled")
+
+// StreamStalledError is returned when a streaming call is cancelled by WithStreamStallTimeout
+type StreamStalledError struct {
+	// Timeout is the stall timeout that was exceeded
+	Timeout time.Duration
+	// Partial holds what was streamed before the stall
+	Partial *llmtypes.ContentResponse
+	// Err is the error the provider returned on cancellation
+	Err error
+}
+
+// Error names the stall timeout
+func (e *StreamStalledError) Error() string {
+	return fmt.Sprintf("%v: no chunk received for %s", ErrStreamStalled, e.Timeout)
+}
+
+// Is reports whether target is ErrStreamStalled
+func (e *StreamStalledError) Is(target error) bool {
+	return target == ErrStreamStalled
+}
+
+// Unwrap returns the provider's error
+func (e *StreamStalledError) Unwrap() error {
+	return e.Err
+}
+
+// streamWatch cancels a streaming call that stalls and sends heartbeats while it is quiet
+type streamWatch struct {
+	timeout time.Duration
+	stalled atomic.Bool
+	cancel  context.CancelFunc
+	finish  func()
+}
+
+// newStreamWatch watches the chunks streamed to streamChan with the stall timeout and
+// heartbeat interval of opts. The call must use the returned context and stream into the
+// returned channel, and call done when it returns.
+func newStreamWatch(ctx context.Context, streamChan chan<- llmtypes.StreamChunk, opts *llmtypes.CallOptions) (*streamWatch, context.Context, chan<- llmtypes.StreamChunk) {
+	callCtx, cancel := context.WithCancel(ctx)
+	w := &streamWatch{timeout: opts.StreamStallTimeout, cancel: cancel}
+	watchedChan, finish := utils.WatchStream(ctx, streamChan, opts.StreamStallTimeout, opts.StreamHeartbeat, func() {
+		w.stalled.Store(true)
+		cancel()
+	})
+	w.finish = finish
+	return w, callCtx, watchedChan
+}
+
+// done stops watching and closes the caller's channel
+func (w *streamWatch) done() {
+	w.finish()
+	w.cancel()
+}
+
+// stallError returns err as a StreamStalledError if the call was cancelled for stalling
+func (w *streamWatch) stallError(err error) error {
+	if w == nil || err == nil || !w.stalled.Load() {
+		return err
+	}
+	return &StreamStalledError{Timeout: w.timeout, Err: err}
+}
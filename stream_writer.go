@@ -0,0 +1,48 @@
+package llmproviders
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+	"github.com/manishiitg/multi-llm-provider-go/pkg/utils"
+)
+
+// StreamToWriter streams the response to messages and writes its text to w as it arrives,
+// e.g. to os.Stdout in a CLI. w is flushed after every write when it has a Flush method
+// (bufio.Writer, http.ResponseWriter). Only the content of the first choice is written;
+// reasoning, tool calls and usage are in the returned response. A failed write cancels the
+// request and its error is returned.
+func (p *ProviderAwareLLM) StreamToWriter(ctx context.Context, w io.Writer, messages []llmtypes.MessageContent, options ...llmtypes.CallOption) (*llmtypes.ContentResponse, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var writeErr error
+	resp, err := utils.GenerateStreaming(ctx, p, messages, options, func(chunk llmtypes.StreamChunk) {
+		if writeErr != nil || chunk.Type != llmtypes.StreamChunkTypeContent || chunk.ChoiceIndex != 0 || chunk.Content == "" {
+			return
+		}
+		if _, writeErr = io.WriteString(w, chunk.Content); writeErr == nil {
+			writeErr = flushWriter(w)
+		}
+		if writeErr != nil {
+			cancel()
+		}
+	})
+	if writeErr != nil {
+		return resp, fmt.Errorf("failed to write stream: %w", writeErr)
+	}
+	return resp, err
+}
+
+// flushWriter flushes w if it buffers its writes
+func flushWriter(w io.Writer) error {
+	switch f := w.(type) {
+	case interface{ Flush() error }:
+		return f.Flush()
+	case interface{ Flush() }:
+		f.Flush()
+	}
+	return nil
+}
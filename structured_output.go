@@ -0,0 +1,217 @@
+package llmproviders
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+	"github.com/manishiitg/multi-llm-provider-go/pkg/jsonschema"
+	"github.com/manishiitg/multi-llm-provider-go/pkg/utils"
+)
+
+// StructuredOutputStrategy is the mechanism used to obtain schema-conforming JSON
+type StructuredOutputStrategy string
+
+const (
+	StructuredOutputJSONSchema StructuredOutputStrategy = "json_schema" // Native JSON schema response format
+	StructuredOutputTool       StructuredOutputStrategy = "tool"        // Forced call to a tool whose parameters are the schema
+	StructuredOutputJSONMode   StructuredOutputStrategy = "json_mode"   // JSON mode with the schema in the system prompt
+)
+
+// defaultStructuredOutputName is used when WithStructuredOutput is given no name
+const defaultStructuredOutputName = "structured_output"
+
+// ErrSchemaValidation is matched (errors.Is) by structured output that does not conform to
+// its schema; use errors.As with *SchemaValidationError to get the violations
+var ErrSchemaValidation = errors.New("structured output does not match schema")
+
+// SchemaValidationError reports the schema violations of a structured output response
+type SchemaValidationError struct {
+	// Choice is the index of the offending choice
+	Choice int
+	// Content is the JSON returned by the model
+	Content string
+	// Violations lists every way Content fails the schema
+	Violations []jsonschema.Violation
+}
+
+// Error lists the violations
+func (e *SchemaValidationError) Error() string {
+	messages := make([]string, len(e.Violations))
+	for i, violation := range e.Violations {
+		messages[i] = violation.String()
+	}
+	return fmt.Sprintf("%v (choice %d): %s", ErrSchemaValidation, e.Choice, strings.Join(messages, "; "))
+}
+
+// Is reports whether target is ErrSchemaValidation
+func (e *SchemaValidationError) Is(target error) bool {
+	return target == ErrSchemaValidation
+}
+
+// feedback is the message asking the model to fix its response
+func (e *SchemaValidationError) feedback() string {
+	var b strings.Builder
+	b.WriteString("Your previous response did not match the required JSON schema:\n")
+	for _, violation := range e.Violations {
+		b.WriteString("- " + violation.String() + "\n")
+	}
+	b.WriteString("Respond again with a corrected JSON document that conforms to the schema.")
+	return b.String()
+}
+
+// SelectStructuredOutputStrategy returns the most reliable structured output strategy for
+// a model: native JSON schema, then a forced tool call, then JSON mode
+func SelectStructuredOutputStrategy(capabilities ModelCapabilities) StructuredOutputStrategy {
+	switch {
+	case capabilities.JSONSchema:
+		return StructuredOutputJSONSchema
+	case capabilities.Tools:
+		return StructuredOutputTool
+	default:
+		return StructuredOutputJSONMode
+	}
+}
+
+// structuredOutputPlan applies a structured output strategy to a call and extracts the result
+type structuredOutputPlan struct {
+	strategy StructuredOutputStrategy
+	name     string
+	config   *llmtypes.JSONSchemaConfig
+	validate bool
+}
+
+// planStructuredOutput picks the strategy for config based on the model's capabilities.
+// With validate, finalize also checks the output against the schema.
+func planStructuredOutput(config *llmtypes.JSONSchemaConfig, capabilities ModelCapabilities, validate bool) *structuredOutputPlan {
+	name := config.Name
+	if name == "" {
+		name = defaultStructuredOutputName
+	}
+	return &structuredOutputPlan{
+		strategy: SelectStructuredOutputStrategy(capabilities),
+		name:     name,
+		config:   config,
+		validate: validate,
+	}
+}
+
+// apply returns the messages and options implementing the strategy
+func (s *structuredOutputPlan) apply(messages []llmtypes.MessageContent, options []llmtypes.CallOption) ([]llmtypes.MessageContent, []llmtypes.CallOption) {
+	options = append([]llmtypes.CallOption{}, options...)
+	switch s.strategy {
+	case StructuredOutputJSONSchema:
+		options = append(options, llmtypes.WithJSONSchema(s.config.Schema, s.name, s.config.Description, s.config.Strict))
+	case StructuredOutputTool:
+		opts := &llmtypes.CallOptions{}
+		for _, opt := range options {
+			opt(opts)
+		}
+		tool := llmtypes.Tool{
+			Type: "function",
+			Function: &llmtypes.FunctionDefinition{
+				Name:        s.name,
+				Description: "Respond with the requested structured output",
+				Parameters:  llmtypes.NewParameters(s.config.Schema),
+			},
+		}
+		options = append(options,
+			llmtypes.WithTools(append(append([]llmtypes.Tool{}, opts.Tools...), tool)),
+			llmtypes.WithToolChoice(&llmtypes.ToolChoice{Type: "function", Function: &llmtypes.FunctionName{Name: s.name}}),
+		)
+	default:
+		schema, err := json.MarshalIndent(s.config.Schema, "", "  ")
+		if err != nil {
+			schema = []byte("{}")
+		}
+		instruction := fmt.Sprintf("Respond only with a JSON document that conforms to this JSON schema, with no other text:\n%s", schema)
+		messages = withSystemInstruction(messages, instruction)
+		options = append(options, llmtypes.WithJSONMode())
+	}
+	return messages, options
+}
+
+// finalize moves the structured output of every choice into Content and checks it is valid
+// JSON, or conforms to the schema when validating (*SchemaValidationError)
+func (s *structuredOutputPlan) finalize(resp *llmtypes.ContentResponse) error {
+	for i, choice := range resp.Choices {
+		if choice == nil {
+			continue
+		}
+		if s.strategy == StructuredOutputTool {
+			remaining := choice.ToolCalls[:0]
+			for _, tc := range choice.ToolCalls {
+				if tc.FunctionCall != nil && tc.FunctionCall.Name == s.name {
+					choice.Content = tc.FunctionCall.Arguments
+					continue
+				}
+				remaining = append(remaining, tc)
+			}
+			choice.ToolCalls = remaining
+		}
+		content := extractJSONDocument(choice.Content)
+		if s.validate {
+			if violations := jsonschema.ValidateJSON(s.config.Schema, []byte(content)); len(violations) > 0 {
+				return &SchemaValidationError{Choice: i, Content: content, Violations: violations}
+			}
+		} else if !json.Valid([]byte(content)) {
+			return fmt.Errorf("structured output (%s) for choice %d is not valid JSON: %q", s.strategy, i, truncateForError(choice.Content))
+		}
+		choice.Content = content
+		// The structured output replaces the blocks the model produced
+		choice.Blocks = nil
+	}
+	return nil
+}
+
+// finalizeStructuredOutput finalizes resp. While schema validation fails and retries
+// remain, the call is repeated with the invalid output and its violations fed back.
+func (p *ProviderAwareLLM) finalizeStructuredOutput(ctx context.Context, plan *structuredOutputPlan, messages []llmtypes.MessageContent, options []llmtypes.CallOption, retries int, resp *llmtypes.ContentResponse) (*llmtypes.ContentResponse, error) {
+	for attempt := 1; ; attempt++ {
+		err := plan.finalize(resp)
+		var validationErr *SchemaValidationError
+		if err == nil || attempt > retries || !errors.As(err, &validationErr) {
+			return resp, err
+		}
+		p.logger.Infof("🔁 Structured output failed schema validation, retrying (%d/%d): %v", attempt, retries, err)
+
+		messages = append(append([]llmtypes.MessageContent{}, messages...),
+			llmtypes.TextParts(llmtypes.ChatMessageTypeAI, validationErr.Content),
+			llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, validationErr.feedback()),
+		)
+		resp, err = p.generateAttempt(ctx, messages, diversifyRetry(options, attempt))
+		if err != nil {
+			return nil, fmt.Errorf("structured output retry %d: %w", attempt, err)
+		}
+		if resp == nil {
+			return nil, fmt.Errorf("structured output retry %d: response is nil", attempt)
+		}
+	}
+}
+
+// withSystemInstruction adds instruction to the first system message, or prepends a
+// system message if there is none. messages is not modified.
+func withSystemInstruction(messages []llmtypes.MessageContent, instruction string) []llmtypes.MessageContent {
+	result := append([]llmtypes.MessageContent{}, messages...)
+	for i, msg := range result {
+		if msg.Role == llmtypes.ChatMessageTypeSystem {
+			parts := append(append([]llmtypes.ContentPart{}, msg.Parts...), llmtypes.TextContent{Text: instruction})
+			msg.Parts = parts
+			result[i] = msg
+			return result
+		}
+	}
+	return append([]llmtypes.MessageContent{llmtypes.TextParts(llmtypes.ChatMessageTypeSystem, instruction)}, result...)
+}
+
+// truncateForError shortens s for inclusion in an error message
+func truncateForError(s string) string {
+	const maxLen = 200
+	if len(s) <= maxLen {
+		return s
+	}
+	return utils.TruncateUTF8(s, maxLen) + "..."
+}
@@ -0,0 +1,29 @@
+package llmproviders
+
+import (
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+)
+
+// withDefaultSystemPrompt returns messages with Config.DefaultSystemPrompt applied: as a
+// system message before the others when they have none, and, with MergeSystemPrompt, as the
+// first part of the call's system message. The default always comes first so providers that
+// cache prompt prefixes cache it across calls. The input is not modified.
+func (p *ProviderAwareLLM) withDefaultSystemPrompt(messages []llmtypes.MessageContent) []llmtypes.MessageContent {
+	if p.defaultSystemPrompt == "" {
+		return messages
+	}
+	prompt := llmtypes.TextContent{Text: p.defaultSystemPrompt}
+	for i, msg := range messages {
+		if msg.Role != llmtypes.ChatMessageTypeSystem {
+			continue
+		}
+		if !p.mergeSystemPrompt {
+			return messages
+		}
+		merged := append([]llmtypes.MessageContent{}, messages...)
+		merged[i].Parts = append([]llmtypes.ContentPart{prompt}, msg.Parts...)
+		return merged
+	}
+	system := llmtypes.MessageContent{Role: llmtypes.ChatMessageTypeSystem, Parts: []llmtypes.ContentPart{prompt}}
+	return append([]llmtypes.MessageContent{system}, messages...)
+}
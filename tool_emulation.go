@@ -0,0 +1,208 @@
+package llmproviders
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+	"github.com/manishiitg/multi-llm-provider-go/pkg/utils"
+)
+
+// Tags delimiting an emulated tool call or tool result in message text
+const (
+	toolCallOpenTag    = "<tool_call>"
+	toolCallCloseTag   = "</tool_call>"
+	toolResultOpenTag  = "<tool_result"
+	toolResultCloseTag = "</tool_result>"
+)
+
+// emulatedToolCallPattern matches a tagged tool call block in model output
+var emulatedToolCallPattern = regexp.MustCompile(`(?s)` + regexp.QuoteMeta(toolCallOpenTag) + `\s*(.*?)\s*` + regexp.QuoteMeta(toolCallCloseTag))
+
+// emulatedToolCall is the JSON a model emits inside a tool call block
+type emulatedToolCall struct {
+	Name      string          `json:"name"`
+	Arguments json.RawMessage `json:"arguments"`
+}
+
+// toolEmulationPlan emulates tool calling for a model without native support: the tools are
+// described in the system prompt, the model answers with tagged JSON blocks, and finalize
+// parses them back into ToolCalls
+type toolEmulationPlan struct {
+	tools      []llmtypes.Tool
+	choice     *llmtypes.ToolChoice
+	streamChan chan<- llmtypes.StreamChunk
+}
+
+// planToolEmulation returns a plan when opts asks for tool emulation, has tools and the model
+// lacks native tool calling; otherwise nil
+func planToolEmulation(opts *llmtypes.CallOptions, capabilities ModelCapabilities) *toolEmulationPlan {
+	if !opts.ToolEmulation || len(opts.Tools) == 0 || capabilities.Tools {
+		return nil
+	}
+	return &toolEmulationPlan{tools: opts.Tools, choice: opts.ToolChoice, streamChan: opts.StreamChan}
+}
+
+// apply describes the tools in the system prompt, rewrites tool calls and results in the
+// history as tagged text, and removes the tools from the request. Streaming is handled by
+// stream once the response is parsed, so the tagged blocks never reach the caller.
+func (e *toolEmulationPlan) apply(messages []llmtypes.MessageContent, options []llmtypes.CallOption) ([]llmtypes.MessageContent, []llmtypes.CallOption) {
+	options = append(append([]llmtypes.CallOption{}, options...), func(o *llmtypes.CallOptions) {
+		o.Tools = nil
+		o.ToolChoice = nil
+		o.StreamChan = nil
+	})
+	messages = emulateToolHistory(messages)
+	if !toolChoiceNone(e.choice) {
+		messages = withSystemInstruction(messages, e.instruction())
+	}
+	return messages, options
+}
+
+// instruction describes the tools and the tool call format
+func (e *toolEmulationPlan) instruction() string {
+	var sb strings.Builder
+	sb.WriteString("You can call the following tools. To call a tool, respond with one block per call in exactly this format, with the arguments as a JSON object matching the tool's parameters:\n")
+	sb.WriteString(toolCallOpenTag + `{"name": "<tool name>", "arguments": {...}}` + toolCallCloseTag + "\n")
+	sb.WriteString("After calling tools, stop and wait: the results are sent back in " + toolResultOpenTag + "> blocks. If no tool is needed, answer normally without any " + toolCallOpenTag + " block.\n\nTools:\n")
+	for _, tool := range e.tools {
+		if tool.Function == nil {
+			continue
+		}
+		definition, err := json.Marshal(tool.Function)
+		if err != nil {
+			continue
+		}
+		sb.Write(definition)
+		sb.WriteString("\n")
+	}
+	if name := forcedToolName(e.choice); name != "" {
+		sb.WriteString(fmt.Sprintf("\nYou must call the %s tool.", name))
+	} else if toolChoiceRequired(e.choice) {
+		sb.WriteString("\nYou must call at least one tool.")
+	}
+	return sb.String()
+}
+
+// finalize moves the tagged tool call blocks of every choice into ToolCalls and strips them
+// from Content. Blocks naming an unknown tool or holding invalid JSON are left in Content.
+func (e *toolEmulationPlan) finalize(resp *llmtypes.ContentResponse) {
+	known := make(map[string]bool, len(e.tools))
+	for _, tool := range e.tools {
+		if tool.Function != nil {
+			known[tool.Function.Name] = true
+		}
+	}
+
+	for _, choice := range resp.Choices {
+		if choice == nil || !strings.Contains(choice.Content, toolCallOpenTag) {
+			continue
+		}
+		var toolCalls []llmtypes.ToolCall
+		var ids utils.ToolCallIDs
+		content := emulatedToolCallPattern.ReplaceAllStringFunc(choice.Content, func(block string) string {
+			var call emulatedToolCall
+			body := emulatedToolCallPattern.FindStringSubmatch(block)[1]
+			if err := json.Unmarshal([]byte(extractJSONDocument(body)), &call); err != nil || !known[call.Name] {
+				return block
+			}
+			arguments := "{}"
+			if len(call.Arguments) > 0 && string(call.Arguments) != "null" {
+				arguments = string(call.Arguments)
+				// Some models send the arguments as a JSON-encoded string
+				var encoded string
+				if json.Unmarshal(call.Arguments, &encoded) == nil {
+					arguments = encoded
+				}
+			}
+			toolCall := llmtypes.ToolCall{
+				Type:         "function",
+				FunctionCall: &llmtypes.FunctionCall{Name: call.Name, Arguments: arguments},
+			}
+			ids.Assign(&toolCall)
+			toolCalls = append(toolCalls, toolCall)
+			return ""
+		})
+		if len(toolCalls) == 0 {
+			continue
+		}
+		choice.Content = strings.TrimSpace(content)
+		choice.ToolCalls = append(choice.ToolCalls, toolCalls...)
+		choice.StopReason = "tool_calls"
+		// The text blocks held the tool calls just parsed out of them
+		choice.Blocks = nil
+	}
+}
+
+// stream sends the parsed response to the caller's stream channel: content, tool calls and
+// a finish chunk for every choice
+func (e *toolEmulationPlan) stream(ctx context.Context, resp *llmtypes.ContentResponse) {
+	if e.streamChan == nil || resp == nil {
+		return
+	}
+	streamResponse(ctx, e.streamChan, resp)
+}
+
+// emulateToolHistory rewrites tool calls in assistant messages and tool results as tagged
+// text, since the model cannot accept them natively. messages is not modified.
+func emulateToolHistory(messages []llmtypes.MessageContent) []llmtypes.MessageContent {
+	result := make([]llmtypes.MessageContent, 0, len(messages))
+	for _, msg := range messages {
+		rewritten := false
+		parts := make([]llmtypes.ContentPart, 0, len(msg.Parts))
+		for _, part := range msg.Parts {
+			switch p := part.(type) {
+			case llmtypes.ToolCall:
+				rewritten = true
+				if p.FunctionCall == nil {
+					continue
+				}
+				arguments := json.RawMessage(p.FunctionCall.Arguments)
+				if !json.Valid(arguments) {
+					arguments = json.RawMessage("{}")
+				}
+				block, _ := json.Marshal(emulatedToolCall{Name: p.FunctionCall.Name, Arguments: arguments})
+				parts = append(parts, llmtypes.TextContent{Text: toolCallOpenTag + string(block) + toolCallCloseTag})
+			case llmtypes.ToolCallResponse:
+				rewritten = true
+				parts = append(parts, llmtypes.TextContent{Text: fmt.Sprintf("%s name=%q>%s%s", toolResultOpenTag, p.Name, p.Text(), toolResultCloseTag)})
+				for _, img := range p.Images() {
+					parts = append(parts, img)
+				}
+			default:
+				parts = append(parts, part)
+			}
+		}
+		if !rewritten {
+			result = append(result, msg)
+			continue
+		}
+		msg.Parts = parts
+		if msg.Role == llmtypes.ChatMessageTypeTool {
+			msg.Role = llmtypes.ChatMessageTypeHuman
+		}
+		result = append(result, msg)
+	}
+	return result
+}
+
+// forcedToolName returns the tool choice forces, if any
+func forcedToolName(choice *llmtypes.ToolChoice) string {
+	if choice != nil && choice.Function != nil {
+		return choice.Function.Name
+	}
+	return ""
+}
+
+// toolChoiceRequired reports whether choice requires some tool call
+func toolChoiceRequired(choice *llmtypes.ToolChoice) bool {
+	return choice != nil && (choice.Any || choice.Type == "required" || choice.Type == "any")
+}
+
+// toolChoiceNone reports whether choice disables tool calls
+func toolChoiceNone(choice *llmtypes.ToolChoice) bool {
+	return choice != nil && (choice.None || choice.Type == "none")
+}
@@ -0,0 +1,148 @@
+package llmproviders
+
+import (
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+	"github.com/manishiitg/multi-llm-provider-go/pkg/utils"
+)
+
+// toolNamePlan renames tools whose names break provider naming rules (see
+// WithToolNameSanitization) and maps tool calls in the response back to the original names
+type toolNamePlan struct {
+	toProvider map[string]string
+	toOriginal map[string]string
+}
+
+// planToolNames returns a plan when sanitization is enabled and some tool in opts has an
+// invalid name; otherwise nil
+func planToolNames(opts *llmtypes.CallOptions) *toolNamePlan {
+	if opts.DisableToolNameSanitization || len(opts.Tools) == 0 {
+		return nil
+	}
+	used := make(map[string]bool, len(opts.Tools))
+	for _, tool := range opts.Tools {
+		if tool.Function != nil && utils.IsValidToolName(tool.Function.Name) {
+			used[tool.Function.Name] = true
+		}
+	}
+
+	var plan *toolNamePlan
+	for _, tool := range opts.Tools {
+		if tool.Function == nil || utils.IsValidToolName(tool.Function.Name) {
+			continue
+		}
+		name := tool.Function.Name
+		if plan == nil {
+			plan = &toolNamePlan{toProvider: make(map[string]string), toOriginal: make(map[string]string)}
+		} else if _, done := plan.toProvider[name]; done {
+			continue
+		}
+		sanitized := utils.SanitizeToolName(name)
+		if used[sanitized] {
+			// e.g. "search.web" next to a tool already named "search_web"
+			sanitized = utils.ShortenToolName(sanitized, name)
+		}
+		used[sanitized] = true
+		plan.toProvider[name] = sanitized
+		plan.toOriginal[sanitized] = name
+	}
+	return plan
+}
+
+// providerName returns the name sent to the provider for the tool name
+func (t *toolNamePlan) providerName(name string) string {
+	if sanitized, ok := t.toProvider[name]; ok {
+		return sanitized
+	}
+	// Tools called earlier in the history but no longer offered
+	return utils.SanitizeToolName(name)
+}
+
+// apply renames the tools, the forced tool choice and the tool calls and results in the
+// history. messages and the caller's tools are not modified.
+func (t *toolNamePlan) apply(messages []llmtypes.MessageContent, options []llmtypes.CallOption, opts *llmtypes.CallOptions) ([]llmtypes.MessageContent, []llmtypes.CallOption) {
+	tools := make([]llmtypes.Tool, len(opts.Tools))
+	for i, tool := range opts.Tools {
+		if tool.Function != nil {
+			function := *tool.Function
+			function.Name = t.providerName(function.Name)
+			tool.Function = &function
+		}
+		tools[i] = tool
+	}
+	choice := opts.ToolChoice
+	if name := forcedToolName(choice); name != "" {
+		renamed := *choice
+		renamed.Function = &llmtypes.FunctionName{Name: t.providerName(name)}
+		choice = &renamed
+	}
+	options = append(append([]llmtypes.CallOption{}, options...), func(o *llmtypes.CallOptions) {
+		o.Tools = tools
+		o.ToolChoice = choice
+	})
+
+	result := make([]llmtypes.MessageContent, len(messages))
+	for i, msg := range messages {
+		result[i] = msg
+		copied := false
+		for j, part := range msg.Parts {
+			var renamed llmtypes.ContentPart
+			switch p := part.(type) {
+			case llmtypes.ToolCall:
+				if p.FunctionCall != nil && !utils.IsValidToolName(p.FunctionCall.Name) {
+					function := *p.FunctionCall
+					function.Name = t.providerName(function.Name)
+					p.FunctionCall = &function
+					renamed = p
+				}
+			case llmtypes.ToolCallResponse:
+				if p.Name != "" && !utils.IsValidToolName(p.Name) {
+					p.Name = t.providerName(p.Name)
+					renamed = p
+				}
+			}
+			if renamed == nil {
+				continue
+			}
+			if !copied {
+				result[i].Parts = append([]llmtypes.ContentPart{}, msg.Parts...)
+				copied = true
+			}
+			result[i].Parts[j] = renamed
+		}
+	}
+	return result, options
+}
+
+// restoreToolCall maps the name of tc back to the caller's tool name
+func (t *toolNamePlan) restoreToolCall(tc llmtypes.ToolCall) llmtypes.ToolCall {
+	if tc.FunctionCall == nil {
+		return tc
+	}
+	if original, ok := t.toOriginal[tc.FunctionCall.Name]; ok {
+		function := *tc.FunctionCall
+		function.Name = original
+		tc.FunctionCall = &function
+	}
+	return tc
+}
+
+// restore maps the tool calls of every choice back to the caller's tool names
+func (t *toolNamePlan) restore(resp *llmtypes.ContentResponse) {
+	for _, choice := range resp.Choices {
+		if choice == nil {
+			continue
+		}
+		for i, tc := range choice.ToolCalls {
+			choice.ToolCalls[i] = t.restoreToolCall(tc)
+		}
+	}
+}
+
+// restoreChunk maps a streamed tool call back to the caller's tool name
+func (t *toolNamePlan) restoreChunk(chunk llmtypes.StreamChunk) llmtypes.StreamChunk {
+	if chunk.ToolCall != nil {
+		tc := t.restoreToolCall(*chunk.ToolCall)
+		chunk.ToolCall = &tc
+	}
+	return chunk
+}
@@ -0,0 +1,135 @@
+package llmproviders
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/manishiitg/multi-llm-provider-go/llmtypes"
+	"github.com/manishiitg/multi-llm-provider-go/pkg/history"
+)
+
+// maxToolSummaries bounds the summaries kept by a ProviderAwareLLM
+const maxToolSummaries = 256
+
+// toolSummaryCache keeps the summaries of tool results, so a result that stays in a
+// conversation's history is summarized once rather than on every turn
+type toolSummaryCache struct {
+	mu        sync.Mutex
+	summaries map[string]string
+}
+
+func (c *toolSummaryCache) get(key string) (string, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	summary, ok := c.summaries[key]
+	return summary, ok
+}
+
+func (c *toolSummaryCache) put(key, summary string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.summaries == nil || len(c.summaries) >= maxToolSummaries {
+		c.summaries = make(map[string]string)
+	}
+	c.summaries[key] = summary
+}
+
+// summarizeToolResults returns messages with the tool results longer than
+// opts.ToolResultMaxTokens replaced by summaries (WithToolResultMaxTokens). Messages are
+// copied, not modified.
+func (p *ProviderAwareLLM) summarizeToolResults(ctx context.Context, messages []llmtypes.MessageContent, opts *llmtypes.CallOptions) ([]llmtypes.MessageContent, error) {
+	var result []llmtypes.MessageContent
+	for i, msg := range messages {
+		var parts []llmtypes.ContentPart
+		for j, part := range msg.Parts {
+			response, ok := part.(llmtypes.ToolCallResponse)
+			if !ok || !p.needsToolSummary(response, opts) {
+				continue
+			}
+			summarized, err := p.summarizeToolResult(ctx, response, opts)
+			if err != nil {
+				return nil, err
+			}
+			if parts == nil {
+				parts = append([]llmtypes.ContentPart(nil), msg.Parts...)
+			}
+			parts[j] = summarized
+		}
+		if parts == nil {
+			continue
+		}
+		if result == nil {
+			result = append([]llmtypes.MessageContent(nil), messages...)
+		}
+		result[i].Parts = parts
+	}
+	if result == nil {
+		return messages, nil
+	}
+	return result, nil
+}
+
+// needsToolSummary reports whether the text of response is over the limit and may be
+// summarized: JSON results only when their tool opted in
+func (p *ProviderAwareLLM) needsToolSummary(response llmtypes.ToolCallResponse, opts *llmtypes.CallOptions) bool {
+	text := response.Text()
+	if history.CountTextTokens(text) <= opts.ToolResultMaxTokens {
+		return false
+	}
+	return !isStructuredToolResult(text) || slices.Contains(opts.SummarizeStructuredTools, response.Name)
+}
+
+// isStructuredToolResult reports whether text is a JSON object or array
+func isStructuredToolResult(text string) bool {
+	text = strings.TrimSpace(text)
+	return (strings.HasPrefix(text, "{") || strings.HasPrefix(text, "[")) && json.Valid([]byte(text))
+}
+
+// summarizeToolResult returns response with its text replaced by a summary and a note that
+// it was summarized; its images are kept
+func (p *ProviderAwareLLM) summarizeToolResult(ctx context.Context, response llmtypes.ToolCallResponse, opts *llmtypes.CallOptions) (llmtypes.ToolCallResponse, error) {
+	text := response.Text()
+	tokens := history.CountTextTokens(text)
+	hash := sha256.Sum256([]byte(fmt.Sprintf("%s\x00%d\x00%s", response.Name, opts.ToolResultMaxTokens, text)))
+	key := hex.EncodeToString(hash[:])
+
+	summary, ok := p.toolSummaries.get(key)
+	if !ok {
+		summarizer := opts.ToolResultSummarizer
+		if summarizer == nil {
+			summarizer = p.Model
+		}
+		instruction := fmt.Sprintf("Summarize the output of the tool %q below for the AI assistant that called it. "+
+			"Keep the facts, names, numbers, identifiers and errors it needs to continue its task and drop "+
+			"repetition and boilerplate. Reply with the summary only, in at most %d tokens.", response.Name, opts.ToolResultMaxTokens)
+		resp, err := summarizer.GenerateContent(ctx, []llmtypes.MessageContent{
+			llmtypes.TextParts(llmtypes.ChatMessageTypeSystem, instruction),
+			llmtypes.TextParts(llmtypes.ChatMessageTypeHuman, text),
+		}, llmtypes.WithMaxTokens(opts.ToolResultMaxTokens))
+		if err != nil {
+			return response, fmt.Errorf("failed to summarize result of tool %s: %w", response.Name, err)
+		}
+		if resp == nil || len(resp.Choices) == 0 || resp.Choices[0] == nil || strings.TrimSpace(resp.Choices[0].Content) == "" {
+			return response, fmt.Errorf("failed to summarize result of tool %s: empty summary", response.Name)
+		}
+		summary = strings.TrimSpace(resp.Choices[0].Content)
+		p.toolSummaries.put(key, summary)
+		p.logger.Infof("📝 Summarized result of tool %s from about %d tokens to %d", response.Name, tokens, history.CountTextTokens(summary))
+	}
+
+	var images []llmtypes.ContentPart
+	for _, part := range response.Parts {
+		if _, isText := part.(llmtypes.TextContent); !isText {
+			images = append(images, part)
+		}
+	}
+	response.Content = fmt.Sprintf("[Summary of a %d-token tool result]\n%s", tokens, summary)
+	response.Parts = images
+	return response, nil
+}
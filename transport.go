@@ -0,0 +1,80 @@
+package llmproviders
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/bedrockruntime"
+)
+
+// TransportConfig tunes the connection pool used for provider requests. Zero fields keep
+// Go's defaults (http.DefaultTransport), which allow only 2 idle connections per host: under
+// high QPS most requests then open a new connection and pay a TLS handshake.
+//
+// Recommended settings for high-QPS services (hundreds of requests per second to one provider):
+//
+//	&TransportConfig{
+//		MaxIdleConns:        500,
+//		MaxIdleConnsPerHost: 100, // about the expected number of concurrent requests
+//		IdleConnTimeout:     90 * time.Second,
+//	}
+//
+// Each InitializeLLM call builds its own pool; to share one pool across models, build an
+// http.Client once and pass it as Config.HTTPClient instead.
+type TransportConfig struct {
+	// MaxIdleConns limits idle connections across all hosts (0 keeps the default of 100)
+	MaxIdleConns int
+	// MaxIdleConnsPerHost limits idle connections kept per host (0 keeps the default of 2)
+	MaxIdleConnsPerHost int
+	// MaxConnsPerHost limits connections per host, including active ones (0 means no limit)
+	MaxConnsPerHost int
+	// IdleConnTimeout closes connections idle for longer (0 keeps the default of 90s)
+	IdleConnTimeout time.Duration
+	// TLSHandshakeTimeout bounds TLS handshakes (0 keeps the default of 10s)
+	TLSHandshakeTimeout time.Duration
+	// DisableKeepAlives opens a new connection for every request
+	DisableKeepAlives bool
+}
+
+// newTransport returns a copy of http.DefaultTransport with the configured settings
+func (t *TransportConfig) newTransport() *http.Transport {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	if t.MaxIdleConns > 0 {
+		transport.MaxIdleConns = t.MaxIdleConns
+	}
+	if t.MaxIdleConnsPerHost > 0 {
+		transport.MaxIdleConnsPerHost = t.MaxIdleConnsPerHost
+	}
+	if t.MaxConnsPerHost > 0 {
+		transport.MaxConnsPerHost = t.MaxConnsPerHost
+	}
+	if t.IdleConnTimeout > 0 {
+		transport.IdleConnTimeout = t.IdleConnTimeout
+	}
+	if t.TLSHandshakeTimeout > 0 {
+		transport.TLSHandshakeTimeout = t.TLSHandshakeTimeout
+	}
+	transport.DisableKeepAlives = t.DisableKeepAlives
+	return transport
+}
+
+// httpClient returns the HTTP client provider SDKs should use: Config.HTTPClient, a client
+// over a transport built from Config.Transport, or nil to keep each SDK's default client
+func (config Config) httpClient() *http.Client {
+	if config.HTTPClient != nil {
+		return config.HTTPClient
+	}
+	if config.Transport != nil {
+		return &http.Client{Transport: config.Transport.newTransport()}
+	}
+	return nil
+}
+
+// withBedrockHTTPClient sets the configured HTTP client on a Bedrock runtime client
+func withBedrockHTTPClient(config Config) func(*bedrockruntime.Options) {
+	return func(o *bedrockruntime.Options) {
+		if httpClient := config.httpClient(); httpClient != nil {
+			o.HTTPClient = httpClient
+		}
+	}
+}
@@ -28,14 +28,39 @@ type ToolChoice = llmtypes.ToolChoice
 type FunctionName = llmtypes.FunctionName
 type CallOptions = llmtypes.CallOptions
 type CallOption = llmtypes.CallOption
+type StreamChunk = llmtypes.StreamChunk
+type StreamChunkType = llmtypes.StreamChunkType
+type StreamBufferingMode = llmtypes.StreamBufferingMode
+type StreamAggregator = llmtypes.StreamAggregator
+type ProviderRequest = llmtypes.ProviderRequest
+type RequestInterceptor = llmtypes.RequestInterceptor
+type MessageTransform = llmtypes.MessageTransform
+type ResponseInterceptor = llmtypes.ResponseInterceptor
+type ResponseValidator = llmtypes.ResponseValidator
+type RetryPolicy = llmtypes.RetryPolicy
+type OpenAIParams = llmtypes.OpenAIParams
+type AnthropicParams = llmtypes.AnthropicParams
+type GeminiParams = llmtypes.GeminiParams
 
 // Re-export embedding types
 type EmbeddingModel = llmtypes.EmbeddingModel
+type CompletionModel = llmtypes.CompletionModel
+type ContextCacheModel = llmtypes.ContextCacheModel
+type CacheName = llmtypes.CacheName
 type Embedding = llmtypes.Embedding
 type EmbeddingResponse = llmtypes.EmbeddingResponse
 type EmbeddingUsage = llmtypes.EmbeddingUsage
 type EmbeddingOptions = llmtypes.EmbeddingOptions
 type EmbeddingOption = llmtypes.EmbeddingOption
+type EmbeddingTaskType = llmtypes.EmbeddingTaskType
+
+// Re-export realtime types
+type AudioContent = llmtypes.AudioContent
+type RealtimeSession = llmtypes.RealtimeSession
+type RealtimeEvent = llmtypes.RealtimeEvent
+type RealtimeEventType = llmtypes.RealtimeEventType
+type RealtimeOptions = llmtypes.RealtimeOptions
+type RealtimeOption = llmtypes.RealtimeOption
 
 // Re-export constants
 const (
@@ -45,22 +70,111 @@ const (
 	ChatMessageTypeTool     = llmtypes.ChatMessageTypeTool
 	ChatMessageTypeGeneric  = llmtypes.ChatMessageTypeGeneric
 	ChatMessageTypeFunction = llmtypes.ChatMessageTypeFunction
+
+	StreamChunkTypeContent   = llmtypes.StreamChunkTypeContent
+	StreamChunkTypeToolCall  = llmtypes.StreamChunkTypeToolCall
+	StreamChunkTypeFinish    = llmtypes.StreamChunkTypeFinish
+	StreamChunkTypeReasoning = llmtypes.StreamChunkTypeReasoning
+	StreamChunkTypeUsage     = llmtypes.StreamChunkTypeUsage
+	StreamChunkTypeHeartbeat = llmtypes.StreamChunkTypeHeartbeat
+
+	StreamBufferingToken    = llmtypes.StreamBufferingToken
+	StreamBufferingLine     = llmtypes.StreamBufferingLine
+	StreamBufferingSentence = llmtypes.StreamBufferingSentence
+
+	EmbeddingTaskRetrievalQuery     = llmtypes.EmbeddingTaskRetrievalQuery
+	EmbeddingTaskRetrievalDocument  = llmtypes.EmbeddingTaskRetrievalDocument
+	EmbeddingTaskClassification     = llmtypes.EmbeddingTaskClassification
+	EmbeddingTaskClustering         = llmtypes.EmbeddingTaskClustering
+	EmbeddingTaskSemanticSimilarity = llmtypes.EmbeddingTaskSemanticSimilarity
 )
 
 // Re-export functions
 var (
-	WithModel          = llmtypes.WithModel
-	WithTemperature    = llmtypes.WithTemperature
-	WithMaxTokens      = llmtypes.WithMaxTokens
-	WithJSONMode       = llmtypes.WithJSONMode
-	WithTools          = llmtypes.WithTools
-	WithToolChoice     = llmtypes.WithToolChoice
-	WithStreamingFunc  = llmtypes.WithStreamingFunc
-	TextPart           = llmtypes.TextPart
-	TextParts          = llmtypes.TextParts
-	ImagePart          = llmtypes.ImagePart
-	ImagePartBase64    = llmtypes.ImagePartBase64
-	ImagePartURL       = llmtypes.ImagePartURL
-	WithEmbeddingModel = llmtypes.WithEmbeddingModel
-	WithDimensions     = llmtypes.WithDimensions
+	WithModel              = llmtypes.WithModel
+	WithTemperature        = llmtypes.WithTemperature
+	WithMaxTokens          = llmtypes.WithMaxTokens
+	WithJSONMode           = llmtypes.WithJSONMode
+	WithTools              = llmtypes.WithTools
+	WithToolChoice         = llmtypes.WithToolChoice
+	WithStreamingFunc      = llmtypes.WithStreamingFunc
+	TextPart               = llmtypes.TextPart
+	TextParts              = llmtypes.TextParts
+	ImagePart              = llmtypes.ImagePart
+	ImagePartBase64        = llmtypes.ImagePartBase64
+	ImagePartURL           = llmtypes.ImagePartURL
+	WithEmbeddingModel     = llmtypes.WithEmbeddingModel
+	WithDimensions         = llmtypes.WithDimensions
+	WithEmbeddingTaskType  = llmtypes.WithEmbeddingTaskType
+	WithInputType          = llmtypes.WithInputType
+	ParseEmbeddingTaskType = llmtypes.ParseEmbeddingTaskType
+	NewStreamAggregator    = llmtypes.NewStreamAggregator
+	AggregateStream        = llmtypes.AggregateStream
+	AddUsage               = llmtypes.AddUsage
+
+	WithRequestInterceptor  = llmtypes.WithRequestInterceptor
+	WithMessageTransform    = llmtypes.WithMessageTransform
+	WithResponseInterceptor = llmtypes.WithResponseInterceptor
+	WithResponseValidator   = llmtypes.WithResponseValidator
+	WithValidationRetries   = llmtypes.WithValidationRetries
+	WithValidationFeedback  = llmtypes.WithValidationFeedback
+	WithRetryDiversity      = llmtypes.WithRetryDiversity
+	WithExtraBody           = llmtypes.WithExtraBody
+	WithExtraHeaders        = llmtypes.WithExtraHeaders
+	WithOpenAIParams        = llmtypes.WithOpenAIParams
+	WithAnthropicParams     = llmtypes.WithAnthropicParams
+	WithGeminiParams        = llmtypes.WithGeminiParams
+	WithServiceTier         = llmtypes.WithServiceTier
+	WithN                   = llmtypes.WithN
+	WithAbortOnToolCall     = llmtypes.WithAbortOnToolCall
+	WithAssistantPrefill    = llmtypes.WithAssistantPrefill
+	WithDryRun              = llmtypes.WithDryRun
+	WithReasoningVisibility = llmtypes.WithReasoningVisibility
+	WithStructuredOutput    = llmtypes.WithStructuredOutput
+	WithSchemaValidation    = llmtypes.WithSchemaValidation
+	WithSchemaRetry         = llmtypes.WithSchemaRetry
+	WithResponseMimeType    = llmtypes.WithResponseMimeType
+	WithResponseModalities  = llmtypes.WithResponseModalities
+
+	WithToolResultImageMaxBytes = llmtypes.WithToolResultImageMaxBytes
+	WithImageAutoConvert        = llmtypes.WithImageAutoConvert
+	WithAutoContinue            = llmtypes.WithAutoContinue
+	WithMaxInputTokens          = llmtypes.WithMaxInputTokens
+	WithToolEmulation           = llmtypes.WithToolEmulation
+	WithStreamUsage             = llmtypes.WithStreamUsage
+	WithStreamEventHook         = llmtypes.WithStreamEventHook
+	WithEnforceRequiredToolArgs = llmtypes.WithEnforceRequiredToolArgs
+	WithStripReasoningTags      = llmtypes.WithStripReasoningTags
+	WithFallbackModels          = llmtypes.WithFallbackModels
+	WithSingleFlight            = llmtypes.WithSingleFlight
+	WithStreamTextOnly          = llmtypes.WithStreamTextOnly
+	WithStreamStallTimeout      = llmtypes.WithStreamStallTimeout
+	WithStreamHeartbeat         = llmtypes.WithStreamHeartbeat
+	WithStreamBuffering         = llmtypes.WithStreamBuffering
+	WithLogitBias               = llmtypes.WithLogitBias
+	WithToolNameSanitization    = llmtypes.WithToolNameSanitization
+	WithMaxToolCallsPerResponse = llmtypes.WithMaxToolCallsPerResponse
+	WithDisableTools            = llmtypes.WithDisableTools
+	WithRegion                  = llmtypes.WithRegion
+	WithStreamFallback          = llmtypes.WithStreamFallback
+	WithMaxRetries              = llmtypes.WithMaxRetries
+	WithRetryPolicy             = llmtypes.WithRetryPolicy
+	WithRetryOnEmptyContent     = llmtypes.WithRetryOnEmptyContent
+	WithAllowedTools            = llmtypes.WithAllowedTools
+	WithCachedContent           = llmtypes.WithCachedContent
+	WithMaxImageCount           = llmtypes.WithMaxImageCount
+	WithImageDownsampling       = llmtypes.WithImageDownsampling
+	WithMetadataTraceID         = llmtypes.WithMetadataTraceID
+
+	WithToolResultMaxTokens            = llmtypes.WithToolResultMaxTokens
+	WithToolResultSummarizer           = llmtypes.WithToolResultSummarizer
+	WithSummarizeStructuredToolResults = llmtypes.WithSummarizeStructuredToolResults
+
+	MarshalTranscript   = llmtypes.MarshalTranscript
+	UnmarshalTranscript = llmtypes.UnmarshalTranscript
+
+	WithRealtimeInstructions       = llmtypes.WithRealtimeInstructions
+	WithRealtimeModalities         = llmtypes.WithRealtimeModalities
+	WithRealtimeVoice              = llmtypes.WithRealtimeVoice
+	WithRealtimeInputTranscription = llmtypes.WithRealtimeInputTranscription
 )